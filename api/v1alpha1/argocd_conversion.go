@@ -65,6 +65,9 @@ func (src *ArgoCD) ConvertTo(dstRaw conversion.Hub) error {
 	dst.Spec.HelpChatURL = src.Spec.HelpChatURL
 	dst.Spec.HelpChatText = src.Spec.HelpChatText
 	dst.Spec.Image = src.Spec.Image
+	dst.Spec.ImagePullPolicy = src.Spec.ImagePullPolicy
+	dst.Spec.ImagePullSecrets = src.Spec.ImagePullSecrets
+	dst.Spec.ImageRegistry = src.Spec.ImageRegistry
 	dst.Spec.Import = (*v1beta1.ArgoCDImportSpec)(src.Spec.Import)
 	dst.Spec.InitialRepositories = src.Spec.InitialRepositories
 	dst.Spec.InitialSSHKnownHosts = v1beta1.SSHHostsSpec(src.Spec.InitialSSHKnownHosts)
@@ -73,7 +76,7 @@ func (src *ArgoCD) ConvertTo(dstRaw conversion.Hub) error {
 	dst.Spec.OIDCConfig = src.Spec.OIDCConfig
 	dst.Spec.Monitoring = v1beta1.ArgoCDMonitoringSpec(src.Spec.Monitoring)
 	dst.Spec.NodePlacement = (*v1beta1.ArgoCDNodePlacementSpec)(src.Spec.NodePlacement)
-	dst.Spec.Notifications = v1beta1.ArgoCDNotifications(src.Spec.Notifications)
+	dst.Spec.Notifications = *ConvertAlphaToBetaNotifications(&src.Spec.Notifications)
 	dst.Spec.Prometheus = *ConvertAlphaToBetaPrometheus(&src.Spec.Prometheus)
 	dst.Spec.RBAC = v1beta1.ArgoCDRBACSpec(src.Spec.RBAC)
 	dst.Spec.Redis = *ConvertAlphaToBetaRedis(&src.Spec.Redis)
@@ -94,7 +97,7 @@ func (src *ArgoCD) ConvertTo(dstRaw conversion.Hub) error {
 	dst.Spec.Banner = (*v1beta1.Banner)(src.Spec.Banner)
 
 	// Status conversion
-	dst.Status = v1beta1.ArgoCDStatus(src.Status)
+	dst.Status = *ConvertAlphaToBetaStatus(&src.Status)
 
 	return nil
 }
@@ -132,6 +135,9 @@ func (dst *ArgoCD) ConvertFrom(srcRaw conversion.Hub) error {
 	dst.Spec.HelpChatURL = src.Spec.HelpChatURL
 	dst.Spec.HelpChatText = src.Spec.HelpChatText
 	dst.Spec.Image = src.Spec.Image
+	dst.Spec.ImagePullPolicy = src.Spec.ImagePullPolicy
+	dst.Spec.ImagePullSecrets = src.Spec.ImagePullSecrets
+	dst.Spec.ImageRegistry = src.Spec.ImageRegistry
 	dst.Spec.Import = (*ArgoCDImportSpec)(src.Spec.Import)
 	dst.Spec.InitialRepositories = src.Spec.InitialRepositories
 	dst.Spec.InitialSSHKnownHosts = SSHHostsSpec(src.Spec.InitialSSHKnownHosts)
@@ -140,7 +146,7 @@ func (dst *ArgoCD) ConvertFrom(srcRaw conversion.Hub) error {
 	dst.Spec.OIDCConfig = src.Spec.OIDCConfig
 	dst.Spec.Monitoring = ArgoCDMonitoringSpec(src.Spec.Monitoring)
 	dst.Spec.NodePlacement = (*ArgoCDNodePlacementSpec)(src.Spec.NodePlacement)
-	dst.Spec.Notifications = ArgoCDNotifications(src.Spec.Notifications)
+	dst.Spec.Notifications = *ConvertBetaToAlphaNotifications(&src.Spec.Notifications)
 	dst.Spec.Prometheus = *ConvertBetaToAlphaPrometheus(&src.Spec.Prometheus)
 	dst.Spec.RBAC = ArgoCDRBACSpec(src.Spec.RBAC)
 	dst.Spec.Redis = *ConvertBetaToAlphaRedis(&src.Spec.Redis)
@@ -161,7 +167,7 @@ func (dst *ArgoCD) ConvertFrom(srcRaw conversion.Hub) error {
 	dst.Spec.Banner = (*Banner)(src.Spec.Banner)
 
 	// Status conversion
-	dst.Status = ArgoCDStatus(src.Status)
+	dst.Status = *ConvertBetaToAlphaStatus(&src.Status)
 
 	return nil
 }
@@ -228,6 +234,7 @@ func ConvertAlphaToBetaWebhookServer(src *WebhookServerSpec) *v1beta1.WebhookSer
 	var dst *v1beta1.WebhookServerSpec
 	if src != nil {
 		dst = &v1beta1.WebhookServerSpec{
+			Gateway: v1beta1.ArgoCDGatewaySpec(src.Gateway),
 			Host:    src.Host,
 			Ingress: v1beta1.ArgoCDIngressSpec(src.Ingress),
 			Route:   v1beta1.ArgoCDRouteSpec(src.Route),
@@ -285,7 +292,21 @@ func ConvertAlphaToBetaSSO(src *ArgoCDSSOSpec) *v1beta1.ArgoCDSSOSpec {
 		dst = &v1beta1.ArgoCDSSOSpec{
 			Provider: v1beta1.SSOProviderType(src.Provider),
 			Dex:      ConvertAlphaToBetaDex(src.Dex),
-			Keycloak: (*v1beta1.ArgoCDKeycloakSpec)(src.Keycloak),
+			Keycloak: ConvertAlphaToBetaKeycloak(src.Keycloak),
+		}
+	}
+	return dst
+}
+
+func ConvertAlphaToBetaKeycloak(src *ArgoCDKeycloakSpec) *v1beta1.ArgoCDKeycloakSpec {
+	var dst *v1beta1.ArgoCDKeycloakSpec
+	if src != nil {
+		dst = &v1beta1.ArgoCDKeycloakSpec{
+			Image:     src.Image,
+			Resources: src.Resources,
+			RootCA:    src.RootCA,
+			Version:   src.Version,
+			VerifyTLS: src.VerifyTLS,
 		}
 	}
 	return dst
@@ -320,12 +341,61 @@ func ConvertAlphaToBetaHA(src *ArgoCDHASpec) *v1beta1.ArgoCDHASpec {
 	return dst
 }
 
+func ConvertAlphaToBetaNotifications(src *ArgoCDNotifications) *v1beta1.ArgoCDNotifications {
+	var dst *v1beta1.ArgoCDNotifications
+	if src != nil {
+		dst = &v1beta1.ArgoCDNotifications{
+			Replicas:  src.Replicas,
+			Enabled:   src.Enabled,
+			Env:       src.Env,
+			Image:     src.Image,
+			Version:   src.Version,
+			Resources: src.Resources,
+			LogLevel:  src.LogLevel,
+		}
+	}
+	return dst
+}
+
+func ConvertAlphaToBetaStatus(src *ArgoCDStatus) *v1beta1.ArgoCDStatus {
+	return &v1beta1.ArgoCDStatus{
+		ApplicationController:    src.ApplicationController,
+		ApplicationSetController: src.ApplicationSetController,
+		SSO:                      src.SSO,
+		NotificationsController:  src.NotificationsController,
+		Phase:                    src.Phase,
+		Redis:                    src.Redis,
+		Repo:                     src.Repo,
+		Server:                   src.Server,
+		RepoTLSChecksum:          src.RepoTLSChecksum,
+		RedisTLSChecksum:         src.RedisTLSChecksum,
+		RedisAuthChecksum:        src.RedisAuthChecksum,
+		Host:                     src.Host,
+		Import:                   (*v1beta1.ArgoCDImportStatus)(src.Import),
+		TLS:                      (*v1beta1.ArgoCDTLSStatus)(src.TLS),
+		Conditions:               src.Conditions,
+		ObservedGeneration:       src.ObservedGeneration,
+	}
+}
+
 func ConvertAlphaToBetaTLS(src *ArgoCDTLSSpec) *v1beta1.ArgoCDTLSSpec {
 	var dst *v1beta1.ArgoCDTLSSpec
 	if src != nil {
 		dst = &v1beta1.ArgoCDTLSSpec{
 			CA:           v1beta1.ArgoCDCASpec(src.CA),
+			CertManager:  ConvertAlphaToBetaCertManager(src.CertManager),
 			InitialCerts: src.InitialCerts,
+			RenewBefore:  src.RenewBefore,
+		}
+	}
+	return dst
+}
+
+func ConvertAlphaToBetaCertManager(src *ArgoCDCertManagerSpec) *v1beta1.ArgoCDCertManagerSpec {
+	var dst *v1beta1.ArgoCDCertManagerSpec
+	if src != nil {
+		dst = &v1beta1.ArgoCDCertManagerSpec{
+			IssuerRef: v1beta1.ArgoCDCertManagerIssuerReference(src.IssuerRef),
 		}
 	}
 	return dst
@@ -336,6 +406,8 @@ func ConvertAlphaToBetaServer(src *ArgoCDServerSpec) *v1beta1.ArgoCDServerSpec {
 	if src != nil {
 		dst = &v1beta1.ArgoCDServerSpec{
 			Autoscale:        v1beta1.ArgoCDServerAutoscaleSpec(src.Autoscale),
+			AutoTLS:          src.AutoTLS,
+			Gateway:          v1beta1.ArgoCDGatewaySpec(src.Gateway),
 			GRPC:             *ConvertAlphaToBetaGRPC(&src.GRPC),
 			Host:             src.Host,
 			Ingress:          v1beta1.ArgoCDIngressSpec(src.Ingress),
@@ -357,6 +429,7 @@ func ConvertAlphaToBetaGRPC(src *ArgoCDServerGRPCSpec) *v1beta1.ArgoCDServerGRPC
 	var dst *v1beta1.ArgoCDServerGRPCSpec
 	if src != nil {
 		dst = &v1beta1.ArgoCDServerGRPCSpec{
+			Gateway: v1beta1.ArgoCDGatewaySpec(src.Gateway),
 			Host:    src.Host,
 			Ingress: v1beta1.ArgoCDIngressSpec(src.Ingress),
 		}
@@ -448,6 +521,7 @@ func ConvertBetaToAlphaWebhookServer(src *v1beta1.WebhookServerSpec) *WebhookSer
 	var dst *WebhookServerSpec
 	if src != nil {
 		dst = &WebhookServerSpec{
+			Gateway: ArgoCDGatewaySpec(src.Gateway),
 			Host:    src.Host,
 			Ingress: ArgoCDIngressSpec(src.Ingress),
 			Route:   ArgoCDRouteSpec(src.Route),
@@ -505,7 +579,21 @@ func ConvertBetaToAlphaSSO(src *v1beta1.ArgoCDSSOSpec) *ArgoCDSSOSpec {
 		dst = &ArgoCDSSOSpec{
 			Provider: SSOProviderType(src.Provider),
 			Dex:      ConvertBetaToAlphaDex(src.Dex),
-			Keycloak: (*ArgoCDKeycloakSpec)(src.Keycloak),
+			Keycloak: ConvertBetaToAlphaKeycloak(src.Keycloak),
+		}
+	}
+	return dst
+}
+
+func ConvertBetaToAlphaKeycloak(src *v1beta1.ArgoCDKeycloakSpec) *ArgoCDKeycloakSpec {
+	var dst *ArgoCDKeycloakSpec
+	if src != nil {
+		dst = &ArgoCDKeycloakSpec{
+			Image:     src.Image,
+			Resources: src.Resources,
+			RootCA:    src.RootCA,
+			Version:   src.Version,
+			VerifyTLS: src.VerifyTLS,
 		}
 	}
 	return dst
@@ -539,12 +627,61 @@ func ConvertBetaToAlphaHA(src *v1beta1.ArgoCDHASpec) *ArgoCDHASpec {
 	return dst
 }
 
+func ConvertBetaToAlphaNotifications(src *v1beta1.ArgoCDNotifications) *ArgoCDNotifications {
+	var dst *ArgoCDNotifications
+	if src != nil {
+		dst = &ArgoCDNotifications{
+			Replicas:  src.Replicas,
+			Enabled:   src.Enabled,
+			Env:       src.Env,
+			Image:     src.Image,
+			Version:   src.Version,
+			Resources: src.Resources,
+			LogLevel:  src.LogLevel,
+		}
+	}
+	return dst
+}
+
+func ConvertBetaToAlphaStatus(src *v1beta1.ArgoCDStatus) *ArgoCDStatus {
+	return &ArgoCDStatus{
+		ApplicationController:    src.ApplicationController,
+		ApplicationSetController: src.ApplicationSetController,
+		SSO:                      src.SSO,
+		NotificationsController:  src.NotificationsController,
+		Phase:                    src.Phase,
+		Redis:                    src.Redis,
+		Repo:                     src.Repo,
+		Server:                   src.Server,
+		RepoTLSChecksum:          src.RepoTLSChecksum,
+		RedisTLSChecksum:         src.RedisTLSChecksum,
+		RedisAuthChecksum:        src.RedisAuthChecksum,
+		Host:                     src.Host,
+		Import:                   (*ArgoCDImportStatus)(src.Import),
+		TLS:                      (*ArgoCDTLSStatus)(src.TLS),
+		Conditions:               src.Conditions,
+		ObservedGeneration:       src.ObservedGeneration,
+	}
+}
+
 func ConvertBetaToAlphaTLS(src *v1beta1.ArgoCDTLSSpec) *ArgoCDTLSSpec {
 	var dst *ArgoCDTLSSpec
 	if src != nil {
 		dst = &ArgoCDTLSSpec{
 			CA:           ArgoCDCASpec(src.CA),
+			CertManager:  ConvertBetaToAlphaCertManager(src.CertManager),
 			InitialCerts: src.InitialCerts,
+			RenewBefore:  src.RenewBefore,
+		}
+	}
+	return dst
+}
+
+func ConvertBetaToAlphaCertManager(src *v1beta1.ArgoCDCertManagerSpec) *ArgoCDCertManagerSpec {
+	var dst *ArgoCDCertManagerSpec
+	if src != nil {
+		dst = &ArgoCDCertManagerSpec{
+			IssuerRef: ArgoCDCertManagerIssuerReference(src.IssuerRef),
 		}
 	}
 	return dst
@@ -555,6 +692,8 @@ func ConvertBetaToAlphaServer(src *v1beta1.ArgoCDServerSpec) *ArgoCDServerSpec {
 	if src != nil {
 		dst = &ArgoCDServerSpec{
 			Autoscale:        ArgoCDServerAutoscaleSpec(src.Autoscale),
+			AutoTLS:          src.AutoTLS,
+			Gateway:          ArgoCDGatewaySpec(src.Gateway),
 			GRPC:             *ConvertBetaToAlphaGRPC(&src.GRPC),
 			Host:             src.Host,
 			Ingress:          ArgoCDIngressSpec(src.Ingress),
@@ -576,6 +715,7 @@ func ConvertBetaToAlphaGRPC(src *v1beta1.ArgoCDServerGRPCSpec) *ArgoCDServerGRPC
 	var dst *ArgoCDServerGRPCSpec
 	if src != nil {
 		dst = &ArgoCDServerGRPCSpec{
+			Gateway: ArgoCDGatewaySpec(src.Gateway),
 			Host:    src.Host,
 			Ingress: ArgoCDIngressSpec(src.Ingress),
 		}
@@ -2,6 +2,7 @@ package v1alpha1
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	corev1 "k8s.io/api/core/v1"
@@ -437,6 +438,143 @@ func TestAlphaToBetaConversion(t *testing.T) {
 				}
 			}),
 		},
+		{
+			name: "ArgoCD Example - TLS Options",
+			input: makeTestArgoCDAlpha(func(cr *ArgoCD) {
+				cr.Spec.Server.AutoTLS = "cert-manager"
+				cr.Spec.Repo.AutoTLS = "cert-manager"
+				cr.Spec.TLS.CertManager = &ArgoCDCertManagerSpec{
+					IssuerRef: ArgoCDCertManagerIssuerReference{
+						Name: "argocd-issuer",
+						Kind: "ClusterIssuer",
+					},
+				}
+				cr.Spec.TLS.RenewBefore = &metav1.Duration{Duration: 240 * time.Hour}
+			}),
+			expectedOutput: makeTestArgoCDBeta(func(cr *v1beta1.ArgoCD) {
+				cr.Spec.Server.AutoTLS = "cert-manager"
+				cr.Spec.Repo.AutoTLS = "cert-manager"
+				cr.Spec.TLS.CertManager = &v1beta1.ArgoCDCertManagerSpec{
+					IssuerRef: v1beta1.ArgoCDCertManagerIssuerReference{
+						Name: "argocd-issuer",
+						Kind: "ClusterIssuer",
+					},
+				}
+				cr.Spec.TLS.RenewBefore = &metav1.Duration{Duration: 240 * time.Hour}
+			}),
+		},
+		{
+			name: "ArgoCD Example - Image Pull Options",
+			input: makeTestArgoCDAlpha(func(cr *ArgoCD) {
+				cr.Spec.ImagePullPolicy = corev1.PullIfNotPresent
+				cr.Spec.ImagePullSecrets = []corev1.LocalObjectReference{{Name: "registry-credentials"}}
+				cr.Spec.ImageRegistry = "mirror.example.com"
+			}),
+			expectedOutput: makeTestArgoCDBeta(func(cr *v1beta1.ArgoCD) {
+				cr.Spec.ImagePullPolicy = corev1.PullIfNotPresent
+				cr.Spec.ImagePullSecrets = []corev1.LocalObjectReference{{Name: "registry-credentials"}}
+				cr.Spec.ImageRegistry = "mirror.example.com"
+			}),
+		},
+		{
+			name: "ArgoCD Example - Server + Gateway",
+			input: makeTestArgoCDAlpha(func(cr *ArgoCD) {
+				cr.Spec.Server = ArgoCDServerSpec{
+					Host: "argocd.example.com",
+					Gateway: ArgoCDGatewaySpec{
+						Enabled:     true,
+						GatewayName: "public",
+						Labels: map[string]string{
+							"team": "platform",
+						},
+					},
+					GRPC: ArgoCDServerGRPCSpec{
+						Gateway: ArgoCDGatewaySpec{
+							Enabled:          true,
+							GatewayName:      "public",
+							GatewayNamespace: "gateways",
+							SectionName:      "grpc",
+						},
+					},
+				}
+				cr.Spec.ApplicationSet = &ArgoCDApplicationSet{
+					WebhookServer: WebhookServerSpec{
+						Gateway: ArgoCDGatewaySpec{
+							Enabled:     true,
+							GatewayName: "public",
+							Path:        "/api/webhook",
+						},
+					},
+				}
+			}),
+			expectedOutput: makeTestArgoCDBeta(func(cr *v1beta1.ArgoCD) {
+				cr.Spec.Server = v1beta1.ArgoCDServerSpec{
+					Host: "argocd.example.com",
+					Gateway: v1beta1.ArgoCDGatewaySpec{
+						Enabled:     true,
+						GatewayName: "public",
+						Labels: map[string]string{
+							"team": "platform",
+						},
+					},
+					GRPC: v1beta1.ArgoCDServerGRPCSpec{
+						Gateway: v1beta1.ArgoCDGatewaySpec{
+							Enabled:          true,
+							GatewayName:      "public",
+							GatewayNamespace: "gateways",
+							SectionName:      "grpc",
+						},
+					},
+				}
+				cr.Spec.ApplicationSet = &v1beta1.ArgoCDApplicationSet{
+					WebhookServer: v1beta1.WebhookServerSpec{
+						Gateway: v1beta1.ArgoCDGatewaySpec{
+							Enabled:     true,
+							GatewayName: "public",
+							Path:        "/api/webhook",
+						},
+					},
+				}
+			}),
+		},
+		{
+			name: "ArgoCD Example - Notifications",
+			input: makeTestArgoCDAlpha(func(cr *ArgoCD) {
+				cr.Spec.Notifications = ArgoCDNotifications{
+					Enabled:  true,
+					Image:    "test-image",
+					LogLevel: "debug",
+				}
+			}),
+			expectedOutput: makeTestArgoCDBeta(func(cr *v1beta1.ArgoCD) {
+				cr.Spec.Notifications = v1beta1.ArgoCDNotifications{
+					Enabled:  true,
+					Image:    "test-image",
+					LogLevel: "debug",
+				}
+			}),
+		},
+		{
+			name: "ArgoCD Example - Import Status",
+			input: makeTestArgoCDAlpha(func(cr *ArgoCD) {
+				cr.Status.Phase = "Available"
+				cr.Status.Import = &ArgoCDImportStatus{
+					Name:      "test-name",
+					Namespace: "argocd",
+					BackupID:  "20231017T120000Z",
+					Phase:     "Completed",
+				}
+			}),
+			expectedOutput: makeTestArgoCDBeta(func(cr *v1beta1.ArgoCD) {
+				cr.Status.Phase = "Available"
+				cr.Status.Import = &v1beta1.ArgoCDImportStatus{
+					Name:      "test-name",
+					Namespace: "argocd",
+					BackupID:  "20231017T120000Z",
+					Phase:     "Completed",
+				}
+			}),
+		},
 	}
 
 	for _, test := range tests {
@@ -534,6 +672,122 @@ func TestBetaToAlphaConversion(t *testing.T) {
 				}
 			}),
 		},
+		{
+			name: "ArgoCD Example - TLS Options",
+			input: makeTestArgoCDBeta(func(cr *v1beta1.ArgoCD) {
+				cr.Spec.Server.AutoTLS = "cert-manager"
+				cr.Spec.Repo.AutoTLS = "cert-manager"
+				cr.Spec.TLS.CertManager = &v1beta1.ArgoCDCertManagerSpec{
+					IssuerRef: v1beta1.ArgoCDCertManagerIssuerReference{
+						Name: "argocd-issuer",
+						Kind: "ClusterIssuer",
+					},
+				}
+				cr.Spec.TLS.RenewBefore = &metav1.Duration{Duration: 240 * time.Hour}
+			}),
+			expectedOutput: makeTestArgoCDAlpha(func(cr *ArgoCD) {
+				cr.Spec.Server.AutoTLS = "cert-manager"
+				cr.Spec.Repo.AutoTLS = "cert-manager"
+				cr.Spec.TLS.CertManager = &ArgoCDCertManagerSpec{
+					IssuerRef: ArgoCDCertManagerIssuerReference{
+						Name: "argocd-issuer",
+						Kind: "ClusterIssuer",
+					},
+				}
+				cr.Spec.TLS.RenewBefore = &metav1.Duration{Duration: 240 * time.Hour}
+			}),
+		},
+		{
+			name: "ArgoCD Example - Image Pull Options",
+			input: makeTestArgoCDBeta(func(cr *v1beta1.ArgoCD) {
+				cr.Spec.ImagePullPolicy = corev1.PullIfNotPresent
+				cr.Spec.ImagePullSecrets = []corev1.LocalObjectReference{{Name: "registry-credentials"}}
+				cr.Spec.ImageRegistry = "mirror.example.com"
+			}),
+			expectedOutput: makeTestArgoCDAlpha(func(cr *ArgoCD) {
+				cr.Spec.ImagePullPolicy = corev1.PullIfNotPresent
+				cr.Spec.ImagePullSecrets = []corev1.LocalObjectReference{{Name: "registry-credentials"}}
+				cr.Spec.ImageRegistry = "mirror.example.com"
+			}),
+		},
+		{
+			name: "ArgoCD Example - Server + Gateway",
+			input: makeTestArgoCDBeta(func(cr *v1beta1.ArgoCD) {
+				cr.Spec.Server = v1beta1.ArgoCDServerSpec{
+					Host: "argocd.example.com",
+					Gateway: v1beta1.ArgoCDGatewaySpec{
+						Enabled:     true,
+						GatewayName: "public",
+						Labels: map[string]string{
+							"team": "platform",
+						},
+					},
+					GRPC: v1beta1.ArgoCDServerGRPCSpec{
+						Gateway: v1beta1.ArgoCDGatewaySpec{
+							Enabled:          true,
+							GatewayName:      "public",
+							GatewayNamespace: "gateways",
+							SectionName:      "grpc",
+						},
+					},
+				}
+				cr.Spec.ApplicationSet = &v1beta1.ArgoCDApplicationSet{
+					WebhookServer: v1beta1.WebhookServerSpec{
+						Gateway: v1beta1.ArgoCDGatewaySpec{
+							Enabled:     true,
+							GatewayName: "public",
+							Path:        "/api/webhook",
+						},
+					},
+				}
+			}),
+			expectedOutput: makeTestArgoCDAlpha(func(cr *ArgoCD) {
+				cr.Spec.Server = ArgoCDServerSpec{
+					Host: "argocd.example.com",
+					Gateway: ArgoCDGatewaySpec{
+						Enabled:     true,
+						GatewayName: "public",
+						Labels: map[string]string{
+							"team": "platform",
+						},
+					},
+					GRPC: ArgoCDServerGRPCSpec{
+						Gateway: ArgoCDGatewaySpec{
+							Enabled:          true,
+							GatewayName:      "public",
+							GatewayNamespace: "gateways",
+							SectionName:      "grpc",
+						},
+					},
+				}
+				cr.Spec.ApplicationSet = &ArgoCDApplicationSet{
+					WebhookServer: WebhookServerSpec{
+						Gateway: ArgoCDGatewaySpec{
+							Enabled:     true,
+							GatewayName: "public",
+							Path:        "/api/webhook",
+						},
+					},
+				}
+			}),
+		},
+		{
+			name: "ArgoCD Example - Notifications",
+			input: makeTestArgoCDBeta(func(cr *v1beta1.ArgoCD) {
+				cr.Spec.Notifications = v1beta1.ArgoCDNotifications{
+					Enabled:  true,
+					Image:    "test-image",
+					LogLevel: "debug",
+				}
+			}),
+			expectedOutput: makeTestArgoCDAlpha(func(cr *ArgoCD) {
+				cr.Spec.Notifications = ArgoCDNotifications{
+					Enabled:  true,
+					Image:    "test-image",
+					LogLevel: "debug",
+				}
+			}),
+		},
 	}
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
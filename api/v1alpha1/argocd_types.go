@@ -170,6 +170,25 @@ type ArgoCDCASpec struct {
 	SecretName string `json:"secretName,omitempty"`
 }
 
+// ArgoCDCertManagerSpec defines the cert-manager options for ArgoCD.
+type ArgoCDCertManagerSpec struct {
+	// IssuerRef is the reference to the cert-manager Issuer or ClusterIssuer of the certificates.
+	IssuerRef ArgoCDCertManagerIssuerReference `json:"issuerRef"`
+}
+
+// ArgoCDCertManagerIssuerReference is a reference to a cert-manager issuer.
+type ArgoCDCertManagerIssuerReference struct {
+	// Name is the name of the issuer.
+	Name string `json:"name"`
+
+	// Kind is the kind of the issuer, Issuer or ClusterIssuer. (optional, default `Issuer`)
+	//+kubebuilder:validation:Enum=Issuer;ClusterIssuer
+	Kind string `json:"kind,omitempty"`
+
+	// Group is the API group of the issuer, for external issuers. (optional, default `cert-manager.io`)
+	Group string `json:"group,omitempty"`
+}
+
 // ArgoCDCertificateSpec defines the options for the ArgoCD certificates.
 type ArgoCDCertificateSpec struct {
 	// SecretName is the name of the Secret containing the Certificate and Key.
@@ -235,6 +254,33 @@ type ArgoCDGrafanaSpec struct {
 	Version string `json:"version,omitempty"`
 }
 
+// ArgoCDGatewaySpec defines the desired state for a Gateway API route, attached to an existing Gateway.
+type ArgoCDGatewaySpec struct {
+	// Annotations is the map of annotations to apply to the route.
+	Annotations map[string]string `json:"annotations,omitempty"`
+
+	// Enabled will toggle the creation of the Gateway API route.
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Gateway Enabled'",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:fieldGroup:Server","urn:alm:descriptor:com.tectonic.ui:booleanSwitch"}
+	Enabled bool `json:"enabled"`
+
+	// GatewayName is the name of the Gateway the route is attached to.
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Gateway Name",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:fieldGroup:Server","urn:alm:descriptor:com.tectonic.ui:text"}
+	GatewayName string `json:"gatewayName,omitempty"`
+
+	// GatewayNamespace is the namespace of the Gateway the route is attached to, defaults to the same namespace as the ArgoCD.
+	GatewayNamespace string `json:"gatewayNamespace,omitempty"`
+
+	// Labels is the map of labels to apply to the route.
+	Labels map[string]string `json:"labels,omitempty"`
+
+	// Path is the path prefix matched by the HTTPRoute, defaults to "/". It is not used for GRPCRoutes.
+	Path string `json:"path,omitempty"`
+
+	// SectionName is the name of the listener of the Gateway the route is attached to, all the listeners of the
+	// Gateway if not set.
+	SectionName string `json:"sectionName,omitempty"`
+}
+
 // ArgoCDHASpec defines the desired state for High Availability support for Argo CD.
 type ArgoCDHASpec struct {
 	// Enabled will toggle HA support globally for Argo CD.
@@ -253,6 +299,11 @@ type ArgoCDHASpec struct {
 
 // ArgoCDImportSpec defines the desired state for the ArgoCD import/restore process.
 type ArgoCDImportSpec struct {
+	// BackupID is the ID of the backup to import, as listed in the restore points of the ArgoCDExport status.
+	// The most recent backup is imported if not set.
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Backup ID",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:fieldGroup:Import","urn:alm:descriptor:com.tectonic.ui:text"}
+	BackupID string `json:"backupID,omitempty"`
+
 	// Name of an ArgoCDExport from which to import data.
 	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Name",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:fieldGroup:Import","urn:alm:descriptor:com.tectonic.ui:text"}
 	Name string `json:"name"`
@@ -262,6 +313,41 @@ type ArgoCDImportSpec struct {
 	Namespace *string `json:"namespace,omitempty"`
 }
 
+// ArgoCDImportStatus defines the observed state of the ArgoCD import/restore process.
+type ArgoCDImportStatus struct {
+	// Name of the ArgoCDExport the data is imported from.
+	Name string `json:"name"`
+
+	// Namespace of the ArgoCDExport the data is imported from.
+	Namespace string `json:"namespace"`
+
+	// RequestedBackupID is the ID of the backup requested by the Import options, empty for the most recent backup.
+	RequestedBackupID string `json:"requestedBackupID,omitempty"`
+
+	// BackupID is the ID of the imported backup, as reported by the import Job once it completed.
+	BackupID string `json:"backupID,omitempty"`
+
+	// JobName is the name of the Job performing the import.
+	JobName string `json:"jobName,omitempty"`
+
+	// Phase is a simple, high-level summary of where the import is in its lifecycle.
+	// There are four possible phase values:
+	// Pending: The import is waiting for the ArgoCDExport to be found.
+	// Running: The import Job is running, the application controller is stopped until it completes.
+	// Completed: The backup has been imported.
+	// Failed: The import Job failed, it is retried once the Job is deleted.
+	Phase string `json:"phase,omitempty"`
+
+	// StartTime is the time at which the import Job started.
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+
+	// CompletionTime is the time at which the import Job completed or failed.
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+
+	// Message is a human readable description of the phase.
+	Message string `json:"message,omitempty"`
+}
+
 // ArgoCDIngressSpec defines the desired state for the Ingress resources.
 type ArgoCDIngressSpec struct {
 	// Annotations is the map of annotations to apply to the Ingress.
@@ -406,6 +492,7 @@ type ArgoCDRedisSpec struct {
 	// AutoTLS specifies the method to use for automatic TLS configuration for the redis server
 	// The value specified here can currently be:
 	// - openshift - Use the OpenShift service CA to request TLS config
+	// - cert-manager - Use a cert-manager Certificate issued by the issuer of the TLS options
 	AutoTLS string `json:"autotls,omitempty"`
 }
 
@@ -442,6 +529,7 @@ type ArgoCDRepoSpec struct {
 	// AutoTLS specifies the method to use for automatic TLS configuration for the repo server
 	// The value specified here can currently be:
 	// - openshift - Use the OpenShift service CA to request TLS config
+	// - cert-manager - Use a cert-manager Certificate issued by the issuer of the TLS options
 	AutoTLS string `json:"autotls,omitempty"`
 
 	// Image is the ArgoCD Repo Server container image.
@@ -503,6 +591,9 @@ type ArgoCDServerAutoscaleSpec struct {
 
 // ArgoCDServerGRPCSpec defines the desired state for the Argo CD Server GRPC options.
 type ArgoCDServerGRPCSpec struct {
+	// Gateway defines the desired state for a Gateway API GRPCRoute for the Argo CD Server GRPC options.
+	Gateway ArgoCDGatewaySpec `json:"gateway,omitempty"`
+
 	// Host is the hostname to use for Ingress/Route resources.
 	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="GRPC Host",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:fieldGroup:Server","urn:alm:descriptor:com.tectonic.ui:text"}
 	Host string `json:"host,omitempty"`
@@ -517,6 +608,14 @@ type ArgoCDServerSpec struct {
 	// Autoscale defines the autoscale options for the Argo CD Server component.
 	Autoscale ArgoCDServerAutoscaleSpec `json:"autoscale,omitempty"`
 
+	// AutoTLS specifies the method to use for automatic TLS configuration for the Argo CD Server component
+	// The value specified here can currently be:
+	// - cert-manager - Use a cert-manager Certificate issued by the issuer of the TLS options
+	AutoTLS string `json:"autotls,omitempty"`
+
+	// Gateway defines the desired state for a Gateway API HTTPRoute for the Argo CD Server component.
+	Gateway ArgoCDGatewaySpec `json:"gateway,omitempty"`
+
 	// GRPC defines the state for the Argo CD Server GRPC options.
 	GRPC ArgoCDServerGRPCSpec `json:"grpc,omitempty"`
 
@@ -653,12 +752,22 @@ type ArgoCDMonitoringSpec struct {
 	Enabled bool `json:"enabled"`
 }
 
-// ArgoCDNodePlacementSpec is used to specify NodeSelector and Tolerations for Argo CD workloads
+// ArgoCDNodePlacementSpec is used to specify NodeSelector, Tolerations and the other scheduling options for Argo CD workloads
 type ArgoCDNodePlacementSpec struct {
 	// NodeSelector is a field of PodSpec, it is a map of key value pairs used for node selection
 	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
 	// Tolerations allow the pods to schedule onto nodes with matching taints
 	Tolerations []corev1.Toleration `json:"tolerations,omitempty"`
+	// Affinity defines the scheduling constraints of the pods. The node affinity, pod affinity and pod anti-affinity
+	// replace the defaults of the component separately.
+	Affinity *corev1.Affinity `json:"affinity,omitempty"`
+	// TopologySpreadConstraints describes how the pods are spread across topology domains. The pods of the component
+	// are selected when a constraint has no label selector.
+	TopologySpreadConstraints []corev1.TopologySpreadConstraint `json:"topologySpreadConstraints,omitempty"`
+	// PriorityClassName is the name of the PriorityClass of the pods
+	PriorityClassName string `json:"priorityClassName,omitempty"`
+	// RuntimeClassName is the name of the RuntimeClass used to run the pods
+	RuntimeClassName *string `json:"runtimeClassName,omitempty"`
 }
 
 // ArgoCDSpec defines the desired state of ArgoCD
@@ -716,6 +825,20 @@ type ArgoCDSpec struct {
 	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Image",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:fieldGroup:ArgoCD","urn:alm:descriptor:com.tectonic.ui:text"}
 	Image string `json:"image,omitempty"`
 
+	// ImagePullPolicy is the pull policy of the containers of all ArgoCD components. Defaults to Always for the Argo CD
+	// images and IfNotPresent for the Redis HA images.
+	//+kubebuilder:validation:Enum=Always;Never;IfNotPresent
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Image Pull Policy",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:fieldGroup:ArgoCD","urn:alm:descriptor:com.tectonic.ui:imagePullPolicy"}
+	ImagePullPolicy corev1.PullPolicy `json:"imagePullPolicy,omitempty"`
+
+	// ImagePullSecrets are the secrets used to pull the images of all ArgoCD components.
+	ImagePullSecrets []corev1.LocalObjectReference `json:"imagePullSecrets,omitempty"`
+
+	// ImageRegistry is the registry, optionally followed by a path, replacing the registry of the images of all ArgoCD
+	// components, such as a mirror of the public registries. The repository path of the images is kept.
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Image Registry",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:fieldGroup:ArgoCD","urn:alm:descriptor:com.tectonic.ui:text"}
+	ImageRegistry string `json:"imageRegistry,omitempty"`
+
 	// Import is the import/restore options for ArgoCD.
 	Import *ArgoCDImportSpec `json:"import,omitempty"`
 
@@ -904,8 +1027,34 @@ type ArgoCDStatus struct {
 	// RedisTLSChecksum contains the SHA256 checksum of the latest known state of tls.crt and tls.key in the argocd-operator-redis-tls secret.
 	RedisTLSChecksum string `json:"redisTLSChecksum,omitempty"`
 
+	// RedisAuthChecksum contains the SHA256 checksum of the latest known Redis password.
+	RedisAuthChecksum string `json:"redisAuthChecksum,omitempty"`
+
 	// Host is the hostname of the Ingress.
 	Host string `json:"host,omitempty"`
+
+	// TLS is the expiry of the CA and TLS certificates generated by the operator.
+	//+operator-sdk:csv:customresourcedefinitions:type=status,displayName="TLS"
+	TLS *ArgoCDTLSStatus `json:"tls,omitempty"`
+
+	// Import is the progress of the import/restore requested by the Import options. It is kept once the import
+	// completed, as a record of the restored backup.
+	//+operator-sdk:csv:customresourcedefinitions:type=status,displayName="Import"
+	Import *ArgoCDImportStatus `json:"import,omitempty"`
+
+	// Conditions describe the current state of the Argo CD instance. They complement the
+	// per-component summaries above with machine readable reasons and the errors that
+	// prevented reconciliation, if any.
+	// +optional
+	// +patchMergeKey=type
+	// +patchStrategy=merge
+	// +listType=map
+	// +listMapKey=type
+	//+operator-sdk:csv:customresourcedefinitions:type=status,displayName="Conditions",xDescriptors={"urn:alm:descriptor:io.kubernetes.conditions"}
+	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type"`
+
+	// ObservedGeneration is the most recent generation of the ArgoCD spec that has been reconciled.
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
 }
 
 // Banner defines an additional banner message to be displayed in Argo CD UI
@@ -922,8 +1071,24 @@ type ArgoCDTLSSpec struct {
 	// CA defines the CA options.
 	CA ArgoCDCASpec `json:"ca,omitempty"`
 
+	// CertManager defines the cert-manager options, used by the components with the cert-manager autotls provider.
+	CertManager *ArgoCDCertManagerSpec `json:"certManager,omitempty"`
+
 	// InitialCerts defines custom TLS certificates upon creation of the cluster for connecting Git repositories via HTTPS.
 	InitialCerts map[string]string `json:"initialCerts,omitempty"`
+
+	// RenewBefore is the time before their expiry at which the CA and TLS certificates generated by the operator are
+	// renewed. (optional, default `720h`)
+	RenewBefore *metav1.Duration `json:"renewBefore,omitempty"`
+}
+
+// ArgoCDTLSStatus defines the observed state of the CA and TLS certificates generated by the operator.
+type ArgoCDTLSStatus struct {
+	// CANotAfter is the expiry time of the CA certificate.
+	CANotAfter *metav1.Time `json:"caNotAfter,omitempty"`
+
+	// NotAfter is the expiry time of the TLS certificate of the Argo CD server.
+	NotAfter *metav1.Time `json:"notAfter,omitempty"`
 }
 
 type SSHHostsSpec struct {
@@ -939,6 +1104,9 @@ type SSHHostsSpec struct {
 // WebhookServerSpec defines the options for the ApplicationSet Webhook Server component.
 type WebhookServerSpec struct {
 
+	// Gateway defines the desired state for a Gateway API HTTPRoute for the Application set webhook component.
+	Gateway ArgoCDGatewaySpec `json:"gateway,omitempty"`
+
 	// Host is the hostname to use for Ingress/Route resources.
 	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Host",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:fieldGroup:Server","urn:alm:descriptor:com.tectonic.ui:text"}
 	Host string `json:"host,omitempty"`
@@ -961,9 +1129,15 @@ func (argocd *ArgoCD) IsDeletionFinalizerPresent() bool {
 }
 
 // WantsAutoTLS returns true if user configured a route with reencryption
-// termination policy.
+// termination policy, and the server certificate is not issued by cert-manager.
 func (s *ArgoCDServerSpec) WantsAutoTLS() bool {
-	return s.Route.TLS != nil && s.Route.TLS.Termination == routev1.TLSTerminationReencrypt
+	return !s.WantsCertManagerTLS() && s.Route.TLS != nil && s.Route.TLS.Termination == routev1.TLSTerminationReencrypt
+}
+
+// WantsCertManagerTLS returns true if the server configuration has set the
+// autoTLS toggle to cert-manager.
+func (s *ArgoCDServerSpec) WantsCertManagerTLS() bool {
+	return s.AutoTLS == common.ArgoCDAutoTLSCertManager
 }
 
 // WantsAutoTLS returns true if the repository server configuration has set
@@ -972,12 +1146,24 @@ func (r *ArgoCDRepoSpec) WantsAutoTLS() bool {
 	return r.AutoTLS == "openshift"
 }
 
+// WantsCertManagerTLS returns true if the repository server configuration has set
+// the autoTLS toggle to cert-manager.
+func (r *ArgoCDRepoSpec) WantsCertManagerTLS() bool {
+	return r.AutoTLS == common.ArgoCDAutoTLSCertManager
+}
+
 // WantsAutoTLS returns true if the redis server configuration has set
 // the autoTLS toggle to a supported provider.
 func (r *ArgoCDRedisSpec) WantsAutoTLS() bool {
 	return r.AutoTLS == "openshift"
 }
 
+// WantsCertManagerTLS returns true if the redis server configuration has set
+// the autoTLS toggle to cert-manager.
+func (r *ArgoCDRedisSpec) WantsCertManagerTLS() bool {
+	return r.AutoTLS == common.ArgoCDAutoTLSCertManager
+}
+
 // ApplicationInstanceLabelKey returns either the custom application instance
 // label key if set, or the default value.
 func (a *ArgoCD) ApplicationInstanceLabelKey() string {
@@ -17,6 +17,7 @@ limitations under the License.
 package v1alpha1
 
 import (
+	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
@@ -41,9 +42,12 @@ import (
 // +operator-sdk:csv:customresourcedefinitions:resources={{Pod,v1,""}}
 // +operator-sdk:csv:customresourcedefinitions:resources={{Prometheus,v1,""}}
 // +operator-sdk:csv:customresourcedefinitions:resources={{ReplicaSet,v1,""}}
+// +operator-sdk:csv:customresourcedefinitions:resources={{Role,v1,""}}
+// +operator-sdk:csv:customresourcedefinitions:resources={{RoleBinding,v1,""}}
 // +operator-sdk:csv:customresourcedefinitions:resources={{Route,v1,""}}
 // +operator-sdk:csv:customresourcedefinitions:resources={{Secret,v1,""}}
 // +operator-sdk:csv:customresourcedefinitions:resources={{Service,v1,""}}
+// +operator-sdk:csv:customresourcedefinitions:resources={{ServiceAccount,v1,""}}
 // +operator-sdk:csv:customresourcedefinitions:resources={{ServiceMonitor,v1,""}}
 // +operator-sdk:csv:customresourcedefinitions:resources={{StatefulSet,v1,""}}
 type ArgoCDExport struct {
@@ -70,9 +74,39 @@ type ArgoCDExportSpec struct {
 	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="ArgoCD",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:text"}
 	Argocd string `json:"argocd"`
 
+	// ArgocdNamespace is the namespace of the ArgoCD instance to export, defaults to the namespace of the
+	// ArgoCDExport. The export Job always runs in the namespace of the ArgoCDExport.
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="ArgoCD Namespace",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:text"}
+	ArgocdNamespace *string `json:"argocdNamespace,omitempty"`
+
+	// ConcurrencyPolicy specifies how to treat concurrent runs of a scheduled export, must be "Allow" (the default),
+	// "Forbid" or "Replace". It is only used when Schedule is set.
+	// +kubebuilder:validation:Enum=Allow;Forbid;Replace
+	ConcurrencyPolicy batchv1.ConcurrencyPolicy `json:"concurrencyPolicy,omitempty"`
+
+	// Encryption defines the key the backups are encrypted with, a key is generated and stored in the export Secret
+	// if not set. When the key changes, a new export is run and the retained backups are re-encrypted with the new key.
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Encryption"
+	Encryption *ArgoCDExportEncryptionSpec `json:"encryption,omitempty"`
+
+	// FailedJobsHistoryLimit is the number of failed runs of a scheduled export to keep, defaults to 1. It is only
+	// used when Schedule is set.
+	// +kubebuilder:validation:Minimum=0
+	FailedJobsHistoryLimit *int32 `json:"failedJobsHistoryLimit,omitempty"`
+
 	// Image is the container image to use for the export Job.
 	Image string `json:"image,omitempty"`
 
+	// NodePlacement defines the nodeSelector and tolerations of the export Pods.
+	NodePlacement *ArgoCDNodePlacementSpec `json:"nodePlacement,omitempty"`
+
+	// Resources defines the Compute Resources required by the export container.
+	Resources *corev1.ResourceRequirements `json:"resources,omitempty"`
+
+	// Retention defines which backups are kept in the storage backend, every backup is kept if not set.
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Retention"
+	Retention *ArgoCDExportRetentionSpec `json:"retention,omitempty"`
+
 	// Schedule in Cron format, see https://en.wikipedia.org/wiki/Cron.
 	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Schedule",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:text"}
 	Schedule *string `json:"schedule,omitempty"`
@@ -81,6 +115,16 @@ type ArgoCDExportSpec struct {
 	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Storage"
 	Storage *ArgoCDExportStorageSpec `json:"storage,omitempty"`
 
+	// SuccessfulJobsHistoryLimit is the number of successful runs of a scheduled export to keep, defaults to 3. It is
+	// only used when Schedule is set.
+	// +kubebuilder:validation:Minimum=0
+	SuccessfulJobsHistoryLimit *int32 `json:"successfulJobsHistoryLimit,omitempty"`
+
+	// Suspend suspends subsequent runs of a scheduled export, runs that already started are not affected. It is only
+	// used when Schedule is set.
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Suspend",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:booleanSwitch"}
+	Suspend *bool `json:"suspend,omitempty"`
+
 	// Version is the tag/digest to use for the export Job container image.
 	Version string `json:"version,omitempty"`
 }
@@ -97,20 +141,186 @@ type ArgoCDExportStatus struct {
 	// Unknown: For some reason the state of the ArgoCDExport could not be obtained.
 	//+operator-sdk:csv:customresourcedefinitions:type=status,displayName="Phase",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:text"}
 	Phase string `json:"phase"`
+
+	// Conditions describe the current state of the ArgoCDExport, including the resolution of the
+	// referenced Argo CD instance and the outcome of the last export run.
+	// +optional
+	// +patchMergeKey=type
+	// +patchStrategy=merge
+	// +listType=map
+	// +listMapKey=type
+	//+operator-sdk:csv:customresourcedefinitions:type=status,displayName="Conditions",xDescriptors={"urn:alm:descriptor:io.kubernetes.conditions"}
+	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type"`
+
+	// LastRunStartTime is the time the last export run was started.
+	LastRunStartTime *metav1.Time `json:"lastRunStartTime,omitempty"`
+
+	// LastRunCompletionTime is the time the last export run finished, either successfully or not.
+	LastRunCompletionTime *metav1.Time `json:"lastRunCompletionTime,omitempty"`
+
+	// LastSuccessfulRunTime is the time the last successful export run finished.
+	LastSuccessfulRunTime *metav1.Time `json:"lastSuccessfulRunTime,omitempty"`
+
+	// KeyID identifies the current backup key. Restore points with a different key ID are re-encrypted by the next
+	// export run.
+	KeyID string `json:"keyID,omitempty"`
+
+	// LastBackupID is the ID of the backup written by the last successful export run.
+	LastBackupID string `json:"lastBackupID,omitempty"`
+
+	// LastBackupLocation is the location of the backup written by the last successful export run.
+	//+operator-sdk:csv:customresourcedefinitions:type=status,displayName="Last Backup Location",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:text"}
+	LastBackupLocation string `json:"lastBackupLocation,omitempty"`
+
+	// ObservedGeneration is the most recent generation of the ArgoCDExport spec that has been reconciled.
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// RestorePoints are the backups available in the storage backend after the last successful export run,
+	// most recent first. Their ID can be set as spec.import.backupID on an ArgoCD to restore a specific backup.
+	//+operator-sdk:csv:customresourcedefinitions:type=status,displayName="Restore Points"
+	RestorePoints []ArgoCDExportRestorePoint `json:"restorePoints,omitempty"`
+}
+
+// ArgoCDExportRestorePoint describes a backup available for import.
+type ArgoCDExportRestorePoint struct {
+	// BackupID is the ID of the backup, the UTC time at which it was taken formatted as 20060102T150405Z.
+	BackupID string `json:"backupID"`
+
+	// CreationTime is the time at which the backup was taken.
+	CreationTime metav1.Time `json:"creationTime"`
+
+	// KeyID identifies the key the backup is encrypted with, it is empty if unknown.
+	KeyID string `json:"keyID,omitempty"`
+
+	// Location is the location of the backup in the storage backend.
+	Location string `json:"location,omitempty"`
 }
 
+// Condition types reported in ArgoCDExportStatus.Conditions.
+const (
+	// ArgoCDExportConditionArgoCDResolved indicates whether the Argo CD instance to export could be resolved.
+	ArgoCDExportConditionArgoCDResolved = "ArgoCDResolved"
+
+	// ArgoCDExportConditionEncryptionKeyResolved indicates whether the backup key could be resolved.
+	ArgoCDExportConditionEncryptionKeyResolved = "EncryptionKeyResolved"
+
+	// ArgoCDExportConditionComplete indicates that the last export run completed successfully.
+	ArgoCDExportConditionComplete = "Complete"
+
+	// ArgoCDExportConditionFailed indicates that the last export run failed.
+	ArgoCDExportConditionFailed = "Failed"
+)
+
+// Condition reasons reported in ArgoCDExportStatus.Conditions.
+const (
+	ArgoCDExportReasonArgoCDFound     = "ArgoCDFound"
+	ArgoCDExportReasonArgoCDNotFound  = "ArgoCDNotFound"
+	ArgoCDExportReasonAmbiguousArgoCD = "AmbiguousArgoCD"
+	ArgoCDExportReasonKeyFound        = "KeyFound"
+	ArgoCDExportReasonKeyNotFound     = "KeyNotFound"
+	ArgoCDExportReasonKeyInvalid      = "KeyInvalid"
+	ArgoCDExportReasonExportPending   = "ExportPending"
+	ArgoCDExportReasonExportRunning   = "ExportRunning"
+	ArgoCDExportReasonExportSucceeded = "ExportSucceeded"
+	ArgoCDExportReasonExportFailed    = "ExportFailed"
+
+	// ArgoCDExportReasonUnsupportedByImage is reported when the export image cannot honour the ArgoCDExport options.
+	ArgoCDExportReasonUnsupportedByImage = "UnsupportedByImage"
+)
+
 // ArgoCDExportStorageSpec defines the desired state for ArgoCDExport storage options.
 type ArgoCDExportStorageSpec struct {
-	// Backend defines the storage backend to use, must be "local" (the default), "aws", "azure" or "gcp".
+	// Backend defines the storage backend to use, must be "local" (the default), "aws", "azure", "gcp" or "s3".
 	Backend string `json:"backend,omitempty"`
 
 	// PVC is the desired characteristics for a PersistentVolumeClaim.
 	PVC *corev1.PersistentVolumeClaimSpec `json:"pvc,omitempty"`
 
+	// S3 configures the "s3" backend, which stores backups in any S3 compatible object store such as MinIO or Ceph RGW.
+	S3 *ArgoCDExportS3Spec `json:"s3,omitempty"`
+
 	// SecretName is the name of a Secret with encryption key, credentials, etc.
 	SecretName string `json:"secretName,omitempty"`
 }
 
+// ArgoCDExportEncryptionSpec defines the key the backups are encrypted with. At most one of KeySecretRef and KMS
+// may be set.
+// +k8s:openapi-gen=true
+type ArgoCDExportEncryptionSpec struct {
+	// KeySecretRef selects the key of a Secret, in the namespace of the ArgoCDExport, that holds the backup key. The
+	// key must be at least 16 characters long.
+	KeySecretRef *corev1.SecretKeySelector `json:"keySecretRef,omitempty"`
+
+	// KMS configures envelope encryption with a key managed by AWS KMS, or a compatible service: every backup is
+	// encrypted with a new data key, which is stored with the backup wrapped by the KMS key.
+	KMS *ArgoCDExportKMSSpec `json:"kms,omitempty"`
+}
+
+// ArgoCDExportKMSSpec defines the KMS key used for the envelope encryption of backups. The credentials are read
+// from the kms.access.key.id and kms.secret.access.key keys of the export Secret, or from the credentials of the
+// "aws" and "s3" storage backends.
+// +k8s:openapi-gen=true
+type ArgoCDExportKMSSpec struct {
+	// KeyID is the ID, ARN or alias of the KMS key.
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:MaxLength=251
+	KeyID string `json:"keyID"`
+
+	// Region is the region of the KMS key, defaults to us-east-1.
+	Region string `json:"region,omitempty"`
+
+	// Endpoint is the URL of the KMS API, defaults to the AWS KMS endpoint of the region.
+	Endpoint string `json:"endpoint,omitempty"`
+}
+
+// ArgoCDExportRetentionSpec defines which backups are kept in the storage backend after each export. A backup
+// is pruned as soon as it falls outside either limit, but the most recent backup is always kept.
+// +k8s:openapi-gen=true
+type ArgoCDExportRetentionSpec struct {
+	// KeepLast is the number of most recent backups to keep.
+	// +kubebuilder:validation:Minimum=1
+	KeepLast *int32 `json:"keepLast,omitempty"`
+
+	// MaxAge is the maximum age of the backups to keep, e.g. 720h.
+	MaxAge *metav1.Duration `json:"maxAge,omitempty"`
+}
+
+// ArgoCDExportS3Spec defines the options for the S3 compatible storage backend.
+// +k8s:openapi-gen=true
+type ArgoCDExportS3Spec struct {
+	// Endpoint is the URL of the S3 API, e.g. https://minio.example.com:9000.
+	Endpoint string `json:"endpoint"`
+
+	// Bucket is the name of the bucket to store backups in. The bucket is created if it does not exist.
+	Bucket string `json:"bucket"`
+
+	// Region is the region of the bucket, defaults to us-east-1.
+	Region string `json:"region,omitempty"`
+
+	// ForcePathStyle addresses the bucket as part of the URL path instead of the host name, as required by
+	// most MinIO and Ceph RGW deployments.
+	ForcePathStyle bool `json:"forcePathStyle,omitempty"`
+
+	// CABundle references a ConfigMap key holding the PEM encoded CA certificates used to verify the endpoint,
+	// in addition to the system CAs. The ConfigMap must exist in the namespace of the export Job and of the
+	// Argo CD instance importing the backup.
+	CABundle *corev1.ConfigMapKeySelector `json:"caBundle,omitempty"`
+
+	// ServerSideEncryption configures the server-side encryption requested for uploaded backups.
+	ServerSideEncryption *ArgoCDExportS3SSESpec `json:"serverSideEncryption,omitempty"`
+}
+
+// ArgoCDExportS3SSESpec defines the server-side encryption of backups stored with the S3 compatible backend.
+// +k8s:openapi-gen=true
+type ArgoCDExportS3SSESpec struct {
+	// Type is the server-side encryption algorithm, must be "AES256" or "aws:kms".
+	// +kubebuilder:validation:Enum=AES256;"aws:kms"
+	Type string `json:"type"`
+
+	// KMSKeyID is the ID of the KMS key used when Type is "aws:kms". The default key of the store is used if empty.
+	KMSKeyID string `json:"kmsKeyID,omitempty"`
+}
+
 func init() {
 	SchemeBuilder.Register(&ArgoCDExport{}, &ArgoCDExportList{})
 }
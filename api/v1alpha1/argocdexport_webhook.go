@@ -0,0 +1,212 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"net/url"
+	"strings"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"github.com/argoproj-labs/argocd-operator/common"
+)
+
+var argocdexportlog = logf.Log.WithName("argocdexport-resource")
+
+func (r *ArgoCDExport) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(r).
+		Complete()
+}
+
+//+kubebuilder:webhook:path=/validate-argoproj-io-v1alpha1-argocdexport,mutating=false,failurePolicy=fail,sideEffects=None,groups=argoproj.io,resources=argocdexports,verbs=create;update,versions=v1alpha1,name=vargocdexport.kb.io,admissionReviewVersions=v1
+
+var _ webhook.Validator = &ArgoCDExport{}
+
+// ValidateCreate implements webhook.Validator so a webhook will be registered for the type.
+func (r *ArgoCDExport) ValidateCreate() (admission.Warnings, error) {
+	argocdexportlog.Info("validate create", "name", r.Name, "namespace", r.Namespace)
+	return r.validateArgoCDExport()
+}
+
+// ValidateUpdate implements webhook.Validator so a webhook will be registered for the type.
+func (r *ArgoCDExport) ValidateUpdate(old runtime.Object) (admission.Warnings, error) {
+	argocdexportlog.Info("validate update", "name", r.Name, "namespace", r.Namespace)
+	if r.DeletionTimestamp != nil {
+		return nil, nil
+	}
+	return r.validateArgoCDExport()
+}
+
+// ValidateDelete implements webhook.Validator so a webhook will be registered for the type.
+func (r *ArgoCDExport) ValidateDelete() (admission.Warnings, error) {
+	return nil, nil
+}
+
+// validateArgoCDExport returns an Invalid error listing every problem found in the spec.
+func (r *ArgoCDExport) validateArgoCDExport() (admission.Warnings, error) {
+	var allErrs field.ErrorList
+	var warnings admission.Warnings
+	specPath := field.NewPath("spec")
+
+	if len(r.Spec.Argocd) == 0 {
+		allErrs = append(allErrs, field.Required(specPath.Child("argocd"), "name of the Argo CD instance to export is required"))
+	}
+
+	if r.Spec.Schedule != nil && len(*r.Spec.Schedule) > 0 && !isValidSchedule(*r.Spec.Schedule) {
+		allErrs = append(allErrs, field.Invalid(specPath.Child("schedule"), *r.Spec.Schedule,
+			"must be a standard cron expression with five fields or a predefined schedule such as @daily"))
+	}
+
+	if r.Spec.Encryption != nil {
+		allErrs = append(allErrs, validateEncryptionSpec(r.Spec.Encryption, specPath.Child("encryption"))...)
+	}
+
+	if r.Spec.Retention != nil {
+		retentionPath := specPath.Child("retention")
+		if r.Spec.Retention.KeepLast != nil && *r.Spec.Retention.KeepLast < 1 {
+			allErrs = append(allErrs, field.Invalid(retentionPath.Child("keepLast"), *r.Spec.Retention.KeepLast, "must be greater than or equal to 1"))
+		}
+		if r.Spec.Retention.MaxAge != nil && r.Spec.Retention.MaxAge.Duration <= 0 {
+			allErrs = append(allErrs, field.Invalid(retentionPath.Child("maxAge"), r.Spec.Retention.MaxAge.Duration.String(), "must be a positive duration"))
+		}
+	}
+
+	if r.Spec.Storage != nil {
+		storagePath := specPath.Child("storage")
+		backend := strings.ToLower(r.Spec.Storage.Backend)
+		supported := []string{
+			common.ArgoCDExportStorageBackendLocal,
+			common.ArgoCDExportStorageBackendAWS,
+			common.ArgoCDExportStorageBackendAzure,
+			common.ArgoCDExportStorageBackendGCP,
+			common.ArgoCDExportStorageBackendS3,
+		}
+		if len(backend) > 0 && !containsString(supported, backend) {
+			allErrs = append(allErrs, field.NotSupported(storagePath.Child("backend"), r.Spec.Storage.Backend, supported))
+		}
+		if backend == common.ArgoCDExportStorageBackendS3 {
+			allErrs = append(allErrs, validateS3Spec(r.Spec.Storage.S3, storagePath.Child("s3"))...)
+		} else if r.Spec.Storage.S3 != nil {
+			warnings = append(warnings, "spec.storage.s3 is ignored when the storage backend is not s3")
+		}
+		if r.Spec.Storage.PVC != nil && len(backend) > 0 && backend != common.ArgoCDExportStorageBackendLocal {
+			warnings = append(warnings, "spec.storage.pvc is ignored when the storage backend is not local")
+		}
+	}
+
+	if len(allErrs) > 0 {
+		return warnings, apierrors.NewInvalid(GroupVersion.WithKind("ArgoCDExport").GroupKind(), r.Name, allErrs)
+	}
+	return warnings, nil
+}
+
+// validateS3Spec validates the options of the S3 compatible storage backend.
+func validateS3Spec(spec *ArgoCDExportS3Spec, path *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+
+	if spec == nil {
+		return append(allErrs, field.Required(path, "s3 options are required when the storage backend is s3"))
+	}
+
+	if len(spec.Endpoint) == 0 {
+		allErrs = append(allErrs, field.Required(path.Child("endpoint"), "endpoint of the S3 API is required"))
+	} else if u, err := url.Parse(spec.Endpoint); err != nil || (u.Scheme != "http" && u.Scheme != "https") || len(u.Host) == 0 {
+		allErrs = append(allErrs, field.Invalid(path.Child("endpoint"), spec.Endpoint, "must be an http or https URL"))
+	}
+
+	if len(spec.Bucket) == 0 {
+		allErrs = append(allErrs, field.Required(path.Child("bucket"), "name of the bucket is required"))
+	}
+
+	if spec.CABundle != nil && (len(spec.CABundle.Name) == 0 || len(spec.CABundle.Key) == 0) {
+		allErrs = append(allErrs, field.Required(path.Child("caBundle"), "name and key of the CA bundle ConfigMap are required"))
+	}
+
+	if sse := spec.ServerSideEncryption; sse != nil {
+		ssePath := path.Child("serverSideEncryption")
+		supported := []string{common.ArgoCDExportS3SSEAES256, common.ArgoCDExportS3SSEKMS}
+		if !containsString(supported, sse.Type) {
+			allErrs = append(allErrs, field.NotSupported(ssePath.Child("type"), sse.Type, supported))
+		}
+		if len(sse.KMSKeyID) > 0 && sse.Type != common.ArgoCDExportS3SSEKMS {
+			allErrs = append(allErrs, field.Forbidden(ssePath.Child("kmsKeyID"), "kmsKeyID may only be set when type is aws:kms"))
+		}
+	}
+
+	return allErrs
+}
+
+// validateEncryptionSpec validates the backup key options.
+func validateEncryptionSpec(spec *ArgoCDExportEncryptionSpec, path *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+
+	if spec.KeySecretRef != nil && spec.KMS != nil {
+		allErrs = append(allErrs, field.Forbidden(path, "keySecretRef and kms are mutually exclusive"))
+	}
+
+	if spec.KeySecretRef != nil && (len(spec.KeySecretRef.Name) == 0 || len(spec.KeySecretRef.Key) == 0) {
+		allErrs = append(allErrs, field.Required(path.Child("keySecretRef"), "name and key of the backup key Secret are required"))
+	}
+
+	if kms := spec.KMS; kms != nil {
+		kmsPath := path.Child("kms")
+		if len(kms.KeyID) == 0 {
+			allErrs = append(allErrs, field.Required(kmsPath.Child("keyID"), "ID of the KMS key is required"))
+		} else if len(common.ArgoCDExportKMSKeyIDPrefix+kms.KeyID) > 255 {
+			allErrs = append(allErrs, field.TooLong(kmsPath.Child("keyID"), kms.KeyID, 255-len(common.ArgoCDExportKMSKeyIDPrefix)))
+		}
+		if len(kms.Endpoint) > 0 {
+			if u, err := url.Parse(kms.Endpoint); err != nil || (u.Scheme != "http" && u.Scheme != "https") || len(u.Host) == 0 {
+				allErrs = append(allErrs, field.Invalid(kmsPath.Child("endpoint"), kms.Endpoint, "must be an http or https URL"))
+			}
+		}
+	}
+
+	return allErrs
+}
+
+// isValidSchedule performs a shallow check of a CronJob schedule, the CronJob API performs the full validation.
+func isValidSchedule(schedule string) bool {
+	if strings.HasPrefix(schedule, "@") {
+		switch schedule {
+		case "@yearly", "@annually", "@monthly", "@weekly", "@daily", "@midnight", "@hourly":
+			return true
+		}
+		return false
+	}
+	fields := strings.Fields(schedule)
+	if strings.HasPrefix(schedule, "TZ=") || strings.HasPrefix(schedule, "CRON_TZ=") {
+		fields = fields[1:]
+	}
+	return len(fields) == 5
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
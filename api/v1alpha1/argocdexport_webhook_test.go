@@ -0,0 +1,216 @@
+package v1alpha1
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func makeTestArgoCDExportForWebhook(opts ...func(*ArgoCDExport)) *ArgoCDExport {
+	a := &ArgoCDExport{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "export",
+			Namespace: "argocd",
+		},
+		Spec: ArgoCDExportSpec{
+			Argocd: "argocd",
+		},
+	}
+	for _, o := range opts {
+		o(a)
+	}
+	return a
+}
+
+func TestArgoCDExport_ValidateCreate(t *testing.T) {
+	schedule := func(s string) func(*ArgoCDExport) {
+		return func(a *ArgoCDExport) {
+			a.Spec.Schedule = &s
+		}
+	}
+
+	tests := []struct {
+		name       string
+		export     *ArgoCDExport
+		wantFields []string
+	}{
+		{
+			name:   "minimal spec is valid",
+			export: makeTestArgoCDExportForWebhook(),
+		},
+		{
+			name:   "cron schedule",
+			export: makeTestArgoCDExportForWebhook(schedule("*/5 * * * *")),
+		},
+		{
+			name:   "predefined schedule",
+			export: makeTestArgoCDExportForWebhook(schedule("@daily")),
+		},
+		{
+			name:   "schedule with time zone",
+			export: makeTestArgoCDExportForWebhook(schedule("CRON_TZ=UTC 0 2 * * *")),
+		},
+		{
+			name:       "malformed schedule",
+			export:     makeTestArgoCDExportForWebhook(schedule("every day")),
+			wantFields: []string{"spec.schedule"},
+		},
+		{
+			name:       "unknown predefined schedule",
+			export:     makeTestArgoCDExportForWebhook(schedule("@sometimes")),
+			wantFields: []string{"spec.schedule"},
+		},
+		{
+			name: "missing argocd",
+			export: makeTestArgoCDExportForWebhook(func(a *ArgoCDExport) {
+				a.Spec.Argocd = ""
+			}),
+			wantFields: []string{"spec.argocd"},
+		},
+		{
+			name: "retention",
+			export: makeTestArgoCDExportForWebhook(func(a *ArgoCDExport) {
+				keepLast := int32(7)
+				a.Spec.Retention = &ArgoCDExportRetentionSpec{KeepLast: &keepLast, MaxAge: &metav1.Duration{Duration: 720 * time.Hour}}
+			}),
+		},
+		{
+			name: "invalid retention",
+			export: makeTestArgoCDExportForWebhook(func(a *ArgoCDExport) {
+				keepLast := int32(0)
+				a.Spec.Retention = &ArgoCDExportRetentionSpec{KeepLast: &keepLast, MaxAge: &metav1.Duration{Duration: -time.Hour}}
+			}),
+			wantFields: []string{"spec.retention.keepLast", "spec.retention.maxAge"},
+		},
+		{
+			name: "unsupported backend",
+			export: makeTestArgoCDExportForWebhook(func(a *ArgoCDExport) {
+				a.Spec.Storage = &ArgoCDExportStorageSpec{Backend: "dropbox"}
+			}),
+			wantFields: []string{"spec.storage.backend"},
+		},
+		{
+			name: "backend is case insensitive",
+			export: makeTestArgoCDExportForWebhook(func(a *ArgoCDExport) {
+				a.Spec.Storage = &ArgoCDExportStorageSpec{Backend: "AWS"}
+			}),
+		},
+		{
+			name: "s3 backend",
+			export: makeTestArgoCDExportForWebhook(func(a *ArgoCDExport) {
+				a.Spec.Storage = &ArgoCDExportStorageSpec{
+					Backend: "s3",
+					S3: &ArgoCDExportS3Spec{
+						Endpoint:       "https://minio.example.com:9000",
+						Bucket:         "backups",
+						ForcePathStyle: true,
+						CABundle: &corev1.ConfigMapKeySelector{
+							LocalObjectReference: corev1.LocalObjectReference{Name: "minio-ca"},
+							Key:                  "ca.crt",
+						},
+						ServerSideEncryption: &ArgoCDExportS3SSESpec{Type: "aws:kms", KMSKeyID: "backup-key"},
+					},
+				}
+			}),
+		},
+		{
+			name: "s3 backend without options",
+			export: makeTestArgoCDExportForWebhook(func(a *ArgoCDExport) {
+				a.Spec.Storage = &ArgoCDExportStorageSpec{Backend: "s3"}
+			}),
+			wantFields: []string{"spec.storage.s3"},
+		},
+		{
+			name: "invalid s3 options",
+			export: makeTestArgoCDExportForWebhook(func(a *ArgoCDExport) {
+				a.Spec.Storage = &ArgoCDExportStorageSpec{
+					Backend: "s3",
+					S3: &ArgoCDExportS3Spec{
+						Endpoint:             "minio:9000",
+						CABundle:             &corev1.ConfigMapKeySelector{},
+						ServerSideEncryption: &ArgoCDExportS3SSESpec{Type: "AES256", KMSKeyID: "backup-key"},
+					},
+				}
+			}),
+			wantFields: []string{
+				"spec.storage.s3.endpoint",
+				"spec.storage.s3.bucket",
+				"spec.storage.s3.caBundle",
+				"spec.storage.s3.serverSideEncryption.kmsKeyID",
+			},
+		},
+		{
+			name: "unsupported s3 server-side encryption",
+			export: makeTestArgoCDExportForWebhook(func(a *ArgoCDExport) {
+				a.Spec.Storage = &ArgoCDExportStorageSpec{
+					Backend: "s3",
+					S3: &ArgoCDExportS3Spec{
+						Endpoint:             "http://minio:9000",
+						Bucket:               "backups",
+						ServerSideEncryption: &ArgoCDExportS3SSESpec{Type: "SSE-C"},
+					},
+				}
+			}),
+			wantFields: []string{"spec.storage.s3.serverSideEncryption.type"},
+		},
+		{
+			name: "valid kms encryption",
+			export: makeTestArgoCDExportForWebhook(func(a *ArgoCDExport) {
+				a.Spec.Encryption = &ArgoCDExportEncryptionSpec{
+					KMS: &ArgoCDExportKMSSpec{KeyID: "alias/argocd-backups", Region: "eu-west-1"},
+				}
+			}),
+		},
+		{
+			name: "invalid encryption",
+			export: makeTestArgoCDExportForWebhook(func(a *ArgoCDExport) {
+				a.Spec.Encryption = &ArgoCDExportEncryptionSpec{
+					KeySecretRef: &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "backup-key"}},
+					KMS:          &ArgoCDExportKMSSpec{Endpoint: "kms.example.com"},
+				}
+			}),
+			wantFields: []string{
+				"spec.encryption",
+				"spec.encryption.keySecretRef",
+				"spec.encryption.kms.keyID",
+				"spec.encryption.kms.endpoint",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := test.export.ValidateCreate()
+			if len(test.wantFields) == 0 {
+				assert.NoError(t, err)
+				return
+			}
+			statusErr, ok := err.(*apierrors.StatusError)
+			if !ok {
+				t.Fatalf("expected a StatusError, got %T", err)
+			}
+			fields := []string{}
+			for _, c := range statusErr.ErrStatus.Details.Causes {
+				fields = append(fields, c.Field)
+			}
+			assert.ElementsMatch(t, test.wantFields, fields)
+		})
+	}
+}
+
+func TestArgoCDExport_ValidateCreate_Warnings(t *testing.T) {
+	a := makeTestArgoCDExportForWebhook(func(a *ArgoCDExport) {
+		a.Spec.Storage = &ArgoCDExportStorageSpec{
+			Backend: "gcp",
+			PVC:     &corev1.PersistentVolumeClaimSpec{},
+		}
+	})
+
+	warnings, err := a.ValidateCreate()
+	assert.NoError(t, err)
+	assert.Len(t, warnings, 1)
+}
@@ -27,7 +27,7 @@ import (
 	"k8s.io/api/core/v1"
 	networkingv1 "k8s.io/api/networking/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	runtime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime"
 )
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
@@ -36,7 +36,7 @@ func (in *ArgoCD) DeepCopyInto(out *ArgoCD) {
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
-	out.Status = in.Status
+	in.Status.DeepCopyInto(&out.Status)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ArgoCD.
@@ -174,6 +174,37 @@ func (in *ArgoCDCASpec) DeepCopy() *ArgoCDCASpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ArgoCDCertManagerIssuerReference) DeepCopyInto(out *ArgoCDCertManagerIssuerReference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ArgoCDCertManagerIssuerReference.
+func (in *ArgoCDCertManagerIssuerReference) DeepCopy() *ArgoCDCertManagerIssuerReference {
+	if in == nil {
+		return nil
+	}
+	out := new(ArgoCDCertManagerIssuerReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ArgoCDCertManagerSpec) DeepCopyInto(out *ArgoCDCertManagerSpec) {
+	*out = *in
+	out.IssuerRef = in.IssuerRef
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ArgoCDCertManagerSpec.
+func (in *ArgoCDCertManagerSpec) DeepCopy() *ArgoCDCertManagerSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ArgoCDCertManagerSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ArgoCDCertificateSpec) DeepCopyInto(out *ArgoCDCertificateSpec) {
 	*out = *in
@@ -220,7 +251,7 @@ func (in *ArgoCDExport) DeepCopyInto(out *ArgoCDExport) {
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
-	out.Status = in.Status
+	in.Status.DeepCopyInto(&out.Status)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ArgoCDExport.
@@ -241,6 +272,46 @@ func (in *ArgoCDExport) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ArgoCDExportEncryptionSpec) DeepCopyInto(out *ArgoCDExportEncryptionSpec) {
+	*out = *in
+	if in.KeySecretRef != nil {
+		in, out := &in.KeySecretRef, &out.KeySecretRef
+		*out = new(v1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.KMS != nil {
+		in, out := &in.KMS, &out.KMS
+		*out = new(ArgoCDExportKMSSpec)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ArgoCDExportEncryptionSpec.
+func (in *ArgoCDExportEncryptionSpec) DeepCopy() *ArgoCDExportEncryptionSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ArgoCDExportEncryptionSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ArgoCDExportKMSSpec) DeepCopyInto(out *ArgoCDExportKMSSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ArgoCDExportKMSSpec.
+func (in *ArgoCDExportKMSSpec) DeepCopy() *ArgoCDExportKMSSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ArgoCDExportKMSSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ArgoCDExportList) DeepCopyInto(out *ArgoCDExportList) {
 	*out = *in
@@ -273,9 +344,120 @@ func (in *ArgoCDExportList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ArgoCDExportRestorePoint) DeepCopyInto(out *ArgoCDExportRestorePoint) {
+	*out = *in
+	in.CreationTime.DeepCopyInto(&out.CreationTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ArgoCDExportRestorePoint.
+func (in *ArgoCDExportRestorePoint) DeepCopy() *ArgoCDExportRestorePoint {
+	if in == nil {
+		return nil
+	}
+	out := new(ArgoCDExportRestorePoint)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ArgoCDExportRetentionSpec) DeepCopyInto(out *ArgoCDExportRetentionSpec) {
+	*out = *in
+	if in.KeepLast != nil {
+		in, out := &in.KeepLast, &out.KeepLast
+		*out = new(int32)
+		**out = **in
+	}
+	if in.MaxAge != nil {
+		in, out := &in.MaxAge, &out.MaxAge
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ArgoCDExportRetentionSpec.
+func (in *ArgoCDExportRetentionSpec) DeepCopy() *ArgoCDExportRetentionSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ArgoCDExportRetentionSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ArgoCDExportS3SSESpec) DeepCopyInto(out *ArgoCDExportS3SSESpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ArgoCDExportS3SSESpec.
+func (in *ArgoCDExportS3SSESpec) DeepCopy() *ArgoCDExportS3SSESpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ArgoCDExportS3SSESpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ArgoCDExportS3Spec) DeepCopyInto(out *ArgoCDExportS3Spec) {
+	*out = *in
+	if in.CABundle != nil {
+		in, out := &in.CABundle, &out.CABundle
+		*out = new(v1.ConfigMapKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ServerSideEncryption != nil {
+		in, out := &in.ServerSideEncryption, &out.ServerSideEncryption
+		*out = new(ArgoCDExportS3SSESpec)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ArgoCDExportS3Spec.
+func (in *ArgoCDExportS3Spec) DeepCopy() *ArgoCDExportS3Spec {
+	if in == nil {
+		return nil
+	}
+	out := new(ArgoCDExportS3Spec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ArgoCDExportSpec) DeepCopyInto(out *ArgoCDExportSpec) {
 	*out = *in
+	if in.ArgocdNamespace != nil {
+		in, out := &in.ArgocdNamespace, &out.ArgocdNamespace
+		*out = new(string)
+		**out = **in
+	}
+	if in.Encryption != nil {
+		in, out := &in.Encryption, &out.Encryption
+		*out = new(ArgoCDExportEncryptionSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.FailedJobsHistoryLimit != nil {
+		in, out := &in.FailedJobsHistoryLimit, &out.FailedJobsHistoryLimit
+		*out = new(int32)
+		**out = **in
+	}
+	if in.NodePlacement != nil {
+		in, out := &in.NodePlacement, &out.NodePlacement
+		*out = new(ArgoCDNodePlacementSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = new(v1.ResourceRequirements)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Retention != nil {
+		in, out := &in.Retention, &out.Retention
+		*out = new(ArgoCDExportRetentionSpec)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.Schedule != nil {
 		in, out := &in.Schedule, &out.Schedule
 		*out = new(string)
@@ -286,6 +468,16 @@ func (in *ArgoCDExportSpec) DeepCopyInto(out *ArgoCDExportSpec) {
 		*out = new(ArgoCDExportStorageSpec)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.SuccessfulJobsHistoryLimit != nil {
+		in, out := &in.SuccessfulJobsHistoryLimit, &out.SuccessfulJobsHistoryLimit
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Suspend != nil {
+		in, out := &in.Suspend, &out.Suspend
+		*out = new(bool)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ArgoCDExportSpec.
@@ -301,6 +493,32 @@ func (in *ArgoCDExportSpec) DeepCopy() *ArgoCDExportSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ArgoCDExportStatus) DeepCopyInto(out *ArgoCDExportStatus) {
 	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.LastRunStartTime != nil {
+		in, out := &in.LastRunStartTime, &out.LastRunStartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.LastRunCompletionTime != nil {
+		in, out := &in.LastRunCompletionTime, &out.LastRunCompletionTime
+		*out = (*in).DeepCopy()
+	}
+	if in.LastSuccessfulRunTime != nil {
+		in, out := &in.LastSuccessfulRunTime, &out.LastSuccessfulRunTime
+		*out = (*in).DeepCopy()
+	}
+	if in.RestorePoints != nil {
+		in, out := &in.RestorePoints, &out.RestorePoints
+		*out = make([]ArgoCDExportRestorePoint, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ArgoCDExportStatus.
@@ -321,6 +539,11 @@ func (in *ArgoCDExportStorageSpec) DeepCopyInto(out *ArgoCDExportStorageSpec) {
 		*out = new(v1.PersistentVolumeClaimSpec)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.S3 != nil {
+		in, out := &in.S3, &out.S3
+		*out = new(ArgoCDExportS3Spec)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ArgoCDExportStorageSpec.
@@ -333,6 +556,35 @@ func (in *ArgoCDExportStorageSpec) DeepCopy() *ArgoCDExportStorageSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ArgoCDGatewaySpec) DeepCopyInto(out *ArgoCDGatewaySpec) {
+	*out = *in
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ArgoCDGatewaySpec.
+func (in *ArgoCDGatewaySpec) DeepCopy() *ArgoCDGatewaySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ArgoCDGatewaySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ArgoCDGrafanaSpec) DeepCopyInto(out *ArgoCDGrafanaSpec) {
 	*out = *in
@@ -400,6 +652,29 @@ func (in *ArgoCDImportSpec) DeepCopy() *ArgoCDImportSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ArgoCDImportStatus) DeepCopyInto(out *ArgoCDImportStatus) {
+	*out = *in
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ArgoCDImportStatus.
+func (in *ArgoCDImportStatus) DeepCopy() *ArgoCDImportStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ArgoCDImportStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ArgoCDIngressSpec) DeepCopyInto(out *ArgoCDIngressSpec) {
 	*out = *in
@@ -523,6 +798,23 @@ func (in *ArgoCDNodePlacementSpec) DeepCopyInto(out *ArgoCDNodePlacementSpec) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.Affinity != nil {
+		in, out := &in.Affinity, &out.Affinity
+		*out = new(v1.Affinity)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.TopologySpreadConstraints != nil {
+		in, out := &in.TopologySpreadConstraints, &out.TopologySpreadConstraints
+		*out = make([]v1.TopologySpreadConstraint, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.RuntimeClassName != nil {
+		in, out := &in.RuntimeClassName, &out.RuntimeClassName
+		*out = new(string)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ArgoCDNodePlacementSpec.
@@ -811,6 +1103,7 @@ func (in *ArgoCDServerAutoscaleSpec) DeepCopy() *ArgoCDServerAutoscaleSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ArgoCDServerGRPCSpec) DeepCopyInto(out *ArgoCDServerGRPCSpec) {
 	*out = *in
+	in.Gateway.DeepCopyInto(&out.Gateway)
 	in.Ingress.DeepCopyInto(&out.Ingress)
 }
 
@@ -843,6 +1136,7 @@ func (in *ArgoCDServerServiceSpec) DeepCopy() *ArgoCDServerServiceSpec {
 func (in *ArgoCDServerSpec) DeepCopyInto(out *ArgoCDServerSpec) {
 	*out = *in
 	in.Autoscale.DeepCopyInto(&out.Autoscale)
+	in.Gateway.DeepCopyInto(&out.Gateway)
 	in.GRPC.DeepCopyInto(&out.GRPC)
 	in.Ingress.DeepCopyInto(&out.Ingress)
 	if in.Replicas != nil {
@@ -899,6 +1193,11 @@ func (in *ArgoCDSpec) DeepCopyInto(out *ArgoCDSpec) {
 	}
 	in.Grafana.DeepCopyInto(&out.Grafana)
 	in.HA.DeepCopyInto(&out.HA)
+	if in.ImagePullSecrets != nil {
+		in, out := &in.ImagePullSecrets, &out.ImagePullSecrets
+		*out = make([]v1.LocalObjectReference, len(*in))
+		copy(*out, *in)
+	}
 	if in.Import != nil {
 		in, out := &in.Import, &out.Import
 		*out = new(ArgoCDImportSpec)
@@ -973,6 +1272,23 @@ func (in *ArgoCDSpec) DeepCopy() *ArgoCDSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ArgoCDStatus) DeepCopyInto(out *ArgoCDStatus) {
 	*out = *in
+	if in.TLS != nil {
+		in, out := &in.TLS, &out.TLS
+		*out = new(ArgoCDTLSStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Import != nil {
+		in, out := &in.Import, &out.Import
+		*out = new(ArgoCDImportStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ArgoCDStatus.
@@ -989,6 +1305,11 @@ func (in *ArgoCDStatus) DeepCopy() *ArgoCDStatus {
 func (in *ArgoCDTLSSpec) DeepCopyInto(out *ArgoCDTLSSpec) {
 	*out = *in
 	out.CA = in.CA
+	if in.CertManager != nil {
+		in, out := &in.CertManager, &out.CertManager
+		*out = new(ArgoCDCertManagerSpec)
+		**out = **in
+	}
 	if in.InitialCerts != nil {
 		in, out := &in.InitialCerts, &out.InitialCerts
 		*out = make(map[string]string, len(*in))
@@ -996,6 +1317,11 @@ func (in *ArgoCDTLSSpec) DeepCopyInto(out *ArgoCDTLSSpec) {
 			(*out)[key] = val
 		}
 	}
+	if in.RenewBefore != nil {
+		in, out := &in.RenewBefore, &out.RenewBefore
+		*out = new(metav1.Duration)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ArgoCDTLSSpec.
@@ -1008,6 +1334,29 @@ func (in *ArgoCDTLSSpec) DeepCopy() *ArgoCDTLSSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ArgoCDTLSStatus) DeepCopyInto(out *ArgoCDTLSStatus) {
+	*out = *in
+	if in.CANotAfter != nil {
+		in, out := &in.CANotAfter, &out.CANotAfter
+		*out = (*in).DeepCopy()
+	}
+	if in.NotAfter != nil {
+		in, out := &in.NotAfter, &out.NotAfter
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ArgoCDTLSStatus.
+func (in *ArgoCDTLSStatus) DeepCopy() *ArgoCDTLSStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ArgoCDTLSStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Banner) DeepCopyInto(out *Banner) {
 	*out = *in
@@ -1159,6 +1508,7 @@ func (in *SSHHostsSpec) DeepCopy() *SSHHostsSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *WebhookServerSpec) DeepCopyInto(out *WebhookServerSpec) {
 	*out = *in
+	in.Gateway.DeepCopyInto(&out.Gateway)
 	in.Ingress.DeepCopyInto(&out.Ingress)
 	in.Route.DeepCopyInto(&out.Route)
 }
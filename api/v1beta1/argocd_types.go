@@ -26,7 +26,9 @@ import (
 	autoscaling "k8s.io/api/autoscaling/v1"
 	corev1 "k8s.io/api/core/v1"
 	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
 func init() {
@@ -111,6 +113,19 @@ type ArgoCDApplicationControllerSpec struct {
 
 	// Enabled is the flag to enable the Application Controller during ArgoCD installation. (optional, default `true`)
 	Enabled *bool `json:"enabled,omitempty"`
+
+	// PDB defines the PodDisruptionBudget options for the Application Controller component.
+	PDB *ArgoCDPodDisruptionBudgetSpec `json:"pdb,omitempty"`
+
+	// NodePlacement defines the scheduling options for the Application Controller component, merged over the global NodePlacement.
+	NodePlacement *ArgoCDNodePlacementSpec `json:"nodePlacement,omitempty"`
+
+	// ImagePullPolicy is the pull policy of the containers of the Application Controller component. Defaults to the global ImagePullPolicy.
+	//+kubebuilder:validation:Enum=Always;Never;IfNotPresent
+	ImagePullPolicy corev1.PullPolicy `json:"imagePullPolicy,omitempty"`
+
+	// ImagePullSecrets are the secrets used to pull the images of the Application Controller component, in addition to the global ImagePullSecrets.
+	ImagePullSecrets []corev1.LocalObjectReference `json:"imagePullSecrets,omitempty"`
 }
 
 func (a *ArgoCDApplicationControllerSpec) IsEnabled() bool {
@@ -172,6 +187,19 @@ type ArgoCDApplicationSet struct {
 
 	// Enabled is the flag to enable the Application Set Controller during ArgoCD installation. (optional, default `true`)
 	Enabled *bool `json:"enabled,omitempty"`
+
+	// PDB defines the PodDisruptionBudget options for the ApplicationSet controller component.
+	PDB *ArgoCDPodDisruptionBudgetSpec `json:"pdb,omitempty"`
+
+	// NodePlacement defines the scheduling options for the ApplicationSet controller component, merged over the global NodePlacement.
+	NodePlacement *ArgoCDNodePlacementSpec `json:"nodePlacement,omitempty"`
+
+	// ImagePullPolicy is the pull policy of the containers of the ApplicationSet controller component. Defaults to the global ImagePullPolicy.
+	//+kubebuilder:validation:Enum=Always;Never;IfNotPresent
+	ImagePullPolicy corev1.PullPolicy `json:"imagePullPolicy,omitempty"`
+
+	// ImagePullSecrets are the secrets used to pull the images of the ApplicationSet controller component, in addition to the global ImagePullSecrets.
+	ImagePullSecrets []corev1.LocalObjectReference `json:"imagePullSecrets,omitempty"`
 }
 
 func (a *ArgoCDApplicationSet) IsEnabled() bool {
@@ -187,6 +215,25 @@ type ArgoCDCASpec struct {
 	SecretName string `json:"secretName,omitempty"`
 }
 
+// ArgoCDCertManagerSpec defines the cert-manager options for ArgoCD.
+type ArgoCDCertManagerSpec struct {
+	// IssuerRef is the reference to the cert-manager Issuer or ClusterIssuer of the certificates.
+	IssuerRef ArgoCDCertManagerIssuerReference `json:"issuerRef"`
+}
+
+// ArgoCDCertManagerIssuerReference is a reference to a cert-manager issuer.
+type ArgoCDCertManagerIssuerReference struct {
+	// Name is the name of the issuer.
+	Name string `json:"name"`
+
+	// Kind is the kind of the issuer, Issuer or ClusterIssuer. (optional, default `Issuer`)
+	//+kubebuilder:validation:Enum=Issuer;ClusterIssuer
+	Kind string `json:"kind,omitempty"`
+
+	// Group is the API group of the issuer, for external issuers. (optional, default `cert-manager.io`)
+	Group string `json:"group,omitempty"`
+}
+
 // ArgoCDCertificateSpec defines the options for the ArgoCD certificates.
 type ArgoCDCertificateSpec struct {
 	// SecretName is the name of the Secret containing the Certificate and Key.
@@ -220,6 +267,246 @@ type ArgoCDDexSpec struct {
 
 	// Env lets you specify environment variables for Dex.
 	Env []corev1.EnvVar `json:"env,omitempty"`
+
+	// PDB defines the PodDisruptionBudget options for the Dex server component.
+	PDB *ArgoCDPodDisruptionBudgetSpec `json:"pdb,omitempty"`
+
+	// NodePlacement defines the scheduling options for the Dex server component, merged over the global NodePlacement.
+	NodePlacement *ArgoCDNodePlacementSpec `json:"nodePlacement,omitempty"`
+
+	// ImagePullPolicy is the pull policy of the containers of the Dex server component. Defaults to the global ImagePullPolicy.
+	//+kubebuilder:validation:Enum=Always;Never;IfNotPresent
+	ImagePullPolicy corev1.PullPolicy `json:"imagePullPolicy,omitempty"`
+
+	// ImagePullSecrets are the secrets used to pull the images of the Dex server component, in addition to the global ImagePullSecrets.
+	ImagePullSecrets []corev1.LocalObjectReference `json:"imagePullSecrets,omitempty"`
+
+	// Connectors are the typed Dex connectors, rendered by the operator after the connectors of the Config. Their
+	// secrets are read from the referenced Secrets in the namespace of the ArgoCD.
+	Connectors []ArgoCDDexConnector `json:"connectors,omitempty"`
+}
+
+// ArgoCDDexConnector defines a typed Dex connector. Exactly one of the connector types must be set.
+type ArgoCDDexConnector struct {
+	// ID is the unique identifier of the connector.
+	//+kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`
+	ID string `json:"id"`
+
+	// Name is the name of the connector displayed on the login page. (optional, default is the ID)
+	Name string `json:"name,omitempty"`
+
+	// GitHub defines the options of a GitHub connector.
+	GitHub *ArgoCDDexGitHubConnector `json:"github,omitempty"`
+
+	// GitLab defines the options of a GitLab connector.
+	GitLab *ArgoCDDexGitLabConnector `json:"gitlab,omitempty"`
+
+	// LDAP defines the options of an LDAP connector.
+	LDAP *ArgoCDDexLDAPConnector `json:"ldap,omitempty"`
+
+	// OIDC defines the options of an OpenID Connect connector.
+	OIDC *ArgoCDDexOIDCConnector `json:"oidc,omitempty"`
+
+	// SAML defines the options of a SAML 2.0 connector.
+	SAML *ArgoCDDexSAMLConnector `json:"saml,omitempty"`
+
+	// Microsoft defines the options of a Microsoft connector.
+	Microsoft *ArgoCDDexMicrosoftConnector `json:"microsoft,omitempty"`
+}
+
+// ArgoCDDexGitHubConnector defines the options of a Dex GitHub connector.
+type ArgoCDDexGitHubConnector struct {
+	// ClientID is the client ID of the GitHub OAuth application.
+	ClientID string `json:"clientID"`
+
+	// ClientSecret selects the key of the Secret containing the client secret of the GitHub OAuth application.
+	ClientSecret corev1.SecretKeySelector `json:"clientSecret"`
+
+	// Orgs are the organizations, and optionally the teams, a user must be a member of.
+	Orgs []ArgoCDDexGitHubOrg `json:"orgs,omitempty"`
+
+	// HostName is the host name of a GitHub Enterprise instance. (optional, default is github.com)
+	HostName string `json:"hostName,omitempty"`
+
+	// LoadAllGroups loads all the organizations and teams of a user as groups, not only the ones of the Orgs.
+	LoadAllGroups bool `json:"loadAllGroups,omitempty"`
+}
+
+// ArgoCDDexGitHubOrg defines a GitHub organization, and optionally the teams of it, a user must be a member of.
+type ArgoCDDexGitHubOrg struct {
+	// Name is the name of the organization.
+	Name string `json:"name"`
+
+	// Teams are the teams of the organization a user must be a member of.
+	Teams []string `json:"teams,omitempty"`
+}
+
+// ArgoCDDexGitLabConnector defines the options of a Dex GitLab connector.
+type ArgoCDDexGitLabConnector struct {
+	// BaseURL is the URL of the GitLab instance. (optional, default is https://gitlab.com)
+	BaseURL string `json:"baseURL,omitempty"`
+
+	// ClientID is the ID of the GitLab application.
+	ClientID string `json:"clientID"`
+
+	// ClientSecret selects the key of the Secret containing the secret of the GitLab application.
+	ClientSecret corev1.SecretKeySelector `json:"clientSecret"`
+
+	// Groups are the groups a user must be a member of.
+	Groups []string `json:"groups,omitempty"`
+
+	// UseLoginAsID uses the username of a user as its ID, instead of its internal GitLab ID.
+	UseLoginAsID bool `json:"useLoginAsID,omitempty"`
+}
+
+// ArgoCDDexLDAPConnector defines the options of a Dex LDAP connector.
+type ArgoCDDexLDAPConnector struct {
+	// Host is the host and optional port of the LDAP server.
+	Host string `json:"host"`
+
+	// InsecureNoSSL connects to the LDAP server without TLS.
+	InsecureNoSSL bool `json:"insecureNoSSL,omitempty"`
+
+	// InsecureSkipVerify skips the verification of the certificate of the LDAP server.
+	InsecureSkipVerify bool `json:"insecureSkipVerify,omitempty"`
+
+	// StartTLS connects to the LDAP server without TLS, then upgrades the connection with StartTLS.
+	StartTLS bool `json:"startTLS,omitempty"`
+
+	// RootCA selects the key of the Secret containing the PEM encoded CA certificate of the LDAP server.
+	RootCA *corev1.SecretKeySelector `json:"rootCA,omitempty"`
+
+	// BindDN is the DN used to search the users and groups. (optional, default is an anonymous bind)
+	BindDN string `json:"bindDN,omitempty"`
+
+	// BindPW selects the key of the Secret containing the password of the BindDN.
+	BindPW *corev1.SecretKeySelector `json:"bindPW,omitempty"`
+
+	// UsernamePrompt is the label of the username field on the login page.
+	UsernamePrompt string `json:"usernamePrompt,omitempty"`
+
+	// UserSearch defines how the users are searched.
+	UserSearch ArgoCDDexLDAPUserSearch `json:"userSearch"`
+
+	// GroupSearch defines how the groups of a user are searched.
+	GroupSearch *ArgoCDDexLDAPGroupSearch `json:"groupSearch,omitempty"`
+}
+
+// ArgoCDDexLDAPUserSearch defines how the users of a Dex LDAP connector are searched.
+type ArgoCDDexLDAPUserSearch struct {
+	// BaseDN is the DN to start the search from.
+	BaseDN string `json:"baseDN"`
+
+	// Filter is an optional filter applied to the search.
+	Filter string `json:"filter,omitempty"`
+
+	// Username is the attribute matched against the username entered on the login page.
+	Username string `json:"username"`
+
+	// IDAttr is the attribute used as the ID of a user. (optional, default is uid)
+	IDAttr string `json:"idAttr,omitempty"`
+
+	// EmailAttr is the attribute used as the email of a user. (optional, default is mail)
+	EmailAttr string `json:"emailAttr,omitempty"`
+
+	// NameAttr is the attribute used as the display name of a user.
+	NameAttr string `json:"nameAttr,omitempty"`
+}
+
+// ArgoCDDexLDAPGroupSearch defines how the groups of a user of a Dex LDAP connector are searched.
+type ArgoCDDexLDAPGroupSearch struct {
+	// BaseDN is the DN to start the search from.
+	BaseDN string `json:"baseDN"`
+
+	// Filter is an optional filter applied to the search.
+	Filter string `json:"filter,omitempty"`
+
+	// UserMatchers are the pairs of attributes matching a user with its groups.
+	UserMatchers []ArgoCDDexLDAPUserMatcher `json:"userMatchers"`
+
+	// NameAttr is the attribute used as the name of a group.
+	NameAttr string `json:"nameAttr"`
+}
+
+// ArgoCDDexLDAPUserMatcher defines a pair of attributes matching a user with its groups.
+type ArgoCDDexLDAPUserMatcher struct {
+	// UserAttr is the attribute of the user.
+	UserAttr string `json:"userAttr"`
+
+	// GroupAttr is the attribute of the group matched against the UserAttr.
+	GroupAttr string `json:"groupAttr"`
+}
+
+// ArgoCDDexOIDCConnector defines the options of a Dex OpenID Connect connector.
+type ArgoCDDexOIDCConnector struct {
+	// Issuer is the URL of the OpenID Connect provider.
+	Issuer string `json:"issuer"`
+
+	// ClientID is the client ID registered with the OpenID Connect provider.
+	ClientID string `json:"clientID"`
+
+	// ClientSecret selects the key of the Secret containing the client secret registered with the OpenID Connect provider.
+	ClientSecret corev1.SecretKeySelector `json:"clientSecret"`
+
+	// Scopes are the scopes requested in addition to openid. (optional, default is profile and email)
+	Scopes []string `json:"scopes,omitempty"`
+
+	// GetUserInfo queries the UserInfo endpoint for additional claims.
+	GetUserInfo bool `json:"getUserInfo,omitempty"`
+
+	// InsecureSkipEmailVerified ignores the email_verified claim.
+	InsecureSkipEmailVerified bool `json:"insecureSkipEmailVerified,omitempty"`
+
+	// InsecureEnableGroups reads the groups of a user from the groups claim.
+	InsecureEnableGroups bool `json:"insecureEnableGroups,omitempty"`
+}
+
+// ArgoCDDexSAMLConnector defines the options of a Dex SAML 2.0 connector.
+type ArgoCDDexSAMLConnector struct {
+	// SSOURL is the URL of the SSO service of the identity provider.
+	SSOURL string `json:"ssoURL"`
+
+	// CA selects the key of the Secret containing the PEM encoded CA certificate signing the SAML responses.
+	CA *corev1.SecretKeySelector `json:"ca,omitempty"`
+
+	// InsecureSkipSignatureValidation skips the validation of the signature of the SAML responses.
+	InsecureSkipSignatureValidation bool `json:"insecureSkipSignatureValidation,omitempty"`
+
+	// EntityIssuer is the issuer of the SAML requests.
+	EntityIssuer string `json:"entityIssuer,omitempty"`
+
+	// SSOIssuer is the expected issuer of the SAML responses.
+	SSOIssuer string `json:"ssoIssuer,omitempty"`
+
+	// UsernameAttr is the attribute used as the username of a user.
+	UsernameAttr string `json:"usernameAttr"`
+
+	// EmailAttr is the attribute used as the email of a user.
+	EmailAttr string `json:"emailAttr"`
+
+	// GroupsAttr is the attribute used as the groups of a user.
+	GroupsAttr string `json:"groupsAttr,omitempty"`
+
+	// NameIDPolicyFormat is the format of the NameID requested from the identity provider.
+	NameIDPolicyFormat string `json:"nameIDPolicyFormat,omitempty"`
+}
+
+// ArgoCDDexMicrosoftConnector defines the options of a Dex Microsoft connector.
+type ArgoCDDexMicrosoftConnector struct {
+	// ClientID is the ID of the Azure AD application.
+	ClientID string `json:"clientID"`
+
+	// ClientSecret selects the key of the Secret containing the client secret of the Azure AD application.
+	ClientSecret corev1.SecretKeySelector `json:"clientSecret"`
+
+	// Tenant is the tenant of the users. (optional, default is common)
+	Tenant string `json:"tenant,omitempty"`
+
+	// Groups are the groups a user must be a member of.
+	Groups []string `json:"groups,omitempty"`
+
+	// OnlySecurityGroups loads only the security groups of a user.
+	OnlySecurityGroups bool `json:"onlySecurityGroups,omitempty"`
 }
 
 // ArgoCDGrafanaSpec defines the desired state for the Grafana component.
@@ -255,6 +542,33 @@ type ArgoCDGrafanaSpec struct {
 	Version string `json:"version,omitempty"`
 }
 
+// ArgoCDGatewaySpec defines the desired state for a Gateway API route, attached to an existing Gateway.
+type ArgoCDGatewaySpec struct {
+	// Annotations is the map of annotations to apply to the route.
+	Annotations map[string]string `json:"annotations,omitempty"`
+
+	// Enabled will toggle the creation of the Gateway API route.
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Gateway Enabled'",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:fieldGroup:Server","urn:alm:descriptor:com.tectonic.ui:booleanSwitch"}
+	Enabled bool `json:"enabled"`
+
+	// GatewayName is the name of the Gateway the route is attached to.
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Gateway Name",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:fieldGroup:Server","urn:alm:descriptor:com.tectonic.ui:text"}
+	GatewayName string `json:"gatewayName,omitempty"`
+
+	// GatewayNamespace is the namespace of the Gateway the route is attached to, defaults to the same namespace as the ArgoCD.
+	GatewayNamespace string `json:"gatewayNamespace,omitempty"`
+
+	// Labels is the map of labels to apply to the route.
+	Labels map[string]string `json:"labels,omitempty"`
+
+	// Path is the path prefix matched by the HTTPRoute, defaults to "/". It is not used for GRPCRoutes.
+	Path string `json:"path,omitempty"`
+
+	// SectionName is the name of the listener of the Gateway the route is attached to, all the listeners of the
+	// Gateway if not set.
+	SectionName string `json:"sectionName,omitempty"`
+}
+
 // ArgoCDHASpec defines the desired state for High Availability support for Argo CD.
 type ArgoCDHASpec struct {
 	// Enabled will toggle HA support globally for Argo CD.
@@ -269,10 +583,28 @@ type ArgoCDHASpec struct {
 
 	// Resources defines the Compute Resources required by the container for HA.
 	Resources *corev1.ResourceRequirements `json:"resources,omitempty"`
+
+	// PDB defines the PodDisruptionBudget options for the Redis HA proxy (HAProxy) component.
+	PDB *ArgoCDPodDisruptionBudgetSpec `json:"pdb,omitempty"`
+
+	// NodePlacement defines the scheduling options for the Redis HA proxy (HAProxy) component, merged over the global NodePlacement.
+	NodePlacement *ArgoCDNodePlacementSpec `json:"nodePlacement,omitempty"`
+
+	// ImagePullPolicy is the pull policy of the containers of the Redis HA proxy (HAProxy) component. Defaults to the global ImagePullPolicy.
+	//+kubebuilder:validation:Enum=Always;Never;IfNotPresent
+	ImagePullPolicy corev1.PullPolicy `json:"imagePullPolicy,omitempty"`
+
+	// ImagePullSecrets are the secrets used to pull the images of the Redis HA proxy (HAProxy) component, in addition to the global ImagePullSecrets.
+	ImagePullSecrets []corev1.LocalObjectReference `json:"imagePullSecrets,omitempty"`
 }
 
 // ArgoCDImportSpec defines the desired state for the ArgoCD import/restore process.
 type ArgoCDImportSpec struct {
+	// BackupID is the ID of the backup to import, as listed in the restore points of the ArgoCDExport status.
+	// The most recent backup is imported if not set.
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Backup ID",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:fieldGroup:Import","urn:alm:descriptor:com.tectonic.ui:text"}
+	BackupID string `json:"backupID,omitempty"`
+
 	// Name of an ArgoCDExport from which to import data.
 	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Name",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:fieldGroup:Import","urn:alm:descriptor:com.tectonic.ui:text"}
 	Name string `json:"name"`
@@ -282,6 +614,41 @@ type ArgoCDImportSpec struct {
 	Namespace *string `json:"namespace,omitempty"`
 }
 
+// ArgoCDImportStatus defines the observed state of the ArgoCD import/restore process.
+type ArgoCDImportStatus struct {
+	// Name of the ArgoCDExport the data is imported from.
+	Name string `json:"name"`
+
+	// Namespace of the ArgoCDExport the data is imported from.
+	Namespace string `json:"namespace"`
+
+	// RequestedBackupID is the ID of the backup requested by the Import options, empty for the most recent backup.
+	RequestedBackupID string `json:"requestedBackupID,omitempty"`
+
+	// BackupID is the ID of the imported backup, as reported by the import Job once it completed.
+	BackupID string `json:"backupID,omitempty"`
+
+	// JobName is the name of the Job performing the import.
+	JobName string `json:"jobName,omitempty"`
+
+	// Phase is a simple, high-level summary of where the import is in its lifecycle.
+	// There are four possible phase values:
+	// Pending: The import is waiting for the ArgoCDExport to be found.
+	// Running: The import Job is running, the application controller is stopped until it completes.
+	// Completed: The backup has been imported.
+	// Failed: The import Job failed, it is retried once the Job is deleted.
+	Phase string `json:"phase,omitempty"`
+
+	// StartTime is the time at which the import Job started.
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+
+	// CompletionTime is the time at which the import Job completed or failed.
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+
+	// Message is a human readable description of the phase.
+	Message string `json:"message,omitempty"`
+}
+
 // ArgoCDIngressSpec defines the desired state for the Ingress resources.
 type ArgoCDIngressSpec struct {
 	// Annotations is the map of annotations to apply to the Ingress.
@@ -322,6 +689,61 @@ type ArgoCDKeycloakSpec struct {
 
 	// VerifyTLS set to false disables strict TLS validation.
 	VerifyTLS *bool `json:"verifyTLS,omitempty"`
+
+	// Database configures the PostgreSQL database in which Keycloak persists its realms and users. Keycloak runs
+	// with an ephemeral database, which is lost when its pod restarts, when not set.
+	Database *ArgoCDKeycloakDatabaseSpec `json:"database,omitempty"`
+
+	// External configures an existing Keycloak server in which the operator only provisions the realm for Argo CD,
+	// instead of installing Keycloak.
+	External *ArgoCDKeycloakExternalSpec `json:"external,omitempty"`
+}
+
+// ArgoCDKeycloakDatabaseSpec defines the PostgreSQL database of Keycloak.
+type ArgoCDKeycloakDatabaseSpec struct {
+	// Host of an external PostgreSQL server. The operator deploys a PostgreSQL server for Keycloak when not set.
+	Host string `json:"host,omitempty"`
+
+	// Port of the external PostgreSQL server. Defaults to 5432.
+	Port int32 `json:"port,omitempty"`
+
+	// Name of the database. Defaults to keycloak.
+	Name string `json:"name,omitempty"`
+
+	// CredentialsSecret references the Secret holding the username and password keys used to connect to the
+	// database. It must be set for an external PostgreSQL server, and the operator generates it for the PostgreSQL
+	// server it deploys when not set.
+	CredentialsSecret *corev1.LocalObjectReference `json:"credentialsSecret,omitempty"`
+
+	// Image is the container image of the PostgreSQL server deployed by the operator.
+	Image string `json:"image,omitempty"`
+
+	// Size of the volume of the PostgreSQL server deployed by the operator. Defaults to 1Gi.
+	Size *resource.Quantity `json:"size,omitempty"`
+
+	// StorageClassName is the storage class of the volume of the PostgreSQL server deployed by the operator.
+	StorageClassName *string `json:"storageClassName,omitempty"`
+}
+
+// IsExternal returns true if the database is hosted on an external PostgreSQL server.
+func (d *ArgoCDKeycloakDatabaseSpec) IsExternal() bool {
+	return d.Host != ""
+}
+
+// ArgoCDKeycloakExternalSpec defines an existing Keycloak server.
+type ArgoCDKeycloakExternalSpec struct {
+	// URL of the Keycloak server, e.g. https://keycloak.example.com, which serves the Keycloak APIs under the /auth
+	// context path.
+	URL string `json:"url"`
+
+	// AdminCredentialsSecret references the Secret holding the username and password keys of an administrator of
+	// the master realm, which the operator uses to provision the realm for Argo CD.
+	AdminCredentialsSecret corev1.LocalObjectReference `json:"adminCredentialsSecret"`
+}
+
+// IsExternal returns true if the operator configures an existing Keycloak server instead of installing Keycloak.
+func (k *ArgoCDKeycloakSpec) IsExternal() bool {
+	return k != nil && k.External != nil
 }
 
 //+kubebuilder:object:root=true
@@ -333,6 +755,22 @@ type ArgoCDList struct {
 	Items           []ArgoCD `json:"items"`
 }
 
+// ArgoCDNetworkPolicySpec defines the desired state for the NetworkPolicies of the Argo CD components.
+type ArgoCDNetworkPolicySpec struct {
+	// Enabled will toggle the creation of NetworkPolicies that deny all ingress traffic to the Argo CD components,
+	// except the traffic required between the components and from the ingress controller and Prometheus.
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="NetworkPolicy Enabled",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:booleanSwitch"}
+	Enabled bool `json:"enabled"`
+
+	// IngressNamespaceSelector selects the namespaces, such as the namespace of the ingress controller, that are
+	// allowed to reach the Argo CD Server and the ApplicationSet webhook. Any source is allowed when not set.
+	IngressNamespaceSelector *metav1.LabelSelector `json:"ingressNamespaceSelector,omitempty"`
+
+	// MetricsNamespaceSelector selects the namespaces, such as the namespace of Prometheus, that are allowed to
+	// reach the metrics ports of the Argo CD components. Any source is allowed when not set.
+	MetricsNamespaceSelector *metav1.LabelSelector `json:"metricsNamespaceSelector,omitempty"`
+}
+
 // ArgoCDNotifications defines whether the Argo CD Notifications controller should be installed.
 type ArgoCDNotifications struct {
 
@@ -356,6 +794,34 @@ type ArgoCDNotifications struct {
 
 	// LogLevel describes the log level that should be used by the argocd-notifications. Defaults to ArgoCDDefaultLogLevel if not set.  Valid options are debug,info, error, and warn.
 	LogLevel string `json:"logLevel,omitempty"`
+
+	// PDB defines the PodDisruptionBudget options for the Notifications controller component.
+	PDB *ArgoCDPodDisruptionBudgetSpec `json:"pdb,omitempty"`
+
+	// NodePlacement defines the scheduling options for the Notifications controller component, merged over the global NodePlacement.
+	NodePlacement *ArgoCDNodePlacementSpec `json:"nodePlacement,omitempty"`
+
+	// ImagePullPolicy is the pull policy of the containers of the Notifications controller component. Defaults to the global ImagePullPolicy.
+	//+kubebuilder:validation:Enum=Always;Never;IfNotPresent
+	ImagePullPolicy corev1.PullPolicy `json:"imagePullPolicy,omitempty"`
+
+	// ImagePullSecrets are the secrets used to pull the images of the Notifications controller component, in addition to the global ImagePullSecrets.
+	ImagePullSecrets []corev1.LocalObjectReference `json:"imagePullSecrets,omitempty"`
+}
+
+// ArgoCDPodDisruptionBudgetSpec defines the desired state for the PodDisruptionBudget of an Argo CD component.
+type ArgoCDPodDisruptionBudgetSpec struct {
+	// Enabled will toggle the creation of the PodDisruptionBudget. Defaults to true when HA is enabled, false otherwise.
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="PodDisruptionBudget Enabled",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:booleanSwitch"}
+	Enabled *bool `json:"enabled,omitempty"`
+
+	// MaxUnavailable is the maximum number of pods of the component that can be unavailable during a voluntary
+	// disruption, as a number or a percentage. Defaults to 1 when MinAvailable is not set.
+	MaxUnavailable *intstr.IntOrString `json:"maxUnavailable,omitempty"`
+
+	// MinAvailable is the minimum number of pods of the component that must remain available during a voluntary
+	// disruption, as a number or a percentage. It cannot be set together with MaxUnavailable.
+	MinAvailable *intstr.IntOrString `json:"minAvailable,omitempty"`
 }
 
 // ArgoCDPrometheusSpec defines the desired state for the Prometheus component.
@@ -426,6 +892,7 @@ type ArgoCDRedisSpec struct {
 	// AutoTLS specifies the method to use for automatic TLS configuration for the redis server
 	// The value specified here can currently be:
 	// - openshift - Use the OpenShift service CA to request TLS config
+	// - cert-manager - Use a cert-manager Certificate issued by the issuer of the TLS options
 	AutoTLS string `json:"autotls,omitempty"`
 
 	// Enabled is the flag to enable Redis during ArgoCD installation. (optional, default `true`)
@@ -433,6 +900,36 @@ type ArgoCDRedisSpec struct {
 
 	// Remote specifies the remote URL of the Redis container. (optional, by default, a local instance managed by the operator is used.)
 	Remote *string `json:"remote,omitempty"`
+
+	// PDB defines the PodDisruptionBudget options for the Redis component, or the Redis HA servers when HA is enabled.
+	PDB *ArgoCDPodDisruptionBudgetSpec `json:"pdb,omitempty"`
+
+	// NodePlacement defines the scheduling options for the Redis component, or the Redis HA servers when HA is enabled, merged over the global NodePlacement.
+	NodePlacement *ArgoCDNodePlacementSpec `json:"nodePlacement,omitempty"`
+
+	// ImagePullPolicy is the pull policy of the containers of the Redis component, or the Redis HA servers when HA is enabled. Defaults to the global ImagePullPolicy.
+	//+kubebuilder:validation:Enum=Always;Never;IfNotPresent
+	ImagePullPolicy corev1.PullPolicy `json:"imagePullPolicy,omitempty"`
+
+	// ImagePullSecrets are the secrets used to pull the images of the Redis component, or the Redis HA servers when HA is enabled, in addition to the global ImagePullSecrets.
+	ImagePullSecrets []corev1.LocalObjectReference `json:"imagePullSecrets,omitempty"`
+
+	// Auth defines the password authentication options of Redis. (optional, by default, Redis does not require a password.)
+	Auth *ArgoCDRedisAuthSpec `json:"auth,omitempty"`
+}
+
+// ArgoCDRedisAuthSpec defines the password authentication options of Redis.
+type ArgoCDRedisAuthSpec struct {
+	// Enabled is the flag to require a password to connect to Redis, which is passed to the Argo CD components.
+	Enabled bool `json:"enabled,omitempty"`
+
+	// PasswordSecret references the key of the secret holding the Redis password. When not set, the operator generates
+	// the password in the <argocd-name>-redis-auth secret, and generates a new one once that secret is deleted. It must
+	// be set when a remote Redis is used.
+	PasswordSecret *corev1.SecretKeySelector `json:"passwordSecret,omitempty"`
+
+	// Username is the name of the ACL user to authenticate as with a remote Redis. (optional, by default, the default user is used.)
+	Username string `json:"username,omitempty"`
 }
 
 func (a *ArgoCDRedisSpec) IsEnabled() bool {
@@ -472,6 +969,7 @@ type ArgoCDRepoSpec struct {
 	// AutoTLS specifies the method to use for automatic TLS configuration for the repo server
 	// The value specified here can currently be:
 	// - openshift - Use the OpenShift service CA to request TLS config
+	// - cert-manager - Use a cert-manager Certificate issued by the issuer of the TLS options
 	AutoTLS string `json:"autotls,omitempty"`
 
 	// Image is the ArgoCD Repo Server container image.
@@ -503,6 +1001,19 @@ type ArgoCDRepoSpec struct {
 
 	// Remote specifies the remote URL of the Repo Server container. (optional, by default, a local instance managed by the operator is used.)
 	Remote *string `json:"remote,omitempty"`
+
+	// PDB defines the PodDisruptionBudget options for the Repo Server component.
+	PDB *ArgoCDPodDisruptionBudgetSpec `json:"pdb,omitempty"`
+
+	// NodePlacement defines the scheduling options for the Repo Server component, merged over the global NodePlacement.
+	NodePlacement *ArgoCDNodePlacementSpec `json:"nodePlacement,omitempty"`
+
+	// ImagePullPolicy is the pull policy of the containers of the Repo Server component. Defaults to the global ImagePullPolicy.
+	//+kubebuilder:validation:Enum=Always;Never;IfNotPresent
+	ImagePullPolicy corev1.PullPolicy `json:"imagePullPolicy,omitempty"`
+
+	// ImagePullSecrets are the secrets used to pull the images of the Repo Server component, in addition to the global ImagePullSecrets.
+	ImagePullSecrets []corev1.LocalObjectReference `json:"imagePullSecrets,omitempty"`
 }
 
 func (a *ArgoCDRepoSpec) IsEnabled() bool {
@@ -543,6 +1054,9 @@ type ArgoCDServerAutoscaleSpec struct {
 
 // ArgoCDServerGRPCSpec defines the desired state for the Argo CD Server GRPC options.
 type ArgoCDServerGRPCSpec struct {
+	// Gateway defines the desired state for a Gateway API GRPCRoute for the Argo CD Server GRPC options.
+	Gateway ArgoCDGatewaySpec `json:"gateway,omitempty"`
+
 	// Host is the hostname to use for Ingress/Route resources.
 	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="GRPC Host",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:fieldGroup:Server","urn:alm:descriptor:com.tectonic.ui:text"}
 	Host string `json:"host,omitempty"`
@@ -557,6 +1071,14 @@ type ArgoCDServerSpec struct {
 	// Autoscale defines the autoscale options for the Argo CD Server component.
 	Autoscale ArgoCDServerAutoscaleSpec `json:"autoscale,omitempty"`
 
+	// AutoTLS specifies the method to use for automatic TLS configuration for the Argo CD Server component
+	// The value specified here can currently be:
+	// - cert-manager - Use a cert-manager Certificate issued by the issuer of the TLS options
+	AutoTLS string `json:"autotls,omitempty"`
+
+	// Gateway defines the desired state for a Gateway API HTTPRoute for the Argo CD Server component.
+	Gateway ArgoCDGatewaySpec `json:"gateway,omitempty"`
+
 	// GRPC defines the state for the Argo CD Server GRPC options.
 	GRPC ArgoCDServerGRPCSpec `json:"grpc,omitempty"`
 
@@ -600,6 +1122,19 @@ type ArgoCDServerSpec struct {
 
 	// Enabled is the flag to enable ArgoCD Server during ArgoCD installation. (optional, default `true`)
 	Enabled *bool `json:"enabled,omitempty"`
+
+	// PDB defines the PodDisruptionBudget options for the Argo CD Server component.
+	PDB *ArgoCDPodDisruptionBudgetSpec `json:"pdb,omitempty"`
+
+	// NodePlacement defines the scheduling options for the Argo CD Server component, merged over the global NodePlacement.
+	NodePlacement *ArgoCDNodePlacementSpec `json:"nodePlacement,omitempty"`
+
+	// ImagePullPolicy is the pull policy of the containers of the Argo CD Server component. Defaults to the global ImagePullPolicy.
+	//+kubebuilder:validation:Enum=Always;Never;IfNotPresent
+	ImagePullPolicy corev1.PullPolicy `json:"imagePullPolicy,omitempty"`
+
+	// ImagePullSecrets are the secrets used to pull the images of the Argo CD Server component, in addition to the global ImagePullSecrets.
+	ImagePullSecrets []corev1.LocalObjectReference `json:"imagePullSecrets,omitempty"`
 }
 
 func (a *ArgoCDServerSpec) IsEnabled() bool {
@@ -687,12 +1222,22 @@ type ArgoCDMonitoringSpec struct {
 	Enabled bool `json:"enabled"`
 }
 
-// ArgoCDNodePlacementSpec is used to specify NodeSelector and Tolerations for Argo CD workloads
+// ArgoCDNodePlacementSpec is used to specify NodeSelector, Tolerations and the other scheduling options for Argo CD workloads
 type ArgoCDNodePlacementSpec struct {
 	// NodeSelector is a field of PodSpec, it is a map of key value pairs used for node selection
 	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
 	// Tolerations allow the pods to schedule onto nodes with matching taints
 	Tolerations []corev1.Toleration `json:"tolerations,omitempty"`
+	// Affinity defines the scheduling constraints of the pods. The node affinity, pod affinity and pod anti-affinity
+	// replace the defaults of the component separately.
+	Affinity *corev1.Affinity `json:"affinity,omitempty"`
+	// TopologySpreadConstraints describes how the pods are spread across topology domains. The pods of the component
+	// are selected when a constraint has no label selector.
+	TopologySpreadConstraints []corev1.TopologySpreadConstraint `json:"topologySpreadConstraints,omitempty"`
+	// PriorityClassName is the name of the PriorityClass of the pods
+	PriorityClassName string `json:"priorityClassName,omitempty"`
+	// RuntimeClassName is the name of the RuntimeClass used to run the pods
+	RuntimeClassName *string `json:"runtimeClassName,omitempty"`
 }
 
 // ArgoCDSpec defines the desired state of ArgoCD
@@ -750,6 +1295,20 @@ type ArgoCDSpec struct {
 	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Image",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:fieldGroup:ArgoCD","urn:alm:descriptor:com.tectonic.ui:text"}
 	Image string `json:"image,omitempty"`
 
+	// ImagePullPolicy is the pull policy of the containers of all ArgoCD components. Defaults to Always for the Argo CD
+	// images and IfNotPresent for the Redis HA images.
+	//+kubebuilder:validation:Enum=Always;Never;IfNotPresent
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Image Pull Policy",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:fieldGroup:ArgoCD","urn:alm:descriptor:com.tectonic.ui:imagePullPolicy"}
+	ImagePullPolicy corev1.PullPolicy `json:"imagePullPolicy,omitempty"`
+
+	// ImagePullSecrets are the secrets used to pull the images of all ArgoCD components.
+	ImagePullSecrets []corev1.LocalObjectReference `json:"imagePullSecrets,omitempty"`
+
+	// ImageRegistry is the registry, optionally followed by a path, replacing the registry of the images of all ArgoCD
+	// components, such as a mirror of the public registries. The repository path of the images is kept.
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Image Registry",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:fieldGroup:ArgoCD","urn:alm:descriptor:com.tectonic.ui:text"}
+	ImageRegistry string `json:"imageRegistry,omitempty"`
+
 	// Import is the import/restore options for ArgoCD.
 	Import *ArgoCDImportSpec `json:"import,omitempty"`
 
@@ -774,6 +1333,9 @@ type ArgoCDSpec struct {
 	// Monitoring defines whether workload status monitoring configuration for this instance.
 	Monitoring ArgoCDMonitoringSpec `json:"monitoring,omitempty"`
 
+	// NetworkPolicy defines the NetworkPolicy options for the Argo CD components.
+	NetworkPolicy *ArgoCDNetworkPolicySpec `json:"networkPolicy,omitempty"`
+
 	// NodePlacement defines NodeSelectors and Taints for Argo CD workloads
 	NodePlacement *ArgoCDNodePlacementSpec `json:"nodePlacement,omitempty"`
 
@@ -929,10 +1491,86 @@ type ArgoCDStatus struct {
 	// RedisTLSChecksum contains the SHA256 checksum of the latest known state of tls.crt and tls.key in the argocd-operator-redis-tls secret.
 	RedisTLSChecksum string `json:"redisTLSChecksum,omitempty"`
 
+	// RedisAuthChecksum contains the SHA256 checksum of the latest known Redis password.
+	RedisAuthChecksum string `json:"redisAuthChecksum,omitempty"`
+
 	// Host is the hostname of the Ingress.
 	Host string `json:"host,omitempty"`
+
+	// TLS is the expiry of the CA and TLS certificates generated by the operator.
+	//+operator-sdk:csv:customresourcedefinitions:type=status,displayName="TLS"
+	TLS *ArgoCDTLSStatus `json:"tls,omitempty"`
+
+	// Import is the progress of the import/restore requested by the Import options. It is kept once the import
+	// completed, as a record of the restored backup.
+	//+operator-sdk:csv:customresourcedefinitions:type=status,displayName="Import"
+	Import *ArgoCDImportStatus `json:"import,omitempty"`
+
+	// Conditions describe the current state of the Argo CD instance. They complement the
+	// per-component summaries above with machine readable reasons and the errors that
+	// prevented reconciliation, if any.
+	// +optional
+	// +patchMergeKey=type
+	// +patchStrategy=merge
+	// +listType=map
+	// +listMapKey=type
+	//+operator-sdk:csv:customresourcedefinitions:type=status,displayName="Conditions",xDescriptors={"urn:alm:descriptor:io.kubernetes.conditions"}
+	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type"`
+
+	// ObservedGeneration is the most recent generation of the ArgoCD spec that has been reconciled.
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
 }
 
+// Condition types reported in ArgoCDStatus.Conditions.
+const (
+	// ArgoCDConditionAvailable indicates that all enabled Argo CD components are running.
+	ArgoCDConditionAvailable = "Available"
+
+	// ArgoCDConditionProgressing indicates that the operator is still rolling out changes to the instance.
+	ArgoCDConditionProgressing = "Progressing"
+
+	// ArgoCDConditionDegraded indicates that reconciliation failed or a component is in a failed state.
+	ArgoCDConditionDegraded = "Degraded"
+
+	// ArgoCDConditionReconcileSuccess indicates whether the last reconciliation completed without errors.
+	ArgoCDConditionReconcileSuccess = "ReconcileSuccess"
+
+	// ArgoCDConditionSSOConfigured indicates whether the SSO configuration of the instance is legal and has been applied.
+	ArgoCDConditionSSOConfigured = "SSOConfigured"
+
+	// ArgoCDConditionImported indicates whether the backup requested by the Import options has been imported.
+	ArgoCDConditionImported = "Imported"
+
+	// Per component readiness conditions.
+	ArgoCDConditionApplicationControllerReady    = "ApplicationControllerReady"
+	ArgoCDConditionApplicationSetControllerReady = "ApplicationSetControllerReady"
+	ArgoCDConditionNotificationsControllerReady  = "NotificationsControllerReady"
+	ArgoCDConditionRedisReady                    = "RedisReady"
+	ArgoCDConditionRepoReady                     = "RepoReady"
+	ArgoCDConditionServerReady                   = "ServerReady"
+	ArgoCDConditionSSOReady                      = "SSOReady"
+)
+
+// Condition reasons reported in ArgoCDStatus.Conditions.
+const (
+	ArgoCDReasonComponentsReady         = "ComponentsReady"
+	ArgoCDReasonComponentsPending       = "ComponentsPending"
+	ArgoCDReasonComponentFailed         = "ComponentFailed"
+	ArgoCDReasonReconcileSucceeded      = "ReconcileSucceeded"
+	ArgoCDReasonReconcileFailed         = "ReconcileFailed"
+	ArgoCDReasonSSOConfigured           = "SSOConfigured"
+	ArgoCDReasonIllegalSSOConfiguration = "IllegalSSOConfiguration"
+	ArgoCDReasonSSOConfigurationFailed  = "SSOConfigurationFailed"
+	ArgoCDReasonImportPending           = "ImportPending"
+	ArgoCDReasonImportRunning           = "ImportRunning"
+	ArgoCDReasonImportCompleted         = "ImportCompleted"
+	ArgoCDReasonImportFailed            = "ImportFailed"
+	ArgoCDReasonRunning                 = "Running"
+	ArgoCDReasonPending                 = "Pending"
+	ArgoCDReasonFailed                  = "Failed"
+	ArgoCDReasonUnknown                 = "Unknown"
+)
+
 // Banner defines an additional banner message to be displayed in Argo CD UI
 // https://argo-cd.readthedocs.io/en/stable/operator-manual/custom-styles/#banners
 type Banner struct {
@@ -947,8 +1585,24 @@ type ArgoCDTLSSpec struct {
 	// CA defines the CA options.
 	CA ArgoCDCASpec `json:"ca,omitempty"`
 
+	// CertManager defines the cert-manager options, used by the components with the cert-manager autotls provider.
+	CertManager *ArgoCDCertManagerSpec `json:"certManager,omitempty"`
+
 	// InitialCerts defines custom TLS certificates upon creation of the cluster for connecting Git repositories via HTTPS.
 	InitialCerts map[string]string `json:"initialCerts,omitempty"`
+
+	// RenewBefore is the time before their expiry at which the CA and TLS certificates generated by the operator are
+	// renewed. (optional, default `720h`)
+	RenewBefore *metav1.Duration `json:"renewBefore,omitempty"`
+}
+
+// ArgoCDTLSStatus defines the observed state of the CA and TLS certificates generated by the operator.
+type ArgoCDTLSStatus struct {
+	// CANotAfter is the expiry time of the CA certificate.
+	CANotAfter *metav1.Time `json:"caNotAfter,omitempty"`
+
+	// NotAfter is the expiry time of the TLS certificate of the Argo CD server.
+	NotAfter *metav1.Time `json:"notAfter,omitempty"`
 }
 
 type SSHHostsSpec struct {
@@ -964,6 +1618,9 @@ type SSHHostsSpec struct {
 // WebhookServerSpec defines the options for the ApplicationSet Webhook Server component.
 type WebhookServerSpec struct {
 
+	// Gateway defines the desired state for a Gateway API HTTPRoute for the Application set webhook component.
+	Gateway ArgoCDGatewaySpec `json:"gateway,omitempty"`
+
 	// Host is the hostname to use for Ingress/Route resources.
 	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Host",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:fieldGroup:Server","urn:alm:descriptor:com.tectonic.ui:text"}
 	Host string `json:"host,omitempty"`
@@ -986,9 +1643,15 @@ func (argocd *ArgoCD) IsDeletionFinalizerPresent() bool {
 }
 
 // WantsAutoTLS returns true if user configured a route with reencryption
-// termination policy.
+// termination policy, and the server certificate is not issued by cert-manager.
 func (s *ArgoCDServerSpec) WantsAutoTLS() bool {
-	return s.Route.TLS != nil && s.Route.TLS.Termination == routev1.TLSTerminationReencrypt
+	return !s.WantsCertManagerTLS() && s.Route.TLS != nil && s.Route.TLS.Termination == routev1.TLSTerminationReencrypt
+}
+
+// WantsCertManagerTLS returns true if the server configuration has set the
+// autoTLS toggle to cert-manager.
+func (s *ArgoCDServerSpec) WantsCertManagerTLS() bool {
+	return s.AutoTLS == common.ArgoCDAutoTLSCertManager
 }
 
 // WantsAutoTLS returns true if the repository server configuration has set
@@ -997,12 +1660,30 @@ func (r *ArgoCDRepoSpec) WantsAutoTLS() bool {
 	return r.AutoTLS == "openshift"
 }
 
+// WantsCertManagerTLS returns true if the repository server configuration has set
+// the autoTLS toggle to cert-manager.
+func (r *ArgoCDRepoSpec) WantsCertManagerTLS() bool {
+	return r.AutoTLS == common.ArgoCDAutoTLSCertManager
+}
+
 // WantsAutoTLS returns true if the redis server configuration has set
 // the autoTLS toggle to a supported provider.
 func (r *ArgoCDRedisSpec) WantsAutoTLS() bool {
 	return r.AutoTLS == "openshift"
 }
 
+// WantsCertManagerTLS returns true if the redis server configuration has set
+// the autoTLS toggle to cert-manager.
+func (r *ArgoCDRedisSpec) WantsCertManagerTLS() bool {
+	return r.AutoTLS == common.ArgoCDAutoTLSCertManager
+}
+
+// WantsAuth returns true if the redis server configuration requires a
+// password.
+func (r *ArgoCDRedisSpec) WantsAuth() bool {
+	return r.Auth != nil && r.Auth.Enabled
+}
+
 // ApplicationInstanceLabelKey returns either the custom application instance
 // label key if set, or the default value.
 func (a *ArgoCD) ApplicationInstanceLabelKey() string {
@@ -17,11 +17,443 @@ limitations under the License.
 package v1beta1
 
 import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v2"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
 	ctrl "sigs.k8s.io/controller-runtime"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"github.com/argoproj-labs/argocd-operator/common"
 )
 
+var argocdlog = logf.Log.WithName("argocd-resource")
+
 func (r *ArgoCD) SetupWebhookWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewWebhookManagedBy(mgr).
 		For(r).
 		Complete()
 }
+
+//+kubebuilder:webhook:path=/mutate-argoproj-io-v1beta1-argocd,mutating=true,failurePolicy=fail,sideEffects=None,groups=argoproj.io,resources=argocds,verbs=create;update,versions=v1beta1,name=margocd.kb.io,admissionReviewVersions=v1
+
+var _ webhook.Defaulter = &ArgoCD{}
+
+// Default implements webhook.Defaulter so a webhook will be registered for the type.
+// It only fills in values that the operator would otherwise assume at reconcile time.
+func (r *ArgoCD) Default() {
+	argocdlog.Info("default", "name", r.Name, "namespace", r.Namespace)
+
+	sharding := &r.Spec.Controller.Sharding
+	if sharding.DynamicScalingEnabled != nil && *sharding.DynamicScalingEnabled {
+		if sharding.MinShards == 0 {
+			sharding.MinShards = 1
+		}
+		if sharding.MaxShards == 0 {
+			sharding.MaxShards = sharding.MinShards
+		}
+		if sharding.ClustersPerShard == 0 {
+			sharding.ClustersPerShard = 1
+		}
+	}
+
+	if r.Spec.SSO != nil {
+		r.Spec.SSO.Provider = r.Spec.SSO.Provider.ToLower()
+	}
+}
+
+//+kubebuilder:webhook:path=/validate-argoproj-io-v1beta1-argocd,mutating=false,failurePolicy=fail,sideEffects=None,groups=argoproj.io,resources=argocds,verbs=create;update,versions=v1beta1,name=vargocd.kb.io,admissionReviewVersions=v1
+
+var _ webhook.Validator = &ArgoCD{}
+
+// ValidateCreate implements webhook.Validator so a webhook will be registered for the type.
+func (r *ArgoCD) ValidateCreate() (admission.Warnings, error) {
+	argocdlog.Info("validate create", "name", r.Name, "namespace", r.Namespace)
+	return r.validateArgoCD()
+}
+
+// ValidateUpdate implements webhook.Validator so a webhook will be registered for the type.
+func (r *ArgoCD) ValidateUpdate(old runtime.Object) (admission.Warnings, error) {
+	argocdlog.Info("validate update", "name", r.Name, "namespace", r.Namespace)
+
+	// Do not block the removal of the deletion finalizer on an instance
+	// that was admitted before validation was introduced.
+	if r.GetDeletionTimestamp() != nil {
+		return nil, nil
+	}
+	return r.validateArgoCD()
+}
+
+// ValidateDelete implements webhook.Validator so a webhook will be registered for the type.
+func (r *ArgoCD) ValidateDelete() (admission.Warnings, error) {
+	return nil, nil
+}
+
+// validateArgoCD runs every spec validation and aggregates the resulting
+// field errors into a single Invalid status error.
+func (r *ArgoCD) validateArgoCD() (admission.Warnings, error) {
+	var allErrs field.ErrorList
+	var warnings admission.Warnings
+
+	specPath := field.NewPath("spec")
+
+	shardingErrs, shardingWarnings := validateSharding(&r.Spec.Controller.Sharding, specPath.Child("controller", "sharding"))
+	allErrs = append(allErrs, shardingErrs...)
+	warnings = append(warnings, shardingWarnings...)
+
+	allErrs = append(allErrs, validateSSO(r.Spec.SSO, specPath.Child("sso"))...)
+	allErrs = append(allErrs, validateResourceTrackingMethod(r.Spec.ResourceTrackingMethod, specPath.Child("resourceTrackingMethod"))...)
+	allErrs = append(allErrs, validateExtraConfig(r.Spec.ExtraConfig, specPath.Child("extraConfig"))...)
+	allErrs = append(allErrs, validateImport(r.Spec.Import, specPath.Child("import"))...)
+	allErrs = append(allErrs, validateRedisAuth(&r.Spec.Redis, specPath.Child("redis"))...)
+	allErrs = append(allErrs, validateTLS(&r.Spec.TLS, specPath.Child("tls"))...)
+	allErrs = append(allErrs, validatePodDisruptionBudgets(&r.Spec, specPath)...)
+
+	allErrs = append(allErrs, validateExtraArgs(r.Spec.Server.ExtraCommandArgs,
+		argoServerDefaultFlags(&r.Spec), specPath.Child("server", "extraCommandArgs"))...)
+	allErrs = append(allErrs, validateExtraArgs(r.Spec.Repo.ExtraRepoCommandArgs,
+		argoRepoDefaultFlags(&r.Spec), specPath.Child("repo", "extraRepoCommandArgs"))...)
+	if r.Spec.ApplicationSet != nil {
+		allErrs = append(allErrs, validateExtraArgs(r.Spec.ApplicationSet.ExtraCommandArgs,
+			argoApplicationSetDefaultFlags(&r.Spec), specPath.Child("applicationSet", "extraCommandArgs"))...)
+	}
+
+	if len(allErrs) == 0 {
+		return warnings, nil
+	}
+	return warnings, apierrors.NewInvalid(GroupVersion.WithKind("ArgoCD").GroupKind(), r.Name, allErrs)
+}
+
+// validateSharding rejects contradictory application controller sharding settings.
+func validateSharding(sharding *ArgoCDApplicationControllerShardSpec, fldPath *field.Path) (field.ErrorList, admission.Warnings) {
+	var allErrs field.ErrorList
+	var warnings admission.Warnings
+
+	if sharding.Replicas < 0 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("replicas"), sharding.Replicas, "must be greater than or equal to 0"))
+	}
+
+	if sharding.DynamicScalingEnabled == nil || !*sharding.DynamicScalingEnabled {
+		return allErrs, warnings
+	}
+
+	if sharding.MinShards < 1 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("minShards"), sharding.MinShards, "must be greater than or equal to 1"))
+	}
+	if sharding.MaxShards < sharding.MinShards {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("maxShards"), sharding.MaxShards,
+			fmt.Sprintf("must be greater than or equal to minShards (%d)", sharding.MinShards)))
+	}
+	if sharding.ClustersPerShard < 1 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("clustersPerShard"), sharding.ClustersPerShard, "must be greater than or equal to 1"))
+	}
+	if sharding.Replicas != 0 {
+		warnings = append(warnings, fmt.Sprintf("%s is ignored when %s is true",
+			fldPath.Child("replicas"), fldPath.Child("dynamicScalingEnabled")))
+	}
+
+	return allErrs, warnings
+}
+
+// validateSSO rejects SSO configurations that the operator cannot reconcile,
+// such as a provider spec that does not match the requested provider.
+func validateSSO(sso *ArgoCDSSOSpec, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+
+	if sso == nil {
+		return allErrs
+	}
+
+	providerPath := fldPath.Child("provider")
+	switch sso.Provider.ToLower() {
+	case SSOProviderTypeDex:
+		if sso.Dex == nil || (!sso.Dex.OpenShiftOAuth && sso.Dex.Config == "" && len(sso.Dex.Connectors) == 0) {
+			allErrs = append(allErrs, field.Required(fldPath.Child("dex"),
+				"must supply valid dex configuration when requested SSO provider is dex"))
+		}
+		if sso.Keycloak != nil {
+			allErrs = append(allErrs, field.Forbidden(fldPath.Child("keycloak"),
+				"cannot supply keycloak configuration when requested SSO provider is dex"))
+		}
+	case SSOProviderTypeKeycloak:
+		if sso.Dex != nil {
+			allErrs = append(allErrs, field.Forbidden(fldPath.Child("dex"),
+				"cannot supply dex configuration when requested SSO provider is keycloak"))
+		}
+		allErrs = append(allErrs, validateKeycloak(sso.Keycloak, fldPath.Child("keycloak"))...)
+	case "":
+		if sso.Dex != nil || sso.Keycloak != nil {
+			allErrs = append(allErrs, field.Required(providerPath,
+				"cannot specify SSO provider spec without specifying SSO provider type"))
+		} else {
+			allErrs = append(allErrs, field.Required(providerPath, "must specify SSO provider type"))
+		}
+	default:
+		allErrs = append(allErrs, field.NotSupported(providerPath, sso.Provider,
+			[]string{string(SSOProviderTypeDex), string(SSOProviderTypeKeycloak)}))
+	}
+
+	return allErrs
+}
+
+// validateKeycloak rejects Keycloak configurations that lack the references the operator needs to connect to an
+// external Keycloak server or PostgreSQL database.
+func validateKeycloak(keycloak *ArgoCDKeycloakSpec, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+
+	if keycloak == nil {
+		return allErrs
+	}
+
+	if keycloak.External != nil {
+		externalPath := fldPath.Child("external")
+		if u, err := url.Parse(keycloak.External.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			allErrs = append(allErrs, field.Invalid(externalPath.Child("url"), keycloak.External.URL,
+				"must be an absolute http or https URL"))
+		}
+		if keycloak.External.AdminCredentialsSecret.Name == "" {
+			allErrs = append(allErrs, field.Required(externalPath.Child("adminCredentialsSecret", "name"),
+				"must reference the secret holding the admin credentials of the external keycloak"))
+		}
+		if keycloak.Database != nil {
+			allErrs = append(allErrs, field.Forbidden(fldPath.Child("database"),
+				"cannot supply a database when using an external keycloak"))
+		}
+	}
+
+	if keycloak.Database != nil && keycloak.Database.IsExternal() && keycloak.Database.CredentialsSecret == nil {
+		allErrs = append(allErrs, field.Required(fldPath.Child("database", "credentialsSecret"),
+			"must reference the secret holding the credentials of the external database"))
+	}
+
+	return allErrs
+}
+
+// validateResourceTrackingMethod rejects tracking methods unknown to Argo CD.
+func validateResourceTrackingMethod(method string, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+
+	if ParseResourceTrackingMethod(method) == ResourceTrackingMethodInvalid {
+		allErrs = append(allErrs, field.NotSupported(fldPath, method, []string{
+			stringResourceTrackingMethodLabel,
+			stringResourceTrackingMethodAnnotation,
+			stringResourceTrackingMethodAnnotationAndLabel,
+		}))
+	}
+
+	return allErrs
+}
+
+// validateImport rejects backup IDs that cannot match a backup written by the export process.
+func validateImport(spec *ArgoCDImportSpec, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+
+	if spec == nil || len(spec.BackupID) == 0 {
+		return allErrs
+	}
+	if _, err := time.Parse(common.ArgoCDExportBackupIDFormat, spec.BackupID); err != nil {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("backupID"), spec.BackupID,
+			fmt.Sprintf("must be a backup ID formatted as %s, as listed in the restore points of the ArgoCDExport", common.ArgoCDExportBackupIDFormat)))
+	}
+
+	return allErrs
+}
+
+// validateRedisAuth rejects a remote Redis requiring a password that the operator cannot know.
+func validateRedisAuth(redis *ArgoCDRedisSpec, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+
+	if !redis.WantsAuth() || redis.Remote == nil || len(*redis.Remote) == 0 {
+		return allErrs
+	}
+	if redis.Auth.PasswordSecret == nil {
+		allErrs = append(allErrs, field.Required(fldPath.Child("auth", "passwordSecret"),
+			"must be set to authenticate with a remote Redis"))
+	}
+
+	return allErrs
+}
+
+// validateTLS rejects a certificate renewal time that is not within the lifetime of the certificates generated by
+// the operator, which would renew them on every reconciliation.
+func validateTLS(tls *ArgoCDTLSSpec, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+
+	if tls.RenewBefore == nil {
+		return allErrs
+	}
+	renewBefore := tls.RenewBefore.Duration
+	if renewBefore <= 0 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("renewBefore"), renewBefore.String(), "must be greater than 0"))
+	} else if renewBefore >= common.ArgoCDDuration365Days {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("renewBefore"), renewBefore.String(),
+			fmt.Sprintf("must be less than the lifetime of the generated certificates (%s)", common.ArgoCDDuration365Days)))
+	}
+
+	return allErrs
+}
+
+// validatePodDisruptionBudgets validates the PodDisruptionBudget options of every component of the given spec.
+func validatePodDisruptionBudgets(spec *ArgoCDSpec, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+
+	allErrs = append(allErrs, validatePodDisruptionBudget(spec.Server.PDB, fldPath.Child("server", "pdb"))...)
+	allErrs = append(allErrs, validatePodDisruptionBudget(spec.Repo.PDB, fldPath.Child("repo", "pdb"))...)
+	allErrs = append(allErrs, validatePodDisruptionBudget(spec.Controller.PDB, fldPath.Child("controller", "pdb"))...)
+	allErrs = append(allErrs, validatePodDisruptionBudget(spec.Redis.PDB, fldPath.Child("redis", "pdb"))...)
+	allErrs = append(allErrs, validatePodDisruptionBudget(spec.HA.PDB, fldPath.Child("ha", "pdb"))...)
+	allErrs = append(allErrs, validatePodDisruptionBudget(spec.Notifications.PDB, fldPath.Child("notifications", "pdb"))...)
+	if spec.ApplicationSet != nil {
+		allErrs = append(allErrs, validatePodDisruptionBudget(spec.ApplicationSet.PDB, fldPath.Child("applicationSet", "pdb"))...)
+	}
+	if spec.SSO != nil && spec.SSO.Dex != nil {
+		allErrs = append(allErrs, validatePodDisruptionBudget(spec.SSO.Dex.PDB, fldPath.Child("sso", "dex", "pdb"))...)
+	}
+
+	return allErrs
+}
+
+// validatePodDisruptionBudget rejects PodDisruptionBudget options setting both minAvailable and maxUnavailable,
+// which are mutually exclusive.
+func validatePodDisruptionBudget(pdb *ArgoCDPodDisruptionBudgetSpec, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+
+	if pdb != nil && pdb.MinAvailable != nil && pdb.MaxUnavailable != nil {
+		allErrs = append(allErrs, field.Forbidden(fldPath.Child("maxUnavailable"),
+			"cannot be set together with minAvailable"))
+	}
+
+	return allErrs
+}
+
+// extraConfigYAMLKeys are the argocd-cm keys whose values Argo CD parses as YAML.
+var extraConfigYAMLKeys = []string{
+	common.ArgoCDKeyConfigManagementPlugins,
+	common.ArgoCDKeyDexConfig,
+	common.ArgoCDKeyOIDCConfig,
+	common.ArgoCDKeyRepositories,
+	common.ArgoCDKeyRepositoryCredentials,
+	common.ArgoCDKeyResourceExclusions,
+	common.ArgoCDKeyResourceInclusions,
+}
+
+// extraConfigBoolKeys are the argocd-cm keys whose values Argo CD parses as booleans.
+var extraConfigBoolKeys = []string{
+	common.ArgoCDKeyAdminEnabled,
+	common.ArgoCDKeyGAAnonymizeUsers,
+	common.ArgoCDKeyStatusBadgeEnabled,
+	common.ArgoCDKeyUsersAnonymousEnabled,
+}
+
+// validateExtraConfig rejects ExtraConfig entries that cannot be written to
+// argocd-cm or that Argo CD would fail to parse.
+func validateExtraConfig(extraConfig map[string]string, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+
+	for k, v := range extraConfig {
+		keyPath := fldPath.Key(k)
+
+		for _, msg := range validation.IsConfigMapKey(k) {
+			allErrs = append(allErrs, field.Invalid(keyPath, k, msg))
+		}
+
+		switch {
+		case containsString(extraConfigYAMLKeys, k):
+			var out interface{}
+			if err := yaml.Unmarshal([]byte(v), &out); err != nil {
+				allErrs = append(allErrs, field.Invalid(keyPath, v, fmt.Sprintf("must be valid YAML: %v", err)))
+			}
+		case containsString(extraConfigBoolKeys, k):
+			if _, err := strconv.ParseBool(v); err != nil {
+				allErrs = append(allErrs, field.Invalid(keyPath, v, "must be a boolean"))
+			}
+		case k == common.ArgoCDKeyResourceTrackingMethod:
+			allErrs = append(allErrs, validateResourceTrackingMethod(v, keyPath)...)
+		}
+	}
+
+	return allErrs
+}
+
+// validateExtraArgs rejects extra command arguments that are repeated or that
+// override a flag the operator already sets on the component.
+func validateExtraArgs(extraArgs []string, defaultFlags []string, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+
+	seen := map[string]bool{}
+	for i, arg := range extraArgs {
+		if len(arg) <= 2 || arg[:2] != "--" {
+			continue
+		}
+		flag := strings.SplitN(arg, "=", 2)[0]
+		if containsString(defaultFlags, flag) {
+			allErrs = append(allErrs, field.Invalid(fldPath.Index(i), arg,
+				fmt.Sprintf("%s is already part of the default command arguments", flag)))
+			continue
+		}
+		if seen[flag] {
+			allErrs = append(allErrs, field.Duplicate(fldPath.Index(i), arg))
+		}
+		seen[flag] = true
+	}
+
+	return allErrs
+}
+
+// argoServerDefaultFlags returns the flags the operator always sets on argocd-server for the given spec.
+func argoServerDefaultFlags(spec *ArgoCDSpec) []string {
+	flags := []string{"--staticassets", "--dex-server", "--loglevel", "--logformat"}
+	if spec.Server.Insecure {
+		flags = append(flags, "--insecure")
+	}
+	if spec.Repo.VerifyTLS {
+		flags = append(flags, "--repo-server-strict-tls")
+	}
+	if spec.Repo.IsEnabled() {
+		flags = append(flags, "--repo-server")
+	}
+	if spec.Redis.IsEnabled() {
+		flags = append(flags, "--redis")
+	}
+	return flags
+}
+
+// argoRepoDefaultFlags returns the flags the operator always sets on argocd-repo-server for the given spec.
+func argoRepoDefaultFlags(spec *ArgoCDSpec) []string {
+	flags := []string{"--loglevel", "--logformat"}
+	if spec.Redis.IsEnabled() {
+		flags = append(flags, "--redis")
+	}
+	return flags
+}
+
+// argoApplicationSetDefaultFlags returns the flags the operator always sets on the ApplicationSet controller for the given spec.
+func argoApplicationSetDefaultFlags(spec *ArgoCDSpec) []string {
+	flags := []string{"--loglevel"}
+	if spec.Repo.IsEnabled() {
+		flags = append(flags, "--argocd-repo-server")
+	}
+	if spec.ApplicationSet != nil && spec.ApplicationSet.SCMRootCAConfigMap != "" {
+		flags = append(flags, "--scm-root-ca-path")
+	}
+	return flags
+}
+
+// containsString returns true if s is part of the given slice.
+func containsString(arr []string, s string) bool {
+	for _, val := range arr {
+		if val == s {
+			return true
+		}
+	}
+	return false
+}
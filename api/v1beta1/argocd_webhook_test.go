@@ -0,0 +1,339 @@
+package v1beta1
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+func makeTestArgoCDForWebhook(opts ...func(*ArgoCD)) *ArgoCD {
+	a := &ArgoCD{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "argocd",
+			Namespace: "argocd",
+		},
+	}
+	for _, o := range opts {
+		o(a)
+	}
+	return a
+}
+
+func boolPtr(val bool) *bool {
+	return &val
+}
+
+func causeFields(t *testing.T, err error) []string {
+	t.Helper()
+	statusErr, ok := err.(*apierrors.StatusError)
+	if !ok {
+		t.Fatalf("expected a StatusError, got %T", err)
+	}
+	fields := []string{}
+	for _, c := range statusErr.ErrStatus.Details.Causes {
+		fields = append(fields, c.Field)
+	}
+	return fields
+}
+
+func TestArgoCD_Default(t *testing.T) {
+	t.Run("dynamic sharding defaults", func(t *testing.T) {
+		a := makeTestArgoCDForWebhook(func(a *ArgoCD) {
+			a.Spec.Controller.Sharding.DynamicScalingEnabled = boolPtr(true)
+		})
+		a.Default()
+		assert.Equal(t, int32(1), a.Spec.Controller.Sharding.MinShards)
+		assert.Equal(t, int32(1), a.Spec.Controller.Sharding.MaxShards)
+		assert.Equal(t, int32(1), a.Spec.Controller.Sharding.ClustersPerShard)
+	})
+
+	t.Run("static sharding left untouched", func(t *testing.T) {
+		a := makeTestArgoCDForWebhook(func(a *ArgoCD) {
+			a.Spec.Controller.Sharding.Enabled = true
+			a.Spec.Controller.Sharding.Replicas = 3
+		})
+		a.Default()
+		assert.Equal(t, int32(0), a.Spec.Controller.Sharding.MinShards)
+		assert.Equal(t, int32(0), a.Spec.Controller.Sharding.ClustersPerShard)
+	})
+
+	t.Run("sso provider is lower cased", func(t *testing.T) {
+		a := makeTestArgoCDForWebhook(func(a *ArgoCD) {
+			a.Spec.SSO = &ArgoCDSSOSpec{Provider: "Keycloak"}
+		})
+		a.Default()
+		assert.Equal(t, SSOProviderTypeKeycloak, a.Spec.SSO.Provider)
+	})
+}
+
+func TestArgoCD_ValidateCreate(t *testing.T) {
+	tests := []struct {
+		name       string
+		argocd     *ArgoCD
+		wantFields []string
+	}{
+		{
+			name:   "empty spec is valid",
+			argocd: makeTestArgoCDForWebhook(),
+		},
+		{
+			name: "maxShards lower than minShards",
+			argocd: makeTestArgoCDForWebhook(func(a *ArgoCD) {
+				a.Spec.Controller.Sharding.DynamicScalingEnabled = boolPtr(true)
+				a.Spec.Controller.Sharding.MinShards = 3
+				a.Spec.Controller.Sharding.MaxShards = 2
+				a.Spec.Controller.Sharding.ClustersPerShard = 1
+			}),
+			wantFields: []string{"spec.controller.sharding.maxShards"},
+		},
+		{
+			name: "zero minShards and clustersPerShard",
+			argocd: makeTestArgoCDForWebhook(func(a *ArgoCD) {
+				a.Spec.Controller.Sharding.DynamicScalingEnabled = boolPtr(true)
+				a.Spec.Controller.Sharding.MaxShards = 2
+			}),
+			wantFields: []string{"spec.controller.sharding.minShards", "spec.controller.sharding.clustersPerShard"},
+		},
+		{
+			name: "dex provider without dex config",
+			argocd: makeTestArgoCDForWebhook(func(a *ArgoCD) {
+				a.Spec.SSO = &ArgoCDSSOSpec{Provider: SSOProviderTypeDex}
+			}),
+			wantFields: []string{"spec.sso.dex"},
+		},
+		{
+			name: "dex provider with typed connectors",
+			argocd: makeTestArgoCDForWebhook(func(a *ArgoCD) {
+				a.Spec.SSO = &ArgoCDSSOSpec{
+					Provider: SSOProviderTypeDex,
+					Dex:      &ArgoCDDexSpec{Connectors: []ArgoCDDexConnector{{ID: "github", GitHub: &ArgoCDDexGitHubConnector{ClientID: "github-client"}}}},
+				}
+			}),
+		},
+		{
+			name: "dex provider with keycloak spec",
+			argocd: makeTestArgoCDForWebhook(func(a *ArgoCD) {
+				a.Spec.SSO = &ArgoCDSSOSpec{
+					Provider: SSOProviderTypeDex,
+					Dex:      &ArgoCDDexSpec{OpenShiftOAuth: true},
+					Keycloak: &ArgoCDKeycloakSpec{},
+				}
+			}),
+			wantFields: []string{"spec.sso.keycloak"},
+		},
+		{
+			name: "keycloak provider with dex spec",
+			argocd: makeTestArgoCDForWebhook(func(a *ArgoCD) {
+				a.Spec.SSO = &ArgoCDSSOSpec{
+					Provider: SSOProviderTypeKeycloak,
+					Dex:      &ArgoCDDexSpec{OpenShiftOAuth: true},
+				}
+			}),
+			wantFields: []string{"spec.sso.dex"},
+		},
+		{
+			name: "external keycloak without url and admin credentials",
+			argocd: makeTestArgoCDForWebhook(func(a *ArgoCD) {
+				a.Spec.SSO = &ArgoCDSSOSpec{
+					Provider: SSOProviderTypeKeycloak,
+					Keycloak: &ArgoCDKeycloakSpec{
+						External: &ArgoCDKeycloakExternalSpec{URL: "keycloak.example.com"},
+						Database: &ArgoCDKeycloakDatabaseSpec{},
+					},
+				}
+			}),
+			wantFields: []string{"spec.sso.keycloak.external.url", "spec.sso.keycloak.external.adminCredentialsSecret.name",
+				"spec.sso.keycloak.database"},
+		},
+		{
+			name: "external keycloak",
+			argocd: makeTestArgoCDForWebhook(func(a *ArgoCD) {
+				a.Spec.SSO = &ArgoCDSSOSpec{
+					Provider: SSOProviderTypeKeycloak,
+					Keycloak: &ArgoCDKeycloakSpec{External: &ArgoCDKeycloakExternalSpec{
+						URL:                    "https://keycloak.example.com",
+						AdminCredentialsSecret: corev1.LocalObjectReference{Name: "keycloak-admin"},
+					}},
+				}
+			}),
+		},
+		{
+			name: "external keycloak database without credentials",
+			argocd: makeTestArgoCDForWebhook(func(a *ArgoCD) {
+				a.Spec.SSO = &ArgoCDSSOSpec{
+					Provider: SSOProviderTypeKeycloak,
+					Keycloak: &ArgoCDKeycloakSpec{Database: &ArgoCDKeycloakDatabaseSpec{Host: "postgresql.example.com"}},
+				}
+			}),
+			wantFields: []string{"spec.sso.keycloak.database.credentialsSecret"},
+		},
+		{
+			name: "provider spec without provider",
+			argocd: makeTestArgoCDForWebhook(func(a *ArgoCD) {
+				a.Spec.SSO = &ArgoCDSSOSpec{Keycloak: &ArgoCDKeycloakSpec{}}
+			}),
+			wantFields: []string{"spec.sso.provider"},
+		},
+		{
+			name: "remote redis auth without password secret",
+			argocd: makeTestArgoCDForWebhook(func(a *ArgoCD) {
+				remote := "redis.example.com:6379"
+				a.Spec.Redis.Remote = &remote
+				a.Spec.Redis.Auth = &ArgoCDRedisAuthSpec{Enabled: true}
+			}),
+			wantFields: []string{"spec.redis.auth.passwordSecret"},
+		},
+		{
+			name: "unsupported provider",
+			argocd: makeTestArgoCDForWebhook(func(a *ArgoCD) {
+				a.Spec.SSO = &ArgoCDSSOSpec{Provider: "okta"}
+			}),
+			wantFields: []string{"spec.sso.provider"},
+		},
+		{
+			name: "invalid resource tracking method",
+			argocd: makeTestArgoCDForWebhook(func(a *ArgoCD) {
+				a.Spec.ResourceTrackingMethod = "labels"
+			}),
+			wantFields: []string{"spec.resourceTrackingMethod"},
+		},
+		{
+			name: "server extra args override default flag",
+			argocd: makeTestArgoCDForWebhook(func(a *ArgoCD) {
+				a.Spec.Server.ExtraCommandArgs = []string{"--rootpath", "/argocd", "--loglevel=debug"}
+			}),
+			wantFields: []string{"spec.server.extraCommandArgs[2]"},
+		},
+		{
+			name: "insecure extra arg allowed when server is secure",
+			argocd: makeTestArgoCDForWebhook(func(a *ArgoCD) {
+				a.Spec.Server.ExtraCommandArgs = []string{"--insecure"}
+			}),
+		},
+		{
+			name: "duplicate repo extra args",
+			argocd: makeTestArgoCDForWebhook(func(a *ArgoCD) {
+				a.Spec.Repo.ExtraRepoCommandArgs = []string{"--parallelismlimit", "2", "--parallelismlimit", "3"}
+			}),
+			wantFields: []string{"spec.repo.extraRepoCommandArgs[2]"},
+		},
+		{
+			name: "applicationset extra args override repo server flag",
+			argocd: makeTestArgoCDForWebhook(func(a *ArgoCD) {
+				a.Spec.ApplicationSet = &ArgoCDApplicationSet{
+					ExtraCommandArgs: []string{"--argocd-repo-server", "foo:8081"},
+				}
+			}),
+			wantFields: []string{"spec.applicationSet.extraCommandArgs[0]"},
+		},
+		{
+			name: "malformed extra config",
+			argocd: makeTestArgoCDForWebhook(func(a *ArgoCD) {
+				a.Spec.ExtraConfig = map[string]string{
+					"oidc.config":      "name: [unterminated",
+					"admin.enabled":    "yes please",
+					"invalid/key":      "value",
+					"url":              "https://argocd.example.com",
+					"ui.bannercontent": "hello",
+				}
+			}),
+			wantFields: []string{"spec.extraConfig[oidc.config]", "spec.extraConfig[admin.enabled]", "spec.extraConfig[invalid/key]"},
+		},
+		{
+			name: "import backup ID",
+			argocd: makeTestArgoCDForWebhook(func(a *ArgoCD) {
+				a.Spec.Import = &ArgoCDImportSpec{Name: "export", BackupID: "20231017T120000Z"}
+			}),
+		},
+		{
+			name: "malformed import backup ID",
+			argocd: makeTestArgoCDForWebhook(func(a *ArgoCD) {
+				a.Spec.Import = &ArgoCDImportSpec{Name: "export", BackupID: "2023-10-17"}
+			}),
+			wantFields: []string{"spec.import.backupID"},
+		},
+		{
+			name: "certificate renewal time",
+			argocd: makeTestArgoCDForWebhook(func(a *ArgoCD) {
+				a.Spec.TLS.RenewBefore = &metav1.Duration{Duration: 240 * time.Hour}
+			}),
+		},
+		{
+			name: "zero certificate renewal time",
+			argocd: makeTestArgoCDForWebhook(func(a *ArgoCD) {
+				a.Spec.TLS.RenewBefore = &metav1.Duration{}
+			}),
+			wantFields: []string{"spec.tls.renewBefore"},
+		},
+		{
+			name: "certificate renewal time beyond the certificate lifetime",
+			argocd: makeTestArgoCDForWebhook(func(a *ArgoCD) {
+				a.Spec.TLS.RenewBefore = &metav1.Duration{Duration: 365 * 24 * time.Hour}
+			}),
+			wantFields: []string{"spec.tls.renewBefore"},
+		},
+		{
+			name: "pod disruption budgets",
+			argocd: makeTestArgoCDForWebhook(func(a *ArgoCD) {
+				minAvailable := intstr.FromInt(1)
+				maxUnavailable := intstr.FromString("50%")
+				a.Spec.Server.PDB = &ArgoCDPodDisruptionBudgetSpec{MinAvailable: &minAvailable}
+				a.Spec.Repo.PDB = &ArgoCDPodDisruptionBudgetSpec{MaxUnavailable: &maxUnavailable}
+			}),
+		},
+		{
+			name: "pod disruption budgets with both minAvailable and maxUnavailable",
+			argocd: makeTestArgoCDForWebhook(func(a *ArgoCD) {
+				minAvailable := intstr.FromInt(1)
+				maxUnavailable := intstr.FromInt(1)
+				pdb := &ArgoCDPodDisruptionBudgetSpec{MinAvailable: &minAvailable, MaxUnavailable: &maxUnavailable}
+				a.Spec.Server.PDB = pdb
+				a.Spec.HA.PDB = pdb
+				a.Spec.ApplicationSet = &ArgoCDApplicationSet{PDB: pdb}
+				a.Spec.SSO = &ArgoCDSSOSpec{Provider: SSOProviderTypeDex, Dex: &ArgoCDDexSpec{OpenShiftOAuth: true, PDB: pdb}}
+			}),
+			wantFields: []string{"spec.server.pdb.maxUnavailable", "spec.ha.pdb.maxUnavailable",
+				"spec.applicationSet.pdb.maxUnavailable", "spec.sso.dex.pdb.maxUnavailable"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := test.argocd.ValidateCreate()
+			if len(test.wantFields) == 0 {
+				assert.NoError(t, err)
+				return
+			}
+			assert.Error(t, err)
+			assert.ElementsMatch(t, test.wantFields, causeFields(t, err))
+		})
+	}
+}
+
+func TestArgoCD_ValidateCreate_Warnings(t *testing.T) {
+	a := makeTestArgoCDForWebhook(func(a *ArgoCD) {
+		a.Spec.Controller.Sharding.DynamicScalingEnabled = boolPtr(true)
+		a.Spec.Controller.Sharding.Replicas = 3
+	})
+	a.Default()
+
+	warnings, err := a.ValidateCreate()
+	assert.NoError(t, err)
+	assert.Len(t, warnings, 1)
+}
+
+func TestArgoCD_ValidateUpdate_Deleting(t *testing.T) {
+	a := makeTestArgoCDForWebhook(func(a *ArgoCD) {
+		a.Spec.ResourceTrackingMethod = "labels"
+		now := metav1.Now()
+		a.DeletionTimestamp = &now
+	})
+
+	_, err := a.ValidateUpdate(makeTestArgoCDForWebhook())
+	assert.NoError(t, err)
+}
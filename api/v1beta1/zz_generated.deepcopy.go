@@ -27,7 +27,8 @@ import (
 	"k8s.io/api/core/v1"
 	networkingv1 "k8s.io/api/networking/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	runtime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
@@ -36,7 +37,7 @@ func (in *ArgoCD) DeepCopyInto(out *ArgoCD) {
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
-	out.Status = in.Status
+	in.Status.DeepCopyInto(&out.Status)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ArgoCD.
@@ -119,6 +120,21 @@ func (in *ArgoCDApplicationControllerSpec) DeepCopyInto(out *ArgoCDApplicationCo
 		*out = new(bool)
 		**out = **in
 	}
+	if in.PDB != nil {
+		in, out := &in.PDB, &out.PDB
+		*out = new(ArgoCDPodDisruptionBudgetSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.NodePlacement != nil {
+		in, out := &in.NodePlacement, &out.NodePlacement
+		*out = new(ArgoCDNodePlacementSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ImagePullSecrets != nil {
+		in, out := &in.ImagePullSecrets, &out.ImagePullSecrets
+		*out = make([]v1.LocalObjectReference, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ArgoCDApplicationControllerSpec.
@@ -157,6 +173,21 @@ func (in *ArgoCDApplicationSet) DeepCopyInto(out *ArgoCDApplicationSet) {
 		*out = new(bool)
 		**out = **in
 	}
+	if in.PDB != nil {
+		in, out := &in.PDB, &out.PDB
+		*out = new(ArgoCDPodDisruptionBudgetSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.NodePlacement != nil {
+		in, out := &in.NodePlacement, &out.NodePlacement
+		*out = new(ArgoCDNodePlacementSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ImagePullSecrets != nil {
+		in, out := &in.ImagePullSecrets, &out.ImagePullSecrets
+		*out = make([]v1.LocalObjectReference, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ArgoCDApplicationSet.
@@ -184,6 +215,37 @@ func (in *ArgoCDCASpec) DeepCopy() *ArgoCDCASpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ArgoCDCertManagerIssuerReference) DeepCopyInto(out *ArgoCDCertManagerIssuerReference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ArgoCDCertManagerIssuerReference.
+func (in *ArgoCDCertManagerIssuerReference) DeepCopy() *ArgoCDCertManagerIssuerReference {
+	if in == nil {
+		return nil
+	}
+	out := new(ArgoCDCertManagerIssuerReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ArgoCDCertManagerSpec) DeepCopyInto(out *ArgoCDCertManagerSpec) {
+	*out = *in
+	out.IssuerRef = in.IssuerRef
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ArgoCDCertManagerSpec.
+func (in *ArgoCDCertManagerSpec) DeepCopy() *ArgoCDCertManagerSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ArgoCDCertManagerSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ArgoCDCertificateSpec) DeepCopyInto(out *ArgoCDCertificateSpec) {
 	*out = *in
@@ -199,6 +261,258 @@ func (in *ArgoCDCertificateSpec) DeepCopy() *ArgoCDCertificateSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ArgoCDDexConnector) DeepCopyInto(out *ArgoCDDexConnector) {
+	*out = *in
+	if in.GitHub != nil {
+		in, out := &in.GitHub, &out.GitHub
+		*out = new(ArgoCDDexGitHubConnector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.GitLab != nil {
+		in, out := &in.GitLab, &out.GitLab
+		*out = new(ArgoCDDexGitLabConnector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.LDAP != nil {
+		in, out := &in.LDAP, &out.LDAP
+		*out = new(ArgoCDDexLDAPConnector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.OIDC != nil {
+		in, out := &in.OIDC, &out.OIDC
+		*out = new(ArgoCDDexOIDCConnector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SAML != nil {
+		in, out := &in.SAML, &out.SAML
+		*out = new(ArgoCDDexSAMLConnector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Microsoft != nil {
+		in, out := &in.Microsoft, &out.Microsoft
+		*out = new(ArgoCDDexMicrosoftConnector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ArgoCDDexConnector.
+func (in *ArgoCDDexConnector) DeepCopy() *ArgoCDDexConnector {
+	if in == nil {
+		return nil
+	}
+	out := new(ArgoCDDexConnector)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ArgoCDDexGitHubConnector) DeepCopyInto(out *ArgoCDDexGitHubConnector) {
+	*out = *in
+	in.ClientSecret.DeepCopyInto(&out.ClientSecret)
+	if in.Orgs != nil {
+		in, out := &in.Orgs, &out.Orgs
+		*out = make([]ArgoCDDexGitHubOrg, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ArgoCDDexGitHubConnector.
+func (in *ArgoCDDexGitHubConnector) DeepCopy() *ArgoCDDexGitHubConnector {
+	if in == nil {
+		return nil
+	}
+	out := new(ArgoCDDexGitHubConnector)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ArgoCDDexGitHubOrg) DeepCopyInto(out *ArgoCDDexGitHubOrg) {
+	*out = *in
+	if in.Teams != nil {
+		in, out := &in.Teams, &out.Teams
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ArgoCDDexGitHubOrg.
+func (in *ArgoCDDexGitHubOrg) DeepCopy() *ArgoCDDexGitHubOrg {
+	if in == nil {
+		return nil
+	}
+	out := new(ArgoCDDexGitHubOrg)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ArgoCDDexGitLabConnector) DeepCopyInto(out *ArgoCDDexGitLabConnector) {
+	*out = *in
+	in.ClientSecret.DeepCopyInto(&out.ClientSecret)
+	if in.Groups != nil {
+		in, out := &in.Groups, &out.Groups
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ArgoCDDexGitLabConnector.
+func (in *ArgoCDDexGitLabConnector) DeepCopy() *ArgoCDDexGitLabConnector {
+	if in == nil {
+		return nil
+	}
+	out := new(ArgoCDDexGitLabConnector)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ArgoCDDexLDAPConnector) DeepCopyInto(out *ArgoCDDexLDAPConnector) {
+	*out = *in
+	if in.RootCA != nil {
+		in, out := &in.RootCA, &out.RootCA
+		*out = new(v1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.BindPW != nil {
+		in, out := &in.BindPW, &out.BindPW
+		*out = new(v1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	out.UserSearch = in.UserSearch
+	if in.GroupSearch != nil {
+		in, out := &in.GroupSearch, &out.GroupSearch
+		*out = new(ArgoCDDexLDAPGroupSearch)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ArgoCDDexLDAPConnector.
+func (in *ArgoCDDexLDAPConnector) DeepCopy() *ArgoCDDexLDAPConnector {
+	if in == nil {
+		return nil
+	}
+	out := new(ArgoCDDexLDAPConnector)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ArgoCDDexLDAPGroupSearch) DeepCopyInto(out *ArgoCDDexLDAPGroupSearch) {
+	*out = *in
+	if in.UserMatchers != nil {
+		in, out := &in.UserMatchers, &out.UserMatchers
+		*out = make([]ArgoCDDexLDAPUserMatcher, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ArgoCDDexLDAPGroupSearch.
+func (in *ArgoCDDexLDAPGroupSearch) DeepCopy() *ArgoCDDexLDAPGroupSearch {
+	if in == nil {
+		return nil
+	}
+	out := new(ArgoCDDexLDAPGroupSearch)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ArgoCDDexLDAPUserMatcher) DeepCopyInto(out *ArgoCDDexLDAPUserMatcher) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ArgoCDDexLDAPUserMatcher.
+func (in *ArgoCDDexLDAPUserMatcher) DeepCopy() *ArgoCDDexLDAPUserMatcher {
+	if in == nil {
+		return nil
+	}
+	out := new(ArgoCDDexLDAPUserMatcher)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ArgoCDDexLDAPUserSearch) DeepCopyInto(out *ArgoCDDexLDAPUserSearch) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ArgoCDDexLDAPUserSearch.
+func (in *ArgoCDDexLDAPUserSearch) DeepCopy() *ArgoCDDexLDAPUserSearch {
+	if in == nil {
+		return nil
+	}
+	out := new(ArgoCDDexLDAPUserSearch)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ArgoCDDexMicrosoftConnector) DeepCopyInto(out *ArgoCDDexMicrosoftConnector) {
+	*out = *in
+	in.ClientSecret.DeepCopyInto(&out.ClientSecret)
+	if in.Groups != nil {
+		in, out := &in.Groups, &out.Groups
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ArgoCDDexMicrosoftConnector.
+func (in *ArgoCDDexMicrosoftConnector) DeepCopy() *ArgoCDDexMicrosoftConnector {
+	if in == nil {
+		return nil
+	}
+	out := new(ArgoCDDexMicrosoftConnector)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ArgoCDDexOIDCConnector) DeepCopyInto(out *ArgoCDDexOIDCConnector) {
+	*out = *in
+	in.ClientSecret.DeepCopyInto(&out.ClientSecret)
+	if in.Scopes != nil {
+		in, out := &in.Scopes, &out.Scopes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ArgoCDDexOIDCConnector.
+func (in *ArgoCDDexOIDCConnector) DeepCopy() *ArgoCDDexOIDCConnector {
+	if in == nil {
+		return nil
+	}
+	out := new(ArgoCDDexOIDCConnector)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ArgoCDDexSAMLConnector) DeepCopyInto(out *ArgoCDDexSAMLConnector) {
+	*out = *in
+	if in.CA != nil {
+		in, out := &in.CA, &out.CA
+		*out = new(v1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ArgoCDDexSAMLConnector.
+func (in *ArgoCDDexSAMLConnector) DeepCopy() *ArgoCDDexSAMLConnector {
+	if in == nil {
+		return nil
+	}
+	out := new(ArgoCDDexSAMLConnector)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ArgoCDDexSpec) DeepCopyInto(out *ArgoCDDexSpec) {
 	*out = *in
@@ -219,6 +533,28 @@ func (in *ArgoCDDexSpec) DeepCopyInto(out *ArgoCDDexSpec) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.PDB != nil {
+		in, out := &in.PDB, &out.PDB
+		*out = new(ArgoCDPodDisruptionBudgetSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.NodePlacement != nil {
+		in, out := &in.NodePlacement, &out.NodePlacement
+		*out = new(ArgoCDNodePlacementSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ImagePullSecrets != nil {
+		in, out := &in.ImagePullSecrets, &out.ImagePullSecrets
+		*out = make([]v1.LocalObjectReference, len(*in))
+		copy(*out, *in)
+	}
+	if in.Connectors != nil {
+		in, out := &in.Connectors, &out.Connectors
+		*out = make([]ArgoCDDexConnector, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ArgoCDDexSpec.
@@ -231,6 +567,35 @@ func (in *ArgoCDDexSpec) DeepCopy() *ArgoCDDexSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ArgoCDGatewaySpec) DeepCopyInto(out *ArgoCDGatewaySpec) {
+	*out = *in
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ArgoCDGatewaySpec.
+func (in *ArgoCDGatewaySpec) DeepCopy() *ArgoCDGatewaySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ArgoCDGatewaySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ArgoCDGrafanaSpec) DeepCopyInto(out *ArgoCDGrafanaSpec) {
 	*out = *in
@@ -266,6 +631,21 @@ func (in *ArgoCDHASpec) DeepCopyInto(out *ArgoCDHASpec) {
 		*out = new(v1.ResourceRequirements)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.PDB != nil {
+		in, out := &in.PDB, &out.PDB
+		*out = new(ArgoCDPodDisruptionBudgetSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.NodePlacement != nil {
+		in, out := &in.NodePlacement, &out.NodePlacement
+		*out = new(ArgoCDNodePlacementSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ImagePullSecrets != nil {
+		in, out := &in.ImagePullSecrets, &out.ImagePullSecrets
+		*out = make([]v1.LocalObjectReference, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ArgoCDHASpec.
@@ -298,6 +678,29 @@ func (in *ArgoCDImportSpec) DeepCopy() *ArgoCDImportSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ArgoCDImportStatus) DeepCopyInto(out *ArgoCDImportStatus) {
+	*out = *in
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ArgoCDImportStatus.
+func (in *ArgoCDImportStatus) DeepCopy() *ArgoCDImportStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ArgoCDImportStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ArgoCDIngressSpec) DeepCopyInto(out *ArgoCDIngressSpec) {
 	*out = *in
@@ -332,6 +735,52 @@ func (in *ArgoCDIngressSpec) DeepCopy() *ArgoCDIngressSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ArgoCDKeycloakDatabaseSpec) DeepCopyInto(out *ArgoCDKeycloakDatabaseSpec) {
+	*out = *in
+	if in.CredentialsSecret != nil {
+		in, out := &in.CredentialsSecret, &out.CredentialsSecret
+		*out = new(v1.LocalObjectReference)
+		**out = **in
+	}
+	if in.Size != nil {
+		in, out := &in.Size, &out.Size
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.StorageClassName != nil {
+		in, out := &in.StorageClassName, &out.StorageClassName
+		*out = new(string)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ArgoCDKeycloakDatabaseSpec.
+func (in *ArgoCDKeycloakDatabaseSpec) DeepCopy() *ArgoCDKeycloakDatabaseSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ArgoCDKeycloakDatabaseSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ArgoCDKeycloakExternalSpec) DeepCopyInto(out *ArgoCDKeycloakExternalSpec) {
+	*out = *in
+	out.AdminCredentialsSecret = in.AdminCredentialsSecret
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ArgoCDKeycloakExternalSpec.
+func (in *ArgoCDKeycloakExternalSpec) DeepCopy() *ArgoCDKeycloakExternalSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ArgoCDKeycloakExternalSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ArgoCDKeycloakSpec) DeepCopyInto(out *ArgoCDKeycloakSpec) {
 	*out = *in
@@ -345,6 +794,16 @@ func (in *ArgoCDKeycloakSpec) DeepCopyInto(out *ArgoCDKeycloakSpec) {
 		*out = new(bool)
 		**out = **in
 	}
+	if in.Database != nil {
+		in, out := &in.Database, &out.Database
+		*out = new(ArgoCDKeycloakDatabaseSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.External != nil {
+		in, out := &in.External, &out.External
+		*out = new(ArgoCDKeycloakExternalSpec)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ArgoCDKeycloakSpec.
@@ -404,6 +863,31 @@ func (in *ArgoCDMonitoringSpec) DeepCopy() *ArgoCDMonitoringSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ArgoCDNetworkPolicySpec) DeepCopyInto(out *ArgoCDNetworkPolicySpec) {
+	*out = *in
+	if in.IngressNamespaceSelector != nil {
+		in, out := &in.IngressNamespaceSelector, &out.IngressNamespaceSelector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.MetricsNamespaceSelector != nil {
+		in, out := &in.MetricsNamespaceSelector, &out.MetricsNamespaceSelector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ArgoCDNetworkPolicySpec.
+func (in *ArgoCDNetworkPolicySpec) DeepCopy() *ArgoCDNetworkPolicySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ArgoCDNetworkPolicySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ArgoCDNodePlacementSpec) DeepCopyInto(out *ArgoCDNodePlacementSpec) {
 	*out = *in
@@ -421,6 +905,23 @@ func (in *ArgoCDNodePlacementSpec) DeepCopyInto(out *ArgoCDNodePlacementSpec) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.Affinity != nil {
+		in, out := &in.Affinity, &out.Affinity
+		*out = new(v1.Affinity)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.TopologySpreadConstraints != nil {
+		in, out := &in.TopologySpreadConstraints, &out.TopologySpreadConstraints
+		*out = make([]v1.TopologySpreadConstraint, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.RuntimeClassName != nil {
+		in, out := &in.RuntimeClassName, &out.RuntimeClassName
+		*out = new(string)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ArgoCDNodePlacementSpec.
@@ -453,6 +954,21 @@ func (in *ArgoCDNotifications) DeepCopyInto(out *ArgoCDNotifications) {
 		*out = new(v1.ResourceRequirements)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.PDB != nil {
+		in, out := &in.PDB, &out.PDB
+		*out = new(ArgoCDPodDisruptionBudgetSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.NodePlacement != nil {
+		in, out := &in.NodePlacement, &out.NodePlacement
+		*out = new(ArgoCDNodePlacementSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ImagePullSecrets != nil {
+		in, out := &in.ImagePullSecrets, &out.ImagePullSecrets
+		*out = make([]v1.LocalObjectReference, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ArgoCDNotifications.
@@ -465,6 +981,36 @@ func (in *ArgoCDNotifications) DeepCopy() *ArgoCDNotifications {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ArgoCDPodDisruptionBudgetSpec) DeepCopyInto(out *ArgoCDPodDisruptionBudgetSpec) {
+	*out = *in
+	if in.Enabled != nil {
+		in, out := &in.Enabled, &out.Enabled
+		*out = new(bool)
+		**out = **in
+	}
+	if in.MaxUnavailable != nil {
+		in, out := &in.MaxUnavailable, &out.MaxUnavailable
+		*out = new(intstr.IntOrString)
+		**out = **in
+	}
+	if in.MinAvailable != nil {
+		in, out := &in.MinAvailable, &out.MinAvailable
+		*out = new(intstr.IntOrString)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ArgoCDPodDisruptionBudgetSpec.
+func (in *ArgoCDPodDisruptionBudgetSpec) DeepCopy() *ArgoCDPodDisruptionBudgetSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ArgoCDPodDisruptionBudgetSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ArgoCDPrometheusSpec) DeepCopyInto(out *ArgoCDPrometheusSpec) {
 	*out = *in
@@ -522,6 +1068,26 @@ func (in *ArgoCDRBACSpec) DeepCopy() *ArgoCDRBACSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ArgoCDRedisAuthSpec) DeepCopyInto(out *ArgoCDRedisAuthSpec) {
+	*out = *in
+	if in.PasswordSecret != nil {
+		in, out := &in.PasswordSecret, &out.PasswordSecret
+		*out = new(v1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ArgoCDRedisAuthSpec.
+func (in *ArgoCDRedisAuthSpec) DeepCopy() *ArgoCDRedisAuthSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ArgoCDRedisAuthSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ArgoCDRedisSpec) DeepCopyInto(out *ArgoCDRedisSpec) {
 	*out = *in
@@ -540,6 +1106,26 @@ func (in *ArgoCDRedisSpec) DeepCopyInto(out *ArgoCDRedisSpec) {
 		*out = new(string)
 		**out = **in
 	}
+	if in.PDB != nil {
+		in, out := &in.PDB, &out.PDB
+		*out = new(ArgoCDPodDisruptionBudgetSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.NodePlacement != nil {
+		in, out := &in.NodePlacement, &out.NodePlacement
+		*out = new(ArgoCDNodePlacementSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ImagePullSecrets != nil {
+		in, out := &in.ImagePullSecrets, &out.ImagePullSecrets
+		*out = make([]v1.LocalObjectReference, len(*in))
+		copy(*out, *in)
+	}
+	if in.Auth != nil {
+		in, out := &in.Auth, &out.Auth
+		*out = new(ArgoCDRedisAuthSpec)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ArgoCDRedisSpec.
@@ -620,6 +1206,21 @@ func (in *ArgoCDRepoSpec) DeepCopyInto(out *ArgoCDRepoSpec) {
 		*out = new(string)
 		**out = **in
 	}
+	if in.PDB != nil {
+		in, out := &in.PDB, &out.PDB
+		*out = new(ArgoCDPodDisruptionBudgetSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.NodePlacement != nil {
+		in, out := &in.NodePlacement, &out.NodePlacement
+		*out = new(ArgoCDNodePlacementSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ImagePullSecrets != nil {
+		in, out := &in.ImagePullSecrets, &out.ImagePullSecrets
+		*out = make([]v1.LocalObjectReference, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ArgoCDRepoSpec.
@@ -719,6 +1320,7 @@ func (in *ArgoCDServerAutoscaleSpec) DeepCopy() *ArgoCDServerAutoscaleSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ArgoCDServerGRPCSpec) DeepCopyInto(out *ArgoCDServerGRPCSpec) {
 	*out = *in
+	in.Gateway.DeepCopyInto(&out.Gateway)
 	in.Ingress.DeepCopyInto(&out.Ingress)
 }
 
@@ -751,6 +1353,7 @@ func (in *ArgoCDServerServiceSpec) DeepCopy() *ArgoCDServerServiceSpec {
 func (in *ArgoCDServerSpec) DeepCopyInto(out *ArgoCDServerSpec) {
 	*out = *in
 	in.Autoscale.DeepCopyInto(&out.Autoscale)
+	in.Gateway.DeepCopyInto(&out.Gateway)
 	in.GRPC.DeepCopyInto(&out.GRPC)
 	in.Ingress.DeepCopyInto(&out.Ingress)
 	if in.Replicas != nil {
@@ -782,6 +1385,21 @@ func (in *ArgoCDServerSpec) DeepCopyInto(out *ArgoCDServerSpec) {
 		*out = new(bool)
 		**out = **in
 	}
+	if in.PDB != nil {
+		in, out := &in.PDB, &out.PDB
+		*out = new(ArgoCDPodDisruptionBudgetSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.NodePlacement != nil {
+		in, out := &in.NodePlacement, &out.NodePlacement
+		*out = new(ArgoCDNodePlacementSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ImagePullSecrets != nil {
+		in, out := &in.ImagePullSecrets, &out.ImagePullSecrets
+		*out = make([]v1.LocalObjectReference, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ArgoCDServerSpec.
@@ -812,6 +1430,11 @@ func (in *ArgoCDSpec) DeepCopyInto(out *ArgoCDSpec) {
 	}
 	in.Grafana.DeepCopyInto(&out.Grafana)
 	in.HA.DeepCopyInto(&out.HA)
+	if in.ImagePullSecrets != nil {
+		in, out := &in.ImagePullSecrets, &out.ImagePullSecrets
+		*out = make([]v1.LocalObjectReference, len(*in))
+		copy(*out, *in)
+	}
 	if in.Import != nil {
 		in, out := &in.Import, &out.Import
 		*out = new(ArgoCDImportSpec)
@@ -824,6 +1447,11 @@ func (in *ArgoCDSpec) DeepCopyInto(out *ArgoCDSpec) {
 		copy(*out, *in)
 	}
 	out.Monitoring = in.Monitoring
+	if in.NetworkPolicy != nil {
+		in, out := &in.NetworkPolicy, &out.NetworkPolicy
+		*out = new(ArgoCDNetworkPolicySpec)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.NodePlacement != nil {
 		in, out := &in.NodePlacement, &out.NodePlacement
 		*out = new(ArgoCDNodePlacementSpec)
@@ -881,6 +1509,23 @@ func (in *ArgoCDSpec) DeepCopy() *ArgoCDSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ArgoCDStatus) DeepCopyInto(out *ArgoCDStatus) {
 	*out = *in
+	if in.TLS != nil {
+		in, out := &in.TLS, &out.TLS
+		*out = new(ArgoCDTLSStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Import != nil {
+		in, out := &in.Import, &out.Import
+		*out = new(ArgoCDImportStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ArgoCDStatus.
@@ -897,6 +1542,11 @@ func (in *ArgoCDStatus) DeepCopy() *ArgoCDStatus {
 func (in *ArgoCDTLSSpec) DeepCopyInto(out *ArgoCDTLSSpec) {
 	*out = *in
 	out.CA = in.CA
+	if in.CertManager != nil {
+		in, out := &in.CertManager, &out.CertManager
+		*out = new(ArgoCDCertManagerSpec)
+		**out = **in
+	}
 	if in.InitialCerts != nil {
 		in, out := &in.InitialCerts, &out.InitialCerts
 		*out = make(map[string]string, len(*in))
@@ -904,6 +1554,11 @@ func (in *ArgoCDTLSSpec) DeepCopyInto(out *ArgoCDTLSSpec) {
 			(*out)[key] = val
 		}
 	}
+	if in.RenewBefore != nil {
+		in, out := &in.RenewBefore, &out.RenewBefore
+		*out = new(metav1.Duration)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ArgoCDTLSSpec.
@@ -916,6 +1571,29 @@ func (in *ArgoCDTLSSpec) DeepCopy() *ArgoCDTLSSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ArgoCDTLSStatus) DeepCopyInto(out *ArgoCDTLSStatus) {
+	*out = *in
+	if in.CANotAfter != nil {
+		in, out := &in.CANotAfter, &out.CANotAfter
+		*out = (*in).DeepCopy()
+	}
+	if in.NotAfter != nil {
+		in, out := &in.NotAfter, &out.NotAfter
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ArgoCDTLSStatus.
+func (in *ArgoCDTLSStatus) DeepCopy() *ArgoCDTLSStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ArgoCDTLSStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Banner) DeepCopyInto(out *Banner) {
 	*out = *in
@@ -1067,6 +1745,7 @@ func (in *SSHHostsSpec) DeepCopy() *SSHHostsSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *WebhookServerSpec) DeepCopyInto(out *WebhookServerSpec) {
 	*out = *in
+	in.Gateway.DeepCopyInto(&out.Gateway)
 	in.Ingress.DeepCopyInto(&out.Ingress)
 	in.Route.DeepCopyInto(&out.Route)
 }
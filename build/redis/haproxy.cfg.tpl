@@ -101,6 +101,10 @@ backend bk_redis_master
     tcp-check connect
 {{- else}}
     tcp-check connect ssl
+{{- end}}
+{{- if eq .UseAuth "true"}}
+    tcp-check send AUTH\ REPLACE_AUTH_SECRET\r\n
+    tcp-check expect string +OK
 {{- end}}
     tcp-check send PING\r\n
     tcp-check expect string +PONG
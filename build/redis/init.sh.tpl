@@ -132,6 +132,9 @@ setup_defaults() {
 
 redis_ping() {
 set +e
+{{- if eq .UseAuth "true"}}
+    export REDISCLI_AUTH="${AUTH}"
+{{- end}}
     if [ "$REDIS_PORT" -eq 0 ]; then
         redis-cli -h "${MASTER}" -p "${REDIS_TLS_PORT}"  --tls --cacert /app/config/redis/tls/tls.crt ping
     else
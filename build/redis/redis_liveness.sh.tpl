@@ -1,4 +1,5 @@
-response=$(
+{{if eq .UseAuth "true"}}export REDISCLI_AUTH="${AUTH}"
+{{end}}response=$(
   redis-cli \
     -h localhost \
     -p 6379 \
@@ -15,3 +15,6 @@ bind 0.0.0.0
     sentinel failover-timeout argocd 180000
     maxclients 10000
     sentinel parallel-syncs argocd 5
+{{- if eq .UseAuth "true"}}
+    sentinel auth-pass argocd replace-default-auth
+{{- end}}
@@ -0,0 +1,102 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Command argocd-operator-util exports and imports Argo CD data for ArgoCDExport resources.
+//
+// Usage:
+//
+//	argocd-operator-util [flags] export|import [local|aws|azure|gcp|s3]
+//
+// The retention policy of exports and the backup to import are configured by the operator through the
+// ARGOCD_EXPORT_RETENTION_KEEP_LAST, ARGOCD_EXPORT_RETENTION_MAX_AGE and ARGOCD_IMPORT_BACKUP_ID environment
+// variables.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+
+	"github.com/argoproj-labs/argocd-operator/common"
+	"github.com/argoproj-labs/argocd-operator/pkg/backup"
+)
+
+func main() {
+	os.Exit(run())
+}
+
+func run() int {
+	opts := backup.Options{}
+	flag.StringVar(&opts.SecretsDir, "secrets-dir", "/secrets", "The directory the export secret is mounted at.")
+	flag.StringVar(&opts.BackupDir, "backup-dir", "/backups", "The directory used by the local storage backend.")
+	flag.StringVar(&opts.ArgoCDBinary, "argocd", "argocd", "The path to the argocd CLI.")
+	flag.StringVar(&opts.TerminationLog, "termination-log", "/dev/termination-log", "The file the result of an export or import is written to, empty to disable.")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: %s [flags] export|import [local|aws|azure|gcp|s3]\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+
+	zapOpts := zap.Options{}
+	zapOpts.BindFlags(flag.CommandLine)
+	flag.Parse()
+
+	log := zap.New(zap.UseFlagOptions(&zapOpts)).WithName("argocd-operator-util")
+
+	if flag.NArg() < 1 || flag.NArg() > 2 {
+		flag.Usage()
+		return backup.ExitUsage
+	}
+	if flag.NArg() == 2 {
+		opts.Backend = flag.Arg(1)
+	}
+
+	retention, err := backup.RetentionFromEnv()
+	if err != nil {
+		log.Error(err, "invalid retention policy")
+		return backup.ExitConfig
+	}
+	opts.Retention = retention
+	opts.BackupID = os.Getenv(common.ArgoCDImportBackupIDEnvName)
+	opts.KeyID = os.Getenv(common.ArgoCDExportKeyIDEnvName)
+	opts.Namespace = os.Getenv(common.ArgoCDExportNamespaceEnvName)
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	runner := backup.NewRunner(opts, log)
+
+	switch flag.Arg(0) {
+	case "export":
+		err = runner.Export(ctx)
+	case "import":
+		err = runner.Import(ctx)
+	default:
+		flag.Usage()
+		return backup.ExitUsage
+	}
+
+	if err != nil {
+		code := backup.ExitCode(err)
+		log.Error(err, "argocd-operator-util failed", "exitCode", code)
+		return code
+	}
+	return backup.ExitOK
+}
@@ -14,6 +14,8 @@
 
 package common
 
+import "time"
+
 const (
 	// ArgoCDApplicationControllerComponent is the name of the application controller control plane component
 	ArgoCDApplicationControllerComponent = "argocd-application-controller"
@@ -72,6 +74,10 @@ const (
 	// ArgoCDDefaultBackupKeyNumSymbols is the number of symbols to use for the generated default backup key.
 	ArgoCDDefaultBackupKeyNumSymbols = 5
 
+	// ArgoCDDefaultCertificateRenewBefore is the default time before their expiry at which the CA and TLS
+	// certificates generated by the operator are renewed.
+	ArgoCDDefaultCertificateRenewBefore = time.Hour * 24 * 30
+
 	// ArgoCDDefaultConfigManagementPlugins is the default configuration value for the config management plugins.
 	ArgoCDDefaultConfigManagementPlugins = ""
 
@@ -115,15 +121,31 @@ const (
 	// ArgoCDDefaultDexVersion is the Dex container image tag to use when not specified.
 	ArgoCDDefaultDexVersion = "sha256:d5f887574312f606c61e7e188cfb11ddb33ff3bf4bd9f06e6b1458efca75f604" // v2.30.3
 
+	// ArgoCDDefaultExportFailedJobsHistoryLimit is the number of failed scheduled export runs to keep when not specified.
+	ArgoCDDefaultExportFailedJobsHistoryLimit = 1
+
 	// ArgoCDDefaultExportJobImage is the export job container image to use when not specified.
 	ArgoCDDefaultExportJobImage = "quay.io/argoprojlabs/argocd-operator-util"
 
-	// ArgoCDDefaultExportJobVersion is the export job container image tag to use when not specified.
-	ArgoCDDefaultExportJobVersion = "sha256:6f80965a2bef1c80875be0995b18d9be5a6ad4af841cbc170ed3c60101a7deb2" // 0.5.0
+	// ArgoCDDefaultExportJobVersion is the export job container image tag to use when not specified. It remains the
+	// legacy image until an image built from build/util/Dockerfile is published.
+	ArgoCDDefaultExportJobVersion = ArgoCDLegacyExportJobVersion
+
+	// ArgoCDLegacyExportJobVersion is the tag of the last export job container image running util.sh, which is started
+	// through uid_entrypoint.sh and writes a single backup to the storage backend.
+	ArgoCDLegacyExportJobVersion = "sha256:6f80965a2bef1c80875be0995b18d9be5a6ad4af841cbc170ed3c60101a7deb2" // 0.5.0
 
 	// ArgoCDDefaultExportLocalCapicity is the default capacity to use for local export.
 	ArgoCDDefaultExportLocalCapicity = "2Gi"
 
+	// ArgoCDDefaultExportMaxPreviousBackupKeys is the number of former backup keys kept while the retained backups
+	// are re-encrypted after a key rotation.
+	ArgoCDDefaultExportMaxPreviousBackupKeys = 5
+
+	// ArgoCDDefaultExportSuccessfulJobsHistoryLimit is the number of successful scheduled export runs to keep when not
+	// specified.
+	ArgoCDDefaultExportSuccessfulJobsHistoryLimit = 3
+
 	// ArgoCDDefaultGATrackingID is the default Google Analytics tracking ID.
 	ArgoCDDefaultGATrackingID = ""
 
@@ -172,6 +194,9 @@ const (
 	// ArgoCDDefaultIngressPath is the path to use for the Ingress when not specified.
 	ArgoCDDefaultIngressPath = "/"
 
+	// ArgoCDDefaultImportJobBackoffLimit is the number of retries of a failed import Job.
+	ArgoCDDefaultImportJobBackoffLimit = int32(2)
+
 	// ArgoCDDefaultKustomizeBuildOptions is the default kustomize build options.
 	ArgoCDDefaultKustomizeBuildOptions = ""
 
@@ -181,6 +206,26 @@ const (
 	// ArgoCDDefaultLabelSelector is the default Label Selector which will reconcile all ArgoCD instances.
 	ArgoCDDefaultLabelSelector = ""
 
+	// ArgoCDDefaultMaxConcurrentReconciles is the default maximum number of ArgoCD instances reconciled concurrently.
+	ArgoCDDefaultMaxConcurrentReconciles = 1
+
+	// ArgoCDDefaultReconcileBaseDelay is the default delay before retrying a failed reconciliation, doubled on every
+	// consecutive failure.
+	ArgoCDDefaultReconcileBaseDelay = 5 * time.Millisecond
+
+	// ArgoCDDefaultReconcileMaxDelay is the default maximum delay before retrying a failed reconciliation.
+	ArgoCDDefaultReconcileMaxDelay = 1000 * time.Second
+
+	// ArgoCDDefaultReconcileQPS is the default overall number of reconciliations per second.
+	ArgoCDDefaultReconcileQPS = float32(10)
+
+	// ArgoCDDefaultReconcileBurst is the default burst of reconciliations allowed above the QPS.
+	ArgoCDDefaultReconcileBurst = 100
+
+	// ArgoCDDefaultRequeueAfter is the default interval after which ArgoCD instances are reconciled again, zero disables
+	// the periodic reconciliation.
+	ArgoCDDefaultRequeueAfter = time.Duration(0)
+
 	// ArgoCDKeycloakVersion is the default Keycloak version used for the non-openshift platform when not specified.
 	// Version: 15.0.2
 	ArgoCDKeycloakVersion = "sha256:64fb81886fde61dee55091e6033481fa5ccdac62ae30a4fd29b54eb5e97df6a9"
@@ -192,9 +237,48 @@ const (
 	// Version: 7.6-32
 	ArgoCDKeycloakVersionForOpenShift = "sha256:ec9f60018694dcc5d431ba47d5536b761b71cb3f66684978fe6bb74c157679ac"
 
+	// ArgoCDKeycloakPostgreSQLImage is the default image of the PostgreSQL server deployed for Keycloak when not specified.
+	ArgoCDKeycloakPostgreSQLImage = "quay.io/sclorg/postgresql-15-c9s"
+
+	// ArgoCDKeycloakPostgreSQLVersion is the default version of the PostgreSQL server deployed for Keycloak when not
+	// specified.
+	ArgoCDKeycloakPostgreSQLVersion = "latest"
+
+	// ArgoCDDefaultKeycloakDatabaseName is the name of the Keycloak database when not specified.
+	ArgoCDDefaultKeycloakDatabaseName = "keycloak"
+
+	// ArgoCDDefaultKeycloakDatabasePort is the port of the external PostgreSQL server of Keycloak when not specified.
+	ArgoCDDefaultKeycloakDatabasePort = int32(5432)
+
+	// ArgoCDDefaultKeycloakDatabaseSize is the size of the volume of the PostgreSQL server deployed for Keycloak when
+	// not specified.
+	ArgoCDDefaultKeycloakDatabaseSize = "1Gi"
+
+	// ArgoCDDefaultKeycloakDatabaseUsername is the username of the generated credentials of the Keycloak database.
+	ArgoCDDefaultKeycloakDatabaseUsername = "keycloak"
+
+	// ArgoCDDefaultKeycloakDatabasePasswordLength is the length of the generated Keycloak database password.
+	ArgoCDDefaultKeycloakDatabasePasswordLength = 32
+
+	// ArgoCDDefaultKeycloakDatabasePasswordNumDigits is the number of digits to use for the generated Keycloak
+	// database password.
+	ArgoCDDefaultKeycloakDatabasePasswordNumDigits = 5
+
+	// ArgoCDDefaultKeycloakDatabasePasswordNumSymbols is the number of symbols to use for the generated Keycloak
+	// database password.
+	ArgoCDDefaultKeycloakDatabasePasswordNumSymbols = 0
+
+	// ArgoCDDefaultKeycloakRequestTimeout is the time after which a request to the admin API of Keycloak is abandoned,
+	// so that an unreachable Keycloak does not block the reconciliation.
+	ArgoCDDefaultKeycloakRequestTimeout = 30 * time.Second
+
 	// ArgoCDDefaultOIDCConfig is the default OIDC configuration.
 	ArgoCDDefaultOIDCConfig = ""
 
+	// ArgoCDDefaultPodDisruptionBudgetMaxUnavailable is the default number of pods of a component that can be unavailable
+	// during a voluntary disruption, when its PodDisruptionBudget does not set one.
+	ArgoCDDefaultPodDisruptionBudgetMaxUnavailable = 1
+
 	// ArgoCDDefaultPrometheusReplicas is the default Prometheus replica count.
 	ArgoCDDefaultPrometheusReplicas = int32(1)
 
@@ -222,6 +306,16 @@ const (
 	// ArgoCDDefaultRedisImage is the Redis container image to use when not specified.
 	ArgoCDDefaultRedisImage = "redis"
 
+	// ArgoCDDefaultRedisPasswordLength is the length of the generated Redis password.
+	ArgoCDDefaultRedisPasswordLength = 32
+
+	// ArgoCDDefaultRedisPasswordNumDigits is the number of digits to use for the generated Redis password.
+	ArgoCDDefaultRedisPasswordNumDigits = 5
+
+	// ArgoCDDefaultRedisPasswordNumSymbols is the number of symbols to use for the generated Redis password. The
+	// password is written to the Redis and HAProxy configuration, so no symbols are used.
+	ArgoCDDefaultRedisPasswordNumSymbols = 0
+
 	// ArgoCDDefaultRedisPort is the default listen port for Redis.
 	ArgoCDDefaultRedisPort = 6379
 
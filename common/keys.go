@@ -34,6 +34,10 @@ const (
 	// ArgoCDKeyBackupKey is the "backup key" key for ConfigMaps.
 	ArgoCDKeyBackupKey = "backup.key"
 
+	// ArgoCDKeyBackupKeyPrevious is the key of the export Secret holding the former backup keys, one per line,
+	// while the retained backups are re-encrypted with the current key.
+	ArgoCDKeyBackupKeyPrevious = "backup.key.previous"
+
 	// ArgoCDKeyConfigManagementPlugins is the configuration key for config management plugins.
 	ArgoCDKeyConfigManagementPlugins = "configManagementPlugins"
 
@@ -115,9 +119,15 @@ const (
 	// ArgoCDKeyRBACPolicyDefault is the configuration key for the Argo CD RBAC default policy.
 	ArgoCDKeyRBACPolicyDefault = "policy.default"
 
+	// ArgoCDKeyPassword is the key of the password in the credentials Secrets of the Keycloak database and admin.
+	ArgoCDKeyPassword = "password"
+
 	// ArgoCDKeyRBACScopes is the configuration key for the Argo CD RBAC scopes.
 	ArgoCDKeyRBACScopes = "scopes"
 
+	// ArgoCDKeyRedisAuth is the key of the Redis password in the Redis auth Secret generated by the operator.
+	ArgoCDKeyRedisAuth = "auth"
+
 	// ArgoCDKeyRelease is the prometheus release key for labels.
 	ArgoCDKeyRelease = "release"
 
@@ -174,6 +184,9 @@ const (
 	// ArgoCDKeyTolerateUnreadyEndpounts is the resource tolerate unready endpoints key for labels.
 	ArgoCDKeyTolerateUnreadyEndpounts = "service.alpha.kubernetes.io/tolerate-unready-endpoints"
 
+	// ArgoCDKeyUsername is the key of the username in the credentials Secrets of the Keycloak database and admin.
+	ArgoCDKeyUsername = "username"
+
 	// ArgoCDKeyUsersAnonymousEnabled is the configuration key for anonymous user access.
 	ArgoCDKeyUsersAnonymousEnabled = "users.anonymous.enabled"
 
@@ -189,6 +202,10 @@ const (
 	// to used for the Keycloak container.
 	ArgoCDKeycloakImageEnvName = "ARGOCD_KEYCLOAK_IMAGE"
 
+	// ArgoCDKeycloakPostgreSQLImageEnvName is the environment variable used to get the image
+	// to used for the PostgreSQL server deployed for Keycloak.
+	ArgoCDKeycloakPostgreSQLImageEnvName = "ARGOCD_KEYCLOAK_POSTGRESQL_IMAGE"
+
 	// ArgoCDRedisHAProxyImageEnvName is the environment variable used to get the image
 	// to used for the Redis HA Proxy container.
 	ArgoCDRedisHAProxyImageEnvName = "ARGOCD_REDIS_HA_PROXY_IMAGE"
@@ -205,6 +222,78 @@ const (
 	// to used for the Grafana container.
 	ArgoCDGrafanaImageEnvName = "ARGOCD_GRAFANA_IMAGE"
 
+	// ArgoCDExportS3EndpointEnvName is the environment variable used to pass the endpoint
+	// of the S3 compatible storage backend to the export and import processes.
+	ArgoCDExportS3EndpointEnvName = "ARGOCD_EXPORT_S3_ENDPOINT"
+
+	// ArgoCDExportS3BucketEnvName is the environment variable used to pass the bucket
+	// of the S3 compatible storage backend to the export and import processes.
+	ArgoCDExportS3BucketEnvName = "ARGOCD_EXPORT_S3_BUCKET"
+
+	// ArgoCDExportS3RegionEnvName is the environment variable used to pass the region
+	// of the S3 compatible storage backend to the export and import processes.
+	ArgoCDExportS3RegionEnvName = "ARGOCD_EXPORT_S3_REGION"
+
+	// ArgoCDExportS3ForcePathStyleEnvName is the environment variable used to enable
+	// path-style addressing for the S3 compatible storage backend.
+	ArgoCDExportS3ForcePathStyleEnvName = "ARGOCD_EXPORT_S3_FORCE_PATH_STYLE"
+
+	// ArgoCDExportS3CABundleEnvName is the environment variable used to pass the path of
+	// the CA bundle file for the S3 compatible storage backend.
+	ArgoCDExportS3CABundleEnvName = "ARGOCD_EXPORT_S3_CA_BUNDLE"
+
+	// ArgoCDExportS3SSEEnvName is the environment variable used to pass the server-side
+	// encryption algorithm for the S3 compatible storage backend.
+	ArgoCDExportS3SSEEnvName = "ARGOCD_EXPORT_S3_SSE"
+
+	// ArgoCDExportS3SSEKMSKeyIDEnvName is the environment variable used to pass the KMS key
+	// ID for server-side encryption with the S3 compatible storage backend.
+	ArgoCDExportS3SSEKMSKeyIDEnvName = "ARGOCD_EXPORT_S3_SSE_KMS_KEY_ID"
+
+	// ArgoCDExportRetentionKeepLastEnvName is the environment variable used to pass the
+	// number of backups kept by the export process.
+	ArgoCDExportRetentionKeepLastEnvName = "ARGOCD_EXPORT_RETENTION_KEEP_LAST"
+
+	// ArgoCDExportRetentionMaxAgeEnvName is the environment variable used to pass the
+	// maximum age of the backups kept by the export process.
+	ArgoCDExportRetentionMaxAgeEnvName = "ARGOCD_EXPORT_RETENTION_MAX_AGE"
+
+	// ArgoCDExportKeyIDEnvName is the environment variable used to pass the ID of the
+	// backup key expected by the export process.
+	ArgoCDExportKeyIDEnvName = "ARGOCD_EXPORT_KEY_ID"
+
+	// ArgoCDExportKMSKeyIDEnvName is the environment variable used to pass the KMS key
+	// used for the envelope encryption of backups.
+	ArgoCDExportKMSKeyIDEnvName = "ARGOCD_EXPORT_KMS_KEY_ID"
+
+	// ArgoCDExportKMSRegionEnvName is the environment variable used to pass the region
+	// of the KMS used for the envelope encryption of backups.
+	ArgoCDExportKMSRegionEnvName = "ARGOCD_EXPORT_KMS_REGION"
+
+	// ArgoCDExportKMSEndpointEnvName is the environment variable used to pass the URL
+	// of the KMS used for the envelope encryption of backups.
+	ArgoCDExportKMSEndpointEnvName = "ARGOCD_EXPORT_KMS_ENDPOINT"
+
+	// ArgoCDExportNamespaceEnvName is the environment variable used to pass the namespace
+	// of the Argo CD instance to export.
+	ArgoCDExportNamespaceEnvName = "ARGOCD_EXPORT_NAMESPACE"
+
+	// ArgoCDExportNameLabel is the label used to identify the RBAC resources of an
+	// ArgoCDExport by its name.
+	ArgoCDExportNameLabel = "argocd.argoproj.io/export-name"
+
+	// ArgoCDExportNamespaceLabel is the label used to identify the RBAC resources of an
+	// ArgoCDExport by its namespace.
+	ArgoCDExportNamespaceLabel = "argocd.argoproj.io/export-namespace"
+
+	// ArgoCDExportFinalizer is a finalizer used to remove the RBAC resources of an
+	// ArgoCDExport from the namespace of the exported Argo CD instance.
+	ArgoCDExportFinalizer = "argoproj.io/export-finalizer"
+
+	// ArgoCDImportBackupIDEnvName is the environment variable used to pass the ID of
+	// the backup to restore to the import process.
+	ArgoCDImportBackupIDEnvName = "ARGOCD_IMPORT_BACKUP_ID"
+
 	// ArgoCDDeletionFinalizer is a finalizer to implement pre-delete hooks
 	ArgoCDDeletionFinalizer = "argoproj.io/finalizer"
 
@@ -217,6 +306,14 @@ const (
 	// ArgoCDManagedByLabel is needed to identify namespace managed by an instance on ArgoCD
 	ArgoCDManagedByLabel = "argocd.argoproj.io/managed-by"
 
+	// ArgoCDManagedAnnotationsAnnotation is the annotation recording the keys of the annotations that the operator
+	// sets on an object, so that they are removed once no longer desired.
+	ArgoCDManagedAnnotationsAnnotation = "argocd.argoproj.io/managed-annotations"
+
+	// ArgoCDManagedLabelsAnnotation is the annotation recording the keys of the labels that the operator sets on an
+	// object, so that they are removed once no longer desired.
+	ArgoCDManagedLabelsAnnotation = "argocd.argoproj.io/managed-labels"
+
 	// ArgoCDManagedByClusterArgoCDLabel is needed to identify namespace mentioned as sourceNamespace on ArgoCD
 	ArgoCDManagedByClusterArgoCDLabel = "argocd.argoproj.io/managed-by-cluster-argocd"
 
@@ -231,4 +328,36 @@ const (
 
 	// Label Selector is an env variable for ArgoCD instance reconcilliation.
 	ArgoCDLabelSelectorKey = "ARGOCD_LABEL_SELECTOR"
+
+	// ArgoCDMaxConcurrentReconcilesEnvName is an environment variable to specify the maximum number of ArgoCD instances reconciled concurrently.
+	ArgoCDMaxConcurrentReconcilesEnvName = "MAX_CONCURRENT_RECONCILES"
+
+	// ArgoCDReconcileBaseDelayEnvName is an environment variable to specify the delay before retrying a failed reconciliation.
+	ArgoCDReconcileBaseDelayEnvName = "RECONCILE_BASE_DELAY"
+
+	// ArgoCDReconcileMaxDelayEnvName is an environment variable to specify the maximum delay before retrying a failed reconciliation.
+	ArgoCDReconcileMaxDelayEnvName = "RECONCILE_MAX_DELAY"
+
+	// ArgoCDReconcileQPSEnvName is an environment variable to specify the overall number of reconciliations per second.
+	ArgoCDReconcileQPSEnvName = "RECONCILE_QPS"
+
+	// ArgoCDReconcileBurstEnvName is an environment variable to specify the burst of reconciliations allowed above the QPS.
+	ArgoCDReconcileBurstEnvName = "RECONCILE_BURST"
+
+	// ArgoCDRequeueAfterEnvName is an environment variable to specify the interval after which ArgoCD instances are reconciled again.
+	ArgoCDRequeueAfterEnvName = "RECONCILE_REQUEUE_AFTER"
+
+	// ArgoCDRequeueAfterAnnotation is the annotation overriding the interval after which an ArgoCD instance is reconciled again.
+	ArgoCDRequeueAfterAnnotation = "argocd.argoproj.io/requeue-after"
+
+	// ArgoCDRedisHAConfigChecksumAnnotation is the pod template annotation holding the checksum of the configuration of
+	// the Redis HA servers and proxy.
+	ArgoCDRedisHAConfigChecksumAnnotation = "checksum/init-config"
+
+	// ArgoCDRedisHATLSAnnotation is the Redis HA StatefulSet annotation recording whether its servers use TLS.
+	ArgoCDRedisHATLSAnnotation = "argocd.argoproj.io/redis-tls"
+
+	// ArgoCDKeycloakDatabaseChecksumAnnotation is the pod template annotation holding the checksum of the credentials of
+	// the Keycloak database.
+	ArgoCDKeycloakDatabaseChecksumAnnotation = "checksum/keycloak-database"
 )
@@ -32,6 +32,28 @@ const (
 	// ArgoCDDuration365Days is a duration representing 365 days.
 	ArgoCDDuration365Days = time.Hour * 24 * 365
 
+	// ArgoCDExportBackupFileName is the name of the single backup file written by former versions of the export
+	// process. It is still imported when no timestamped backup exists.
+	ArgoCDExportBackupFileName = "argocd-backup.yaml"
+
+	// ArgoCDExportBackupFilePrefix is the prefix of the timestamped backup files written by the export process.
+	ArgoCDExportBackupFilePrefix = "argocd-backup-"
+
+	// ArgoCDExportBackupFileSuffix is the suffix of the timestamped backup files written by the export process.
+	ArgoCDExportBackupFileSuffix = ".yaml"
+
+	// ArgoCDExportBackupIDFormat is the time layout of backup IDs, the UTC time at which the backup was taken.
+	ArgoCDExportBackupIDFormat = "20060102T150405Z"
+
+	// ArgoCDExportBackupKeyIDLabel is the message authenticated with a backup key to derive its ID.
+	ArgoCDExportBackupKeyIDLabel = "argocd-operator backup key id"
+
+	// ArgoCDExportKMSKeyIDPrefix prefixes the ID of backup keys managed by a KMS.
+	ArgoCDExportKMSKeyIDPrefix = "kms:"
+
+	// ArgoCDExportMinBackupKeyLength is the minimum length of a user managed backup key.
+	ArgoCDExportMinBackupKeyLength = 16
+
 	// ArgoCDExportName is the export name for labels.
 	ArgoCDExportName = "argocd.export"
 
@@ -47,6 +69,21 @@ const (
 	// ArgoCDExportStorageBackendLocal is the value for the local storage backend.
 	ArgoCDExportStorageBackendLocal = "local"
 
+	// ArgoCDExportStorageBackendS3 is the value for the generic S3 compatible storage backend.
+	ArgoCDExportStorageBackendS3 = "s3"
+
+	// ArgoCDExportS3CABundleMountPath is the path the CA bundle of the S3 compatible storage backend is mounted at.
+	ArgoCDExportS3CABundleMountPath = "/s3-ca"
+
+	// ArgoCDExportS3CABundleFileName is the name of the mounted CA bundle file of the S3 compatible storage backend.
+	ArgoCDExportS3CABundleFileName = "ca.crt"
+
+	// ArgoCDExportS3SSEAES256 is the server-side encryption type for keys managed by the S3 compatible store.
+	ArgoCDExportS3SSEAES256 = "AES256"
+
+	// ArgoCDExportS3SSEKMS is the server-side encryption type for keys managed by a KMS.
+	ArgoCDExportS3SSEKMS = "aws:kms"
+
 	// ArgoCDGrafanaConfigMapSuffix is the default suffix for the Grafana configuration ConfigMap.
 	ArgoCDGrafanaConfigMapSuffix = "grafana-config"
 
@@ -74,6 +111,15 @@ const (
 	// ArgoCDStatusCompleted is the completed status value.
 	ArgoCDStatusCompleted = "Completed"
 
+	// ArgoCDStatusFailed is the failed status value.
+	ArgoCDStatusFailed = "Failed"
+
+	// ArgoCDStatusPending is the pending status value.
+	ArgoCDStatusPending = "Pending"
+
+	// ArgoCDStatusRunning is the running status value.
+	ArgoCDStatusRunning = "Running"
+
 	// ArgoCDTLSCertsConfigMapName is the upstream hard-coded TLS certificate data ConfigMap name.
 	ArgoCDTLSCertsConfigMapName = "argocd-tls-certs-cm"
 
@@ -89,6 +135,9 @@ const (
 	// ArgoCDServerTLSSecretName is the name of the TLS secret for the argocd-server
 	ArgoCDServerTLSSecretName = "argocd-server-tls"
 
+	// ArgoCDAutoTLSCertManager is the autotls provider issuing the TLS certificates with cert-manager.
+	ArgoCDAutoTLSCertManager = "cert-manager"
+
 	//ApplicationSetServiceNameSuffix is the suffix for Apllication Set Controller Service
 	ApplicationSetServiceNameSuffix = "applicationset-controller"
 )
@@ -181,6 +181,13 @@ func (r *ReconcileArgoCD) reconcileApplicationSetDeployment(cr *argoproj.ArgoCD,
 	}
 	AddSeccompProfileForOpenShift(r.Client, podSpec)
 
+	appSetSpec := &argoproj.ArgoCDApplicationSet{}
+	if cr.Spec.ApplicationSet != nil {
+		appSetSpec = cr.Spec.ApplicationSet
+	}
+	applyNodePlacement(podSpec, getNodePlacement(cr, appSetSpec.NodePlacement), deploy.Spec.Selector)
+	podSpec.ImagePullSecrets = getImagePullSecrets(cr, appSetSpec.ImagePullSecrets)
+
 	if existing := newDeploymentWithSuffix("applicationset-controller", "controller", cr); argoutil.IsObjectFound(r.Client, cr.Namespace, existing.Name, existing) {
 
 		if cr.Spec.ApplicationSet != nil && !cr.Spec.ApplicationSet.IsEnabled() {
@@ -195,9 +202,9 @@ func (r *ReconcileArgoCD) reconcileApplicationSetDeployment(cr *argoproj.ArgoCD,
 			existingSpec.ServiceAccountName != podSpec.ServiceAccountName ||
 			!reflect.DeepEqual(existing.Labels, deploy.Labels) ||
 			!reflect.DeepEqual(existing.Spec.Template.Labels, deploy.Spec.Template.Labels) ||
-			!reflect.DeepEqual(existing.Spec.Selector, deploy.Spec.Selector) ||
-			!reflect.DeepEqual(existing.Spec.Template.Spec.NodeSelector, deploy.Spec.Template.Spec.NodeSelector) ||
-			!reflect.DeepEqual(existing.Spec.Template.Spec.Tolerations, deploy.Spec.Template.Spec.Tolerations)
+			!reflect.DeepEqual(existing.Spec.Selector, deploy.Spec.Selector)
+		updateNodePlacement(existing, deploy, &deploymentsDifferent)
+		updateImagePullOptions(&existing.Spec.Template.Spec, podSpec, &deploymentsDifferent)
 
 		// If the Deployment already exists, make sure the values we care about are up-to-date
 		if deploymentsDifferent {
@@ -207,8 +214,6 @@ func (r *ReconcileArgoCD) reconcileApplicationSetDeployment(cr *argoproj.ArgoCD,
 			existing.Labels = deploy.Labels
 			existing.Spec.Template.Labels = deploy.Spec.Template.Labels
 			existing.Spec.Selector = deploy.Spec.Selector
-			existing.Spec.Template.Spec.NodeSelector = deploy.Spec.Template.Spec.NodeSelector
-			existing.Spec.Template.Spec.Tolerations = deploy.Spec.Template.Spec.Tolerations
 			return r.Client.Update(context.TODO(), existing)
 		}
 		return nil // Deployment found with nothing to do, move along...
@@ -246,7 +251,7 @@ func applicationSetContainer(cr *argoproj.ArgoCD, addSCMGitlabVolumeMount bool)
 		Command:         getArgoApplicationSetCommand(cr),
 		Env:             appSetEnv,
 		Image:           getApplicationSetContainerImage(cr),
-		ImagePullPolicy: corev1.PullAlways,
+		ImagePullPolicy: getImagePullPolicy(cr, cr.Spec.ApplicationSet.ImagePullPolicy, corev1.PullAlways),
 		Name:            "argocd-applicationset-controller",
 		Resources:       getApplicationSetResources(cr),
 		VolumeMounts: []corev1.VolumeMount{
@@ -520,9 +525,9 @@ func getApplicationSetContainerImage(cr *argoproj.ArgoCD) string {
 
 	// If an env var is specified then use that, but don't override the spec values (if they are present)
 	if e := os.Getenv(common.ArgoCDImageEnvName); e != "" && (defaultTag && defaultImg) {
-		return e
+		return argoutil.ReplaceImageRegistry(e, cr.Spec.ImageRegistry)
 	}
-	return argoutil.CombineImageTag(img, tag)
+	return argoutil.ReplaceImageRegistry(argoutil.CombineImageTag(img, tag), cr.Spec.ImageRegistry)
 }
 
 // getApplicationSetResources will return the ResourceRequirements for the Application Sets container.
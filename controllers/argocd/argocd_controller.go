@@ -29,10 +29,14 @@ import (
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
 	logr "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/ratelimiter"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
 
@@ -49,16 +53,18 @@ type ReconcileArgoCD struct {
 	ManagedSourceNamespaces map[string]string
 	// Stores label selector used to reconcile a subset of ArgoCD
 	LabelSelector string
+	// Instances keeps track of the reconciled ArgoCD instances, and drives the instance metrics
+	Instances *InstanceRegistry
+	// MaxConcurrentReconciles is the maximum number of ArgoCD instances reconciled concurrently
+	MaxConcurrentReconciles int
+	// RateLimiter limits how often the ArgoCD instances are reconciled, the controller-runtime default is used if nil
+	RateLimiter ratelimiter.RateLimiter
+	// RequeueAfter is the default interval after which the ArgoCD instances are reconciled again, zero disables it
+	RequeueAfter time.Duration
 }
 
 var log = logr.Log.WithName("controller_argocd")
 
-// Map to keep track of running Argo CD instances using their namespaces as key and phase as value
-// This map will be used for the performance metrics purposes
-// Important note: This assumes that each instance only contains one Argo CD instance
-// as, having multiple Argo CD instances in the same namespace is considered an anti-pattern
-var ActiveInstanceMap = make(map[string]string)
-
 //+kubebuilder:rbac:groups=rbac.authorization.k8s.io,resources=clusterroles;clusterrolebindings,verbs=*
 //+kubebuilder:rbac:groups="",resources=configmaps;endpoints;events;persistentvolumeclaims;pods;namespaces;secrets;serviceaccounts;services;services/finalizers,verbs=*
 //+kubebuilder:rbac:groups=apps.openshift.io,resources=deploymentconfigs,verbs=*
@@ -67,8 +73,12 @@ var ActiveInstanceMap = make(map[string]string)
 //+kubebuilder:rbac:groups=argoproj.io,resources=argocds;argocds/finalizers;argocds/status,verbs=*
 //+kubebuilder:rbac:groups=autoscaling,resources=horizontalpodautoscalers,verbs=*
 //+kubebuilder:rbac:groups=batch,resources=cronjobs;jobs,verbs=*
+//+kubebuilder:rbac:groups=cert-manager.io,resources=certificates,verbs=*
 //+kubebuilder:rbac:groups=config.openshift.io,resources=clusterversions,verbs=get;list;watch
-//+kubebuilder:rbac:groups=networking.k8s.io,resources=ingresses,verbs=*
+//+kubebuilder:rbac:groups=gateway.networking.k8s.io,resources=httproutes;grpcroutes,verbs=*
+//+kubebuilder:rbac:groups=gateway.networking.k8s.io,resources=gateways,verbs=get;list;watch
+//+kubebuilder:rbac:groups=networking.k8s.io,resources=ingresses;networkpolicies,verbs=*
+//+kubebuilder:rbac:groups=policy,resources=poddisruptionbudgets,verbs=*
 //+kubebuilder:rbac:groups=monitoring.coreos.com,resources=prometheuses;prometheusrules;servicemonitors,verbs=*
 //+kubebuilder:rbac:groups=route.openshift.io,resources=routes;routes/custom-host,verbs=*
 //+kubebuilder:rbac:groups=argoproj.io,resources=applications;appprojects,verbs=*
@@ -85,23 +95,27 @@ var ActiveInstanceMap = make(map[string]string)
 //
 // For more details, check Reconcile and its Result here:
 // - https://pkg.go.dev/sigs.k8s.io/controller-runtime@v0.9.2/pkg/reconcile
-func (r *ReconcileArgoCD) Reconcile(ctx context.Context, request ctrl.Request) (ctrl.Result, error) {
+func (r *ReconcileArgoCD) Reconcile(ctx context.Context, request ctrl.Request) (result ctrl.Result, err error) {
 
 	reconcileStartTS := time.Now()
 	defer func() {
-		ReconcileTime.WithLabelValues(request.Namespace).Observe(time.Since(reconcileStartTS).Seconds())
+		r.Instances.RecordResult(request.NamespacedName, err)
+		if r.Instances.HasNamespace(request.Namespace) {
+			ReconcileTime.WithLabelValues(request.Namespace).Observe(time.Since(reconcileStartTS).Seconds())
+		}
 	}()
 
 	reqLogger := logr.FromContext(ctx, "namespace", request.Namespace, "name", request.Name)
 	reqLogger.Info("Reconciling ArgoCD")
 
 	argocd := &argoproj.ArgoCD{}
-	err := r.Client.Get(ctx, request.NamespacedName, argocd)
+	err = r.Client.Get(ctx, request.NamespacedName, argocd)
 	if err != nil {
 		if errors.IsNotFound(err) {
 			// Request object not found, could have been deleted after reconcile request.
 			// Owned objects are automatically garbage collected. For additional cleanup logic use finalizers.
 			// Return and don't requeue
+			r.removeInstance(request.NamespacedName)
 			return reconcile.Result{}, nil
 		}
 		// Error reading the object - requeue the request.
@@ -117,69 +131,48 @@ func (r *ReconcileArgoCD) Reconcile(ctx context.Context, request ctrl.Request) (
 	// Match the value of labelSelector from ReconcileArgoCD to labels from the argocd instance
 	if !labelSelector.Matches(labels.Set(argocd.Labels)) {
 		reqLogger.Info(fmt.Sprintf("the ArgoCD instance '%s' does not match the label selector '%s' and skipping for reconciliation", request.NamespacedName, r.LabelSelector))
+		r.removeInstance(request.NamespacedName)
 		return reconcile.Result{}, fmt.Errorf("Error: failed to reconcile ArgoCD instance: '%s'", request.NamespacedName)
 	}
 
-	newPhase := argocd.Status.Phase
-	// If we discover a new Argo CD instance in a previously un-seen namespace
-	// we add it to the map and increment active instance count by phase
-	// as well as total active instance count
-	if _, ok := ActiveInstanceMap[request.Namespace]; !ok {
-		if newPhase != "" {
-			ActiveInstanceMap[request.Namespace] = newPhase
-			ActiveInstancesByPhase.WithLabelValues(newPhase).Inc()
-			ActiveInstancesTotal.Inc()
-		}
-	} else {
-		// If we discover an existing instance's phase has changed since we last saw it
-		// increment instance count with new phase and decrement instance count with old phase
-		// update the phase in corresponding map entry
-		// total instance count remains the same
-		if oldPhase := ActiveInstanceMap[argocd.Namespace]; oldPhase != newPhase {
-			ActiveInstanceMap[argocd.Namespace] = newPhase
-			ActiveInstancesByPhase.WithLabelValues(newPhase).Inc()
-			ActiveInstancesByPhase.WithLabelValues(oldPhase).Dec()
-		}
-	}
-
-	ActiveInstanceReconciliationCount.WithLabelValues(argocd.Namespace).Inc()
-
 	if argocd.GetDeletionTimestamp() != nil {
 
-		// Argo CD instance marked for deletion; remove entry from activeInstances map and decrement active instance count
-		// by phase as well as total
-		delete(ActiveInstanceMap, argocd.Namespace)
-		ActiveInstancesByPhase.WithLabelValues(newPhase).Dec()
-		ActiveInstancesTotal.Dec()
-		ActiveInstanceReconciliationCount.DeleteLabelValues(argocd.Namespace)
-		ReconcileTime.DeletePartialMatch(prometheus.Labels{"namespace": argocd.Namespace})
+		// Argo CD instance marked for deletion; remove it from the instance registry, which updates the active
+		// instance metrics
+		r.removeInstance(request.NamespacedName)
 
 		if argocd.IsDeletionFinalizerPresent() {
-			if err := r.deleteClusterResources(argocd); err != nil {
+			// The reconciler is copied, so that the namespaces of this instance are not shared with the reconciliations
+			// of other instances running concurrently.
+			rr := *r
+			if err := rr.setManagedSourceNamespaces(argocd); err != nil {
+				return reconcile.Result{}, err
+			}
+
+			if err := rr.deleteClusterResources(argocd); err != nil {
 				return reconcile.Result{}, fmt.Errorf("failed to delete ClusterResources: %w", err)
 			}
 
 			if isRemoveManagedByLabelOnArgoCDDeletion() {
-				if err := r.removeManagedByLabelFromNamespaces(argocd.Namespace); err != nil {
+				if err := rr.removeManagedByLabelFromNamespaces(argocd.Namespace); err != nil {
 					return reconcile.Result{}, fmt.Errorf("failed to remove label from namespace[%v], error: %w", argocd.Namespace, err)
 				}
 			}
 
-			if err := r.removeUnmanagedSourceNamespaceResources(argocd); err != nil {
+			if err := rr.removeUnmanagedSourceNamespaceResources(argocd); err != nil {
 				return reconcile.Result{}, fmt.Errorf("failed to remove resources from sourceNamespaces, error: %w", err)
 			}
 
-			if err := r.removeDeletionFinalizer(argocd); err != nil {
+			if err := rr.removeDeletionFinalizer(argocd); err != nil {
 				return reconcile.Result{}, err
 			}
-
-			// remove namespace of deleted Argo CD instance from deprecationEventEmissionTracker (if exists) so that if another instance
-			// is created in the same namespace in the future, that instance is appropriately tracked
-			delete(DeprecationEventEmissionTracker, argocd.Namespace)
 		}
 		return reconcile.Result{}, nil
 	}
 
+	r.Instances.Observe(argocd)
+	ActiveInstanceReconciliationCount.WithLabelValues(argocd.Namespace).Inc()
+
 	if !argocd.IsDeletionFinalizerPresent() {
 		if err := r.addDeletionFinalizer(argocd); err != nil {
 			return reconcile.Result{}, err
@@ -191,26 +184,63 @@ func (r *ReconcileArgoCD) Reconcile(ctx context.Context, request ctrl.Request) (
 		return reconcile.Result{}, err
 	}
 
-	if err = r.setManagedNamespaces(argocd); err != nil {
+	// The reconciler is copied, so that the namespaces of this instance are not shared with the reconciliations of
+	// other instances running concurrently.
+	rr := *r
+	if err = rr.setManagedNamespaces(argocd); err != nil {
 		return reconcile.Result{}, err
 	}
 
-	if err = r.setManagedSourceNamespaces(argocd); err != nil {
+	if err = rr.setManagedSourceNamespaces(argocd); err != nil {
 		return reconcile.Result{}, err
 	}
 
-	if err := r.reconcileResources(argocd); err != nil {
+	if err := rr.reconcileResources(argocd); err != nil {
 		// Error reconciling ArgoCD sub-resources - requeue the request.
 		return reconcile.Result{}, err
 	}
 
-	// Return and don't requeue
-	return reconcile.Result{}, nil
+	// Return and requeue according to the requeue policy of the instance
+	return reconcile.Result{RequeueAfter: r.requeueAfter(argocd)}, nil
+}
+
+// removeInstance removes the given instance from the instance registry and its metrics, and the per namespace metrics
+// once no other instance is left in its namespace.
+func (r *ReconcileArgoCD) removeInstance(key types.NamespacedName) {
+	r.Instances.Remove(key)
+	CertificateExpiryTime.DeletePartialMatch(prometheus.Labels{"namespace": key.Namespace, "name": key.Name})
+	if !r.Instances.HasNamespace(key.Namespace) {
+		ActiveInstanceReconciliationCount.DeleteLabelValues(key.Namespace)
+		ReconcileTime.DeletePartialMatch(prometheus.Labels{"namespace": key.Namespace})
+	}
 }
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *ReconcileArgoCD) SetupWithManager(mgr ctrl.Manager) error {
-	bldr := ctrl.NewControllerManagedBy(mgr)
-	r.setResourceWatches(bldr, r.clusterResourceMapper, r.tlsSecretMapper, r.namespaceResourceMapper, r.clusterSecretResourceMapper, r.applicationSetSCMTLSConfigMapMapper)
+	if r.Instances == nil {
+		r.Instances = NewInstanceRegistry()
+	}
+
+	// The instance registry is rebuilt once the cache is synced, so that the instance metrics account for the existing
+	// instances right after the operator restarted, before they are all reconciled.
+	err := mgr.Add(manager.RunnableFunc(func(ctx context.Context) error {
+		if !mgr.GetCache().WaitForCacheSync(ctx) {
+			return fmt.Errorf("failed to wait for the cache to sync")
+		}
+		if err := r.rebuildInstanceRegistry(ctx); err != nil {
+			log.Error(err, "failed to rebuild the instance registry")
+		}
+		return nil
+	}))
+	if err != nil {
+		return err
+	}
+
+	bldr := ctrl.NewControllerManagedBy(mgr).
+		WithOptions(controller.Options{
+			MaxConcurrentReconciles: r.MaxConcurrentReconciles,
+			RateLimiter:             r.RateLimiter,
+		})
+	r.setResourceWatches(bldr, r.clusterResourceMapper, r.tlsSecretMapper, r.namespaceResourceMapper, r.clusterSecretResourceMapper, r.applicationSetSCMTLSConfigMapMapper, r.argoCDExportMapper, r.dexConnectorSecretMapper, r.redisAuthSecretMapper)
 	return bldr.Complete(r)
 }
@@ -0,0 +1,247 @@
+// Copyright 2023 ArgoCD Operator Developers
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package argocd
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	argoproj "github.com/argoproj-labs/argocd-operator/api/v1beta1"
+	"github.com/argoproj-labs/argocd-operator/common"
+	"github.com/argoproj-labs/argocd-operator/controllers/argoutil"
+)
+
+const (
+	// certManagerAPIGroup is the API group of cert-manager.
+	certManagerAPIGroup = "cert-manager.io"
+
+	// certManagerAPIVersion is the version of the cert-manager API used for the certificates.
+	certManagerAPIVersion = "v1"
+
+	// certManagerCertificateReadyCondition is the condition set by cert-manager on the certificates it issued.
+	certManagerCertificateReadyCondition = "Ready"
+)
+
+var certificateGVK = schema.GroupVersionKind{Group: certManagerAPIGroup, Version: certManagerAPIVersion, Kind: "Certificate"}
+
+var certManagerAPIFound = false
+
+// IsCertManagerAPIAvailable returns true if the cert-manager Certificate API is present.
+func IsCertManagerAPIAvailable() bool {
+	return certManagerAPIFound
+}
+
+// verifyCertManagerAPI will verify that the cert-manager Certificate API is present.
+func verifyCertManagerAPI() error {
+	found, err := argoutil.VerifyAPIKind(certManagerAPIGroup, certManagerAPIVersion, certificateGVK.Kind)
+	if err != nil {
+		return err
+	}
+	certManagerAPIFound = found
+	return nil
+}
+
+// certManagerCertificate describes a cert-manager Certificate issuing the TLS secret of an Argo CD component.
+type certManagerCertificate struct {
+	// secretName is the name of the TLS secret, which is also the name of the Certificate.
+	secretName string
+	// enabled is true if the component uses the cert-manager autotls provider.
+	enabled bool
+	// dnsNames are the DNS names the certificate is issued for.
+	dnsNames []string
+}
+
+// newCertificate returns a new, empty cert-manager Certificate with the given name and namespace.
+func newCertificate(name, namespace string) *unstructured.Unstructured {
+	cert := &unstructured.Unstructured{}
+	cert.SetGroupVersionKind(certificateGVK)
+	cert.SetName(name)
+	cert.SetNamespace(namespace)
+	return cert
+}
+
+// getServiceDNSNames returns the DNS names of the Service with the given suffix of the given ArgoCD.
+func getServiceDNSNames(suffix string, cr *argoproj.ArgoCD) []string {
+	name := nameWithSuffix(suffix, cr)
+	return []string{
+		name,
+		fmt.Sprintf("%s.%s", name, cr.Namespace),
+		fmt.Sprintf("%s.%s.svc", name, cr.Namespace),
+		fmt.Sprintf("%s.%s.svc.cluster.local", name, cr.Namespace),
+	}
+}
+
+// getCertManagerCertificates returns the cert-manager Certificates of the components of the given ArgoCD.
+func getCertManagerCertificates(cr *argoproj.ArgoCD) []certManagerCertificate {
+	serverDNSNames := getServiceDNSNames("server", cr)
+	for _, host := range []string{cr.Spec.Server.Host, cr.Spec.Server.GRPC.Host} {
+		if len(host) > 0 {
+			serverDNSNames = append(serverDNSNames, host)
+		}
+	}
+
+	redisDNSNames := getServiceDNSNames("redis", cr)
+	if cr.Spec.HA.Enabled {
+		redisDNSNames = append(redisDNSNames, getServiceDNSNames("redis-ha-haproxy", cr)...)
+	}
+
+	return []certManagerCertificate{
+		{
+			secretName: common.ArgoCDServerTLSSecretName,
+			enabled:    cr.Spec.Server.IsEnabled() && cr.Spec.Server.WantsCertManagerTLS(),
+			dnsNames:   serverDNSNames,
+		},
+		{
+			secretName: common.ArgoCDRepoServerTLSSecretName,
+			enabled:    cr.Spec.Repo.IsEnabled() && cr.Spec.Repo.WantsCertManagerTLS(),
+			dnsNames:   getServiceDNSNames("repo-server", cr),
+		},
+		{
+			secretName: common.ArgoCDRedisServerTLSSecretName,
+			enabled: cr.Spec.Redis.IsEnabled() && cr.Spec.Redis.WantsCertManagerTLS() &&
+				(cr.Spec.Redis.Remote == nil || *cr.Spec.Redis.Remote == ""),
+			dnsNames: redisDNSNames,
+		},
+	}
+}
+
+// newCertificateSpec returns the desired spec of the cert-manager Certificate of the given component of the given
+// ArgoCD. The issued secret is annotated with the ArgoCD, so that its changes are watched like the ones of a TLS
+// secret created by hand.
+func newCertificateSpec(cr *argoproj.ArgoCD, cert certManagerCertificate) map[string]interface{} {
+	issuerRef := cr.Spec.TLS.CertManager.IssuerRef
+	kind := issuerRef.Kind
+	if len(kind) == 0 {
+		kind = "Issuer"
+	}
+	group := issuerRef.Group
+	if len(group) == 0 {
+		group = certManagerAPIGroup
+	}
+
+	dnsNames := []interface{}{}
+	for _, name := range cert.dnsNames {
+		dnsNames = append(dnsNames, name)
+	}
+
+	return map[string]interface{}{
+		"secretName": cert.secretName,
+		"dnsNames":   dnsNames,
+		"issuerRef": map[string]interface{}{
+			"name":  issuerRef.Name,
+			"kind":  kind,
+			"group": group,
+		},
+		"secretTemplate": map[string]interface{}{
+			"annotations": map[string]interface{}{
+				common.AnnotationName:      cr.Name,
+				common.AnnotationNamespace: cr.Namespace,
+			},
+		},
+	}
+}
+
+// reconcileCertificates will ensure that the cert-manager Certificates of the components using the cert-manager
+// autotls provider are present, and that the other ones are absent.
+func (r *ReconcileArgoCD) reconcileCertificates(cr *argoproj.ArgoCD) error {
+	for _, cert := range getCertManagerCertificates(cr) {
+		if err := r.reconcileCertificate(cr, cert); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// reconcileCertificate will ensure that the cert-manager Certificate of the given component is present when the
+// component uses the cert-manager autotls provider, and absent otherwise. A Certificate of the same name that is not
+// controlled by the given ArgoCD is left untouched.
+func (r *ReconcileArgoCD) reconcileCertificate(cr *argoproj.ArgoCD, cert certManagerCertificate) error {
+	if cert.enabled && (cr.Spec.TLS.CertManager == nil || len(cr.Spec.TLS.CertManager.IssuerRef.Name) == 0) {
+		return fmt.Errorf("the cert-manager issuer of the TLS options must be set for the %s certificate", cert.secretName)
+	}
+
+	existing := newCertificate(cert.secretName, cr.Namespace)
+	if argoutil.IsObjectFound(r.Client, cr.Namespace, existing.GetName(), existing) {
+		if !metav1.IsControlledBy(existing, cr) {
+			if cert.enabled {
+				log.Info("ignoring certificate not controlled by the ArgoCD", "name", existing.GetName(), "namespace", existing.GetNamespace())
+			}
+			return nil
+		}
+
+		if !cert.enabled {
+			log.Info("deleting certificate", "name", existing.GetName(), "namespace", existing.GetNamespace())
+			return r.Client.Delete(context.TODO(), existing)
+		}
+
+		desired := newCertificateSpec(cr, cert)
+		if reflect.DeepEqual(existing.Object["spec"], desired) {
+			return nil // Certificate found and up to date, do nothing
+		}
+		existing.Object["spec"] = runtime.DeepCopyJSONValue(desired)
+		log.Info("updating certificate to match the ArgoCD spec", "name", existing.GetName(), "namespace", existing.GetNamespace())
+		return r.Client.Update(context.TODO(), existing)
+	}
+
+	if !cert.enabled {
+		return nil // Certificate not enabled, move along...
+	}
+
+	certificate := newCertificate(cert.secretName, cr.Namespace)
+	labels := argoutil.LabelsForCluster(cr)
+	labels[common.ArgoCDKeyName] = certificate.GetName()
+	certificate.SetLabels(labels)
+	certificate.Object["spec"] = newCertificateSpec(cr, cert)
+	if err := controllerutil.SetControllerReference(cr, certificate, r.Scheme); err != nil {
+		return err
+	}
+	log.Info("creating certificate", "name", certificate.GetName(), "namespace", certificate.GetNamespace())
+	return r.Client.Create(context.TODO(), certificate)
+}
+
+// isCertificateReady returns true if the cert-manager Certificate with the given name has been issued, false if it is
+// not ready yet or not found.
+func (r *ReconcileArgoCD) isCertificateReady(cr *argoproj.ArgoCD, name string) bool {
+	cert := newCertificate(name, cr.Namespace)
+	if !argoutil.IsObjectFound(r.Client, cr.Namespace, name, cert) {
+		return false
+	}
+
+	conditions, _, _ := unstructured.NestedSlice(cert.Object, "status", "conditions")
+	for _, c := range conditions {
+		condition, ok := c.(map[string]interface{})
+		if ok && condition["type"] == certManagerCertificateReadyCondition {
+			return condition["status"] == string(metav1.ConditionTrue)
+		}
+	}
+	return false
+}
+
+// waitsForCertificate returns true if the given component uses the cert-manager autotls provider and its Certificate
+// is not ready yet, in which case the rollout of its TLS secret is postponed until the certificate is issued.
+func (r *ReconcileArgoCD) waitsForCertificate(cr *argoproj.ArgoCD, wantsCertManagerTLS bool, name string) bool {
+	if !wantsCertManagerTLS || !IsCertManagerAPIAvailable() || r.isCertificateReady(cr, name) {
+		return false
+	}
+	log.Info("waiting for the certificate to be issued", "name", name, "namespace", cr.Namespace)
+	return true
+}
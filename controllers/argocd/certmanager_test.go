@@ -0,0 +1,185 @@
+package argocd
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	argoproj "github.com/argoproj-labs/argocd-operator/api/v1beta1"
+	"github.com/argoproj-labs/argocd-operator/common"
+)
+
+func withCertManagerAPI(t *testing.T) {
+	certManagerAPIFound = true
+	t.Cleanup(func() {
+		certManagerAPIFound = false
+	})
+}
+
+func withCertManagerIssuer(a *argoproj.ArgoCD) {
+	a.Spec.TLS.CertManager = &argoproj.ArgoCDCertManagerSpec{
+		IssuerRef: argoproj.ArgoCDCertManagerIssuerReference{Name: "argocd-issuer", Kind: "ClusterIssuer"},
+	}
+}
+
+func getCertificate(t *testing.T, r *ReconcileArgoCD, name string) (*unstructured.Unstructured, bool) {
+	t.Helper()
+	cert := newCertificate(name, testNamespace)
+	err := r.Client.Get(context.TODO(), client.ObjectKeyFromObject(cert), cert)
+	return cert, err == nil
+}
+
+func setCertificateReady(t *testing.T, r *ReconcileArgoCD, name string, status metav1.ConditionStatus) {
+	t.Helper()
+	cert, _ := getCertificate(t, r, name)
+	assert.NoError(t, unstructured.SetNestedSlice(cert.Object, []interface{}{
+		map[string]interface{}{"type": "Ready", "status": string(status)},
+	}, "status", "conditions"))
+	assert.NoError(t, r.Client.Update(context.TODO(), cert))
+}
+
+func TestReconcileArgoCD_reconcileCertificates(t *testing.T) {
+	logf.SetLogger(ZapLogger(true))
+	withCertManagerAPI(t)
+	a := makeTestArgoCD(withCertManagerIssuer, func(a *argoproj.ArgoCD) {
+		a.Spec.Server.AutoTLS = common.ArgoCDAutoTLSCertManager
+		a.Spec.Server.Host = "argocd.example.com"
+		a.Spec.Repo.AutoTLS = common.ArgoCDAutoTLSCertManager
+	})
+	resObjs := []client.Object{a}
+	sch := makeTestReconcilerScheme(argoproj.AddToScheme)
+	cl := makeTestReconcilerClient(sch, resObjs, resObjs, []runtime.Object{})
+	r := makeTestReconciler(cl, sch)
+
+	assert.NoError(t, r.reconcileCertificates(a))
+
+	server, ok := getCertificate(t, r, common.ArgoCDServerTLSSecretName)
+	assert.True(t, ok)
+	assert.Equal(t, a.Name, server.GetOwnerReferences()[0].Name)
+	secretName, _, _ := unstructured.NestedString(server.Object, "spec", "secretName")
+	assert.Equal(t, common.ArgoCDServerTLSSecretName, secretName)
+	dnsNames, _, _ := unstructured.NestedStringSlice(server.Object, "spec", "dnsNames")
+	assert.Equal(t, []string{"argocd-server", "argocd-server.argocd", "argocd-server.argocd.svc",
+		"argocd-server.argocd.svc.cluster.local", "argocd.example.com"}, dnsNames)
+	issuerRef, _, _ := unstructured.NestedStringMap(server.Object, "spec", "issuerRef")
+	assert.Equal(t, map[string]string{"name": "argocd-issuer", "kind": "ClusterIssuer", "group": "cert-manager.io"}, issuerRef)
+	annotations, _, _ := unstructured.NestedStringMap(server.Object, "spec", "secretTemplate", "annotations")
+	assert.Equal(t, a.Name, annotations[common.AnnotationName])
+
+	repo, ok := getCertificate(t, r, common.ArgoCDRepoServerTLSSecretName)
+	assert.True(t, ok)
+	dnsNames, _, _ = unstructured.NestedStringSlice(repo.Object, "spec", "dnsNames")
+	assert.Contains(t, dnsNames, "argocd-repo-server.argocd.svc")
+
+	_, ok = getCertificate(t, r, common.ArgoCDRedisServerTLSSecretName)
+	assert.False(t, ok)
+
+	// the certificates are updated, and deleted once the component no longer uses cert-manager
+	a.Spec.TLS.CertManager.IssuerRef = argoproj.ArgoCDCertManagerIssuerReference{Name: "other-issuer"}
+	a.Spec.Repo.AutoTLS = ""
+	assert.NoError(t, r.reconcileCertificates(a))
+
+	server, _ = getCertificate(t, r, common.ArgoCDServerTLSSecretName)
+	issuerRef, _, _ = unstructured.NestedStringMap(server.Object, "spec", "issuerRef")
+	assert.Equal(t, map[string]string{"name": "other-issuer", "kind": "Issuer", "group": "cert-manager.io"}, issuerRef)
+	_, ok = getCertificate(t, r, common.ArgoCDRepoServerTLSSecretName)
+	assert.False(t, ok)
+}
+
+func TestReconcileArgoCD_reconcileCertificates_redisHA(t *testing.T) {
+	logf.SetLogger(ZapLogger(true))
+	withCertManagerAPI(t)
+	a := makeTestArgoCD(withCertManagerIssuer, func(a *argoproj.ArgoCD) {
+		a.Spec.HA.Enabled = true
+		a.Spec.Redis.AutoTLS = common.ArgoCDAutoTLSCertManager
+	})
+	resObjs := []client.Object{a}
+	sch := makeTestReconcilerScheme(argoproj.AddToScheme)
+	cl := makeTestReconcilerClient(sch, resObjs, resObjs, []runtime.Object{})
+	r := makeTestReconciler(cl, sch)
+
+	assert.NoError(t, r.reconcileCertificates(a))
+
+	redis, ok := getCertificate(t, r, common.ArgoCDRedisServerTLSSecretName)
+	assert.True(t, ok)
+	dnsNames, _, _ := unstructured.NestedStringSlice(redis.Object, "spec", "dnsNames")
+	assert.Contains(t, dnsNames, "argocd-redis.argocd.svc")
+	assert.Contains(t, dnsNames, "argocd-redis-ha-haproxy.argocd.svc")
+}
+
+func TestReconcileArgoCD_reconcileCertificates_missingIssuer(t *testing.T) {
+	logf.SetLogger(ZapLogger(true))
+	withCertManagerAPI(t)
+	a := makeTestArgoCD(func(a *argoproj.ArgoCD) {
+		a.Spec.Repo.AutoTLS = common.ArgoCDAutoTLSCertManager
+	})
+	resObjs := []client.Object{a}
+	sch := makeTestReconcilerScheme(argoproj.AddToScheme)
+	cl := makeTestReconcilerClient(sch, resObjs, resObjs, []runtime.Object{})
+	r := makeTestReconciler(cl, sch)
+
+	assert.EqualError(t, r.reconcileCertificates(a), "the cert-manager issuer of the TLS options must be set for the argocd-repo-server-tls certificate")
+}
+
+func TestReconcileArgoCD_reconcileCertificates_notControlled(t *testing.T) {
+	logf.SetLogger(ZapLogger(true))
+	withCertManagerAPI(t)
+	a := makeTestArgoCD(withCertManagerIssuer)
+	existing := newCertificate(common.ArgoCDRepoServerTLSSecretName, testNamespace)
+	existing.Object["spec"] = map[string]interface{}{"secretName": common.ArgoCDRepoServerTLSSecretName}
+	resObjs := []client.Object{a, existing}
+	sch := makeTestReconcilerScheme(argoproj.AddToScheme)
+	cl := makeTestReconcilerClient(sch, resObjs, resObjs, []runtime.Object{})
+	r := makeTestReconciler(cl, sch)
+
+	// a certificate created by hand is not deleted
+	assert.NoError(t, r.reconcileCertificates(a))
+	_, ok := getCertificate(t, r, common.ArgoCDRepoServerTLSSecretName)
+	assert.True(t, ok)
+}
+
+func TestReconcileArgoCD_reconcileRepoServerTLSSecret_certManager(t *testing.T) {
+	logf.SetLogger(ZapLogger(true))
+	withCertManagerAPI(t)
+	a := makeTestArgoCD(withCertManagerIssuer, func(a *argoproj.ArgoCD) {
+		a.Spec.Repo.AutoTLS = common.ArgoCDAutoTLSCertManager
+	})
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        common.ArgoCDRepoServerTLSSecretName,
+			Namespace:   testNamespace,
+			Annotations: map[string]string{common.AnnotationName: a.Name},
+		},
+		Type: corev1.SecretTypeTLS,
+		Data: map[string][]byte{
+			corev1.TLSCertKey:       []byte("crt"),
+			corev1.TLSPrivateKeyKey: []byte("key"),
+		},
+	}
+	repoDepl := newDeploymentWithSuffix("repo-server", "repo-server", a)
+	resObjs := []client.Object{a, secret, repoDepl}
+	sch := makeTestReconcilerScheme(argoproj.AddToScheme)
+	cl := makeTestReconcilerClient(sch, resObjs, resObjs, []runtime.Object{})
+	r := makeTestReconciler(cl, sch)
+	assert.NoError(t, r.reconcileCertificates(a))
+
+	// the rollout waits for the certificate to be issued
+	assert.NoError(t, r.reconcileRepoServerTLSSecret(a))
+	assert.Empty(t, a.Status.RepoTLSChecksum)
+
+	setCertificateReady(t, r, common.ArgoCDRepoServerTLSSecretName, metav1.ConditionTrue)
+	assert.NoError(t, r.reconcileRepoServerTLSSecret(a))
+	assert.NotEmpty(t, a.Status.RepoTLSChecksum)
+
+	deployment := &appsv1.Deployment{}
+	assert.NoError(t, r.Client.Get(context.TODO(), client.ObjectKeyFromObject(repoDepl), deployment))
+	assert.Contains(t, deployment.Spec.Template.Labels, "repo.tls.cert.changed")
+}
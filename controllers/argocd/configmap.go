@@ -16,6 +16,7 @@ package argocd
 
 import (
 	"context"
+	"crypto/sha256"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -349,19 +350,32 @@ func (r *ReconcileArgoCD) reconcileConfigMaps(cr *argoproj.ArgoCD, useTLSForRedi
 }
 
 // reconcileCAConfigMap will ensure that the Certificate Authority ConfigMap is present.
-// This ConfigMap holds the CA Certificate data for client use.
+// This ConfigMap holds the CA Certificate data for client use, and is updated when the CA is renewed.
 func (r *ReconcileArgoCD) reconcileCAConfigMap(cr *argoproj.ArgoCD) error {
 	cm := newConfigMapWithName(getCAConfigMapName(cr), cr)
-	if argoutil.IsObjectFound(r.Client, cr.Namespace, cm.Name, cm) {
-		return nil // ConfigMap found, do nothing
-	}
+	found := argoutil.IsObjectFound(r.Client, cr.Namespace, cm.Name, cm)
 
 	caSecret := argoutil.NewSecretWithSuffix(cr, common.ArgoCDCASuffix)
 	if !argoutil.IsObjectFound(r.Client, cr.Namespace, caSecret.Name, caSecret) {
+		if found {
+			return nil // ConfigMap found, do nothing
+		}
 		log.Info(fmt.Sprintf("ca secret [%s] not found, waiting to reconcile ca configmap [%s]", caSecret.Name, cm.Name))
 		return nil
 	}
 
+	if found {
+		if !metav1.IsControlledBy(cm, cr) || cm.Data[common.ArgoCDKeyTLSCert] == string(caSecret.Data[common.ArgoCDKeyTLSCert]) {
+			return nil // ConfigMap found and up to date, do nothing
+		}
+		log.Info("updating ca configmap", "name", cm.Name, "namespace", cm.Namespace)
+		if cm.Data == nil {
+			cm.Data = map[string]string{}
+		}
+		cm.Data[common.ArgoCDKeyTLSCert] = string(caSecret.Data[common.ArgoCDKeyTLSCert])
+		return r.Client.Update(context.TODO(), cm)
+	}
+
 	cm.Data = map[string]string{
 		common.ArgoCDKeyTLSCert: string(caSecret.Data[common.ArgoCDKeyTLSCert]),
 	}
@@ -426,16 +440,9 @@ func (r *ReconcileArgoCD) reconcileArgoConfigMap(cr *argoproj.ArgoCD) error {
 
 	// create dex config if dex is enabled through `.spec.sso`
 	if UseDex(cr) {
-		dexConfig := getDexConfig(cr)
-
-		// If no dexConfig expressed but openShiftOAuth is requested through `.spec.sso.dex`, use default
-		// openshift dex config
-		if dexConfig == "" && (cr.Spec.SSO != nil && cr.Spec.SSO.Dex != nil && cr.Spec.SSO.Dex.OpenShiftOAuth) {
-			cfg, err := r.getOpenShiftDexConfig(cr)
-			if err != nil {
-				return err
-			}
-			dexConfig = cfg
+		dexConfig, err := r.getDesiredDexConfig(cr)
+		if err != nil {
+			return err
 		}
 		cm.Data[common.ArgoCDKeyDexConfig] = dexConfig
 	}
@@ -619,6 +626,26 @@ func (r *ReconcileArgoCD) reconcileRedisConfiguration(cr *argoproj.ArgoCD, useTL
 	return nil
 }
 
+// getRedisHAHealthConfigMapData returns the data of the Redis HA Health ConfigMap.
+func getRedisHAHealthConfigMapData(cr *argoproj.ArgoCD, useTLSForRedis bool) map[string]string {
+	return map[string]string{
+		"redis_liveness.sh":    getRedisLivenessScript(cr, useTLSForRedis),
+		"redis_readiness.sh":   getRedisReadinessScript(cr, useTLSForRedis),
+		"sentinel_liveness.sh": getSentinelLivenessScript(useTLSForRedis),
+	}
+}
+
+// getRedisHAConfigMapData returns the data of the Redis HA ConfigMap for the given ArgoCD.
+func getRedisHAConfigMapData(cr *argoproj.ArgoCD, useTLSForRedis bool) map[string]string {
+	return map[string]string{
+		"haproxy.cfg":     getRedisHAProxyConfig(cr, useTLSForRedis),
+		"haproxy_init.sh": getRedisHAProxyScript(cr),
+		"init.sh":         getRedisInitScript(cr, useTLSForRedis),
+		"redis.conf":      getRedisConf(cr, useTLSForRedis),
+		"sentinel.conf":   getRedisSentinelConf(cr, useTLSForRedis),
+	}
+}
+
 // reconcileRedisHAConfigMap will ensure that the Redis HA Health ConfigMap is present for the given ArgoCD.
 func (r *ReconcileArgoCD) reconcileRedisHAHealthConfigMap(cr *argoproj.ArgoCD, useTLSForRedis bool) error {
 	cm := newConfigMapWithName(common.ArgoCDRedisHAHealthConfigMapName, cr)
@@ -627,6 +654,10 @@ func (r *ReconcileArgoCD) reconcileRedisHAHealthConfigMap(cr *argoproj.ArgoCD, u
 			// ConfigMap exists but HA enabled flag has been set to false, delete the ConfigMap
 			return r.Client.Delete(context.TODO(), cm)
 		}
+		if desired := getRedisHAHealthConfigMapData(cr, useTLSForRedis); !reflect.DeepEqual(cm.Data, desired) {
+			cm.Data = desired
+			return r.Client.Update(context.TODO(), cm)
+		}
 		return nil // ConfigMap found with nothing changed, move along...
 	}
 
@@ -634,11 +665,7 @@ func (r *ReconcileArgoCD) reconcileRedisHAHealthConfigMap(cr *argoproj.ArgoCD, u
 		return nil // HA not enabled, do nothing.
 	}
 
-	cm.Data = map[string]string{
-		"redis_liveness.sh":    getRedisLivenessScript(useTLSForRedis),
-		"redis_readiness.sh":   getRedisReadinessScript(useTLSForRedis),
-		"sentinel_liveness.sh": getSentinelLivenessScript(useTLSForRedis),
-	}
+	cm.Data = getRedisHAHealthConfigMapData(cr, useTLSForRedis)
 
 	if err := controllerutil.SetControllerReference(cr, cm, r.Scheme); err != nil {
 		return err
@@ -654,6 +681,10 @@ func (r *ReconcileArgoCD) reconcileRedisHAConfigMap(cr *argoproj.ArgoCD, useTLSF
 			// ConfigMap exists but HA enabled flag has been set to false, delete the ConfigMap
 			return r.Client.Delete(context.TODO(), cm)
 		}
+		if desired := getRedisHAConfigMapData(cr, useTLSForRedis); !reflect.DeepEqual(cm.Data, desired) {
+			cm.Data = desired
+			return r.Client.Update(context.TODO(), cm)
+		}
 		return nil // ConfigMap found with nothing changed, move along...
 	}
 
@@ -661,13 +692,7 @@ func (r *ReconcileArgoCD) reconcileRedisHAConfigMap(cr *argoproj.ArgoCD, useTLSF
 		return nil // HA not enabled, do nothing.
 	}
 
-	cm.Data = map[string]string{
-		"haproxy.cfg":     getRedisHAProxyConfig(cr, useTLSForRedis),
-		"haproxy_init.sh": getRedisHAProxyScript(cr),
-		"init.sh":         getRedisInitScript(cr, useTLSForRedis),
-		"redis.conf":      getRedisConf(useTLSForRedis),
-		"sentinel.conf":   getRedisSentinelConf(useTLSForRedis),
-	}
+	cm.Data = getRedisHAConfigMapData(cr, useTLSForRedis)
 
 	if err := controllerutil.SetControllerReference(cr, cm, r.Scheme); err != nil {
 		return err
@@ -675,24 +700,35 @@ func (r *ReconcileArgoCD) reconcileRedisHAConfigMap(cr *argoproj.ArgoCD, useTLSF
 	return r.Client.Create(context.TODO(), cm)
 }
 
-func (r *ReconcileArgoCD) recreateRedisHAConfigMap(cr *argoproj.ArgoCD, useTLSForRedis bool) error {
-	cm := newConfigMapWithName(common.ArgoCDRedisHAConfigMapName, cr)
-	if argoutil.IsObjectFound(r.Client, cr.Namespace, cm.Name, cm) {
-		if err := r.Client.Delete(context.TODO(), cm); err != nil {
-			return err
-		}
+// getRedisHAChecksum returns the checksum of the given files of the given Redis HA ConfigMap data. The checksums of
+// the Redis TLS certificate and password are included when they are used, so that they are rolled out once rotated.
+func getRedisHAChecksum(cr *argoproj.ArgoCD, useTLSForRedis bool, data map[string]string, files ...string) string {
+	sum := sha256.New()
+	for _, file := range files {
+		sum.Write([]byte(file))
+		sum.Write([]byte{0})
+		sum.Write([]byte(data[file]))
+		sum.Write([]byte{0})
 	}
-	return r.reconcileRedisHAConfigMap(cr, useTLSForRedis)
+	if useTLSForRedis {
+		sum.Write([]byte(cr.Status.RedisTLSChecksum))
+	}
+	if cr.Spec.Redis.WantsAuth() {
+		sum.Write([]byte(cr.Status.RedisAuthChecksum))
+	}
+	return fmt.Sprintf("%x", sum.Sum(nil))
 }
 
-func (r *ReconcileArgoCD) recreateRedisHAHealthConfigMap(cr *argoproj.ArgoCD, useTLSForRedis bool) error {
-	cm := newConfigMapWithName(common.ArgoCDRedisHAHealthConfigMapName, cr)
-	if argoutil.IsObjectFound(r.Client, cr.Namespace, cm.Name, cm) {
-		if err := r.Client.Delete(context.TODO(), cm); err != nil {
-			return err
-		}
-	}
-	return r.reconcileRedisHAHealthConfigMap(cr, useTLSForRedis)
+// getRedisHAServerChecksum returns the checksum of the configuration of the Redis HA servers of the given ArgoCD.
+func getRedisHAServerChecksum(cr *argoproj.ArgoCD, useTLSForRedis bool) string {
+	data := argoutil.AppendStringMap(getRedisHAConfigMapData(cr, useTLSForRedis), getRedisHAHealthConfigMapData(cr, useTLSForRedis))
+	return getRedisHAChecksum(cr, useTLSForRedis, data, "init.sh", "redis.conf", "sentinel.conf",
+		"redis_liveness.sh", "redis_readiness.sh", "sentinel_liveness.sh")
+}
+
+// getRedisHAProxyChecksum returns the checksum of the configuration of the Redis HA proxy of the given ArgoCD.
+func getRedisHAProxyChecksum(cr *argoproj.ArgoCD, useTLSForRedis bool) string {
+	return getRedisHAChecksum(cr, useTLSForRedis, getRedisHAConfigMapData(cr, useTLSForRedis), "haproxy.cfg", "haproxy_init.sh")
 }
 
 // reconcileSSHKnownHosts will ensure that the ArgoCD SSH Known Hosts ConfigMap is present.
@@ -997,3 +997,33 @@ func Test_reconcileRBAC(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, cm.Data["policy.matchMode"], matcherMode)
 }
+
+func TestReconcileArgoCD_reconcileRedisConfiguration_update(t *testing.T) {
+	logf.SetLogger(ZapLogger(true))
+	t.Setenv("REDIS_CONFIG_PATH", "../../build/redis")
+	a := makeTestArgoCD(func(a *argoproj.ArgoCD) {
+		a.Spec.HA.Enabled = true
+	})
+	resObjs := []client.Object{a}
+	subresObjs := []client.Object{a}
+	runtimeObjs := []runtime.Object{}
+	sch := makeTestReconcilerScheme(argoproj.AddToScheme)
+	cl := makeTestReconcilerClient(sch, resObjs, subresObjs, runtimeObjs)
+	r := makeTestReconciler(cl, sch)
+
+	assert.NoError(t, r.reconcileRedisConfiguration(a, false))
+
+	cm := &corev1.ConfigMap{}
+	assert.NoError(t, r.Client.Get(context.TODO(), types.NamespacedName{Name: common.ArgoCDRedisHAConfigMapName, Namespace: testNamespace}, cm))
+	assert.NotEmpty(t, cm.Data["redis.conf"])
+	assert.Equal(t, getRedisConf(a, false), cm.Data["redis.conf"])
+
+	// the ConfigMaps are updated in place once TLS is used
+	assert.NoError(t, r.reconcileRedisConfiguration(a, true))
+
+	assert.NoError(t, r.Client.Get(context.TODO(), types.NamespacedName{Name: common.ArgoCDRedisHAConfigMapName, Namespace: testNamespace}, cm))
+	assert.Equal(t, getRedisConf(a, true), cm.Data["redis.conf"])
+	assert.Equal(t, getRedisHAProxyConfig(a, true), cm.Data["haproxy.cfg"])
+	assert.NoError(t, r.Client.Get(context.TODO(), types.NamespacedName{Name: common.ArgoCDRedisHAHealthConfigMapName, Namespace: testNamespace}, cm))
+	assert.Equal(t, getRedisLivenessScript(a, true), cm.Data["redis_liveness.sh"])
+}
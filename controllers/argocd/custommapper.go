@@ -210,3 +210,76 @@ func (r *ReconcileArgoCD) applicationSetSCMTLSConfigMapMapper(ctx context.Contex
 
 	return result
 }
+
+// argoCDExportMapper maps a watch event on an ArgoCDExport back to the ArgoCD objects that import it, so that an
+// import waiting for its ArgoCDExport is started once the ArgoCDExport is created.
+func (r *ReconcileArgoCD) argoCDExportMapper(ctx context.Context, o client.Object) []reconcile.Request {
+	var result = []reconcile.Request{}
+
+	argocds := &argoproj.ArgoCDList{}
+	if err := r.Client.List(ctx, argocds); err != nil {
+		log.Error(err, "failed to list ArgoCD instances")
+		return result
+	}
+
+	for _, argocd := range argocds.Items {
+		if argocd.Spec.Import == nil {
+			continue
+		}
+		if status := desiredImportStatus(&argocd); status.Name == o.GetName() && status.Namespace == o.GetNamespace() {
+			result = append(result, reconcile.Request{
+				NamespacedName: client.ObjectKey{Name: argocd.Name, Namespace: argocd.Namespace},
+			})
+		}
+	}
+	return result
+}
+
+// dexConnectorSecretMapper maps a watch event on a Secret back to the ArgoCD objects whose typed Dex connectors
+// reference it, so that the rotated secrets of the connectors are rolled out to Dex.
+func (r *ReconcileArgoCD) dexConnectorSecretMapper(ctx context.Context, o client.Object) []reconcile.Request {
+	var result = []reconcile.Request{}
+
+	argocds := &argoproj.ArgoCDList{}
+	if err := r.Client.List(ctx, argocds, &client.ListOptions{Namespace: o.GetNamespace()}); err != nil {
+		return result
+	}
+
+	for _, argocd := range argocds.Items {
+		if !UseDex(&argocd) {
+			continue
+		}
+		for _, name := range getDexConnectorSecretNames(&argocd) {
+			if name == o.GetName() {
+				result = append(result, reconcile.Request{
+					NamespacedName: client.ObjectKey{Name: argocd.Name, Namespace: argocd.Namespace},
+				})
+				break
+			}
+		}
+	}
+	return result
+}
+
+// redisAuthSecretMapper maps a watch event on a Secret back to the ArgoCD objects whose Redis auth options reference
+// it, so that the rotated Redis password is rolled out.
+func (r *ReconcileArgoCD) redisAuthSecretMapper(ctx context.Context, o client.Object) []reconcile.Request {
+	var result = []reconcile.Request{}
+
+	argocds := &argoproj.ArgoCDList{}
+	if err := r.Client.List(ctx, argocds, &client.ListOptions{Namespace: o.GetNamespace()}); err != nil {
+		return result
+	}
+
+	for _, argocd := range argocds.Items {
+		if !argocd.Spec.Redis.WantsAuth() || argocd.Spec.Redis.Auth.PasswordSecret == nil {
+			continue
+		}
+		if argocd.Spec.Redis.Auth.PasswordSecret.Name == o.GetName() {
+			result = append(result, reconcile.Request{
+				NamespacedName: client.ObjectKey{Name: argocd.Name, Namespace: argocd.Namespace},
+			})
+		}
+	}
+	return result
+}
@@ -32,7 +32,6 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
-	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 )
 
@@ -57,23 +56,6 @@ func getArgoCDServerReplicas(cr *argoproj.ArgoCD) *int32 {
 	return nil
 }
 
-func (r *ReconcileArgoCD) getArgoCDExport(cr *argoproj.ArgoCD) *argoprojv1alpha1.ArgoCDExport {
-	if cr.Spec.Import == nil {
-		return nil
-	}
-
-	namespace := cr.ObjectMeta.Namespace
-	if cr.Spec.Import.Namespace != nil && len(*cr.Spec.Import.Namespace) > 0 {
-		namespace = *cr.Spec.Import.Namespace
-	}
-
-	export := &argoprojv1alpha1.ArgoCDExport{}
-	if argoutil.IsObjectFound(r.Client, namespace, cr.Spec.Import.Name, export) {
-		return export
-	}
-	return nil
-}
-
 func getArgoExportSecretName(export *argoprojv1alpha1.ArgoCDExport) string {
 	name := argoutil.NameWithSuffix(export.ObjectMeta, "export")
 	if export.Spec.Storage != nil && len(export.Spec.Storage.SecretName) > 0 {
@@ -82,43 +64,41 @@ func getArgoExportSecretName(export *argoprojv1alpha1.ArgoCDExport) string {
 	return name
 }
 
-func getArgoImportBackend(client client.Client, cr *argoproj.ArgoCD) string {
+// getArgoImportBackend will return the storage backend of the given ArgoCDExport.
+func getArgoImportBackend(export *argoprojv1alpha1.ArgoCDExport) string {
 	backend := common.ArgoCDExportStorageBackendLocal
-	namespace := cr.ObjectMeta.Namespace
-	if cr.Spec.Import != nil && cr.Spec.Import.Namespace != nil && len(*cr.Spec.Import.Namespace) > 0 {
-		namespace = *cr.Spec.Import.Namespace
-	}
-
-	export := &argoprojv1alpha1.ArgoCDExport{}
-	if argoutil.IsObjectFound(client, namespace, cr.Spec.Import.Name, export) {
-		if export.Spec.Storage != nil && len(export.Spec.Storage.Backend) > 0 {
-			backend = export.Spec.Storage.Backend
-		}
+	if export.Spec.Storage != nil && len(export.Spec.Storage.Backend) > 0 {
+		backend = export.Spec.Storage.Backend
 	}
 	return backend
 }
 
-// getArgoImportCommand will return the command for the ArgoCD import process.
-func getArgoImportCommand(client client.Client, cr *argoproj.ArgoCD) []string {
+// getArgoImportCommand will return the command for the ArgoCD import process, performed by the
+// argocd-operator-util binary built from cmd/argocd-operator-util, or by util.sh in the given legacy image.
+func getArgoImportCommand(export *argoprojv1alpha1.ArgoCDExport, image string) []string {
 	cmd := make([]string, 0)
-	cmd = append(cmd, "uid_entrypoint.sh")
+	if argoutil.IsLegacyExportImage(image) {
+		cmd = append(cmd, "uid_entrypoint.sh")
+	}
 	cmd = append(cmd, "argocd-operator-util")
 	cmd = append(cmd, "import")
-	cmd = append(cmd, getArgoImportBackend(client, cr))
+	cmd = append(cmd, getArgoImportBackend(export))
 	return cmd
 }
 
-func getArgoImportContainerEnv(cr *argoprojv1alpha1.ArgoCDExport) []corev1.EnvVar {
+// getArgoImportContainerEnv will return the environment of the Argo CD import process, restoring a backup of the
+// given ArgoCDExport.
+func getArgoImportContainerEnv(cr *argoproj.ArgoCD, export *argoprojv1alpha1.ArgoCDExport) []corev1.EnvVar {
 	env := make([]corev1.EnvVar, 0)
 
-	switch cr.Spec.Storage.Backend {
+	switch strings.ToLower(export.Spec.Storage.Backend) {
 	case common.ArgoCDExportStorageBackendAWS:
 		env = append(env, corev1.EnvVar{
 			Name: "AWS_ACCESS_KEY_ID",
 			ValueFrom: &corev1.EnvVarSource{
 				SecretKeyRef: &corev1.SecretKeySelector{
 					LocalObjectReference: corev1.LocalObjectReference{
-						Name: argoutil.FetchStorageSecretName(cr),
+						Name: argoutil.FetchStorageSecretName(export),
 					},
 					Key: "aws.access.key.id",
 				},
@@ -130,34 +110,43 @@ func getArgoImportContainerEnv(cr *argoprojv1alpha1.ArgoCDExport) []corev1.EnvVa
 			ValueFrom: &corev1.EnvVarSource{
 				SecretKeyRef: &corev1.SecretKeySelector{
 					LocalObjectReference: corev1.LocalObjectReference{
-						Name: argoutil.FetchStorageSecretName(cr),
+						Name: argoutil.FetchStorageSecretName(export),
 					},
 					Key: "aws.secret.access.key",
 				},
 			},
 		})
+	case common.ArgoCDExportStorageBackendS3:
+		env = append(env, argoutil.GetS3StorageEnv(export)...)
 	}
 
+	if cr.Spec.Import != nil && len(cr.Spec.Import.BackupID) > 0 {
+		env = append(env, corev1.EnvVar{
+			Name:  common.ArgoCDImportBackupIDEnvName,
+			Value: cr.Spec.Import.BackupID,
+		})
+	}
+	env = append(env, argoutil.GetEncryptionEnv(export)...)
 	return env
 }
 
 // getArgoImportContainerImage will return the container image for the Argo CD import process.
-func getArgoImportContainerImage(cr *argoprojv1alpha1.ArgoCDExport) string {
+func getArgoImportContainerImage(cr *argoproj.ArgoCD, export *argoprojv1alpha1.ArgoCDExport) string {
 	img := common.ArgoCDDefaultExportJobImage
-	if len(cr.Spec.Image) > 0 {
-		img = cr.Spec.Image
+	if len(export.Spec.Image) > 0 {
+		img = export.Spec.Image
 	}
 
 	tag := common.ArgoCDDefaultExportJobVersion
-	if len(cr.Spec.Version) > 0 {
-		tag = cr.Spec.Version
+	if len(export.Spec.Version) > 0 {
+		tag = export.Spec.Version
 	}
 
-	return argoutil.CombineImageTag(img, tag)
+	return argoutil.ReplaceImageRegistry(argoutil.CombineImageTag(img, tag), cr.Spec.ImageRegistry)
 }
 
 // getArgoImportVolumeMounts will return the VolumneMounts for the given ArgoCDExport.
-func getArgoImportVolumeMounts() []corev1.VolumeMount {
+func getArgoImportVolumeMounts(cr *argoprojv1alpha1.ArgoCDExport) []corev1.VolumeMount {
 	mounts := make([]corev1.VolumeMount, 0)
 
 	mounts = append(mounts, corev1.VolumeMount{
@@ -170,6 +159,8 @@ func getArgoImportVolumeMounts() []corev1.VolumeMount {
 		MountPath: "/secrets",
 	})
 
+	mounts = append(mounts, argoutil.GetS3StorageVolumeMounts(cr)...)
+
 	return mounts
 }
 
@@ -204,15 +195,21 @@ func getArgoImportVolumes(cr *argoprojv1alpha1.ArgoCDExport) []corev1.Volume {
 		},
 	})
 
+	volumes = append(volumes, argoutil.GetS3StorageVolumes(cr)...)
+
 	return volumes
 }
 
-func getArgoRedisArgs(useTLS bool) []string {
+func getArgoRedisArgs(cr *argoproj.ArgoCD, useTLS bool) []string {
 	args := make([]string, 0)
 
 	args = append(args, "--save", "")
 	args = append(args, "--appendonly", "no")
 
+	if cr.Spec.Redis.WantsAuth() {
+		args = append(args, "--requirepass", fmt.Sprintf("$(%s)", redisPasswordEnvName))
+	}
+
 	if useTLS {
 		args = append(args, "--tls-port", "6379")
 		args = append(args, "--port", "0")
@@ -425,7 +422,7 @@ func (r *ReconcileArgoCD) reconcileDeployments(cr *argoproj.ArgoCD, useTLSForRed
 		return err
 	}
 
-	err = r.reconcileRedisHAProxyDeployment(cr)
+	err = r.reconcileRedisHAProxyDeployment(cr, useTLSForRedis)
 	if err != nil {
 		return err
 	}
@@ -455,7 +452,7 @@ func (r *ReconcileArgoCD) reconcileGrafanaDeployment(cr *argoproj.ArgoCD) error
 	AddSeccompProfileForOpenShift(r.Client, &deploy.Spec.Template.Spec)
 	deploy.Spec.Template.Spec.Containers = []corev1.Container{{
 		Image:           getGrafanaContainerImage(cr),
-		ImagePullPolicy: corev1.PullAlways,
+		ImagePullPolicy: getImagePullPolicy(cr, "", corev1.PullAlways),
 		Name:            "grafana",
 		Ports: []corev1.ContainerPort{
 			{
@@ -544,6 +541,9 @@ func (r *ReconcileArgoCD) reconcileGrafanaDeployment(cr *argoproj.ArgoCD) error
 		},
 	}
 
+	applyNodePlacement(&deploy.Spec.Template.Spec, getNodePlacement(cr, nil), deploy.Spec.Selector)
+	deploy.Spec.Template.Spec.ImagePullSecrets = getImagePullSecrets(cr, nil)
+
 	existing := newDeploymentWithSuffix("grafana", "grafana", cr)
 	if argoutil.IsObjectFound(r.Client, cr.Namespace, existing.Name, existing) {
 		if !cr.Spec.Grafana.Enabled {
@@ -556,6 +556,7 @@ func (r *ReconcileArgoCD) reconcileGrafanaDeployment(cr *argoproj.ArgoCD) error
 			changed = true
 		}
 		updateNodePlacement(existing, deploy, &changed)
+		updateImagePullOptions(&existing.Spec.Template.Spec, &deploy.Spec.Template.Spec, &changed)
 		if !reflect.DeepEqual(existing.Spec.Template.Spec.Containers[0].Env,
 			deploy.Spec.Template.Spec.Containers[0].Env) {
 			existing.Spec.Template.Spec.Containers[0].Env = deploy.Spec.Template.Spec.Containers[0].Env
@@ -586,10 +587,15 @@ func (r *ReconcileArgoCD) reconcileRedisDeployment(cr *argoproj.ArgoCD, useTLS b
 
 	AddSeccompProfileForOpenShift(r.Client, &deploy.Spec.Template.Spec)
 
+	redisEnv := proxyEnvVars()
+	if cr.Spec.Redis.WantsAuth() {
+		redisEnv = proxyEnvVars(getRedisPasswordEnvVar(redisPasswordEnvName, cr))
+	}
+
 	deploy.Spec.Template.Spec.Containers = []corev1.Container{{
-		Args:            getArgoRedisArgs(useTLS),
+		Args:            getArgoRedisArgs(cr, useTLS),
 		Image:           getRedisContainerImage(cr),
-		ImagePullPolicy: corev1.PullAlways,
+		ImagePullPolicy: getImagePullPolicy(cr, cr.Spec.Redis.ImagePullPolicy, corev1.PullAlways),
 		Name:            "redis",
 		Ports: []corev1.ContainerPort{
 			{
@@ -597,7 +603,7 @@ func (r *ReconcileArgoCD) reconcileRedisDeployment(cr *argoproj.ArgoCD, useTLS b
 			},
 		},
 		Resources: getRedisResources(cr),
-		Env:       proxyEnvVars(),
+		Env:       redisEnv,
 		SecurityContext: &corev1.SecurityContext{
 			AllowPrivilegeEscalation: boolPtr(false),
 			Capabilities: &corev1.Capabilities{
@@ -629,6 +635,9 @@ func (r *ReconcileArgoCD) reconcileRedisDeployment(cr *argoproj.ArgoCD, useTLS b
 		},
 	}
 
+	applyNodePlacement(&deploy.Spec.Template.Spec, getNodePlacement(cr, cr.Spec.Redis.NodePlacement), deploy.Spec.Selector)
+	deploy.Spec.Template.Spec.ImagePullSecrets = getImagePullSecrets(cr, cr.Spec.Redis.ImagePullSecrets)
+
 	if err := applyReconcilerHook(cr, deploy, ""); err != nil {
 		return err
 	}
@@ -653,6 +662,7 @@ func (r *ReconcileArgoCD) reconcileRedisDeployment(cr *argoproj.ArgoCD, useTLS b
 			changed = true
 		}
 		updateNodePlacement(existing, deploy, &changed)
+		updateImagePullOptions(&existing.Spec.Template.Spec, &deploy.Spec.Template.Spec, &changed)
 
 		if !reflect.DeepEqual(deploy.Spec.Template.Spec.Containers[0].Args, existing.Spec.Template.Spec.Containers[0].Args) {
 			existing.Spec.Template.Spec.Containers[0].Args = deploy.Spec.Template.Spec.Containers[0].Args
@@ -691,8 +701,11 @@ func (r *ReconcileArgoCD) reconcileRedisDeployment(cr *argoproj.ArgoCD, useTLS b
 }
 
 // reconcileRedisHAProxyDeployment will ensure the Deployment resource is present for the Redis HA Proxy component.
-func (r *ReconcileArgoCD) reconcileRedisHAProxyDeployment(cr *argoproj.ArgoCD) error {
+func (r *ReconcileArgoCD) reconcileRedisHAProxyDeployment(cr *argoproj.ArgoCD, useTLSForRedis bool) error {
 	deploy := newDeploymentWithSuffix("redis-ha-haproxy", "redis", cr)
+	deploy.Spec.Template.Annotations = map[string]string{
+		common.ArgoCDRedisHAConfigChecksumAnnotation: getRedisHAProxyChecksum(cr, useTLSForRedis),
+	}
 
 	deploy.Spec.Template.Spec.Affinity = &corev1.Affinity{
 		PodAntiAffinity: &corev1.PodAntiAffinity{
@@ -724,7 +737,7 @@ func (r *ReconcileArgoCD) reconcileRedisHAProxyDeployment(cr *argoproj.ArgoCD) e
 
 	deploy.Spec.Template.Spec.Containers = []corev1.Container{{
 		Image:           getRedisHAProxyContainerImage(cr),
-		ImagePullPolicy: corev1.PullIfNotPresent,
+		ImagePullPolicy: getImagePullPolicy(cr, cr.Spec.HA.ImagePullPolicy, corev1.PullIfNotPresent),
 		Name:            "haproxy",
 		Env:             proxyEnvVars(),
 		LivenessProbe: &corev1.Probe{
@@ -777,9 +790,9 @@ func (r *ReconcileArgoCD) reconcileRedisHAProxyDeployment(cr *argoproj.ArgoCD) e
 			"sh",
 		},
 		Image:           getRedisHAProxyContainerImage(cr),
-		ImagePullPolicy: corev1.PullIfNotPresent,
+		ImagePullPolicy: getImagePullPolicy(cr, cr.Spec.HA.ImagePullPolicy, corev1.PullIfNotPresent),
 		Name:            "config-init",
-		Env:             proxyEnvVars(),
+		Env:             proxyEnvVars(getRedisHAAuthEnv(cr)...),
 		Resources:       getRedisHAResources(cr),
 		SecurityContext: &corev1.SecurityContext{
 			AllowPrivilegeEscalation: boolPtr(false),
@@ -849,6 +862,9 @@ func (r *ReconcileArgoCD) reconcileRedisHAProxyDeployment(cr *argoproj.ArgoCD) e
 	if err != nil {
 		log.Error(err, "error getting cluster version")
 	}
+	applyNodePlacement(&deploy.Spec.Template.Spec, getNodePlacement(cr, cr.Spec.HA.NodePlacement), deploy.Spec.Selector)
+	deploy.Spec.Template.Spec.ImagePullSecrets = getImagePullSecrets(cr, cr.Spec.HA.ImagePullSecrets)
+
 	if err := applyReconcilerHook(cr, deploy, version); err != nil {
 		return err
 	}
@@ -860,6 +876,7 @@ func (r *ReconcileArgoCD) reconcileRedisHAProxyDeployment(cr *argoproj.ArgoCD) e
 			return r.Client.Delete(context.TODO(), existing)
 		}
 		changed := false
+		updateConfigChecksum(&existing.Spec.Template, &deploy.Spec.Template, &changed)
 		actualImage := existing.Spec.Template.Spec.Containers[0].Image
 		desiredImage := getRedisHAProxyContainerImage(cr)
 
@@ -869,6 +886,7 @@ func (r *ReconcileArgoCD) reconcileRedisHAProxyDeployment(cr *argoproj.ArgoCD) e
 			changed = true
 		}
 		updateNodePlacement(existing, deploy, &changed)
+		updateImagePullOptions(&existing.Spec.Template.Spec, &deploy.Spec.Template.Spec, &changed)
 
 		if !reflect.DeepEqual(deploy.Spec.Template.Spec.Containers[0].Resources, existing.Spec.Template.Spec.Containers[0].Resources) {
 			existing.Spec.Template.Spec.Containers[0].Resources = deploy.Spec.Template.Spec.Containers[0].Resources
@@ -880,6 +898,11 @@ func (r *ReconcileArgoCD) reconcileRedisHAProxyDeployment(cr *argoproj.ArgoCD) e
 			changed = true
 		}
 
+		if !reflect.DeepEqual(deploy.Spec.Template.Spec.InitContainers[0].Env, existing.Spec.Template.Spec.InitContainers[0].Env) {
+			existing.Spec.Template.Spec.InitContainers[0].Env = deploy.Spec.Template.Spec.InitContainers[0].Env
+			changed = true
+		}
+
 		if changed {
 			return r.Client.Update(context.TODO(), existing)
 		}
@@ -914,6 +937,7 @@ func (r *ReconcileArgoCD) reconcileRepoDeployment(cr *argoproj.ArgoCD, useTLSFor
 	repoEnv := cr.Spec.Repo.Env
 	// Environment specified in the CR take precedence over everything else
 	repoEnv = argoutil.EnvMerge(repoEnv, proxyEnvVars(), false)
+	repoEnv = argoutil.EnvMerge(repoEnv, getRedisAuthEnv(cr), true)
 	if cr.Spec.Repo.ExecTimeout != nil {
 		repoEnv = argoutil.EnvMerge(repoEnv, []corev1.EnvVar{{Name: "ARGOCD_EXEC_TIMEOUT", Value: fmt.Sprintf("%ds", *cr.Spec.Repo.ExecTimeout)}}, true)
 	}
@@ -924,7 +948,7 @@ func (r *ReconcileArgoCD) reconcileRepoDeployment(cr *argoproj.ArgoCD, useTLSFor
 		Name:            "copyutil",
 		Image:           getArgoContainerImage(cr),
 		Command:         getArgoCmpServerInitCommand(),
-		ImagePullPolicy: corev1.PullAlways,
+		ImagePullPolicy: getImagePullPolicy(cr, cr.Spec.Repo.ImagePullPolicy, corev1.PullAlways),
 		Resources:       getArgoRepoResources(cr),
 		Env:             proxyEnvVars(),
 		SecurityContext: &corev1.SecurityContext{
@@ -990,7 +1014,7 @@ func (r *ReconcileArgoCD) reconcileRepoDeployment(cr *argoproj.ArgoCD, useTLSFor
 	deploy.Spec.Template.Spec.Containers = []corev1.Container{{
 		Command:         getArgoRepoCommand(cr, useTLSForRedis),
 		Image:           getRepoServerContainerImage(cr),
-		ImagePullPolicy: corev1.PullAlways,
+		ImagePullPolicy: getImagePullPolicy(cr, cr.Spec.Repo.ImagePullPolicy, corev1.PullAlways),
 		LivenessProbe: &corev1.Probe{
 			ProbeHandler: corev1.ProbeHandler{
 				TCPSocket: &corev1.TCPSocketAction{
@@ -1122,6 +1146,9 @@ func (r *ReconcileArgoCD) reconcileRepoDeployment(cr *argoproj.ArgoCD, useTLSFor
 		deploy.Spec.Replicas = replicas
 	}
 
+	applyNodePlacement(&deploy.Spec.Template.Spec, getNodePlacement(cr, cr.Spec.Repo.NodePlacement), deploy.Spec.Selector)
+	deploy.Spec.Template.Spec.ImagePullSecrets = getImagePullSecrets(cr, cr.Spec.Repo.ImagePullSecrets)
+
 	existing := newDeploymentWithSuffix("repo-server", "repo-server", cr)
 	if argoutil.IsObjectFound(r.Client, cr.Namespace, existing.Name, existing) {
 
@@ -1145,6 +1172,7 @@ func (r *ReconcileArgoCD) reconcileRepoDeployment(cr *argoproj.ArgoCD, useTLSFor
 			changed = true
 		}
 		updateNodePlacement(existing, deploy, &changed)
+		updateImagePullOptions(&existing.Spec.Template.Spec, &deploy.Spec.Template.Spec, &changed)
 		if !reflect.DeepEqual(deploy.Spec.Template.Spec.Volumes, existing.Spec.Template.Spec.Volumes) {
 			existing.Spec.Template.Spec.Volumes = deploy.Spec.Template.Spec.Volumes
 			changed = true
@@ -1215,11 +1243,12 @@ func (r *ReconcileArgoCD) reconcileServerDeployment(cr *argoproj.ArgoCD, useTLSF
 	deploy := newDeploymentWithSuffix("server", "server", cr)
 	serverEnv := cr.Spec.Server.Env
 	serverEnv = argoutil.EnvMerge(serverEnv, proxyEnvVars(), false)
+	serverEnv = argoutil.EnvMerge(serverEnv, getRedisAuthEnv(cr), true)
 	AddSeccompProfileForOpenShift(r.Client, &deploy.Spec.Template.Spec)
 	deploy.Spec.Template.Spec.Containers = []corev1.Container{{
 		Command:         getArgoServerCommand(cr, useTLSForRedis),
 		Image:           getArgoContainerImage(cr),
-		ImagePullPolicy: corev1.PullAlways,
+		ImagePullPolicy: getImagePullPolicy(cr, cr.Spec.Server.ImagePullPolicy, corev1.PullAlways),
 		Env:             serverEnv,
 		LivenessProbe: &corev1.Probe{
 			ProbeHandler: corev1.ProbeHandler{
@@ -1323,6 +1352,9 @@ func (r *ReconcileArgoCD) reconcileServerDeployment(cr *argoproj.ArgoCD, useTLSF
 		deploy.Spec.Replicas = replicas
 	}
 
+	applyNodePlacement(&deploy.Spec.Template.Spec, getNodePlacement(cr, cr.Spec.Server.NodePlacement), deploy.Spec.Selector)
+	deploy.Spec.Template.Spec.ImagePullSecrets = getImagePullSecrets(cr, cr.Spec.Server.ImagePullSecrets)
+
 	existing := newDeploymentWithSuffix("server", "server", cr)
 	if argoutil.IsObjectFound(r.Client, cr.Namespace, existing.Name, existing) {
 		if !cr.Spec.Server.IsEnabled() {
@@ -1339,6 +1371,7 @@ func (r *ReconcileArgoCD) reconcileServerDeployment(cr *argoproj.ArgoCD, useTLSF
 			changed = true
 		}
 		updateNodePlacement(existing, deploy, &changed)
+		updateImagePullOptions(&existing.Spec.Template.Spec, &deploy.Spec.Template.Spec, &changed)
 		if !reflect.DeepEqual(existing.Spec.Template.Spec.Containers[0].Env,
 			deploy.Spec.Template.Spec.Containers[0].Env) {
 			existing.Spec.Template.Spec.Containers[0].Env = deploy.Spec.Template.Spec.Containers[0].Env
@@ -1427,14 +1460,21 @@ func isRemoveManagedByLabelOnArgoCDDeletion() bool {
 	return false
 }
 
-// to update nodeSelector and tolerations in reconciler
-func updateNodePlacement(existing *appsv1.Deployment, deploy *appsv1.Deployment, changed *bool) {
-	if !reflect.DeepEqual(existing.Spec.Template.Spec.NodeSelector, deploy.Spec.Template.Spec.NodeSelector) {
-		existing.Spec.Template.Spec.NodeSelector = deploy.Spec.Template.Spec.NodeSelector
-		*changed = true
+// updateConfigChecksum updates the configuration checksum annotation of the given existing pod template when it differs
+// from the one of the given desired pod template, which rolls the pods out, and sets changed to true if it was updated.
+func updateConfigChecksum(existing *corev1.PodTemplateSpec, desired *corev1.PodTemplateSpec, changed *bool) {
+	checksum := desired.Annotations[common.ArgoCDRedisHAConfigChecksumAnnotation]
+	if existing.Annotations[common.ArgoCDRedisHAConfigChecksumAnnotation] == checksum {
+		return
 	}
-	if !reflect.DeepEqual(existing.Spec.Template.Spec.Tolerations, deploy.Spec.Template.Spec.Tolerations) {
-		existing.Spec.Template.Spec.Tolerations = deploy.Spec.Template.Spec.Tolerations
-		*changed = true
+	if existing.Annotations == nil {
+		existing.Annotations = map[string]string{}
 	}
+	existing.Annotations[common.ArgoCDRedisHAConfigChecksumAnnotation] = checksum
+	*changed = true
+}
+
+// to update nodeSelector, tolerations and the other scheduling options in reconciler
+func updateNodePlacement(existing *appsv1.Deployment, deploy *appsv1.Deployment, changed *bool) {
+	updatePodSchedulingOptions(&existing.Spec.Template.Spec, &deploy.Spec.Template.Spec, changed)
 }
@@ -770,7 +770,7 @@ func TestReconcileArgoCD_reconcileDeployments_HA_proxy_with_resources(t *testing
 	r := makeTestReconciler(cl, sch)
 
 	// test resource is Created on reconciliation
-	assert.NoError(t, r.reconcileRedisHAProxyDeployment(a))
+	assert.NoError(t, r.reconcileRedisHAProxyDeployment(a, false))
 
 	deployment := &appsv1.Deployment{}
 	assert.NoError(t, r.Client.Get(
@@ -806,7 +806,7 @@ func TestReconcileArgoCD_reconcileDeployments_HA_proxy_with_resources(t *testing
 		},
 	}
 	a.Spec.HA.Resources = &newResources
-	assert.NoError(t, r.reconcileRedisHAProxyDeployment(a))
+	assert.NoError(t, r.reconcileRedisHAProxyDeployment(a, false))
 
 	assert.NoError(t, r.Client.Get(
 		context.TODO(),
@@ -2005,3 +2005,31 @@ func TestArgoCDRepoServerDeploymentCommand(t *testing.T) {
 
 	assert.Equal(t, baseCommand, deployment.Spec.Template.Spec.Containers[0].Command)
 }
+
+func TestReconcileArgoCD_reconcileRedisHAProxyDeployment_configChecksum(t *testing.T) {
+	logf.SetLogger(ZapLogger(true))
+	t.Setenv("REDIS_CONFIG_PATH", "../../build/redis")
+	a := makeTestArgoCD(func(a *argoproj.ArgoCD) {
+		a.Spec.HA.Enabled = true
+	})
+
+	resObjs := []client.Object{a}
+	subresObjs := []client.Object{a}
+	runtimeObjs := []runtime.Object{}
+	sch := makeTestReconcilerScheme(argoproj.AddToScheme)
+	cl := makeTestReconcilerClient(sch, resObjs, subresObjs, runtimeObjs)
+	r := makeTestReconciler(cl, sch)
+
+	deployment := &appsv1.Deployment{}
+	key := types.NamespacedName{Name: a.Name + "-redis-ha-haproxy", Namespace: a.Namespace}
+	assert.NoError(t, r.reconcileRedisHAProxyDeployment(a, false))
+	assert.NoError(t, r.Client.Get(context.TODO(), key, deployment))
+	checksum := deployment.Spec.Template.Annotations[common.ArgoCDRedisHAConfigChecksumAnnotation]
+	assert.Equal(t, getRedisHAProxyChecksum(a, false), checksum)
+
+	// the proxy is rolled out once its configuration changes
+	assert.NoError(t, r.reconcileRedisHAProxyDeployment(a, true))
+	assert.NoError(t, r.Client.Get(context.TODO(), key, deployment))
+	assert.NotEqual(t, checksum, deployment.Spec.Template.Annotations[common.ArgoCDRedisHAConfigChecksumAnnotation])
+	assert.Equal(t, getRedisHAProxyChecksum(a, true), deployment.Spec.Template.Annotations[common.ArgoCDRedisHAConfigChecksumAnnotation])
+}
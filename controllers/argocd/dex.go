@@ -96,19 +96,30 @@ func (r *ReconcileArgoCD) getDexOAuthClientSecret(cr *argoproj.ArgoCD) (*string,
 	return &token, nil
 }
 
-// reconcileDexConfiguration will ensure that Dex is configured properly.
-func (r *ReconcileArgoCD) reconcileDexConfiguration(cm *corev1.ConfigMap, cr *argoproj.ArgoCD) error {
-	actual := cm.Data[common.ArgoCDKeyDexConfig]
-	desired := getDexConfig(cr)
+// getDesiredDexConfig will return the Dex configuration of the given ArgoCD, with its typed connectors rendered after
+// the connectors of the configuration.
+func (r *ReconcileArgoCD) getDesiredDexConfig(cr *argoproj.ArgoCD) (string, error) {
+	config := getDexConfig(cr)
 
 	// If no dexConfig expressed but openShiftOAuth is requested through `.spec.sso.dex`, use default
 	// openshift dex config
-	if len(desired) <= 0 && (cr.Spec.SSO != nil && cr.Spec.SSO.Dex != nil && cr.Spec.SSO.Dex.OpenShiftOAuth) {
+	if len(config) <= 0 && (cr.Spec.SSO != nil && cr.Spec.SSO.Dex != nil && cr.Spec.SSO.Dex.OpenShiftOAuth) {
 		cfg, err := r.getOpenShiftDexConfig(cr)
 		if err != nil {
-			return err
+			return "", err
 		}
-		desired = cfg
+		config = cfg
+	}
+
+	return renderDexConnectors(config, getDexConnectors(cr))
+}
+
+// reconcileDexConfiguration will ensure that Dex is configured properly.
+func (r *ReconcileArgoCD) reconcileDexConfiguration(cm *corev1.ConfigMap, cr *argoproj.ArgoCD) error {
+	actual := cm.Data[common.ArgoCDKeyDexConfig]
+	desired, err := r.getDesiredDexConfig(cr)
+	if err != nil {
+		return err
 	}
 
 	if actual != desired {
@@ -119,18 +130,23 @@ func (r *ReconcileArgoCD) reconcileDexConfiguration(cm *corev1.ConfigMap, cr *ar
 		}
 
 		// Trigger rollout of Dex Deployment to pick up changes.
-		deploy := newDeploymentWithSuffix("dex-server", "dex-server", cr)
-		if !argoutil.IsObjectFound(r.Client, deploy.Namespace, deploy.Name, deploy) {
-			log.Info("unable to locate dex deployment")
-			return nil
-		}
-
-		deploy.Spec.Template.ObjectMeta.Labels["dex.config.changed"] = time.Now().UTC().Format("01022006-150406-MST")
-		return r.Client.Update(context.TODO(), deploy)
+		return r.triggerDexRollout(cr)
 	}
 	return nil
 }
 
+// triggerDexRollout will trigger the rollout of the Dex Deployment, for it to pick up the changes of its configuration.
+func (r *ReconcileArgoCD) triggerDexRollout(cr *argoproj.ArgoCD) error {
+	deploy := newDeploymentWithSuffix("dex-server", "dex-server", cr)
+	if !argoutil.IsObjectFound(r.Client, deploy.Namespace, deploy.Name, deploy) {
+		log.Info("unable to locate dex deployment")
+		return nil
+	}
+
+	deploy.Spec.Template.ObjectMeta.Labels["dex.config.changed"] = time.Now().UTC().Format("01022006-150406-MST")
+	return r.Client.Update(context.TODO(), deploy)
+}
+
 // getOpenShiftDexConfig will return the configuration for the Dex server running on OpenShift.
 func (r *ReconcileArgoCD) getOpenShiftDexConfig(cr *argoproj.ArgoCD) (string, error) {
 	groups := []string{}
@@ -205,19 +221,24 @@ func (r *ReconcileArgoCD) reconcileDexDeployment(cr *argoproj.ArgoCD) error {
 
 	AddSeccompProfileForOpenShift(r.Client, &deploy.Spec.Template.Spec)
 
-	dexEnv := proxyEnvVars()
+	dexSpec := &argoproj.ArgoCDDexSpec{}
 	if cr.Spec.SSO != nil && cr.Spec.SSO.Dex != nil {
-		dexEnv = append(dexEnv, cr.Spec.SSO.Dex.Env...)
+		dexSpec = cr.Spec.SSO.Dex
 	}
 
+	dexEnv := proxyEnvVars()
+	dexEnv = append(dexEnv, dexSpec.Env...)
+
 	deploy.Spec.Template.Spec.Containers = []corev1.Container{{
 		Command: []string{
 			"/shared/argocd-dex",
 			"rundex",
 		},
 		Image: getDexContainerImage(cr),
-		Name:  "dex",
-		Env:   dexEnv,
+		// the pull policy of the dex container defaults to the one of Kubernetes
+		ImagePullPolicy: getImagePullPolicy(cr, dexSpec.ImagePullPolicy, ""),
+		Name:            "dex",
+		Env:             dexEnv,
 		LivenessProbe: &corev1.Probe{
 			ProbeHandler: corev1.ProbeHandler{
 				HTTPGet: &corev1.HTTPGetAction{
@@ -265,7 +286,7 @@ func (r *ReconcileArgoCD) reconcileDexDeployment(cr *argoproj.ArgoCD) error {
 		},
 		Env:             proxyEnvVars(),
 		Image:           getArgoContainerImage(cr),
-		ImagePullPolicy: corev1.PullAlways,
+		ImagePullPolicy: getImagePullPolicy(cr, dexSpec.ImagePullPolicy, corev1.PullAlways),
 		Name:            "copyutil",
 		Resources:       getDexResources(cr),
 		SecurityContext: &corev1.SecurityContext{
@@ -291,6 +312,9 @@ func (r *ReconcileArgoCD) reconcileDexDeployment(cr *argoproj.ArgoCD) error {
 		},
 	}}
 
+	applyNodePlacement(&deploy.Spec.Template.Spec, getNodePlacement(cr, dexSpec.NodePlacement), deploy.Spec.Selector)
+	deploy.Spec.Template.Spec.ImagePullSecrets = getImagePullSecrets(cr, dexSpec.ImagePullSecrets)
+
 	existing := newDeploymentWithSuffix("dex-server", "dex-server", cr)
 	if argoutil.IsObjectFound(r.Client, cr.Namespace, existing.Name, existing) {
 
@@ -317,6 +341,7 @@ func (r *ReconcileArgoCD) reconcileDexDeployment(cr *argoproj.ArgoCD) error {
 			changed = true
 		}
 		updateNodePlacement(existing, deploy, &changed)
+		updateImagePullOptions(&existing.Spec.Template.Spec, &deploy.Spec.Template.Spec, &changed)
 		if !reflect.DeepEqual(existing.Spec.Template.Spec.Containers[0].Env,
 			deploy.Spec.Template.Spec.Containers[0].Env) {
 			existing.Spec.Template.Spec.Containers[0].Env = deploy.Spec.Template.Spec.Containers[0].Env
@@ -399,7 +424,7 @@ func (r *ReconcileArgoCD) reconcileDexService(cr *argoproj.ArgoCD) error {
 
 // reconcileDexResources consolidates all dex resources reconciliation calls. It serves as the single place to trigger both creation
 // and deletion of dex resources based on the specified configuration of dex
-func (r *ReconcileArgoCD) reconcileDexResources(cr *argoproj.ArgoCD) error {
+func (r *ReconcileArgoCD) reconcileDexResources(cr *argoproj.ArgoCD, sso *ssoState) error {
 	if _, err := r.reconcileRole(common.ArgoCDDexServerComponent, policyRuleForDexServer(), cr); err != nil {
 		log.Error(err, "error reconciling dex role")
 	}
@@ -430,7 +455,7 @@ func (r *ReconcileArgoCD) reconcileDexResources(cr *argoproj.ArgoCD) error {
 		log.Error(err, "error reconciling dex deployment")
 	}
 
-	if err := r.reconcileStatusSSO(cr); err != nil {
+	if err := r.reconcileStatusSSO(cr, sso); err != nil {
 		log.Error(err, "error reconciling dex status")
 	}
 
@@ -442,7 +467,7 @@ func (r *ReconcileArgoCD) reconcileDexResources(cr *argoproj.ArgoCD) error {
 // RoleBinding and deployment are dependent on these resouces. During deletion the order is reversed.
 // Deployment and RoleBinding must be deleted before the role and sa. deleteDexResources will only be called during
 // delete events, so we don't need to worry about duplicate, recurring reconciliation calls
-func (r *ReconcileArgoCD) deleteDexResources(cr *argoproj.ArgoCD) error {
+func (r *ReconcileArgoCD) deleteDexResources(cr *argoproj.ArgoCD, sso *ssoState) error {
 	sa := &corev1.ServiceAccount{}
 	role := &rbacv1.Role{}
 
@@ -480,7 +505,7 @@ func (r *ReconcileArgoCD) deleteDexResources(cr *argoproj.ArgoCD) error {
 		log.Error(err, "error reconciling dex rolebinding")
 	}
 
-	if err := r.reconcileStatusSSO(cr); err != nil {
+	if err := r.reconcileStatusSSO(cr, sso); err != nil {
 		log.Error(err, "error reconciling dex status")
 	}
 
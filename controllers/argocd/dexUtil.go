@@ -47,9 +47,9 @@ func getDexContainerImage(cr *argoproj.ArgoCD) string {
 		defaultTag = true
 	}
 	if e := os.Getenv(common.ArgoCDDexImageEnvName); e != "" && (defaultTag && defaultImg) {
-		return e
+		return argoutil.ReplaceImageRegistry(e, cr.Spec.ImageRegistry)
 	}
-	return argoutil.CombineImageTag(img, tag)
+	return argoutil.ReplaceImageRegistry(argoutil.CombineImageTag(img, tag), cr.Spec.ImageRegistry)
 }
 
 // getDexOAuthRedirectURI will return the OAuth redirect URI for the Dex server.
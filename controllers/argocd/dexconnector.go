@@ -0,0 +1,370 @@
+// Copyright 2023 ArgoCD Operator Developers
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package argocd
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+
+	argoproj "github.com/argoproj-labs/argocd-operator/api/v1beta1"
+	"github.com/argoproj-labs/argocd-operator/controllers/argoutil"
+)
+
+// dexConnectorSecretKeyPrefix is the prefix of the keys of the Argo CD Secret holding the secrets of the typed Dex
+// connectors.
+const dexConnectorSecretKeyPrefix = "dex.connector."
+
+// dexConnectorSecretRef describes a secret of a typed Dex connector, read from a key of a Secret.
+type dexConnectorSecretRef struct {
+	// field is the name of the field of the connector configuration referencing the secret.
+	field string
+	// selector selects the key of the Secret containing the secret.
+	selector *corev1.SecretKeySelector
+	// encode is true if the secret is base64 encoded in the connector configuration.
+	encode bool
+}
+
+// getDexConnectors returns the typed Dex connectors of the given ArgoCD.
+func getDexConnectors(cr *argoproj.ArgoCD) []argoproj.ArgoCDDexConnector {
+	if cr.Spec.SSO == nil || cr.Spec.SSO.Dex == nil {
+		return nil
+	}
+	return cr.Spec.SSO.Dex.Connectors
+}
+
+// getDexConnectorSecretKey returns the key of the Argo CD Secret holding the given secret of the given connector.
+func getDexConnectorSecretKey(id, field string) string {
+	return fmt.Sprintf("%s%s.%s", dexConnectorSecretKeyPrefix, id, field)
+}
+
+// getDexConnectorSecretRefs returns the secrets referenced by the given connector.
+func getDexConnectorSecretRefs(c argoproj.ArgoCDDexConnector) []dexConnectorSecretRef {
+	refs := []dexConnectorSecretRef{}
+	switch {
+	case c.GitHub != nil:
+		refs = append(refs, dexConnectorSecretRef{field: "clientSecret", selector: &c.GitHub.ClientSecret})
+	case c.GitLab != nil:
+		refs = append(refs, dexConnectorSecretRef{field: "clientSecret", selector: &c.GitLab.ClientSecret})
+	case c.LDAP != nil:
+		if c.LDAP.RootCA != nil {
+			refs = append(refs, dexConnectorSecretRef{field: "rootCAData", selector: c.LDAP.RootCA, encode: true})
+		}
+		if c.LDAP.BindPW != nil {
+			refs = append(refs, dexConnectorSecretRef{field: "bindPW", selector: c.LDAP.BindPW})
+		}
+	case c.OIDC != nil:
+		refs = append(refs, dexConnectorSecretRef{field: "clientSecret", selector: &c.OIDC.ClientSecret})
+	case c.SAML != nil:
+		if c.SAML.CA != nil {
+			refs = append(refs, dexConnectorSecretRef{field: "caData", selector: c.SAML.CA, encode: true})
+		}
+	case c.Microsoft != nil:
+		refs = append(refs, dexConnectorSecretRef{field: "clientSecret", selector: &c.Microsoft.ClientSecret})
+	}
+	return refs
+}
+
+// getDexConnectorSecretNames returns the names of the Secrets referenced by the typed Dex connectors of the given
+// ArgoCD.
+func getDexConnectorSecretNames(cr *argoproj.ArgoCD) []string {
+	names := []string{}
+	for _, c := range getDexConnectors(cr) {
+		for _, ref := range getDexConnectorSecretRefs(c) {
+			names = append(names, ref.selector.Name)
+		}
+	}
+	return names
+}
+
+// countDexConnectorTypes returns the number of connector types set on the given connector.
+func countDexConnectorTypes(c argoproj.ArgoCDDexConnector) int {
+	count := 0
+	for _, set := range []bool{c.GitHub != nil, c.GitLab != nil, c.LDAP != nil, c.OIDC != nil, c.SAML != nil, c.Microsoft != nil} {
+		if set {
+			count++
+		}
+	}
+	return count
+}
+
+// validateDexConnectors returns an error if one of the given typed Dex connectors is invalid.
+func validateDexConnectors(connectors []argoproj.ArgoCDDexConnector) error {
+	ids := map[string]bool{}
+	for _, c := range connectors {
+		if len(c.ID) == 0 {
+			return fmt.Errorf("the id of the dex connectors must be set")
+		}
+		if ids[c.ID] {
+			return fmt.Errorf("the id of the dex connector %s is not unique", c.ID)
+		}
+		ids[c.ID] = true
+
+		if countDexConnectorTypes(c) != 1 {
+			return fmt.Errorf("exactly one connector type must be set for the dex connector %s", c.ID)
+		}
+
+		missing := []string{}
+		required := func(field, value string) {
+			if len(value) == 0 {
+				missing = append(missing, field)
+			}
+		}
+		switch {
+		case c.GitHub != nil:
+			required("clientID", c.GitHub.ClientID)
+		case c.GitLab != nil:
+			required("clientID", c.GitLab.ClientID)
+		case c.LDAP != nil:
+			required("host", c.LDAP.Host)
+			required("userSearch.baseDN", c.LDAP.UserSearch.BaseDN)
+			required("userSearch.username", c.LDAP.UserSearch.Username)
+			if c.LDAP.GroupSearch != nil {
+				required("groupSearch.baseDN", c.LDAP.GroupSearch.BaseDN)
+				required("groupSearch.nameAttr", c.LDAP.GroupSearch.NameAttr)
+				if len(c.LDAP.GroupSearch.UserMatchers) == 0 {
+					missing = append(missing, "groupSearch.userMatchers")
+				}
+			}
+		case c.OIDC != nil:
+			required("issuer", c.OIDC.Issuer)
+			required("clientID", c.OIDC.ClientID)
+		case c.SAML != nil:
+			required("ssoURL", c.SAML.SSOURL)
+			required("usernameAttr", c.SAML.UsernameAttr)
+			required("emailAttr", c.SAML.EmailAttr)
+			if c.SAML.CA == nil && !c.SAML.InsecureSkipSignatureValidation {
+				missing = append(missing, "ca")
+			}
+		case c.Microsoft != nil:
+			required("clientID", c.Microsoft.ClientID)
+		}
+		for _, ref := range getDexConnectorSecretRefs(c) {
+			required(ref.field+".name", ref.selector.Name)
+			required(ref.field+".key", ref.selector.Key)
+		}
+
+		if len(missing) > 0 {
+			return fmt.Errorf("the dex connector %s is missing the required fields %s", c.ID, strings.Join(missing, ", "))
+		}
+	}
+	return nil
+}
+
+// setDexConnectorOption sets the given option of a connector configuration when it is not empty.
+func setDexConnectorOption(config map[string]interface{}, key string, value interface{}) {
+	switch v := value.(type) {
+	case string:
+		if len(v) == 0 {
+			return
+		}
+	case bool:
+		if !v {
+			return
+		}
+	case []string:
+		if len(v) == 0 {
+			return
+		}
+	}
+	config[key] = value
+}
+
+// newDexConnector returns the Dex configuration of the given typed connector. Its secrets reference the keys of the
+// Argo CD Secret they are copied to, and are replaced by Argo CD when rendering the configuration of Dex.
+func newDexConnector(c argoproj.ArgoCDDexConnector) DexConnector {
+	config := map[string]interface{}{}
+	connector := DexConnector{ID: c.ID, Name: c.Name, Config: config}
+	if len(connector.Name) == 0 {
+		connector.Name = c.ID
+	}
+
+	switch {
+	case c.GitHub != nil:
+		connector.Type = "github"
+		config["clientID"] = c.GitHub.ClientID
+		orgs := []map[string]interface{}{}
+		for _, org := range c.GitHub.Orgs {
+			o := map[string]interface{}{"name": org.Name}
+			setDexConnectorOption(o, "teams", org.Teams)
+			orgs = append(orgs, o)
+		}
+		if len(orgs) > 0 {
+			config["orgs"] = orgs
+		}
+		setDexConnectorOption(config, "hostName", c.GitHub.HostName)
+		setDexConnectorOption(config, "loadAllGroups", c.GitHub.LoadAllGroups)
+	case c.GitLab != nil:
+		connector.Type = "gitlab"
+		config["clientID"] = c.GitLab.ClientID
+		setDexConnectorOption(config, "baseURL", c.GitLab.BaseURL)
+		setDexConnectorOption(config, "groups", c.GitLab.Groups)
+		setDexConnectorOption(config, "useLoginAsID", c.GitLab.UseLoginAsID)
+	case c.LDAP != nil:
+		connector.Type = "ldap"
+		config["host"] = c.LDAP.Host
+		setDexConnectorOption(config, "insecureNoSSL", c.LDAP.InsecureNoSSL)
+		setDexConnectorOption(config, "insecureSkipVerify", c.LDAP.InsecureSkipVerify)
+		setDexConnectorOption(config, "startTLS", c.LDAP.StartTLS)
+		setDexConnectorOption(config, "bindDN", c.LDAP.BindDN)
+		setDexConnectorOption(config, "usernamePrompt", c.LDAP.UsernamePrompt)
+		userSearch := map[string]interface{}{
+			"baseDN":   c.LDAP.UserSearch.BaseDN,
+			"username": c.LDAP.UserSearch.Username,
+		}
+		setDexConnectorOption(userSearch, "filter", c.LDAP.UserSearch.Filter)
+		setDexConnectorOption(userSearch, "idAttr", c.LDAP.UserSearch.IDAttr)
+		setDexConnectorOption(userSearch, "emailAttr", c.LDAP.UserSearch.EmailAttr)
+		setDexConnectorOption(userSearch, "nameAttr", c.LDAP.UserSearch.NameAttr)
+		config["userSearch"] = userSearch
+		if c.LDAP.GroupSearch != nil {
+			userMatchers := []map[string]interface{}{}
+			for _, m := range c.LDAP.GroupSearch.UserMatchers {
+				userMatchers = append(userMatchers, map[string]interface{}{"userAttr": m.UserAttr, "groupAttr": m.GroupAttr})
+			}
+			groupSearch := map[string]interface{}{
+				"baseDN":       c.LDAP.GroupSearch.BaseDN,
+				"userMatchers": userMatchers,
+				"nameAttr":     c.LDAP.GroupSearch.NameAttr,
+			}
+			setDexConnectorOption(groupSearch, "filter", c.LDAP.GroupSearch.Filter)
+			config["groupSearch"] = groupSearch
+		}
+	case c.OIDC != nil:
+		connector.Type = "oidc"
+		config["issuer"] = c.OIDC.Issuer
+		config["clientID"] = c.OIDC.ClientID
+		setDexConnectorOption(config, "scopes", c.OIDC.Scopes)
+		setDexConnectorOption(config, "getUserInfo", c.OIDC.GetUserInfo)
+		setDexConnectorOption(config, "insecureSkipEmailVerified", c.OIDC.InsecureSkipEmailVerified)
+		setDexConnectorOption(config, "insecureEnableGroups", c.OIDC.InsecureEnableGroups)
+	case c.SAML != nil:
+		connector.Type = "saml"
+		config["ssoURL"] = c.SAML.SSOURL
+		config["usernameAttr"] = c.SAML.UsernameAttr
+		config["emailAttr"] = c.SAML.EmailAttr
+		setDexConnectorOption(config, "insecureSkipSignatureValidation", c.SAML.InsecureSkipSignatureValidation)
+		setDexConnectorOption(config, "entityIssuer", c.SAML.EntityIssuer)
+		setDexConnectorOption(config, "ssoIssuer", c.SAML.SSOIssuer)
+		setDexConnectorOption(config, "groupsAttr", c.SAML.GroupsAttr)
+		setDexConnectorOption(config, "nameIDPolicyFormat", c.SAML.NameIDPolicyFormat)
+	case c.Microsoft != nil:
+		connector.Type = "microsoft"
+		config["clientID"] = c.Microsoft.ClientID
+		setDexConnectorOption(config, "tenant", c.Microsoft.Tenant)
+		setDexConnectorOption(config, "groups", c.Microsoft.Groups)
+		setDexConnectorOption(config, "onlySecurityGroups", c.Microsoft.OnlySecurityGroups)
+	}
+
+	for _, ref := range getDexConnectorSecretRefs(c) {
+		config[ref.field] = "$" + getDexConnectorSecretKey(c.ID, ref.field)
+	}
+	return connector
+}
+
+// renderDexConnectors returns the given Dex configuration, with the given typed connectors appended to its connectors.
+// The configuration is returned unchanged when there are no typed connectors.
+func renderDexConnectors(config string, connectors []argoproj.ArgoCDDexConnector) (string, error) {
+	if len(connectors) == 0 {
+		return config, nil
+	}
+	if err := validateDexConnectors(connectors); err != nil {
+		return "", err
+	}
+
+	dex := yaml.MapSlice{}
+	if err := yaml.Unmarshal([]byte(config), &dex); err != nil {
+		return "", fmt.Errorf("failed to parse the dex configuration: %w", err)
+	}
+
+	rendered := []interface{}{}
+	for _, c := range connectors {
+		rendered = append(rendered, newDexConnector(c))
+	}
+
+	found := false
+	for i, item := range dex {
+		if item.Key != "connectors" {
+			continue
+		}
+		existing, ok := item.Value.([]interface{})
+		if !ok && item.Value != nil {
+			return "", fmt.Errorf("the connectors of the dex configuration must be a list")
+		}
+		dex[i].Value = append(existing, rendered...)
+		found = true
+	}
+	if !found {
+		dex = append(dex, yaml.MapItem{Key: "connectors", Value: rendered})
+	}
+
+	out, err := yaml.Marshal(dex)
+	return string(out), err
+}
+
+// getDexConnectorSecretData returns the secrets of the typed Dex connectors of the given ArgoCD, by key of the Argo CD
+// Secret. The secrets of optional references to missing Secrets or keys are left out.
+func (r *ReconcileArgoCD) getDexConnectorSecretData(cr *argoproj.ArgoCD) (map[string][]byte, error) {
+	data := map[string][]byte{}
+	for _, c := range getDexConnectors(cr) {
+		for _, ref := range getDexConnectorSecretRefs(c) {
+			optional := ref.selector.Optional != nil && *ref.selector.Optional
+			secret := argoutil.NewSecretWithName(cr, ref.selector.Name)
+			if err := argoutil.FetchObject(r.Client, cr.Namespace, ref.selector.Name, secret); err != nil {
+				if errors.IsNotFound(err) && optional {
+					continue
+				}
+				return nil, fmt.Errorf("failed to get the secret %s of the dex connector %s: %w", ref.selector.Name, c.ID, err)
+			}
+
+			value, ok := secret.Data[ref.selector.Key]
+			if !ok {
+				if optional {
+					continue
+				}
+				return nil, fmt.Errorf("the secret %s of the dex connector %s has no key %s", ref.selector.Name, c.ID, ref.selector.Key)
+			}
+			if ref.encode {
+				value = []byte(base64.StdEncoding.EncodeToString(value))
+			}
+			data[getDexConnectorSecretKey(c.ID, ref.field)] = value
+		}
+	}
+	return data, nil
+}
+
+// updateDexConnectorSecretData sets the given secrets of the typed Dex connectors on the given Argo CD Secret, and
+// removes the ones of the connectors no longer present. It returns true if the Secret was changed.
+func updateDexConnectorSecretData(secret *corev1.Secret, data map[string][]byte) bool {
+	changed := false
+	for key := range secret.Data {
+		if _, ok := data[key]; strings.HasPrefix(key, dexConnectorSecretKeyPrefix) && !ok {
+			delete(secret.Data, key)
+			changed = true
+		}
+	}
+	for key, value := range data {
+		if actual, ok := secret.Data[key]; !ok || !bytes.Equal(actual, value) {
+			secret.Data[key] = value
+			changed = true
+		}
+	}
+	return changed
+}
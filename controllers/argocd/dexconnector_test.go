@@ -0,0 +1,220 @@
+package argocd
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v2"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	argoproj "github.com/argoproj-labs/argocd-operator/api/v1beta1"
+	"github.com/argoproj-labs/argocd-operator/common"
+	"github.com/argoproj-labs/argocd-operator/controllers/argoutil"
+)
+
+func testSecretKeySelector(name, key string) corev1.SecretKeySelector {
+	return corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: name}, Key: key}
+}
+
+func withDexConnectors(connectors ...argoproj.ArgoCDDexConnector) argoCDOpt {
+	return func(a *argoproj.ArgoCD) {
+		a.Spec.SSO = &argoproj.ArgoCDSSOSpec{
+			Provider: argoproj.SSOProviderTypeDex,
+			Dex:      &argoproj.ArgoCDDexSpec{Connectors: connectors},
+		}
+	}
+}
+
+func testGitHubConnector() argoproj.ArgoCDDexConnector {
+	return argoproj.ArgoCDDexConnector{
+		ID: "github",
+		GitHub: &argoproj.ArgoCDDexGitHubConnector{
+			ClientID:     "github-client",
+			ClientSecret: testSecretKeySelector("github-oauth", "clientSecret"),
+			Orgs:         []argoproj.ArgoCDDexGitHubOrg{{Name: "argoproj", Teams: []string{"maintainers"}}},
+		},
+	}
+}
+
+func testLDAPConnector() argoproj.ArgoCDDexConnector {
+	bindPW := testSecretKeySelector("ldap-bind", "password")
+	return argoproj.ArgoCDDexConnector{
+		ID:   "ldap",
+		Name: "Corporate LDAP",
+		LDAP: &argoproj.ArgoCDDexLDAPConnector{
+			Host:   "ldap.example.com:636",
+			BindDN: "cn=argocd,dc=example,dc=com",
+			BindPW: &bindPW,
+			UserSearch: argoproj.ArgoCDDexLDAPUserSearch{
+				BaseDN:   "ou=users,dc=example,dc=com",
+				Username: "uid",
+			},
+		},
+	}
+}
+
+func TestRenderDexConnectors(t *testing.T) {
+	config := "logger:\n  level: debug\nconnectors:\n- type: mock\n  id: mock\n  name: Mock\n"
+	rendered, err := renderDexConnectors(config, []argoproj.ArgoCDDexConnector{testGitHubConnector(), testLDAPConnector()})
+	assert.NoError(t, err)
+
+	dex := map[string]interface{}{}
+	assert.NoError(t, yaml.Unmarshal([]byte(rendered), &dex))
+	assert.Equal(t, map[interface{}]interface{}{"level": "debug"}, dex["logger"])
+
+	connectors := dex["connectors"].([]interface{})
+	assert.Len(t, connectors, 3)
+	assert.Equal(t, "mock", connectors[0].(map[interface{}]interface{})["id"])
+
+	github := connectors[1].(map[interface{}]interface{})
+	assert.Equal(t, "github", github["type"])
+	assert.Equal(t, "github", github["name"])
+	assert.Equal(t, map[interface{}]interface{}{
+		"clientID":     "github-client",
+		"clientSecret": "$dex.connector.github.clientSecret",
+		"orgs":         []interface{}{map[interface{}]interface{}{"name": "argoproj", "teams": []interface{}{"maintainers"}}},
+	}, github["config"])
+
+	ldap := connectors[2].(map[interface{}]interface{})
+	assert.Equal(t, "ldap", ldap["type"])
+	assert.Equal(t, "Corporate LDAP", ldap["name"])
+	ldapConfig := ldap["config"].(map[interface{}]interface{})
+	assert.Equal(t, "$dex.connector.ldap.bindPW", ldapConfig["bindPW"])
+	assert.Equal(t, map[interface{}]interface{}{"baseDN": "ou=users,dc=example,dc=com", "username": "uid"}, ldapConfig["userSearch"])
+
+	// the configuration is left untouched without typed connectors
+	rendered, err = renderDexConnectors(config, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, config, rendered)
+}
+
+func TestValidateDexConnectors(t *testing.T) {
+	tests := []struct {
+		name       string
+		connectors []argoproj.ArgoCDDexConnector
+		wantErr    string
+	}{
+		{"valid connectors", []argoproj.ArgoCDDexConnector{testGitHubConnector(), testLDAPConnector()}, ""},
+		{"duplicate id", []argoproj.ArgoCDDexConnector{testGitHubConnector(), testGitHubConnector()},
+			"the id of the dex connector github is not unique"},
+		{"no connector type", []argoproj.ArgoCDDexConnector{{ID: "none"}},
+			"exactly one connector type must be set for the dex connector none"},
+		{"several connector types", []argoproj.ArgoCDDexConnector{{
+			ID:     "both",
+			GitHub: testGitHubConnector().GitHub,
+			LDAP:   testLDAPConnector().LDAP,
+		}}, "exactly one connector type must be set for the dex connector both"},
+		{"missing fields", []argoproj.ArgoCDDexConnector{{
+			ID:   "oidc",
+			OIDC: &argoproj.ArgoCDDexOIDCConnector{ClientID: "client"},
+		}}, "the dex connector oidc is missing the required fields issuer, clientSecret.name, clientSecret.key"},
+		{"saml without ca", []argoproj.ArgoCDDexConnector{{
+			ID:   "saml",
+			SAML: &argoproj.ArgoCDDexSAMLConnector{SSOURL: "https://idp.example.com/sso", UsernameAttr: "name", EmailAttr: "email"},
+		}}, "the dex connector saml is missing the required fields ca"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := validateDexConnectors(test.connectors)
+			if test.wantErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, test.wantErr)
+			}
+		})
+	}
+}
+
+func TestReconcileArgoCD_reconcileArgoSecret_dexConnectors(t *testing.T) {
+	logf.SetLogger(ZapLogger(true))
+	a := makeTestArgoCD(withDexConnectors(testGitHubConnector()))
+
+	clusterSecret := argoutil.NewSecretWithSuffix(a, "cluster")
+	clusterSecret.Data = map[string][]byte{common.ArgoCDKeyAdminPassword: []byte("something")}
+	tlsSecret := argoutil.NewSecretWithSuffix(a, "tls")
+	tokenSecret := argoutil.NewSecretWithName(a, "argocd-dex-server-token")
+	tokenSecret.Data = map[string][]byte{"token": []byte("token")}
+	sa := newServiceAccountWithName(common.ArgoCDDefaultDexServiceAccountName, a)
+	sa.Secrets = []corev1.ObjectReference{{Name: tokenSecret.Name}}
+	githubSecret := argoutil.NewSecretWithName(a, "github-oauth")
+	githubSecret.Data = map[string][]byte{"clientSecret": []byte("s3cr3t")}
+	dexDeployment := newDeploymentWithSuffix("dex-server", "dex-server", a)
+	dexDeployment.Spec.Template.Labels = map[string]string{common.ArgoCDKeyName: dexDeployment.Name}
+
+	resObjs := []client.Object{a, clusterSecret, tlsSecret, tokenSecret, sa, githubSecret, dexDeployment}
+	sch := makeTestReconcilerScheme(argoproj.AddToScheme)
+	cl := makeTestReconcilerClient(sch, resObjs, resObjs, []runtime.Object{})
+	r := makeTestReconciler(cl, sch)
+
+	assert.NoError(t, r.reconcileArgoSecret(a))
+
+	secret := &corev1.Secret{}
+	key := types.NamespacedName{Name: common.ArgoCDSecretName, Namespace: testNamespace}
+	assert.NoError(t, r.Client.Get(context.TODO(), key, secret))
+	assert.Equal(t, "s3cr3t", string(secret.Data["dex.connector.github.clientSecret"]))
+
+	// the rotated secret is copied, and Dex is rolled out
+	githubSecret.Data["clientSecret"] = []byte("r0tated")
+	assert.NoError(t, r.Client.Update(context.TODO(), githubSecret))
+	assert.NoError(t, r.reconcileArgoSecret(a))
+
+	assert.NoError(t, r.Client.Get(context.TODO(), key, secret))
+	assert.Equal(t, "r0tated", string(secret.Data["dex.connector.github.clientSecret"]))
+	deployment := &appsv1.Deployment{}
+	assert.NoError(t, r.Client.Get(context.TODO(), client.ObjectKeyFromObject(dexDeployment), deployment))
+	assert.Contains(t, deployment.Spec.Template.Labels, "dex.config.changed")
+
+	// the secrets of the removed connectors are removed
+	a.Spec.SSO.Dex.Connectors = []argoproj.ArgoCDDexConnector{testLDAPConnector()}
+	a.Spec.SSO.Dex.Connectors[0].LDAP.BindPW.Optional = boolPtr(true)
+	assert.NoError(t, r.reconcileArgoSecret(a))
+
+	assert.NoError(t, r.Client.Get(context.TODO(), key, secret))
+	assert.NotContains(t, secret.Data, "dex.connector.github.clientSecret")
+	assert.NotContains(t, secret.Data, "dex.connector.ldap.bindPW")
+
+	// a missing secret that is not optional is an error
+	a.Spec.SSO.Dex.Connectors[0].LDAP.BindPW.Optional = nil
+	assert.EqualError(t, r.reconcileArgoSecret(a), "failed to get the secret ldap-bind of the dex connector ldap: secrets \"ldap-bind\" not found")
+}
+
+func TestReconcileArgoCD_dexConnectorSecretMapper(t *testing.T) {
+	a := makeTestArgoCD(withDexConnectors(testGitHubConnector()))
+	other := makeTestArgoCD(func(a *argoproj.ArgoCD) {
+		a.Name = "other"
+	})
+
+	resObjs := []client.Object{a, other}
+	sch := makeTestReconcilerScheme(argoproj.AddToScheme)
+	cl := makeTestReconcilerClient(sch, resObjs, resObjs, []runtime.Object{})
+	r := makeTestReconciler(cl, sch)
+
+	referenced := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "github-oauth", Namespace: testNamespace}}
+	assert.Equal(t, []reconcile.Request{{NamespacedName: types.NamespacedName{Name: a.Name, Namespace: testNamespace}}},
+		r.dexConnectorSecretMapper(context.TODO(), referenced))
+
+	unrelated := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "unrelated", Namespace: testNamespace}}
+	assert.Empty(t, r.dexConnectorSecretMapper(context.TODO(), unrelated))
+}
+
+func TestReconcileArgoCD_reconcileSSO_invalidDexConnectors(t *testing.T) {
+	logf.SetLogger(ZapLogger(true))
+	a := makeTestArgoCD(withDexConnectors(argoproj.ArgoCDDexConnector{ID: "none"}))
+
+	resObjs := []client.Object{a}
+	sch := makeTestReconcilerScheme(argoproj.AddToScheme)
+	cl := makeTestReconcilerClient(sch, resObjs, resObjs, []runtime.Object{})
+	r := makeTestReconciler(cl, sch)
+
+	_, err := r.reconcileSSO(a)
+	assert.EqualError(t, err, illegalSSOConfiguration+"exactly one connector type must be set for the dex connector none")
+	assert.Equal(t, "Failed", a.Status.SSO)
+}
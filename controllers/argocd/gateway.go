@@ -0,0 +1,382 @@
+// Copyright 2023 ArgoCD Operator Developers
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package argocd
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	argoproj "github.com/argoproj-labs/argocd-operator/api/v1beta1"
+	"github.com/argoproj-labs/argocd-operator/common"
+	"github.com/argoproj-labs/argocd-operator/controllers/argoutil"
+)
+
+const (
+	// gatewayAPIGroup is the API group of the Kubernetes Gateway API.
+	gatewayAPIGroup = "gateway.networking.k8s.io"
+
+	// gatewayAPIVersion is the version of the Kubernetes Gateway API used for the routes.
+	gatewayAPIVersion = "v1"
+
+	// gatewayRouteAcceptedCondition is the condition set by a Gateway on the routes it accepted.
+	gatewayRouteAcceptedCondition = "Accepted"
+)
+
+var (
+	gatewayGVK   = schema.GroupVersionKind{Group: gatewayAPIGroup, Version: gatewayAPIVersion, Kind: "Gateway"}
+	httpRouteGVK = schema.GroupVersionKind{Group: gatewayAPIGroup, Version: gatewayAPIVersion, Kind: "HTTPRoute"}
+	grpcRouteGVK = schema.GroupVersionKind{Group: gatewayAPIGroup, Version: gatewayAPIVersion, Kind: "GRPCRoute"}
+)
+
+var gatewayAPIFound = false
+
+var grpcRouteAPIFound = false
+
+// IsGatewayAPIAvailable returns true if the Gateway API HTTPRoute is present.
+func IsGatewayAPIAvailable() bool {
+	return gatewayAPIFound
+}
+
+// IsGRPCRouteAPIAvailable returns true if the Gateway API GRPCRoute is present.
+func IsGRPCRouteAPIAvailable() bool {
+	return grpcRouteAPIFound
+}
+
+// verifyGatewayAPI will verify that the Gateway API HTTPRoute and GRPCRoute are present. The GRPCRoute is only
+// served as v1 by the most recent releases of the Gateway API, so it is verified on its own.
+func verifyGatewayAPI() error {
+	found, err := argoutil.VerifyAPIKind(gatewayAPIGroup, gatewayAPIVersion, httpRouteGVK.Kind)
+	if err != nil {
+		return err
+	}
+	gatewayAPIFound = found
+
+	found, err = argoutil.VerifyAPIKind(gatewayAPIGroup, gatewayAPIVersion, grpcRouteGVK.Kind)
+	if err != nil {
+		return err
+	}
+	grpcRouteAPIFound = found
+	return nil
+}
+
+// newGatewayObject returns a new, empty Gateway API object of the given kind, with the given name and namespace.
+func newGatewayObject(gvk schema.GroupVersionKind, name, namespace string) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{}
+	obj.SetGroupVersionKind(gvk)
+	obj.SetName(name)
+	obj.SetNamespace(namespace)
+	return obj
+}
+
+// newGatewayRoute returns a new Gateway API route of the given kind, with the given name suffix, for the given
+// ArgoCD and gateway options.
+func newGatewayRoute(gvk schema.GroupVersionKind, suffix string, cr *argoproj.ArgoCD, gateway argoproj.ArgoCDGatewaySpec) *unstructured.Unstructured {
+	route := newGatewayObject(gvk, nameWithSuffix(suffix, cr), cr.Namespace)
+
+	labels := argoutil.LabelsForCluster(cr)
+	labels[common.ArgoCDKeyName] = route.GetName()
+	route.SetLabels(argoutil.AppendStringMap(labels, gateway.Labels))
+
+	if len(gateway.Annotations) > 0 {
+		route.SetAnnotations(argoutil.AppendStringMap(nil, gateway.Annotations))
+	}
+	return route
+}
+
+// gatewayRouteSpec returns the spec of a Gateway API route attached to the Gateway of the given options, for the
+// given host and rule. The fields defaulted by the Gateway API are set, so that they are not reported as changes.
+func gatewayRouteSpec(cr *argoproj.ArgoCD, gateway argoproj.ArgoCDGatewaySpec, host string, rule map[string]interface{}) map[string]interface{} {
+	namespace := gateway.GatewayNamespace
+	if len(namespace) == 0 {
+		namespace = cr.Namespace
+	}
+
+	parentRef := map[string]interface{}{
+		"group":     gatewayAPIGroup,
+		"kind":      gatewayGVK.Kind,
+		"name":      gateway.GatewayName,
+		"namespace": namespace,
+	}
+	if len(gateway.SectionName) > 0 {
+		parentRef["sectionName"] = gateway.SectionName
+	}
+
+	spec := map[string]interface{}{
+		"parentRefs": []interface{}{parentRef},
+		"rules":      []interface{}{rule},
+	}
+	if len(host) > 0 {
+		spec["hostnames"] = []interface{}{host}
+	}
+	return spec
+}
+
+// gatewayBackendRefs returns the backend references of a Gateway API route rule to the given port of the given
+// Service.
+func gatewayBackendRefs(service string, port int64) []interface{} {
+	return []interface{}{
+		map[string]interface{}{
+			"group":  "",
+			"kind":   "Service",
+			"name":   service,
+			"port":   port,
+			"weight": int64(1),
+		},
+	}
+}
+
+// gatewayPathRule returns a Gateway API HTTPRoute rule forwarding the requests matching the given path prefix to the
+// given port of the given Service.
+func gatewayPathRule(path, service string, port int64) map[string]interface{} {
+	return map[string]interface{}{
+		"matches": []interface{}{
+			map[string]interface{}{
+				"path": map[string]interface{}{
+					"type":  "PathPrefix",
+					"value": path,
+				},
+			},
+		},
+		"backendRefs": gatewayBackendRefs(service, port),
+	}
+}
+
+// getArgoServerGatewayPort returns the port of the Argo CD Server Service the Gateway API routes forward to, the
+// plain HTTP port when the server runs in insecure mode as TLS is then terminated by the Gateway.
+func getArgoServerGatewayPort(cr *argoproj.ArgoCD) int64 {
+	if cr.Spec.Server.Insecure {
+		return 80
+	}
+	return 443
+}
+
+// newServerHTTPRoute returns the desired Argo CD Server HTTPRoute for the given ArgoCD.
+func newServerHTTPRoute(cr *argoproj.ArgoCD) *unstructured.Unstructured {
+	gateway := cr.Spec.Server.Gateway
+	route := newGatewayRoute(httpRouteGVK, "server", cr, gateway)
+	rule := gatewayPathRule(getPathOrDefault(gateway.Path), nameWithSuffix("server", cr), getArgoServerGatewayPort(cr))
+	route.Object["spec"] = gatewayRouteSpec(cr, gateway, cr.Spec.Server.Host, rule)
+	return route
+}
+
+// newServerGRPCRoute returns the desired Argo CD Server GRPCRoute for the given ArgoCD.
+func newServerGRPCRoute(cr *argoproj.ArgoCD) *unstructured.Unstructured {
+	gateway := cr.Spec.Server.GRPC.Gateway
+	route := newGatewayRoute(grpcRouteGVK, "grpc", cr, gateway)
+	rule := map[string]interface{}{
+		"backendRefs": gatewayBackendRefs(nameWithSuffix("server", cr), getArgoServerGatewayPort(cr)),
+	}
+	route.Object["spec"] = gatewayRouteSpec(cr, gateway, cr.Spec.Server.GRPC.Host, rule)
+	return route
+}
+
+// newApplicationSetControllerWebhookHTTPRoute returns the desired ApplicationSetController webhook HTTPRoute for the
+// given ArgoCD.
+func newApplicationSetControllerWebhookHTTPRoute(cr *argoproj.ArgoCD) *unstructured.Unstructured {
+	suffix := fmt.Sprintf("%s-%s", common.ApplicationSetServiceNameSuffix, "webhook")
+	if cr.Spec.ApplicationSet == nil {
+		return newGatewayRoute(httpRouteGVK, suffix, cr, argoproj.ArgoCDGatewaySpec{})
+	}
+
+	webhookServer := cr.Spec.ApplicationSet.WebhookServer
+	route := newGatewayRoute(httpRouteGVK, suffix, cr, webhookServer.Gateway)
+	path := "/api/webhook"
+	if len(webhookServer.Gateway.Path) > 0 {
+		path = webhookServer.Gateway.Path
+	}
+	rule := gatewayPathRule(path, nameWithSuffix(common.ApplicationSetServiceNameSuffix, cr), 7000)
+	route.Object["spec"] = gatewayRouteSpec(cr, webhookServer.Gateway, webhookServer.Host, rule)
+	return route
+}
+
+// reconcileGatewayRoutes will ensure that all ArgoCD Gateway API routes are present.
+func (r *ReconcileArgoCD) reconcileGatewayRoutes(cr *argoproj.ArgoCD) error {
+	if err := r.reconcileGatewayRoute(cr, newServerHTTPRoute(cr), cr.Spec.Server.Gateway); err != nil {
+		return err
+	}
+
+	if IsGRPCRouteAPIAvailable() {
+		if err := r.reconcileGatewayRoute(cr, newServerGRPCRoute(cr), cr.Spec.Server.GRPC.Gateway); err != nil {
+			return err
+		}
+	} else if cr.Spec.Server.GRPC.Gateway.Enabled {
+		log.Info("the GRPCRoute API is not available, the argocd-server GRPC gateway is ignored", "namespace", cr.Namespace, "name", cr.Name)
+	}
+
+	webhookGateway := argoproj.ArgoCDGatewaySpec{}
+	if cr.Spec.ApplicationSet != nil {
+		webhookGateway = cr.Spec.ApplicationSet.WebhookServer.Gateway
+	}
+	return r.reconcileGatewayRoute(cr, newApplicationSetControllerWebhookHTTPRoute(cr), webhookGateway)
+}
+
+// reconcileGatewayRoute will ensure that the given desired Gateway API route is present when enabled by the given
+// gateway options, and absent otherwise. The annotations, labels and spec of an existing route are updated to match
+// the desired route, reverting any manual change, which is recorded as an event of the given ArgoCD. Annotations and
+// labels added by others are kept, while the ones no longer desired are removed.
+func (r *ReconcileArgoCD) reconcileGatewayRoute(cr *argoproj.ArgoCD, desired *unstructured.Unstructured, gateway argoproj.ArgoCDGatewaySpec) error {
+	if gateway.Enabled && len(gateway.GatewayName) == 0 {
+		return fmt.Errorf("the gateway name of %s %s must be set", desired.GetKind(), desired.GetName())
+	}
+
+	existing := newGatewayObject(desired.GroupVersionKind(), desired.GetName(), desired.GetNamespace())
+	if argoutil.IsObjectFound(r.Client, desired.GetNamespace(), desired.GetName(), existing) {
+		if !gateway.Enabled {
+			// Route exists but enabled flag has been set to false, delete the route
+			log.Info("deleting gateway route", "kind", existing.GetKind(), "name", existing.GetName(), "namespace", existing.GetNamespace())
+			return r.Client.Delete(context.TODO(), existing)
+		}
+
+		changed := mergeDesiredMetadata(existing, desired)
+		if !gatewayRouteSpecMatches(desired.Object["spec"], existing.Object["spec"]) {
+			existing.Object["spec"] = runtime.DeepCopyJSONValue(desired.Object["spec"])
+			changed = true
+		}
+		if !changed {
+			return nil // Route found and up to date, do nothing
+		}
+
+		log.Info("updating gateway route to match the ArgoCD spec", "kind", existing.GetKind(), "name", existing.GetName(), "namespace", existing.GetNamespace())
+		if err := r.Client.Update(context.TODO(), existing); err != nil {
+			return err
+		}
+		r.createDriftEvent(cr, existing.GetKind(), existing.GetName())
+		return nil
+	}
+
+	if !gateway.Enabled {
+		return nil // Route not enabled, move along...
+	}
+
+	route := desired.DeepCopy()
+	setManagedMetadata(route)
+	if err := controllerutil.SetControllerReference(cr, route, r.Scheme); err != nil {
+		return err
+	}
+	log.Info("creating gateway route", "kind", route.GetKind(), "name", route.GetName(), "namespace", route.GetNamespace())
+	return r.Client.Create(context.TODO(), route)
+}
+
+// gatewayRouteSpecMatches returns true if the given existing route spec matches the given desired spec. The fields
+// of the existing spec that are not in the desired spec, such as the ones defaulted by the Gateway API, are ignored,
+// except at the top level of the spec, so that hostnames added to the route are reverted.
+func gatewayRouteSpecMatches(desired, existing interface{}) bool {
+	desiredSpec, ok := desired.(map[string]interface{})
+	if !ok {
+		return false
+	}
+	existingSpec, ok := existing.(map[string]interface{})
+	if !ok || len(existingSpec) != len(desiredSpec) {
+		return false
+	}
+	return gatewayValueMatches(desiredSpec, existingSpec)
+}
+
+// gatewayValueMatches returns true if the given existing value holds all the fields of the given desired value.
+// Lists must have the same length, and their items match one by one.
+func gatewayValueMatches(desired, existing interface{}) bool {
+	switch d := desired.(type) {
+	case map[string]interface{}:
+		e, ok := existing.(map[string]interface{})
+		if !ok {
+			return false
+		}
+		for key, val := range d {
+			if !gatewayValueMatches(val, e[key]) {
+				return false
+			}
+		}
+		return true
+	case []interface{}:
+		e, ok := existing.([]interface{})
+		if !ok || len(e) != len(d) {
+			return false
+		}
+		for i := range d {
+			if !gatewayValueMatches(d[i], e[i]) {
+				return false
+			}
+		}
+		return true
+	default:
+		return reflect.DeepEqual(desired, existing)
+	}
+}
+
+// getGatewayRouteHost returns the host of the given Gateway API route, once it has been accepted by its Gateway. It
+// is the hostname of the route or, when the route matches any hostname, the addresses of its Gateway. The returned
+// bool is false while the route has not been accepted.
+func (r *ReconcileArgoCD) getGatewayRouteHost(route *unstructured.Unstructured) (string, bool) {
+	parents, _, _ := unstructured.NestedSlice(route.Object, "status", "parents")
+	accepted := false
+	for _, parent := range parents {
+		conditions, _, _ := unstructured.NestedSlice(asMap(parent), "conditions")
+		for _, condition := range conditions {
+			c := asMap(condition)
+			if c["type"] == gatewayRouteAcceptedCondition && c["status"] == "True" {
+				accepted = true
+			}
+		}
+	}
+	if !accepted {
+		return "", false
+	}
+
+	hostnames, _, _ := unstructured.NestedStringSlice(route.Object, "spec", "hostnames")
+	if len(hostnames) > 0 {
+		return strings.Join(hostnames, ", "), true
+	}
+
+	parentRefs, _, _ := unstructured.NestedSlice(route.Object, "spec", "parentRefs")
+	if len(parentRefs) == 0 {
+		return "", true
+	}
+	ref := asMap(parentRefs[0])
+	name, _ := ref["name"].(string)
+	namespace, _ := ref["namespace"].(string)
+	if len(namespace) == 0 {
+		namespace = route.GetNamespace()
+	}
+
+	gateway := newGatewayObject(gatewayGVK, name, namespace)
+	if err := argoutil.FetchObject(r.Client, namespace, name, gateway); err != nil {
+		log.Info("unable to get the gateway of the route", "gateway", name, "namespace", namespace, "error", err.Error())
+		return "", true
+	}
+	addresses, _, _ := unstructured.NestedSlice(gateway.Object, "status", "addresses")
+	var hosts []string
+	for _, address := range addresses {
+		if value, ok := asMap(address)["value"].(string); ok && len(value) > 0 {
+			hosts = append(hosts, value)
+		}
+	}
+	return strings.Join(hosts, ", "), true
+}
+
+// asMap returns the given unstructured value as a map, an empty map if it is not one.
+func asMap(val interface{}) map[string]interface{} {
+	m, ok := val.(map[string]interface{})
+	if !ok {
+		return map[string]interface{}{}
+	}
+	return m
+}
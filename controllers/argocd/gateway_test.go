@@ -0,0 +1,280 @@
+package argocd
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	argoproj "github.com/argoproj-labs/argocd-operator/api/v1beta1"
+	"github.com/argoproj-labs/argocd-operator/common"
+)
+
+func withGatewayAPI(t *testing.T) {
+	gatewayAPIFound, grpcRouteAPIFound = true, true
+	t.Cleanup(func() {
+		gatewayAPIFound, grpcRouteAPIFound = false, false
+	})
+}
+
+func makeGatewayArgoCD(opts ...argoCDOpt) *argoproj.ArgoCD {
+	return makeTestArgoCD(append([]argoCDOpt{func(a *argoproj.ArgoCD) {
+		a.Spec.Server.Host = "argocd.example.com"
+		a.Spec.Server.Gateway = argoproj.ArgoCDGatewaySpec{
+			Enabled:     true,
+			GatewayName: "public",
+			Labels:      map[string]string{"team": "platform"},
+		}
+		a.Spec.Server.GRPC.Gateway = argoproj.ArgoCDGatewaySpec{
+			Enabled:          true,
+			GatewayName:      "public",
+			GatewayNamespace: "gateways",
+			SectionName:      "grpc",
+		}
+		a.Spec.ApplicationSet = &argoproj.ArgoCDApplicationSet{
+			WebhookServer: argoproj.WebhookServerSpec{
+				Gateway: argoproj.ArgoCDGatewaySpec{
+					Enabled:     true,
+					GatewayName: "public",
+				},
+			},
+		}
+	}}, opts...)...)
+}
+
+func getGatewayRoute(t *testing.T, r *ReconcileArgoCD, gvk schema.GroupVersionKind, name string) *unstructured.Unstructured {
+	t.Helper()
+	route := newGatewayObject(gvk, name, testNamespace)
+	assert.NoError(t, r.Client.Get(context.TODO(), client.ObjectKeyFromObject(route), route))
+	return route
+}
+
+func TestReconcileArgoCD_reconcileGatewayRoutes(t *testing.T) {
+	logf.SetLogger(ZapLogger(true))
+	withGatewayAPI(t)
+	a := makeGatewayArgoCD()
+
+	resObjs := []client.Object{a}
+	sch := makeTestReconcilerScheme(argoproj.AddToScheme)
+	cl := makeTestReconcilerClient(sch, resObjs, resObjs, []runtime.Object{})
+	r := makeTestReconciler(cl, sch)
+
+	assert.NoError(t, r.reconcileGatewayRoutes(a))
+
+	server := getGatewayRoute(t, r, httpRouteGVK, "argocd-server")
+	assert.Equal(t, "platform", server.GetLabels()["team"])
+	assert.Equal(t, "argocd-server", server.GetLabels()[common.ArgoCDKeyName])
+	assert.Equal(t, a.Name, server.GetOwnerReferences()[0].Name)
+	hostnames, _, _ := unstructured.NestedStringSlice(server.Object, "spec", "hostnames")
+	assert.Equal(t, []string{"argocd.example.com"}, hostnames)
+	parentRefs, _, _ := unstructured.NestedSlice(server.Object, "spec", "parentRefs")
+	assert.Equal(t, "public", asMap(parentRefs[0])["name"])
+	assert.Equal(t, testNamespace, asMap(parentRefs[0])["namespace"])
+	rules, _, _ := unstructured.NestedSlice(server.Object, "spec", "rules")
+	backendRefs, _, _ := unstructured.NestedSlice(asMap(rules[0]), "backendRefs")
+	assert.Equal(t, "argocd-server", asMap(backendRefs[0])["name"])
+	assert.Equal(t, int64(443), asMap(backendRefs[0])["port"])
+
+	grpc := getGatewayRoute(t, r, grpcRouteGVK, "argocd-grpc")
+	parentRefs, _, _ = unstructured.NestedSlice(grpc.Object, "spec", "parentRefs")
+	assert.Equal(t, "gateways", asMap(parentRefs[0])["namespace"])
+	assert.Equal(t, "grpc", asMap(parentRefs[0])["sectionName"])
+	_, found, _ := unstructured.NestedSlice(grpc.Object, "spec", "hostnames")
+	assert.False(t, found)
+
+	webhook := getGatewayRoute(t, r, httpRouteGVK, "argocd-applicationset-controller-webhook")
+	rules, _, _ = unstructured.NestedSlice(webhook.Object, "spec", "rules")
+	matches, _, _ := unstructured.NestedSlice(asMap(rules[0]), "matches")
+	path, _, _ := unstructured.NestedString(asMap(matches[0]), "path", "value")
+	assert.Equal(t, "/api/webhook", path)
+	backendRefs, _, _ = unstructured.NestedSlice(asMap(rules[0]), "backendRefs")
+	assert.Equal(t, int64(7000), asMap(backendRefs[0])["port"])
+
+	// the routes are deleted once disabled
+	a.Spec.Server.Gateway.Enabled = false
+	a.Spec.ApplicationSet = nil
+	assert.NoError(t, r.reconcileGatewayRoutes(a))
+	for _, name := range []string{"argocd-server", "argocd-applicationset-controller-webhook"} {
+		route := newGatewayObject(httpRouteGVK, name, testNamespace)
+		assert.Error(t, r.Client.Get(context.TODO(), client.ObjectKeyFromObject(route), route))
+	}
+	getGatewayRoute(t, r, grpcRouteGVK, "argocd-grpc")
+}
+
+func TestReconcileArgoCD_reconcileGatewayRoutes_drift(t *testing.T) {
+	logf.SetLogger(ZapLogger(true))
+	withGatewayAPI(t)
+	a := makeGatewayArgoCD()
+
+	resObjs := []client.Object{a}
+	sch := makeTestReconcilerScheme(argoproj.AddToScheme)
+	cl := makeTestReconcilerClient(sch, resObjs, resObjs, []runtime.Object{})
+	r := makeTestReconciler(cl, sch)
+	assert.NoError(t, r.reconcileGatewayRoutes(a))
+
+	// fields defaulted by the Gateway API are kept
+	server := getGatewayRoute(t, r, httpRouteGVK, "argocd-server")
+	rules, _, _ := unstructured.NestedSlice(server.Object, "spec", "rules")
+	asMap(rules[0])["timeouts"] = map[string]interface{}{"request": "10s"}
+	assert.NoError(t, unstructured.SetNestedSlice(server.Object, rules, "spec", "rules"))
+	assert.NoError(t, r.Client.Update(context.TODO(), server))
+	resourceVersion := getGatewayRoute(t, r, httpRouteGVK, "argocd-server").GetResourceVersion()
+	assert.NoError(t, r.reconcileGatewayRoutes(a))
+	assert.Equal(t, resourceVersion, getGatewayRoute(t, r, httpRouteGVK, "argocd-server").GetResourceVersion())
+
+	// manual changes are reverted, and annotations added by others are kept
+	server = getGatewayRoute(t, r, httpRouteGVK, "argocd-server")
+	server.SetAnnotations(map[string]string{"external-dns.alpha.kubernetes.io/ttl": "60"})
+	assert.NoError(t, unstructured.SetNestedStringSlice(server.Object, []string{"argocd.example.com", "other.example.com"}, "spec", "hostnames"))
+	server.SetLabels(map[string]string{"team": "other"})
+	assert.NoError(t, r.Client.Update(context.TODO(), server))
+	grpc := getGatewayRoute(t, r, grpcRouteGVK, "argocd-grpc")
+	assert.NoError(t, unstructured.SetNestedStringSlice(grpc.Object, []string{"grpc.example.com"}, "spec", "hostnames"))
+	assert.NoError(t, r.Client.Update(context.TODO(), grpc))
+
+	assert.NoError(t, r.reconcileGatewayRoutes(a))
+
+	server = getGatewayRoute(t, r, httpRouteGVK, "argocd-server")
+	hostnames, _, _ := unstructured.NestedStringSlice(server.Object, "spec", "hostnames")
+	assert.Equal(t, []string{"argocd.example.com"}, hostnames)
+	assert.Equal(t, "platform", server.GetLabels()["team"])
+	assert.Equal(t, "argocd-server", server.GetLabels()[common.ArgoCDKeyName])
+	assert.Equal(t, "60", server.GetAnnotations()["external-dns.alpha.kubernetes.io/ttl"])
+	grpc = getGatewayRoute(t, r, grpcRouteGVK, "argocd-grpc")
+	_, found, _ := unstructured.NestedStringSlice(grpc.Object, "spec", "hostnames")
+	assert.False(t, found)
+}
+
+func TestReconcileArgoCD_reconcileGatewayRoutes_removedMetadata(t *testing.T) {
+	logf.SetLogger(ZapLogger(true))
+	withGatewayAPI(t)
+	a := makeGatewayArgoCD(func(a *argoproj.ArgoCD) {
+		a.Spec.Server.Gateway.Annotations = map[string]string{"example.com/annotation": "value"}
+	})
+
+	resObjs := []client.Object{a}
+	sch := makeTestReconcilerScheme(argoproj.AddToScheme)
+	cl := makeTestReconcilerClient(sch, resObjs, resObjs, []runtime.Object{})
+	r := makeTestReconciler(cl, sch)
+	assert.NoError(t, r.reconcileGatewayRoutes(a))
+
+	server := getGatewayRoute(t, r, httpRouteGVK, "argocd-server")
+	assert.Equal(t, "value", server.GetAnnotations()["example.com/annotation"])
+	annotations := server.GetAnnotations()
+	annotations["external-dns.alpha.kubernetes.io/ttl"] = "60"
+	server.SetAnnotations(annotations)
+	assert.NoError(t, r.Client.Update(context.TODO(), server))
+
+	// the annotations and labels removed from the spec are removed from the route, the ones added by others are kept
+	a.Spec.Server.Gateway.Annotations = nil
+	a.Spec.Server.Gateway.Labels = nil
+	assert.NoError(t, r.reconcileGatewayRoutes(a))
+
+	server = getGatewayRoute(t, r, httpRouteGVK, "argocd-server")
+	assert.NotContains(t, server.GetAnnotations(), "example.com/annotation")
+	assert.NotContains(t, server.GetLabels(), "team")
+	assert.Equal(t, "argocd-server", server.GetLabels()[common.ArgoCDKeyName])
+	assert.Equal(t, "60", server.GetAnnotations()["external-dns.alpha.kubernetes.io/ttl"])
+}
+
+func TestReconcileArgoCD_reconcileGatewayRoutes_missingGatewayName(t *testing.T) {
+	logf.SetLogger(ZapLogger(true))
+	withGatewayAPI(t)
+	a := makeGatewayArgoCD(func(a *argoproj.ArgoCD) {
+		a.Spec.Server.Gateway.GatewayName = ""
+	})
+
+	resObjs := []client.Object{a}
+	sch := makeTestReconcilerScheme(argoproj.AddToScheme)
+	cl := makeTestReconcilerClient(sch, resObjs, resObjs, []runtime.Object{})
+	r := makeTestReconciler(cl, sch)
+
+	assert.EqualError(t, r.reconcileGatewayRoutes(a), "the gateway name of HTTPRoute argocd-server must be set")
+}
+
+func TestGatewayRouteSpecMatches(t *testing.T) {
+	a := makeGatewayArgoCD()
+	desired := newServerHTTPRoute(a).Object["spec"]
+
+	tests := []struct {
+		name     string
+		existing func(spec map[string]interface{})
+		want     bool
+	}{
+		{"same spec", func(spec map[string]interface{}) {}, true},
+		{"defaulted field", func(spec map[string]interface{}) {
+			asMap(asMap(spec["rules"].([]interface{})[0])["matches"].([]interface{})[0])["method"] = "GET"
+		}, true},
+		{"changed field", func(spec map[string]interface{}) {
+			asMap(asMap(spec["rules"].([]interface{})[0])["backendRefs"].([]interface{})[0])["port"] = int64(80)
+		}, false},
+		{"added rule", func(spec map[string]interface{}) {
+			spec["rules"] = append(spec["rules"].([]interface{}), map[string]interface{}{})
+		}, false},
+		{"added top-level field", func(spec map[string]interface{}) {
+			delete(spec, "hostnames")
+			spec["other"] = "value"
+		}, false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			existing := runtime.DeepCopyJSONValue(desired).(map[string]interface{})
+			test.existing(existing)
+			assert.Equal(t, test.want, gatewayRouteSpecMatches(desired, existing))
+		})
+	}
+}
+
+func TestReconcileArgoCD_reconcileStatusHost_gateway(t *testing.T) {
+	logf.SetLogger(ZapLogger(true))
+	withGatewayAPI(t)
+
+	accepted := []interface{}{
+		map[string]interface{}{
+			"conditions": []interface{}{
+				map[string]interface{}{"type": "Accepted", "status": "True"},
+			},
+		},
+	}
+	gateway := newGatewayObject(gatewayGVK, "public", testNamespace)
+	assert.NoError(t, unstructured.SetNestedSlice(gateway.Object, []interface{}{
+		map[string]interface{}{"type": "IPAddress", "value": "10.0.0.1"},
+	}, "status", "addresses"))
+
+	tests := []struct {
+		name      string
+		host      string
+		parents   []interface{}
+		wantHost  string
+		wantPhase string
+	}{
+		{"hostname of the route", "argocd.example.com", accepted, "argocd.example.com", ""},
+		{"address of the gateway", "", accepted, "10.0.0.1", ""},
+		{"route not accepted", "argocd.example.com", nil, "", "Pending"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			a := makeGatewayArgoCD(func(a *argoproj.ArgoCD) {
+				a.Spec.Server.Host = test.host
+			})
+			route := newServerHTTPRoute(a)
+			if test.parents != nil {
+				assert.NoError(t, unstructured.SetNestedSlice(route.Object, test.parents, "status", "parents"))
+			}
+
+			resObjs := []client.Object{a, route, gateway.DeepCopy()}
+			sch := makeTestReconcilerScheme(argoproj.AddToScheme)
+			cl := makeTestReconcilerClient(sch, resObjs, []client.Object{a}, []runtime.Object{})
+			r := makeTestReconciler(cl, sch)
+
+			assert.NoError(t, r.reconcileStatusHost(a))
+			assert.Equal(t, test.wantHost, a.Status.Host)
+			assert.Equal(t, test.wantPhase, a.Status.Phase)
+		})
+	}
+}
@@ -63,8 +63,8 @@ func (r *ReconcileArgoCD) reconcileServerHPA(cr *argoproj.ArgoCD) error {
 	defaultHPA := newHorizontalPodAutoscalerWithSuffix("server", cr)
 	defaultHPA.Spec = autoscaling.HorizontalPodAutoscalerSpec{
 		MaxReplicas:                    maxReplicas,
-		MinReplicas:                    &minReplicas,
-		TargetCPUUtilizationPercentage: &tcup,
+		MinReplicas:                    int32Ptr(minReplicas),
+		TargetCPUUtilizationPercentage: int32Ptr(tcup),
 		ScaleTargetRef: autoscaling.CrossVersionObjectReference{
 			APIVersion: "apps/v1",
 			Kind:       "Deployment",
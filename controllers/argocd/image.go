@@ -0,0 +1,75 @@
+// Copyright 2023 ArgoCD Operator Developers
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package argocd
+
+import (
+	"reflect"
+
+	corev1 "k8s.io/api/core/v1"
+
+	argoproj "github.com/argoproj-labs/argocd-operator/api/v1beta1"
+)
+
+// getImagePullPolicy will return the pull policy of the containers of a component of the given ArgoCD, which is the
+// given policy of the component, the global policy or the given default policy, in that order of preference.
+func getImagePullPolicy(cr *argoproj.ArgoCD, policy corev1.PullPolicy, defaultPolicy corev1.PullPolicy) corev1.PullPolicy {
+	if policy != "" {
+		return policy
+	}
+	if cr.Spec.ImagePullPolicy != "" {
+		return cr.Spec.ImagePullPolicy
+	}
+	return defaultPolicy
+}
+
+// getImagePullSecrets will return the secrets used to pull the images of a component of the given ArgoCD, which are
+// the global secrets followed by the given secrets of the component.
+func getImagePullSecrets(cr *argoproj.ArgoCD, secrets []corev1.LocalObjectReference) []corev1.LocalObjectReference {
+	var pullSecrets []corev1.LocalObjectReference
+	seen := map[string]bool{}
+	for _, secret := range append(append([]corev1.LocalObjectReference{}, cr.Spec.ImagePullSecrets...), secrets...) {
+		if !seen[secret.Name] {
+			seen[secret.Name] = true
+			pullSecrets = append(pullSecrets, secret)
+		}
+	}
+	return pullSecrets
+}
+
+// updateImagePullOptions updates the image pull secrets and the pull policy of the containers of the given existing
+// pod spec that differ from the given desired pod spec, and sets changed to true if any of them was updated.
+func updateImagePullOptions(existing *corev1.PodSpec, desired *corev1.PodSpec, changed *bool) {
+	if !reflect.DeepEqual(existing.ImagePullSecrets, desired.ImagePullSecrets) {
+		existing.ImagePullSecrets = desired.ImagePullSecrets
+		*changed = true
+	}
+	updateContainersImagePullPolicy(existing.InitContainers, desired.InitContainers, changed)
+	updateContainersImagePullPolicy(existing.Containers, desired.Containers, changed)
+}
+
+// updateContainersImagePullPolicy updates the pull policy of the given existing containers that differ from the given
+// desired containers of the same name, and sets changed to true if any of them was updated. The pull policy defaulted
+// by Kubernetes is kept when the desired container has none.
+func updateContainersImagePullPolicy(existing []corev1.Container, desired []corev1.Container, changed *bool) {
+	for i := range existing {
+		for _, container := range desired {
+			if existing[i].Name == container.Name && container.ImagePullPolicy != "" &&
+				existing[i].ImagePullPolicy != container.ImagePullPolicy {
+				existing[i].ImagePullPolicy = container.ImagePullPolicy
+				*changed = true
+			}
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package argocd
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	argoproj "github.com/argoproj-labs/argocd-operator/api/v1beta1"
+)
+
+func TestGetImagePullPolicy(t *testing.T) {
+	tests := []struct {
+		name      string
+		global    corev1.PullPolicy
+		component corev1.PullPolicy
+		want      corev1.PullPolicy
+	}{
+		{"default policy", "", "", corev1.PullAlways},
+		{"global policy", corev1.PullIfNotPresent, "", corev1.PullIfNotPresent},
+		{"component policy", "", corev1.PullNever, corev1.PullNever},
+		{"component policy over global policy", corev1.PullIfNotPresent, corev1.PullNever, corev1.PullNever},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			a := makeTestArgoCD(func(a *argoproj.ArgoCD) {
+				a.Spec.ImagePullPolicy = test.global
+			})
+			assert.Equal(t, test.want, getImagePullPolicy(a, test.component, corev1.PullAlways))
+		})
+	}
+}
+
+func TestGetImagePullSecrets(t *testing.T) {
+	tests := []struct {
+		name      string
+		global    []corev1.LocalObjectReference
+		component []corev1.LocalObjectReference
+		want      []corev1.LocalObjectReference
+	}{
+		{"no secrets", nil, nil, nil},
+		{"global secrets", []corev1.LocalObjectReference{{Name: "mirror"}}, nil,
+			[]corev1.LocalObjectReference{{Name: "mirror"}}},
+		{"global and component secrets",
+			[]corev1.LocalObjectReference{{Name: "mirror"}},
+			[]corev1.LocalObjectReference{{Name: "plugins"}, {Name: "mirror"}},
+			[]corev1.LocalObjectReference{{Name: "mirror"}, {Name: "plugins"}}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			a := makeTestArgoCD(func(a *argoproj.ArgoCD) {
+				a.Spec.ImagePullSecrets = test.global
+			})
+			assert.Equal(t, test.want, getImagePullSecrets(a, test.component))
+		})
+	}
+}
+
+func TestReconcileArgoCD_reconcileRepoDeployment_imagePullOptions(t *testing.T) {
+	logf.SetLogger(ZapLogger(true))
+	a := makeTestArgoCD(func(a *argoproj.ArgoCD) {
+		a.Spec.ImageRegistry = "mirror.example.com/"
+		a.Spec.ImagePullPolicy = corev1.PullIfNotPresent
+		a.Spec.ImagePullSecrets = []corev1.LocalObjectReference{{Name: "mirror"}}
+		a.Spec.Repo.ImagePullSecrets = []corev1.LocalObjectReference{{Name: "plugins"}}
+	})
+
+	resObjs := []client.Object{a}
+	sch := makeTestReconcilerScheme(argoproj.AddToScheme)
+	cl := makeTestReconcilerClient(sch, resObjs, resObjs, []runtime.Object{})
+	r := makeTestReconciler(cl, sch)
+
+	assert.NoError(t, r.reconcileRepoDeployment(a, false))
+
+	deployment := &appsv1.Deployment{}
+	key := types.NamespacedName{Name: "argocd-repo-server", Namespace: testNamespace}
+	assert.NoError(t, r.Client.Get(context.TODO(), key, deployment))
+	podSpec := deployment.Spec.Template.Spec
+	assert.Equal(t, []corev1.LocalObjectReference{{Name: "mirror"}, {Name: "plugins"}}, podSpec.ImagePullSecrets)
+	assert.Regexp(t, "^mirror.example.com/argoproj/argocd@", podSpec.Containers[0].Image)
+	assert.Equal(t, corev1.PullIfNotPresent, podSpec.Containers[0].ImagePullPolicy)
+	assert.Regexp(t, "^mirror.example.com/argoproj/argocd@", podSpec.InitContainers[0].Image)
+	assert.Equal(t, corev1.PullIfNotPresent, podSpec.InitContainers[0].ImagePullPolicy)
+
+	// the pull options are updated
+	a.Spec.ImagePullSecrets = nil
+	a.Spec.Repo.ImagePullPolicy = corev1.PullNever
+	assert.NoError(t, r.reconcileRepoDeployment(a, false))
+
+	assert.NoError(t, r.Client.Get(context.TODO(), key, deployment))
+	podSpec = deployment.Spec.Template.Spec
+	assert.Equal(t, []corev1.LocalObjectReference{{Name: "plugins"}}, podSpec.ImagePullSecrets)
+	assert.Equal(t, corev1.PullNever, podSpec.Containers[0].ImagePullPolicy)
+	assert.Equal(t, corev1.PullNever, podSpec.InitContainers[0].ImagePullPolicy)
+}
@@ -0,0 +1,303 @@
+// Copyright 2023 ArgoCD Operator Developers
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package argocd
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	argoprojv1alpha1 "github.com/argoproj-labs/argocd-operator/api/v1alpha1"
+	argoproj "github.com/argoproj-labs/argocd-operator/api/v1beta1"
+	"github.com/argoproj-labs/argocd-operator/common"
+	"github.com/argoproj-labs/argocd-operator/controllers/argoutil"
+)
+
+// importContainerName is the name of the container performing the import process.
+const importContainerName = "argocd-import"
+
+// importResult is the result that the import process writes to the termination message of the import container,
+// see ImportResult in pkg/backup.
+type importResult struct {
+	BackupID string `json:"backupID,omitempty"`
+	Name     string `json:"name"`
+}
+
+// desiredImportStatus returns the import status for the Import options of the given ArgoCD, before the import
+// has started.
+func desiredImportStatus(cr *argoproj.ArgoCD) *argoproj.ArgoCDImportStatus {
+	namespace := cr.Namespace
+	if cr.Spec.Import.Namespace != nil && len(*cr.Spec.Import.Namespace) > 0 {
+		namespace = *cr.Spec.Import.Namespace
+	}
+	return &argoproj.ArgoCDImportStatus{
+		Name:              cr.Spec.Import.Name,
+		Namespace:         namespace,
+		RequestedBackupID: cr.Spec.Import.BackupID,
+	}
+}
+
+// isSameImport returns true if both import statuses are for the same ArgoCDExport and requested backup.
+func isSameImport(a, b *argoproj.ArgoCDImportStatus) bool {
+	return a.Name == b.Name && a.Namespace == b.Namespace && a.RequestedBackupID == b.RequestedBackupID
+}
+
+// isImportInProgress returns true if the Import options of the given ArgoCD request a backup that has not been
+// imported yet. The application controller is kept stopped meanwhile, so that it does not act on partial data.
+func isImportInProgress(cr *argoproj.ArgoCD) bool {
+	if cr.Spec.Import == nil {
+		return false
+	}
+	status := cr.Status.Import
+	return status == nil || !isSameImport(status, desiredImportStatus(cr)) || status.Phase != common.ArgoCDStatusCompleted
+}
+
+// getImportJobName returns the name of the import Job for the given import. The name depends on the requested
+// backup, so that a new Job is created when the Import options change.
+func getImportJobName(cr *argoproj.ArgoCD, status *argoproj.ArgoCDImportStatus) string {
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s/%s/%s", status.Namespace, status.Name, status.RequestedBackupID)))
+	suffix := "-import-" + hex.EncodeToString(sum[:4])
+	name := cr.Name
+	if len(name)+len(suffix) > 63 {
+		name = name[:63-len(suffix)]
+	}
+	return name + suffix
+}
+
+// newImportJob returns the Job importing a backup of the given ArgoCDExport into the given ArgoCD.
+func (r *ReconcileArgoCD) newImportJob(cr *argoproj.ArgoCD, export *argoprojv1alpha1.ArgoCDExport, name string) *batchv1.Job {
+	labels := argoutil.LabelsForCluster(cr)
+	labels[common.ArgoCDKeyName] = name
+	labels[common.ArgoCDKeyComponent] = "import"
+
+	image := getArgoImportContainerImage(cr, export)
+	podSpec := corev1.PodSpec{
+		Containers: []corev1.Container{{
+			Command:         getArgoImportCommand(export, image),
+			Env:             proxyEnvVars(getArgoImportContainerEnv(cr, export)...),
+			Resources:       getArgoApplicationControllerResources(cr),
+			Image:           image,
+			ImagePullPolicy: getImagePullPolicy(cr, "", corev1.PullAlways),
+			Name:            importContainerName,
+			SecurityContext: &corev1.SecurityContext{
+				AllowPrivilegeEscalation: boolPtr(false),
+				Capabilities: &corev1.Capabilities{
+					Drop: []corev1.Capability{
+						"ALL",
+					},
+				},
+				RunAsNonRoot: boolPtr(true),
+			},
+			VolumeMounts: getArgoImportVolumeMounts(export),
+		}},
+		NodeSelector:       common.DefaultNodeSelector(),
+		RestartPolicy:      corev1.RestartPolicyNever,
+		ServiceAccountName: nameWithSuffix("argocd-application-controller", cr),
+		Volumes:            getArgoImportVolumes(export),
+	}
+	applyNodePlacement(&podSpec, getNodePlacement(cr, nil), nil)
+	podSpec.ImagePullSecrets = getImagePullSecrets(cr, nil)
+	AddSeccompProfileForOpenShift(r.Client, &podSpec)
+
+	backoffLimit := common.ArgoCDDefaultImportJobBackoffLimit
+	return &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: cr.Namespace,
+			Labels:    labels,
+		},
+		Spec: batchv1.JobSpec{
+			BackoffLimit: &backoffLimit,
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: map[string]string{
+						common.ArgoCDKeyName: name,
+					},
+				},
+				Spec: podSpec,
+			},
+		},
+	}
+}
+
+// reconcileImport will ensure that the backup requested by the Import options of the given ArgoCD is imported once,
+// by a Job, and that the progress of the import is recorded in the Import status. Once the import completed, it is
+// not run again until the Import options request another backup. A failed import is retried once its Job has been
+// deleted.
+func (r *ReconcileArgoCD) reconcileImport(cr *argoproj.ArgoCD) error {
+	existing := cr.Status.DeepCopy()
+
+	if cr.Spec.Import == nil {
+		// The status of the last import is kept as a record of the restored backup.
+		meta.RemoveStatusCondition(&cr.Status.Conditions, argoproj.ArgoCDConditionImported)
+	} else {
+		status := desiredImportStatus(cr)
+		if cr.Status.Import != nil && isSameImport(cr.Status.Import, status) {
+			status = cr.Status.Import.DeepCopy()
+		}
+
+		if status.Phase != common.ArgoCDStatusCompleted {
+			if err := r.reconcileImportJob(cr, status); err != nil {
+				return err
+			}
+		}
+		cr.Status.Import = status
+		setImportCondition(cr, status)
+	}
+
+	if !reflect.DeepEqual(existing, &cr.Status) {
+		return r.Client.Status().Update(context.TODO(), cr)
+	}
+	return nil
+}
+
+// reconcileImportJob will ensure that the import Job for the given import status exists, and updates the status
+// with its progress.
+func (r *ReconcileArgoCD) reconcileImportJob(cr *argoproj.ArgoCD, status *argoproj.ArgoCDImportStatus) error {
+	export := &argoprojv1alpha1.ArgoCDExport{}
+	if !argoutil.IsObjectFound(r.Client, status.Namespace, status.Name, export) {
+		status.Phase = common.ArgoCDStatusPending
+		status.Message = fmt.Sprintf("waiting for ArgoCDExport %s in namespace %s", status.Name, status.Namespace)
+		return nil
+	}
+	if export.Spec.Storage == nil {
+		status.Phase = common.ArgoCDStatusPending
+		status.Message = fmt.Sprintf("waiting for the storage of ArgoCDExport %s in namespace %s", status.Name, status.Namespace)
+		return nil
+	}
+
+	job := r.newImportJob(cr, export, getImportJobName(cr, status))
+	if !argoutil.IsObjectFound(r.Client, cr.Namespace, job.Name, job) {
+		if unsupported := legacyImportUnsupportedOptions(cr, export); len(unsupported) > 0 {
+			status.Phase = common.ArgoCDStatusFailed
+			status.Message = fmt.Sprintf("import image %s does not support %s, set spec.image and spec.version of ArgoCDExport %s to an image built from build/util/Dockerfile",
+				getArgoImportContainerImage(cr, export), strings.Join(unsupported, ", "), export.Name)
+			return nil
+		}
+		if err := controllerutil.SetControllerReference(cr, job, r.Scheme); err != nil {
+			return err
+		}
+		log.Info("creating import job", "name", job.Name, "namespace", job.Namespace)
+		if err := r.Client.Create(context.TODO(), job); err != nil {
+			return err
+		}
+		status.JobName = job.Name
+		status.Phase = common.ArgoCDStatusRunning
+		status.StartTime = nil
+		status.CompletionTime = nil
+		status.Message = fmt.Sprintf("import job %s created", job.Name)
+		return nil
+	}
+
+	status.JobName = job.Name
+	status.StartTime = job.Status.StartTime
+	for _, c := range job.Status.Conditions {
+		if c.Status != corev1.ConditionTrue {
+			continue
+		}
+		switch c.Type {
+		case batchv1.JobComplete:
+			status.Phase = common.ArgoCDStatusCompleted
+			status.CompletionTime = job.Status.CompletionTime
+			status.Message = fmt.Sprintf("import job %s completed", job.Name)
+			if result := r.importJobResult(job); result != nil {
+				status.BackupID = result.BackupID
+				status.Message = fmt.Sprintf("backup %s imported by job %s", result.Name, job.Name)
+			}
+			return nil
+		case batchv1.JobFailed:
+			status.Phase = common.ArgoCDStatusFailed
+			status.CompletionTime = c.LastTransitionTime.DeepCopy()
+			status.Message = fmt.Sprintf("import job %s failed: %s, delete the job to retry", job.Name, c.Reason)
+			return nil
+		}
+	}
+	status.Phase = common.ArgoCDStatusRunning
+	status.CompletionTime = nil
+	status.Message = fmt.Sprintf("import job %s is running", job.Name)
+	return nil
+}
+
+// legacyImportUnsupportedOptions returns the import options that the legacy export image of the given ArgoCDExport
+// would ignore, restoring another backup than the requested one. It returns nothing for any other image.
+func legacyImportUnsupportedOptions(cr *argoproj.ArgoCD, export *argoprojv1alpha1.ArgoCDExport) []string {
+	if !argoutil.IsLegacyExportImage(getArgoImportContainerImage(cr, export)) {
+		return nil
+	}
+	unsupported := argoutil.LegacyExportUnsupportedOptions(export)
+	if cr.Spec.Import != nil && len(cr.Spec.Import.BackupID) > 0 {
+		unsupported = append(unsupported, "spec.import.backupID")
+	}
+	return unsupported
+}
+
+// importJobResult returns the result reported by the import container of the given Job, or nil if no Pod of the Job
+// reported one.
+func (r *ReconcileArgoCD) importJobResult(job *batchv1.Job) *importResult {
+	pods := &corev1.PodList{}
+	if err := r.Client.List(context.TODO(), pods, client.InNamespace(job.Namespace), client.MatchingLabels{"job-name": job.Name}); err != nil {
+		log.Error(err, "failed to list import job pods", "job", job.Name)
+		return nil
+	}
+
+	for _, pod := range pods.Items {
+		for _, status := range pod.Status.ContainerStatuses {
+			terminated := status.State.Terminated
+			if status.Name != importContainerName || terminated == nil || terminated.ExitCode != 0 || len(terminated.Message) == 0 {
+				continue
+			}
+			result := &importResult{}
+			if err := json.Unmarshal([]byte(terminated.Message), result); err != nil || len(result.Name) == 0 {
+				log.Info("ignoring malformed import result", "pod", pod.Name)
+				continue
+			}
+			return result
+		}
+	}
+	return nil
+}
+
+// setImportCondition sets the Imported condition of the given ArgoCD from the given import status.
+func setImportCondition(cr *argoproj.ArgoCD, status *argoproj.ArgoCDImportStatus) {
+	condition := metav1.Condition{
+		Type:               argoproj.ArgoCDConditionImported,
+		Status:             metav1.ConditionFalse,
+		Message:            status.Message,
+		ObservedGeneration: cr.Generation,
+	}
+	switch status.Phase {
+	case common.ArgoCDStatusCompleted:
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = argoproj.ArgoCDReasonImportCompleted
+	case common.ArgoCDStatusFailed:
+		condition.Reason = argoproj.ArgoCDReasonImportFailed
+	case common.ArgoCDStatusRunning:
+		condition.Reason = argoproj.ArgoCDReasonImportRunning
+	default:
+		condition.Reason = argoproj.ArgoCDReasonImportPending
+	}
+	meta.SetStatusCondition(&cr.Status.Conditions, condition)
+}
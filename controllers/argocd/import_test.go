@@ -0,0 +1,228 @@
+package argocd
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	argoprojv1alpha1 "github.com/argoproj-labs/argocd-operator/api/v1alpha1"
+	argoproj "github.com/argoproj-labs/argocd-operator/api/v1beta1"
+	"github.com/argoproj-labs/argocd-operator/common"
+)
+
+func withImport(name, backupID string) argoCDOpt {
+	return func(a *argoproj.ArgoCD) {
+		a.Spec.Import = &argoproj.ArgoCDImportSpec{
+			Name:     name,
+			BackupID: backupID,
+		}
+	}
+}
+
+func makeTestImportExport(a *argoproj.ArgoCD) *argoprojv1alpha1.ArgoCDExport {
+	return &argoprojv1alpha1.ArgoCDExport{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "testimport",
+			Namespace: a.Namespace,
+		},
+		Spec: argoprojv1alpha1.ArgoCDExportSpec{
+			Storage: &argoprojv1alpha1.ArgoCDExportStorageSpec{
+				Backend: common.ArgoCDExportStorageBackendLocal,
+			},
+			Version: "v0.6.0",
+		},
+	}
+}
+
+func makeTestImportReconciler(a *argoproj.ArgoCD, objs ...client.Object) *ReconcileArgoCD {
+	resObjs := append([]client.Object{a}, objs...)
+	subresObjs := []client.Object{a, &batchv1.Job{}}
+	runtimeObjs := []runtime.Object{}
+	sch := makeTestReconcilerScheme(argoproj.AddToScheme, argoprojv1alpha1.AddToScheme)
+	cl := makeTestReconcilerClient(sch, resObjs, subresObjs, runtimeObjs)
+	return makeTestReconciler(cl, sch)
+}
+
+// finishImportJob marks the import Job of the given ArgoCD as finished with the given condition, and adds a Pod
+// reporting the given termination message.
+func finishImportJob(t *testing.T, r *ReconcileArgoCD, a *argoproj.ArgoCD, conditionType batchv1.JobConditionType, message string) {
+	job := &batchv1.Job{}
+	assert.NoError(t, r.Client.Get(context.TODO(), types.NamespacedName{Name: a.Status.Import.JobName, Namespace: a.Namespace}, job))
+	job.Status.Conditions = []batchv1.JobCondition{{
+		Type:   conditionType,
+		Status: corev1.ConditionTrue,
+		Reason: "BackoffLimitExceeded",
+	}}
+	assert.NoError(t, r.Client.Status().Update(context.TODO(), job))
+
+	exitCode := int32(0)
+	if conditionType == batchv1.JobFailed {
+		exitCode = 1
+	}
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      job.Name + "-abcde",
+			Namespace: a.Namespace,
+			Labels:    map[string]string{"job-name": job.Name},
+		},
+		Status: corev1.PodStatus{
+			ContainerStatuses: []corev1.ContainerStatus{{
+				Name: importContainerName,
+				State: corev1.ContainerState{
+					Terminated: &corev1.ContainerStateTerminated{ExitCode: exitCode, Message: message},
+				},
+			}},
+		},
+	}
+	assert.NoError(t, r.Client.Create(context.TODO(), pod))
+}
+
+func TestReconcileArgoCD_reconcileImport_WaitsForExport(t *testing.T) {
+	logf.SetLogger(ZapLogger(true))
+	a := makeTestArgoCD(withImport("testimport", ""))
+	r := makeTestImportReconciler(a)
+
+	assert.NoError(t, r.reconcileImport(a))
+	assert.Equal(t, common.ArgoCDStatusPending, a.Status.Import.Phase)
+	assert.Equal(t, a.Namespace, a.Status.Import.Namespace)
+	assert.True(t, isImportInProgress(a))
+
+	condition := meta.FindStatusCondition(a.Status.Conditions, argoproj.ArgoCDConditionImported)
+	assert.NotNil(t, condition)
+	assert.Equal(t, metav1.ConditionFalse, condition.Status)
+	assert.Equal(t, argoproj.ArgoCDReasonImportPending, condition.Reason)
+
+	jobs := &batchv1.JobList{}
+	assert.NoError(t, r.Client.List(context.TODO(), jobs))
+	assert.Empty(t, jobs.Items)
+
+	// the status is persisted
+	existing := &argoproj.ArgoCD{}
+	assert.NoError(t, r.Client.Get(context.TODO(), types.NamespacedName{Name: a.Name, Namespace: a.Namespace}, existing))
+	assert.Equal(t, common.ArgoCDStatusPending, existing.Status.Import.Phase)
+}
+
+func TestReconcileArgoCD_reconcileImport_Completed(t *testing.T) {
+	logf.SetLogger(ZapLogger(true))
+	a := makeTestArgoCD(withImport("testimport", ""))
+	r := makeTestImportReconciler(a, makeTestImportExport(a))
+
+	assert.NoError(t, r.reconcileImport(a))
+	assert.Equal(t, common.ArgoCDStatusRunning, a.Status.Import.Phase)
+	assert.Equal(t, getImportJobName(a, a.Status.Import), a.Status.Import.JobName)
+
+	job := &batchv1.Job{}
+	assert.NoError(t, r.Client.Get(context.TODO(), types.NamespacedName{Name: a.Status.Import.JobName, Namespace: a.Namespace}, job))
+	assert.True(t, metav1.IsControlledBy(job, a))
+	assert.Equal(t, []string{"argocd-operator-util", "import", "local"}, job.Spec.Template.Spec.Containers[0].Command)
+
+	finishImportJob(t, r, a, batchv1.JobComplete, `{"backupID":"20231017T120000Z","name":"argocd-backup-20231017T120000Z.yaml"}`)
+	assert.NoError(t, r.reconcileImport(a))
+	assert.Equal(t, common.ArgoCDStatusCompleted, a.Status.Import.Phase)
+	assert.Equal(t, "20231017T120000Z", a.Status.Import.BackupID)
+	assert.False(t, isImportInProgress(a))
+	assert.True(t, meta.IsStatusConditionTrue(a.Status.Conditions, argoproj.ArgoCDConditionImported))
+
+	// a completed import is not run again
+	assert.NoError(t, r.Client.Delete(context.TODO(), job))
+	assert.NoError(t, r.reconcileImport(a))
+	assert.Equal(t, common.ArgoCDStatusCompleted, a.Status.Import.Phase)
+	jobs := &batchv1.JobList{}
+	assert.NoError(t, r.Client.List(context.TODO(), jobs))
+	assert.Empty(t, jobs.Items)
+
+	// another backup is imported by a new job
+	a.Spec.Import.BackupID = "20231016T120000Z"
+	assert.NoError(t, r.Client.Update(context.TODO(), a))
+	assert.True(t, isImportInProgress(a))
+	assert.NoError(t, r.reconcileImport(a))
+	assert.Equal(t, common.ArgoCDStatusRunning, a.Status.Import.Phase)
+	assert.Equal(t, "20231016T120000Z", a.Status.Import.RequestedBackupID)
+	assert.Empty(t, a.Status.Import.BackupID)
+	assert.NoError(t, r.Client.List(context.TODO(), jobs))
+	assert.Len(t, jobs.Items, 1)
+	assert.NotEqual(t, job.Name, jobs.Items[0].Name)
+
+	// removing the Import options keeps the status of the last import
+	a.Spec.Import = nil
+	assert.NoError(t, r.Client.Update(context.TODO(), a))
+	assert.NoError(t, r.reconcileImport(a))
+	assert.NotNil(t, a.Status.Import)
+	assert.Nil(t, meta.FindStatusCondition(a.Status.Conditions, argoproj.ArgoCDConditionImported))
+	assert.False(t, isImportInProgress(a))
+}
+
+func TestReconcileArgoCD_reconcileImport_Failed(t *testing.T) {
+	logf.SetLogger(ZapLogger(true))
+	a := makeTestArgoCD(withImport("testimport", "20231017T120000Z"))
+	r := makeTestImportReconciler(a, makeTestImportExport(a))
+
+	assert.NoError(t, r.reconcileImport(a))
+	finishImportJob(t, r, a, batchv1.JobFailed, "")
+	assert.NoError(t, r.reconcileImport(a))
+	assert.Equal(t, common.ArgoCDStatusFailed, a.Status.Import.Phase)
+	assert.True(t, isImportInProgress(a))
+
+	condition := meta.FindStatusCondition(a.Status.Conditions, argoproj.ArgoCDConditionImported)
+	assert.NotNil(t, condition)
+	assert.Equal(t, argoproj.ArgoCDReasonImportFailed, condition.Reason)
+
+	// the failed job is kept until it is deleted
+	assert.NoError(t, r.reconcileImport(a))
+	assert.Equal(t, common.ArgoCDStatusFailed, a.Status.Import.Phase)
+
+	job := &batchv1.Job{}
+	assert.NoError(t, r.Client.Get(context.TODO(), types.NamespacedName{Name: a.Status.Import.JobName, Namespace: a.Namespace}, job))
+	assert.NoError(t, r.Client.Delete(context.TODO(), job))
+	assert.NoError(t, r.reconcileImport(a))
+	assert.Equal(t, common.ArgoCDStatusRunning, a.Status.Import.Phase)
+	assert.NoError(t, r.Client.Get(context.TODO(), types.NamespacedName{Name: a.Status.Import.JobName, Namespace: a.Namespace}, job))
+}
+
+func TestReconcileArgoCD_reconcileImport_LegacyImage(t *testing.T) {
+	logf.SetLogger(ZapLogger(true))
+	a := makeTestArgoCD(withImport("testimport", "20231017T120000Z"))
+	export := makeTestImportExport(a)
+	export.Spec.Version = ""
+	r := makeTestImportReconciler(a, export)
+
+	// the legacy default image only restores its single backup
+	assert.NoError(t, r.reconcileImport(a))
+	assert.Equal(t, common.ArgoCDStatusFailed, a.Status.Import.Phase)
+	assert.Contains(t, a.Status.Import.Message, "does not support spec.import.backupID")
+	jobs := &batchv1.JobList{}
+	assert.NoError(t, r.Client.List(context.TODO(), jobs))
+	assert.Empty(t, jobs.Items)
+
+	a.Spec.Import.BackupID = ""
+	assert.NoError(t, r.Client.Update(context.TODO(), a))
+	assert.NoError(t, r.reconcileImport(a))
+	assert.Equal(t, common.ArgoCDStatusRunning, a.Status.Import.Phase)
+	job := &batchv1.Job{}
+	assert.NoError(t, r.Client.Get(context.TODO(), types.NamespacedName{Name: a.Status.Import.JobName, Namespace: a.Namespace}, job))
+	assert.Equal(t, []string{"uid_entrypoint.sh", "argocd-operator-util", "import", "local"}, job.Spec.Template.Spec.Containers[0].Command)
+}
+
+func TestReconcileArgoCD_argoCDExportMapper(t *testing.T) {
+	a := makeTestArgoCD(withImport("testimport", ""))
+	other := makeTestArgoCD()
+	other.Name = "other-argocd"
+	r := makeTestImportReconciler(a, other)
+
+	export := makeTestImportExport(a)
+	requests := r.argoCDExportMapper(context.TODO(), export)
+	assert.Equal(t, []reconcile.Request{{NamespacedName: types.NamespacedName{Name: a.Name, Namespace: a.Namespace}}}, requests)
+
+	export.Namespace = "other-namespace"
+	assert.Empty(t, r.argoCDExportMapper(context.TODO(), export))
+}
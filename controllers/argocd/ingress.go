@@ -19,6 +19,7 @@ import (
 	"fmt"
 
 	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 
@@ -89,21 +90,67 @@ func (r *ReconcileArgoCD) reconcileIngresses(cr *argoproj.ArgoCD) error {
 	return nil
 }
 
-// reconcileArgoServerIngress will ensure that the ArgoCD Server Ingress is present.
-func (r *ReconcileArgoCD) reconcileArgoServerIngress(cr *argoproj.ArgoCD) error {
-	ingress := newIngressWithSuffix("server", cr)
-	if argoutil.IsObjectFound(r.Client, cr.Namespace, ingress.Name, ingress) {
-		if !cr.Spec.Server.Ingress.Enabled {
+// reconcileIngress will ensure that the given desired Ingress is present when enabled, and absent otherwise. The
+// annotations, labels and spec of an existing Ingress are updated to match the desired Ingress, reverting any manual
+// change, which is recorded as an event of the given ArgoCD. Annotations and labels added by others are kept, while
+// the ones no longer desired are removed.
+func (r *ReconcileArgoCD) reconcileIngress(cr *argoproj.ArgoCD, desired *networkingv1.Ingress, enabled bool) error {
+	existing := &networkingv1.Ingress{}
+	if argoutil.IsObjectFound(r.Client, desired.Namespace, desired.Name, existing) {
+		if !enabled {
 			// Ingress exists but enabled flag has been set to false, delete the Ingress
-			return r.Client.Delete(context.TODO(), ingress)
+			return r.Client.Delete(context.TODO(), existing)
+		}
+
+		changed := mergeDesiredMetadata(existing, desired)
+		spec := desiredIngressSpec(existing, desired)
+		if !equality.Semantic.DeepEqual(existing.Spec, spec) {
+			existing.Spec = spec
+			changed = true
+		}
+		if !changed {
+			return nil // Ingress found and up to date, do nothing
 		}
-		return nil // Ingress found and enabled, do nothing
+
+		log.Info("updating ingress to match the ArgoCD spec", "name", existing.Name, "namespace", existing.Namespace)
+		if err := r.Client.Update(context.TODO(), existing); err != nil {
+			return err
+		}
+		r.createDriftEvent(cr, "Ingress", existing.Name)
+		return nil
 	}
 
-	if !cr.Spec.Server.Ingress.Enabled {
+	if !enabled {
 		return nil // Ingress not enabled, move along...
 	}
 
+	ingress := desired.DeepCopy()
+	setManagedMetadata(ingress)
+	if err := controllerutil.SetControllerReference(cr, ingress, r.Scheme); err != nil {
+		return err
+	}
+	return r.Client.Create(context.TODO(), ingress)
+}
+
+// desiredIngressSpec returns the spec of the given desired Ingress, with the ingress class taken from the given existing
+// Ingress when the desired Ingress has none, as the API server defaults it to the default IngressClass of the cluster.
+func desiredIngressSpec(existing, desired *networkingv1.Ingress) networkingv1.IngressSpec {
+	spec := *desired.Spec.DeepCopy()
+	if spec.IngressClassName == nil {
+		spec.IngressClassName = existing.Spec.IngressClassName
+	}
+	return spec
+}
+
+// reconcileArgoServerIngress will ensure that the ArgoCD Server Ingress is present and up to date.
+func (r *ReconcileArgoCD) reconcileArgoServerIngress(cr *argoproj.ArgoCD) error {
+	return r.reconcileIngress(cr, newArgoServerIngress(cr), cr.Spec.Server.Ingress.Enabled)
+}
+
+// newArgoServerIngress returns the desired ArgoCD Server Ingress for the given ArgoCD.
+func newArgoServerIngress(cr *argoproj.ArgoCD) *networkingv1.Ingress {
+	ingress := newIngressWithSuffix("server", cr)
+
 	// Add default annotations
 	atns := make(map[string]string)
 	atns[common.ArgoCDKeyIngressSSLRedirect] = "true"
@@ -159,26 +206,17 @@ func (r *ReconcileArgoCD) reconcileArgoServerIngress(cr *argoproj.ArgoCD) error
 		ingress.Spec.TLS = cr.Spec.Server.Ingress.TLS
 	}
 
-	if err := controllerutil.SetControllerReference(cr, ingress, r.Scheme); err != nil {
-		return err
-	}
-	return r.Client.Create(context.TODO(), ingress)
+	return ingress
 }
 
-// reconcileArgoServerGRPCIngress will ensure that the ArgoCD Server GRPC Ingress is present.
+// reconcileArgoServerGRPCIngress will ensure that the ArgoCD Server GRPC Ingress is present and up to date.
 func (r *ReconcileArgoCD) reconcileArgoServerGRPCIngress(cr *argoproj.ArgoCD) error {
-	ingress := newIngressWithSuffix("grpc", cr)
-	if argoutil.IsObjectFound(r.Client, cr.Namespace, ingress.Name, ingress) {
-		if !cr.Spec.Server.GRPC.Ingress.Enabled {
-			// Ingress exists but enabled flag has been set to false, delete the Ingress
-			return r.Client.Delete(context.TODO(), ingress)
-		}
-		return nil // Ingress found and enabled, do nothing
-	}
+	return r.reconcileIngress(cr, newArgoServerGRPCIngress(cr), cr.Spec.Server.GRPC.Ingress.Enabled)
+}
 
-	if !cr.Spec.Server.GRPC.Ingress.Enabled {
-		return nil // Ingress not enabled, move along...
-	}
+// newArgoServerGRPCIngress returns the desired ArgoCD Server GRPC Ingress for the given ArgoCD.
+func newArgoServerGRPCIngress(cr *argoproj.ArgoCD) *networkingv1.Ingress {
+	ingress := newIngressWithSuffix("grpc", cr)
 
 	// Add default annotations
 	atns := make(map[string]string)
@@ -234,26 +272,17 @@ func (r *ReconcileArgoCD) reconcileArgoServerGRPCIngress(cr *argoproj.ArgoCD) er
 		ingress.Spec.TLS = cr.Spec.Server.GRPC.Ingress.TLS
 	}
 
-	if err := controllerutil.SetControllerReference(cr, ingress, r.Scheme); err != nil {
-		return err
-	}
-	return r.Client.Create(context.TODO(), ingress)
+	return ingress
 }
 
-// reconcileGrafanaIngress will ensure that the ArgoCD Server GRPC Ingress is present.
+// reconcileGrafanaIngress will ensure that the ArgoCD Grafana Ingress is present and up to date.
 func (r *ReconcileArgoCD) reconcileGrafanaIngress(cr *argoproj.ArgoCD) error {
-	ingress := newIngressWithSuffix("grafana", cr)
-	if argoutil.IsObjectFound(r.Client, cr.Namespace, ingress.Name, ingress) {
-		if !cr.Spec.Grafana.Enabled || !cr.Spec.Grafana.Ingress.Enabled {
-			// Ingress exists but enabled flag has been set to false, delete the Ingress
-			return r.Client.Delete(context.TODO(), ingress)
-		}
-		return nil // Ingress found and enabled, do nothing
-	}
+	return r.reconcileIngress(cr, newGrafanaIngress(cr), cr.Spec.Grafana.Enabled && cr.Spec.Grafana.Ingress.Enabled)
+}
 
-	if !cr.Spec.Grafana.Enabled || !cr.Spec.Grafana.Ingress.Enabled {
-		return nil // Grafana itself or Ingress not enabled, move along...
-	}
+// newGrafanaIngress returns the desired ArgoCD Grafana Ingress for the given ArgoCD.
+func newGrafanaIngress(cr *argoproj.ArgoCD) *networkingv1.Ingress {
+	ingress := newIngressWithSuffix("grafana", cr)
 
 	// Add default annotations
 	atns := make(map[string]string)
@@ -311,26 +340,17 @@ func (r *ReconcileArgoCD) reconcileGrafanaIngress(cr *argoproj.ArgoCD) error {
 		ingress.Spec.TLS = cr.Spec.Grafana.Ingress.TLS
 	}
 
-	if err := controllerutil.SetControllerReference(cr, ingress, r.Scheme); err != nil {
-		return err
-	}
-	return r.Client.Create(context.TODO(), ingress)
+	return ingress
 }
 
-// reconcilePrometheusIngress will ensure that the Prometheus Ingress is present.
+// reconcilePrometheusIngress will ensure that the Prometheus Ingress is present and up to date.
 func (r *ReconcileArgoCD) reconcilePrometheusIngress(cr *argoproj.ArgoCD) error {
-	ingress := newIngressWithSuffix("prometheus", cr)
-	if argoutil.IsObjectFound(r.Client, cr.Namespace, ingress.Name, ingress) {
-		if !cr.Spec.Prometheus.Enabled || !cr.Spec.Prometheus.Ingress.Enabled {
-			// Ingress exists but enabled flag has been set to false, delete the Ingress
-			return r.Client.Delete(context.TODO(), ingress)
-		}
-		return nil // Ingress found and enabled, do nothing
-	}
+	return r.reconcileIngress(cr, newPrometheusIngress(cr), cr.Spec.Prometheus.Enabled && cr.Spec.Prometheus.Ingress.Enabled)
+}
 
-	if !cr.Spec.Prometheus.Enabled || !cr.Spec.Prometheus.Ingress.Enabled {
-		return nil // Prometheus itself or Ingress not enabled, move along...
-	}
+// newPrometheusIngress returns the desired Prometheus Ingress for the given ArgoCD.
+func newPrometheusIngress(cr *argoproj.ArgoCD) *networkingv1.Ingress {
+	ingress := newIngressWithSuffix("prometheus", cr)
 
 	// Add default annotations
 	atns := make(map[string]string)
@@ -385,26 +405,21 @@ func (r *ReconcileArgoCD) reconcilePrometheusIngress(cr *argoproj.ArgoCD) error
 		ingress.Spec.TLS = cr.Spec.Prometheus.Ingress.TLS
 	}
 
-	if err := controllerutil.SetControllerReference(cr, ingress, r.Scheme); err != nil {
-		return err
-	}
-	return r.Client.Create(context.TODO(), ingress)
+	return ingress
 }
 
-// reconcileApplicationSetControllerIngress will ensure that the ApplicationSetController Ingress is present.
+// reconcileApplicationSetControllerIngress will ensure that the ApplicationSetController Ingress is present and up to date.
 func (r *ReconcileArgoCD) reconcileApplicationSetControllerIngress(cr *argoproj.ArgoCD) error {
-	ingress := newIngressWithSuffix(common.ApplicationSetServiceNameSuffix, cr)
-	if argoutil.IsObjectFound(r.Client, cr.Namespace, ingress.Name, ingress) {
-		if cr.Spec.ApplicationSet == nil || !cr.Spec.ApplicationSet.WebhookServer.Ingress.Enabled {
-			return r.Client.Delete(context.TODO(), ingress)
-		}
-		return nil // Ingress found and enabled, do nothing
+	enabled := cr.Spec.ApplicationSet != nil && cr.Spec.ApplicationSet.WebhookServer.Ingress.Enabled
+	if !enabled {
+		return r.reconcileIngress(cr, newIngressWithSuffix(common.ApplicationSetServiceNameSuffix, cr), false)
 	}
+	return r.reconcileIngress(cr, newApplicationSetControllerIngress(cr), true)
+}
 
-	if cr.Spec.ApplicationSet == nil || !cr.Spec.ApplicationSet.WebhookServer.Ingress.Enabled {
-		log.Info("not enabled")
-		return nil // Ingress not enabled, move along...
-	}
+// newApplicationSetControllerIngress returns the desired ApplicationSetController Ingress for the given ArgoCD.
+func newApplicationSetControllerIngress(cr *argoproj.ArgoCD) *networkingv1.Ingress {
+	ingress := newIngressWithSuffix(common.ApplicationSetServiceNameSuffix, cr)
 
 	// Add annotations
 	atns := make(map[string]string)
@@ -449,8 +464,5 @@ func (r *ReconcileArgoCD) reconcileApplicationSetControllerIngress(cr *argoproj.
 		ingress.Spec.TLS = cr.Spec.ApplicationSet.WebhookServer.Ingress.TLS
 	}
 
-	if err := controllerutil.SetControllerReference(cr, ingress, r.Scheme); err != nil {
-		return err
-	}
-	return r.Client.Create(context.TODO(), ingress)
+	return ingress
 }
@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
 	networkingv1 "k8s.io/api/networking/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
@@ -232,3 +233,168 @@ func TestReconcileApplicationSetService_Ingress(t *testing.T) {
 	assert.NoError(t, r.reconcileApplicationSetControllerIngress(a))
 	assert.NoError(t, r.Client.Get(context.TODO(), types.NamespacedName{Namespace: ingress.Namespace, Name: ingress.Name}, ingress))
 }
+
+func TestReconcileArgoCD_reconcile_ServerIngress_drift(t *testing.T) {
+	logf.SetLogger(ZapLogger(true))
+	a := makeTestArgoCD(func(a *argoproj.ArgoCD) {
+		a.Spec.Server.Ingress.Enabled = true
+	})
+
+	resObjs := []client.Object{a}
+	subresObjs := []client.Object{a}
+	runtimeObjs := []runtime.Object{}
+	sch := makeTestReconcilerScheme(argoproj.AddToScheme)
+	cl := makeTestReconcilerClient(sch, resObjs, subresObjs, runtimeObjs)
+	r := makeTestReconciler(cl, sch)
+
+	assert.NoError(t, r.reconcileArgoServerIngress(a))
+	key := types.NamespacedName{Name: "argocd-server", Namespace: testNamespace}
+	ingress := &networkingv1.Ingress{}
+	assert.NoError(t, r.Client.Get(context.TODO(), key, ingress))
+	resourceVersion := ingress.ResourceVersion
+
+	// nothing is updated when the Ingress matches the spec
+	assert.NoError(t, r.reconcileArgoServerIngress(a))
+	assert.NoError(t, r.Client.Get(context.TODO(), key, ingress))
+	assert.Equal(t, resourceVersion, ingress.ResourceVersion)
+
+	// changes of the spec are applied to the Ingress
+	nginx := "nginx"
+	a.Spec.Server.Host = "argocd.example.com"
+	a.Spec.Server.Ingress.Annotations = map[string]string{"example.com/annotation": "value"}
+	a.Spec.Server.Ingress.Path = "/argocd"
+	a.Spec.Server.Ingress.IngressClassName = &nginx
+	assert.NoError(t, r.reconcileArgoServerIngress(a))
+	assert.NoError(t, r.Client.Get(context.TODO(), key, ingress))
+	assert.Equal(t, "value", ingress.Annotations["example.com/annotation"])
+	assert.Equal(t, &nginx, ingress.Spec.IngressClassName)
+	assert.Equal(t, "argocd.example.com", ingress.Spec.Rules[0].Host)
+	assert.Equal(t, "/argocd", ingress.Spec.Rules[0].HTTP.Paths[0].Path)
+	assert.Equal(t, []string{"argocd.example.com"}, ingress.Spec.TLS[0].Hosts)
+
+	// manual changes are reverted, and annotations and labels added by others are kept
+	ingress.Annotations["example.com/annotation"] = "changed"
+	ingress.Annotations["cert-manager.io/cluster-issuer"] = "letsencrypt"
+	ingress.Labels["example.com/label"] = "value"
+	ingress.Spec.Rules[0].Host = "other.example.com"
+	ingress.Spec.TLS = nil
+	assert.NoError(t, r.Client.Update(context.TODO(), ingress))
+	assert.NoError(t, r.reconcileArgoServerIngress(a))
+	assert.NoError(t, r.Client.Get(context.TODO(), key, ingress))
+	assert.Equal(t, "value", ingress.Annotations["example.com/annotation"])
+	assert.Equal(t, "letsencrypt", ingress.Annotations["cert-manager.io/cluster-issuer"])
+	assert.Equal(t, "value", ingress.Labels["example.com/label"])
+	assert.Equal(t, "argocd.example.com", ingress.Spec.Rules[0].Host)
+	assert.Len(t, ingress.Spec.TLS, 1)
+
+	// the updates are recorded as events
+	events := &corev1.EventList{}
+	assert.NoError(t, r.Client.List(context.TODO(), events, client.InNamespace(testNamespace)))
+	assert.Len(t, events.Items, 2)
+	assert.Equal(t, "IngressUpdated", events.Items[0].Reason)
+	assert.Equal(t, "ArgoCD", events.Items[0].InvolvedObject.Kind)
+
+	// the spec of the ArgoCD is not changed by the updates
+	assert.Equal(t, map[string]string{"example.com/annotation": "value"}, a.Spec.Server.Ingress.Annotations)
+
+	// the Ingress is deleted once disabled
+	a.Spec.Server.Ingress.Enabled = false
+	assert.NoError(t, r.reconcileArgoServerIngress(a))
+	assert.Error(t, r.Client.Get(context.TODO(), key, ingress))
+}
+
+func TestReconcileArgoCD_reconcile_ServerIngress_removedAnnotations(t *testing.T) {
+	logf.SetLogger(ZapLogger(true))
+	a := makeTestArgoCD(func(a *argoproj.ArgoCD) {
+		a.Spec.Server.Ingress.Enabled = true
+	})
+
+	resObjs := []client.Object{a}
+	subresObjs := []client.Object{a}
+	runtimeObjs := []runtime.Object{}
+	sch := makeTestReconcilerScheme(argoproj.AddToScheme)
+	cl := makeTestReconcilerClient(sch, resObjs, subresObjs, runtimeObjs)
+	r := makeTestReconciler(cl, sch)
+
+	assert.NoError(t, r.reconcileArgoServerIngress(a))
+	key := types.NamespacedName{Name: "argocd-server", Namespace: testNamespace}
+	ingress := &networkingv1.Ingress{}
+	assert.NoError(t, r.Client.Get(context.TODO(), key, ingress))
+	assert.Equal(t, "true", ingress.Annotations[common.ArgoCDKeyIngressSSLRedirect])
+
+	ingress.Annotations["cert-manager.io/cluster-issuer"] = "letsencrypt"
+	assert.NoError(t, r.Client.Update(context.TODO(), ingress))
+
+	// the default annotations are removed once the spec overrides them
+	a.Spec.Server.Ingress.Annotations = map[string]string{
+		"example.com/first":  "value",
+		"example.com/second": "value",
+	}
+	assert.NoError(t, r.reconcileArgoServerIngress(a))
+	assert.NoError(t, r.Client.Get(context.TODO(), key, ingress))
+	assert.NotContains(t, ingress.Annotations, common.ArgoCDKeyIngressSSLRedirect)
+	assert.NotContains(t, ingress.Annotations, common.ArgoCDKeyIngressBackendProtocol)
+	assert.Equal(t, "value", ingress.Annotations["example.com/first"])
+	assert.Equal(t, "example.com/first,example.com/second", ingress.Annotations[common.ArgoCDManagedAnnotationsAnnotation])
+
+	// an annotation removed from the spec is removed from the Ingress, and the ones added by others are kept
+	delete(a.Spec.Server.Ingress.Annotations, "example.com/second")
+	assert.NoError(t, r.reconcileArgoServerIngress(a))
+	assert.NoError(t, r.Client.Get(context.TODO(), key, ingress))
+	assert.NotContains(t, ingress.Annotations, "example.com/second")
+	assert.Equal(t, "value", ingress.Annotations["example.com/first"])
+	assert.Equal(t, "letsencrypt", ingress.Annotations["cert-manager.io/cluster-issuer"])
+
+	// a label removed from the desired Ingress is removed as well
+	desired := newArgoServerIngress(a)
+	desired.Labels["example.com/label"] = "value"
+	assert.NoError(t, r.reconcileIngress(a, desired, true))
+	assert.NoError(t, r.Client.Get(context.TODO(), key, ingress))
+	assert.Equal(t, "value", ingress.Labels["example.com/label"])
+
+	assert.NoError(t, r.reconcileArgoServerIngress(a))
+	assert.NoError(t, r.Client.Get(context.TODO(), key, ingress))
+	assert.NotContains(t, ingress.Labels, "example.com/label")
+	assert.Equal(t, testArgoCDName+"-server", ingress.Labels[common.ArgoCDKeyName])
+}
+
+func TestReconcileArgoCD_reconcile_ServerIngress_defaultIngressClass(t *testing.T) {
+	logf.SetLogger(ZapLogger(true))
+	a := makeTestArgoCD(func(a *argoproj.ArgoCD) {
+		a.Spec.Server.Ingress.Enabled = true
+	})
+
+	// the API server sets the default IngressClass of the cluster on an Ingress created without a class
+	defaultClass := "nginx"
+	ingress := newArgoServerIngress(a)
+	ingress.Spec.IngressClassName = &defaultClass
+	setManagedMetadata(ingress)
+
+	resObjs := []client.Object{a, ingress}
+	subresObjs := []client.Object{a}
+	runtimeObjs := []runtime.Object{}
+	sch := makeTestReconcilerScheme(argoproj.AddToScheme)
+	cl := makeTestReconcilerClient(sch, resObjs, subresObjs, runtimeObjs)
+	r := makeTestReconciler(cl, sch)
+
+	key := types.NamespacedName{Name: "argocd-server", Namespace: testNamespace}
+	assert.NoError(t, r.Client.Get(context.TODO(), key, ingress))
+	resourceVersion := ingress.ResourceVersion
+
+	// the defaulted class is kept, and the Ingress is not reported as changed
+	assert.NoError(t, r.reconcileArgoServerIngress(a))
+	assert.NoError(t, r.Client.Get(context.TODO(), key, ingress))
+	assert.Equal(t, resourceVersion, ingress.ResourceVersion)
+	assert.Equal(t, &defaultClass, ingress.Spec.IngressClassName)
+
+	events := &corev1.EventList{}
+	assert.NoError(t, r.Client.List(context.TODO(), events, client.InNamespace(testNamespace)))
+	assert.Empty(t, events.Items)
+
+	// a class set in the spec still takes precedence
+	traefik := "traefik"
+	a.Spec.Server.Ingress.IngressClassName = &traefik
+	assert.NoError(t, r.reconcileArgoServerIngress(a))
+	assert.NoError(t, r.Client.Get(context.TODO(), key, ingress))
+	assert.Equal(t, &traefik, ingress.Spec.IngressClassName)
+}
@@ -21,6 +21,8 @@ import (
 	json "encoding/json"
 	"fmt"
 	"os"
+	"reflect"
+	"strings"
 
 	argoproj "github.com/argoproj-labs/argocd-operator/api/v1beta1"
 	"github.com/argoproj-labs/argocd-operator/common"
@@ -31,7 +33,6 @@ import (
 	oauthv1 "github.com/openshift/api/oauth/v1"
 	routev1 "github.com/openshift/api/route/v1"
 	template "github.com/openshift/api/template/v1"
-	oappsv1client "github.com/openshift/client-go/apps/clientset/versioned/typed/apps/v1"
 	oauthclient "github.com/openshift/client-go/oauth/clientset/versioned/typed/oauth/v1"
 	templatev1client "github.com/openshift/client-go/template/clientset/versioned/typed/template/v1"
 	"gopkg.in/yaml.v2"
@@ -46,13 +47,12 @@ import (
 	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/config"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 )
 
 const (
-	// SuccessResonse is returned when a realm is created in keycloak.
-	successResponse = "201 Created"
 	// ExpectedReplicas is used to identify the keycloak running status.
 	expectedReplicas int32 = 1
 	// ServingCertSecretName is a secret that holds the service certificate.
@@ -129,9 +129,9 @@ func getKeycloakContainerImage(cr *argoproj.ArgoCD) string {
 		defaultTag = true
 	}
 	if e := os.Getenv(common.ArgoCDKeycloakImageEnvName); e != "" && (defaultTag && defaultImg) {
-		return e
+		return argoutil.ReplaceImageRegistry(e, cr.Spec.ImageRegistry)
 	}
-	return argoutil.CombineImageTag(img, tag)
+	return argoutil.ReplaceImageRegistry(argoutil.CombineImageTag(img, tag), cr.Spec.ImageRegistry)
 }
 
 func getKeycloakConfigMapTemplate(ns string) *corev1.ConfigMap {
@@ -213,9 +213,9 @@ func getKeycloakContainer(cr *argoproj.ArgoCD) corev1.Container {
 	}
 
 	return corev1.Container{
-		Env:             proxyEnvVars(envVars...),
+		Env:             withKeycloakDatabaseEnv(proxyEnvVars(envVars...), cr),
 		Image:           getKeycloakContainerImage(cr),
-		ImagePullPolicy: "Always",
+		ImagePullPolicy: getImagePullPolicy(cr, "", corev1.PullAlways),
 		LivenessProbe: &corev1.Probe{
 			TimeoutSeconds: 240,
 			ProbeHandler: corev1.ProbeHandler{
@@ -277,9 +277,6 @@ func getKeycloakDeploymentConfigTemplate(cr *argoproj.ArgoCD) *appsv1.Deployment
 
 	dc := &appsv1.DeploymentConfig{
 		ObjectMeta: metav1.ObjectMeta{
-			Annotations: map[string]string{
-				"argocd.argoproj.io/realm-created": "false",
-			},
 			Labels:    map[string]string{"application": "${APPLICATION_NAME}"},
 			Name:      "${APPLICATION_NAME}",
 			Namespace: ns,
@@ -288,7 +285,7 @@ func getKeycloakDeploymentConfigTemplate(cr *argoproj.ArgoCD) *appsv1.Deployment
 					APIVersion: "argoproj.io/v1alpha1",
 					UID:        cr.UID,
 					Name:       cr.Name,
-					Controller: &controllerRef,
+					Controller: boolPtr(controllerRef),
 					Kind:       "ArgoCD",
 				},
 			},
@@ -322,7 +319,7 @@ func getKeycloakDeploymentConfigTemplate(cr *argoproj.ArgoCD) *appsv1.Deployment
 					Containers: []corev1.Container{
 						keycloakContainer,
 					},
-					TerminationGracePeriodSeconds: &graceTime,
+					TerminationGracePeriodSeconds: int64Ptr(graceTime),
 					Volumes: []corev1.Volume{
 						{
 							Name: "sso-x509-https-volume",
@@ -351,7 +348,8 @@ func getKeycloakDeploymentConfigTemplate(cr *argoproj.ArgoCD) *appsv1.Deployment
 							},
 						},
 					},
-					NodeSelector: common.DefaultNodeSelector(),
+					ImagePullSecrets: getImagePullSecrets(cr, nil),
+					NodeSelector:     common.DefaultNodeSelector(),
 				},
 			},
 			Triggers: appsv1.DeploymentTriggerPolicies{
@@ -596,9 +594,6 @@ func newKeycloakDeployment(cr *argoproj.ArgoCD) *k8sappsv1.Deployment {
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      defaultKeycloakIdentifier,
 			Namespace: cr.Namespace,
-			Annotations: map[string]string{
-				"argocd.argoproj.io/realm-created": "false",
-			},
 			Labels: map[string]string{
 				"app": defaultKeycloakIdentifier,
 			},
@@ -617,11 +612,12 @@ func newKeycloakDeployment(cr *argoproj.ArgoCD) *k8sappsv1.Deployment {
 					},
 				},
 				Spec: corev1.PodSpec{
+					ImagePullSecrets: getImagePullSecrets(cr, nil),
 					Containers: []corev1.Container{
 						{
 							Name:  defaultKeycloakIdentifier,
 							Image: getKeycloakContainerImage(cr),
-							Env:   proxyEnvVars(getKeycloakContainerEnv()...),
+							Env:   withKeycloakDatabaseEnv(proxyEnvVars(getKeycloakContainerEnv()...), cr),
 							Ports: []corev1.ContainerPort{
 								{Name: "http", ContainerPort: httpPort},
 								{Name: "https", ContainerPort: portTLS},
@@ -777,6 +773,7 @@ func (r *ReconcileArgoCD) prepareKeycloakConfig(cr *argoproj.ArgoCD) (*keycloakC
 		ArgoCDURL:          aRouteURL,
 		KeycloakServerCert: serverCert,
 		VerifyTLS:          tlsVerification,
+		ClientSecret:       r.getKeycloakClientSecret(cr),
 	}
 
 	return cfg, nil
@@ -823,6 +820,7 @@ func (r *ReconcileArgoCD) prepareKeycloakConfigForK8s(cr *argoproj.ArgoCD) (*key
 		KeycloakURL:   kIngURL,
 		ArgoCDURL:     aIngURL,
 		VerifyTLS:     false,
+		ClientSecret:  r.getKeycloakClientSecret(cr),
 	}
 
 	return cfg, nil
@@ -830,6 +828,16 @@ func (r *ReconcileArgoCD) prepareKeycloakConfigForK8s(cr *argoproj.ArgoCD) (*key
 
 // creates a keycloak realm configuration which when posted to keycloak using http client creates a keycloak realm.
 func createRealmConfig(cfg *keycloakConfig) ([]byte, error) {
+	json, err := json.Marshal(newKeycloakRealm(cfg))
+	if err != nil {
+		return nil, err
+	}
+
+	return json, nil
+}
+
+// newKeycloakRealm returns the desired representation of the keycloak realm for Argo CD.
+func newKeycloakRealm(cfg *keycloakConfig) *CustomKeycloakAPIRealm {
 
 	ks := &CustomKeycloakAPIRealm{
 		Realm:       keycloakRealm,
@@ -842,7 +850,7 @@ func createRealmConfig(cfg *keycloakConfig) ([]byte, error) {
 				RootURL:                 cfg.ArgoCDURL,
 				AdminURL:                cfg.ArgoCDURL,
 				ClientAuthenticatorType: "client-secret",
-				Secret:                  oAuthClientSecret,
+				Secret:                  cfg.ClientSecret,
 				RedirectUris: []string{fmt.Sprintf("%s/%s",
 					cfg.ArgoCDURL, "auth/callback")},
 				WebOrigins: []string{cfg.ArgoCDURL},
@@ -923,7 +931,7 @@ func createRealmConfig(cfg *keycloakConfig) ([]byte, error) {
 				ProviderID:  "openshift-v4",
 				Config: map[string]string{
 					"baseUrl":      baseURL,
-					"clientSecret": oAuthClientSecret,
+					"clientSecret": cfg.ClientSecret,
 					"clientId":     getOAuthClient(cfg.ArgoNamespace),
 					"defaultScope": "user:full",
 					"syncMode":     "FORCE",
@@ -944,12 +952,7 @@ func createRealmConfig(cfg *keycloakConfig) ([]byte, error) {
 		}
 	}
 
-	json, err := json.Marshal(ks)
-	if err != nil {
-		return nil, err
-	}
-
-	return json, nil
+	return ks
 }
 
 // Gets Keycloak Server cert. This cert is used to authenticate the api calls to the Keycloak service.
@@ -974,12 +977,24 @@ func (r *ReconcileArgoCD) getKCServerCert(cr *argoproj.ArgoCD) ([]byte, error) {
 	}
 }
 
+// getKeycloakClientSecret returns the secret of the argocd client of keycloak. The secret is kept in the argocd-secret
+// once keycloak is configured, so that it does not change when the operator restarts.
+func (r *ReconcileArgoCD) getKeycloakClientSecret(cr *argoproj.ArgoCD) string {
+	argoCDSecret := argoutil.NewSecretWithName(cr, common.ArgoCDSecretName)
+	if argoutil.IsObjectFound(r.Client, cr.Namespace, argoCDSecret.Name, argoCDSecret) {
+		if clientSecret := argoCDSecret.Data["oidc.keycloak.clientSecret"]; len(clientSecret) > 0 {
+			return string(clientSecret)
+		}
+	}
+	return oAuthClientSecret
+}
+
 func getOAuthClient(ns string) string {
 	return fmt.Sprintf("%s-%s", defaultKeycloakBrokerName, ns)
 }
 
 // Updates OIDC configuration for ArgoCD.
-func (r *ReconcileArgoCD) updateArgoCDConfiguration(cr *argoproj.ArgoCD, kRouteURL string) error {
+func (r *ReconcileArgoCD) updateArgoCDConfiguration(cr *argoproj.ArgoCD, kRouteURL, clientSecret string) error {
 
 	// Update the ArgoCD client secret for OIDC in argocd-secret.
 	argoCDSecret := &corev1.Secret{
@@ -996,7 +1011,7 @@ func (r *ReconcileArgoCD) updateArgoCDConfiguration(cr *argoproj.ArgoCD, kRouteU
 		return err
 	}
 
-	argoCDSecret.Data["oidc.keycloak.clientSecret"] = []byte(oAuthClientSecret)
+	argoCDSecret.Data["oidc.keycloak.clientSecret"] = []byte(clientSecret)
 	err = r.Client.Update(context.TODO(), argoCDSecret)
 	if err != nil {
 		log.Error(err, fmt.Sprintf("Error updating ArgoCD Secret for ArgoCD %s in namespace %s",
@@ -1015,7 +1030,7 @@ func (r *ReconcileArgoCD) updateArgoCDConfiguration(cr *argoproj.ArgoCD, kRouteU
 				Name:      getOAuthClient(cr.Namespace),
 				Namespace: cr.Namespace,
 			},
-			Secret: oAuthClientSecret,
+			Secret: clientSecret,
 			RedirectURIs: []string{fmt.Sprintf("%s/auth/realms/%s/broker/openshift-v4/endpoint",
 				kRouteURL, keycloakClient)},
 			GrantMethod: "prompt",
@@ -1026,7 +1041,8 @@ func (r *ReconcileArgoCD) updateArgoCDConfiguration(cr *argoproj.ArgoCD, kRouteU
 			return err
 		}
 
-		err = r.Client.Get(context.TODO(), types.NamespacedName{Name: oAuthClient.Name}, oAuthClient)
+		existingOAuthClient := &oauthv1.OAuthClient{}
+		err = r.Client.Get(context.TODO(), types.NamespacedName{Name: oAuthClient.Name}, existingOAuthClient)
 		if err != nil {
 			if errors.IsNotFound(err) {
 				err = r.Client.Create(context.TODO(), oAuthClient)
@@ -1034,6 +1050,15 @@ func (r *ReconcileArgoCD) updateArgoCDConfiguration(cr *argoproj.ArgoCD, kRouteU
 					return err
 				}
 			}
+		} else if existingOAuthClient.Secret != oAuthClient.Secret ||
+			!reflect.DeepEqual(existingOAuthClient.RedirectURIs, oAuthClient.RedirectURIs) {
+			// Keep the OAuthClient in sync with the openshift-v4 identity provider of the keycloak realm.
+			existingOAuthClient.Secret = oAuthClient.Secret
+			existingOAuthClient.RedirectURIs = oAuthClient.RedirectURIs
+			err = r.Client.Update(context.TODO(), existingOAuthClient)
+			if err != nil {
+				return err
+			}
 		}
 	}
 
@@ -1094,39 +1119,13 @@ func (r *ReconcileArgoCD) updateArgoCDConfiguration(cr *argoproj.ArgoCD, kRouteU
 	return nil
 }
 
-// HandleKeycloakPodDeletion resets the Realm Creation Status to false when keycloak pod is deleted.
-func handleKeycloakPodDeletion(dc *oappsv1.DeploymentConfig) error {
-	cfg, err := config.GetConfig()
-	if err != nil {
-		log.Error(err, "unable to get k8s config")
-		return err
-	}
-
-	// Initialize deployment config client.
-	dcClient, err := oappsv1client.NewForConfig(cfg)
-	if err != nil {
-		log.Error(err, fmt.Sprintf("unable to create apps client for Deployment config %s in namespace %s",
-			dc.Name, dc.Namespace))
-		return err
-	}
-
-	log.Info("Set the Realm Creation status annoation to false")
-	existingDC, err := dcClient.DeploymentConfigs(dc.Namespace).Get(context.TODO(), defaultKeycloakIdentifier, metav1.GetOptions{})
-	if err != nil {
-		return err
-	}
+func (r *ReconcileArgoCD) reconcileKeycloakConfiguration(cr *argoproj.ArgoCD) error {
 
-	existingDC.Annotations["argocd.argoproj.io/realm-created"] = "false"
-	_, err = dcClient.DeploymentConfigs(dc.Namespace).Update(context.TODO(), existingDC, metav1.UpdateOptions{})
-	if err != nil {
-		return err
+	// An external keycloak is only configured, nothing is installed.
+	if cr.Spec.SSO.Keycloak.IsExternal() {
+		return r.reconcileExternalKeycloak(cr)
 	}
 
-	return nil
-}
-
-func (r *ReconcileArgoCD) reconcileKeycloakConfiguration(cr *argoproj.ArgoCD) error {
-
 	// TemplateAPI is available, Install keycloak using openshift templates.
 	if IsTemplateAPIAvailable() {
 		err := r.reconcileKeycloakForOpenShift(cr)
@@ -1292,6 +1291,10 @@ func deleteKeycloakConfigForK8s(cr *argoproj.ArgoCD) error {
 // Installs and configures Keycloak for OpenShift
 func (r *ReconcileArgoCD) reconcileKeycloakForOpenShift(cr *argoproj.ArgoCD) error {
 
+	if err := r.reconcileKeycloakPostgreSQL(cr); err != nil {
+		return err
+	}
+
 	templateInstanceRef, err := newKeycloakTemplateInstance(cr)
 	if err != nil {
 		return err
@@ -1327,11 +1330,18 @@ func (r *ReconcileArgoCD) reconcileKeycloakForOpenShift(cr *argoproj.ArgoCD) err
 		log.Error(err, fmt.Sprintf("Keycloak Deployment not found or being created for ArgoCD %s in namespace %s",
 			cr.Name, cr.Namespace))
 	} else {
-		// Handle Image upgrades
+		// Handle Image upgrades and changes of the database options and credentials
+		changed := false
+		container := &existingDC.Spec.Template.Spec.Containers[0]
 		desiredImage := getKeycloakContainerImage(cr)
-		if existingDC.Spec.Template.Spec.Containers[0].Image != desiredImage {
-			existingDC.Spec.Template.Spec.Containers[0].Image = desiredImage
-
+		desiredEnv := withKeycloakDatabaseEnv(container.Env, cr)
+		if container.Image != desiredImage || !reflect.DeepEqual(container.Env, desiredEnv) {
+			container.Image = desiredImage
+			container.Env = desiredEnv
+			changed = true
+		}
+		updateKeycloakDatabaseChecksum(existingDC.Spec.Template, r.getKeycloakDatabaseChecksum(cr), &changed)
+		if changed {
 			err = retry.RetryOnConflict(retry.DefaultBackoff, func() error {
 				return r.Client.Update(context.TODO(), existingDC)
 			})
@@ -1353,51 +1363,18 @@ func (r *ReconcileArgoCD) reconcileKeycloakForOpenShift(cr *argoproj.ArgoCD) err
 		// keycloakRouteURL is used to update the OIDC configuration for ArgoCD.
 		keycloakRouteURL := cfg.KeycloakURL
 
-		// If Keycloak deployment exists and a realm is already created for ArgoCD, Do not create a new one.
-		if existingDC.Annotations["argocd.argoproj.io/realm-created"] == "false" {
-
-			// Create a keycloak realm and publish.
-			response, err := createRealm(cfg)
-			if err != nil {
-				log.Error(err, fmt.Sprintf("Failed posting keycloak realm configuration for ArgoCD %s in namespace %s",
-					cr.Name, cr.Namespace))
-				return err
-			}
-
-			if response == successResponse {
-				log.Info(fmt.Sprintf("Successfully created keycloak realm for ArgoCD %s in namespace %s",
-					cr.Name, cr.Namespace))
-
-				// TODO: Remove the deleteOAuthClient invocation once the issue is resolved.
-				// OAuthClient configuration does not get deleted from previous instances occasionally.
-				// It is safe to delete before updating the OIDC config.
-				// https://github.com/openshift/client-go/issues/209
-				err = deleteOAuthClient(cr)
-				if err != nil {
-					return err
-				}
-
-				// Update Realm creation. This will avoid posting of realm configuration on further reconciliations.
-				err = r.Client.Get(context.TODO(), types.NamespacedName{Name: existingDC.Name, Namespace: existingDC.Namespace}, existingDC)
-				if err != nil {
-					return err
-				}
-
-				existingDC.Annotations["argocd.argoproj.io/realm-created"] = "true"
-				err = retry.RetryOnConflict(retry.DefaultBackoff, func() error {
-					return r.Client.Update(context.TODO(), existingDC)
-				})
-
-				if err != nil {
-					return err
-				}
-
-			}
+		// Reconcile the keycloak realm, which creates it once keycloak is running, and corrects any drift from the
+		// desired configuration, such as a changed hostname of Argo CD, afterwards.
+		err = reconcileKeycloakRealm(cfg)
+		if err != nil {
+			log.Error(err, fmt.Sprintf("Failed reconciling keycloak realm configuration for ArgoCD %s in namespace %s",
+				cr.Name, cr.Namespace))
+			return fmt.Errorf("failed to reconcile the keycloak realm %s: %w", keycloakRealm, err)
 		}
 
 		// Updates OIDC Configuration in the argocd-cm when Keycloak is initially configured
 		// or when user requests to update the OIDC configuration through `.spec.sso.keycloak.rootCA`.
-		err = r.updateArgoCDConfiguration(cr, keycloakRouteURL)
+		err = r.updateArgoCDConfiguration(cr, keycloakRouteURL, cfg.ClientSecret)
 		if err != nil {
 			log.Error(err, fmt.Sprintf("Failed to update OIDC Configuration for ArgoCD %s in namespace %s",
 				cr.Name, cr.Namespace))
@@ -1411,6 +1388,10 @@ func (r *ReconcileArgoCD) reconcileKeycloakForOpenShift(cr *argoproj.ArgoCD) err
 // Installs and configures Keycloak for Kubernetes
 func (r *ReconcileArgoCD) reconcileKeycloak(cr *argoproj.ArgoCD) error {
 
+	if err := r.reconcileKeycloakPostgreSQL(cr); err != nil {
+		return err
+	}
+
 	err := r.newKeycloakInstance(cr)
 	if err != nil {
 		log.Error(err, fmt.Sprintf("Failed creating keycloak instance for ArgoCD %s in Namespace %s",
@@ -1430,11 +1411,18 @@ func (r *ReconcileArgoCD) reconcileKeycloak(cr *argoproj.ArgoCD) error {
 		log.Error(err, fmt.Sprintf("Keycloak Deployment not found or being created for ArgoCD %s in namespace %s",
 			cr.Name, cr.Namespace))
 	} else {
-		// Handle Image upgrades
+		// Handle Image upgrades and changes of the database options and credentials
+		changed := false
+		container := &existingDeployment.Spec.Template.Spec.Containers[0]
 		desiredImage := getKeycloakContainerImage(cr)
-		if existingDeployment.Spec.Template.Spec.Containers[0].Image != desiredImage {
-			existingDeployment.Spec.Template.Spec.Containers[0].Image = desiredImage
-
+		desiredEnv := withKeycloakDatabaseEnv(container.Env, cr)
+		if container.Image != desiredImage || !reflect.DeepEqual(container.Env, desiredEnv) {
+			container.Image = desiredImage
+			container.Env = desiredEnv
+			changed = true
+		}
+		updateKeycloakDatabaseChecksum(&existingDeployment.Spec.Template, r.getKeycloakDatabaseChecksum(cr), &changed)
+		if changed {
 			err = retry.RetryOnConflict(retry.DefaultBackoff, func() error {
 				return r.Client.Update(context.TODO(), existingDeployment)
 			})
@@ -1456,31 +1444,18 @@ func (r *ReconcileArgoCD) reconcileKeycloak(cr *argoproj.ArgoCD) error {
 		// kIngURL is used to update the OIDC configuration for ArgoCD.
 		kIngURL := cfg.KeycloakURL
 
-		// If Keycloak deployment exists and a realm is already created for ArgoCD, Do not create a new one.
-		if existingDeployment.Annotations["argocd.argoproj.io/realm-created"] == "false" {
-			// Create a keycloak realm and publish.
-			response, err := createRealm(cfg)
-			if err != nil {
-				log.Error(err, fmt.Sprintf("Failed posting keycloak realm configuration for ArgoCD %s in namespace %s",
-					cr.Name, cr.Namespace))
-				return err
-			}
-
-			if response == successResponse {
-				log.Info("Successfully created keycloak realm for ArgoCD %s in namespace %s")
-
-				// Update Realm creation. This will avoid posting of realm configuration on further reconciliations.
-				existingDeployment.Annotations["argocd.argoproj.io/realm-created"] = "true"
-				err = r.Client.Update(context.TODO(), existingDeployment)
-				if err != nil {
-					return err
-				}
-			}
+		// Reconcile the keycloak realm, which creates it once keycloak is running, and corrects any drift from the
+		// desired configuration, such as a changed hostname of Argo CD, afterwards.
+		err = reconcileKeycloakRealm(cfg)
+		if err != nil {
+			log.Error(err, fmt.Sprintf("Failed reconciling keycloak realm configuration for ArgoCD %s in namespace %s",
+				cr.Name, cr.Namespace))
+			return fmt.Errorf("failed to reconcile the keycloak realm %s: %w", keycloakRealm, err)
 		}
 
 		// Updates OIDC Configuration in the argocd-cm when Keycloak is initially configured
 		// or when user requests to update the OIDC configuration through `.spec.sso.keycloak.rootCA`.
-		err = r.updateArgoCDConfiguration(cr, kIngURL)
+		err = r.updateArgoCDConfiguration(cr, kIngURL, cfg.ClientSecret)
 		if err != nil {
 			log.Error(err, fmt.Sprintf("Failed to update OIDC Configuration for ArgoCD %s in namespace %s",
 				cr.Name, cr.Namespace))
@@ -1490,3 +1465,136 @@ func (r *ReconcileArgoCD) reconcileKeycloak(cr *argoproj.ArgoCD) error {
 
 	return nil
 }
+
+// Configures an external Keycloak, of which only the realm for ArgoCD is managed.
+func (r *ReconcileArgoCD) reconcileExternalKeycloak(cr *argoproj.ArgoCD) error {
+
+	// Remove the keycloak instance, along with its database, installed before the external keycloak was configured.
+	if err := r.deleteKeycloakInstance(cr); err != nil {
+		return err
+	}
+	if err := r.reconcileKeycloakPostgreSQL(cr); err != nil {
+		return err
+	}
+
+	cfg, err := r.prepareExternalKeycloakConfig(cr)
+	if err != nil {
+		return err
+	}
+
+	// kURL is used to update the OIDC configuration for ArgoCD.
+	kURL := cfg.KeycloakURL
+
+	err = reconcileKeycloakRealm(cfg)
+	if err != nil {
+		log.Error(err, fmt.Sprintf("Failed reconciling keycloak realm configuration for ArgoCD %s in namespace %s",
+			cr.Name, cr.Namespace))
+		return fmt.Errorf("failed to reconcile the keycloak realm %s: %w", keycloakRealm, err)
+	}
+
+	err = r.updateArgoCDConfiguration(cr, kURL, cfg.ClientSecret)
+	if err != nil {
+		log.Error(err, fmt.Sprintf("Failed to update OIDC Configuration for ArgoCD %s in namespace %s",
+			cr.Name, cr.Namespace))
+		return err
+	}
+
+	return nil
+}
+
+// prepares a keycloak config which is used in creating keycloak realm configuration for an external keycloak.
+func (r *ReconcileArgoCD) prepareExternalKeycloakConfig(cr *argoproj.ArgoCD) (*keycloakConfig, error) {
+	external := cr.Spec.SSO.Keycloak.External
+
+	// Get the admin credentials of the external keycloak.
+	adminSecret := &corev1.Secret{}
+	err := r.Client.Get(context.TODO(), types.NamespacedName{Name: external.AdminCredentialsSecret.Name,
+		Namespace: cr.Namespace}, adminSecret)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the keycloak admin credentials secret %s: %w", external.AdminCredentialsSecret.Name, err)
+	}
+	for _, key := range []string{common.ArgoCDKeyUsername, common.ArgoCDKeyPassword} {
+		if len(adminSecret.Data[key]) == 0 {
+			return nil, fmt.Errorf("the keycloak admin credentials secret %s has no %s key", adminSecret.Name, key)
+		}
+	}
+
+	argoCDURL, err := r.getArgoCDServerURL(cr)
+	if err != nil {
+		return nil, err
+	}
+
+	var serverCert []byte
+	if cr.Spec.SSO.Keycloak.RootCA != "" {
+		serverCert = []byte(cr.Spec.SSO.Keycloak.RootCA)
+	}
+
+	cfg := &keycloakConfig{
+		ArgoName:           cr.Name,
+		ArgoNamespace:      cr.Namespace,
+		Username:           string(adminSecret.Data[common.ArgoCDKeyUsername]),
+		Password:           string(adminSecret.Data[common.ArgoCDKeyPassword]),
+		KeycloakURL:        strings.TrimSuffix(external.URL, "/"),
+		ArgoCDURL:          argoCDURL,
+		KeycloakServerCert: serverCert,
+		VerifyTLS:          cr.Spec.SSO.Keycloak.VerifyTLS == nil || *cr.Spec.SSO.Keycloak.VerifyTLS,
+		ClientSecret:       r.getKeycloakClientSecret(cr),
+		External:           true,
+	}
+
+	return cfg, nil
+}
+
+// getArgoCDServerURL returns the URL ArgoCD is served at, which is taken from the Route or the Ingress of the ArgoCD
+// server, or from the host of the ArgoCD server otherwise.
+func (r *ReconcileArgoCD) getArgoCDServerURL(cr *argoproj.ArgoCD) (string, error) {
+	name := fmt.Sprintf("%s-%s", cr.Name, "server")
+
+	if IsRouteAPIAvailable() {
+		route := &routev1.Route{}
+		if argoutil.IsObjectFound(r.Client, cr.Namespace, name, route) && route.Spec.Host != "" {
+			return fmt.Sprintf("https://%s", route.Spec.Host), nil
+		}
+	}
+
+	ing := &networkingv1.Ingress{}
+	if argoutil.IsObjectFound(r.Client, cr.Namespace, name, ing) && len(ing.Spec.Rules) > 0 && ing.Spec.Rules[0].Host != "" {
+		return fmt.Sprintf("https://%s", ing.Spec.Rules[0].Host), nil
+	}
+
+	if cr.Spec.Server.Host != "" {
+		return fmt.Sprintf("https://%s", cr.Spec.Server.Host), nil
+	}
+
+	return "", fmt.Errorf("the URL of the ArgoCD server %s is unknown, a route, an ingress or a host must be set for the ArgoCD server", name)
+}
+
+// deleteKeycloakInstance deletes the keycloak instance installed by the operator for the given ArgoCD, if any.
+func (r *ReconcileArgoCD) deleteKeycloakInstance(cr *argoproj.ArgoCD) error {
+	var objs []client.Object
+	if IsTemplateAPIAvailable() {
+		objs = []client.Object{&template.TemplateInstance{}}
+	} else {
+		objs = []client.Object{&k8sappsv1.Deployment{}, &corev1.Service{}, &networkingv1.Ingress{}}
+	}
+
+	// We use the foreground propagation policy to ensure that the garbage
+	// collector removes all instantiated objects before the TemplateInstance
+	// itself disappears.
+	foreground := metav1.DeletePropagationForeground
+	for _, obj := range objs {
+		name := defaultKeycloakIdentifier
+		if _, ok := obj.(*template.TemplateInstance); ok {
+			name = defaultTemplateIdentifier
+		}
+		if !argoutil.IsObjectFound(r.Client, cr.Namespace, name, obj) || !metav1.IsControlledBy(obj, cr) {
+			continue
+		}
+		log.Info(fmt.Sprintf("Delete keycloak instance %s for ArgoCD %s in namespace %s", name, cr.Name, cr.Namespace))
+		if err := r.Client.Delete(context.TODO(), obj, client.PropagationPolicy(foreground)); err != nil && !errors.IsNotFound(err) {
+			return err
+		}
+	}
+
+	return nil
+}
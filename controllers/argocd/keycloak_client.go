@@ -9,9 +9,13 @@ import (
 	"io"
 	"net/http"
 	"net/url"
+	"reflect"
+	"sort"
 	"strings"
 
 	"github.com/pkg/errors"
+
+	"github.com/argoproj-labs/argocd-operator/common"
 )
 
 type requester interface {
@@ -24,12 +28,20 @@ type httpclient struct {
 	token     string
 }
 
-// Creates a new realm for Keycloak.
-func createRealm(cfg *keycloakConfig) (string, error) {
+// errKeycloakNotFound is returned when a resource requested from the admin API of Keycloak does not exist.
+var errKeycloakNotFound = errors.New("not found")
+
+// keycloakSecretMask is returned by the admin API of Keycloak in place of the secrets it does not disclose.
+const keycloakSecretMask = "**********"
+
+// reconcileKeycloakRealm ensures that the realm for Argo CD in Keycloak, along with its client, client scopes and
+// identity providers, matches the given configuration. The realm is created when it does not exist, and any drift
+// is corrected through the admin API of Keycloak otherwise.
+func reconcileKeycloakRealm(cfg *keycloakConfig) error {
 
-	req, err := defaultRequester(cfg.KeycloakServerCert, cfg.VerifyTLS)
+	req, err := newKeycloakRequester(cfg)
 	if err != nil {
-		return "", err
+		return err
 	}
 
 	// create a new http client.
@@ -37,9 +49,12 @@ func createRealm(cfg *keycloakConfig) (string, error) {
 		requester: req,
 	}
 
-	kSvcName := h.getKeycloakURL(cfg.ArgoNamespace)
-	if kSvcName != "" {
-		cfg.KeycloakURL = kSvcName
+	// An external keycloak is only reachable at its configured URL.
+	if !cfg.External {
+		kSvcName := h.getKeycloakURL(cfg.ArgoNamespace)
+		if kSvcName != "" {
+			cfg.KeycloakURL = kSvcName
+		}
 	}
 
 	h.URL = cfg.KeycloakURL
@@ -47,19 +62,12 @@ func createRealm(cfg *keycloakConfig) (string, error) {
 	// login request updates the auth token for httpclient.
 	err = h.login(cfg.Username, cfg.Password)
 	if err != nil {
-		return "", err
+		return err
 	}
 	log.Info(fmt.Sprintf("Access Token for keycloak of ArgoCD %s in namespace %s generated successfully",
 		cfg.ArgoName, cfg.ArgoNamespace))
 
-	realmConfig, err := createRealmConfig(cfg)
-	if err != nil {
-		return "", err
-	}
-
-	status, _ := h.post(realmConfig)
-
-	return status, nil
+	return h.reconcileRealm(newKeycloakRealm(cfg))
 }
 
 // login requests a new auth token.
@@ -98,7 +106,7 @@ func (h *httpclient) login(user, pass string) error {
 	}
 
 	if tokenRes.Error != "" {
-		return err
+		return errors.Errorf("failed to log in to keycloak: %s", tokenRes.Error)
 	}
 
 	h.token = tokenRes.AccessToken
@@ -124,10 +132,397 @@ func (h *httpclient) post(realmConfig []byte) (string, error) {
 	if err != nil {
 		return "", err
 	}
+	_ = response.Body.Close()
+
+	if response.StatusCode != http.StatusCreated {
+		return response.Status, errors.Errorf("POST %s: unexpected response %s", realmURL, response.Status)
+	}
 
 	return response.Status, nil
 }
 
+// do sends an authenticated request, with the given value encoded as its JSON body, to the given path of the admin
+// API of Keycloak and returns the body of the response.
+func (h *httpclient) do(method, path string, in interface{}) ([]byte, error) {
+	var body io.Reader
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return nil, err
+		}
+		body = bytes.NewBuffer(data)
+	}
+
+	request, err := http.NewRequest(method, fmt.Sprintf("%s%s", h.URL, path), body)
+	if err != nil {
+		return nil, err
+	}
+
+	// set headers.
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Add("Authorization", fmt.Sprintf("Bearer %s", h.token))
+
+	response, err := h.requester.Do(request)
+	if err != nil {
+		return nil, err
+	}
+
+	defer response.Body.Close()
+	data, err := io.ReadAll(response.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case response.StatusCode == http.StatusNotFound:
+		return nil, errors.Wrapf(errKeycloakNotFound, "%s %s", method, path)
+	case response.StatusCode < 200 || response.StatusCode > 299:
+		return nil, errors.Errorf("%s %s: unexpected response %s", method, path, response.Status)
+	}
+
+	return data, nil
+}
+
+// get decodes the representation found at the given path of the admin API of Keycloak into out.
+func (h *httpclient) get(path string, out interface{}) error {
+	data, err := h.do(http.MethodGet, path, nil)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, out)
+}
+
+// create posts the given representation to the given path of the admin API of Keycloak.
+func (h *httpclient) create(path string, in interface{}) error {
+	_, err := h.do(http.MethodPost, path, in)
+	return err
+}
+
+// update puts the given representation to the given path of the admin API of Keycloak. The representation is
+// merged over the existing one, so that the fields the operator does not manage are kept as they are.
+func (h *httpclient) update(path string, desired interface{}) error {
+	existing := map[string]interface{}{}
+	if err := h.get(path, &existing); err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(desired)
+	if err != nil {
+		return err
+	}
+	overlay := map[string]interface{}{}
+	if err := json.Unmarshal(data, &overlay); err != nil {
+		return err
+	}
+	mergeRepresentation(existing, overlay)
+
+	_, err = h.do(http.MethodPut, path, existing)
+	return err
+}
+
+// reconcileRealm creates the given realm when it does not exist, and otherwise updates the parts of the realm that
+// have drifted from the given representation.
+func (h *httpclient) reconcileRealm(realm *CustomKeycloakAPIRealm) error {
+	path := fmt.Sprintf("%s/%s", realmURL, realm.Realm)
+
+	existing := &CustomKeycloakAPIRealm{}
+	err := h.get(path, existing)
+	if errors.Is(err, errKeycloakNotFound) {
+		log.Info(fmt.Sprintf("Creating keycloak realm %s", realm.Realm))
+		realmConfig, err := json.Marshal(realm)
+		if err != nil {
+			return err
+		}
+		_, err = h.post(realmConfig)
+		return err
+	}
+	if err != nil {
+		return err
+	}
+
+	if existing.Enabled != realm.Enabled || existing.SslRequired != realm.SslRequired {
+		log.Info(fmt.Sprintf("Updating keycloak realm %s", realm.Realm))
+		err = h.update(path, &CustomKeycloakAPIRealm{
+			Realm:       realm.Realm,
+			Enabled:     realm.Enabled,
+			SslRequired: realm.SslRequired,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := h.reconcileClientScopes(path, realm.ClientScopes); err != nil {
+		return err
+	}
+
+	for _, client := range realm.Clients {
+		if err := h.reconcileClient(path, client); err != nil {
+			return err
+		}
+	}
+
+	for _, idp := range realm.IdentityProviders {
+		if err := h.reconcileIdentityProvider(path, idp, realm.IdentityProviderMappers); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// reconcileClientScopes ensures that the given client scopes, and their protocol mappers, exist in the realm found at
+// the given path and match the given representations.
+func (h *httpclient) reconcileClientScopes(realmPath string, scopes []KeycloakClientScope) error {
+	path := fmt.Sprintf("%s/client-scopes", realmPath)
+
+	existing := []KeycloakClientScope{}
+	if err := h.get(path, &existing); err != nil {
+		return err
+	}
+
+	for _, scope := range scopes {
+		current := findClientScope(existing, scope.Name)
+		if current == nil {
+			log.Info(fmt.Sprintf("Creating keycloak client scope %s", scope.Name))
+			if err := h.create(path, scope); err != nil {
+				return err
+			}
+			continue
+		}
+
+		scopePath := fmt.Sprintf("%s/%s", path, current.ID)
+		if current.Protocol != scope.Protocol || configDrifted(current.Attributes, scope.Attributes) {
+			log.Info(fmt.Sprintf("Updating keycloak client scope %s", scope.Name))
+			desired := scope
+			desired.ID = current.ID
+			desired.ProtocolMappers = nil
+			if err := h.update(scopePath, &desired); err != nil {
+				return err
+			}
+		}
+
+		mappersPath := fmt.Sprintf("%s/protocol-mappers/models", scopePath)
+		for _, mapper := range scope.ProtocolMappers {
+			currentMapper := findProtocolMapper(current.ProtocolMappers, mapper.Name)
+			if currentMapper == nil {
+				log.Info(fmt.Sprintf("Creating keycloak protocol mapper %s of client scope %s", mapper.Name, scope.Name))
+				if err := h.create(mappersPath, mapper); err != nil {
+					return err
+				}
+				continue
+			}
+
+			if currentMapper.ProtocolMapper != mapper.ProtocolMapper || configDrifted(currentMapper.Config, mapper.Config) {
+				log.Info(fmt.Sprintf("Updating keycloak protocol mapper %s of client scope %s", mapper.Name, scope.Name))
+				desired := mapper
+				desired.ID = currentMapper.ID
+				if err := h.update(fmt.Sprintf("%s/%s", mappersPath, currentMapper.ID), &desired); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// reconcileClient ensures that the given client exists in the realm found at the given path, matches the given
+// representation and has the given default client scopes.
+func (h *httpclient) reconcileClient(realmPath string, client *KeycloakAPIClient) error {
+	path := fmt.Sprintf("%s/clients", realmPath)
+
+	existing := []KeycloakAPIClient{}
+	if err := h.get(fmt.Sprintf("%s?clientId=%s", path, url.QueryEscape(client.ClientID)), &existing); err != nil {
+		return err
+	}
+
+	if len(existing) == 0 {
+		log.Info(fmt.Sprintf("Creating keycloak client %s", client.ClientID))
+		return h.create(path, client)
+	}
+
+	current := existing[0]
+	clientPath := fmt.Sprintf("%s/%s", path, current.ID)
+	if clientDrifted(&current, client) {
+		log.Info(fmt.Sprintf("Updating keycloak client %s", client.ClientID))
+		desired := *client
+		desired.ID = current.ID
+		if err := h.update(clientPath, &desired); err != nil {
+			return err
+		}
+	}
+
+	// The default client scopes of an existing client are not updated along with its representation.
+	var scopes []KeycloakClientScope
+	for _, name := range client.DefaultClientScopes {
+		if containsString(current.DefaultClientScopes, name) {
+			continue
+		}
+		if scopes == nil {
+			if err := h.get(fmt.Sprintf("%s/client-scopes", realmPath), &scopes); err != nil {
+				return err
+			}
+		}
+
+		// Only the OpenID Connect scopes can be the default client scopes of the client.
+		scope := findClientScope(scopes, name)
+		if scope == nil || scope.Protocol != "openid-connect" {
+			continue
+		}
+
+		log.Info(fmt.Sprintf("Adding default client scope %s to keycloak client %s", name, client.ClientID))
+		if _, err := h.do(http.MethodPut, fmt.Sprintf("%s/default-client-scopes/%s", clientPath, scope.ID), nil); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// reconcileIdentityProvider ensures that the given identity provider, and the mappers of the given ones that belong to
+// it, exist in the realm found at the given path and match the given representations.
+func (h *httpclient) reconcileIdentityProvider(realmPath string, idp *KeycloakIdentityProvider, mappers []*KeycloakIdentityProviderMapper) error {
+	path := fmt.Sprintf("%s/identity-provider/instances", realmPath)
+	idpPath := fmt.Sprintf("%s/%s", path, idp.Alias)
+
+	current := &KeycloakIdentityProvider{}
+	err := h.get(idpPath, current)
+	switch {
+	case errors.Is(err, errKeycloakNotFound):
+		log.Info(fmt.Sprintf("Creating keycloak identity provider %s", idp.Alias))
+		if err := h.create(path, idp); err != nil {
+			return err
+		}
+	case err != nil:
+		return err
+	case current.DisplayName != idp.DisplayName || current.ProviderID != idp.ProviderID || configDrifted(current.Config, idp.Config):
+		log.Info(fmt.Sprintf("Updating keycloak identity provider %s", idp.Alias))
+		if err := h.update(idpPath, idp); err != nil {
+			return err
+		}
+	}
+
+	mappersPath := fmt.Sprintf("%s/mappers", idpPath)
+	existing := []KeycloakIdentityProviderMapper{}
+	if err := h.get(mappersPath, &existing); err != nil {
+		return err
+	}
+
+	for _, mapper := range mappers {
+		if mapper.IdentityProviderAlias != idp.Alias {
+			continue
+		}
+
+		currentMapper := findIdentityProviderMapper(existing, mapper.Name)
+		if currentMapper == nil {
+			log.Info(fmt.Sprintf("Creating keycloak mapper %s of identity provider %s", mapper.Name, idp.Alias))
+			if err := h.create(mappersPath, mapper); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if currentMapper.IdentityProviderMapper != mapper.IdentityProviderMapper || configDrifted(currentMapper.Config, mapper.Config) {
+			log.Info(fmt.Sprintf("Updating keycloak mapper %s of identity provider %s", mapper.Name, idp.Alias))
+			desired := *mapper
+			desired.ID = currentMapper.ID
+			if err := h.update(fmt.Sprintf("%s/%s", mappersPath, currentMapper.ID), &desired); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// clientDrifted returns true if the fields of the existing client that are managed by the operator differ from the
+// desired ones.
+func clientDrifted(existing, desired *KeycloakAPIClient) bool {
+	return existing.Name != desired.Name ||
+		existing.RootURL != desired.RootURL ||
+		existing.AdminURL != desired.AdminURL ||
+		existing.ClientAuthenticatorType != desired.ClientAuthenticatorType ||
+		(existing.Secret != desired.Secret && existing.Secret != keycloakSecretMask) ||
+		existing.StandardFlowEnabled != desired.StandardFlowEnabled ||
+		!sameStrings(existing.RedirectUris, desired.RedirectUris) ||
+		!sameStrings(existing.WebOrigins, desired.WebOrigins)
+}
+
+// configDrifted returns true if any of the desired config entries is missing from, or differs in, the existing
+// config. Entries that are not desired, such as the ones defaulted by Keycloak, are ignored.
+func configDrifted(existing, desired map[string]string) bool {
+	for key, value := range desired {
+		current, ok := existing[key]
+		if !ok || (current != value && current != keycloakSecretMask) {
+			return true
+		}
+	}
+	return false
+}
+
+// sameStrings returns true if both slices hold the same strings, regardless of their order.
+func sameStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	sortedA := append([]string{}, a...)
+	sortedB := append([]string{}, b...)
+	sort.Strings(sortedA)
+	sort.Strings(sortedB)
+	return reflect.DeepEqual(sortedA, sortedB)
+}
+
+// mergeRepresentation overlays the given desired representation over the existing one. Nested objects are merged
+// rather than replaced, so that the entries defaulted by Keycloak are kept.
+func mergeRepresentation(existing, desired map[string]interface{}) {
+	for key, value := range desired {
+		if desiredObject, ok := value.(map[string]interface{}); ok {
+			if existingObject, ok := existing[key].(map[string]interface{}); ok {
+				mergeRepresentation(existingObject, desiredObject)
+				continue
+			}
+		}
+		existing[key] = value
+	}
+}
+
+func findClientScope(scopes []KeycloakClientScope, name string) *KeycloakClientScope {
+	for i := range scopes {
+		if scopes[i].Name == name {
+			return &scopes[i]
+		}
+	}
+	return nil
+}
+
+func findProtocolMapper(mappers []KeycloakProtocolMapper, name string) *KeycloakProtocolMapper {
+	for i := range mappers {
+		if mappers[i].Name == name {
+			return &mappers[i]
+		}
+	}
+	return nil
+}
+
+func findIdentityProviderMapper(mappers []KeycloakIdentityProviderMapper, name string) *KeycloakIdentityProviderMapper {
+	for i := range mappers {
+		if mappers[i].Name == name {
+			return &mappers[i]
+		}
+	}
+	return nil
+}
+
+// newKeycloakRequester returns the client for requesting the admin API of the keycloak of the given configuration.
+// An external keycloak is verified against the system roots unless a root CA is given.
+func newKeycloakRequester(cfg *keycloakConfig) (requester, error) {
+	if cfg.External && cfg.KeycloakServerCert == nil && cfg.VerifyTLS {
+		return &http.Client{Timeout: common.ArgoCDDefaultKeycloakRequestTimeout}, nil
+	}
+	return defaultRequester(cfg.KeycloakServerCert, cfg.VerifyTLS)
+}
+
 // defaultRequester returns a default client for requesting http endpoints.
 func defaultRequester(serverCert []byte, verifyTLS bool) (requester, error) {
 	tlsConfig, err := createTLSConfig(serverCert, verifyTLS)
@@ -137,7 +532,7 @@ func defaultRequester(serverCert []byte, verifyTLS bool) (requester, error) {
 	transport := http.DefaultTransport.(*http.Transport).Clone()
 	transport.TLSClientConfig = tlsConfig
 
-	c := &http.Client{Transport: transport}
+	c := &http.Client{Transport: transport, Timeout: common.ArgoCDDefaultKeycloakRequestTimeout}
 	return c, nil
 }
 
@@ -1,6 +1,7 @@
 package argocd
 
 import (
+	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -9,6 +10,8 @@ import (
 
 	jsoniter "github.com/json-iterator/go"
 	"github.com/stretchr/testify/assert"
+
+	"github.com/argoproj-labs/argocd-operator/common"
 )
 
 func TestKeycloak_testRealmCreation(t *testing.T) {
@@ -65,6 +68,221 @@ func TestKeycloak_testLogin(t *testing.T) {
 	assert.Equal(t, h.token, "dummy")
 }
 
+func TestKeycloak_testLoginError(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.WriteHeader(401)
+		_, err := w.Write([]byte(`{"error":"invalid_grant"}`))
+		assert.NoError(t, err)
+	})
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	h := &httpclient{
+		requester: server.Client(),
+		URL:       server.URL,
+	}
+
+	assert.EqualError(t, h.login("dummy", "wrong"), "failed to log in to keycloak: invalid_grant")
+	assert.Empty(t, h.token)
+}
+
+// testKeycloakServer is a fake admin API of Keycloak. It serves the representations registered for each request URI,
+// and records the requests that create or update them.
+type testKeycloakServer struct {
+	resources map[string]interface{}
+	statuses  map[string]int
+	changes   []string
+	bodies    map[string]map[string]interface{}
+}
+
+func newTestKeycloakServer(t *testing.T, resources map[string]interface{}) (*testKeycloakServer, *httpclient) {
+	t.Helper()
+	ks := &testKeycloakServer{
+		resources: resources,
+		statuses:  map[string]int{},
+		bodies:    map[string]map[string]interface{}{},
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, "Bearer dummy", req.Header.Get("Authorization"))
+		request := req.Method + " " + req.URL.RequestURI()
+		if status, ok := ks.statuses[request]; ok {
+			w.WriteHeader(status)
+			return
+		}
+
+		switch req.Method {
+		case http.MethodGet:
+			resource, ok := ks.resources[req.URL.RequestURI()]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			assert.NoError(t, json.NewEncoder(w).Encode(resource))
+		case http.MethodPost:
+			ks.record(t, request, req)
+			w.WriteHeader(http.StatusCreated)
+		default:
+			ks.record(t, request, req)
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	return ks, &httpclient{
+		requester: server.Client(),
+		URL:       server.URL,
+		token:     "dummy",
+	}
+}
+
+func (ks *testKeycloakServer) record(t *testing.T, request string, req *http.Request) {
+	ks.changes = append(ks.changes, request)
+	body := map[string]interface{}{}
+	if req.ContentLength > 0 {
+		assert.NoError(t, json.NewDecoder(req.Body).Decode(&body))
+	}
+	ks.bodies[request] = body
+}
+
+func testKeycloakRealmConfig() *keycloakConfig {
+	return &keycloakConfig{
+		ArgoName:      "argocd",
+		ArgoNamespace: "argocd",
+		ArgoCDURL:     "https://argocd.example.com",
+		ClientSecret:  "s3cr3t",
+	}
+}
+
+func TestKeycloak_reconcileRealm_create(t *testing.T) {
+	ks, h := newTestKeycloakServer(t, map[string]interface{}{})
+
+	assert.NoError(t, h.reconcileRealm(newKeycloakRealm(testKeycloakRealmConfig())))
+
+	assert.Equal(t, []string{"POST " + realmURL}, ks.changes)
+	realm := ks.bodies["POST "+realmURL]
+	assert.Equal(t, keycloakRealm, realm["realm"])
+	client := realm["clients"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, []interface{}{"https://argocd.example.com/auth/callback"}, client["redirectUris"])
+	assert.Equal(t, "s3cr3t", client["secret"])
+}
+
+func TestKeycloak_reconcileRealm_inSync(t *testing.T) {
+	realm := newKeycloakRealm(testKeycloakRealmConfig())
+	scopes := append([]KeycloakClientScope{}, realm.ClientScopes...)
+	for i := range scopes {
+		scopes[i].ID = scopes[i].Name
+	}
+	client := *realm.Clients[0]
+	client.ID = "argocd-id"
+
+	ks, h := newTestKeycloakServer(t, map[string]interface{}{
+		realmURL + "/argocd":                         realm,
+		realmURL + "/argocd/client-scopes":           scopes,
+		realmURL + "/argocd/clients?clientId=argocd": []KeycloakAPIClient{client},
+	})
+
+	assert.NoError(t, h.reconcileRealm(realm))
+	assert.Empty(t, ks.changes)
+}
+
+func TestKeycloak_reconcileRealm_drift(t *testing.T) {
+	oldURL := "https://old.example.com"
+	client := map[string]interface{}{
+		"id":                      "argocd-id",
+		"clientId":                keycloakClient,
+		"name":                    keycloakClient,
+		"rootUrl":                 oldURL,
+		"adminUrl":                oldURL,
+		"clientAuthenticatorType": "client-secret",
+		"secret":                  "s3cr3t",
+		"redirectUris":            []string{oldURL + "/auth/callback"},
+		"webOrigins":              []string{oldURL},
+		"standardFlowEnabled":     true,
+		"consentRequired":         true,
+		"defaultClientScopes":     []string{"web-origins", "profile", "email"},
+	}
+	groupsMapper := map[string]interface{}{
+		"id":             "groups-mapper-id",
+		"name":           "groups",
+		"protocol":       "openid-connect",
+		"protocolMapper": "oidc-usermodel-attribute-mapper",
+		"config":         map[string]string{"claim.name": "roles", "jsonType.label": "String"},
+	}
+
+	ks, h := newTestKeycloakServer(t, map[string]interface{}{
+		realmURL + "/argocd": map[string]interface{}{"realm": "argocd", "enabled": true, "sslRequired": "external"},
+		realmURL + "/argocd/client-scopes": []interface{}{
+			map[string]interface{}{"id": "groups-id", "name": "groups", "protocol": "openid-connect",
+				"protocolMappers": []interface{}{groupsMapper}},
+			map[string]interface{}{"id": "profile-id", "name": "profile", "protocol": "openid-connect",
+				"attributes": map[string]string{"include.in.token.scope": "true", "display.on.consent.screen": "true"}},
+			map[string]interface{}{"id": "role_list-id", "name": "role_list", "protocol": "saml"},
+		},
+		realmURL + "/argocd/client-scopes/groups-id/protocol-mappers/models/groups-mapper-id": groupsMapper,
+		realmURL + "/argocd/clients?clientId=argocd":                                          []interface{}{client},
+		realmURL + "/argocd/clients/argocd-id":                                                client,
+	})
+
+	assert.NoError(t, h.reconcileRealm(newKeycloakRealm(testKeycloakRealmConfig())))
+
+	groupsMapperURL := "PUT " + realmURL + "/argocd/client-scopes/groups-id/protocol-mappers/models/groups-mapper-id"
+	clientURL := "PUT " + realmURL + "/argocd/clients/argocd-id"
+	assert.Equal(t, []string{
+		groupsMapperURL,
+		"POST " + realmURL + "/argocd/client-scopes",
+		clientURL,
+		"PUT " + realmURL + "/argocd/clients/argocd-id/default-client-scopes/groups-id",
+	}, ks.changes)
+
+	// the drifted entries are corrected, and the other ones are kept
+	assert.Equal(t, map[string]interface{}{
+		"aggregate.attrs":      "false",
+		"multivalued":          "true",
+		"userinfo.token.claim": "true",
+		"user.attribute":       "groups",
+		"id.token.claim":       "true",
+		"access.token.claim":   "true",
+		"claim.name":           "groups",
+		"jsonType.label":       "String",
+	}, ks.bodies[groupsMapperURL]["config"])
+	assert.Equal(t, "groups-mapper-id", ks.bodies[groupsMapperURL]["id"])
+	assert.Equal(t, "email", ks.bodies["POST "+realmURL+"/argocd/client-scopes"]["name"])
+	assert.Equal(t, []interface{}{"https://argocd.example.com/auth/callback"}, ks.bodies[clientURL]["redirectUris"])
+	assert.Equal(t, "https://argocd.example.com", ks.bodies[clientURL]["rootUrl"])
+	assert.Equal(t, true, ks.bodies[clientURL]["consentRequired"])
+}
+
+func TestKeycloak_reconcileRealm_error(t *testing.T) {
+	ks, h := newTestKeycloakServer(t, map[string]interface{}{
+		realmURL + "/argocd":               map[string]interface{}{"realm": "argocd", "enabled": true, "sslRequired": "external"},
+		realmURL + "/argocd/client-scopes": []interface{}{},
+	})
+	ks.statuses["POST "+realmURL+"/argocd/client-scopes"] = http.StatusForbidden
+
+	assert.EqualError(t, h.reconcileRealm(newKeycloakRealm(testKeycloakRealmConfig())),
+		"POST /auth/admin/realms/argocd/client-scopes: unexpected response 403 Forbidden")
+}
+
+func TestKeycloak_configDrifted(t *testing.T) {
+	tests := []struct {
+		name     string
+		existing map[string]string
+		desired  map[string]string
+		want     bool
+	}{
+		{"same config", map[string]string{"syncMode": "FORCE"}, map[string]string{"syncMode": "FORCE"}, false},
+		{"extra existing entries", map[string]string{"syncMode": "FORCE", "useJwksUrl": "true"}, map[string]string{"syncMode": "FORCE"}, false},
+		{"changed entry", map[string]string{"syncMode": "IMPORT"}, map[string]string{"syncMode": "FORCE"}, true},
+		{"missing entry", map[string]string{}, map[string]string{"syncMode": "FORCE"}, true},
+		{"masked secret", map[string]string{"clientSecret": keycloakSecretMask}, map[string]string{"clientSecret": "s3cr3t"}, false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.want, configDrifted(test.existing, test.desired))
+		})
+	}
+}
+
 func TestClient_useKeycloakServerCertificate(t *testing.T) {
 	var insecure bool
 	handler := http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
@@ -106,3 +324,16 @@ func TestClient_useKeycloakServerCertificate(t *testing.T) {
 	assert.Equal(t, resp.StatusCode, 200)
 
 }
+
+func TestKeycloak_newKeycloakRequester(t *testing.T) {
+	// an external keycloak is verified against the system roots
+	req, err := newKeycloakRequester(&keycloakConfig{External: true, VerifyTLS: true})
+	assert.NoError(t, err)
+	assert.Equal(t, &http.Client{Timeout: common.ArgoCDDefaultKeycloakRequestTimeout}, req)
+
+	// keycloak installed by the operator is not verified without its serving certificate
+	req, err = newKeycloakRequester(&keycloakConfig{VerifyTLS: true})
+	assert.NoError(t, err)
+	assert.True(t, req.(*http.Client).Transport.(*http.Transport).TLSClientConfig.InsecureSkipVerify)
+	assert.Equal(t, common.ArgoCDDefaultKeycloakRequestTimeout, req.(*http.Client).Timeout)
+}
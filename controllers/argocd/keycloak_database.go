@@ -0,0 +1,459 @@
+// Copyright 2023 ArgoCD Operator Developers
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package argocd
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+
+	"github.com/sethvargo/go-password/password"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	argoproj "github.com/argoproj-labs/argocd-operator/api/v1beta1"
+	"github.com/argoproj-labs/argocd-operator/common"
+	"github.com/argoproj-labs/argocd-operator/controllers/argoutil"
+)
+
+const (
+	// keycloakPostgreSQLName is the name of the PostgreSQL server deployed for Keycloak, of its Service and of the
+	// Secret holding its generated credentials.
+	keycloakPostgreSQLName = "keycloak-postgresql"
+
+	// keycloakPostgreSQLDataPath is the path of the data directory of the PostgreSQL server deployed for Keycloak.
+	keycloakPostgreSQLDataPath = "/var/lib/pgsql/data"
+)
+
+// keycloakDatabaseEnvNames holds the names of the environment variables configuring the database of Keycloak.
+var keycloakDatabaseEnvNames = map[string]bool{
+	"DB_VENDOR":                        true,
+	"DB_ADDR":                          true,
+	"DB_PORT":                          true,
+	"DB_DATABASE":                      true,
+	"DB_USER":                          true,
+	"DB_USERNAME":                      true,
+	"DB_PASSWORD":                      true,
+	"DB_SERVICE_PREFIX_MAPPING":        true,
+	"DB_JNDI":                          true,
+	"KEYCLOAK_POSTGRESQL_SERVICE_HOST": true,
+	"KEYCLOAK_POSTGRESQL_SERVICE_PORT": true,
+}
+
+// getKeycloakDatabase returns the database options of Keycloak for the given ArgoCD, or nil if Keycloak runs with
+// an ephemeral database.
+func getKeycloakDatabase(cr *argoproj.ArgoCD) *argoproj.ArgoCDKeycloakDatabaseSpec {
+	if cr.Spec.SSO == nil || cr.Spec.SSO.Keycloak == nil || cr.Spec.SSO.Keycloak.IsExternal() {
+		return nil
+	}
+	return cr.Spec.SSO.Keycloak.Database
+}
+
+// deploysKeycloakPostgreSQL returns true if the operator deploys a PostgreSQL server for the Keycloak of the given
+// ArgoCD.
+func deploysKeycloakPostgreSQL(cr *argoproj.ArgoCD) bool {
+	db := getKeycloakDatabase(cr)
+	return db != nil && !db.IsExternal()
+}
+
+// generatesKeycloakDatabaseCredentials returns true if the operator generates the credentials of the Keycloak
+// database of the given ArgoCD.
+func generatesKeycloakDatabaseCredentials(cr *argoproj.ArgoCD) bool {
+	return deploysKeycloakPostgreSQL(cr) && getKeycloakDatabase(cr).CredentialsSecret == nil
+}
+
+// getKeycloakDatabaseCredentialsSecretName returns the name of the Secret holding the credentials of the Keycloak
+// database of the given ArgoCD.
+func getKeycloakDatabaseCredentialsSecretName(cr *argoproj.ArgoCD) string {
+	if db := getKeycloakDatabase(cr); db != nil && db.CredentialsSecret != nil {
+		return db.CredentialsSecret.Name
+	}
+	return keycloakPostgreSQLName
+}
+
+// getKeycloakDatabaseName returns the name of the Keycloak database of the given ArgoCD.
+func getKeycloakDatabaseName(cr *argoproj.ArgoCD) string {
+	if db := getKeycloakDatabase(cr); db != nil && db.Name != "" {
+		return db.Name
+	}
+	return common.ArgoCDDefaultKeycloakDatabaseName
+}
+
+// getKeycloakDatabaseAddress returns the host and port of the PostgreSQL server of the Keycloak database of the
+// given ArgoCD.
+func getKeycloakDatabaseAddress(cr *argoproj.ArgoCD) (string, string) {
+	db := getKeycloakDatabase(cr)
+	if db != nil && db.IsExternal() {
+		port := common.ArgoCDDefaultKeycloakDatabasePort
+		if db.Port > 0 {
+			port = db.Port
+		}
+		return db.Host, strconv.Itoa(int(port))
+	}
+	return fmt.Sprintf("%s.%s.svc", keycloakPostgreSQLName, cr.Namespace), strconv.Itoa(int(common.ArgoCDDefaultKeycloakDatabasePort))
+}
+
+// getKeycloakDatabaseCredentialEnvVar returns the environment variable with the given name referencing the given key
+// of the Secret holding the credentials of the Keycloak database of the given ArgoCD.
+func getKeycloakDatabaseCredentialEnvVar(name, key string, cr *argoproj.ArgoCD) corev1.EnvVar {
+	return corev1.EnvVar{
+		Name: name,
+		ValueFrom: &corev1.EnvVarSource{
+			SecretKeyRef: &corev1.SecretKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: getKeycloakDatabaseCredentialsSecretName(cr)},
+				Key:                  key,
+			},
+		},
+	}
+}
+
+// getKeycloakDatabaseEnv returns the environment variables connecting the Keycloak of the given ArgoCD to its
+// PostgreSQL database, or nothing if Keycloak runs with an ephemeral database.
+func getKeycloakDatabaseEnv(cr *argoproj.ArgoCD) []corev1.EnvVar {
+	if getKeycloakDatabase(cr) == nil {
+		return nil
+	}
+
+	host, port := getKeycloakDatabaseAddress(cr)
+
+	// Red Hat Single Sign-On, which is installed on OpenShift, resolves the address of the database from the
+	// variables of the service named in the prefix mapping.
+	if IsTemplateAPIAvailable() {
+		return []corev1.EnvVar{
+			{Name: "DB_SERVICE_PREFIX_MAPPING", Value: fmt.Sprintf("%s=DB", keycloakPostgreSQLName)},
+			{Name: "DB_JNDI", Value: "java:jboss/datasources/KeycloakDS"},
+			{Name: "DB_DATABASE", Value: getKeycloakDatabaseName(cr)},
+			getKeycloakDatabaseCredentialEnvVar("DB_USERNAME", common.ArgoCDKeyUsername, cr),
+			getKeycloakDatabaseCredentialEnvVar("DB_PASSWORD", common.ArgoCDKeyPassword, cr),
+			{Name: "KEYCLOAK_POSTGRESQL_SERVICE_HOST", Value: host},
+			{Name: "KEYCLOAK_POSTGRESQL_SERVICE_PORT", Value: port},
+		}
+	}
+
+	return []corev1.EnvVar{
+		{Name: "DB_VENDOR", Value: "postgres"},
+		{Name: "DB_ADDR", Value: host},
+		{Name: "DB_PORT", Value: port},
+		{Name: "DB_DATABASE", Value: getKeycloakDatabaseName(cr)},
+		getKeycloakDatabaseCredentialEnvVar("DB_USER", common.ArgoCDKeyUsername, cr),
+		getKeycloakDatabaseCredentialEnvVar("DB_PASSWORD", common.ArgoCDKeyPassword, cr),
+	}
+}
+
+// withKeycloakDatabaseEnv returns the given environment variables of the Keycloak container, with the ones
+// configuring the database replaced by the ones of the given ArgoCD.
+func withKeycloakDatabaseEnv(env []corev1.EnvVar, cr *argoproj.ArgoCD) []corev1.EnvVar {
+	result := []corev1.EnvVar{}
+	for _, e := range env {
+		if !keycloakDatabaseEnvNames[e.Name] {
+			result = append(result, e)
+		}
+	}
+	return append(result, getKeycloakDatabaseEnv(cr)...)
+}
+
+// getKeycloakDatabaseChecksum returns the checksum of the credentials of the Keycloak database of the given ArgoCD, or
+// an empty string if Keycloak runs with an ephemeral database or the credentials are not found.
+func (r *ReconcileArgoCD) getKeycloakDatabaseChecksum(cr *argoproj.ArgoCD) string {
+	if getKeycloakDatabase(cr) == nil {
+		return ""
+	}
+	secret := argoutil.NewSecretWithName(cr, getKeycloakDatabaseCredentialsSecretName(cr))
+	if !argoutil.IsObjectFound(r.Client, cr.Namespace, secret.Name, secret) {
+		return ""
+	}
+	sum := sha256.New()
+	sum.Write(secret.Data[common.ArgoCDKeyUsername])
+	sum.Write(secret.Data[common.ArgoCDKeyPassword])
+	return fmt.Sprintf("%x", sum.Sum(nil))
+}
+
+// updateKeycloakDatabaseChecksum updates the checksum annotation of the credentials of the Keycloak database of the
+// given existing pod template when it differs from the given checksum, which rolls the pods out, and sets changed to
+// true if it was updated.
+func updateKeycloakDatabaseChecksum(existing *corev1.PodTemplateSpec, checksum string, changed *bool) {
+	if existing.Annotations[common.ArgoCDKeycloakDatabaseChecksumAnnotation] == checksum {
+		return
+	}
+	if existing.Annotations == nil {
+		existing.Annotations = map[string]string{}
+	}
+	existing.Annotations[common.ArgoCDKeycloakDatabaseChecksumAnnotation] = checksum
+	*changed = true
+}
+
+// getKeycloakPostgreSQLImage will return the container image of the PostgreSQL server deployed for Keycloak.
+//
+// The image is taken from the database options of Keycloak, from the `ARGOCD_KEYCLOAK_POSTGRESQL_IMAGE`
+// environment variable or from common.ArgoCDKeycloakPostgreSQLImage and common.ArgoCDKeycloakPostgreSQLVersion, in this
+// order of preference.
+func getKeycloakPostgreSQLImage(cr *argoproj.ArgoCD) string {
+	img := argoutil.CombineImageTag(common.ArgoCDKeycloakPostgreSQLImage, common.ArgoCDKeycloakPostgreSQLVersion)
+	if db := getKeycloakDatabase(cr); db != nil && db.Image != "" {
+		img = db.Image
+	} else if e := os.Getenv(common.ArgoCDKeycloakPostgreSQLImageEnvName); e != "" {
+		img = e
+	}
+	return argoutil.ReplaceImageRegistry(img, cr.Spec.ImageRegistry)
+}
+
+// generateKeycloakDatabasePassword will generate and return a password for the Keycloak database.
+func generateKeycloakDatabasePassword() ([]byte, error) {
+	pass, err := password.Generate(
+		common.ArgoCDDefaultKeycloakDatabasePasswordLength,
+		common.ArgoCDDefaultKeycloakDatabasePasswordNumDigits,
+		common.ArgoCDDefaultKeycloakDatabasePasswordNumSymbols,
+		false, false)
+
+	return []byte(pass), err
+}
+
+// newKeycloakPostgreSQLLabels returns the labels of the PostgreSQL server deployed for Keycloak.
+func newKeycloakPostgreSQLLabels() map[string]string {
+	return map[string]string{
+		"app": keycloakPostgreSQLName,
+	}
+}
+
+// newKeycloakPostgreSQLService returns the Service of the PostgreSQL server deployed for Keycloak.
+func newKeycloakPostgreSQLService(cr *argoproj.ArgoCD) *corev1.Service {
+	return &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      keycloakPostgreSQLName,
+			Namespace: cr.Namespace,
+			Labels:    newKeycloakPostgreSQLLabels(),
+		},
+		Spec: corev1.ServiceSpec{
+			Selector: newKeycloakPostgreSQLLabels(),
+			Ports: []corev1.ServicePort{
+				{
+					Name:       "postgresql",
+					Port:       common.ArgoCDDefaultKeycloakDatabasePort,
+					Protocol:   corev1.ProtocolTCP,
+					TargetPort: intstr.FromInt(int(common.ArgoCDDefaultKeycloakDatabasePort)),
+				},
+			},
+		},
+	}
+}
+
+// newKeycloakPostgreSQLStatefulSet returns the StatefulSet of the PostgreSQL server deployed for Keycloak.
+func newKeycloakPostgreSQLStatefulSet(cr *argoproj.ArgoCD) *appsv1.StatefulSet {
+	db := getKeycloakDatabase(cr)
+
+	size := resource.MustParse(common.ArgoCDDefaultKeycloakDatabaseSize)
+	var storageClassName *string
+	if db != nil {
+		if db.Size != nil {
+			size = *db.Size
+		}
+		storageClassName = db.StorageClassName
+	}
+
+	var replicas int32 = 1
+	return &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      keycloakPostgreSQLName,
+			Namespace: cr.Namespace,
+			Labels:    newKeycloakPostgreSQLLabels(),
+		},
+		Spec: appsv1.StatefulSetSpec{
+			Replicas:    &replicas,
+			ServiceName: keycloakPostgreSQLName,
+			Selector: &metav1.LabelSelector{
+				MatchLabels: newKeycloakPostgreSQLLabels(),
+			},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: newKeycloakPostgreSQLLabels(),
+				},
+				Spec: corev1.PodSpec{
+					ImagePullSecrets: getImagePullSecrets(cr, nil),
+					Containers: []corev1.Container{
+						{
+							Name:            "postgresql",
+							Image:           getKeycloakPostgreSQLImage(cr),
+							ImagePullPolicy: getImagePullPolicy(cr, "", corev1.PullIfNotPresent),
+							Env: []corev1.EnvVar{
+								getKeycloakDatabaseCredentialEnvVar("POSTGRESQL_USER", common.ArgoCDKeyUsername, cr),
+								getKeycloakDatabaseCredentialEnvVar("POSTGRESQL_PASSWORD", common.ArgoCDKeyPassword, cr),
+								{Name: "POSTGRESQL_DATABASE", Value: getKeycloakDatabaseName(cr)},
+							},
+							Ports: []corev1.ContainerPort{
+								{Name: "postgresql", ContainerPort: common.ArgoCDDefaultKeycloakDatabasePort},
+							},
+							ReadinessProbe: &corev1.Probe{
+								ProbeHandler: corev1.ProbeHandler{
+									Exec: &corev1.ExecAction{
+										Command: []string{"/usr/libexec/check-container"},
+									},
+								},
+								InitialDelaySeconds: 5,
+							},
+							LivenessProbe: &corev1.Probe{
+								ProbeHandler: corev1.ProbeHandler{
+									Exec: &corev1.ExecAction{
+										Command: []string{"/usr/libexec/check-container", "--live"},
+									},
+								},
+								InitialDelaySeconds: 120,
+							},
+							VolumeMounts: []corev1.VolumeMount{
+								{Name: "data", MountPath: keycloakPostgreSQLDataPath},
+							},
+						},
+					},
+				},
+			},
+			VolumeClaimTemplates: []corev1.PersistentVolumeClaim{
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "data",
+					},
+					Spec: corev1.PersistentVolumeClaimSpec{
+						AccessModes:      []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+						StorageClassName: storageClassName,
+						Resources: corev1.ResourceRequirements{
+							Requests: corev1.ResourceList{corev1.ResourceStorage: size},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// reconcileKeycloakDatabaseSecret will ensure that the credentials of the Keycloak database are generated when the
+// operator deploys a PostgreSQL server for Keycloak and no credentials secret is supplied.
+func (r *ReconcileArgoCD) reconcileKeycloakDatabaseSecret(cr *argoproj.ArgoCD) error {
+	secret := argoutil.NewSecretWithName(cr, keycloakPostgreSQLName)
+	found := argoutil.IsObjectFound(r.Client, cr.Namespace, secret.Name, secret)
+
+	if !generatesKeycloakDatabaseCredentials(cr) {
+		if found && metav1.IsControlledBy(secret, cr) {
+			log.Info("deleting the generated keycloak database credentials", "name", secret.Name, "namespace", secret.Namespace)
+			return r.Client.Delete(context.TODO(), secret)
+		}
+		return nil
+	}
+
+	if found {
+		// The password can be rotated by deleting its key. PostgreSQL and Keycloak are then rolled out, as the checksum
+		// of the credentials on their pod templates changes, and PostgreSQL sets the new password when it starts.
+		if len(secret.Data[common.ArgoCDKeyUsername]) > 0 && len(secret.Data[common.ArgoCDKeyPassword]) > 0 {
+			return nil
+		}
+		pass, err := generateKeycloakDatabasePassword()
+		if err != nil {
+			return err
+		}
+		if secret.Data == nil {
+			secret.Data = map[string][]byte{}
+		}
+		secret.Data[common.ArgoCDKeyUsername] = []byte(common.ArgoCDDefaultKeycloakDatabaseUsername)
+		secret.Data[common.ArgoCDKeyPassword] = pass
+		log.Info("generating new keycloak database credentials", "name", secret.Name, "namespace", secret.Namespace)
+		return r.Client.Update(context.TODO(), secret)
+	}
+
+	pass, err := generateKeycloakDatabasePassword()
+	if err != nil {
+		return err
+	}
+	secret.Data = map[string][]byte{
+		common.ArgoCDKeyUsername: []byte(common.ArgoCDDefaultKeycloakDatabaseUsername),
+		common.ArgoCDKeyPassword: pass,
+	}
+	if err := controllerutil.SetControllerReference(cr, secret, r.Scheme); err != nil {
+		return err
+	}
+	log.Info("creating the keycloak database credentials", "name", secret.Name, "namespace", secret.Namespace)
+	return r.Client.Create(context.TODO(), secret)
+}
+
+// reconcileKeycloakPostgreSQL will ensure that the PostgreSQL server of Keycloak, along with its credentials and
+// Service, is deployed when the Keycloak database is not hosted on an external PostgreSQL server, and removed
+// otherwise. The volume of the PostgreSQL server is kept when it is removed.
+func (r *ReconcileArgoCD) reconcileKeycloakPostgreSQL(cr *argoproj.ArgoCD) error {
+	if err := r.reconcileKeycloakDatabaseSecret(cr); err != nil {
+		return err
+	}
+
+	svc := newKeycloakPostgreSQLService(cr)
+	ss := newKeycloakPostgreSQLStatefulSet(cr)
+
+	if !deploysKeycloakPostgreSQL(cr) {
+		for _, obj := range []client.Object{ss, svc} {
+			if argoutil.IsObjectFound(r.Client, cr.Namespace, obj.GetName(), obj) && metav1.IsControlledBy(obj, cr) {
+				log.Info("deleting the keycloak postgresql server", "name", obj.GetName(), "namespace", obj.GetNamespace())
+				if err := r.Client.Delete(context.TODO(), obj); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}
+
+	if !argoutil.IsObjectFound(r.Client, cr.Namespace, svc.Name, svc) {
+		if err := controllerutil.SetControllerReference(cr, svc, r.Scheme); err != nil {
+			return err
+		}
+		log.Info("creating the keycloak postgresql service", "name", svc.Name, "namespace", svc.Namespace)
+		if err := r.Client.Create(context.TODO(), svc); err != nil {
+			return err
+		}
+	}
+
+	checksum := r.getKeycloakDatabaseChecksum(cr)
+	existing := &appsv1.StatefulSet{}
+	if !argoutil.IsObjectFound(r.Client, cr.Namespace, ss.Name, existing) {
+		ss.Spec.Template.Annotations = map[string]string{common.ArgoCDKeycloakDatabaseChecksumAnnotation: checksum}
+		if err := controllerutil.SetControllerReference(cr, ss, r.Scheme); err != nil {
+			return err
+		}
+		log.Info("creating the keycloak postgresql server", "name", ss.Name, "namespace", ss.Namespace)
+		return r.Client.Create(context.TODO(), ss)
+	}
+
+	// The volume claim templates of a StatefulSet cannot be updated, and only the fields of the container managed by
+	// the operator are compared, as the others are defaulted by the API server.
+	changed := false
+	desired := &ss.Spec.Template.Spec.Containers[0]
+	container := &existing.Spec.Template.Spec.Containers[0]
+	if container.Image != desired.Image {
+		container.Image = desired.Image
+		changed = true
+	}
+	if !reflect.DeepEqual(container.Env, desired.Env) {
+		container.Env = desired.Env
+		changed = true
+	}
+	updateImagePullOptions(&existing.Spec.Template.Spec, &ss.Spec.Template.Spec, &changed)
+	updateKeycloakDatabaseChecksum(&existing.Spec.Template, checksum, &changed)
+	if !changed {
+		return nil
+	}
+
+	log.Info("updating the keycloak postgresql server", "name", existing.Name, "namespace", existing.Namespace)
+	return r.Client.Update(context.TODO(), existing)
+}
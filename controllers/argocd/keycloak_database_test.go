@@ -0,0 +1,247 @@
+package argocd
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	argoproj "github.com/argoproj-labs/argocd-operator/api/v1beta1"
+	"github.com/argoproj-labs/argocd-operator/common"
+	"github.com/argoproj-labs/argocd-operator/controllers/argoutil"
+)
+
+func withKeycloakDatabase(db *argoproj.ArgoCDKeycloakDatabaseSpec) argoCDOpt {
+	return func(a *argoproj.ArgoCD) {
+		a.Spec.SSO = &argoproj.ArgoCDSSOSpec{
+			Provider: argoproj.SSOProviderTypeKeycloak,
+			Keycloak: &argoproj.ArgoCDKeycloakSpec{Database: db},
+		}
+	}
+}
+
+func TestGetKeycloakDatabaseEnv(t *testing.T) {
+	credential := func(name, secret, key string) corev1.EnvVar {
+		selector := testSecretKeySelector(secret, key)
+		return corev1.EnvVar{Name: name, ValueFrom: &corev1.EnvVarSource{SecretKeyRef: &selector}}
+	}
+
+	tests := []struct {
+		name             string
+		db               *argoproj.ArgoCDKeycloakDatabaseSpec
+		templateAPIFound bool
+		want             []corev1.EnvVar
+	}{
+		{"ephemeral database", nil, false, nil},
+		{"operator managed database", &argoproj.ArgoCDKeycloakDatabaseSpec{}, false, []corev1.EnvVar{
+			{Name: "DB_VENDOR", Value: "postgres"},
+			{Name: "DB_ADDR", Value: "keycloak-postgresql.argocd.svc"},
+			{Name: "DB_PORT", Value: "5432"},
+			{Name: "DB_DATABASE", Value: "keycloak"},
+			credential("DB_USER", "keycloak-postgresql", "username"),
+			credential("DB_PASSWORD", "keycloak-postgresql", "password"),
+		}},
+		{"external database", &argoproj.ArgoCDKeycloakDatabaseSpec{
+			Host:              "db.example.com",
+			Port:              5433,
+			Name:              "sso",
+			CredentialsSecret: &corev1.LocalObjectReference{Name: "sso-db"},
+		}, false, []corev1.EnvVar{
+			{Name: "DB_VENDOR", Value: "postgres"},
+			{Name: "DB_ADDR", Value: "db.example.com"},
+			{Name: "DB_PORT", Value: "5433"},
+			{Name: "DB_DATABASE", Value: "sso"},
+			credential("DB_USER", "sso-db", "username"),
+			credential("DB_PASSWORD", "sso-db", "password"),
+		}},
+		{"operator managed database on OpenShift", &argoproj.ArgoCDKeycloakDatabaseSpec{}, true, []corev1.EnvVar{
+			{Name: "DB_SERVICE_PREFIX_MAPPING", Value: "keycloak-postgresql=DB"},
+			{Name: "DB_JNDI", Value: "java:jboss/datasources/KeycloakDS"},
+			{Name: "DB_DATABASE", Value: "keycloak"},
+			credential("DB_USERNAME", "keycloak-postgresql", "username"),
+			credential("DB_PASSWORD", "keycloak-postgresql", "password"),
+			{Name: "KEYCLOAK_POSTGRESQL_SERVICE_HOST", Value: "keycloak-postgresql.argocd.svc"},
+			{Name: "KEYCLOAK_POSTGRESQL_SERVICE_PORT", Value: "5432"},
+		}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			templateAPIFound = test.templateAPIFound
+			defer removeTemplateAPI()
+
+			a := makeTestArgoCD(withKeycloakDatabase(test.db))
+			assert.Equal(t, test.want, getKeycloakDatabaseEnv(a))
+		})
+	}
+}
+
+func TestWithKeycloakDatabaseEnv(t *testing.T) {
+	env := []corev1.EnvVar{
+		{Name: "KEYCLOAK_USER", Value: "admin"},
+		{Name: "DB_VENDOR", Value: "h2"},
+		{Name: "DB_ADDR", Value: "stale"},
+	}
+
+	// the database variables are removed along with the database options
+	a := makeTestArgoCD(withKeycloakDatabase(nil))
+	assert.Equal(t, []corev1.EnvVar{{Name: "KEYCLOAK_USER", Value: "admin"}}, withKeycloakDatabaseEnv(env, a))
+
+	// and replaced otherwise
+	a = makeTestArgoCD(withKeycloakDatabase(&argoproj.ArgoCDKeycloakDatabaseSpec{}))
+	result := withKeycloakDatabaseEnv(env, a)
+	assert.Equal(t, append([]corev1.EnvVar{{Name: "KEYCLOAK_USER", Value: "admin"}}, getKeycloakDatabaseEnv(a)...), result)
+	assert.Equal(t, result, withKeycloakDatabaseEnv(result, a))
+}
+
+func TestReconcileArgoCD_reconcileKeycloakPostgreSQL(t *testing.T) {
+	logf.SetLogger(ZapLogger(true))
+	t.Setenv(common.ArgoCDKeycloakPostgreSQLImageEnvName, "")
+	size := resource.MustParse("5Gi")
+	storageClass := "fast"
+	a := makeTestArgoCD(withKeycloakDatabase(&argoproj.ArgoCDKeycloakDatabaseSpec{
+		Size:             &size,
+		StorageClassName: &storageClass,
+	}))
+
+	resObjs := []client.Object{a}
+	sch := makeTestReconcilerScheme(argoproj.AddToScheme)
+	cl := makeTestReconcilerClient(sch, resObjs, resObjs, []runtime.Object{})
+	r := makeTestReconciler(cl, sch)
+
+	assert.NoError(t, r.reconcileKeycloakPostgreSQL(a))
+
+	key := types.NamespacedName{Name: keycloakPostgreSQLName, Namespace: testNamespace}
+	secret := &corev1.Secret{}
+	assert.NoError(t, r.Client.Get(context.TODO(), key, secret))
+	assert.Equal(t, "keycloak", string(secret.Data[common.ArgoCDKeyUsername]))
+	assert.Len(t, secret.Data[common.ArgoCDKeyPassword], common.ArgoCDDefaultKeycloakDatabasePasswordLength)
+	assert.NoError(t, r.Client.Get(context.TODO(), key, &corev1.Service{}))
+
+	ss := &appsv1.StatefulSet{}
+	assert.NoError(t, r.Client.Get(context.TODO(), key, ss))
+	assert.Equal(t, argoutil.CombineImageTag(common.ArgoCDKeycloakPostgreSQLImage, common.ArgoCDKeycloakPostgreSQLVersion),
+		ss.Spec.Template.Spec.Containers[0].Image)
+	claim := ss.Spec.VolumeClaimTemplates[0]
+	assert.Equal(t, size, claim.Spec.Resources.Requests[corev1.ResourceStorage])
+	assert.Equal(t, &storageClass, claim.Spec.StorageClassName)
+
+	// the fields defaulted by the API server are kept
+	ss.Spec.Template.Spec.Containers[0].TerminationMessagePath = corev1.TerminationMessagePathDefault
+	ss.Spec.Template.Spec.Containers[0].ReadinessProbe.TimeoutSeconds = 1
+	ss.Spec.Template.Spec.Containers[0].Ports[0].Protocol = corev1.ProtocolTCP
+	assert.NoError(t, r.Client.Update(context.TODO(), ss))
+	resourceVersion := ss.ResourceVersion
+	assert.NoError(t, r.reconcileKeycloakPostgreSQL(a))
+	assert.NoError(t, r.Client.Get(context.TODO(), key, ss))
+	assert.Equal(t, resourceVersion, ss.ResourceVersion)
+
+	// the generated password is kept, and the image is updated
+	password := secret.Data[common.ArgoCDKeyPassword]
+	a.Spec.SSO.Keycloak.Database.Image = "postgres:15"
+	assert.NoError(t, r.reconcileKeycloakPostgreSQL(a))
+
+	assert.NoError(t, r.Client.Get(context.TODO(), key, secret))
+	assert.Equal(t, password, secret.Data[common.ArgoCDKeyPassword])
+	assert.NoError(t, r.Client.Get(context.TODO(), key, ss))
+	assert.Equal(t, "postgres:15", ss.Spec.Template.Spec.Containers[0].Image)
+
+	// the server and its credentials are removed once an external database is used
+	a.Spec.SSO.Keycloak.Database = &argoproj.ArgoCDKeycloakDatabaseSpec{
+		Host:              "db.example.com",
+		CredentialsSecret: &corev1.LocalObjectReference{Name: "sso-db"},
+	}
+	assert.NoError(t, r.reconcileKeycloakPostgreSQL(a))
+
+	for _, obj := range []client.Object{&corev1.Secret{}, &corev1.Service{}, &appsv1.StatefulSet{}} {
+		assert.True(t, errors.IsNotFound(r.Client.Get(context.TODO(), key, obj)))
+	}
+}
+
+func TestReconcileArgoCD_reconcileKeycloakPostgreSQL_credentialsSecret(t *testing.T) {
+	logf.SetLogger(ZapLogger(true))
+	a := makeTestArgoCD(withKeycloakDatabase(&argoproj.ArgoCDKeycloakDatabaseSpec{
+		CredentialsSecret: &corev1.LocalObjectReference{Name: "sso-db"},
+	}))
+
+	resObjs := []client.Object{a}
+	sch := makeTestReconcilerScheme(argoproj.AddToScheme)
+	cl := makeTestReconcilerClient(sch, resObjs, resObjs, []runtime.Object{})
+	r := makeTestReconciler(cl, sch)
+
+	assert.NoError(t, r.reconcileKeycloakPostgreSQL(a))
+
+	key := types.NamespacedName{Name: keycloakPostgreSQLName, Namespace: testNamespace}
+	assert.True(t, errors.IsNotFound(r.Client.Get(context.TODO(), key, &corev1.Secret{})))
+	ss := &appsv1.StatefulSet{}
+	assert.NoError(t, r.Client.Get(context.TODO(), key, ss))
+	assert.Equal(t, "sso-db", ss.Spec.Template.Spec.Containers[0].Env[0].ValueFrom.SecretKeyRef.Name)
+}
+
+func TestReconcileArgoCD_reconcileKeycloak_databaseEnv(t *testing.T) {
+	logf.SetLogger(ZapLogger(true))
+	a := makeTestArgoCD(withKeycloakDatabase(nil))
+
+	resObjs := []client.Object{a}
+	sch := makeTestReconcilerScheme(argoproj.AddToScheme)
+	cl := makeTestReconcilerClient(sch, resObjs, resObjs, []runtime.Object{})
+	r := makeTestReconciler(cl, sch)
+
+	assert.NoError(t, r.reconcileKeycloak(a))
+
+	deployment := &appsv1.Deployment{}
+	key := types.NamespacedName{Name: defaultKeycloakIdentifier, Namespace: testNamespace}
+	assert.NoError(t, r.Client.Get(context.TODO(), key, deployment))
+	assert.NotContains(t, deployment.Spec.Template.Spec.Containers[0].Env, corev1.EnvVar{Name: "DB_VENDOR", Value: "postgres"})
+
+	// keycloak is connected to the database once it is configured
+	a.Spec.SSO.Keycloak.Database = &argoproj.ArgoCDKeycloakDatabaseSpec{}
+	assert.NoError(t, r.reconcileKeycloak(a))
+
+	assert.NoError(t, r.Client.Get(context.TODO(), key, deployment))
+	assert.Contains(t, deployment.Spec.Template.Spec.Containers[0].Env, corev1.EnvVar{Name: "DB_VENDOR", Value: "postgres"})
+	assert.NoError(t, r.Client.Get(context.TODO(), types.NamespacedName{Name: keycloakPostgreSQLName, Namespace: testNamespace}, &appsv1.StatefulSet{}))
+}
+
+func TestReconcileArgoCD_reconcileKeycloak_databasePasswordRotation(t *testing.T) {
+	logf.SetLogger(ZapLogger(true))
+	a := makeTestArgoCD(withKeycloakDatabase(&argoproj.ArgoCDKeycloakDatabaseSpec{}))
+
+	resObjs := []client.Object{a}
+	sch := makeTestReconcilerScheme(argoproj.AddToScheme)
+	cl := makeTestReconcilerClient(sch, resObjs, resObjs, []runtime.Object{})
+	r := makeTestReconciler(cl, sch)
+
+	// the pods of PostgreSQL and Keycloak carry the checksum of the database credentials
+	assert.NoError(t, r.reconcileKeycloak(a))
+
+	dbKey := types.NamespacedName{Name: keycloakPostgreSQLName, Namespace: testNamespace}
+	keycloakKey := types.NamespacedName{Name: defaultKeycloakIdentifier, Namespace: testNamespace}
+	ss := &appsv1.StatefulSet{}
+	deployment := &appsv1.Deployment{}
+	assert.NoError(t, r.Client.Get(context.TODO(), dbKey, ss))
+	assert.NoError(t, r.Client.Get(context.TODO(), keycloakKey, deployment))
+	checksum := ss.Spec.Template.Annotations[common.ArgoCDKeycloakDatabaseChecksumAnnotation]
+	assert.NotEmpty(t, checksum)
+	assert.Equal(t, checksum, deployment.Spec.Template.Annotations[common.ArgoCDKeycloakDatabaseChecksumAnnotation])
+
+	// both are rolled out once the password is rotated
+	secret := &corev1.Secret{}
+	assert.NoError(t, r.Client.Get(context.TODO(), dbKey, secret))
+	delete(secret.Data, common.ArgoCDKeyPassword)
+	assert.NoError(t, r.Client.Update(context.TODO(), secret))
+	assert.NoError(t, r.reconcileKeycloak(a))
+
+	assert.NoError(t, r.Client.Get(context.TODO(), dbKey, ss))
+	assert.NoError(t, r.Client.Get(context.TODO(), keycloakKey, deployment))
+	rotated := ss.Spec.Template.Annotations[common.ArgoCDKeycloakDatabaseChecksumAnnotation]
+	assert.NotEqual(t, checksum, rotated)
+	assert.Equal(t, rotated, deployment.Spec.Template.Annotations[common.ArgoCDKeycloakDatabaseChecksumAnnotation])
+}
@@ -24,11 +24,16 @@ import (
 	routev1 "github.com/openshift/api/route/v1"
 	templatev1 "github.com/openshift/api/template/v1"
 	"github.com/stretchr/testify/assert"
+	k8sappsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
 	resourcev1 "k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
 
 	argoproj "github.com/argoproj-labs/argocd-operator/api/v1beta1"
 	"github.com/argoproj-labs/argocd-operator/common"
@@ -495,6 +500,98 @@ func TestKeycloak_NodeLabelSelector(t *testing.T) {
 	assert.Equal(t, dc.Spec.Template.Spec.Tolerations, a.Spec.NodePlacement.Tolerations)
 }
 
+func withExternalKeycloak(url string) argoCDOpt {
+	return func(a *argoproj.ArgoCD) {
+		a.Spec.SSO = &argoproj.ArgoCDSSOSpec{
+			Provider: argoproj.SSOProviderTypeKeycloak,
+			Keycloak: &argoproj.ArgoCDKeycloakSpec{
+				External: &argoproj.ArgoCDKeycloakExternalSpec{
+					URL:                    url,
+					AdminCredentialsSecret: corev1.LocalObjectReference{Name: "keycloak-admin"},
+				},
+			},
+		}
+	}
+}
+
+func TestKeycloak_prepareExternalKeycloakConfig(t *testing.T) {
+	a := makeTestArgoCD(withExternalKeycloak("https://sso.example.com/"), func(a *argoproj.ArgoCD) {
+		a.Spec.Server.Host = "argocd.example.com"
+		a.Spec.SSO.Keycloak.RootCA = "---CERTIFICATE---"
+	})
+	adminSecret := argoutil.NewSecretWithName(a, "keycloak-admin")
+	adminSecret.Data = map[string][]byte{"username": []byte("admin")}
+
+	resObjs := []client.Object{a, adminSecret}
+	sch := makeTestReconcilerScheme(argoproj.AddToScheme)
+	cl := makeTestReconcilerClient(sch, resObjs, resObjs, []runtime.Object{})
+	r := makeTestReconciler(cl, sch)
+
+	_, err := r.prepareExternalKeycloakConfig(a)
+	assert.EqualError(t, err, "the keycloak admin credentials secret keycloak-admin has no password key")
+
+	adminSecret.Data["password"] = []byte("s3cr3t")
+	assert.NoError(t, r.Client.Update(context.TODO(), adminSecret))
+
+	cfg, err := r.prepareExternalKeycloakConfig(a)
+	assert.NoError(t, err)
+	assert.Equal(t, &keycloakConfig{
+		ArgoName:           a.Name,
+		ArgoNamespace:      a.Namespace,
+		Username:           "admin",
+		Password:           "s3cr3t",
+		KeycloakURL:        "https://sso.example.com",
+		ArgoCDURL:          "https://argocd.example.com",
+		KeycloakServerCert: []byte("---CERTIFICATE---"),
+		VerifyTLS:          true,
+		ClientSecret:       oAuthClientSecret,
+		External:           true,
+	}, cfg)
+
+	// the URL of Argo CD is unknown without a host
+	a.Spec.Server.Host = ""
+	_, err = r.prepareExternalKeycloakConfig(a)
+	assert.EqualError(t, err, "the URL of the ArgoCD server argocd-server is unknown, a route, an ingress or a host must be set for the ArgoCD server")
+}
+
+func TestKeycloak_reconcileExternalKeycloak_deletesKeycloakInstance(t *testing.T) {
+	logf.SetLogger(ZapLogger(true))
+	a := makeTestArgoCD(withKeycloakDatabase(&argoproj.ArgoCDKeycloakDatabaseSpec{}))
+
+	resObjs := []client.Object{a}
+	sch := makeTestReconcilerScheme(argoproj.AddToScheme)
+	cl := makeTestReconcilerClient(sch, resObjs, resObjs, []runtime.Object{})
+	r := makeTestReconciler(cl, sch)
+
+	assert.NoError(t, r.reconcileKeycloak(a))
+
+	// the external keycloak cannot be configured without admin credentials, but the keycloak instance and its
+	// database are removed first
+	withExternalKeycloak("https://sso.example.com")(a)
+	assert.EqualError(t, r.reconcileExternalKeycloak(a),
+		"failed to get the keycloak admin credentials secret keycloak-admin: secrets \"keycloak-admin\" not found")
+
+	for _, obj := range []client.Object{&k8sappsv1.Deployment{}, &corev1.Service{}, &networkingv1.Ingress{}} {
+		err := r.Client.Get(context.TODO(), types.NamespacedName{Name: defaultKeycloakIdentifier, Namespace: testNamespace}, obj)
+		assert.True(t, errors.IsNotFound(err))
+	}
+	err := r.Client.Get(context.TODO(), types.NamespacedName{Name: keycloakPostgreSQLName, Namespace: testNamespace}, &k8sappsv1.StatefulSet{})
+	assert.True(t, errors.IsNotFound(err))
+}
+
+func TestReconcileArgoCD_reconcileStatusKeycloak_external(t *testing.T) {
+	logf.SetLogger(ZapLogger(true))
+	a := makeTestArgoCD(withExternalKeycloak("https://sso.example.com"))
+
+	resObjs := []client.Object{a}
+	sch := makeTestReconcilerScheme(argoproj.AddToScheme)
+	cl := makeTestReconcilerClient(sch, resObjs, resObjs, []runtime.Object{})
+	r := makeTestReconciler(cl, sch)
+
+	assert.NoError(t, r.reconcileStatusKeycloak(a))
+	assert.Equal(t, "Running", a.Status.SSO)
+}
+
 func removeTemplateAPI() {
 	templateAPIFound = false
 }
@@ -1,6 +1,9 @@
 package argocd
 
 type KeycloakAPIClient struct {
+	// Client internal ID.
+	// +optional
+	ID string `json:"id,omitempty"`
 	// Client ID.
 	// +kubebuilder:validation:Required
 	ClientID string `json:"clientId"`
@@ -104,6 +107,9 @@ type keycloakConfig struct {
 	ArgoCDURL          string
 	KeycloakServerCert []byte
 	VerifyTLS          bool
+	ClientSecret       string
+	// External is set when the keycloak is not installed by the operator.
+	External bool
 }
 
 type oidcConfig struct {
@@ -118,6 +124,9 @@ type oidcConfig struct {
 // KeycloakIdentityProviderMapper defines IdentityProvider Mappers
 // issue: https://github.com/keycloak/keycloak-operator/issues/471
 type KeycloakIdentityProviderMapper struct {
+	// Identity Provider Mapper ID.
+	// +optional
+	ID string `json:"id,omitempty"`
 	// Name
 	// +optional
 	Name string `json:"name,omitempty"`
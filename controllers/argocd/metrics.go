@@ -36,8 +36,18 @@ var (
 		Help:    "Length of time per reconciliation per instance",
 		Buckets: []float64{0.05, 0.075, 0.1, 0.15, 0.2, 0.22, 0.24, 0.26, 0.28, 0.3, 0.32, 0.34, 0.37, 0.4, 0.42, 0.44, 0.48, 0.5, 0.55, 0.6, 0.75, 0.9, 1.00},
 	}, []string{"namespace"})
+
+	// CertificateExpiryTime is a prometheus metric which keeps track of the expiry time of the CA and TLS
+	// certificates generated by the operator for a given instance
+	CertificateExpiryTime = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "argocd_certificate_expiry_timestamp_seconds",
+			Help: "Expiry time of the CA and TLS certificates of a given instance, in seconds since the epoch",
+		},
+		[]string{"namespace", "name", "certificate"},
+	)
 )
 
 func init() {
-	metrics.Registry.MustRegister(ActiveInstancesTotal, ActiveInstancesByPhase, ActiveInstanceReconciliationCount, ReconcileTime)
+	metrics.Registry.MustRegister(ActiveInstancesTotal, ActiveInstancesByPhase, ActiveInstanceReconciliationCount, ReconcileTime, CertificateExpiryTime)
 }
@@ -0,0 +1,255 @@
+// Copyright 2023 ArgoCD Operator Developers
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package argocd
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	argoproj "github.com/argoproj-labs/argocd-operator/api/v1beta1"
+	"github.com/argoproj-labs/argocd-operator/common"
+	"github.com/argoproj-labs/argocd-operator/controllers/argoutil"
+)
+
+const (
+	// networkPolicyDefaultDenySuffix is the name suffix of the NetworkPolicy denying the ingress traffic to the
+	// Argo CD components.
+	networkPolicyDefaultDenySuffix = "default-deny"
+
+	// networkPolicySuffix is the name suffix of the NetworkPolicies allowing the ingress traffic to an Argo CD component.
+	networkPolicySuffix = "network-policy"
+)
+
+// networkPolicyComponent is an Argo CD component whose ingress traffic is restricted by a NetworkPolicy.
+type networkPolicyComponent struct {
+	// selectorSuffix is the name suffix selecting the pods of the component.
+	selectorSuffix string
+
+	// enabled is true if the component is deployed for the ArgoCD.
+	enabled bool
+
+	// ingress is the ingress traffic allowed to the component.
+	ingress []networkingv1.NetworkPolicyIngressRule
+}
+
+// isNetworkPolicyEnabled returns true if the NetworkPolicies are enabled for the given ArgoCD.
+func isNetworkPolicyEnabled(cr *argoproj.ArgoCD) bool {
+	return cr.Spec.NetworkPolicy != nil && cr.Spec.NetworkPolicy.Enabled
+}
+
+// getNetworkPolicyComponents returns the Argo CD components of the given ArgoCD whose ingress traffic is restricted,
+// along with the traffic allowed to each of them.
+func getNetworkPolicyComponents(cr *argoproj.ArgoCD) []networkPolicyComponent {
+	// remote components are not deployed by the operator
+	redisEnabled := cr.Spec.Redis.IsEnabled() && (cr.Spec.Redis.Remote == nil || *cr.Spec.Redis.Remote == "")
+	repoEnabled := cr.Spec.Repo.IsEnabled() && (cr.Spec.Repo.Remote == nil || *cr.Spec.Repo.Remote == "")
+
+	ingressPeers := getNetworkPolicyIngressPeers(cr)
+	metricsPeers := getNetworkPolicyMetricsPeers(cr)
+	redisClients := networkPolicyPodPeers(cr, "server", "repo-server", "application-controller")
+
+	return []networkPolicyComponent{
+		{"server", cr.Spec.Server.IsEnabled(), []networkingv1.NetworkPolicyIngressRule{
+			networkPolicyIngressRule(ingressPeers, 8080),
+			networkPolicyIngressRule(metricsPeers, 8083),
+		}},
+		{"repo-server", repoEnabled, []networkingv1.NetworkPolicyIngressRule{
+			networkPolicyIngressRule(networkPolicyPodPeers(cr, "server", "application-controller",
+				"applicationset-controller", "notifications-controller"), common.ArgoCDDefaultRepoServerPort),
+			networkPolicyIngressRule(metricsPeers, common.ArgoCDDefaultRepoMetricsPort),
+		}},
+		{"application-controller", cr.Spec.Controller.IsEnabled(), []networkingv1.NetworkPolicyIngressRule{
+			networkPolicyIngressRule(metricsPeers, 8082),
+		}},
+		{"redis", redisEnabled && !cr.Spec.HA.Enabled, []networkingv1.NetworkPolicyIngressRule{
+			networkPolicyIngressRule(redisClients, common.ArgoCDDefaultRedisPort),
+		}},
+		{"redis-ha-haproxy", redisEnabled && cr.Spec.HA.Enabled, []networkingv1.NetworkPolicyIngressRule{
+			networkPolicyIngressRule(redisClients, common.ArgoCDDefaultRedisPort),
+		}},
+		{"redis-ha", redisEnabled && cr.Spec.HA.Enabled, []networkingv1.NetworkPolicyIngressRule{
+			networkPolicyIngressRule(networkPolicyPodPeers(cr, "redis-ha", "redis-ha-haproxy"),
+				common.ArgoCDDefaultRedisPort, common.ArgoCDDefaultRedisSentinelPort),
+		}},
+		{"dex-server", UseDex(cr), []networkingv1.NetworkPolicyIngressRule{
+			networkPolicyIngressRule(networkPolicyPodPeers(cr, "server"),
+				common.ArgoCDDefaultDexHTTPPort, common.ArgoCDDefaultDexGRPCPort),
+			networkPolicyIngressRule(metricsPeers, common.ArgoCDDefaultDexMetricsPort),
+		}},
+		{"applicationset-controller", cr.Spec.ApplicationSet != nil && cr.Spec.ApplicationSet.IsEnabled(), []networkingv1.NetworkPolicyIngressRule{
+			networkPolicyIngressRule(ingressPeers, 7000),
+			networkPolicyIngressRule(metricsPeers, 8080),
+		}},
+		{"notifications-controller", cr.Spec.Notifications.Enabled, []networkingv1.NetworkPolicyIngressRule{
+			networkPolicyIngressRule(metricsPeers, 9001),
+		}},
+	}
+}
+
+// getNetworkPolicyIngressPeers returns the sources allowed to reach the Argo CD Server and the ApplicationSet
+// webhook of the given ArgoCD. A nil value allows any source.
+func getNetworkPolicyIngressPeers(cr *argoproj.ArgoCD) []networkingv1.NetworkPolicyPeer {
+	if cr.Spec.NetworkPolicy == nil || cr.Spec.NetworkPolicy.IngressNamespaceSelector == nil {
+		return nil
+	}
+	return []networkingv1.NetworkPolicyPeer{{
+		NamespaceSelector: cr.Spec.NetworkPolicy.IngressNamespaceSelector.DeepCopy(),
+	}}
+}
+
+// getNetworkPolicyMetricsPeers returns the sources allowed to reach the metrics ports of the given ArgoCD. A nil value
+// allows any source.
+func getNetworkPolicyMetricsPeers(cr *argoproj.ArgoCD) []networkingv1.NetworkPolicyPeer {
+	if cr.Spec.NetworkPolicy == nil || cr.Spec.NetworkPolicy.MetricsNamespaceSelector == nil {
+		return nil
+	}
+	return []networkingv1.NetworkPolicyPeer{{
+		NamespaceSelector: cr.Spec.NetworkPolicy.MetricsNamespaceSelector.DeepCopy(),
+	}}
+}
+
+// networkPolicyPodPeers returns the peers selecting the pods of the given components of the given ArgoCD.
+func networkPolicyPodPeers(cr *argoproj.ArgoCD, suffixes ...string) []networkingv1.NetworkPolicyPeer {
+	peers := make([]networkingv1.NetworkPolicyPeer, 0, len(suffixes))
+	for _, suffix := range suffixes {
+		peers = append(peers, networkingv1.NetworkPolicyPeer{
+			PodSelector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{
+					common.ArgoCDKeyName: nameWithSuffix(suffix, cr),
+				},
+			},
+		})
+	}
+	return peers
+}
+
+// networkPolicyIngressRule returns a rule allowing the traffic from the given peers to the given TCP ports.
+func networkPolicyIngressRule(peers []networkingv1.NetworkPolicyPeer, ports ...int) networkingv1.NetworkPolicyIngressRule {
+	rule := networkingv1.NetworkPolicyIngressRule{From: peers}
+	for _, port := range ports {
+		protocol := corev1.ProtocolTCP
+		target := intstr.FromInt(port)
+		rule.Ports = append(rule.Ports, networkingv1.NetworkPolicyPort{
+			Protocol: &protocol,
+			Port:     &target,
+		})
+	}
+	return rule
+}
+
+// newNetworkPolicyWithSuffix returns a new NetworkPolicy with the given name suffix for the given ArgoCD.
+func newNetworkPolicyWithSuffix(suffix string, cr *argoproj.ArgoCD) *networkingv1.NetworkPolicy {
+	name := nameWithSuffix(suffix, cr)
+	labels := argoutil.LabelsForCluster(cr)
+	labels[common.ArgoCDKeyName] = name
+	return &networkingv1.NetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: cr.Namespace,
+			Labels:    labels,
+		},
+	}
+}
+
+// newDefaultDenyNetworkPolicy returns the NetworkPolicy denying the ingress traffic to the given components of the
+// given ArgoCD.
+func newDefaultDenyNetworkPolicy(cr *argoproj.ArgoCD, components []networkPolicyComponent) *networkingv1.NetworkPolicy {
+	names := make([]string, 0, len(components))
+	for _, component := range components {
+		names = append(names, nameWithSuffix(component.selectorSuffix, cr))
+	}
+
+	np := newNetworkPolicyWithSuffix(networkPolicyDefaultDenySuffix, cr)
+	np.Spec = networkingv1.NetworkPolicySpec{
+		PodSelector: metav1.LabelSelector{
+			MatchExpressions: []metav1.LabelSelectorRequirement{{
+				Key:      common.ArgoCDKeyName,
+				Operator: metav1.LabelSelectorOpIn,
+				Values:   names,
+			}},
+		},
+		PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeIngress},
+	}
+	return np
+}
+
+// newComponentNetworkPolicy returns the NetworkPolicy allowing the ingress traffic to the given component of the
+// given ArgoCD.
+func newComponentNetworkPolicy(cr *argoproj.ArgoCD, component networkPolicyComponent) *networkingv1.NetworkPolicy {
+	np := newNetworkPolicyWithSuffix(component.selectorSuffix+"-"+networkPolicySuffix, cr)
+	np.Spec = networkingv1.NetworkPolicySpec{
+		PodSelector: metav1.LabelSelector{
+			MatchLabels: map[string]string{
+				common.ArgoCDKeyName: nameWithSuffix(component.selectorSuffix, cr),
+			},
+		},
+		Ingress:     component.ingress,
+		PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeIngress},
+	}
+	return np
+}
+
+// reconcileNetworkPolicies will ensure that the NetworkPolicies restricting the ingress traffic to the Argo CD
+// components are present when enabled, and absent otherwise.
+func (r *ReconcileArgoCD) reconcileNetworkPolicies(cr *argoproj.ArgoCD) error {
+	enabled := isNetworkPolicyEnabled(cr)
+	components := getNetworkPolicyComponents(cr)
+
+	if err := r.reconcileNetworkPolicy(cr, newDefaultDenyNetworkPolicy(cr, components), enabled); err != nil {
+		return err
+	}
+	for _, component := range components {
+		if err := r.reconcileNetworkPolicy(cr, newComponentNetworkPolicy(cr, component), enabled && component.enabled); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// reconcileNetworkPolicy will ensure that the given NetworkPolicy is present and up to date when enabled, and absent
+// otherwise.
+func (r *ReconcileArgoCD) reconcileNetworkPolicy(cr *argoproj.ArgoCD, desired *networkingv1.NetworkPolicy, enabled bool) error {
+	existing := &networkingv1.NetworkPolicy{}
+	if argoutil.IsObjectFound(r.Client, cr.Namespace, desired.Name, existing) {
+		if !enabled {
+			log.Info("deleting network policy", "name", existing.Name, "namespace", existing.Namespace)
+			return r.Client.Delete(context.TODO(), existing)
+		}
+
+		if equality.Semantic.DeepEqual(existing.Spec, desired.Spec) {
+			return nil // NetworkPolicy found and up to date, do nothing
+		}
+
+		existing.Spec = desired.Spec
+		log.Info("updating network policy", "name", existing.Name, "namespace", existing.Namespace)
+		return r.Client.Update(context.TODO(), existing)
+	}
+
+	if !enabled {
+		return nil // NetworkPolicy not enabled, move along...
+	}
+
+	if err := controllerutil.SetControllerReference(cr, desired, r.Scheme); err != nil {
+		return err
+	}
+	log.Info("creating network policy", "name", desired.Name, "namespace", desired.Namespace)
+	return r.Client.Create(context.TODO(), desired)
+}
@@ -0,0 +1,187 @@
+package argocd
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	argoproj "github.com/argoproj-labs/argocd-operator/api/v1beta1"
+	"github.com/argoproj-labs/argocd-operator/common"
+)
+
+func withNetworkPolicy(a *argoproj.ArgoCD) {
+	a.Spec.NetworkPolicy = &argoproj.ArgoCDNetworkPolicySpec{Enabled: true}
+}
+
+func listNetworkPolicies(t *testing.T, r *ReconcileArgoCD) map[string]networkingv1.NetworkPolicy {
+	t.Helper()
+	list := &networkingv1.NetworkPolicyList{}
+	assert.NoError(t, r.Client.List(context.TODO(), list, client.InNamespace(testNamespace)))
+	policies := map[string]networkingv1.NetworkPolicy{}
+	for _, np := range list.Items {
+		policies[np.Name] = np
+	}
+	return policies
+}
+
+func networkPolicyPorts(rule networkingv1.NetworkPolicyIngressRule) []int {
+	ports := []int{}
+	for _, port := range rule.Ports {
+		ports = append(ports, port.Port.IntValue())
+	}
+	return ports
+}
+
+func networkPolicyPeerNames(rule networkingv1.NetworkPolicyIngressRule) []string {
+	names := []string{}
+	for _, peer := range rule.From {
+		names = append(names, peer.PodSelector.MatchLabels[common.ArgoCDKeyName])
+	}
+	return names
+}
+
+func TestReconcileArgoCD_reconcileNetworkPolicies_disabledByDefault(t *testing.T) {
+	logf.SetLogger(ZapLogger(true))
+	a := makeTestArgoCD()
+	resObjs := []client.Object{a}
+	sch := makeTestReconcilerScheme(argoproj.AddToScheme)
+	cl := makeTestReconcilerClient(sch, resObjs, resObjs, []runtime.Object{})
+	r := makeTestReconciler(cl, sch)
+
+	assert.NoError(t, r.reconcileNetworkPolicies(a))
+	assert.Empty(t, listNetworkPolicies(t, r))
+}
+
+func TestReconcileArgoCD_reconcileNetworkPolicies(t *testing.T) {
+	logf.SetLogger(ZapLogger(true))
+	a := makeTestArgoCD(withNetworkPolicy)
+	resObjs := []client.Object{a}
+	sch := makeTestReconcilerScheme(argoproj.AddToScheme)
+	cl := makeTestReconcilerClient(sch, resObjs, resObjs, []runtime.Object{})
+	r := makeTestReconciler(cl, sch)
+
+	assert.NoError(t, r.reconcileNetworkPolicies(a))
+
+	policies := listNetworkPolicies(t, r)
+	assert.Len(t, policies, 5)
+
+	deny := policies["argocd-default-deny"]
+	assert.Equal(t, []networkingv1.PolicyType{networkingv1.PolicyTypeIngress}, deny.Spec.PolicyTypes)
+	assert.Empty(t, deny.Spec.Ingress)
+	assert.Contains(t, deny.Spec.PodSelector.MatchExpressions[0].Values, "argocd-server")
+	assert.Equal(t, a.Name, deny.OwnerReferences[0].Name)
+
+	server := policies["argocd-server-network-policy"]
+	assert.Equal(t, map[string]string{common.ArgoCDKeyName: "argocd-server"}, server.Spec.PodSelector.MatchLabels)
+	assert.Nil(t, server.Spec.Ingress[0].From)
+	assert.Equal(t, []int{8080}, networkPolicyPorts(server.Spec.Ingress[0]))
+	assert.Equal(t, []int{8083}, networkPolicyPorts(server.Spec.Ingress[1]))
+
+	repo := policies["argocd-repo-server-network-policy"]
+	assert.Equal(t, []string{"argocd-server", "argocd-application-controller", "argocd-applicationset-controller",
+		"argocd-notifications-controller"}, networkPolicyPeerNames(repo.Spec.Ingress[0]))
+	assert.Equal(t, []int{8081}, networkPolicyPorts(repo.Spec.Ingress[0]))
+
+	redis := policies["argocd-redis-network-policy"]
+	assert.Equal(t, []string{"argocd-server", "argocd-repo-server", "argocd-application-controller"},
+		networkPolicyPeerNames(redis.Spec.Ingress[0]))
+	assert.Equal(t, []int{6379}, networkPolicyPorts(redis.Spec.Ingress[0]))
+
+	assert.Contains(t, policies, "argocd-application-controller-network-policy")
+}
+
+func TestReconcileArgoCD_reconcileNetworkPolicies_namespaceSelectors(t *testing.T) {
+	logf.SetLogger(ZapLogger(true))
+	ingressSelector := &metav1.LabelSelector{MatchLabels: map[string]string{"name": "ingress"}}
+	metricsSelector := &metav1.LabelSelector{MatchLabels: map[string]string{"name": "monitoring"}}
+	a := makeTestArgoCD(func(a *argoproj.ArgoCD) {
+		a.Spec.NetworkPolicy = &argoproj.ArgoCDNetworkPolicySpec{
+			Enabled:                  true,
+			IngressNamespaceSelector: ingressSelector,
+			MetricsNamespaceSelector: metricsSelector,
+		}
+		a.Spec.ApplicationSet = &argoproj.ArgoCDApplicationSet{}
+	})
+	resObjs := []client.Object{a}
+	sch := makeTestReconcilerScheme(argoproj.AddToScheme)
+	cl := makeTestReconcilerClient(sch, resObjs, resObjs, []runtime.Object{})
+	r := makeTestReconciler(cl, sch)
+
+	assert.NoError(t, r.reconcileNetworkPolicies(a))
+
+	policies := listNetworkPolicies(t, r)
+	server := policies["argocd-server-network-policy"]
+	assert.Equal(t, ingressSelector, server.Spec.Ingress[0].From[0].NamespaceSelector)
+	assert.Equal(t, metricsSelector, server.Spec.Ingress[1].From[0].NamespaceSelector)
+
+	appset := policies["argocd-applicationset-controller-network-policy"]
+	assert.Equal(t, ingressSelector, appset.Spec.Ingress[0].From[0].NamespaceSelector)
+	assert.Equal(t, []int{7000}, networkPolicyPorts(appset.Spec.Ingress[0]))
+	assert.Equal(t, metricsSelector, appset.Spec.Ingress[1].From[0].NamespaceSelector)
+}
+
+func TestReconcileArgoCD_reconcileNetworkPolicies_componentsToggled(t *testing.T) {
+	logf.SetLogger(ZapLogger(true))
+	a := makeTestArgoCD(withNetworkPolicy)
+	resObjs := []client.Object{a}
+	sch := makeTestReconcilerScheme(argoproj.AddToScheme)
+	cl := makeTestReconcilerClient(sch, resObjs, resObjs, []runtime.Object{})
+	r := makeTestReconciler(cl, sch)
+	assert.NoError(t, r.reconcileNetworkPolicies(a))
+
+	remote := "redis.example.com:6379"
+	a.Spec.Redis.Remote = &remote
+	a.Spec.Server.Enabled = boolPtr(false)
+	a.Spec.Notifications.Enabled = true
+	assert.NoError(t, r.reconcileNetworkPolicies(a))
+
+	policies := listNetworkPolicies(t, r)
+	assert.NotContains(t, policies, "argocd-redis-network-policy")
+	assert.NotContains(t, policies, "argocd-server-network-policy")
+	assert.Contains(t, policies, "argocd-notifications-controller-network-policy")
+
+	a.Spec.Redis.Remote = nil
+	a.Spec.HA.Enabled = true
+	assert.NoError(t, r.reconcileNetworkPolicies(a))
+
+	policies = listNetworkPolicies(t, r)
+	assert.NotContains(t, policies, "argocd-redis-network-policy")
+	haproxy := policies["argocd-redis-ha-haproxy-network-policy"]
+	assert.Equal(t, []int{6379}, networkPolicyPorts(haproxy.Spec.Ingress[0]))
+	ha := policies["argocd-redis-ha-network-policy"]
+	assert.Equal(t, []string{"argocd-redis-ha", "argocd-redis-ha-haproxy"}, networkPolicyPeerNames(ha.Spec.Ingress[0]))
+	assert.Equal(t, []int{6379, 26379}, networkPolicyPorts(ha.Spec.Ingress[0]))
+
+	// all the NetworkPolicies are deleted once disabled
+	a.Spec.NetworkPolicy.Enabled = false
+	assert.NoError(t, r.reconcileNetworkPolicies(a))
+	assert.Empty(t, listNetworkPolicies(t, r))
+}
+
+func TestReconcileArgoCD_reconcileNetworkPolicies_drift(t *testing.T) {
+	logf.SetLogger(ZapLogger(true))
+	a := makeTestArgoCD(withNetworkPolicy)
+	resObjs := []client.Object{a}
+	sch := makeTestReconcilerScheme(argoproj.AddToScheme)
+	cl := makeTestReconcilerClient(sch, resObjs, resObjs, []runtime.Object{})
+	r := makeTestReconciler(cl, sch)
+	assert.NoError(t, r.reconcileNetworkPolicies(a))
+
+	np := &networkingv1.NetworkPolicy{}
+	key := types.NamespacedName{Name: "argocd-redis-network-policy", Namespace: testNamespace}
+	assert.NoError(t, r.Client.Get(context.TODO(), key, np))
+	np.Spec.Ingress = append(np.Spec.Ingress, networkingv1.NetworkPolicyIngressRule{})
+	assert.NoError(t, r.Client.Update(context.TODO(), np))
+
+	assert.NoError(t, r.reconcileNetworkPolicies(a))
+
+	assert.NoError(t, r.Client.Get(context.TODO(), key, np))
+	assert.Len(t, np.Spec.Ingress, 1)
+}
@@ -0,0 +1,126 @@
+// Copyright 2023 ArgoCD Operator Developers
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package argocd
+
+import (
+	"reflect"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	argoproj "github.com/argoproj-labs/argocd-operator/api/v1beta1"
+	"github.com/argoproj-labs/argocd-operator/controllers/argoutil"
+)
+
+// getNodePlacement returns the NodePlacement of a component of the given ArgoCD, merging the given options of the
+// component over the global NodePlacement. The node selectors are merged, while the other options of the component
+// replace the global ones when set. The node affinity, pod affinity and pod anti-affinity are replaced separately.
+func getNodePlacement(cr *argoproj.ArgoCD, component *argoproj.ArgoCDNodePlacementSpec) *argoproj.ArgoCDNodePlacementSpec {
+	placement := &argoproj.ArgoCDNodePlacementSpec{}
+	if cr.Spec.NodePlacement != nil {
+		placement = cr.Spec.NodePlacement.DeepCopy()
+	}
+	if component == nil {
+		return placement
+	}
+	component = component.DeepCopy()
+
+	placement.NodeSelector = argoutil.AppendStringMap(placement.NodeSelector, component.NodeSelector)
+	if len(component.Tolerations) > 0 {
+		placement.Tolerations = component.Tolerations
+	}
+	placement.Affinity = mergeAffinity(placement.Affinity, component.Affinity)
+	if len(component.TopologySpreadConstraints) > 0 {
+		placement.TopologySpreadConstraints = component.TopologySpreadConstraints
+	}
+	if component.PriorityClassName != "" {
+		placement.PriorityClassName = component.PriorityClassName
+	}
+	if component.RuntimeClassName != nil {
+		placement.RuntimeClassName = component.RuntimeClassName
+	}
+	return placement
+}
+
+// mergeAffinity returns the given affinity, with the node affinity, pod affinity and pod anti-affinity of the given
+// override replacing its own when set.
+func mergeAffinity(affinity *corev1.Affinity, override *corev1.Affinity) *corev1.Affinity {
+	if override == nil {
+		return affinity
+	}
+	if affinity == nil {
+		affinity = &corev1.Affinity{}
+	}
+	if override.NodeAffinity != nil {
+		affinity.NodeAffinity = override.NodeAffinity
+	}
+	if override.PodAffinity != nil {
+		affinity.PodAffinity = override.PodAffinity
+	}
+	if override.PodAntiAffinity != nil {
+		affinity.PodAntiAffinity = override.PodAntiAffinity
+	}
+	return affinity
+}
+
+// applyNodePlacement sets the scheduling options of the given NodePlacement on the given pod spec, over the defaults
+// of the component. The topology spread constraints without a label selector select the pods of the given selector.
+func applyNodePlacement(podSpec *corev1.PodSpec, placement *argoproj.ArgoCDNodePlacementSpec, selector *metav1.LabelSelector) {
+	placement = placement.DeepCopy()
+
+	podSpec.NodeSelector = argoutil.AppendStringMap(podSpec.NodeSelector, placement.NodeSelector)
+	if len(placement.Tolerations) > 0 {
+		podSpec.Tolerations = placement.Tolerations
+	}
+	podSpec.Affinity = mergeAffinity(podSpec.Affinity, placement.Affinity)
+
+	for i := range placement.TopologySpreadConstraints {
+		if placement.TopologySpreadConstraints[i].LabelSelector == nil {
+			placement.TopologySpreadConstraints[i].LabelSelector = selector.DeepCopy()
+		}
+	}
+	podSpec.TopologySpreadConstraints = placement.TopologySpreadConstraints
+	podSpec.PriorityClassName = placement.PriorityClassName
+	podSpec.RuntimeClassName = placement.RuntimeClassName
+}
+
+// updatePodSchedulingOptions updates the scheduling options of the given existing pod spec that differ from the given
+// desired pod spec, and sets changed to true if any of them was updated.
+func updatePodSchedulingOptions(existing *corev1.PodSpec, desired *corev1.PodSpec, changed *bool) {
+	if !reflect.DeepEqual(existing.NodeSelector, desired.NodeSelector) {
+		existing.NodeSelector = desired.NodeSelector
+		*changed = true
+	}
+	if !reflect.DeepEqual(existing.Tolerations, desired.Tolerations) {
+		existing.Tolerations = desired.Tolerations
+		*changed = true
+	}
+	if !reflect.DeepEqual(existing.Affinity, desired.Affinity) {
+		existing.Affinity = desired.Affinity
+		*changed = true
+	}
+	if !reflect.DeepEqual(existing.TopologySpreadConstraints, desired.TopologySpreadConstraints) {
+		existing.TopologySpreadConstraints = desired.TopologySpreadConstraints
+		*changed = true
+	}
+	if existing.PriorityClassName != desired.PriorityClassName {
+		existing.PriorityClassName = desired.PriorityClassName
+		*changed = true
+	}
+	if !reflect.DeepEqual(existing.RuntimeClassName, desired.RuntimeClassName) {
+		existing.RuntimeClassName = desired.RuntimeClassName
+		*changed = true
+	}
+}
@@ -0,0 +1,148 @@
+package argocd
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	argoproj "github.com/argoproj-labs/argocd-operator/api/v1beta1"
+	"github.com/argoproj-labs/argocd-operator/common"
+)
+
+func testNodeAffinity(zone string) *corev1.NodeAffinity {
+	return &corev1.NodeAffinity{
+		RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{
+			NodeSelectorTerms: []corev1.NodeSelectorTerm{{
+				MatchExpressions: []corev1.NodeSelectorRequirement{{
+					Key:      "topology.kubernetes.io/zone",
+					Operator: corev1.NodeSelectorOpIn,
+					Values:   []string{zone},
+				}},
+			}},
+		},
+	}
+}
+
+func TestGetNodePlacement(t *testing.T) {
+	global := &argoproj.ArgoCDNodePlacementSpec{
+		NodeSelector:      map[string]string{"disk": "ssd", "pool": "default"},
+		Tolerations:       []corev1.Toleration{{Key: "global", Operator: corev1.TolerationOpExists}},
+		Affinity:          &corev1.Affinity{NodeAffinity: testNodeAffinity("zone-a")},
+		PriorityClassName: "argocd",
+	}
+	runtimeClass := "gvisor"
+
+	tests := []struct {
+		name      string
+		global    *argoproj.ArgoCDNodePlacementSpec
+		component *argoproj.ArgoCDNodePlacementSpec
+		want      *argoproj.ArgoCDNodePlacementSpec
+	}{
+		{"no placement", nil, nil, &argoproj.ArgoCDNodePlacementSpec{}},
+		{"global placement", global, nil, global},
+		{"component placement", nil, &argoproj.ArgoCDNodePlacementSpec{PriorityClassName: "critical"},
+			&argoproj.ArgoCDNodePlacementSpec{PriorityClassName: "critical", NodeSelector: map[string]string{}}},
+		{"component merged over global placement", global, &argoproj.ArgoCDNodePlacementSpec{
+			NodeSelector:     map[string]string{"pool": "controllers"},
+			Affinity:         &corev1.Affinity{PodAntiAffinity: &corev1.PodAntiAffinity{}},
+			RuntimeClassName: &runtimeClass,
+		}, &argoproj.ArgoCDNodePlacementSpec{
+			NodeSelector: map[string]string{"disk": "ssd", "pool": "controllers"},
+			Tolerations:  global.Tolerations,
+			Affinity: &corev1.Affinity{
+				NodeAffinity:    testNodeAffinity("zone-a"),
+				PodAntiAffinity: &corev1.PodAntiAffinity{},
+			},
+			PriorityClassName: "argocd",
+			RuntimeClassName:  &runtimeClass,
+		}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			a := makeTestArgoCD(func(a *argoproj.ArgoCD) {
+				a.Spec.NodePlacement = test.global
+			})
+			assert.Equal(t, test.want, getNodePlacement(a, test.component))
+		})
+	}
+
+	// the global placement is left untouched
+	assert.Equal(t, map[string]string{"disk": "ssd", "pool": "default"}, global.NodeSelector)
+	assert.Nil(t, global.Affinity.PodAntiAffinity)
+}
+
+func TestReconcileArgoCD_reconcileServerDeployment_nodePlacement(t *testing.T) {
+	logf.SetLogger(ZapLogger(true))
+	a := makeTestArgoCD(func(a *argoproj.ArgoCD) {
+		a.Spec.NodePlacement = &argoproj.ArgoCDNodePlacementSpec{PriorityClassName: "argocd"}
+		a.Spec.Server.NodePlacement = &argoproj.ArgoCDNodePlacementSpec{
+			TopologySpreadConstraints: []corev1.TopologySpreadConstraint{{
+				MaxSkew:           1,
+				TopologyKey:       "topology.kubernetes.io/zone",
+				WhenUnsatisfiable: corev1.ScheduleAnyway,
+			}},
+		}
+	})
+
+	resObjs := []client.Object{a}
+	sch := makeTestReconcilerScheme(argoproj.AddToScheme)
+	cl := makeTestReconcilerClient(sch, resObjs, resObjs, []runtime.Object{})
+	r := makeTestReconciler(cl, sch)
+
+	assert.NoError(t, r.reconcileServerDeployment(a, false))
+
+	deployment := &appsv1.Deployment{}
+	key := types.NamespacedName{Name: "argocd-server", Namespace: testNamespace}
+	assert.NoError(t, r.Client.Get(context.TODO(), key, deployment))
+	podSpec := deployment.Spec.Template.Spec
+	assert.Equal(t, "argocd", podSpec.PriorityClassName)
+	assert.Len(t, podSpec.TopologySpreadConstraints, 1)
+	assert.Equal(t, &metav1.LabelSelector{MatchLabels: map[string]string{common.ArgoCDKeyName: "argocd-server"}},
+		podSpec.TopologySpreadConstraints[0].LabelSelector)
+
+	// the scheduling options are updated
+	runtimeClass := "gvisor"
+	a.Spec.Server.NodePlacement = &argoproj.ArgoCDNodePlacementSpec{
+		PriorityClassName: "critical",
+		RuntimeClassName:  &runtimeClass,
+	}
+	assert.NoError(t, r.reconcileServerDeployment(a, false))
+
+	assert.NoError(t, r.Client.Get(context.TODO(), key, deployment))
+	podSpec = deployment.Spec.Template.Spec
+	assert.Equal(t, "critical", podSpec.PriorityClassName)
+	assert.Equal(t, &runtimeClass, podSpec.RuntimeClassName)
+	assert.Empty(t, podSpec.TopologySpreadConstraints)
+}
+
+func TestReconcileArgoCD_reconcileRedisStatefulSet_nodePlacement(t *testing.T) {
+	logf.SetLogger(ZapLogger(true))
+	a := makeTestArgoCD(func(a *argoproj.ArgoCD) {
+		a.Spec.HA.Enabled = true
+		a.Spec.Redis.NodePlacement = &argoproj.ArgoCDNodePlacementSpec{
+			Affinity: &corev1.Affinity{NodeAffinity: testNodeAffinity("zone-b")},
+		}
+	})
+
+	resObjs := []client.Object{a}
+	sch := makeTestReconcilerScheme(argoproj.AddToScheme)
+	cl := makeTestReconcilerClient(sch, resObjs, resObjs, []runtime.Object{})
+	r := makeTestReconciler(cl, sch)
+
+	assert.NoError(t, r.reconcileRedisStatefulSet(a, false))
+
+	ss := &appsv1.StatefulSet{}
+	assert.NoError(t, r.Client.Get(context.TODO(), types.NamespacedName{Name: "argocd-redis-ha-server", Namespace: testNamespace}, ss))
+	affinity := ss.Spec.Template.Spec.Affinity
+	assert.Equal(t, testNodeAffinity("zone-b"), affinity.NodeAffinity)
+	// the default anti-affinity of the Redis HA servers is kept
+	assert.Len(t, affinity.PodAntiAffinity.RequiredDuringSchedulingIgnoredDuringExecution, 1)
+}
@@ -302,7 +302,7 @@ func (r *ReconcileArgoCD) reconcileNotificationsDeployment(cr *argoproj.ArgoCD,
 	podSpec.Containers = []corev1.Container{{
 		Command:         getNotificationsCommand(cr),
 		Image:           getArgoContainerImage(cr),
-		ImagePullPolicy: corev1.PullAlways,
+		ImagePullPolicy: getImagePullPolicy(cr, cr.Spec.Notifications.ImagePullPolicy, corev1.PullAlways),
 		Name:            common.ArgoCDNotificationsControllerComponent,
 		Env:             notificationEnv,
 		Resources:       getNotificationsResources(cr),
@@ -336,6 +336,9 @@ func (r *ReconcileArgoCD) reconcileNotificationsDeployment(cr *argoproj.ArgoCD,
 		WorkingDir: "/app",
 	}}
 
+	applyNodePlacement(&desiredDeployment.Spec.Template.Spec, getNodePlacement(cr, cr.Spec.Notifications.NodePlacement), desiredDeployment.Spec.Selector)
+	desiredDeployment.Spec.Template.Spec.ImagePullSecrets = getImagePullSecrets(cr, cr.Spec.Notifications.ImagePullSecrets)
+
 	// fetch existing deployment by name
 	deploymentChanged := false
 	existingDeployment := &appsv1.Deployment{}
@@ -366,6 +369,7 @@ func (r *ReconcileArgoCD) reconcileNotificationsDeployment(cr *argoproj.ArgoCD,
 
 	// deployment exists and should. Reconcile deployment if changed
 	updateNodePlacement(existingDeployment, desiredDeployment, &deploymentChanged)
+	updateImagePullOptions(&existingDeployment.Spec.Template.Spec, &desiredDeployment.Spec.Template.Spec, &deploymentChanged)
 
 	if existingDeployment.Spec.Template.Spec.Containers[0].Image != desiredDeployment.Spec.Template.Spec.Containers[0].Image {
 		existingDeployment.Spec.Template.Spec.Containers[0].Image = desiredDeployment.Spec.Template.Spec.Containers[0].Image
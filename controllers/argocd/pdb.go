@@ -0,0 +1,177 @@
+// Copyright 2023 ArgoCD Operator Developers
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package argocd
+
+import (
+	"context"
+	"fmt"
+
+	policyv1 "k8s.io/api/policy/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	argoproj "github.com/argoproj-labs/argocd-operator/api/v1beta1"
+	"github.com/argoproj-labs/argocd-operator/common"
+	"github.com/argoproj-labs/argocd-operator/controllers/argoutil"
+)
+
+// podDisruptionBudgetComponent is an Argo CD workload that may be protected by a PodDisruptionBudget.
+type podDisruptionBudgetComponent struct {
+	// suffix is the name suffix of the workload, and of its PodDisruptionBudget.
+	suffix string
+
+	// selectorSuffix is the name suffix selecting the pods of the workload.
+	selectorSuffix string
+
+	// enabled is true if the workload is deployed for the ArgoCD.
+	enabled bool
+
+	// spec is the PodDisruptionBudget options of the workload, if any.
+	spec *argoproj.ArgoCDPodDisruptionBudgetSpec
+}
+
+// getPodDisruptionBudgetComponents returns the workloads of the given ArgoCD that may be protected by a
+// PodDisruptionBudget.
+func getPodDisruptionBudgetComponents(cr *argoproj.ArgoCD) []podDisruptionBudgetComponent {
+	redisEnabled := cr.Spec.Redis.IsEnabled()
+
+	var dexSpec *argoproj.ArgoCDPodDisruptionBudgetSpec
+	if cr.Spec.SSO != nil && cr.Spec.SSO.Dex != nil {
+		dexSpec = cr.Spec.SSO.Dex.PDB
+	}
+
+	var appSetSpec *argoproj.ArgoCDPodDisruptionBudgetSpec
+	if cr.Spec.ApplicationSet != nil {
+		appSetSpec = cr.Spec.ApplicationSet.PDB
+	}
+
+	return []podDisruptionBudgetComponent{
+		{"server", "server", cr.Spec.Server.IsEnabled(), cr.Spec.Server.PDB},
+		{"repo-server", "repo-server", cr.Spec.Repo.IsEnabled(), cr.Spec.Repo.PDB},
+		{"application-controller", "application-controller", cr.Spec.Controller.IsEnabled(), cr.Spec.Controller.PDB},
+		{"redis", "redis", redisEnabled && !cr.Spec.HA.Enabled, cr.Spec.Redis.PDB},
+		{"redis-ha-server", "redis-ha", redisEnabled && cr.Spec.HA.Enabled, cr.Spec.Redis.PDB},
+		{"redis-ha-haproxy", "redis-ha-haproxy", redisEnabled && cr.Spec.HA.Enabled, cr.Spec.HA.PDB},
+		{"dex-server", "dex-server", UseDex(cr), dexSpec},
+		{"applicationset-controller", "applicationset-controller", cr.Spec.ApplicationSet != nil && cr.Spec.ApplicationSet.IsEnabled(), appSetSpec},
+		{"notifications-controller", "notifications-controller", cr.Spec.Notifications.Enabled, cr.Spec.Notifications.PDB},
+	}
+}
+
+// isPodDisruptionBudgetEnabled returns true if the given PodDisruptionBudget options are enabled for the given
+// ArgoCD. PodDisruptionBudgets are enabled by default when HA is enabled.
+func isPodDisruptionBudgetEnabled(cr *argoproj.ArgoCD, spec *argoproj.ArgoCDPodDisruptionBudgetSpec) bool {
+	if spec != nil && spec.Enabled != nil {
+		return *spec.Enabled
+	}
+	return cr.Spec.HA.Enabled
+}
+
+// newPodDisruptionBudgetWithSuffix returns a new PodDisruptionBudget with the given name suffix for the given ArgoCD.
+func newPodDisruptionBudgetWithSuffix(suffix string, cr *argoproj.ArgoCD) *policyv1.PodDisruptionBudget {
+	name := nameWithSuffix(suffix, cr)
+	labels := argoutil.LabelsForCluster(cr)
+	labels[common.ArgoCDKeyName] = name
+	return &policyv1.PodDisruptionBudget{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: cr.Namespace,
+			Labels:    labels,
+		},
+	}
+}
+
+// newPodDisruptionBudget returns the desired PodDisruptionBudget of the given component of the given ArgoCD.
+func newPodDisruptionBudget(cr *argoproj.ArgoCD, component podDisruptionBudgetComponent) (*policyv1.PodDisruptionBudget, error) {
+	pdb := newPodDisruptionBudgetWithSuffix(component.suffix, cr)
+	pdb.Spec.Selector = &metav1.LabelSelector{
+		MatchLabels: map[string]string{
+			common.ArgoCDKeyName: nameWithSuffix(component.selectorSuffix, cr),
+		},
+	}
+
+	spec := component.spec
+	switch {
+	case spec != nil && spec.MinAvailable != nil && spec.MaxUnavailable != nil:
+		return nil, fmt.Errorf("only one of minAvailable and maxUnavailable can be set for the %s pod disruption budget", component.suffix)
+	case spec != nil && spec.MinAvailable != nil:
+		minAvailable := *spec.MinAvailable
+		pdb.Spec.MinAvailable = &minAvailable
+	case spec != nil && spec.MaxUnavailable != nil:
+		maxUnavailable := *spec.MaxUnavailable
+		pdb.Spec.MaxUnavailable = &maxUnavailable
+	default:
+		maxUnavailable := intstr.FromInt(common.ArgoCDDefaultPodDisruptionBudgetMaxUnavailable)
+		pdb.Spec.MaxUnavailable = &maxUnavailable
+	}
+	return pdb, nil
+}
+
+// reconcilePodDisruptionBudgets will ensure that the PodDisruptionBudgets of the Argo CD workloads are present when
+// enabled, and absent otherwise.
+func (r *ReconcileArgoCD) reconcilePodDisruptionBudgets(cr *argoproj.ArgoCD) error {
+	for _, component := range getPodDisruptionBudgetComponents(cr) {
+		if err := r.reconcilePodDisruptionBudget(cr, component); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// reconcilePodDisruptionBudget will ensure that the PodDisruptionBudget of the given component is present and up to
+// date when the component is deployed and its PodDisruptionBudget enabled, and absent otherwise.
+func (r *ReconcileArgoCD) reconcilePodDisruptionBudget(cr *argoproj.ArgoCD, component podDisruptionBudgetComponent) error {
+	enabled := component.enabled && isPodDisruptionBudgetEnabled(cr, component.spec)
+
+	existing := newPodDisruptionBudgetWithSuffix(component.suffix, cr)
+	if argoutil.IsObjectFound(r.Client, cr.Namespace, existing.Name, existing) {
+		if !enabled {
+			log.Info("deleting pod disruption budget", "name", existing.Name, "namespace", existing.Namespace)
+			return r.Client.Delete(context.TODO(), existing)
+		}
+
+		desired, err := newPodDisruptionBudget(cr, component)
+		if err != nil {
+			return err
+		}
+		if equality.Semantic.DeepEqual(existing.Spec.Selector, desired.Spec.Selector) &&
+			equality.Semantic.DeepEqual(existing.Spec.MinAvailable, desired.Spec.MinAvailable) &&
+			equality.Semantic.DeepEqual(existing.Spec.MaxUnavailable, desired.Spec.MaxUnavailable) {
+			return nil // PodDisruptionBudget found and up to date, do nothing
+		}
+
+		existing.Spec.Selector = desired.Spec.Selector
+		existing.Spec.MinAvailable = desired.Spec.MinAvailable
+		existing.Spec.MaxUnavailable = desired.Spec.MaxUnavailable
+		log.Info("updating pod disruption budget", "name", existing.Name, "namespace", existing.Namespace)
+		return r.Client.Update(context.TODO(), existing)
+	}
+
+	if !enabled {
+		return nil // PodDisruptionBudget not enabled, move along...
+	}
+
+	pdb, err := newPodDisruptionBudget(cr, component)
+	if err != nil {
+		return err
+	}
+	if err := controllerutil.SetControllerReference(cr, pdb, r.Scheme); err != nil {
+		return err
+	}
+	log.Info("creating pod disruption budget", "name", pdb.Name, "namespace", pdb.Namespace)
+	return r.Client.Create(context.TODO(), pdb)
+}
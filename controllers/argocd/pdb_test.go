@@ -0,0 +1,143 @@
+package argocd
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	policyv1 "k8s.io/api/policy/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	argoproj "github.com/argoproj-labs/argocd-operator/api/v1beta1"
+	"github.com/argoproj-labs/argocd-operator/common"
+)
+
+func listPodDisruptionBudgets(t *testing.T, r *ReconcileArgoCD) map[string]policyv1.PodDisruptionBudget {
+	t.Helper()
+	list := &policyv1.PodDisruptionBudgetList{}
+	assert.NoError(t, r.Client.List(context.TODO(), list, client.InNamespace(testNamespace)))
+	pdbs := map[string]policyv1.PodDisruptionBudget{}
+	for _, pdb := range list.Items {
+		pdbs[pdb.Name] = pdb
+	}
+	return pdbs
+}
+
+func TestReconcileArgoCD_reconcilePodDisruptionBudgets_disabledByDefault(t *testing.T) {
+	logf.SetLogger(ZapLogger(true))
+	a := makeTestArgoCD()
+	resObjs := []client.Object{a}
+	sch := makeTestReconcilerScheme(argoproj.AddToScheme)
+	cl := makeTestReconcilerClient(sch, resObjs, resObjs, []runtime.Object{})
+	r := makeTestReconciler(cl, sch)
+
+	assert.NoError(t, r.reconcilePodDisruptionBudgets(a))
+	assert.Empty(t, listPodDisruptionBudgets(t, r))
+}
+
+func TestReconcileArgoCD_reconcilePodDisruptionBudgets_HA(t *testing.T) {
+	logf.SetLogger(ZapLogger(true))
+	a := makeTestArgoCD(func(a *argoproj.ArgoCD) {
+		a.Spec.HA.Enabled = true
+		a.Spec.Notifications.Enabled = true
+		a.Spec.Notifications.PDB = &argoproj.ArgoCDPodDisruptionBudgetSpec{Enabled: boolPtr(false)}
+	})
+	resObjs := []client.Object{a}
+	sch := makeTestReconcilerScheme(argoproj.AddToScheme)
+	cl := makeTestReconcilerClient(sch, resObjs, resObjs, []runtime.Object{})
+	r := makeTestReconciler(cl, sch)
+
+	assert.NoError(t, r.reconcilePodDisruptionBudgets(a))
+
+	pdbs := listPodDisruptionBudgets(t, r)
+	assert.Len(t, pdbs, 5)
+	for name, selector := range map[string]string{
+		"argocd-server":                 "argocd-server",
+		"argocd-repo-server":            "argocd-repo-server",
+		"argocd-application-controller": "argocd-application-controller",
+		"argocd-redis-ha-server":        "argocd-redis-ha",
+		"argocd-redis-ha-haproxy":       "argocd-redis-ha-haproxy",
+	} {
+		pdb, ok := pdbs[name]
+		if !assert.True(t, ok, name) {
+			continue
+		}
+		assert.Equal(t, map[string]string{common.ArgoCDKeyName: selector}, pdb.Spec.Selector.MatchLabels)
+		assert.Equal(t, intstr.FromInt(1), *pdb.Spec.MaxUnavailable)
+		assert.Nil(t, pdb.Spec.MinAvailable)
+		assert.Equal(t, a.Name, pdb.OwnerReferences[0].Name)
+	}
+}
+
+func TestReconcileArgoCD_reconcilePodDisruptionBudgets_update(t *testing.T) {
+	logf.SetLogger(ZapLogger(true))
+	minAvailable := intstr.FromString("50%")
+	a := makeTestArgoCD(func(a *argoproj.ArgoCD) {
+		a.Spec.Server.PDB = &argoproj.ArgoCDPodDisruptionBudgetSpec{Enabled: boolPtr(true)}
+	})
+	resObjs := []client.Object{a}
+	sch := makeTestReconcilerScheme(argoproj.AddToScheme)
+	cl := makeTestReconcilerClient(sch, resObjs, resObjs, []runtime.Object{})
+	r := makeTestReconciler(cl, sch)
+
+	assert.NoError(t, r.reconcilePodDisruptionBudgets(a))
+	assert.Len(t, listPodDisruptionBudgets(t, r), 1)
+
+	a.Spec.Server.PDB.MinAvailable = &minAvailable
+	assert.NoError(t, r.reconcilePodDisruptionBudgets(a))
+
+	pdb := &policyv1.PodDisruptionBudget{}
+	assert.NoError(t, r.Client.Get(context.TODO(), types.NamespacedName{Name: "argocd-server", Namespace: testNamespace}, pdb))
+	assert.Equal(t, minAvailable, *pdb.Spec.MinAvailable)
+	assert.Nil(t, pdb.Spec.MaxUnavailable)
+
+	// the PodDisruptionBudget is deleted once disabled
+	a.Spec.Server.PDB.Enabled = boolPtr(false)
+	assert.NoError(t, r.reconcilePodDisruptionBudgets(a))
+	assert.Empty(t, listPodDisruptionBudgets(t, r))
+}
+
+func TestReconcileArgoCD_reconcilePodDisruptionBudgets_componentDisabled(t *testing.T) {
+	logf.SetLogger(ZapLogger(true))
+	a := makeTestArgoCD(func(a *argoproj.ArgoCD) {
+		a.Spec.HA.Enabled = true
+	})
+	resObjs := []client.Object{a}
+	sch := makeTestReconcilerScheme(argoproj.AddToScheme)
+	cl := makeTestReconcilerClient(sch, resObjs, resObjs, []runtime.Object{})
+	r := makeTestReconciler(cl, sch)
+	assert.NoError(t, r.reconcilePodDisruptionBudgets(a))
+
+	a.Spec.HA.Enabled = false
+	a.Spec.Repo.Enabled = boolPtr(false)
+	a.Spec.Repo.PDB = &argoproj.ArgoCDPodDisruptionBudgetSpec{Enabled: boolPtr(true)}
+	a.Spec.Redis.PDB = &argoproj.ArgoCDPodDisruptionBudgetSpec{Enabled: boolPtr(true)}
+	assert.NoError(t, r.reconcilePodDisruptionBudgets(a))
+
+	pdbs := listPodDisruptionBudgets(t, r)
+	assert.Len(t, pdbs, 1)
+	assert.Contains(t, pdbs, "argocd-redis")
+}
+
+func TestReconcileArgoCD_reconcilePodDisruptionBudgets_invalid(t *testing.T) {
+	logf.SetLogger(ZapLogger(true))
+	minAvailable := intstr.FromInt(1)
+	maxUnavailable := intstr.FromInt(1)
+	a := makeTestArgoCD(func(a *argoproj.ArgoCD) {
+		a.Spec.Server.PDB = &argoproj.ArgoCDPodDisruptionBudgetSpec{
+			Enabled:        boolPtr(true),
+			MinAvailable:   &minAvailable,
+			MaxUnavailable: &maxUnavailable,
+		}
+	})
+	resObjs := []client.Object{a}
+	sch := makeTestReconcilerScheme(argoproj.AddToScheme)
+	cl := makeTestReconcilerClient(sch, resObjs, resObjs, []runtime.Object{})
+	r := makeTestReconciler(cl, sch)
+
+	assert.EqualError(t, r.reconcilePodDisruptionBudgets(a), "only one of minAvailable and maxUnavailable can be set for the server pod disruption budget")
+}
@@ -0,0 +1,234 @@
+// Copyright 2023 ArgoCD Operator Developers
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package argocd
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+
+	"github.com/sethvargo/go-password/password"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	argoproj "github.com/argoproj-labs/argocd-operator/api/v1beta1"
+	"github.com/argoproj-labs/argocd-operator/common"
+	"github.com/argoproj-labs/argocd-operator/controllers/argoutil"
+)
+
+const (
+	// redisAuthSecretSuffix is the suffix of the Secret holding the Redis password generated by the operator.
+	redisAuthSecretSuffix = "redis-auth"
+
+	// redisPasswordEnvName is the environment variable the Argo CD components read the Redis password from.
+	redisPasswordEnvName = "REDIS_PASSWORD"
+
+	// redisUsernameEnvName is the environment variable the Argo CD components read the Redis ACL user from.
+	redisUsernameEnvName = "REDIS_USERNAME"
+
+	// redisHAAuthEnvName is the environment variable the Redis HA scripts read the Redis password from.
+	redisHAAuthEnvName = "AUTH"
+)
+
+// errRemoteRedisPasswordSecretRequired is returned when a remote Redis requires a password that is not supplied.
+var errRemoteRedisPasswordSecretRequired = errors.New("the password secret of the Redis auth options must be set to authenticate with a remote Redis")
+
+// generateRedisPassword will generate and return a Redis password.
+func generateRedisPassword() ([]byte, error) {
+	pass, err := password.Generate(
+		common.ArgoCDDefaultRedisPasswordLength,
+		common.ArgoCDDefaultRedisPasswordNumDigits,
+		common.ArgoCDDefaultRedisPasswordNumSymbols,
+		false, false)
+
+	return []byte(pass), err
+}
+
+// isRemoteRedis returns true if the given ArgoCD uses a remote Redis.
+func isRemoteRedis(cr *argoproj.ArgoCD) bool {
+	return cr.Spec.Redis.Remote != nil && *cr.Spec.Redis.Remote != ""
+}
+
+// generatesRedisPassword returns true if the operator generates the Redis password of the given ArgoCD.
+func generatesRedisPassword(cr *argoproj.ArgoCD) bool {
+	return cr.Spec.Redis.WantsAuth() && cr.Spec.Redis.Auth.PasswordSecret == nil && !isRemoteRedis(cr)
+}
+
+// getRedisPasswordSecretKeySelector returns the reference to the key of the Secret holding the Redis password of the
+// given ArgoCD, which is either the Secret supplied in the Redis auth options or the one generated by the operator.
+func getRedisPasswordSecretKeySelector(cr *argoproj.ArgoCD) corev1.SecretKeySelector {
+	if cr.Spec.Redis.Auth != nil && cr.Spec.Redis.Auth.PasswordSecret != nil {
+		return corev1.SecretKeySelector{
+			LocalObjectReference: cr.Spec.Redis.Auth.PasswordSecret.LocalObjectReference,
+			Key:                  cr.Spec.Redis.Auth.PasswordSecret.Key,
+		}
+	}
+	return corev1.SecretKeySelector{
+		LocalObjectReference: corev1.LocalObjectReference{Name: nameWithSuffix(redisAuthSecretSuffix, cr)},
+		Key:                  common.ArgoCDKeyRedisAuth,
+	}
+}
+
+// getRedisPasswordEnvVar returns the environment variable with the given name referencing the Redis password of the
+// given ArgoCD.
+func getRedisPasswordEnvVar(name string, cr *argoproj.ArgoCD) corev1.EnvVar {
+	selector := getRedisPasswordSecretKeySelector(cr)
+	return corev1.EnvVar{
+		Name:      name,
+		ValueFrom: &corev1.EnvVarSource{SecretKeyRef: &selector},
+	}
+}
+
+// getRedisAuthEnv returns the environment variables holding the Redis credentials of the Argo CD components of the
+// given ArgoCD, or nothing if Redis does not require a password.
+func getRedisAuthEnv(cr *argoproj.ArgoCD) []corev1.EnvVar {
+	if !cr.Spec.Redis.WantsAuth() {
+		return nil
+	}
+
+	env := []corev1.EnvVar{getRedisPasswordEnvVar(redisPasswordEnvName, cr)}
+	if len(cr.Spec.Redis.Auth.Username) > 0 {
+		env = append(env, corev1.EnvVar{Name: redisUsernameEnvName, Value: cr.Spec.Redis.Auth.Username})
+	}
+	return env
+}
+
+// getRedisHAAuthEnv returns the environment variables holding the Redis password of the Redis HA servers and proxy
+// of the given ArgoCD, or nothing if Redis does not require a password.
+func getRedisHAAuthEnv(cr *argoproj.ArgoCD) []corev1.EnvVar {
+	if !cr.Spec.Redis.WantsAuth() {
+		return nil
+	}
+	return []corev1.EnvVar{getRedisPasswordEnvVar(redisHAAuthEnvName, cr)}
+}
+
+// getRedisPassword returns the Redis password of the given ArgoCD.
+func (r *ReconcileArgoCD) getRedisPassword(cr *argoproj.ArgoCD) ([]byte, error) {
+	selector := getRedisPasswordSecretKeySelector(cr)
+	secret := &corev1.Secret{}
+	if err := r.Client.Get(context.TODO(), types.NamespacedName{Name: selector.Name, Namespace: cr.Namespace}, secret); err != nil {
+		return nil, fmt.Errorf("failed to get the Redis password secret %s: %w", selector.Name, err)
+	}
+
+	password, ok := secret.Data[selector.Key]
+	if !ok || len(password) == 0 {
+		return nil, fmt.Errorf("the Redis password secret %s has no %s key", selector.Name, selector.Key)
+	}
+	return password, nil
+}
+
+// reconcileRedisAuthSecret will ensure that the Redis password is generated when Redis requires a password and no
+// password secret is supplied, and that Redis and the Argo CD components are rolled out once the password changes.
+// Deleting the generated secret, or its key, rotates the password.
+func (r *ReconcileArgoCD) reconcileRedisAuthSecret(cr *argoproj.ArgoCD) error {
+	log.Info("reconciling redis auth secret")
+
+	if cr.Spec.Redis.WantsAuth() && cr.Spec.Redis.Auth.PasswordSecret == nil && isRemoteRedis(cr) {
+		return errRemoteRedisPasswordSecretRequired
+	}
+
+	secret := argoutil.NewSecretWithSuffix(cr, redisAuthSecretSuffix)
+	if argoutil.IsObjectFound(r.Client, cr.Namespace, secret.Name, secret) {
+		if !generatesRedisPassword(cr) {
+			if metav1.IsControlledBy(secret, cr) {
+				log.Info("deleting the generated redis password secret", "name", secret.Name, "namespace", secret.Namespace)
+				if err := r.Client.Delete(context.TODO(), secret); err != nil {
+					return err
+				}
+			}
+		} else if len(secret.Data[common.ArgoCDKeyRedisAuth]) == 0 {
+			password, err := generateRedisPassword()
+			if err != nil {
+				return err
+			}
+			if secret.Data == nil {
+				secret.Data = map[string][]byte{}
+			}
+			secret.Data[common.ArgoCDKeyRedisAuth] = password
+			log.Info("generating a new redis password", "name", secret.Name, "namespace", secret.Namespace)
+			if err := r.Client.Update(context.TODO(), secret); err != nil {
+				return err
+			}
+		}
+	} else if generatesRedisPassword(cr) {
+		password, err := generateRedisPassword()
+		if err != nil {
+			return err
+		}
+		secret.Data = map[string][]byte{
+			common.ArgoCDKeyRedisAuth: password,
+		}
+		if err := controllerutil.SetControllerReference(cr, secret, r.Scheme); err != nil {
+			return err
+		}
+		log.Info("creating the redis password secret", "name", secret.Name, "namespace", secret.Namespace)
+		if err := r.Client.Create(context.TODO(), secret); err != nil {
+			return err
+		}
+	}
+
+	var sha256sum string
+	if cr.Spec.Redis.WantsAuth() {
+		password, err := r.getRedisPassword(cr)
+		if err != nil {
+			return err
+		}
+		sha256sum = fmt.Sprintf("%x", sha256.Sum256(password))
+	}
+
+	// The password has changed since we last looked if the calculated checksum
+	// doesn't match the one stored in the status.
+	if cr.Status.RedisAuthChecksum == sha256sum {
+		return nil
+	}
+
+	// We store the value early to prevent a possible restart loop, for the
+	// cost of a possibly missed restart when we cannot update the status
+	// field of the resource.
+	cr.Status.RedisAuthChecksum = sha256sum
+	if err := r.Client.Status().Update(context.TODO(), cr); err != nil {
+		return err
+	}
+
+	// Trigger rollout of redis. The Redis HA proxy is rolled out once the checksum of its configuration, which
+	// includes the one of the password, changes.
+	if cr.Spec.HA.Enabled {
+		// If we rolled out the Redis HA servers, kubernetes would restart the pods one at a time, and the first one
+		// to restart (with the new requirepass, masterauth and sentinel auth-pass) could neither replicate from nor
+		// be monitored alongside the existing pods, which still use the former password. So instead we delete the
+		// stateful set, which will delete all the pods, and it is recreated with the new password.
+		redisSts := newStatefulSetWithSuffix("redis-ha-server", "redis", cr)
+		if argoutil.IsObjectFound(r.Client, redisSts.Namespace, redisSts.Name, redisSts) {
+			log.Info("redis password changed, recreating the redis ha statefulset", "name", redisSts.Name, "namespace", redisSts.Namespace)
+			if err := r.Client.Delete(context.TODO(), redisSts); err != nil {
+				return err
+			}
+		}
+	} else if err := r.triggerRollout(newDeploymentWithSuffix("redis", "redis", cr), "redis.auth.changed"); err != nil {
+		return err
+	}
+
+	// Trigger rollout of the API server, repository server and application controller
+	if err := r.triggerRollout(newDeploymentWithSuffix("server", "server", cr), "redis.auth.changed"); err != nil {
+		return err
+	}
+	if err := r.triggerRollout(newDeploymentWithSuffix("repo-server", "repo-server", cr), "redis.auth.changed"); err != nil {
+		return err
+	}
+	return r.triggerRollout(newStatefulSetWithSuffix("application-controller", "application-controller", cr), "redis.auth.changed")
+}
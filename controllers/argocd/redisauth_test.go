@@ -0,0 +1,286 @@
+package argocd
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	argoproj "github.com/argoproj-labs/argocd-operator/api/v1beta1"
+	"github.com/argoproj-labs/argocd-operator/common"
+	"github.com/argoproj-labs/argocd-operator/controllers/argoutil"
+)
+
+func withRedisAuth(a *argoproj.ArgoCD) {
+	a.Spec.Redis.Auth = &argoproj.ArgoCDRedisAuthSpec{Enabled: true}
+}
+
+func getContainerEnvVar(container corev1.Container, name string) *corev1.EnvVar {
+	for _, env := range container.Env {
+		if env.Name == name {
+			return &env
+		}
+	}
+	return nil
+}
+
+func TestReconcileArgoCD_reconcileRedisAuthSecret(t *testing.T) {
+	logf.SetLogger(ZapLogger(true))
+	a := makeTestArgoCD(withRedisAuth)
+	serverDepl := newDeploymentWithSuffix("server", "server", a)
+	serverDepl.Spec.Template.Labels = map[string]string{common.ArgoCDKeyName: serverDepl.Name}
+
+	resObjs := []client.Object{a, serverDepl}
+	sch := makeTestReconcilerScheme(argoproj.AddToScheme)
+	cl := makeTestReconcilerClient(sch, resObjs, resObjs, []runtime.Object{})
+	r := makeTestReconciler(cl, sch)
+
+	assert.NoError(t, r.reconcileRedisAuthSecret(a))
+
+	secret := &corev1.Secret{}
+	key := types.NamespacedName{Name: "argocd-redis-auth", Namespace: testNamespace}
+	assert.NoError(t, r.Client.Get(context.TODO(), key, secret))
+	assert.True(t, metav1.IsControlledBy(secret, a))
+	password := secret.Data[common.ArgoCDKeyRedisAuth]
+	assert.Len(t, password, common.ArgoCDDefaultRedisPasswordLength)
+	assert.NotEmpty(t, a.Status.RedisAuthChecksum)
+
+	deployment := &appsv1.Deployment{}
+	assert.NoError(t, r.Client.Get(context.TODO(), client.ObjectKeyFromObject(serverDepl), deployment))
+	assert.Contains(t, deployment.Spec.Template.Labels, "redis.auth.changed")
+
+	// the password is kept, and regenerated once its key is removed
+	checksum := a.Status.RedisAuthChecksum
+	assert.NoError(t, r.reconcileRedisAuthSecret(a))
+	assert.Equal(t, checksum, a.Status.RedisAuthChecksum)
+
+	delete(secret.Data, common.ArgoCDKeyRedisAuth)
+	assert.NoError(t, r.Client.Update(context.TODO(), secret))
+	assert.NoError(t, r.reconcileRedisAuthSecret(a))
+
+	assert.NoError(t, r.Client.Get(context.TODO(), key, secret))
+	assert.NotEqual(t, password, secret.Data[common.ArgoCDKeyRedisAuth])
+	assert.NotEqual(t, checksum, a.Status.RedisAuthChecksum)
+
+	// the generated password is removed once Redis no longer requires a password
+	a.Spec.Redis.Auth.Enabled = false
+	assert.NoError(t, r.reconcileRedisAuthSecret(a))
+	assert.False(t, argoutil.IsObjectFound(r.Client, testNamespace, key.Name, secret))
+	assert.Empty(t, a.Status.RedisAuthChecksum)
+}
+
+func TestReconcileArgoCD_reconcileRedisAuthSecret_passwordSecret(t *testing.T) {
+	logf.SetLogger(ZapLogger(true))
+	remote := "redis.example.com:6379"
+	a := makeTestArgoCD(withRedisAuth, func(a *argoproj.ArgoCD) {
+		a.Spec.Redis.Remote = &remote
+	})
+
+	resObjs := []client.Object{a}
+	sch := makeTestReconcilerScheme(argoproj.AddToScheme)
+	cl := makeTestReconcilerClient(sch, resObjs, resObjs, []runtime.Object{})
+	r := makeTestReconciler(cl, sch)
+
+	// the password of a remote Redis cannot be generated
+	assert.EqualError(t, r.reconcileRedisAuthSecret(a), errRemoteRedisPasswordSecretRequired.Error())
+
+	passwordSecret := testSecretKeySelector("remote-redis", "password")
+	a.Spec.Redis.Auth.PasswordSecret = &passwordSecret
+	assert.EqualError(t, r.reconcileRedisAuthSecret(a),
+		"failed to get the Redis password secret remote-redis: secrets \"remote-redis\" not found")
+
+	secret := argoutil.NewSecretWithName(a, "remote-redis")
+	secret.Data = map[string][]byte{"password": []byte("s3cr3t")}
+	assert.NoError(t, r.Client.Create(context.TODO(), secret))
+	assert.NoError(t, r.reconcileRedisAuthSecret(a))
+	assert.NotEmpty(t, a.Status.RedisAuthChecksum)
+	assert.False(t, argoutil.IsObjectFound(r.Client, testNamespace, "argocd-redis-auth", &corev1.Secret{}))
+}
+
+func TestReconcileArgoCD_reconcileRedisAuthSecret_HA(t *testing.T) {
+	logf.SetLogger(ZapLogger(true))
+	t.Setenv("REDIS_CONFIG_PATH", "../../build/redis")
+	a := makeTestArgoCD(func(a *argoproj.ArgoCD) {
+		a.Spec.HA.Enabled = true
+	})
+
+	resObjs := []client.Object{a}
+	sch := makeTestReconcilerScheme(argoproj.AddToScheme)
+	cl := makeTestReconcilerClient(sch, resObjs, resObjs, []runtime.Object{})
+	r := makeTestReconciler(cl, sch)
+
+	assert.NoError(t, r.reconcileRedisAuthSecret(a))
+	assert.NoError(t, r.reconcileRedisStatefulSet(a, false))
+
+	ss := &appsv1.StatefulSet{}
+	key := types.NamespacedName{Name: "argocd-redis-ha-server", Namespace: testNamespace}
+	assert.NoError(t, r.Client.Get(context.TODO(), key, ss))
+
+	// the Redis HA servers are recreated rather than rolled out once Redis requires a password, since the restarted
+	// servers could not talk to the former ones
+	withRedisAuth(a)
+	assert.NoError(t, r.Client.Update(context.TODO(), a))
+	assert.NoError(t, r.reconcileRedisAuthSecret(a))
+	assert.False(t, argoutil.IsObjectFound(r.Client, key.Namespace, key.Name, ss))
+
+	assert.NoError(t, r.reconcileRedisStatefulSet(a, false))
+	assert.NoError(t, r.Client.Get(context.TODO(), key, ss))
+	assert.NotNil(t, getContainerEnvVar(ss.Spec.Template.Spec.Containers[0], "AUTH"))
+
+	// an unchanged password keeps them
+	assert.NoError(t, r.reconcileRedisAuthSecret(a))
+	assert.True(t, argoutil.IsObjectFound(r.Client, key.Namespace, key.Name, ss))
+
+	// and they are recreated once the password changes
+	secret := &corev1.Secret{}
+	assert.NoError(t, r.Client.Get(context.TODO(), types.NamespacedName{Name: "argocd-redis-auth", Namespace: testNamespace}, secret))
+	delete(secret.Data, common.ArgoCDKeyRedisAuth)
+	assert.NoError(t, r.Client.Update(context.TODO(), secret))
+	assert.NoError(t, r.reconcileRedisAuthSecret(a))
+	assert.False(t, argoutil.IsObjectFound(r.Client, key.Namespace, key.Name, ss))
+}
+
+func TestReconcileArgoCD_reconcileServerDeployment_redisAuth(t *testing.T) {
+	logf.SetLogger(ZapLogger(true))
+	a := makeTestArgoCD(withRedisAuth, func(a *argoproj.ArgoCD) {
+		a.Spec.Redis.Auth.Username = "argocd"
+		a.Spec.Server.Env = []corev1.EnvVar{{Name: "REDIS_PASSWORD", Value: "overridden"}}
+	})
+
+	resObjs := []client.Object{a}
+	sch := makeTestReconcilerScheme(argoproj.AddToScheme)
+	cl := makeTestReconcilerClient(sch, resObjs, resObjs, []runtime.Object{})
+	r := makeTestReconciler(cl, sch)
+
+	assert.NoError(t, r.reconcileServerDeployment(a, false))
+
+	deployment := &appsv1.Deployment{}
+	assert.NoError(t, r.Client.Get(context.TODO(), types.NamespacedName{Name: "argocd-server", Namespace: testNamespace}, deployment))
+	container := deployment.Spec.Template.Spec.Containers[0]
+	assert.Equal(t, &corev1.EnvVar{
+		Name: "REDIS_PASSWORD",
+		ValueFrom: &corev1.EnvVarSource{
+			SecretKeyRef: &corev1.SecretKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: "argocd-redis-auth"},
+				Key:                  common.ArgoCDKeyRedisAuth,
+			},
+		},
+	}, getContainerEnvVar(container, "REDIS_PASSWORD"))
+	assert.Equal(t, "argocd", getContainerEnvVar(container, "REDIS_USERNAME").Value)
+
+	// the credentials are removed once Redis no longer requires a password
+	a.Spec.Redis.Auth = nil
+	a.Spec.Server.Env = nil
+	assert.NoError(t, r.reconcileServerDeployment(a, false))
+
+	assert.NoError(t, r.Client.Get(context.TODO(), types.NamespacedName{Name: "argocd-server", Namespace: testNamespace}, deployment))
+	assert.Nil(t, getContainerEnvVar(deployment.Spec.Template.Spec.Containers[0], "REDIS_PASSWORD"))
+}
+
+func TestReconcileArgoCD_reconcileRedisDeployment_redisAuth(t *testing.T) {
+	logf.SetLogger(ZapLogger(true))
+	a := makeTestArgoCD(withRedisAuth)
+
+	resObjs := []client.Object{a}
+	sch := makeTestReconcilerScheme(argoproj.AddToScheme)
+	cl := makeTestReconcilerClient(sch, resObjs, resObjs, []runtime.Object{})
+	r := makeTestReconciler(cl, sch)
+
+	assert.NoError(t, r.reconcileRedisDeployment(a, false))
+
+	deployment := &appsv1.Deployment{}
+	assert.NoError(t, r.Client.Get(context.TODO(), types.NamespacedName{Name: "argocd-redis", Namespace: testNamespace}, deployment))
+	container := deployment.Spec.Template.Spec.Containers[0]
+	assert.Equal(t, []string{"--save", "", "--appendonly", "no", "--requirepass", "$(REDIS_PASSWORD)"}, container.Args)
+	assert.NotNil(t, getContainerEnvVar(container, "REDIS_PASSWORD"))
+}
+
+func TestReconcileArgoCD_reconcileRedisStatefulSet_redisAuth(t *testing.T) {
+	logf.SetLogger(ZapLogger(true))
+	t.Setenv("REDIS_CONFIG_PATH", "../../build/redis")
+	a := makeTestArgoCD(func(a *argoproj.ArgoCD) {
+		a.Spec.HA.Enabled = true
+	})
+
+	resObjs := []client.Object{a}
+	sch := makeTestReconcilerScheme(argoproj.AddToScheme)
+	cl := makeTestReconcilerClient(sch, resObjs, resObjs, []runtime.Object{})
+	r := makeTestReconciler(cl, sch)
+
+	assert.NoError(t, r.reconcileRedisStatefulSet(a, false))
+
+	ss := &appsv1.StatefulSet{}
+	key := types.NamespacedName{Name: "argocd-redis-ha-server", Namespace: testNamespace}
+	assert.NoError(t, r.Client.Get(context.TODO(), key, ss))
+	checksum := ss.Spec.Template.Annotations[common.ArgoCDRedisHAConfigChecksumAnnotation]
+	assert.Nil(t, getContainerEnvVar(ss.Spec.Template.Spec.Containers[0], "AUTH"))
+
+	// the Redis HA servers get the password once Redis requires one, and a new checksum once it is rotated
+	withRedisAuth(a)
+	a.Status.RedisAuthChecksum = "first"
+	assert.NoError(t, r.reconcileRedisStatefulSet(a, false))
+
+	assert.NoError(t, r.Client.Get(context.TODO(), key, ss))
+	assert.NotEqual(t, checksum, ss.Spec.Template.Annotations[common.ArgoCDRedisHAConfigChecksumAnnotation])
+	checksum = ss.Spec.Template.Annotations[common.ArgoCDRedisHAConfigChecksumAnnotation]
+	assert.NotNil(t, getContainerEnvVar(ss.Spec.Template.Spec.Containers[0], "AUTH"))
+	assert.NotNil(t, getContainerEnvVar(ss.Spec.Template.Spec.InitContainers[0], "AUTH"))
+
+	a.Status.RedisAuthChecksum = "rotated"
+	assert.NoError(t, r.reconcileRedisStatefulSet(a, false))
+
+	assert.NoError(t, r.Client.Get(context.TODO(), key, ss))
+	assert.NotEqual(t, checksum, ss.Spec.Template.Annotations[common.ArgoCDRedisHAConfigChecksumAnnotation])
+}
+
+func TestGetRedisHAConfigMapData_redisAuth(t *testing.T) {
+	t.Setenv("REDIS_CONFIG_PATH", "../../build/redis")
+	a := makeTestArgoCD(func(a *argoproj.ArgoCD) {
+		a.Spec.HA.Enabled = true
+	})
+
+	data := argoutil.AppendStringMap(getRedisHAConfigMapData(a, false), getRedisHAHealthConfigMapData(a, false))
+	assert.NotContains(t, data["redis.conf"], "requirepass")
+	assert.NotContains(t, data["sentinel.conf"], "auth-pass")
+	assert.NotContains(t, data["haproxy.cfg"], "AUTH")
+	assert.NotContains(t, data["redis_liveness.sh"], "REDISCLI_AUTH")
+
+	withRedisAuth(a)
+	data = argoutil.AppendStringMap(getRedisHAConfigMapData(a, false), getRedisHAHealthConfigMapData(a, false))
+	assert.Contains(t, data["redis.conf"], "\nrequirepass replace-default-auth\nmasterauth replace-default-auth\n")
+	assert.Contains(t, data["sentinel.conf"], "\n    sentinel auth-pass argocd replace-default-auth\n")
+	assert.Contains(t, data["haproxy.cfg"], "\n    tcp-check send AUTH\\ REPLACE_AUTH_SECRET\\r\\n\n    tcp-check expect string +OK\n")
+	assert.Contains(t, data["init.sh"], "export REDISCLI_AUTH=\"${AUTH}\"")
+	assert.Contains(t, data["redis_liveness.sh"], "export REDISCLI_AUTH=\"${AUTH}\"\nresponse=$(")
+	assert.Contains(t, data["redis_readiness.sh"], "export REDISCLI_AUTH=\"${AUTH}\"\nresponse=$(")
+}
+
+func TestReconcileArgoCD_redisAuthSecretMapper(t *testing.T) {
+	passwordSecret := testSecretKeySelector("redis-password", "password")
+	a := makeTestArgoCD(withRedisAuth, func(a *argoproj.ArgoCD) {
+		a.Spec.Redis.Auth.PasswordSecret = &passwordSecret
+	})
+	other := makeTestArgoCD(func(a *argoproj.ArgoCD) {
+		a.Name = "other"
+	})
+
+	resObjs := []client.Object{a, other}
+	sch := makeTestReconcilerScheme(argoproj.AddToScheme)
+	cl := makeTestReconcilerClient(sch, resObjs, resObjs, []runtime.Object{})
+	r := makeTestReconciler(cl, sch)
+
+	referenced := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "redis-password", Namespace: testNamespace}}
+	assert.Equal(t, []reconcile.Request{{NamespacedName: types.NamespacedName{Name: a.Name, Namespace: testNamespace}}},
+		r.redisAuthSecretMapper(context.TODO(), referenced))
+
+	unrelated := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "unrelated", Namespace: testNamespace}}
+	assert.Empty(t, r.redisAuthSecretMapper(context.TODO(), unrelated))
+}
@@ -0,0 +1,252 @@
+// Copyright 2023 ArgoCD Operator Developers
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package argocd
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+
+	argoproj "github.com/argoproj-labs/argocd-operator/api/v1beta1"
+)
+
+// InstanceState is the state of an Argo CD instance, as last seen by the operator.
+type InstanceState struct {
+	// Phase is the phase of the instance, from its status.
+	Phase string
+
+	// Generation is the generation of the instance.
+	Generation int64
+
+	// LastReconcileTime is the time the last reconciliation of the instance finished.
+	LastReconcileTime time.Time
+
+	// LastReconcileError is the error of the last reconciliation of the instance, empty if it succeeded.
+	LastReconcileError string
+
+	// emittedEvents are the reasons of the events that are only emitted once per instance.
+	emittedEvents map[string]bool
+}
+
+// InstanceRegistry keeps track of the Argo CD instances reconciled by the operator, and drives the instance metrics
+// from them. It is safe for concurrent use, so that instances can be reconciled concurrently.
+type InstanceRegistry struct {
+	mu        sync.RWMutex
+	instances map[types.NamespacedName]*InstanceState
+
+	byPhase *prometheus.GaugeVec
+	total   prometheus.Gauge
+}
+
+// NewInstanceRegistry returns an empty InstanceRegistry, which drives the ActiveInstancesByPhase and
+// ActiveInstancesTotal metrics.
+func NewInstanceRegistry() *InstanceRegistry {
+	return newInstanceRegistry(ActiveInstancesByPhase, ActiveInstancesTotal)
+}
+
+func newInstanceRegistry(byPhase *prometheus.GaugeVec, total prometheus.Gauge) *InstanceRegistry {
+	return &InstanceRegistry{
+		instances: make(map[types.NamespacedName]*InstanceState),
+		byPhase:   byPhase,
+		total:     total,
+	}
+}
+
+// Observe records the phase and generation of the given instance, adding it to the registry if needed.
+func (r *InstanceRegistry) Observe(cr *argoproj.ArgoCD) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := types.NamespacedName{Namespace: cr.Namespace, Name: cr.Name}
+	state, ok := r.instances[key]
+	if !ok {
+		state = &InstanceState{}
+		r.instances[key] = state
+	}
+	if ok && state.Phase == cr.Status.Phase && state.Generation == cr.Generation {
+		return
+	}
+	state.Phase = cr.Status.Phase
+	state.Generation = cr.Generation
+	r.updateMetrics()
+}
+
+// RecordResult records the outcome of a reconciliation of the given instance. It is ignored if the instance is not
+// in the registry, e.g. because it has been deleted.
+func (r *InstanceRegistry) RecordResult(key types.NamespacedName, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	state, ok := r.instances[key]
+	if !ok {
+		return
+	}
+	state.LastReconcileTime = time.Now()
+	state.LastReconcileError = ""
+	if err != nil {
+		state.LastReconcileError = err.Error()
+	}
+}
+
+// Remove removes the given instance from the registry.
+func (r *InstanceRegistry) Remove(key types.NamespacedName) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.instances[key]; !ok {
+		return
+	}
+	delete(r.instances, key)
+	r.updateMetrics()
+}
+
+// RemoveNamespace removes the instances of the given namespace from the registry.
+func (r *InstanceRegistry) RemoveNamespace(namespace string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	changed := false
+	for key := range r.instances {
+		if key.Namespace == namespace {
+			delete(r.instances, key)
+			changed = true
+		}
+	}
+	if changed {
+		r.updateMetrics()
+	}
+}
+
+// HasNamespace returns true if the registry holds an instance of the given namespace.
+func (r *InstanceRegistry) HasNamespace(namespace string) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for key := range r.instances {
+		if key.Namespace == namespace {
+			return true
+		}
+	}
+	return false
+}
+
+// Get returns a copy of the state of the given instance, and whether it is in the registry.
+func (r *InstanceRegistry) Get(key types.NamespacedName) (InstanceState, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	state, ok := r.instances[key]
+	if !ok {
+		return InstanceState{}, false
+	}
+	result := *state
+	result.emittedEvents = nil
+	return result, true
+}
+
+// Len returns the number of instances in the registry.
+func (r *InstanceRegistry) Len() int {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return len(r.instances)
+}
+
+// ShouldEmitEvent returns true the first time it is called for the given instance and event reason, so that
+// warnings such as deprecation notices are only emitted once per instance. It returns false if the instance is not in
+// the registry.
+func (r *InstanceRegistry) ShouldEmitEvent(key types.NamespacedName, reason string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	state, ok := r.instances[key]
+	if !ok || state.emittedEvents[reason] {
+		return false
+	}
+	if state.emittedEvents == nil {
+		state.emittedEvents = make(map[string]bool)
+	}
+	state.emittedEvents[reason] = true
+	return true
+}
+
+// Rebuild replaces the content of the registry with the given instances, e.g. after the operator restarted. The state
+// of the instances that were already in the registry is kept.
+func (r *InstanceRegistry) Rebuild(instances []argoproj.ArgoCD) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	rebuilt := make(map[types.NamespacedName]*InstanceState, len(instances))
+	for _, cr := range instances {
+		key := types.NamespacedName{Namespace: cr.Namespace, Name: cr.Name}
+		state, ok := r.instances[key]
+		if !ok {
+			state = &InstanceState{}
+		}
+		state.Phase = cr.Status.Phase
+		state.Generation = cr.Generation
+		rebuilt[key] = state
+	}
+	r.instances = rebuilt
+	r.updateMetrics()
+}
+
+// updateMetrics sets the instance metrics from the content of the registry, it must be called with the lock held.
+// Instances are only counted once they have a phase.
+func (r *InstanceRegistry) updateMetrics() {
+	counts := map[string]int{}
+	total := 0
+	for _, state := range r.instances {
+		if len(state.Phase) == 0 {
+			continue
+		}
+		counts[state.Phase]++
+		total++
+	}
+
+	r.byPhase.Reset()
+	for phase, count := range counts {
+		r.byPhase.WithLabelValues(phase).Set(float64(count))
+	}
+	r.total.Set(float64(total))
+}
+
+// rebuildInstanceRegistry rebuilds the instance registry from the ArgoCD instances matching the label selector of the
+// operator, so that the instance metrics are accurate right after the operator started.
+func (r *ReconcileArgoCD) rebuildInstanceRegistry(ctx context.Context) error {
+	selector, err := labels.Parse(r.LabelSelector)
+	if err != nil {
+		return err
+	}
+
+	argocds := &argoproj.ArgoCDList{}
+	if err := r.Client.List(ctx, argocds); err != nil {
+		return err
+	}
+
+	instances := make([]argoproj.ArgoCD, 0, len(argocds.Items))
+	for _, cr := range argocds.Items {
+		if cr.GetDeletionTimestamp() != nil || !selector.Matches(labels.Set(cr.Labels)) {
+			continue
+		}
+		instances = append(instances, cr)
+	}
+	r.Instances.Rebuild(instances)
+	log.Info("rebuilt the instance registry", "instances", len(instances))
+	return nil
+}
@@ -0,0 +1,218 @@
+package argocd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	argoproj "github.com/argoproj-labs/argocd-operator/api/v1beta1"
+	"github.com/argoproj-labs/argocd-operator/common"
+)
+
+func makeTestInstanceRegistry() (*InstanceRegistry, *prometheus.GaugeVec, prometheus.Gauge) {
+	byPhase := prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: "test_instances_by_phase"}, []string{"phase"})
+	total := prometheus.NewGauge(prometheus.GaugeOpts{Name: "test_instances_total"})
+	return newInstanceRegistry(byPhase, total), byPhase, total
+}
+
+func makeTestRegistryArgoCD(namespace, name, phase string) *argoproj.ArgoCD {
+	return makeTestArgoCD(func(a *argoproj.ArgoCD) {
+		a.Namespace = namespace
+		a.Name = name
+		a.Status.Phase = phase
+	})
+}
+
+func TestInstanceRegistry_Metrics(t *testing.T) {
+	registry, byPhase, total := makeTestInstanceRegistry()
+
+	a := makeTestRegistryArgoCD("ns-1", "argocd", common.ArgoCDStatusPending)
+	b := makeTestRegistryArgoCD("ns-2", "argocd", common.ArgoCDStatusPending)
+	c := makeTestRegistryArgoCD("ns-2", "other", "")
+	registry.Observe(a)
+	registry.Observe(b)
+	registry.Observe(c)
+
+	// instances without a phase are not counted
+	assert.Equal(t, 3, registry.Len())
+	assert.Equal(t, float64(2), testutil.ToFloat64(total))
+	assert.Equal(t, float64(2), testutil.ToFloat64(byPhase.WithLabelValues(common.ArgoCDStatusPending)))
+
+	a.Status.Phase = "Available"
+	registry.Observe(a)
+	c.Status.Phase = "Available"
+	registry.Observe(c)
+	assert.Equal(t, float64(3), testutil.ToFloat64(total))
+	assert.Equal(t, float64(1), testutil.ToFloat64(byPhase.WithLabelValues(common.ArgoCDStatusPending)))
+	assert.Equal(t, float64(2), testutil.ToFloat64(byPhase.WithLabelValues("Available")))
+
+	registry.Remove(types.NamespacedName{Namespace: "ns-1", Name: "argocd"})
+	assert.Equal(t, float64(2), testutil.ToFloat64(total))
+	assert.Equal(t, float64(1), testutil.ToFloat64(byPhase.WithLabelValues("Available")))
+	assert.True(t, registry.HasNamespace("ns-2"))
+	assert.False(t, registry.HasNamespace("ns-1"))
+
+	registry.RemoveNamespace("ns-2")
+	assert.Equal(t, 0, registry.Len())
+	assert.Equal(t, float64(0), testutil.ToFloat64(total))
+	assert.Equal(t, 0, testutil.CollectAndCount(byPhase))
+}
+
+func TestInstanceRegistry_RecordResult(t *testing.T) {
+	registry, _, _ := makeTestInstanceRegistry()
+	a := makeTestRegistryArgoCD("ns-1", "argocd", common.ArgoCDStatusPending)
+	a.Generation = 2
+	key := types.NamespacedName{Namespace: a.Namespace, Name: a.Name}
+
+	// results of instances that are not tracked are ignored
+	registry.RecordResult(key, nil)
+	_, ok := registry.Get(key)
+	assert.False(t, ok)
+
+	registry.Observe(a)
+	registry.RecordResult(key, errors.New("failed"))
+	state, ok := registry.Get(key)
+	assert.True(t, ok)
+	assert.Equal(t, common.ArgoCDStatusPending, state.Phase)
+	assert.Equal(t, int64(2), state.Generation)
+	assert.Equal(t, "failed", state.LastReconcileError)
+	assert.False(t, state.LastReconcileTime.IsZero())
+
+	registry.RecordResult(key, nil)
+	state, _ = registry.Get(key)
+	assert.Empty(t, state.LastReconcileError)
+}
+
+func TestInstanceRegistry_ShouldEmitEvent(t *testing.T) {
+	registry, _, _ := makeTestInstanceRegistry()
+	a := makeTestRegistryArgoCD("ns-1", "argocd", common.ArgoCDStatusPending)
+	key := types.NamespacedName{Namespace: a.Namespace, Name: a.Name}
+
+	assert.False(t, registry.ShouldEmitEvent(key, "DeprecationNotice"))
+
+	registry.Observe(a)
+	assert.True(t, registry.ShouldEmitEvent(key, "DeprecationNotice"))
+	assert.False(t, registry.ShouldEmitEvent(key, "DeprecationNotice"))
+	assert.True(t, registry.ShouldEmitEvent(key, "OtherNotice"))
+
+	// the events are emitted again for a new instance with the same name
+	registry.Remove(key)
+	registry.Observe(a)
+	assert.True(t, registry.ShouldEmitEvent(key, "DeprecationNotice"))
+}
+
+func TestInstanceRegistry_Rebuild(t *testing.T) {
+	registry, byPhase, total := makeTestInstanceRegistry()
+	kept := makeTestRegistryArgoCD("ns-1", "argocd", common.ArgoCDStatusPending)
+	registry.Observe(kept)
+	registry.Observe(makeTestRegistryArgoCD("ns-2", "argocd", common.ArgoCDStatusPending))
+	registry.RecordResult(types.NamespacedName{Namespace: "ns-1", Name: "argocd"}, errors.New("failed"))
+
+	kept.Status.Phase = "Available"
+	registry.Rebuild([]argoproj.ArgoCD{
+		*kept,
+		*makeTestRegistryArgoCD("ns-3", "argocd", "Available"),
+	})
+
+	assert.Equal(t, 2, registry.Len())
+	assert.False(t, registry.HasNamespace("ns-2"))
+	assert.Equal(t, float64(2), testutil.ToFloat64(total))
+	assert.Equal(t, float64(2), testutil.ToFloat64(byPhase.WithLabelValues("Available")))
+	assert.Equal(t, 1, testutil.CollectAndCount(byPhase))
+
+	state, ok := registry.Get(types.NamespacedName{Namespace: "ns-1", Name: "argocd"})
+	assert.True(t, ok)
+	assert.Equal(t, "Available", state.Phase)
+	assert.Equal(t, "failed", state.LastReconcileError)
+}
+
+func TestInstanceRegistry_Concurrent(t *testing.T) {
+	registry, _, total := makeTestInstanceRegistry()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			a := makeTestRegistryArgoCD(fmt.Sprintf("ns-%d", i), "argocd", common.ArgoCDStatusPending)
+			key := types.NamespacedName{Namespace: a.Namespace, Name: a.Name}
+			for j := 0; j < 50; j++ {
+				registry.Observe(a)
+				registry.RecordResult(key, nil)
+				registry.ShouldEmitEvent(key, "DeprecationNotice")
+				registry.Get(key)
+				registry.HasNamespace(a.Namespace)
+			}
+			if i%2 == 0 {
+				registry.Remove(key)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	assert.Equal(t, 10, registry.Len())
+	assert.Equal(t, float64(10), testutil.ToFloat64(total))
+}
+
+func TestReconcileArgoCD_rebuildInstanceRegistry(t *testing.T) {
+	a := makeTestRegistryArgoCD("ns-1", "argocd", common.ArgoCDStatusPending)
+	a.Labels = map[string]string{"foo": "bar"}
+	b := makeTestRegistryArgoCD("ns-2", "argocd", common.ArgoCDStatusPending)
+	deleted := makeTestRegistryArgoCD("ns-3", "argocd", common.ArgoCDStatusPending)
+	deleted.Labels = map[string]string{"foo": "bar"}
+	deletedAt(time.Now())(deleted)
+
+	resObjs := []client.Object{a, b, deleted}
+	sch := makeTestReconcilerScheme(argoproj.AddToScheme)
+	cl := makeTestReconcilerClient(sch, resObjs, resObjs, []runtime.Object{})
+	r := makeTestReconciler(cl, sch)
+	r.Instances, _, _ = makeTestInstanceRegistry()
+	r.LabelSelector = "foo=bar"
+
+	assert.NoError(t, r.rebuildInstanceRegistry(context.TODO()))
+	assert.Equal(t, 1, r.Instances.Len())
+	assert.True(t, r.Instances.HasNamespace("ns-1"))
+}
+
+func TestReconcileArgoCD_Reconcile_InstanceRegistry(t *testing.T) {
+	logf.SetLogger(ZapLogger(true))
+	a := makeTestArgoCD(func(a *argoproj.ArgoCD) {
+		a.Status.Phase = common.ArgoCDStatusPending
+	})
+
+	resObjs := []client.Object{a}
+	sch := makeTestReconcilerScheme(argoproj.AddToScheme)
+	cl := makeTestReconcilerClient(sch, resObjs, resObjs, []runtime.Object{})
+	r := makeTestReconciler(cl, sch)
+	r.Instances, _, _ = makeTestInstanceRegistry()
+	assert.NoError(t, createNamespace(r, a.Namespace, ""))
+
+	key := types.NamespacedName{Name: a.Name, Namespace: a.Namespace}
+	_, err := r.Reconcile(context.TODO(), reconcile.Request{NamespacedName: key})
+	assert.NoError(t, err)
+
+	state, ok := r.Instances.Get(key)
+	assert.True(t, ok)
+	assert.Empty(t, state.LastReconcileError)
+	assert.False(t, state.LastReconcileTime.IsZero())
+
+	// the instance is removed once it is deleted
+	assert.NoError(t, r.Client.Get(context.TODO(), key, a))
+	assert.NoError(t, r.Client.Delete(context.TODO(), a))
+	_, err = r.Reconcile(context.TODO(), reconcile.Request{NamespacedName: key})
+	assert.NoError(t, err)
+	_, ok = r.Instances.Get(key)
+	assert.False(t, ok)
+}
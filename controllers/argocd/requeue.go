@@ -0,0 +1,78 @@
+// Copyright 2023 ArgoCD Operator Developers
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package argocd
+
+import (
+	"time"
+
+	"golang.org/x/time/rate"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/workqueue"
+
+	argoproj "github.com/argoproj-labs/argocd-operator/api/v1beta1"
+	"github.com/argoproj-labs/argocd-operator/common"
+)
+
+// NewRateLimiter returns the rate limiter of the ArgoCD controller. A failing instance is retried after an exponential
+// backoff, from baseDelay up to maxDelay, so that it does not starve the other instances, while the overall rate of
+// reconciliations is limited to qps, with the given burst.
+func NewRateLimiter(baseDelay, maxDelay time.Duration, qps float32, burst int) workqueue.RateLimiter {
+	return workqueue.NewMaxOfRateLimiter(
+		workqueue.NewItemExponentialFailureRateLimiter(baseDelay, maxDelay),
+		&workqueue.BucketRateLimiter{Limiter: rate.NewLimiter(rate.Limit(qps), burst)},
+	)
+}
+
+// requeueAfter returns the interval after which the given ArgoCD is reconciled again once it has been successfully
+// reconciled, zero if it is only reconciled on changes. The default interval of the operator is overridden by the
+// requeue-after annotation of the instance. The interval is shortened so that the certificates generated by the
+// operator are renewed in time.
+func (r *ReconcileArgoCD) requeueAfter(cr *argoproj.ArgoCD) time.Duration {
+	interval := r.RequeueAfter
+	if value, ok := cr.Annotations[common.ArgoCDRequeueAfterAnnotation]; ok {
+		parsed, err := time.ParseDuration(value)
+		if err != nil || parsed < 0 {
+			log.Info("ignoring invalid requeue interval", "annotation", common.ArgoCDRequeueAfterAnnotation, "value", value, "namespace", cr.Namespace, "name", cr.Name)
+		} else {
+			interval = parsed
+		}
+	}
+
+	if renewal := getCertificateRenewalInterval(cr); renewal > 0 && (interval == 0 || renewal < interval) {
+		interval = renewal
+	}
+	return interval
+}
+
+// getCertificateRenewalInterval returns the interval after which the first of the certificates recorded in the status
+// of the given ArgoCD is due for renewal, zero if none is recorded. The certificates already due for renewal, which
+// the operator could not renew, are ignored.
+func getCertificateRenewalInterval(cr *argoproj.ArgoCD) time.Duration {
+	if cr.Status.TLS == nil {
+		return 0
+	}
+
+	var interval time.Duration
+	for _, notAfter := range []*metav1.Time{cr.Status.TLS.CANotAfter, cr.Status.TLS.NotAfter} {
+		if notAfter == nil {
+			continue
+		}
+		renewal := time.Until(notAfter.Add(-getCertificateRenewBefore(cr)))
+		if renewal > 0 && (interval == 0 || renewal < interval) {
+			interval = renewal
+		}
+	}
+	return interval
+}
@@ -0,0 +1,106 @@
+package argocd
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	argoproj "github.com/argoproj-labs/argocd-operator/api/v1beta1"
+	"github.com/argoproj-labs/argocd-operator/common"
+)
+
+func withRequeueAfter(value string) argoCDOpt {
+	return func(a *argoproj.ArgoCD) {
+		a.Annotations = map[string]string{common.ArgoCDRequeueAfterAnnotation: value}
+	}
+}
+
+func TestReconcileArgoCD_requeueAfter(t *testing.T) {
+	r := &ReconcileArgoCD{RequeueAfter: 10 * time.Minute}
+
+	tests := []struct {
+		name string
+		cr   *argoproj.ArgoCD
+		want time.Duration
+	}{
+		{"default interval", makeTestArgoCD(), 10 * time.Minute},
+		{"interval of the instance", makeTestArgoCD(withRequeueAfter("2m")), 2 * time.Minute},
+		{"disabled for the instance", makeTestArgoCD(withRequeueAfter("0")), 0},
+		{"invalid interval", makeTestArgoCD(withRequeueAfter("often")), 10 * time.Minute},
+		{"negative interval", makeTestArgoCD(withRequeueAfter("-1m")), 10 * time.Minute},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.want, r.requeueAfter(test.cr))
+		})
+	}
+}
+
+func TestReconcileArgoCD_requeueAfter_certificateRenewal(t *testing.T) {
+	r := &ReconcileArgoCD{RequeueAfter: 10 * time.Minute}
+	withExpiry := func(notAfter time.Time) argoCDOpt {
+		return func(a *argoproj.ArgoCD) {
+			expiry := metav1.NewTime(notAfter)
+			a.Spec.TLS.RenewBefore = &metav1.Duration{Duration: time.Hour}
+			a.Status.TLS = &argoproj.ArgoCDTLSStatus{NotAfter: &expiry}
+		}
+	}
+
+	// the instance is reconciled again when its certificate is due for renewal
+	requeue := r.requeueAfter(makeTestArgoCD(withRequeueAfter("0"), withExpiry(time.Now().Add(2*time.Hour))))
+	assert.InDelta(t, float64(time.Hour), float64(requeue), float64(time.Minute))
+
+	// a shorter interval is kept
+	requeue = r.requeueAfter(makeTestArgoCD(withExpiry(time.Now().Add(2 * time.Hour))))
+	assert.Equal(t, 10*time.Minute, requeue)
+
+	// a certificate already due for renewal is ignored
+	requeue = r.requeueAfter(makeTestArgoCD(withRequeueAfter("0"), withExpiry(time.Now())))
+	assert.Equal(t, time.Duration(0), requeue)
+}
+
+func TestNewRateLimiter(t *testing.T) {
+	limiter := NewRateLimiter(10*time.Millisecond, 40*time.Millisecond, 1000, 100)
+
+	// failures of an instance are retried with an exponential backoff, without delaying the other instances
+	assert.Equal(t, 10*time.Millisecond, limiter.When("argocd/a"))
+	assert.Equal(t, 20*time.Millisecond, limiter.When("argocd/a"))
+	assert.Equal(t, 40*time.Millisecond, limiter.When("argocd/a"))
+	assert.Equal(t, 40*time.Millisecond, limiter.When("argocd/a"))
+	assert.Equal(t, 10*time.Millisecond, limiter.When("argocd/b"))
+	assert.Equal(t, 4, limiter.NumRequeues("argocd/a"))
+
+	limiter.Forget("argocd/a")
+	assert.Equal(t, 10*time.Millisecond, limiter.When("argocd/a"))
+}
+
+func TestNewRateLimiter_Bucket(t *testing.T) {
+	limiter := NewRateLimiter(time.Millisecond, time.Millisecond, 1, 1)
+
+	// the overall rate of reconciliations is limited once the burst is used
+	assert.Equal(t, time.Millisecond, limiter.When("argocd/a"))
+	assert.Greater(t, limiter.When("argocd/b"), 500*time.Millisecond)
+}
+
+func TestReconcileArgoCD_Reconcile_RequeueAfter(t *testing.T) {
+	logf.SetLogger(ZapLogger(true))
+	a := makeTestArgoCD(withRequeueAfter("5m"))
+
+	resObjs := []client.Object{a}
+	sch := makeTestReconcilerScheme(argoproj.AddToScheme)
+	cl := makeTestReconcilerClient(sch, resObjs, resObjs, []runtime.Object{})
+	r := makeTestReconciler(cl, sch)
+	assert.NoError(t, createNamespace(r, a.Namespace, ""))
+
+	res, err := r.Reconcile(context.TODO(), reconcile.Request{NamespacedName: types.NamespacedName{Name: a.Name, Namespace: a.Namespace}})
+	assert.NoError(t, err)
+	assert.Equal(t, 5*time.Minute, res.RequeueAfter)
+}
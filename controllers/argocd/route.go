@@ -19,6 +19,7 @@ import (
 	"fmt"
 
 	routev1 "github.com/openshift/api/route/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
@@ -94,20 +95,73 @@ func (r *ReconcileArgoCD) reconcileRoutes(cr *argoproj.ArgoCD) error {
 	return nil
 }
 
-// reconcileGrafanaRoute will ensure that the ArgoCD Grafana Route is present.
-func (r *ReconcileArgoCD) reconcileGrafanaRoute(cr *argoproj.ArgoCD) error {
-	route := newRouteWithSuffix("grafana", cr)
-	if argoutil.IsObjectFound(r.Client, cr.Namespace, route.Name, route) {
-		if !cr.Spec.Grafana.Enabled || !cr.Spec.Grafana.Route.Enabled {
+// reconcileRoute will ensure that the given desired Route is present when enabled, and absent otherwise. The
+// annotations, labels and spec of an existing Route are updated to match the desired Route, reverting any manual
+// change, which is recorded as an event of the given ArgoCD. Annotations and labels added by others, such as the
+// annotation set by OpenShift when it generates the host of the Route, are kept, while the ones no longer desired are
+// removed.
+func (r *ReconcileArgoCD) reconcileRoute(cr *argoproj.ArgoCD, desired *routev1.Route, enabled bool) error {
+	existing := &routev1.Route{}
+	if argoutil.IsObjectFound(r.Client, desired.Namespace, desired.Name, existing) {
+		if !enabled {
 			// Route exists but enabled flag has been set to false, delete the Route
-			return r.Client.Delete(context.TODO(), route)
+			return r.Client.Delete(context.TODO(), existing)
+		}
+
+		changed := mergeDesiredMetadata(existing, desired)
+		spec := desiredRouteSpec(existing, desired)
+		if !equality.Semantic.DeepEqual(existing.Spec, spec) {
+			existing.Spec = spec
+			changed = true
+		}
+		if !changed {
+			return nil // Route found and up to date, do nothing
 		}
-		return nil // Route found, do nothing
+
+		log.Info("updating route to match the ArgoCD spec", "name", existing.Name, "namespace", existing.Namespace)
+		if err := r.Client.Update(context.TODO(), existing); err != nil {
+			return err
+		}
+		r.createDriftEvent(cr, "Route", existing.Name)
+		return nil
+	}
+
+	if !enabled {
+		return nil // Route not enabled, move along...
 	}
 
-	if !cr.Spec.Grafana.Enabled || !cr.Spec.Grafana.Route.Enabled {
-		return nil // Grafana itself or Route not enabled, do nothing.
+	route := desired.DeepCopy()
+	setManagedMetadata(route)
+	if err := controllerutil.SetControllerReference(cr, route, r.Scheme); err != nil {
+		return err
+	}
+	return r.Client.Create(context.TODO(), route)
+}
+
+// desiredRouteSpec returns the spec of the given desired Route, with the fields left empty in it and defaulted by
+// OpenShift taken from the given existing Route, so that they are not reported as changes.
+func desiredRouteSpec(existing, desired *routev1.Route) routev1.RouteSpec {
+	spec := *desired.Spec.DeepCopy()
+	if len(spec.Host) == 0 {
+		spec.Host = existing.Spec.Host
+	}
+	if spec.To.Weight == nil {
+		spec.To.Weight = existing.Spec.To.Weight
+	}
+	if len(spec.WildcardPolicy) == 0 && existing.Spec.WildcardPolicy == routev1.WildcardPolicyNone {
+		spec.WildcardPolicy = existing.Spec.WildcardPolicy
 	}
+	return spec
+}
+
+// reconcileGrafanaRoute will ensure that the ArgoCD Grafana Route is present and up to date.
+func (r *ReconcileArgoCD) reconcileGrafanaRoute(cr *argoproj.ArgoCD) error {
+	return r.reconcileRoute(cr, newGrafanaRoute(cr), cr.Spec.Grafana.Enabled && cr.Spec.Grafana.Route.Enabled)
+}
+
+// newGrafanaRoute returns the desired ArgoCD Grafana Route for the given ArgoCD.
+func newGrafanaRoute(cr *argoproj.ArgoCD) *routev1.Route {
+	route := newRouteWithSuffix("grafana", cr)
 
 	// Allow override of the Annotations for the Route.
 	if len(cr.Spec.Grafana.Route.Annotations) > 0 {
@@ -150,26 +204,17 @@ func (r *ReconcileArgoCD) reconcileGrafanaRoute(cr *argoproj.ArgoCD) error {
 		route.Spec.WildcardPolicy = *cr.Spec.Grafana.Route.WildcardPolicy
 	}
 
-	if err := controllerutil.SetControllerReference(cr, route, r.Scheme); err != nil {
-		return err
-	}
-	return r.Client.Create(context.TODO(), route)
+	return route
 }
 
-// reconcilePrometheusRoute will ensure that the ArgoCD Prometheus Route is present.
+// reconcilePrometheusRoute will ensure that the ArgoCD Prometheus Route is present and up to date.
 func (r *ReconcileArgoCD) reconcilePrometheusRoute(cr *argoproj.ArgoCD) error {
-	route := newRouteWithSuffix("prometheus", cr)
-	if argoutil.IsObjectFound(r.Client, cr.Namespace, route.Name, route) {
-		if !cr.Spec.Prometheus.Enabled || !cr.Spec.Prometheus.Route.Enabled {
-			// Route exists but enabled flag has been set to false, delete the Route
-			return r.Client.Delete(context.TODO(), route)
-		}
-		return nil // Route found, do nothing
-	}
+	return r.reconcileRoute(cr, newPrometheusRoute(cr), cr.Spec.Prometheus.Enabled && cr.Spec.Prometheus.Route.Enabled)
+}
 
-	if !cr.Spec.Prometheus.Enabled || !cr.Spec.Prometheus.Route.Enabled {
-		return nil // Prometheus itself or Route not enabled, do nothing.
-	}
+// newPrometheusRoute returns the desired ArgoCD Prometheus Route for the given ArgoCD.
+func newPrometheusRoute(cr *argoproj.ArgoCD) *routev1.Route {
+	route := newRouteWithSuffix("prometheus", cr)
 
 	// Allow override of the Annotations for the Route.
 	if len(cr.Spec.Prometheus.Route.Annotations) > 0 {
@@ -207,27 +252,17 @@ func (r *ReconcileArgoCD) reconcilePrometheusRoute(cr *argoproj.ArgoCD) error {
 		route.Spec.WildcardPolicy = *cr.Spec.Prometheus.Route.WildcardPolicy
 	}
 
-	if err := controllerutil.SetControllerReference(cr, route, r.Scheme); err != nil {
-		return err
-	}
-	return r.Client.Create(context.TODO(), route)
+	return route
 }
 
-// reconcileServerRoute will ensure that the ArgoCD Server Route is present.
+// reconcileServerRoute will ensure that the ArgoCD Server Route is present and up to date.
 func (r *ReconcileArgoCD) reconcileServerRoute(cr *argoproj.ArgoCD) error {
+	return r.reconcileRoute(cr, newServerRoute(cr), cr.Spec.Server.Route.Enabled)
+}
 
+// newServerRoute returns the desired ArgoCD Server Route for the given ArgoCD.
+func newServerRoute(cr *argoproj.ArgoCD) *routev1.Route {
 	route := newRouteWithSuffix("server", cr)
-	found := argoutil.IsObjectFound(r.Client, cr.Namespace, route.Name, route)
-	if found {
-		if !cr.Spec.Server.Route.Enabled {
-			// Route exists but enabled flag has been set to false, delete the Route
-			return r.Client.Delete(context.TODO(), route)
-		}
-	}
-
-	if !cr.Spec.Server.Route.Enabled {
-		return nil // Route not enabled, move along...
-	}
 
 	// Allow override of the Annotations for the Route.
 	if len(cr.Spec.Server.Route.Annotations) > 0 {
@@ -281,64 +316,57 @@ func (r *ReconcileArgoCD) reconcileServerRoute(cr *argoproj.ArgoCD) error {
 		route.Spec.WildcardPolicy = *cr.Spec.Server.Route.WildcardPolicy
 	}
 
-	if err := controllerutil.SetControllerReference(cr, route, r.Scheme); err != nil {
-		return err
-	}
-	if !found {
-		return r.Client.Create(context.TODO(), route)
-	}
-	return r.Client.Update(context.TODO(), route)
+	return route
 }
 
-// reconcileApplicationSetControllerWebhookRoute will ensure that the ArgoCD Server Route is present.
+// reconcileApplicationSetControllerWebhookRoute will ensure that the ApplicationSetController webhook Route is present
+// and up to date.
 func (r *ReconcileArgoCD) reconcileApplicationSetControllerWebhookRoute(cr *argoproj.ArgoCD) error {
 	name := fmt.Sprintf("%s-%s", common.ApplicationSetServiceNameSuffix, "webhook")
-	route := newRouteWithSuffix(name, cr)
-	found := argoutil.IsObjectFound(r.Client, cr.Namespace, route.Name, route)
-	if found {
-		if cr.Spec.ApplicationSet == nil || !cr.Spec.ApplicationSet.WebhookServer.Route.Enabled {
-			// Route exists but enabled flag has been set to false, delete the Route
-			return r.Client.Delete(context.TODO(), route)
-		}
+	enabled := cr.Spec.ApplicationSet != nil && cr.Spec.ApplicationSet.WebhookServer.Route.Enabled
+	if !enabled {
+		return r.reconcileRoute(cr, newRouteWithSuffix(name, cr), false)
 	}
+	return r.reconcileRoute(cr, newApplicationSetControllerWebhookRoute(cr), true)
+}
 
-	if cr.Spec.ApplicationSet == nil || !cr.Spec.ApplicationSet.WebhookServer.Route.Enabled {
-		return nil // Route not enabled, move along...
-	}
+// newApplicationSetControllerWebhookRoute returns the desired ApplicationSetController webhook Route for the given
+// ArgoCD.
+func newApplicationSetControllerWebhookRoute(cr *argoproj.ArgoCD) *routev1.Route {
+	name := fmt.Sprintf("%s-%s", common.ApplicationSetServiceNameSuffix, "webhook")
+	route := newRouteWithSuffix(name, cr)
+	webhookRoute := cr.Spec.ApplicationSet.WebhookServer.Route
 
 	// Allow override of the Annotations for the Route.
-	if len(cr.Spec.ApplicationSet.WebhookServer.Route.Annotations) > 0 {
-		route.Annotations = cr.Spec.Server.Route.Annotations
+	if len(webhookRoute.Annotations) > 0 {
+		route.Annotations = webhookRoute.Annotations
 	}
 
 	// Allow override of the Labels for the Route.
-	if len(cr.Spec.ApplicationSet.WebhookServer.Route.Labels) > 0 {
+	if len(webhookRoute.Labels) > 0 {
 		labels := route.Labels
-		for key, val := range cr.Spec.Server.Route.Labels {
+		for key, val := range webhookRoute.Labels {
 			labels[key] = val
 		}
 		route.Labels = labels
 	}
 
 	// Allow override of the Host for the Route.
-	if len(cr.Spec.Server.Host) > 0 {
+	if len(cr.Spec.ApplicationSet.WebhookServer.Host) > 0 {
 		route.Spec.Host = cr.Spec.ApplicationSet.WebhookServer.Host
 	}
 
+	route.Spec.Port = &routev1.RoutePort{
+		TargetPort: intstr.FromString("webhook"),
+	}
 	if cr.Spec.Server.Insecure {
 		// Disable TLS and rely on the cluster certificate.
-		route.Spec.Port = &routev1.RoutePort{
-			TargetPort: intstr.FromString("webhook"),
-		}
 		route.Spec.TLS = &routev1.TLSConfig{
 			InsecureEdgeTerminationPolicy: routev1.InsecureEdgeTerminationPolicyRedirect,
 			Termination:                   routev1.TLSTerminationEdge,
 		}
 	} else {
 		// Server is using TLS configure passthrough.
-		route.Spec.Port = &routev1.RoutePort{
-			TargetPort: intstr.FromString("webhook"),
-		}
 		route.Spec.TLS = &routev1.TLSConfig{
 			InsecureEdgeTerminationPolicy: routev1.InsecureEdgeTerminationPolicyRedirect,
 			Termination:                   routev1.TLSTerminationPassthrough,
@@ -346,23 +374,17 @@ func (r *ReconcileArgoCD) reconcileApplicationSetControllerWebhookRoute(cr *argo
 	}
 
 	// Allow override of TLS options for the Route
-	if cr.Spec.Server.Route.TLS != nil {
-		route.Spec.TLS = cr.Spec.Server.Route.TLS
+	if webhookRoute.TLS != nil {
+		route.Spec.TLS = webhookRoute.TLS
 	}
 
 	route.Spec.To.Kind = "Service"
 	route.Spec.To.Name = nameWithSuffix(common.ApplicationSetServiceNameSuffix, cr)
 
 	// Allow override of the WildcardPolicy for the Route
-	if cr.Spec.Server.Route.WildcardPolicy != nil && len(*cr.Spec.Server.Route.WildcardPolicy) > 0 {
-		route.Spec.WildcardPolicy = *cr.Spec.Server.Route.WildcardPolicy
+	if webhookRoute.WildcardPolicy != nil && len(*webhookRoute.WildcardPolicy) > 0 {
+		route.Spec.WildcardPolicy = *webhookRoute.WildcardPolicy
 	}
 
-	if err := controllerutil.SetControllerReference(cr, route, r.Scheme); err != nil {
-		return err
-	}
-	if !found {
-		return r.Client.Create(context.TODO(), route)
-	}
-	return r.Client.Update(context.TODO(), route)
+	return route
 }
@@ -229,8 +229,9 @@ func makeReconciler(t *testing.T, acd *argoproj.ArgoCD, objs ...runtime.Object)
 	cl := fake.NewClientBuilder().WithScheme(s).WithRuntimeObjects(objs...).WithStatusSubresource(clientObjs...).Build()
 
 	return &ReconcileArgoCD{
-		Client: cl,
-		Scheme: s,
+		Client:    cl,
+		Scheme:    s,
+		Instances: NewInstanceRegistry(),
 	}
 }
 
@@ -269,3 +270,98 @@ func testNamespacedName(name string) types.NamespacedName {
 		Namespace: testNamespace,
 	}
 }
+
+func TestReconcileArgoCD_reconcileServerRoute_drift(t *testing.T) {
+	logf.SetLogger(ZapLogger(true))
+	argoCD := makeArgoCD(func(a *argoproj.ArgoCD) {
+		a.Spec.Server.Route.Enabled = true
+	})
+
+	resObjs := []client.Object{argoCD}
+	subresObjs := []client.Object{argoCD}
+	runtimeObjs := []runtime.Object{}
+	sch := makeTestReconcilerScheme(argoproj.AddToScheme, configv1.Install, routev1.Install)
+	cl := makeTestReconcilerClient(sch, resObjs, subresObjs, runtimeObjs)
+	r := makeTestReconciler(cl, sch)
+
+	assert.NoError(t, r.reconcileServerRoute(argoCD))
+	key := types.NamespacedName{Name: testArgoCDName + "-server", Namespace: testNamespace}
+	route := &routev1.Route{}
+	assert.NoError(t, r.Client.Get(context.TODO(), key, route))
+
+	// the fields defaulted by OpenShift are not reverted
+	weight := int32(100)
+	route.Annotations["openshift.io/host.generated"] = "true"
+	route.Spec.Host = "argocd-server-argocd.apps.example.com"
+	route.Spec.To.Weight = &weight
+	route.Spec.WildcardPolicy = routev1.WildcardPolicyNone
+	assert.NoError(t, r.Client.Update(context.TODO(), route))
+	resourceVersion := route.ResourceVersion
+	assert.NoError(t, r.reconcileServerRoute(argoCD))
+	assert.NoError(t, r.Client.Get(context.TODO(), key, route))
+	assert.Equal(t, resourceVersion, route.ResourceVersion)
+
+	// manual changes are reverted
+	route.Spec.TLS = nil
+	route.Spec.To.Name = "other"
+	assert.NoError(t, r.Client.Update(context.TODO(), route))
+	assert.NoError(t, r.reconcileServerRoute(argoCD))
+	assert.NoError(t, r.Client.Get(context.TODO(), key, route))
+	assert.Equal(t, routev1.TLSTerminationPassthrough, route.Spec.TLS.Termination)
+	assert.Equal(t, testArgoCDName+"-server", route.Spec.To.Name)
+	assert.Equal(t, "argocd-server-argocd.apps.example.com", route.Spec.Host)
+
+	// changes of the spec are applied to the Route
+	argoCD.Spec.Server.Host = "argocd.example.com"
+	argoCD.Spec.Server.Route.Annotations = map[string]string{"example.com/annotation": "value"}
+	assert.NoError(t, r.reconcileServerRoute(argoCD))
+	assert.NoError(t, r.Client.Get(context.TODO(), key, route))
+	assert.Equal(t, "argocd.example.com", route.Spec.Host)
+	assert.Equal(t, "value", route.Annotations["example.com/annotation"])
+
+	events := &corev1.EventList{}
+	assert.NoError(t, r.Client.List(context.TODO(), events, client.InNamespace(testNamespace)))
+	assert.Len(t, events.Items, 2)
+	assert.Equal(t, "RouteUpdated", events.Items[0].Reason)
+}
+
+func TestReconcileArgoCD_reconcileApplicationSetControllerWebhookRoute(t *testing.T) {
+	logf.SetLogger(ZapLogger(true))
+	argoCD := makeArgoCD(func(a *argoproj.ArgoCD) {
+		a.Spec.Server.Route.Annotations = map[string]string{"example.com/server": "value"}
+		a.Spec.ApplicationSet = &argoproj.ArgoCDApplicationSet{
+			WebhookServer: argoproj.WebhookServerSpec{
+				Host: "webhook.example.com",
+				Route: argoproj.ArgoCDRouteSpec{
+					Enabled:     true,
+					Annotations: map[string]string{"example.com/webhook": "value"},
+				},
+			},
+		}
+	})
+
+	resObjs := []client.Object{argoCD}
+	subresObjs := []client.Object{argoCD}
+	runtimeObjs := []runtime.Object{}
+	sch := makeTestReconcilerScheme(argoproj.AddToScheme, configv1.Install, routev1.Install)
+	cl := makeTestReconcilerClient(sch, resObjs, subresObjs, runtimeObjs)
+	r := makeTestReconciler(cl, sch)
+
+	assert.NoError(t, r.reconcileApplicationSetControllerWebhookRoute(argoCD))
+	route := &routev1.Route{}
+	key := types.NamespacedName{Name: testArgoCDName + "-applicationset-controller-webhook", Namespace: testNamespace}
+	assert.NoError(t, r.Client.Get(context.TODO(), key, route))
+	assert.Equal(t, "value", route.Annotations["example.com/webhook"])
+	assert.NotContains(t, route.Annotations, "example.com/server")
+	assert.Equal(t, "webhook.example.com", route.Spec.Host)
+
+	// an annotation removed from the spec is removed from the Route
+	argoCD.Spec.ApplicationSet.WebhookServer.Route.Annotations = nil
+	assert.NoError(t, r.reconcileApplicationSetControllerWebhookRoute(argoCD))
+	assert.NoError(t, r.Client.Get(context.TODO(), key, route))
+	assert.NotContains(t, route.Annotations, "example.com/webhook")
+
+	argoCD.Spec.ApplicationSet.WebhookServer.Route.Enabled = false
+	assert.NoError(t, r.reconcileApplicationSetControllerWebhookRoute(argoCD))
+	assert.Error(t, r.Client.Get(context.TODO(), key, route))
+}
@@ -35,6 +35,7 @@ import (
 
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -191,6 +192,14 @@ func (r *ReconcileArgoCD) reconcileArgoSecret(cr *argoproj.ArgoCD) error {
 		secret.Data[common.ArgoCDDexSecretKey] = []byte(*dexOIDCClientSecret)
 	}
 
+	if UseDex(cr) {
+		dexConnectorData, err := r.getDexConnectorSecretData(cr)
+		if err != nil {
+			return err
+		}
+		updateDexConnectorSecretData(secret, dexConnectorData)
+	}
+
 	if err := controllerutil.SetControllerReference(cr, secret, r.Scheme); err != nil {
 		return err
 	}
@@ -219,11 +228,14 @@ func (r *ReconcileArgoCD) reconcileClusterMainSecret(cr *argoproj.ArgoCD) error
 	return r.Client.Create(context.TODO(), secret)
 }
 
-// reconcileClusterTLSSecret ensures the TLS Secret is created for the ArgoCD cluster.
+// reconcileClusterTLSSecret ensures the TLS Secret is created for the ArgoCD cluster. The certificate of an existing
+// Secret is renewed once it is due for renewal, or signed again when the CA has been renewed. A Secret that is not
+// controlled by the ArgoCD is never renewed.
 func (r *ReconcileArgoCD) reconcileClusterTLSSecret(cr *argoproj.ArgoCD) error {
 	secret := argoutil.NewTLSSecret(cr, "tls")
-	if argoutil.IsObjectFound(r.Client, cr.Namespace, secret.Name, secret) {
-		return nil // Secret found, do nothing
+	found := argoutil.IsObjectFound(r.Client, cr.Namespace, secret.Name, secret)
+	if found && !metav1.IsControlledBy(secret, cr) {
+		return nil // Secret provided by the user, do nothing
 	}
 
 	caSecret := argoutil.NewSecretWithSuffix(cr, "ca")
@@ -242,23 +254,41 @@ func (r *ReconcileArgoCD) reconcileClusterTLSSecret(cr *argoproj.ArgoCD) error {
 		return err
 	}
 
-	secret, err = newCertificateSecret("tls", caCert, caKey, cr)
+	if found {
+		cert, err := argoutil.ParsePEMEncodedCert(secret.Data[corev1.TLSCertKey])
+		if err == nil && !isCertificateDueForRenewal(cr, cert) && cert.CheckSignatureFrom(caCert) == nil {
+			return nil // Secret found and valid, do nothing
+		}
+	}
+
+	desired, err := newCertificateSecret("tls", caCert, caKey, cr)
 	if err != nil {
 		return err
 	}
 
-	if err := controllerutil.SetControllerReference(cr, secret, r.Scheme); err != nil {
+	if found {
+		log.Info("renewing TLS secret", "name", secret.Name, "namespace", secret.Namespace)
+		secret.Data = desired.Data
+		if err := r.Client.Update(context.TODO(), secret); err != nil {
+			return err
+		}
+		r.createCertificateEvent(cr, corev1.EventTypeNormal, "CertificateRenewed", fmt.Sprintf("The TLS certificate of secret %s was renewed.", secret.Name))
+		return nil
+	}
+
+	if err := controllerutil.SetControllerReference(cr, desired, r.Scheme); err != nil {
 		return err
 	}
 
-	return r.Client.Create(context.TODO(), secret)
+	return r.Client.Create(context.TODO(), desired)
 }
 
-// reconcileClusterCASecret ensures the CA Secret is created for the ArgoCD cluster.
+// reconcileClusterCASecret ensures the CA Secret is created for the ArgoCD cluster. The certificate of an existing
+// Secret is renewed once it is due for renewal, unless the Secret is not controlled by the ArgoCD.
 func (r *ReconcileArgoCD) reconcileClusterCASecret(cr *argoproj.ArgoCD) error {
 	secret := argoutil.NewSecretWithSuffix(cr, "ca")
 	if argoutil.IsObjectFound(r.Client, cr.Namespace, secret.Name, secret) {
-		return nil // Secret found, do nothing
+		return r.renewClusterCASecret(cr, secret)
 	}
 
 	secret, err := newCASecret(cr)
@@ -272,6 +302,104 @@ func (r *ReconcileArgoCD) reconcileClusterCASecret(cr *argoproj.ArgoCD) error {
 	return r.Client.Create(context.TODO(), secret)
 }
 
+// renewClusterCASecret renews the certificate of the given existing CA Secret once it is due for renewal. The
+// certificates signed by the CA are signed again by reconcileClusterTLSSecret.
+func (r *ReconcileArgoCD) renewClusterCASecret(cr *argoproj.ArgoCD, secret *corev1.Secret) error {
+	cert, err := argoutil.ParsePEMEncodedCert(secret.Data[corev1.TLSCertKey])
+	if err == nil && !isCertificateDueForRenewal(cr, cert) {
+		return nil // Secret found and valid, do nothing
+	}
+	if !metav1.IsControlledBy(secret, cr) {
+		return nil // Secret provided by the user, do nothing
+	}
+
+	renewed, err := newCASecret(cr)
+	if err != nil {
+		return err
+	}
+
+	log.Info("renewing CA secret", "name", secret.Name, "namespace", secret.Namespace)
+	secret.Data = renewed.Data
+	if err := r.Client.Update(context.TODO(), secret); err != nil {
+		return err
+	}
+	r.createCertificateEvent(cr, corev1.EventTypeNormal, "CertificateRenewed", fmt.Sprintf("The CA certificate of secret %s was renewed.", secret.Name))
+	return nil
+}
+
+// getCertificateRenewBefore returns the time before their expiry at which the certificates generated by the operator
+// for the given ArgoCD are renewed. The default is used instead of a time that is not within the lifetime of the
+// certificates, which would renew them on every reconciliation.
+func getCertificateRenewBefore(cr *argoproj.ArgoCD) time.Duration {
+	if renewBefore := cr.Spec.TLS.RenewBefore; renewBefore != nil {
+		if renewBefore.Duration > 0 && renewBefore.Duration < common.ArgoCDDuration365Days {
+			return renewBefore.Duration
+		}
+	}
+	return common.ArgoCDDefaultCertificateRenewBefore
+}
+
+// isCertificateDueForRenewal returns true if the given certificate expires within the renewal window of the given
+// ArgoCD.
+func isCertificateDueForRenewal(cr *argoproj.ArgoCD, cert *x509.Certificate) bool {
+	return !time.Now().Add(getCertificateRenewBefore(cr)).Before(cert.NotAfter)
+}
+
+// reconcileCertificateExpiry records the expiry of the CA and TLS certificates of the given ArgoCD in its status and
+// metrics. A warning event is recorded once for each certificate that is due for renewal but was not renewed by the
+// operator, such as the certificates provided by the user.
+func (r *ReconcileArgoCD) reconcileCertificateExpiry(cr *argoproj.ArgoCD) error {
+	previous := cr.Status.TLS
+	if previous == nil {
+		previous = &argoproj.ArgoCDTLSStatus{}
+	}
+
+	status := &argoproj.ArgoCDTLSStatus{
+		CANotAfter: r.getCertificateNotAfter(cr, argoutil.NewSecretWithSuffix(cr, "ca"), "ca", previous.CANotAfter),
+		NotAfter:   r.getCertificateNotAfter(cr, argoutil.NewTLSSecret(cr, "tls"), "tls", previous.NotAfter),
+	}
+	if status.CANotAfter.Equal(previous.CANotAfter) && status.NotAfter.Equal(previous.NotAfter) {
+		return nil
+	}
+
+	cr.Status.TLS = status
+	return r.Client.Status().Update(context.TODO(), cr)
+}
+
+// getCertificateNotAfter returns the expiry of the certificate of the given Secret, nil if the Secret or its
+// certificate is not found, and records it as the given certificate of the given ArgoCD in the metrics. A warning
+// event is recorded when the certificate is due for renewal and its expiry differs from the given recorded one.
+func (r *ReconcileArgoCD) getCertificateNotAfter(cr *argoproj.ArgoCD, secret *corev1.Secret, certificate string, recorded *metav1.Time) *metav1.Time {
+	if !argoutil.IsObjectFound(r.Client, cr.Namespace, secret.Name, secret) {
+		CertificateExpiryTime.DeleteLabelValues(cr.Namespace, cr.Name, certificate)
+		return nil
+	}
+
+	cert, err := argoutil.ParsePEMEncodedCert(secret.Data[corev1.TLSCertKey])
+	if err != nil {
+		log.Error(err, "failed to parse certificate", "name", secret.Name, "namespace", secret.Namespace)
+		CertificateExpiryTime.DeleteLabelValues(cr.Namespace, cr.Name, certificate)
+		return nil
+	}
+	CertificateExpiryTime.WithLabelValues(cr.Namespace, cr.Name, certificate).Set(float64(cert.NotAfter.Unix()))
+
+	notAfter := metav1.NewTime(cert.NotAfter)
+	if isCertificateDueForRenewal(cr, cert) && !notAfter.Equal(recorded) {
+		message := fmt.Sprintf("The certificate of secret %s expires at %s and was not renewed.", secret.Name, cert.NotAfter.UTC().Format(time.RFC3339))
+		r.createCertificateEvent(cr, corev1.EventTypeWarning, "CertificateExpiring", message)
+	}
+	return &notAfter
+}
+
+// createCertificateEvent records an event of the given type and reason on the given ArgoCD, about its certificates.
+// Failing to record the event is only logged.
+func (r *ReconcileArgoCD) createCertificateEvent(cr *argoproj.ArgoCD, eventType, reason, message string) {
+	typeMeta := metav1.TypeMeta{Kind: "ArgoCD", APIVersion: argoproj.GroupVersion.String()}
+	if err := argoutil.CreateEvent(r.Client, eventType, "Reconciling", message, reason, cr.ObjectMeta, typeMeta); err != nil {
+		log.Error(err, "failed to create event", "reason", reason)
+	}
+}
+
 // reconcileClusterSecrets will reconcile all Secret resources for the ArgoCD cluster.
 func (r *ReconcileArgoCD) reconcileClusterSecrets(cr *argoproj.ArgoCD) error {
 	if err := r.reconcileClusterMainSecret(cr); err != nil {
@@ -348,13 +476,29 @@ func (r *ReconcileArgoCD) reconcileExistingArgoSecret(cr *argoproj.ArgoCD, secre
 		}
 	}
 
-	if changed {
+	// the secrets of the typed dex connectors are removed once dex is disabled
+	dexConnectorData := map[string][]byte{}
+	if UseDex(cr) {
+		data, err := r.getDexConnectorSecretData(cr)
+		if err != nil {
+			return err
+		}
+		dexConnectorData = data
+	}
+	dexConnectorsChanged := updateDexConnectorSecretData(secret, dexConnectorData)
+
+	if changed || dexConnectorsChanged {
 		log.Info("updating argo secret")
 		if err := r.Client.Update(context.TODO(), secret); err != nil {
 			return err
 		}
 	}
 
+	// Trigger rollout of Dex Deployment to pick up the rotated secrets of the connectors.
+	if dexConnectorsChanged && UseDex(cr) {
+		return r.triggerDexRollout(cr)
+	}
+
 	return nil
 }
 
@@ -508,6 +652,10 @@ func (r *ReconcileArgoCD) reconcileRepoServerTLSSecret(cr *argoproj.ArgoCD) erro
 
 	log.Info("reconciling repo-server TLS secret")
 
+	if r.waitsForCertificate(cr, cr.Spec.Repo.WantsCertManagerTLS(), common.ArgoCDRepoServerTLSSecretName) {
+		return nil
+	}
+
 	tlsSecretName := types.NamespacedName{Namespace: cr.Namespace, Name: common.ArgoCDRepoServerTLSSecretName}
 	err := r.Client.Get(context.TODO(), tlsSecretName, &tlsSecretObj)
 	if err != nil {
@@ -570,12 +718,16 @@ func (r *ReconcileArgoCD) reconcileRepoServerTLSSecret(cr *argoproj.ArgoCD) erro
 // has changed since our last reconciliation loop. It does so by comparing the
 // checksum of tls.crt and tls.key in the status of the ArgoCD CR against the
 // values calculated from the live state in the cluster.
-func (r *ReconcileArgoCD) reconcileRedisTLSSecret(cr *argoproj.ArgoCD, useTLSForRedis bool) error {
+func (r *ReconcileArgoCD) reconcileRedisTLSSecret(cr *argoproj.ArgoCD) error {
 	var tlsSecretObj corev1.Secret
 	var sha256sum string
 
 	log.Info("reconciling redis-server TLS secret")
 
+	if r.waitsForCertificate(cr, cr.Spec.Redis.WantsCertManagerTLS(), common.ArgoCDRedisServerTLSSecretName) {
+		return nil
+	}
+
 	tlsSecretName := types.NamespacedName{Namespace: cr.Namespace, Name: common.ArgoCDRedisServerTLSSecretName}
 	err := r.Client.Get(context.TODO(), tlsSecretName, &tlsSecretObj)
 	if err != nil {
@@ -609,33 +761,10 @@ func (r *ReconcileArgoCD) reconcileRedisTLSSecret(cr *argoproj.ArgoCD, useTLSFor
 			return err
 		}
 
-		// Trigger rollout of redis
-		if cr.Spec.HA.Enabled {
-			err = r.recreateRedisHAConfigMap(cr, useTLSForRedis)
-			if err != nil {
-				return err
-			}
-			err = r.recreateRedisHAHealthConfigMap(cr, useTLSForRedis)
-			if err != nil {
-				return err
-			}
-			haProxyDepl := newDeploymentWithSuffix("redis-ha-haproxy", "redis", cr)
-			err = r.triggerRollout(haProxyDepl, "redis.tls.cert.changed")
-			if err != nil {
-				return err
-			}
-			// If we use triggerRollout on the redis stateful set, kubernetes will attempt to restart the  pods
-			// one at a time, and the first one to restart (which will be using tls) will hang as it tries to
-			// communicate with the existing pods (which are not using tls) to establish which is the master.
-			// So instead we delete the stateful set, which will delete all the pods.
-			redisSts := newStatefulSetWithSuffix("redis-ha-server", "redis", cr)
-			if argoutil.IsObjectFound(r.Client, redisSts.Namespace, redisSts.Name, redisSts) {
-				err = r.Client.Delete(context.TODO(), redisSts)
-				if err != nil {
-					return err
-				}
-			}
-		} else {
+		// Trigger rollout of redis. The Redis HA servers and proxy are rolled out once the checksum of their
+		// configuration, which includes the one of the TLS certificate, changes. The Redis HA servers are recreated
+		// instead when TLS is turned on or off, see reconcileRedisStatefulSet.
+		if !cr.Spec.HA.Enabled {
 			redisDepl := newDeploymentWithSuffix("redis", "redis", cr)
 			err = r.triggerRollout(redisDepl, "redis.tls.cert.changed")
 			if err != nil {
@@ -678,6 +807,10 @@ func (r *ReconcileArgoCD) reconcileSecrets(cr *argoproj.ArgoCD) error {
 		return err
 	}
 
+	if err := r.reconcileRedisAuthSecret(cr); err != nil {
+		return err
+	}
+
 	return nil
 }
 
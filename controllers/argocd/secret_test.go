@@ -2,14 +2,18 @@ package argocd
 
 import (
 	"context"
+	"crypto/rand"
 	"crypto/sha256"
+	"crypto/x509"
 	"fmt"
 	"reflect"
 	"sort"
 	"testing"
+	"time"
 
 	configv1 "github.com/openshift/api/config/v1"
 	routev1 "github.com/openshift/api/route/v1"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
 
 	corev1 "k8s.io/api/core/v1"
@@ -333,7 +337,7 @@ func Test_ReconcileArgoCD_ReconcileRedisTLSSecret(t *testing.T) {
 		cl := makeTestReconcilerClient(sch, resObjs, subresObjs, runtimeObjs)
 		r := makeTestReconciler(cl, sch)
 
-		err := r.reconcileRedisTLSSecret(argocd, true)
+		err := r.reconcileRedisTLSSecret(argocd)
 		if err != nil {
 			t.Errorf("Error should be nil, but is %v", err)
 		}
@@ -366,7 +370,7 @@ func Test_ReconcileArgoCD_ReconcileRedisTLSSecret(t *testing.T) {
 		}
 
 		// Second run - no change
-		err = r.reconcileRedisTLSSecret(argocd, true)
+		err = r.reconcileRedisTLSSecret(argocd)
 		if err != nil {
 			t.Errorf("Error should be nil, but is %v", err)
 		}
@@ -407,7 +411,7 @@ func Test_ReconcileArgoCD_ReconcileRedisTLSSecret(t *testing.T) {
 		shasum = fmt.Sprintf("%x", sha256.Sum256(sumOver))
 
 		// Second run - no change
-		err = r.reconcileRedisTLSSecret(argocd, true)
+		err = r.reconcileRedisTLSSecret(argocd)
 		if err != nil {
 			t.Errorf("Error should be nil, but is %v", err)
 		}
@@ -486,3 +490,137 @@ func Test_ReconcileArgoCD_ClusterPermissionsSecret(t *testing.T) {
 	assert.NoError(t, r.Client.Get(context.TODO(), types.NamespacedName{Name: testSecret.Name, Namespace: testSecret.Namespace}, testSecret))
 	assert.Nil(t, r.Client.Get(context.TODO(), types.NamespacedName{Name: testSecret.Name, Namespace: testSecret.Namespace}, testSecret))
 }
+
+func getTestCertificate(t *testing.T, r *ReconcileArgoCD, name string) *x509.Certificate {
+	t.Helper()
+	secret := &corev1.Secret{}
+	assert.NoError(t, r.Client.Get(context.TODO(), types.NamespacedName{Name: name, Namespace: testNamespace}, secret))
+	cert, err := argoutil.ParsePEMEncodedCert(secret.Data[corev1.TLSCertKey])
+	assert.NoError(t, err)
+	return cert
+}
+
+// expireTestCertificate signs the self-signed certificate of the given Secret again, so that it expires in an hour.
+func expireTestCertificate(t *testing.T, r *ReconcileArgoCD, name string) {
+	t.Helper()
+	secret := &corev1.Secret{}
+	assert.NoError(t, r.Client.Get(context.TODO(), types.NamespacedName{Name: name, Namespace: testNamespace}, secret))
+	cert, err := argoutil.ParsePEMEncodedCert(secret.Data[corev1.TLSCertKey])
+	assert.NoError(t, err)
+	key, err := argoutil.ParsePEMEncodedPrivateKey(secret.Data[corev1.TLSPrivateKeyKey])
+	assert.NoError(t, err)
+
+	cert.NotAfter = time.Now().Add(time.Hour)
+	der, err := x509.CreateCertificate(rand.Reader, cert, cert, key.Public(), key)
+	assert.NoError(t, err)
+	expiring, err := x509.ParseCertificate(der)
+	assert.NoError(t, err)
+	secret.Data[corev1.TLSCertKey] = argoutil.EncodeCertificatePEM(expiring)
+	assert.NoError(t, r.Client.Update(context.TODO(), secret))
+}
+
+func TestGetCertificateRenewBefore(t *testing.T) {
+	tests := []struct {
+		name        string
+		renewBefore *metav1.Duration
+		want        time.Duration
+	}{
+		{"default", nil, common.ArgoCDDefaultCertificateRenewBefore},
+		{"within the certificate lifetime", &metav1.Duration{Duration: 240 * time.Hour}, 240 * time.Hour},
+		{"zero", &metav1.Duration{}, common.ArgoCDDefaultCertificateRenewBefore},
+		{"negative", &metav1.Duration{Duration: -time.Hour}, common.ArgoCDDefaultCertificateRenewBefore},
+		{"certificate lifetime", &metav1.Duration{Duration: common.ArgoCDDuration365Days}, common.ArgoCDDefaultCertificateRenewBefore},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			a := makeTestArgoCD(func(a *argoproj.ArgoCD) {
+				a.Spec.TLS.RenewBefore = test.renewBefore
+			})
+			assert.Equal(t, test.want, getCertificateRenewBefore(a))
+		})
+	}
+}
+
+func listTestEventReasons(t *testing.T, r *ReconcileArgoCD) []string {
+	t.Helper()
+	events := &corev1.EventList{}
+	assert.NoError(t, r.Client.List(context.TODO(), events, client.InNamespace(testNamespace)))
+	reasons := []string{}
+	for _, event := range events.Items {
+		reasons = append(reasons, event.Reason)
+	}
+	return reasons
+}
+
+func Test_ReconcileArgoCD_ReconcileClusterSecrets_renewal(t *testing.T) {
+	logf.SetLogger(ZapLogger(true))
+	a := makeTestArgoCD()
+
+	resObjs := []client.Object{a}
+	sch := makeTestReconcilerScheme(argoproj.AddToScheme)
+	cl := makeTestReconcilerClient(sch, resObjs, resObjs, []runtime.Object{})
+	r := makeTestReconciler(cl, sch)
+
+	assert.NoError(t, r.reconcileClusterCASecret(a))
+	assert.NoError(t, r.reconcileClusterTLSSecret(a))
+	assert.NoError(t, r.reconcileCAConfigMap(a))
+	caCert := getTestCertificate(t, r, "argocd-ca")
+	tlsCert := getTestCertificate(t, r, "argocd-tls")
+
+	// certificates outside of the renewal window are kept
+	assert.NoError(t, r.reconcileClusterCASecret(a))
+	assert.NoError(t, r.reconcileClusterTLSSecret(a))
+	assert.Equal(t, caCert.SerialNumber, getTestCertificate(t, r, "argocd-ca").SerialNumber)
+	assert.Equal(t, tlsCert.SerialNumber, getTestCertificate(t, r, "argocd-tls").SerialNumber)
+
+	// the CA is renewed within the renewal window, and the TLS certificate is signed again by the new CA
+	expireTestCertificate(t, r, "argocd-ca")
+	assert.NoError(t, r.reconcileClusterCASecret(a))
+	assert.NoError(t, r.reconcileClusterTLSSecret(a))
+	assert.NoError(t, r.reconcileCAConfigMap(a))
+
+	renewedCA := getTestCertificate(t, r, "argocd-ca")
+	assert.NotEqual(t, caCert.SerialNumber, renewedCA.SerialNumber)
+	renewedTLS := getTestCertificate(t, r, "argocd-tls")
+	assert.NotEqual(t, tlsCert.SerialNumber, renewedTLS.SerialNumber)
+	assert.NoError(t, renewedTLS.CheckSignatureFrom(renewedCA))
+	assert.Equal(t, []string{"CertificateRenewed", "CertificateRenewed"}, listTestEventReasons(t, r))
+
+	cm := &corev1.ConfigMap{}
+	assert.NoError(t, r.Client.Get(context.TODO(), types.NamespacedName{Name: "argocd-ca", Namespace: testNamespace}, cm))
+	assert.Equal(t, string(argoutil.EncodeCertificatePEM(renewedCA)), cm.Data[common.ArgoCDKeyTLSCert])
+}
+
+func Test_ReconcileArgoCD_ReconcileCertificateExpiry(t *testing.T) {
+	logf.SetLogger(ZapLogger(true))
+	a := makeTestArgoCD()
+	userSecret, err := newCASecret(a)
+	assert.NoError(t, err)
+
+	resObjs := []client.Object{a, userSecret}
+	sch := makeTestReconcilerScheme(argoproj.AddToScheme)
+	cl := makeTestReconcilerClient(sch, resObjs, resObjs, []runtime.Object{})
+	r := makeTestReconciler(cl, sch)
+	caCert := getTestCertificate(t, r, "argocd-ca")
+
+	assert.NoError(t, r.reconcileCertificateExpiry(a))
+	assert.True(t, a.Status.TLS.CANotAfter.Equal(&metav1.Time{Time: caCert.NotAfter}))
+	assert.Nil(t, a.Status.TLS.NotAfter)
+	assert.Equal(t, float64(caCert.NotAfter.Unix()), testutil.ToFloat64(CertificateExpiryTime.WithLabelValues(testNamespace, a.Name, "ca")))
+	assert.Empty(t, listTestEventReasons(t, r))
+
+	// a CA provided by the user is not renewed, a warning is recorded once instead
+	expireTestCertificate(t, r, "argocd-ca")
+	assert.NoError(t, r.reconcileClusterCASecret(a))
+	assert.Equal(t, caCert.SerialNumber, getTestCertificate(t, r, "argocd-ca").SerialNumber)
+
+	a.Status.TLS = nil
+	assert.NoError(t, r.reconcileCertificateExpiry(a))
+	assert.NoError(t, r.reconcileCertificateExpiry(a))
+	assert.Equal(t, []string{"CertificateExpiring"}, listTestEventReasons(t, r))
+
+	// the expiry of the CA is no longer reported once the instance is removed
+	count := testutil.CollectAndCount(CertificateExpiryTime)
+	r.removeInstance(types.NamespacedName{Name: a.Name, Namespace: a.Namespace})
+	assert.Equal(t, count-1, testutil.CollectAndCount(CertificateExpiryTime))
+}
@@ -33,10 +33,26 @@ const (
 )
 
 var (
-	templateAPIFound     = false
-	ssoConfigLegalStatus string
+	templateAPIFound = false
 )
 
+// ssoState holds the outcome of an SSO reconciliation round of an ArgoCD, which is reported in its status. It is kept
+// per reconciliation so that concurrent reconciliations of different ArgoCD instances do not affect each other.
+type ssoState struct {
+	// legalStatus is ssoLegalSuccess or ssoLegalFailed depending on whether the SSO configuration is legal, and
+	// ssoLegalUnknown until it has been validated.
+	legalStatus string
+	// configApplied is false when a legal SSO configuration could not be applied, e.g. because the keycloak realm
+	// failed to reconcile.
+	configApplied bool
+}
+
+// newSSOState returns the state of an SSO reconciliation round that has not validated nor applied any SSO
+// configuration yet.
+func newSSOState() *ssoState {
+	return &ssoState{legalStatus: ssoLegalUnknown, configApplied: true}
+}
+
 // IsTemplateAPIAvailable returns true if the template API is present.
 func IsTemplateAPIAvailable() bool {
 	return templateAPIFound
@@ -57,15 +73,15 @@ func verifyTemplateAPI() error {
 // The operator must support `.spec.sso.dex` fields for dex, and `.spec.sso.keycloak` fields for keycloak.
 // The operator must identify edge cases involving partial configurations of specs, spec mismatch with
 // active provider, contradicting configuration etc, and throw the appropriate errors.
-func (r *ReconcileArgoCD) reconcileSSO(cr *argoproj.ArgoCD) error {
+// The returned state is passed to the status reconciliation of the given ArgoCD.
+func (r *ReconcileArgoCD) reconcileSSO(cr *argoproj.ArgoCD) (*ssoState, error) {
 
-	// reset ssoConfigLegalStatus at the beginning of each SSO reconciliation round
-	ssoConfigLegalStatus = ssoLegalUnknown
+	state := newSSOState()
 
 	// case 1
 	if cr.Spec.SSO == nil {
 		// no SSO configured, nothing to do here
-		return nil
+		return state, nil
 	}
 
 	if cr.Spec.SSO != nil {
@@ -78,7 +94,7 @@ func (r *ReconcileArgoCD) reconcileSSO(cr *argoproj.ArgoCD) error {
 		if cr.Spec.SSO.Provider.ToLower() == argoproj.SSOProviderTypeDex {
 			// Relevant SSO settings at play are `.spec.sso.dex` fields, `.spec.sso.keycloak`
 
-			if cr.Spec.SSO.Dex == nil || (cr.Spec.SSO.Dex != nil && !cr.Spec.SSO.Dex.OpenShiftOAuth && cr.Spec.SSO.Dex.Config == "") {
+			if cr.Spec.SSO.Dex == nil || (cr.Spec.SSO.Dex != nil && !cr.Spec.SSO.Dex.OpenShiftOAuth && cr.Spec.SSO.Dex.Config == "" && len(cr.Spec.SSO.Dex.Connectors) == 0) {
 				// sso provider specified as dex but no dexconfig supplied. This will cause health probe to fail as per
 				// https://github.com/argoproj-labs/argocd-operator/pull/615 ==> conflict
 				errMsg = "must supply valid dex configuration when requested SSO provider is dex"
@@ -87,14 +103,17 @@ func (r *ReconcileArgoCD) reconcileSSO(cr *argoproj.ArgoCD) error {
 				// new keycloak spec fields are expressed when `.spec.sso.provider` is set to dex ==> conflict
 				errMsg = "cannot supply keycloak configuration in .spec.sso.keycloak when requested SSO provider is dex"
 				isError = true
+			} else if connectorsErr := validateDexConnectors(cr.Spec.SSO.Dex.Connectors); connectorsErr != nil {
+				errMsg = connectorsErr.Error()
+				isError = true
 			}
 
 			if isError {
 				err = errors.New(illegalSSOConfiguration + errMsg)
 				log.Error(err, fmt.Sprintf("Illegal expression of SSO configuration detected for Argo CD %s in namespace %s. %s", cr.Name, cr.Namespace, errMsg))
-				ssoConfigLegalStatus = ssoLegalFailed // set global indicator that SSO config has gone wrong
-				_ = r.reconcileStatusSSO(cr)
-				return err
+				state.legalStatus = ssoLegalFailed // set indicator that SSO config has gone wrong
+				_ = r.reconcileStatusSSO(cr, state)
+				return state, err
 			}
 		}
 
@@ -111,9 +130,9 @@ func (r *ReconcileArgoCD) reconcileSSO(cr *argoproj.ArgoCD) error {
 
 			if isError {
 				log.Error(err, fmt.Sprintf("Illegal expression of SSO configuration detected for Argo CD %s in namespace %s. %s", cr.Name, cr.Namespace, errMsg))
-				ssoConfigLegalStatus = ssoLegalFailed // set global indicator that SSO config has gone wrong
-				_ = r.reconcileStatusSSO(cr)
-				return err
+				state.legalStatus = ssoLegalFailed // set indicator that SSO config has gone wrong
+				_ = r.reconcileStatusSSO(cr, state)
+				return state, err
 			}
 		}
 
@@ -128,9 +147,9 @@ func (r *ReconcileArgoCD) reconcileSSO(cr *argoproj.ArgoCD) error {
 				errMsg = "Cannot specify SSO provider spec without specifying SSO provider type"
 				err = errors.New(illegalSSOConfiguration + errMsg)
 				log.Error(err, fmt.Sprintf("Cannot specify SSO provider spec without specifying SSO provider type for Argo CD %s in namespace %s.", cr.Name, cr.Namespace))
-				ssoConfigLegalStatus = ssoLegalFailed // set global indicator that SSO config has gone wrong
-				_ = r.reconcileStatusSSO(cr)
-				return err
+				state.legalStatus = ssoLegalFailed // set indicator that SSO config has gone wrong
+				_ = r.reconcileStatusSSO(cr, state)
+				return state, err
 			}
 		}
 
@@ -141,45 +160,52 @@ func (r *ReconcileArgoCD) reconcileSSO(cr *argoproj.ArgoCD) error {
 			errMsg = fmt.Sprintf("Unsupported SSO provider type. Supported providers are %s and %s", argoproj.SSOProviderTypeDex, argoproj.SSOProviderTypeKeycloak)
 			err = errors.New(illegalSSOConfiguration + errMsg)
 			log.Error(err, fmt.Sprintf("Unsupported SSO provider type for Argo CD %s in namespace %s.", cr.Name, cr.Namespace))
-			ssoConfigLegalStatus = ssoLegalFailed // set global indicator that SSO config has gone wrong
-			_ = r.reconcileStatusSSO(cr)
-			return err
+			state.legalStatus = ssoLegalFailed // set indicator that SSO config has gone wrong
+			_ = r.reconcileStatusSSO(cr, state)
+			return state, err
 		}
 	}
 
 	// control reaching this point means that none of the illegal config combinations were detected. SSO is configured legally
-	// set global indicator that SSO config has been successful
-	ssoConfigLegalStatus = ssoLegalSuccess
+	// set indicator that SSO config has been successful
+	state.legalStatus = ssoLegalSuccess
 
 	// reconcile resources based on enabled provider
 	// keycloak
 	if cr.Spec.SSO != nil && cr.Spec.SSO.Provider.ToLower() == argoproj.SSOProviderTypeKeycloak {
 
 		// Trigger reconciliation of any Dex resources so they get deleted
-		if err := r.reconcileDexResources(cr); err != nil && !apiErrors.IsNotFound(err) {
+		if err := r.reconcileDexResources(cr, state); err != nil && !apiErrors.IsNotFound(err) {
 			log.Error(err, "Unable to delete existing dex resources before configuring keycloak")
-			return err
+			return state, err
 		}
 
 		if err := r.reconcileKeycloakConfiguration(cr); err != nil {
-			return err
+			// surface the failure, e.g. a keycloak realm that cannot be reconciled, in the SSO status
+			state.configApplied = false
+			_ = r.reconcileStatusSSO(cr, state)
+			return state, err
 		}
 	} else if UseDex(cr) {
 		// dex
 		// Delete any lingering keycloak artifacts before Dex is configured as this is not handled by the reconcilliation loop
 		if err := deleteKeycloakConfiguration(cr); err != nil && !apiErrors.IsNotFound(err) {
 			log.Error(err, "Unable to delete existing SSO configuration before configuring Dex")
-			return err
+			return state, err
+		}
+		if err := r.reconcileKeycloakPostgreSQL(cr); err != nil {
+			log.Error(err, "Unable to delete the keycloak database before configuring Dex")
+			return state, err
 		}
 
-		if err := r.reconcileDexResources(cr); err != nil {
-			return err
+		if err := r.reconcileDexResources(cr, state); err != nil {
+			return state, err
 		}
 	}
 
-	_ = r.reconcileStatusSSO(cr)
+	_ = r.reconcileStatusSSO(cr, state)
 
-	return nil
+	return state, nil
 }
 
 func (r *ReconcileArgoCD) deleteSSOConfiguration(newCr *argoproj.ArgoCD, oldCr *argoproj.ArgoCD) error {
@@ -191,14 +217,18 @@ func (r *ReconcileArgoCD) deleteSSOConfiguration(newCr *argoproj.ArgoCD, oldCr *
 			log.Error(err, "Unable to delete existing keycloak configuration")
 			return err
 		}
+		if err := r.reconcileKeycloakPostgreSQL(newCr); err != nil {
+			log.Error(err, "Unable to delete the keycloak database")
+			return err
+		}
 	} else if oldCr.Spec.SSO.Provider.ToLower() == argoproj.SSOProviderTypeDex {
 		// Trigger reconciliation of Dex resources so they get deleted
-		if err := r.deleteDexResources(newCr); err != nil {
+		if err := r.deleteDexResources(newCr, newSSOState()); err != nil {
 			log.Error(err, "Unable to reconcile necessary resources for uninstallation of Dex")
 			return err
 		}
 	}
 
-	_ = r.reconcileStatusSSO(newCr)
+	_ = r.reconcileStatusSSO(newCr, newSSOState())
 	return nil
 }
@@ -17,6 +17,7 @@ package argocd
 import (
 	"context"
 	"errors"
+	"sync"
 	"testing"
 
 	oappsv1 "github.com/openshift/api/apps/v1"
@@ -26,6 +27,7 @@ import (
 	k8sappsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
@@ -50,7 +52,8 @@ func TestReconcile_testKeycloakTemplateInstance(t *testing.T) {
 
 	assert.NoError(t, createNamespace(r, a.Namespace, ""))
 
-	assert.NoError(t, r.reconcileSSO(a))
+	_, err := r.reconcileSSO(a)
+	assert.NoError(t, err)
 
 	templateInstance := &templatev1.TemplateInstance{}
 	assert.NoError(t, r.Client.Get(
@@ -75,7 +78,8 @@ func TestReconcile_noTemplateInstance(t *testing.T) {
 
 	assert.NoError(t, createNamespace(r, a.Namespace, ""))
 
-	assert.NoError(t, r.reconcileSSO(a))
+	_, err := r.reconcileSSO(a)
+	assert.NoError(t, err)
 }
 
 func TestReconcile_illegalSSOConfiguration(t *testing.T) {
@@ -221,14 +225,14 @@ func TestReconcile_illegalSSOConfiguration(t *testing.T) {
 
 			assert.NoError(t, createNamespace(r, test.argoCD.Namespace, ""))
 
-			err := r.reconcileSSO(test.argoCD)
-			assert.Equal(t, test.wantSSOConfigLegalStatus, ssoConfigLegalStatus)
+			sso, err := r.reconcileSSO(test.argoCD)
+			assert.Equal(t, test.wantSSOConfigLegalStatus, sso.legalStatus)
 			if err != nil {
 				if !test.wantErr {
 					// ignore unexpected errors for legal sso configurations.
 					// keycloak reconciliation code expects a live cluster &
 					// therefore throws unexpected errors during unit testing
-					if ssoConfigLegalStatus != ssoLegalSuccess {
+					if sso.legalStatus != ssoLegalSuccess {
 						t.Errorf("Got unexpected error")
 					}
 				} else {
@@ -260,7 +264,39 @@ func TestReconcile_testKeycloakK8sInstance(t *testing.T) {
 
 	assert.NoError(t, createNamespace(r, a.Namespace, ""))
 
-	assert.NoError(t, r.reconcileSSO(a))
+	_, err := r.reconcileSSO(a)
+	assert.NoError(t, err)
+}
+
+func TestReconcile_keycloakConfigurationFailed(t *testing.T) {
+	logf.SetLogger(ZapLogger(true))
+	a := makeTestArgoCDForKeycloak()
+
+	// Cluster does not have a template instance
+	templateAPIFound = false
+
+	// keycloak is running, but the ingress of the Argo CD server does not exist
+	keycloak := newKeycloakDeployment(a)
+	keycloak.Status.AvailableReplicas = expectedReplicas
+	keycloak.Status.ReadyReplicas = expectedReplicas
+
+	resObjs := []client.Object{a, keycloak, newKeycloakIngress(a)}
+	subresObjs := []client.Object{a, keycloak}
+	runtimeObjs := []runtime.Object{}
+	sch := makeTestReconcilerScheme(argoproj.AddToScheme, templatev1.Install, oappsv1.Install, routev1.Install)
+	cl := makeTestReconcilerClient(sch, resObjs, subresObjs, runtimeObjs)
+	r := makeTestReconciler(cl, sch)
+
+	assert.NoError(t, createNamespace(r, a.Namespace, ""))
+
+	sso, err := r.reconcileSSO(a)
+	assert.EqualError(t, err, `ingresses.networking.k8s.io "argocd-server" not found`)
+	assert.False(t, sso.configApplied)
+	assert.Equal(t, "Failed", a.Status.SSO)
+
+	// the failure is kept by the status reconciliation that follows
+	assert.NoError(t, r.reconcileStatusSSO(a, sso))
+	assert.Equal(t, "Failed", a.Status.SSO)
 }
 
 func TestReconcile_testKeycloakInstanceResources(t *testing.T) {
@@ -279,11 +315,12 @@ func TestReconcile_testKeycloakInstanceResources(t *testing.T) {
 
 	assert.NoError(t, createNamespace(r, a.Namespace, ""))
 
-	assert.NoError(t, r.reconcileSSO(a))
+	_, err := r.reconcileSSO(a)
+	assert.NoError(t, err)
 
 	// Keycloak Deployment
 	deployment := &k8sappsv1.Deployment{}
-	err := r.Client.Get(context.TODO(), types.NamespacedName{Name: defaultKeycloakIdentifier, Namespace: a.Namespace}, deployment)
+	err = r.Client.Get(context.TODO(), types.NamespacedName{Name: defaultKeycloakIdentifier, Namespace: a.Namespace}, deployment)
 	assert.NoError(t, err)
 
 	assert.Equal(t, deployment.Name, defaultKeycloakIdentifier)
@@ -369,3 +406,50 @@ func TestReconcile_testKeycloakInstanceResources(t *testing.T) {
 
 	assert.Equal(t, ing.Spec.Rules, testRules)
 }
+
+func TestReconcile_concurrentSSOConfigurations(t *testing.T) {
+	logf.SetLogger(ZapLogger(true))
+	legal := makeTestArgoCD(func(a *argoproj.ArgoCD) {
+		a.Namespace = "legal"
+		a.Spec.SSO = &argoproj.ArgoCDSSOSpec{
+			Provider: argoproj.SSOProviderTypeDex,
+			Dex:      &argoproj.ArgoCDDexSpec{Config: "test-config"},
+		}
+	})
+	illegal := makeTestArgoCD(func(a *argoproj.ArgoCD) {
+		a.Namespace = "illegal"
+		a.Spec.SSO = &argoproj.ArgoCDSSOSpec{Provider: argoproj.SSOProviderTypeDex}
+	})
+
+	resObjs := []client.Object{legal, illegal}
+	subresObjs := []client.Object{legal, illegal}
+	runtimeObjs := []runtime.Object{}
+	sch := makeTestReconcilerScheme(argoproj.AddToScheme, templatev1.Install, oappsv1.Install, routev1.Install)
+	cl := makeTestReconcilerClient(sch, resObjs, subresObjs, runtimeObjs)
+	r := makeTestReconciler(cl, sch)
+
+	assert.NoError(t, createNamespace(r, legal.Namespace, ""))
+	assert.NoError(t, createNamespace(r, illegal.Namespace, ""))
+
+	// the SSO reconciliation of each instance, and the status derived from it, is not affected by the other one
+	var wg sync.WaitGroup
+	for _, cr := range []*argoproj.ArgoCD{legal, illegal} {
+		wg.Add(1)
+		go func(cr *argoproj.ArgoCD) {
+			defer wg.Done()
+			want, wantStatus := ssoLegalSuccess, "Unknown"
+			if cr == illegal {
+				want, wantStatus = ssoLegalFailed, "Failed"
+			}
+			for i := 0; i < 10; i++ {
+				sso, _ := r.reconcileSSO(cr)
+				assert.Equal(t, want, sso.legalStatus)
+				assert.NoError(t, r.reconcileStatusSSO(cr, sso))
+				assert.Equal(t, wantStatus, cr.Status.SSO)
+				assert.NoError(t, r.reconcileStatusConditions(cr, sso, nil, nil))
+				assert.Equal(t, cr == illegal, meta.IsStatusConditionFalse(cr.Status.Conditions, argoproj.ArgoCDConditionSSOConfigured))
+			}
+		}(cr)
+	}
+	wg.Wait()
+}
@@ -91,8 +91,13 @@ func newStatefulSetWithSuffix(suffix string, component string, cr *argoproj.Argo
 	return newStatefulSetWithName(fmt.Sprintf("%s-%s", cr.Name, suffix), component, cr)
 }
 
-func (r *ReconcileArgoCD) reconcileRedisStatefulSet(cr *argoproj.ArgoCD) error {
+// reconcileRedisStatefulSet will ensure that the Redis HA StatefulSet is present for the given ArgoCD. The Redis HA
+// servers are rolled out once the checksum of their configuration changes, and recreated when TLS is turned on or off.
+func (r *ReconcileArgoCD) reconcileRedisStatefulSet(cr *argoproj.ArgoCD, useTLSForRedis bool) error {
 	ss := newStatefulSetWithSuffix("redis-ha-server", "redis", cr)
+	ss.Annotations = map[string]string{
+		common.ArgoCDRedisHATLSAnnotation: strconv.FormatBool(useTLSForRedis),
+	}
 
 	ss.Spec.PodManagementPolicy = appsv1.OrderedReadyPodManagement
 	ss.Spec.Replicas = getRedisHAReplicas(cr)
@@ -106,7 +111,7 @@ func (r *ReconcileArgoCD) reconcileRedisStatefulSet(cr *argoproj.ArgoCD) error {
 
 	ss.Spec.Template.ObjectMeta = metav1.ObjectMeta{
 		Annotations: map[string]string{
-			"checksum/init-config": "7128bfbb51eafaffe3c33b1b463e15f0cf6514cec570f9d9c4f2396f28c724ac", // TODO: Should this be hard-coded?
+			common.ArgoCDRedisHAConfigChecksumAnnotation: getRedisHAServerChecksum(cr, useTLSForRedis),
 		},
 		Labels: map[string]string{
 			common.ArgoCDKeyName: nameWithSuffix("redis-ha", cr),
@@ -137,8 +142,9 @@ func (r *ReconcileArgoCD) reconcileRedisStatefulSet(cr *argoproj.ArgoCD) error {
 			Command: []string{
 				"redis-server",
 			},
+			Env:             getRedisHAAuthEnv(cr),
 			Image:           getRedisHAContainerImage(cr),
-			ImagePullPolicy: corev1.PullIfNotPresent,
+			ImagePullPolicy: getImagePullPolicy(cr, cr.Spec.Redis.ImagePullPolicy, corev1.PullIfNotPresent),
 			LivenessProbe: &corev1.Probe{
 				ProbeHandler: corev1.ProbeHandler{
 					Exec: &corev1.ExecAction{
@@ -209,7 +215,7 @@ func (r *ReconcileArgoCD) reconcileRedisStatefulSet(cr *argoproj.ArgoCD) error {
 				"redis-sentinel",
 			},
 			Image:           getRedisHAContainerImage(cr),
-			ImagePullPolicy: corev1.PullIfNotPresent,
+			ImagePullPolicy: getImagePullPolicy(cr, cr.Spec.Redis.ImagePullPolicy, corev1.PullIfNotPresent),
 			LivenessProbe: &corev1.Probe{
 				ProbeHandler: corev1.ProbeHandler{
 					Exec: &corev1.ExecAction{
@@ -281,7 +287,7 @@ func (r *ReconcileArgoCD) reconcileRedisStatefulSet(cr *argoproj.ArgoCD) error {
 		Command: []string{
 			"sh",
 		},
-		Env: []corev1.EnvVar{
+		Env: append([]corev1.EnvVar{
 			{
 				Name:  "SENTINEL_ID_0",
 				Value: "3c0d9c0320bb34888c2df5757c718ce6ca992ce6", // TODO: Should this be hard-coded?
@@ -294,9 +300,9 @@ func (r *ReconcileArgoCD) reconcileRedisStatefulSet(cr *argoproj.ArgoCD) error {
 				Name:  "SENTINEL_ID_2",
 				Value: "2bbec7894d954a8af3bb54d13eaec53cb024e2ca", // TODO: Should this be hard-coded?
 			},
-		},
+		}, getRedisHAAuthEnv(cr)...),
 		Image:           getRedisHAContainerImage(cr),
-		ImagePullPolicy: corev1.PullIfNotPresent,
+		ImagePullPolicy: getImagePullPolicy(cr, cr.Spec.Redis.ImagePullPolicy, corev1.PullIfNotPresent),
 		Name:            "config-init",
 		Resources:       getRedisHAResources(cr),
 		SecurityContext: &corev1.SecurityContext{
@@ -385,6 +391,9 @@ func (r *ReconcileArgoCD) reconcileRedisStatefulSet(cr *argoproj.ArgoCD) error {
 		Type: appsv1.RollingUpdateStatefulSetStrategyType,
 	}
 
+	applyNodePlacement(&ss.Spec.Template.Spec, getNodePlacement(cr, cr.Spec.Redis.NodePlacement), ss.Spec.Selector)
+	ss.Spec.Template.Spec.ImagePullSecrets = getImagePullSecrets(cr, cr.Spec.Redis.ImagePullSecrets)
+
 	if err := applyReconcilerHook(cr, ss, ""); err != nil {
 		return err
 	}
@@ -396,9 +405,27 @@ func (r *ReconcileArgoCD) reconcileRedisStatefulSet(cr *argoproj.ArgoCD) error {
 			return r.Client.Delete(context.TODO(), existing)
 		}
 
+		// If we let the checksum roll out a change of TLS mode, kubernetes will attempt to restart the pods one at a
+		// time, and the first one to restart (which will be using tls) will hang as it tries to communicate with the
+		// existing pods (which are not using tls) to establish which is the master. So instead we delete the stateful
+		// set, which will delete all the pods, and it is recreated by the next reconciliation.
+		if tls, ok := existing.Annotations[common.ArgoCDRedisHATLSAnnotation]; ok && tls != ss.Annotations[common.ArgoCDRedisHATLSAnnotation] {
+			log.Info("redis tls mode changed, recreating the redis ha statefulset", "name", existing.Name, "namespace", existing.Namespace)
+			return r.Client.Delete(context.TODO(), existing)
+		}
+
 		desiredImage := getRedisHAContainerImage(cr)
 		changed := false
+		if existing.Annotations[common.ArgoCDRedisHATLSAnnotation] != ss.Annotations[common.ArgoCDRedisHATLSAnnotation] {
+			if existing.Annotations == nil {
+				existing.Annotations = map[string]string{}
+			}
+			existing.Annotations[common.ArgoCDRedisHATLSAnnotation] = ss.Annotations[common.ArgoCDRedisHATLSAnnotation]
+			changed = true
+		}
+		updateConfigChecksum(&existing.Spec.Template, &ss.Spec.Template, &changed)
 		updateNodePlacementStateful(existing, ss, &changed)
+		updateImagePullOptions(&existing.Spec.Template.Spec, &ss.Spec.Template.Spec, &changed)
 		for i, container := range existing.Spec.Template.Spec.Containers {
 			if container.Image != desiredImage {
 				existing.Spec.Template.Spec.Containers[i].Image = getRedisHAContainerImage(cr)
@@ -410,6 +437,11 @@ func (r *ReconcileArgoCD) reconcileRedisStatefulSet(cr *argoproj.ArgoCD) error {
 				existing.Spec.Template.Spec.Containers[i].Resources = ss.Spec.Template.Spec.Containers[i].Resources
 				changed = true
 			}
+
+			if !reflect.DeepEqual(ss.Spec.Template.Spec.Containers[i].Env, existing.Spec.Template.Spec.Containers[i].Env) {
+				existing.Spec.Template.Spec.Containers[i].Env = ss.Spec.Template.Spec.Containers[i].Env
+				changed = true
+			}
 		}
 
 		if !reflect.DeepEqual(ss.Spec.Template.Spec.InitContainers[0].Resources, existing.Spec.Template.Spec.InitContainers[0].Resources) {
@@ -417,6 +449,11 @@ func (r *ReconcileArgoCD) reconcileRedisStatefulSet(cr *argoproj.ArgoCD) error {
 			changed = true
 		}
 
+		if !reflect.DeepEqual(ss.Spec.Template.Spec.InitContainers[0].Env, existing.Spec.Template.Spec.InitContainers[0].Env) {
+			existing.Spec.Template.Spec.InitContainers[0].Env = ss.Spec.Template.Spec.InitContainers[0].Env
+			changed = true
+		}
+
 		if changed {
 			return r.Client.Update(context.TODO(), existing)
 		}
@@ -471,7 +508,8 @@ func (r *ReconcileArgoCD) getApplicationControllerReplicaCount(cr *argoproj.Argo
 
 	if cr.Spec.Controller.Sharding.DynamicScalingEnabled != nil && *cr.Spec.Controller.Sharding.DynamicScalingEnabled {
 
-		// TODO: add the same validations to Validation Webhook once webhook has been introduced
+		// The ArgoCD validating webhook rejects these values at admission time, the checks below
+		// guard instances that were admitted while the webhook was not enabled.
 		if minShards < 1 {
 			log.Info("Minimum number of shards cannot be less than 1. Setting default value to 1")
 			minShards = 1
@@ -517,6 +555,11 @@ func (r *ReconcileArgoCD) getApplicationControllerReplicaCount(cr *argoproj.Argo
 func (r *ReconcileArgoCD) reconcileApplicationControllerStatefulSet(cr *argoproj.ArgoCD, useTLSForRedis bool) error {
 
 	replicas := r.getApplicationControllerReplicaCount(cr)
+	if isImportInProgress(cr) {
+		// Keep the application controller stopped until the backup has been imported by the import Job.
+		log.Info("waiting for the import to complete before starting the application controller")
+		replicas = 0
+	}
 
 	ss := newStatefulSetWithSuffix("application-controller", "application-controller", cr)
 	ss.Spec.Replicas = &replicas
@@ -525,11 +568,12 @@ func (r *ReconcileArgoCD) reconcileApplicationControllerStatefulSet(cr *argoproj
 	controllerEnv = argoutil.EnvMerge(controllerEnv, getArgoControllerContainerEnv(cr), true)
 	// Let user specify their own environment first
 	controllerEnv = argoutil.EnvMerge(controllerEnv, proxyEnvVars(), false)
+	controllerEnv = argoutil.EnvMerge(controllerEnv, getRedisAuthEnv(cr), true)
 	podSpec := &ss.Spec.Template.Spec
 	podSpec.Containers = []corev1.Container{{
 		Command:         getArgoApplicationControllerCommand(cr, useTLSForRedis),
 		Image:           getArgoContainerImage(cr),
-		ImagePullPolicy: corev1.PullAlways,
+		ImagePullPolicy: getImagePullPolicy(cr, cr.Spec.Controller.ImagePullPolicy, corev1.PullAlways),
 		Name:            "argocd-application-controller",
 		Env:             controllerEnv,
 		Ports: []corev1.ContainerPort{
@@ -618,32 +662,8 @@ func (r *ReconcileArgoCD) reconcileApplicationControllerStatefulSet(cr *argoproj
 		},
 	}
 
-	// Handle import/restore from ArgoCDExport
-	export := r.getArgoCDExport(cr)
-	if export == nil {
-		log.Info("existing argocd export not found, skipping import")
-	} else {
-		podSpec.InitContainers = []corev1.Container{{
-			Command:         getArgoImportCommand(r.Client, cr),
-			Env:             proxyEnvVars(getArgoImportContainerEnv(export)...),
-			Resources:       getArgoApplicationControllerResources(cr),
-			Image:           getArgoImportContainerImage(export),
-			ImagePullPolicy: corev1.PullAlways,
-			Name:            "argocd-import",
-			SecurityContext: &corev1.SecurityContext{
-				AllowPrivilegeEscalation: boolPtr(false),
-				Capabilities: &corev1.Capabilities{
-					Drop: []corev1.Capability{
-						"ALL",
-					},
-				},
-				RunAsNonRoot: boolPtr(true),
-			},
-			VolumeMounts: getArgoImportVolumeMounts(),
-		}}
-
-		podSpec.Volumes = getArgoImportVolumes(export)
-	}
+	applyNodePlacement(&ss.Spec.Template.Spec, getNodePlacement(cr, cr.Spec.Controller.NodePlacement), ss.Spec.Selector)
+	ss.Spec.Template.Spec.ImagePullSecrets = getImagePullSecrets(cr, cr.Spec.Controller.ImagePullSecrets)
 
 	invalidImagePod := containsInvalidImage(cr, r)
 	if invalidImagePod {
@@ -672,6 +692,7 @@ func (r *ReconcileArgoCD) reconcileApplicationControllerStatefulSet(cr *argoproj
 			desiredCommand = append(desiredCommand, "--repo-server-strict-tls")
 		}
 		updateNodePlacementStateful(existing, ss, &changed)
+		updateImagePullOptions(&existing.Spec.Template.Spec, &ss.Spec.Template.Spec, &changed)
 		if !reflect.DeepEqual(desiredCommand, existing.Spec.Template.Spec.Containers[0].Command) {
 			existing.Spec.Template.Spec.Containers[0].Command = desiredCommand
 			changed = true
@@ -691,6 +712,11 @@ func (r *ReconcileArgoCD) reconcileApplicationControllerStatefulSet(cr *argoproj
 			existing.Spec.Template.Spec.Containers[0].VolumeMounts = ss.Spec.Template.Spec.Containers[0].VolumeMounts
 			changed = true
 		}
+		// The import is performed by a Job, remove the init container of earlier operator versions.
+		if len(existing.Spec.Template.Spec.InitContainers) > 0 {
+			existing.Spec.Template.Spec.InitContainers = nil
+			changed = true
+		}
 		if !reflect.DeepEqual(ss.Spec.Template.Spec.Containers[0].Resources, existing.Spec.Template.Spec.Containers[0].Resources) {
 			existing.Spec.Template.Spec.Containers[0].Resources = ss.Spec.Template.Spec.Containers[0].Resources
 			changed = true
@@ -730,7 +756,7 @@ func (r *ReconcileArgoCD) reconcileStatefulSets(cr *argoproj.ArgoCD, useTLSForRe
 	if err := r.reconcileApplicationControllerStatefulSet(cr, useTLSForRedis); err != nil {
 		return err
 	}
-	if err := r.reconcileRedisStatefulSet(cr); err != nil {
+	if err := r.reconcileRedisStatefulSet(cr, useTLSForRedis); err != nil {
 		return err
 	}
 	return nil
@@ -747,16 +773,9 @@ func (r *ReconcileArgoCD) triggerStatefulSetRollout(sts *appsv1.StatefulSet, key
 	return r.Client.Update(context.TODO(), sts)
 }
 
-// to update nodeSelector and tolerations in reconciler
+// to update nodeSelector, tolerations and the other scheduling options in reconciler
 func updateNodePlacementStateful(existing *appsv1.StatefulSet, ss *appsv1.StatefulSet, changed *bool) {
-	if !reflect.DeepEqual(existing.Spec.Template.Spec.NodeSelector, ss.Spec.Template.Spec.NodeSelector) {
-		existing.Spec.Template.Spec.NodeSelector = ss.Spec.Template.Spec.NodeSelector
-		*changed = true
-	}
-	if !reflect.DeepEqual(existing.Spec.Template.Spec.Tolerations, ss.Spec.Template.Spec.Tolerations) {
-		existing.Spec.Template.Spec.Tolerations = ss.Spec.Template.Spec.Tolerations
-		*changed = true
-	}
+	updatePodSchedulingOptions(&existing.Spec.Template.Spec, &ss.Spec.Template.Spec, changed)
 }
 
 // Returns true if a StatefulSet has pods in ErrImagePull or ImagePullBackoff state.
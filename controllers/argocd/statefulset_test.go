@@ -6,6 +6,7 @@ import (
 	"testing"
 	"time"
 
+	"k8s.io/apimachinery/pkg/api/errors"
 	resourcev1 "k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -16,6 +17,7 @@ import (
 
 	"github.com/google/go-cmp/cmp"
 	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 
 	"github.com/argoproj-labs/argocd-operator/common"
@@ -84,7 +86,7 @@ func TestReconcileArgoCD_reconcileRedisStatefulSet_HA_disabled(t *testing.T) {
 
 	s := newStatefulSetWithSuffix("redis-ha-server", "redis", a)
 
-	assert.NoError(t, r.reconcileRedisStatefulSet(a))
+	assert.NoError(t, r.reconcileRedisStatefulSet(a, false))
 	// resource Creation should fail as HA was disabled
 	assert.Errorf(t, r.Client.Get(context.TODO(), types.NamespacedName{Name: s.Name, Namespace: a.Namespace}, s), "not found")
 }
@@ -105,7 +107,7 @@ func TestReconcileArgoCD_reconcileRedisStatefulSet_HA_enabled(t *testing.T) {
 
 	a.Spec.HA.Enabled = true
 	// test resource is Created when HA is enabled
-	assert.NoError(t, r.reconcileRedisStatefulSet(a))
+	assert.NoError(t, r.reconcileRedisStatefulSet(a, false))
 	assert.NoError(t, r.Client.Get(context.TODO(), types.NamespacedName{Name: s.Name, Namespace: a.Namespace}, s))
 
 	// test resource is Updated on reconciliation
@@ -122,7 +124,7 @@ func TestReconcileArgoCD_reconcileRedisStatefulSet_HA_enabled(t *testing.T) {
 		},
 	}
 	a.Spec.HA.Resources = &newResources
-	assert.NoError(t, r.reconcileRedisStatefulSet(a))
+	assert.NoError(t, r.reconcileRedisStatefulSet(a, false))
 	assert.NoError(t, r.Client.Get(context.TODO(), types.NamespacedName{Name: s.Name, Namespace: a.Namespace}, s))
 	for _, container := range s.Spec.Template.Spec.Containers {
 		assert.Equal(t, container.Image, fmt.Sprintf("%s:%s", testRedisImage, testRedisImageVersion))
@@ -132,7 +134,7 @@ func TestReconcileArgoCD_reconcileRedisStatefulSet_HA_enabled(t *testing.T) {
 
 	// test resource is Deleted, when HA is disabled
 	a.Spec.HA.Enabled = false
-	assert.NoError(t, r.reconcileRedisStatefulSet(a))
+	assert.NoError(t, r.reconcileRedisStatefulSet(a, false))
 	assert.Errorf(t, r.Client.Get(context.TODO(), types.NamespacedName{Name: s.Name, Namespace: a.Namespace}, s), "not found")
 }
 
@@ -344,6 +346,100 @@ func TestReconcileArgoCD_reconcileApplicationController_withResources(t *testing
 	assert.False(t, testResources.Limits.Memory().Equal(*rsC.Limits.Memory()))
 }
 
+func TestReconcileArgoCD_reconcileApplicationController_withS3Import(t *testing.T) {
+	logf.SetLogger(ZapLogger(true))
+	a := makeTestArgoCD(func(a *argoproj.ArgoCD) {
+		a.Spec.Import = &argoproj.ArgoCDImportSpec{
+			Name:     "testimport",
+			BackupID: "20231017T120000Z",
+		}
+	})
+	ex := argoprojv1alpha1.ArgoCDExport{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "testimport",
+			Namespace: a.Namespace,
+		},
+		Spec: argoprojv1alpha1.ArgoCDExportSpec{
+			Storage: &argoprojv1alpha1.ArgoCDExportStorageSpec{
+				Backend: "s3",
+				S3: &argoprojv1alpha1.ArgoCDExportS3Spec{
+					Endpoint:       "https://minio.example.com:9000",
+					Bucket:         "backups",
+					ForcePathStyle: true,
+					CABundle: &corev1.ConfigMapKeySelector{
+						LocalObjectReference: corev1.LocalObjectReference{Name: "minio-ca"},
+						Key:                  "service-ca.crt",
+					},
+					ServerSideEncryption: &argoprojv1alpha1.ArgoCDExportS3SSESpec{Type: "AES256"},
+				},
+			},
+			Version: "v0.6.0",
+		},
+	}
+
+	resObjs := []client.Object{a, &ex}
+	subresObjs := []client.Object{a, &ex}
+	runtimeObjs := []runtime.Object{}
+	sch := makeTestReconcilerScheme(argoproj.AddToScheme, argoprojv1alpha1.AddToScheme)
+	cl := makeTestReconcilerClient(sch, resObjs, subresObjs, runtimeObjs)
+	r := makeTestReconciler(cl, sch)
+
+	assert.NoError(t, r.reconcileImport(a))
+	assert.NoError(t, r.reconcileApplicationControllerStatefulSet(a, false))
+
+	// the application controller is stopped while the import job runs
+	ss := &appsv1.StatefulSet{}
+	assert.NoError(t, r.Client.Get(
+		context.TODO(),
+		types.NamespacedName{
+			Name:      "argocd-application-controller",
+			Namespace: a.Namespace,
+		},
+		ss))
+	assert.Equal(t, int32(0), *ss.Spec.Replicas)
+	assert.Empty(t, ss.Spec.Template.Spec.InitContainers)
+
+	job := &batchv1.Job{}
+	assert.NoError(t, r.Client.Get(
+		context.TODO(),
+		types.NamespacedName{
+			Name:      getImportJobName(a, a.Status.Import),
+			Namespace: a.Namespace,
+		},
+		job))
+	assert.Equal(t, corev1.RestartPolicyNever, job.Spec.Template.Spec.RestartPolicy)
+	assert.Len(t, job.Spec.Template.Spec.Containers, 1)
+	container := job.Spec.Template.Spec.Containers[0]
+	assert.Equal(t, []string{"argocd-operator-util", "import", "s3"}, container.Command)
+
+	env := map[string]corev1.EnvVar{}
+	for _, e := range container.Env {
+		env[e.Name] = e
+	}
+	assert.Equal(t, "s3.access.key.id", env["AWS_ACCESS_KEY_ID"].ValueFrom.SecretKeyRef.Key)
+	assert.Equal(t, "s3.secret.access.key", env["AWS_SECRET_ACCESS_KEY"].ValueFrom.SecretKeyRef.Key)
+	assert.Equal(t, "https://minio.example.com:9000", env[common.ArgoCDExportS3EndpointEnvName].Value)
+	assert.Equal(t, "backups", env[common.ArgoCDExportS3BucketEnvName].Value)
+	assert.Equal(t, "true", env[common.ArgoCDExportS3ForcePathStyleEnvName].Value)
+	assert.Equal(t, "/s3-ca/ca.crt", env[common.ArgoCDExportS3CABundleEnvName].Value)
+	assert.Equal(t, "AES256", env[common.ArgoCDExportS3SSEEnvName].Value)
+	assert.NotContains(t, env, common.ArgoCDExportS3SSEKMSKeyIDEnvName)
+	assert.Equal(t, "20231017T120000Z", env[common.ArgoCDImportBackupIDEnvName].Value)
+
+	assert.Contains(t, container.VolumeMounts, corev1.VolumeMount{Name: "s3-ca-bundle", MountPath: "/s3-ca", ReadOnly: true})
+	defaultMode := corev1.ConfigMapVolumeSourceDefaultMode
+	assert.Contains(t, job.Spec.Template.Spec.Volumes, corev1.Volume{
+		Name: "s3-ca-bundle",
+		VolumeSource: corev1.VolumeSource{
+			ConfigMap: &corev1.ConfigMapVolumeSource{
+				LocalObjectReference: corev1.LocalObjectReference{Name: "minio-ca"},
+				Items:                []corev1.KeyToPath{{Key: "service-ca.crt", Path: "ca.crt"}},
+				DefaultMode:          &defaultMode,
+			},
+		},
+	})
+}
+
 func TestReconcileArgoCD_reconcileApplicationController_withSharding(t *testing.T) {
 	logf.SetLogger(ZapLogger(true))
 
@@ -624,3 +720,49 @@ func TestReconcileArgoCD_reconcileApplicationController_withDynamicSharding(t *t
 
 	}
 }
+
+func TestReconcileArgoCD_reconcileRedisStatefulSet_configChecksum(t *testing.T) {
+	logf.SetLogger(ZapLogger(true))
+	t.Setenv("REDIS_CONFIG_PATH", "../../build/redis")
+	a := makeTestArgoCD(func(a *argoproj.ArgoCD) {
+		a.Spec.HA.Enabled = true
+	})
+
+	resObjs := []client.Object{a}
+	subresObjs := []client.Object{a}
+	runtimeObjs := []runtime.Object{}
+	sch := makeTestReconcilerScheme(argoproj.AddToScheme)
+	cl := makeTestReconcilerClient(sch, resObjs, subresObjs, runtimeObjs)
+	r := makeTestReconciler(cl, sch)
+
+	s := newStatefulSetWithSuffix("redis-ha-server", "redis", a)
+	key := types.NamespacedName{Name: s.Name, Namespace: a.Namespace}
+	assert.NoError(t, r.reconcileRedisStatefulSet(a, false))
+	assert.NoError(t, r.Client.Get(context.TODO(), key, s))
+	checksum := s.Spec.Template.Annotations[common.ArgoCDRedisHAConfigChecksumAnnotation]
+	assert.Equal(t, getRedisHAServerChecksum(a, false), checksum)
+
+	// the servers are not rolled out when their configuration is unchanged
+	a.Spec.HA.RedisProxyImage = "haproxy"
+	assert.NoError(t, r.reconcileRedisStatefulSet(a, false))
+	assert.NoError(t, r.Client.Get(context.TODO(), key, s))
+	assert.Equal(t, checksum, s.Spec.Template.Annotations[common.ArgoCDRedisHAConfigChecksumAnnotation])
+
+	// the servers are recreated rather than rolled out once TLS is used, since they cannot talk across TLS modes
+	assert.Equal(t, "false", s.Annotations[common.ArgoCDRedisHATLSAnnotation])
+	assert.NoError(t, r.reconcileRedisStatefulSet(a, true))
+	assert.True(t, errors.IsNotFound(r.Client.Get(context.TODO(), key, s)))
+	assert.NoError(t, r.reconcileRedisStatefulSet(a, true))
+	assert.NoError(t, r.Client.Get(context.TODO(), key, s))
+	assert.Equal(t, "true", s.Annotations[common.ArgoCDRedisHATLSAnnotation])
+	tlsChecksum := s.Spec.Template.Annotations[common.ArgoCDRedisHAConfigChecksumAnnotation]
+	assert.NotEqual(t, checksum, tlsChecksum)
+
+	// the servers are rolled out once the TLS certificate changes
+	uid := s.UID
+	a.Status.RedisTLSChecksum = "rotated"
+	assert.NoError(t, r.reconcileRedisStatefulSet(a, true))
+	assert.NoError(t, r.Client.Get(context.TODO(), key, s))
+	assert.NotEqual(t, tlsChecksum, s.Spec.Template.Annotations[common.ArgoCDRedisHAConfigChecksumAnnotation])
+	assert.Equal(t, uid, s.UID)
+}
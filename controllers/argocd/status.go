@@ -16,12 +16,15 @@ package argocd
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"reflect"
 	"strings"
 
 	oappsv1 "github.com/openshift/api/apps/v1"
 	routev1 "github.com/openshift/api/route/v1"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -30,13 +33,14 @@ import (
 	"github.com/argoproj-labs/argocd-operator/controllers/argoutil"
 )
 
-// reconcileStatus will ensure that all of the Status properties are updated for the given ArgoCD.
-func (r *ReconcileArgoCD) reconcileStatus(cr *argoproj.ArgoCD) error {
+// reconcileStatus will ensure that all of the Status properties are updated for the given ArgoCD, based on the state
+// of its SSO reconciliation.
+func (r *ReconcileArgoCD) reconcileStatus(cr *argoproj.ArgoCD, sso *ssoState) error {
 	if err := r.reconcileStatusApplicationController(cr); err != nil {
 		return err
 	}
 
-	if err := r.reconcileStatusSSO(cr); err != nil {
+	if err := r.reconcileStatusSSO(cr, sso); err != nil {
 		log.Info(err.Error())
 	}
 
@@ -120,7 +124,11 @@ func (r *ReconcileArgoCD) reconcileStatusDex(cr *argoproj.ArgoCD) error {
 func (r *ReconcileArgoCD) reconcileStatusKeycloak(cr *argoproj.ArgoCD) error {
 	status := "Unknown"
 
-	if IsTemplateAPIAvailable() {
+	if cr.Spec.SSO != nil && cr.Spec.SSO.Keycloak.IsExternal() {
+		// an external keycloak is not managed by the operator, a failure to configure it is reported by the SSO
+		// configuration status.
+		status = "Running"
+	} else if IsTemplateAPIAvailable() {
 		// keycloak is installed using OpenShift templates.
 		dc := &oappsv1.DeploymentConfig{
 			ObjectMeta: metav1.ObjectMeta{
@@ -179,11 +187,17 @@ func (r *ReconcileArgoCD) reconcileStatusApplicationSetController(cr *argoproj.A
 	return nil
 }
 
-// reconcileStatusSSOConfig will ensure that the SSOConfig status is updated for the given ArgoCD.
-func (r *ReconcileArgoCD) reconcileStatusSSO(cr *argoproj.ArgoCD) error {
+// reconcileStatusSSO will ensure that the SSO status is updated for the given ArgoCD, based on the state of its SSO
+// reconciliation.
+func (r *ReconcileArgoCD) reconcileStatusSSO(cr *argoproj.ArgoCD, sso *ssoState) error {
 
-	// set status to track ssoConfigLegalStatus so it is always up to date with latest sso situation
-	status := ssoConfigLegalStatus
+	// set status to track the legal status of the sso configuration so it is always up to date with latest sso situation
+	status := sso.legalStatus
+
+	// a legal sso configuration that could not be applied is reported as failed as well
+	if status == ssoLegalSuccess && !sso.configApplied {
+		status = ssoLegalFailed
+	}
 
 	// perform dex/keycloak status reconciliation only if sso configurations are legal
 	if status == ssoLegalSuccess {
@@ -405,6 +419,216 @@ func (r *ReconcileArgoCD) reconcileStatusHost(cr *argoproj.ArgoCD) error {
 				cr.Status.Host = hosts
 			}
 		}
+	} else if cr.Spec.Server.Gateway.Enabled && IsGatewayAPIAvailable() {
+		route := newGatewayObject(httpRouteGVK, nameWithSuffix("server", cr), cr.Namespace)
+		if !argoutil.IsObjectFound(r.Client, cr.Namespace, route.GetName(), route) {
+			log.Info("argocd-server HTTPRoute requested but not found on cluster")
+			cr.Status.Phase = "Pending"
+		} else if host, accepted := r.getGatewayRouteHost(route); accepted {
+			cr.Status.Host = host
+		} else {
+			cr.Status.Phase = "Pending"
+		}
 	}
 	return r.Client.Status().Update(context.TODO(), cr)
 }
+
+// reconcileStepError identifies the reconcile step that returned an error, so that it can be
+// surfaced in the status conditions of the ArgoCD instance.
+type reconcileStepError struct {
+	step string
+	err  error
+}
+
+func newReconcileStepError(step string, err error) error {
+	return &reconcileStepError{step: step, err: err}
+}
+
+func (e *reconcileStepError) Error() string {
+	return e.err.Error()
+}
+
+func (e *reconcileStepError) Unwrap() error {
+	return e.err
+}
+
+// componentCondition maps a component status string to the status and reason of its Ready condition.
+func componentCondition(status string) (metav1.ConditionStatus, string) {
+	switch status {
+	case "Running":
+		return metav1.ConditionTrue, argoproj.ArgoCDReasonRunning
+	case "Pending":
+		return metav1.ConditionFalse, argoproj.ArgoCDReasonPending
+	case "Failed":
+		return metav1.ConditionFalse, argoproj.ArgoCDReasonFailed
+	default:
+		return metav1.ConditionUnknown, argoproj.ArgoCDReasonUnknown
+	}
+}
+
+// setComponentCondition sets the Ready condition of a component, or removes it when the component is disabled.
+func setComponentCondition(cr *argoproj.ArgoCD, conditionType string, enabled bool, status string) {
+	if !enabled {
+		meta.RemoveStatusCondition(&cr.Status.Conditions, conditionType)
+		return
+	}
+	conditionStatus, reason := componentCondition(status)
+	meta.SetStatusCondition(&cr.Status.Conditions, metav1.Condition{
+		Type:               conditionType,
+		Status:             conditionStatus,
+		Reason:             reason,
+		Message:            fmt.Sprintf("component status is %s", status),
+		ObservedGeneration: cr.Generation,
+	})
+}
+
+// reconcileStatusConditions will ensure that the Status Conditions and ObservedGeneration are updated for the
+// given ArgoCD, based on the component statuses, the state of its SSO reconciliation and the errors returned while
+// reconciling SSO and the other resources.
+func (r *ReconcileArgoCD) reconcileStatusConditions(cr *argoproj.ArgoCD, sso *ssoState, ssoErr, reconcileErr error) error {
+	existing := cr.Status.DeepCopy()
+
+	ssoEnabled := cr.Spec.SSO != nil
+	setComponentCondition(cr, argoproj.ArgoCDConditionApplicationControllerReady, cr.Spec.Controller.IsEnabled(), cr.Status.ApplicationController)
+	setComponentCondition(cr, argoproj.ArgoCDConditionApplicationSetControllerReady, cr.Spec.ApplicationSet != nil, cr.Status.ApplicationSetController)
+	setComponentCondition(cr, argoproj.ArgoCDConditionNotificationsControllerReady, cr.Spec.Notifications.Enabled, cr.Status.NotificationsController)
+	setComponentCondition(cr, argoproj.ArgoCDConditionRedisReady, cr.Spec.Redis.IsEnabled(), cr.Status.Redis)
+	setComponentCondition(cr, argoproj.ArgoCDConditionRepoReady, cr.Spec.Repo.IsEnabled(), cr.Status.Repo)
+	setComponentCondition(cr, argoproj.ArgoCDConditionServerReady, cr.Spec.Server.IsEnabled(), cr.Status.Server)
+	setComponentCondition(cr, argoproj.ArgoCDConditionSSOReady, ssoEnabled && sso.legalStatus == ssoLegalSuccess, cr.Status.SSO)
+
+	// SSO configuration
+	switch {
+	case ssoErr != nil || sso.legalStatus == ssoLegalFailed:
+		// only an SSO configuration that failed validation is illegal, any other error, e.g. a keycloak realm that
+		// failed to reconcile or an API error, means that the SSO configuration could not be applied.
+		reason := argoproj.ArgoCDReasonSSOConfigurationFailed
+		message := "illegal SSO configuration"
+		if sso.legalStatus == ssoLegalFailed {
+			reason = argoproj.ArgoCDReasonIllegalSSOConfiguration
+		}
+		if ssoErr != nil {
+			message = ssoErr.Error()
+		}
+		meta.SetStatusCondition(&cr.Status.Conditions, metav1.Condition{
+			Type:               argoproj.ArgoCDConditionSSOConfigured,
+			Status:             metav1.ConditionFalse,
+			Reason:             reason,
+			Message:            message,
+			ObservedGeneration: cr.Generation,
+		})
+	case ssoEnabled:
+		meta.SetStatusCondition(&cr.Status.Conditions, metav1.Condition{
+			Type:               argoproj.ArgoCDConditionSSOConfigured,
+			Status:             metav1.ConditionTrue,
+			Reason:             argoproj.ArgoCDReasonSSOConfigured,
+			Message:            fmt.Sprintf("SSO provider %s is configured", cr.Spec.SSO.Provider),
+			ObservedGeneration: cr.Generation,
+		})
+	default:
+		meta.RemoveStatusCondition(&cr.Status.Conditions, argoproj.ArgoCDConditionSSOConfigured)
+	}
+
+	// ReconcileSuccess
+	if reconcileErr != nil {
+		reason := argoproj.ArgoCDReasonReconcileFailed
+		message := reconcileErr.Error()
+		var stepErr *reconcileStepError
+		if errors.As(reconcileErr, &stepErr) {
+			reason = stepErr.step + argoproj.ArgoCDReasonReconcileFailed
+			message = fmt.Sprintf("failed to reconcile %s: %s", stepErr.step, stepErr.err.Error())
+		}
+		meta.SetStatusCondition(&cr.Status.Conditions, metav1.Condition{
+			Type:               argoproj.ArgoCDConditionReconcileSuccess,
+			Status:             metav1.ConditionFalse,
+			Reason:             reason,
+			Message:            message,
+			ObservedGeneration: cr.Generation,
+		})
+	} else {
+		meta.SetStatusCondition(&cr.Status.Conditions, metav1.Condition{
+			Type:               argoproj.ArgoCDConditionReconcileSuccess,
+			Status:             metav1.ConditionTrue,
+			Reason:             argoproj.ArgoCDReasonReconcileSucceeded,
+			Message:            "all resources have been reconciled",
+			ObservedGeneration: cr.Generation,
+		})
+	}
+
+	// Degraded: reconciliation failed, the SSO configuration is illegal or a component reports a failure.
+	degradedCondition := metav1.Condition{
+		Type:               argoproj.ArgoCDConditionDegraded,
+		Status:             metav1.ConditionFalse,
+		Reason:             argoproj.ArgoCDReasonReconcileSucceeded,
+		Message:            "no errors reported",
+		ObservedGeneration: cr.Generation,
+	}
+	failedComponents := []string{}
+	for _, c := range cr.Status.Conditions {
+		if c.Reason == argoproj.ArgoCDReasonFailed {
+			failedComponents = append(failedComponents, c.Type)
+		}
+	}
+	if c := meta.FindStatusCondition(cr.Status.Conditions, argoproj.ArgoCDConditionReconcileSuccess); c != nil && c.Status == metav1.ConditionFalse {
+		degradedCondition.Status = metav1.ConditionTrue
+		degradedCondition.Reason = c.Reason
+		degradedCondition.Message = c.Message
+	} else if c := meta.FindStatusCondition(cr.Status.Conditions, argoproj.ArgoCDConditionSSOConfigured); c != nil && c.Status == metav1.ConditionFalse {
+		degradedCondition.Status = metav1.ConditionTrue
+		degradedCondition.Reason = c.Reason
+		degradedCondition.Message = c.Message
+	} else if len(failedComponents) > 0 {
+		degradedCondition.Status = metav1.ConditionTrue
+		degradedCondition.Reason = argoproj.ArgoCDReasonComponentFailed
+		degradedCondition.Message = fmt.Sprintf("failed components: %s", strings.Join(failedComponents, ", "))
+	}
+	meta.SetStatusCondition(&cr.Status.Conditions, degradedCondition)
+
+	// Available and Progressing follow the phase computed from the component statuses.
+	if cr.Status.Phase == "Available" {
+		meta.SetStatusCondition(&cr.Status.Conditions, metav1.Condition{
+			Type:               argoproj.ArgoCDConditionAvailable,
+			Status:             metav1.ConditionTrue,
+			Reason:             argoproj.ArgoCDReasonComponentsReady,
+			Message:            "all enabled components are running",
+			ObservedGeneration: cr.Generation,
+		})
+	} else {
+		meta.SetStatusCondition(&cr.Status.Conditions, metav1.Condition{
+			Type:               argoproj.ArgoCDConditionAvailable,
+			Status:             metav1.ConditionFalse,
+			Reason:             argoproj.ArgoCDReasonComponentsPending,
+			Message:            "one or more components are not running",
+			ObservedGeneration: cr.Generation,
+		})
+	}
+
+	if cr.Status.Phase != "Available" && degradedCondition.Status == metav1.ConditionFalse {
+		meta.SetStatusCondition(&cr.Status.Conditions, metav1.Condition{
+			Type:               argoproj.ArgoCDConditionProgressing,
+			Status:             metav1.ConditionTrue,
+			Reason:             argoproj.ArgoCDReasonComponentsPending,
+			Message:            "waiting for components to become ready",
+			ObservedGeneration: cr.Generation,
+		})
+	} else {
+		reason := argoproj.ArgoCDReasonComponentsReady
+		if degradedCondition.Status == metav1.ConditionTrue {
+			reason = degradedCondition.Reason
+		}
+		meta.SetStatusCondition(&cr.Status.Conditions, metav1.Condition{
+			Type:               argoproj.ArgoCDConditionProgressing,
+			Status:             metav1.ConditionFalse,
+			Reason:             reason,
+			Message:            "no rollout in progress",
+			ObservedGeneration: cr.Generation,
+		})
+	}
+
+	cr.Status.ObservedGeneration = cr.Generation
+
+	if !reflect.DeepEqual(existing, &cr.Status) {
+		return r.Client.Status().Update(context.TODO(), cr)
+	}
+	return nil
+}
@@ -2,6 +2,7 @@ package argocd
 
 import (
 	"context"
+	"errors"
 	"testing"
 
 	argoproj "github.com/argoproj-labs/argocd-operator/api/v1beta1"
@@ -11,6 +12,7 @@ import (
 	routev1 "github.com/openshift/api/route/v1"
 	"github.com/stretchr/testify/assert"
 	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -156,9 +158,9 @@ func TestReconcileArgoCD_reconcileStatusSSO(t *testing.T) {
 
 			assert.NoError(t, createNamespace(r, test.argoCD.Namespace, ""))
 
-			r.reconcileSSO(test.argoCD)
+			sso, _ := r.reconcileSSO(test.argoCD)
 
-			r.reconcileStatusSSO(test.argoCD)
+			r.reconcileStatusSSO(test.argoCD, sso)
 
 			assert.Equal(t, test.wantSSOStatus, test.argoCD.Status.SSO)
 		})
@@ -321,3 +323,87 @@ func TestReconcileArgoCD_reconcileStatusApplicationSetController(t *testing.T) {
 	assert.NoError(t, r.reconcileStatusApplicationSetController(a))
 	assert.Equal(t, "Pending", a.Status.ApplicationSetController)
 }
+
+func TestReconcileArgoCD_reconcileStatusConditions(t *testing.T) {
+	logf.SetLogger(ZapLogger(true))
+	a := makeTestArgoCD()
+	a.Generation = 3
+
+	resObjs := []client.Object{a}
+	subresObjs := []client.Object{a}
+	runtimeObjs := []runtime.Object{}
+	sch := makeTestReconcilerScheme(argoproj.AddToScheme)
+	cl := makeTestReconcilerClient(sch, resObjs, subresObjs, runtimeObjs)
+	r := makeTestReconciler(cl, sch)
+
+	sso := newSSOState()
+
+	// components still rolling out
+	a.Status.Phase = "Pending"
+	a.Status.Server = "Pending"
+	a.Status.Repo = "Running"
+	assert.NoError(t, r.reconcileStatusConditions(a, sso, nil, nil))
+	assert.Equal(t, int64(3), a.Status.ObservedGeneration)
+	assert.True(t, meta.IsStatusConditionTrue(a.Status.Conditions, argoproj.ArgoCDConditionProgressing))
+	assert.True(t, meta.IsStatusConditionFalse(a.Status.Conditions, argoproj.ArgoCDConditionAvailable))
+	assert.True(t, meta.IsStatusConditionFalse(a.Status.Conditions, argoproj.ArgoCDConditionDegraded))
+	assert.True(t, meta.IsStatusConditionTrue(a.Status.Conditions, argoproj.ArgoCDConditionReconcileSuccess))
+	assert.True(t, meta.IsStatusConditionTrue(a.Status.Conditions, argoproj.ArgoCDConditionRepoReady))
+	assert.True(t, meta.IsStatusConditionFalse(a.Status.Conditions, argoproj.ArgoCDConditionServerReady))
+	assert.Nil(t, meta.FindStatusCondition(a.Status.Conditions, argoproj.ArgoCDConditionNotificationsControllerReady))
+	assert.Nil(t, meta.FindStatusCondition(a.Status.Conditions, argoproj.ArgoCDConditionSSOConfigured))
+
+	// a reconcile step failed
+	stepErr := newReconcileStepError("Deployments", errors.New("quota exceeded"))
+	assert.NoError(t, r.reconcileStatusConditions(a, sso, nil, stepErr))
+	reconciled := meta.FindStatusCondition(a.Status.Conditions, argoproj.ArgoCDConditionReconcileSuccess)
+	assert.Equal(t, metav1.ConditionFalse, reconciled.Status)
+	assert.Equal(t, "DeploymentsReconcileFailed", reconciled.Reason)
+	assert.Equal(t, "failed to reconcile Deployments: quota exceeded", reconciled.Message)
+	assert.True(t, meta.IsStatusConditionTrue(a.Status.Conditions, argoproj.ArgoCDConditionDegraded))
+	assert.True(t, meta.IsStatusConditionFalse(a.Status.Conditions, argoproj.ArgoCDConditionProgressing))
+
+	// illegal SSO configuration
+	sso.legalStatus = ssoLegalFailed
+	a.Spec.SSO = &argoproj.ArgoCDSSOSpec{Provider: argoproj.SSOProviderTypeDex}
+	assert.NoError(t, r.reconcileStatusConditions(a, sso, errors.New("illegal SSO configuration: must supply dex configuration"), nil))
+	ssoCondition := meta.FindStatusCondition(a.Status.Conditions, argoproj.ArgoCDConditionSSOConfigured)
+	assert.Equal(t, metav1.ConditionFalse, ssoCondition.Status)
+	assert.Equal(t, argoproj.ArgoCDReasonIllegalSSOConfiguration, ssoCondition.Reason)
+	degraded := meta.FindStatusCondition(a.Status.Conditions, argoproj.ArgoCDConditionDegraded)
+	assert.Equal(t, argoproj.ArgoCDReasonIllegalSSOConfiguration, degraded.Reason)
+	assert.Nil(t, meta.FindStatusCondition(a.Status.Conditions, argoproj.ArgoCDConditionSSOReady))
+
+	// legal SSO configuration that could not be applied
+	sso.legalStatus = ssoLegalSuccess
+	a.Spec.SSO = &argoproj.ArgoCDSSOSpec{Provider: argoproj.SSOProviderTypeKeycloak}
+	assert.NoError(t, r.reconcileStatusConditions(a, sso, errors.New("failed to reconcile the keycloak realm argocd: GET /auth/admin/realms/argocd: unexpected response 403 Forbidden"), nil))
+	ssoCondition = meta.FindStatusCondition(a.Status.Conditions, argoproj.ArgoCDConditionSSOConfigured)
+	assert.Equal(t, metav1.ConditionFalse, ssoCondition.Status)
+	assert.Equal(t, argoproj.ArgoCDReasonSSOConfigurationFailed, ssoCondition.Reason)
+	assert.Equal(t, "failed to reconcile the keycloak realm argocd: GET /auth/admin/realms/argocd: unexpected response 403 Forbidden", ssoCondition.Message)
+
+	// SSO errors raised before the configuration is validated are not reported as an illegal configuration
+	sso.legalStatus = ssoLegalUnknown
+	assert.NoError(t, r.reconcileStatusConditions(a, sso, errors.New("Unauthorized"), nil))
+	ssoCondition = meta.FindStatusCondition(a.Status.Conditions, argoproj.ArgoCDConditionSSOConfigured)
+	assert.Equal(t, metav1.ConditionFalse, ssoCondition.Status)
+	assert.Equal(t, argoproj.ArgoCDReasonSSOConfigurationFailed, ssoCondition.Reason)
+
+	// everything is running
+	sso.legalStatus = ssoLegalUnknown
+	a.Spec.SSO = nil
+	a.Status.Phase = "Available"
+	a.Status.Server = "Running"
+	assert.NoError(t, r.reconcileStatusConditions(a, sso, nil, nil))
+	assert.True(t, meta.IsStatusConditionTrue(a.Status.Conditions, argoproj.ArgoCDConditionAvailable))
+	assert.True(t, meta.IsStatusConditionFalse(a.Status.Conditions, argoproj.ArgoCDConditionProgressing))
+	assert.True(t, meta.IsStatusConditionFalse(a.Status.Conditions, argoproj.ArgoCDConditionDegraded))
+	assert.Nil(t, meta.FindStatusCondition(a.Status.Conditions, argoproj.ArgoCDConditionSSOConfigured))
+
+	// the conditions are persisted
+	persisted := &argoproj.ArgoCD{}
+	assert.NoError(t, cl.Get(context.TODO(), client.ObjectKeyFromObject(a), persisted))
+	assert.Equal(t, int64(3), persisted.Status.ObservedGeneration)
+	assert.True(t, meta.IsStatusConditionTrue(persisted.Status.Conditions, argoproj.ArgoCDConditionAvailable))
+}
@@ -54,8 +54,9 @@ type SchemeOpt func(*runtime.Scheme) error
 
 func makeTestReconciler(client client.Client, sch *runtime.Scheme) *ReconcileArgoCD {
 	return &ReconcileArgoCD{
-		Client: client,
-		Scheme: sch,
+		Client:    client,
+		Scheme:    sch,
+		Instances: NewInstanceRegistry(),
 	}
 }
 
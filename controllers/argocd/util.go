@@ -31,6 +31,7 @@ import (
 
 	"gopkg.in/yaml.v2"
 
+	argoprojv1alpha1 "github.com/argoproj-labs/argocd-operator/api/v1alpha1"
 	argoproj "github.com/argoproj-labs/argocd-operator/api/v1beta1"
 	"github.com/argoproj-labs/argocd-operator/common"
 	"github.com/argoproj-labs/argocd-operator/controllers/argoutil"
@@ -43,8 +44,10 @@ import (
 	"github.com/sethvargo/go-password/password"
 	"golang.org/x/mod/semver"
 	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	networkingv1 "k8s.io/api/networking/v1"
+	policyv1 "k8s.io/api/policy/v1"
 	v1 "k8s.io/api/rbac/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
@@ -173,10 +176,10 @@ func getArgoContainerImage(cr *argoproj.ArgoCD) string {
 		defaultTag = true
 	}
 	if e := os.Getenv(common.ArgoCDImageEnvName); e != "" && (defaultTag && defaultImg) {
-		return e
+		return argoutil.ReplaceImageRegistry(e, cr.Spec.ImageRegistry)
 	}
 
-	return argoutil.CombineImageTag(img, tag)
+	return argoutil.ReplaceImageRegistry(argoutil.CombineImageTag(img, tag), cr.Spec.ImageRegistry)
 }
 
 // getRepoServerContainerImage will return the container image for the Repo server.
@@ -204,9 +207,9 @@ func getRepoServerContainerImage(cr *argoproj.ArgoCD) string {
 		defaultTag = true
 	}
 	if e := os.Getenv(common.ArgoCDImageEnvName); e != "" && (defaultTag && defaultImg) {
-		return e
+		return argoutil.ReplaceImageRegistry(e, cr.Spec.ImageRegistry)
 	}
-	return argoutil.CombineImageTag(img, tag)
+	return argoutil.ReplaceImageRegistry(argoutil.CombineImageTag(img, tag), cr.Spec.ImageRegistry)
 }
 
 // getArgoRepoResources will return the ResourceRequirements for the Argo CD Repo server container.
@@ -348,9 +351,9 @@ func getGrafanaContainerImage(cr *argoproj.ArgoCD) string {
 		defaultTag = true
 	}
 	if e := os.Getenv(common.ArgoCDGrafanaImageEnvName); e != "" && (defaultTag && defaultImg) {
-		return e
+		return argoutil.ReplaceImageRegistry(e, cr.Spec.ImageRegistry)
 	}
-	return argoutil.CombineImageTag(img, tag)
+	return argoutil.ReplaceImageRegistry(argoutil.CombineImageTag(img, tag), cr.Spec.ImageRegistry)
 }
 
 // getGrafanaResources will return the ResourceRequirements for the Grafana container.
@@ -376,10 +379,11 @@ func getRedisConfigPath() string {
 
 // getRedisInitScript will load the redis configuration from a template on disk for the given ArgoCD.
 // If an error occurs, an empty string value will be returned.
-func getRedisConf(useTLSForRedis bool) string {
+func getRedisConf(cr *argoproj.ArgoCD, useTLSForRedis bool) string {
 	path := fmt.Sprintf("%s/redis.conf.tpl", getRedisConfigPath())
 	params := map[string]string{
-		"UseTLS": strconv.FormatBool(useTLSForRedis),
+		"UseAuth": strconv.FormatBool(cr.Spec.Redis.WantsAuth()),
+		"UseTLS":  strconv.FormatBool(useTLSForRedis),
 	}
 	conf, err := loadTemplateFile(path, params)
 	if err != nil {
@@ -403,9 +407,9 @@ func getRedisContainerImage(cr *argoproj.ArgoCD) string {
 		defaultTag = true
 	}
 	if e := os.Getenv(common.ArgoCDRedisImageEnvName); e != "" && (defaultTag && defaultImg) {
-		return e
+		return argoutil.ReplaceImageRegistry(e, cr.Spec.ImageRegistry)
 	}
-	return argoutil.CombineImageTag(img, tag)
+	return argoutil.ReplaceImageRegistry(argoutil.CombineImageTag(img, tag), cr.Spec.ImageRegistry)
 }
 
 // getRedisHAContainerImage will return the container image for the Redis server in HA mode.
@@ -422,9 +426,9 @@ func getRedisHAContainerImage(cr *argoproj.ArgoCD) string {
 		defaultTag = true
 	}
 	if e := os.Getenv(common.ArgoCDRedisHAImageEnvName); e != "" && (defaultTag && defaultImg) {
-		return e
+		return argoutil.ReplaceImageRegistry(e, cr.Spec.ImageRegistry)
 	}
-	return argoutil.CombineImageTag(img, tag)
+	return argoutil.ReplaceImageRegistry(argoutil.CombineImageTag(img, tag), cr.Spec.ImageRegistry)
 }
 
 // getRedisHAProxyAddress will return the Redis HA Proxy service address for the given ArgoCD.
@@ -448,10 +452,10 @@ func getRedisHAProxyContainerImage(cr *argoproj.ArgoCD) string {
 	}
 
 	if e := os.Getenv(common.ArgoCDRedisHAProxyImageEnvName); e != "" && (defaultTag && defaultImg) {
-		return e
+		return argoutil.ReplaceImageRegistry(e, cr.Spec.ImageRegistry)
 	}
 
-	return argoutil.CombineImageTag(img, tag)
+	return argoutil.ReplaceImageRegistry(argoutil.CombineImageTag(img, tag), cr.Spec.ImageRegistry)
 }
 
 // getRedisInitScript will load the redis init script from a template on disk for the given ArgoCD.
@@ -460,6 +464,7 @@ func getRedisInitScript(cr *argoproj.ArgoCD, useTLSForRedis bool) string {
 	path := fmt.Sprintf("%s/init.sh.tpl", getRedisConfigPath())
 	vars := map[string]string{
 		"ServiceName": nameWithSuffix("redis-ha", cr),
+		"UseAuth":     strconv.FormatBool(cr.Spec.Redis.WantsAuth()),
 		"UseTLS":      strconv.FormatBool(useTLSForRedis),
 	}
 
@@ -477,6 +482,7 @@ func getRedisHAProxyConfig(cr *argoproj.ArgoCD, useTLSForRedis bool) string {
 	path := fmt.Sprintf("%s/haproxy.cfg.tpl", getRedisConfigPath())
 	vars := map[string]string{
 		"ServiceName": nameWithSuffix("redis-ha", cr),
+		"UseAuth":     strconv.FormatBool(cr.Spec.Redis.WantsAuth()),
 		"UseTLS":      strconv.FormatBool(useTLSForRedis),
 	}
 
@@ -530,10 +536,11 @@ func getRedisHAResources(cr *argoproj.ArgoCD) corev1.ResourceRequirements {
 
 // getRedisSentinelConf will load the redis sentinel configuration from a template on disk for the given ArgoCD.
 // If an error occurs, an empty string value will be returned.
-func getRedisSentinelConf(useTLSForRedis bool) string {
+func getRedisSentinelConf(cr *argoproj.ArgoCD, useTLSForRedis bool) string {
 	path := fmt.Sprintf("%s/sentinel.conf.tpl", getRedisConfigPath())
 	params := map[string]string{
-		"UseTLS": strconv.FormatBool(useTLSForRedis),
+		"UseAuth": strconv.FormatBool(cr.Spec.Redis.WantsAuth()),
+		"UseTLS":  strconv.FormatBool(useTLSForRedis),
 	}
 	conf, err := loadTemplateFile(path, params)
 	if err != nil {
@@ -545,10 +552,11 @@ func getRedisSentinelConf(useTLSForRedis bool) string {
 
 // getRedisLivenessScript will load the redis liveness script from a template on disk for the given ArgoCD.
 // If an error occurs, an empty string value will be returned.
-func getRedisLivenessScript(useTLSForRedis bool) string {
+func getRedisLivenessScript(cr *argoproj.ArgoCD, useTLSForRedis bool) string {
 	path := fmt.Sprintf("%s/redis_liveness.sh.tpl", getRedisConfigPath())
 	params := map[string]string{
-		"UseTLS": strconv.FormatBool(useTLSForRedis),
+		"UseAuth": strconv.FormatBool(cr.Spec.Redis.WantsAuth()),
+		"UseTLS":  strconv.FormatBool(useTLSForRedis),
 	}
 	conf, err := loadTemplateFile(path, params)
 	if err != nil {
@@ -560,10 +568,11 @@ func getRedisLivenessScript(useTLSForRedis bool) string {
 
 // getRedisReadinessScript will load the redis readiness script from a template on disk for the given ArgoCD.
 // If an error occurs, an empty string value will be returned.
-func getRedisReadinessScript(useTLSForRedis bool) string {
+func getRedisReadinessScript(cr *argoproj.ArgoCD, useTLSForRedis bool) string {
 	path := fmt.Sprintf("%s/redis_readiness.sh.tpl", getRedisConfigPath())
 	params := map[string]string{
-		"UseTLS": strconv.FormatBool(useTLSForRedis),
+		"UseAuth": strconv.FormatBool(cr.Spec.Redis.WantsAuth()),
+		"UseTLS":  strconv.FormatBool(useTLSForRedis),
 	}
 	conf, err := loadTemplateFile(path, params)
 	if err != nil {
@@ -629,8 +638,8 @@ func fqdnServiceRef(service string, port int, cr *argoproj.ArgoCD) string {
 	return fmt.Sprintf("%s.%s.svc.cluster.local:%d", nameWithSuffix(service, cr), cr.Namespace, port)
 }
 
-// InspectCluster will verify the availability of extra features available to the cluster, such as Prometheus and
-// OpenShift Routes.
+// InspectCluster will verify the availability of extra features available to the cluster, such as Prometheus,
+// OpenShift Routes, the Gateway API and cert-manager.
 func InspectCluster() error {
 	if err := verifyPrometheusAPI(); err != nil {
 		return err
@@ -640,6 +649,14 @@ func InspectCluster() error {
 		return err
 	}
 
+	if err := verifyGatewayAPI(); err != nil {
+		return err
+	}
+
+	if err := verifyCertManagerAPI(); err != nil {
+		return err
+	}
+
 	if err := verifyTemplateAPI(); err != nil {
 		return err
 	}
@@ -714,131 +731,176 @@ func (r *ReconcileArgoCD) redisShouldUseTLS(cr *argoproj.ArgoCD) bool {
 }
 
 // reconcileResources will reconcile common ArgoCD resources.
-func (r *ReconcileArgoCD) reconcileResources(cr *argoproj.ArgoCD) error {
+func (r *ReconcileArgoCD) reconcileResources(cr *argoproj.ArgoCD) (err error) {
+	var ssoErr error
+	sso := newSSOState()
+
+	// record the outcome of this reconciliation as status conditions, regardless of where it stopped
+	defer func() {
+		if statusErr := r.reconcileStatusConditions(cr, sso, ssoErr, err); statusErr != nil {
+			log.Error(statusErr, "failed to update status conditions")
+		}
+	}()
 
 	// we reconcile SSO first so that we can catch and throw errors for any illegal SSO configurations right away, and return control from here
 	// preventing dex resources from getting created anyway through the other function calls, effectively bypassing the SSO checks
 	log.Info("reconciling SSO")
-	if err := r.reconcileSSO(cr); err != nil {
-		log.Info(err.Error())
+	if sso, ssoErr = r.reconcileSSO(cr); ssoErr != nil {
+		log.Info(ssoErr.Error())
 	}
 
 	log.Info("reconciling status")
-	if err := r.reconcileStatus(cr); err != nil {
+	if err := r.reconcileStatus(cr, sso); err != nil {
 		log.Info(err.Error())
 	}
 
 	log.Info("reconciling roles")
 	if err := r.reconcileRoles(cr); err != nil {
 		log.Info(err.Error())
-		return err
+		return newReconcileStepError("Roles", err)
 	}
 
 	log.Info("reconciling rolebindings")
 	if err := r.reconcileRoleBindings(cr); err != nil {
 		log.Info(err.Error())
-		return err
+		return newReconcileStepError("RoleBindings", err)
 	}
 
 	log.Info("reconciling service accounts")
 	if err := r.reconcileServiceAccounts(cr); err != nil {
 		log.Info(err.Error())
-		return err
+		return newReconcileStepError("ServiceAccounts", err)
 	}
 
 	log.Info("reconciling certificate authority")
 	if err := r.reconcileCertificateAuthority(cr); err != nil {
-		return err
+		return newReconcileStepError("CertificateAuthority", err)
+	}
+
+	if IsCertManagerAPIAvailable() {
+		log.Info("reconciling certificates")
+		if err := r.reconcileCertificates(cr); err != nil {
+			return newReconcileStepError("Certificates", err)
+		}
+	} else if cr.Spec.Server.WantsCertManagerTLS() || cr.Spec.Repo.WantsCertManagerTLS() || cr.Spec.Redis.WantsCertManagerTLS() {
+		log.Info("the cert-manager API is not available, the cert-manager autotls provider is ignored", "namespace", cr.Namespace, "name", cr.Name)
 	}
 
 	log.Info("reconciling secrets")
 	if err := r.reconcileSecrets(cr); err != nil {
-		return err
+		return newReconcileStepError("Secrets", err)
+	}
+
+	log.Info("reconciling certificate expiry")
+	if err := r.reconcileCertificateExpiry(cr); err != nil {
+		return newReconcileStepError("CertificateExpiry", err)
 	}
 
 	useTLSForRedis := r.redisShouldUseTLS(cr)
 
 	log.Info("reconciling config maps")
 	if err := r.reconcileConfigMaps(cr, useTLSForRedis); err != nil {
-		return err
+		return newReconcileStepError("ConfigMaps", err)
 	}
 
 	log.Info("reconciling services")
 	if err := r.reconcileServices(cr); err != nil {
-		return err
+		return newReconcileStepError("Services", err)
 	}
 
 	log.Info("reconciling deployments")
 	if err := r.reconcileDeployments(cr, useTLSForRedis); err != nil {
-		return err
+		return newReconcileStepError("Deployments", err)
+	}
+
+	log.Info("reconciling import")
+	if err := r.reconcileImport(cr); err != nil {
+		return newReconcileStepError("Import", err)
 	}
 
 	log.Info("reconciling statefulsets")
 	if err := r.reconcileStatefulSets(cr, useTLSForRedis); err != nil {
-		return err
+		return newReconcileStepError("StatefulSets", err)
 	}
 
 	log.Info("reconciling autoscalers")
 	if err := r.reconcileAutoscalers(cr); err != nil {
-		return err
+		return newReconcileStepError("Autoscalers", err)
+	}
+
+	log.Info("reconciling pod disruption budgets")
+	if err := r.reconcilePodDisruptionBudgets(cr); err != nil {
+		return newReconcileStepError("PodDisruptionBudgets", err)
+	}
+
+	log.Info("reconciling network policies")
+	if err := r.reconcileNetworkPolicies(cr); err != nil {
+		return newReconcileStepError("NetworkPolicies", err)
 	}
 
 	log.Info("reconciling ingresses")
 	if err := r.reconcileIngresses(cr); err != nil {
-		return err
+		return newReconcileStepError("Ingresses", err)
 	}
 
 	if IsRouteAPIAvailable() {
 		log.Info("reconciling routes")
 		if err := r.reconcileRoutes(cr); err != nil {
-			return err
+			return newReconcileStepError("Routes", err)
+		}
+	}
+
+	if IsGatewayAPIAvailable() {
+		log.Info("reconciling gateway routes")
+		if err := r.reconcileGatewayRoutes(cr); err != nil {
+			return newReconcileStepError("GatewayRoutes", err)
 		}
 	}
 
 	if IsPrometheusAPIAvailable() {
 		log.Info("reconciling prometheus")
 		if err := r.reconcilePrometheus(cr); err != nil {
-			return err
+			return newReconcileStepError("Prometheus", err)
 		}
 
 		// Reconciles prometheusRule created to alert based on argo-cd workload status
 		if err := r.reconcilePrometheusRule(cr); err != nil {
-			return err
+			return newReconcileStepError("PrometheusRule", err)
 		}
 
 		if err := r.reconcileMetricsServiceMonitor(cr); err != nil {
-			return err
+			return newReconcileStepError("MetricsServiceMonitor", err)
 		}
 
 		if err := r.reconcileRepoServerServiceMonitor(cr); err != nil {
-			return err
+			return newReconcileStepError("RepoServerServiceMonitor", err)
 		}
 
 		if err := r.reconcileServerMetricsServiceMonitor(cr); err != nil {
-			return err
+			return newReconcileStepError("ServerMetricsServiceMonitor", err)
 		}
 	}
 
 	if cr.Spec.ApplicationSet != nil {
 		log.Info("reconciling ApplicationSet controller")
 		if err := r.reconcileApplicationSetController(cr); err != nil {
-			return err
+			return newReconcileStepError("ApplicationSetController", err)
 		}
 	}
 
 	if cr.Spec.Notifications.Enabled {
 		log.Info("reconciling Notifications controller")
 		if err := r.reconcileNotificationsController(cr); err != nil {
-			return err
+			return newReconcileStepError("NotificationsController", err)
 		}
 	}
 
 	if err := r.reconcileRepoServerTLSSecret(cr); err != nil {
-		return err
+		return newReconcileStepError("RepoServerTLSSecret", err)
 	}
 
-	if err := r.reconcileRedisTLSSecret(cr, useTLSForRedis); err != nil {
-		return err
+	if err := r.reconcileRedisTLSSecret(cr); err != nil {
+		return newReconcileStepError("RedisTLSSecret", err)
 	}
 
 	return nil
@@ -943,7 +1005,7 @@ func removeString(slice []string, s string) []string {
 }
 
 // setResourceWatches will register Watches for each of the supported Resources.
-func (r *ReconcileArgoCD) setResourceWatches(bldr *builder.Builder, clusterResourceMapper, tlsSecretMapper, namespaceResourceMapper, clusterSecretResourceMapper, applicationSetGitlabSCMTLSConfigMapMapper handler.MapFunc) *builder.Builder {
+func (r *ReconcileArgoCD) setResourceWatches(bldr *builder.Builder, clusterResourceMapper, tlsSecretMapper, namespaceResourceMapper, clusterSecretResourceMapper, applicationSetGitlabSCMTLSConfigMapMapper, argoCDExportMapper, dexConnectorSecretMapper, redisAuthSecretMapper handler.MapFunc) *builder.Builder {
 
 	deploymentConfigPred := predicate.Funcs{
 		UpdateFunc: func(e event.UpdateEvent) bool {
@@ -953,25 +1015,11 @@ func (r *ReconcileArgoCD) setResourceWatches(bldr *builder.Builder, clusterResou
 			if !ok {
 				return false
 			}
-			oldDC, ok := e.ObjectOld.(*oappsv1.DeploymentConfig)
-			if !ok {
-				return false
-			}
+			// The keycloak realm is reconciled once keycloak is running again, e.g. after its pod was deleted.
 			if newDC.Name == defaultKeycloakIdentifier {
 				if newDC.Status.AvailableReplicas == count {
 					return true
 				}
-				if newDC.Status.AvailableReplicas == int32(0) &&
-					!reflect.DeepEqual(oldDC.Status.AvailableReplicas, newDC.Status.AvailableReplicas) {
-					// Handle the deletion of keycloak pod.
-					log.Info(fmt.Sprintf("Handle the pod deletion event for keycloak deployment config %s in namespace %s",
-						newDC.Name, newDC.Namespace))
-					err := handleKeycloakPodDeletion(newDC)
-					if err != nil {
-						log.Error(err, fmt.Sprintf("Failed to update Deployment Config %s for keycloak pod deletion in namespace %s",
-							newDC.Name, newDC.Namespace))
-					}
-				}
 			}
 			return false
 		},
@@ -999,7 +1047,7 @@ func (r *ReconcileArgoCD) setResourceWatches(bldr *builder.Builder, clusterResou
 
 			// Trigger reconciliation of SSO on update event
 			if !reflect.DeepEqual(oldCR.Spec.SSO, newCR.Spec.SSO) && newCR.Spec.SSO != nil && oldCR.Spec.SSO != nil {
-				err := r.reconcileSSO(newCR)
+				_, err := r.reconcileSSO(newCR)
 				if err != nil {
 					log.Error(err, fmt.Sprintf("Failed to update existing SSO Configuration for ArgoCD %s in namespace %s",
 						newCR.Name, newCR.Namespace))
@@ -1079,9 +1127,27 @@ func (r *ReconcileArgoCD) setResourceWatches(bldr *builder.Builder, clusterResou
 			common.ArgoCDManagedByClusterArgoCDLabel: "cluster",
 		}}}, clusterSecretResourceHandler)
 
+	// Watch for the secrets referenced by the typed Dex connectors of the argocd instances
+	bldr.Watches(&corev1.Secret{}, handler.EnqueueRequestsFromMapFunc(dexConnectorSecretMapper))
+
+	// Watch for the Redis password secrets supplied to the argocd instances
+	bldr.Watches(&corev1.Secret{}, handler.EnqueueRequestsFromMapFunc(redisAuthSecretMapper))
+
 	// Watch for changes to Secret sub-resources owned by ArgoCD instances.
 	bldr.Owns(&appsv1.StatefulSet{})
 
+	// Watch for changes to PodDisruptionBudget sub-resources owned by ArgoCD instances.
+	bldr.Owns(&policyv1.PodDisruptionBudget{})
+
+	// Watch for changes to NetworkPolicy sub-resources owned by ArgoCD instances.
+	bldr.Owns(&networkingv1.NetworkPolicy{})
+
+	// Watch for changes to the import Jobs owned by ArgoCD instances.
+	bldr.Owns(&batchv1.Job{})
+
+	// Watch for the ArgoCDExports imported by ArgoCD instances.
+	bldr.Watches(&argoprojv1alpha1.ArgoCDExport{}, handler.EnqueueRequestsFromMapFunc(argoCDExportMapper))
+
 	// Inspect cluster to verify availability of extra features
 	// This sets the flags that are used in subsequent checks
 	if err := InspectCluster(); err != nil {
@@ -1093,6 +1159,21 @@ func (r *ReconcileArgoCD) setResourceWatches(bldr *builder.Builder, clusterResou
 		bldr.Owns(&routev1.Route{})
 	}
 
+	if IsGatewayAPIAvailable() {
+		// Watch Gateway API HTTPRoute sub-resources owned by ArgoCD instances.
+		bldr.Owns(newGatewayObject(httpRouteGVK, "", ""))
+	}
+
+	if IsGRPCRouteAPIAvailable() {
+		// Watch Gateway API GRPCRoute sub-resources owned by ArgoCD instances.
+		bldr.Owns(newGatewayObject(grpcRouteGVK, "", ""))
+	}
+
+	if IsCertManagerAPIAvailable() {
+		// Watch cert-manager Certificate sub-resources owned by ArgoCD instances.
+		bldr.Owns(newCertificate("", ""))
+	}
+
 	if IsPrometheusAPIAvailable() {
 		// Watch Prometheus sub-resources owned by ArgoCD instances.
 		bldr.Owns(&monitoringv1.Prometheus{})
@@ -1109,11 +1190,104 @@ func (r *ReconcileArgoCD) setResourceWatches(bldr *builder.Builder, clusterResou
 
 	namespaceHandler := handler.EnqueueRequestsFromMapFunc(namespaceResourceMapper)
 
-	bldr.Watches(&corev1.Namespace{}, namespaceHandler, builder.WithPredicates(namespaceFilterPredicate()))
+	bldr.Watches(&corev1.Namespace{}, namespaceHandler, builder.WithPredicates(namespaceFilterPredicate(r.Instances)))
 
 	return bldr
 }
 
+// mergeDesiredMetadata updates the annotations and labels of the given existing object to match the ones of the given
+// desired object, and returns whether they changed. Annotations and labels added by others, e.g. ingress controllers,
+// cert-manager or external-dns, are kept, while the ones the operator set before and no longer desires are removed.
+func mergeDesiredMetadata(existing, desired metav1.Object) bool {
+	previous := existing.GetAnnotations()
+	changed := false
+	if annotations, ok := mergeManagedKeys(previous, managedMetadataAnnotations(desired),
+		managedMetadataKeys(previous, common.ArgoCDManagedAnnotationsAnnotation)); ok {
+		existing.SetAnnotations(annotations)
+		changed = true
+	}
+	if labels, ok := mergeManagedKeys(existing.GetLabels(), desired.GetLabels(),
+		managedMetadataKeys(previous, common.ArgoCDManagedLabelsAnnotation)); ok {
+		existing.SetLabels(labels)
+		changed = true
+	}
+	return changed
+}
+
+// setManagedMetadata records the keys of the annotations and labels of the given object, before it is created, as
+// the ones managed by the operator.
+func setManagedMetadata(obj metav1.Object) {
+	obj.SetAnnotations(managedMetadataAnnotations(obj))
+}
+
+// managedMetadataAnnotations returns the annotations of the given desired object, along with the annotations recording
+// the keys of its annotations and labels.
+func managedMetadataAnnotations(desired metav1.Object) map[string]string {
+	annotations := argoutil.AppendStringMap(nil, desired.GetAnnotations())
+	annotations[common.ArgoCDManagedAnnotationsAnnotation] = joinSortedKeys(desired.GetAnnotations())
+	annotations[common.ArgoCDManagedLabelsAnnotation] = joinSortedKeys(desired.GetLabels())
+	return annotations
+}
+
+// managedMetadataKeys returns the keys recorded in the given annotation of the given annotations, or nothing for an
+// object created before the keys were recorded.
+func managedMetadataKeys(annotations map[string]string, key string) []string {
+	if len(annotations[key]) == 0 {
+		return nil
+	}
+	return strings.Split(annotations[key], ",")
+}
+
+// mergeManagedKeys returns the given existing map with the desired keys added, and the given managed keys that are no
+// longer desired removed, and whether it changed. Other keys are kept.
+func mergeManagedKeys(existing, desired map[string]string, managed []string) (map[string]string, bool) {
+	changed := false
+	for key, val := range desired {
+		if current, ok := existing[key]; !ok || current != val {
+			changed = true
+			break
+		}
+	}
+	for _, key := range managed {
+		if _, ok := desired[key]; !ok {
+			if _, ok := existing[key]; ok {
+				changed = true
+			}
+		}
+	}
+	if !changed {
+		return existing, false
+	}
+
+	merged := argoutil.AppendStringMap(nil, existing)
+	for _, key := range managed {
+		if _, ok := desired[key]; !ok {
+			delete(merged, key)
+		}
+	}
+	return argoutil.AppendStringMap(merged, desired), true
+}
+
+// joinSortedKeys returns the sorted keys of the given map, separated by commas.
+func joinSortedKeys(m map[string]string) string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return strings.Join(keys, ",")
+}
+
+// createDriftEvent records an event on the given ArgoCD, stating that the given resource has been updated to match the
+// ArgoCD spec again. Failing to record the event is only logged.
+func (r *ReconcileArgoCD) createDriftEvent(cr *argoproj.ArgoCD, kind, name string) {
+	message := fmt.Sprintf("%s %s was updated to match the ArgoCD spec.", kind, name)
+	typeMeta := metav1.TypeMeta{Kind: "ArgoCD", APIVersion: argoproj.GroupVersion.String()}
+	if err := argoutil.CreateEvent(r.Client, corev1.EventTypeNormal, "Reconciling", message, kind+"Updated", cr.ObjectMeta, typeMeta); err != nil {
+		log.Error(err, "failed to create event", "kind", kind, "name", name)
+	}
+}
+
 // boolPtr returns a pointer to val
 func boolPtr(val bool) *bool {
 	return &val
@@ -1123,6 +1297,10 @@ func int64Ptr(val int64) *int64 {
 	return &val
 }
 
+func int32Ptr(val int32) *int32 {
+	return &val
+}
+
 // triggerRollout will trigger a rollout of a Kubernetes resource specified as
 // obj. It currently supports Deployment and StatefulSet resources.
 func (r *ReconcileArgoCD) triggerRollout(obj interface{}, key string) error {
@@ -1170,20 +1348,9 @@ func containsString(arr []string, s string) bool {
 	return false
 }
 
-// DeprecationEventEmissionStatus is meant to track which deprecation events have been emitted already. This is temporary and can be removed in v0.0.6 once we have provided enough
-// deprecation notice
-type DeprecationEventEmissionStatus struct {
-	SSOSpecDeprecationWarningEmitted    bool
-	DexSpecDeprecationWarningEmitted    bool
-	DisableDexDeprecationWarningEmitted bool
-}
-
-// DeprecationEventEmissionTracker map stores the namespace containing ArgoCD instance as key and DeprecationEventEmissionStatus as value,
-// where DeprecationEventEmissionStatus tracks the events that have been emitted for the instance in the particular namespace.
-// This is temporary and can be removed in v0.0.6 when we remove the deprecated fields.
-var DeprecationEventEmissionTracker = make(map[string]DeprecationEventEmissionStatus)
-
-func namespaceFilterPredicate() predicate.Predicate {
+// namespaceFilterPredicate filters the events of the namespaces, cleaning up the resources of the namespaces that are
+// no longer managed. The instances of a deleted namespace are removed from the given instance registry.
+func namespaceFilterPredicate(instances *InstanceRegistry) predicate.Predicate {
 	return predicate.Funcs{
 		UpdateFunc: func(e event.UpdateEvent) bool {
 			// This checks if ArgoCDManagedByLabel exists in newMeta, if exists then -
@@ -1251,9 +1418,9 @@ func namespaceFilterPredicate() predicate.Predicate {
 				}
 			}
 
-			// if a namespace is deleted, remove it from deprecationEventEmissionTracker (if exists) so that if a namespace with the same name
+			// if a namespace is deleted, remove its instances from the instance registry (if any) so that if a namespace with the same name
 			// is created in the future and contains an Argo CD instance, it will be tracked appropriately
-			delete(DeprecationEventEmissionTracker, e.Object.GetName())
+			instances.RemoveNamespace(e.Object.GetName())
 			return false
 		},
 	}
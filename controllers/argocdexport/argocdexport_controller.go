@@ -69,6 +69,10 @@ func (r *ReconcileArgoCDExport) Reconcile(ctx context.Context, request ctrl.Requ
 		return reconcile.Result{}, err
 	}
 
+	if deleted, err := r.reconcileExportFinalizer(export); deleted || err != nil {
+		return reconcile.Result{}, err
+	}
+
 	if err := r.reconcileArgoCDExportResources(export); err != nil {
 		// Error reconciling ArgoCDExport sub-resources - requeue the request.
 		return reconcile.Result{}, err
@@ -80,6 +84,6 @@ func (r *ReconcileArgoCDExport) Reconcile(ctx context.Context, request ctrl.Requ
 // SetupWithManager sets up the controller with the Manager.
 func (r *ReconcileArgoCDExport) SetupWithManager(mgr ctrl.Manager) error {
 	bld := ctrl.NewControllerManagedBy(mgr)
-	setResourceWatches(bld)
+	setResourceWatches(bld, r.keySecretMapper, r.argoCDMapper)
 	return bld.Complete(r)
 }
@@ -15,9 +15,17 @@
 package argocdexport
 
 import (
+	"bytes"
 	"context"
+	"errors"
+	"fmt"
+	"strings"
 
 	"github.com/sethvargo/go-password/password"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 
 	argoprojv1alpha1 "github.com/argoproj-labs/argocd-operator/api/v1alpha1"
@@ -40,18 +48,46 @@ func generateBackupKey() ([]byte, error) {
 // reconcileExport will ensure that the resources for the export process are present for the ArgoCDExport.
 func (r *ReconcileArgoCDExport) reconcileExport(cr *argoprojv1alpha1.ArgoCDExport) error {
 	log.Info("reconciling export secret")
-	if err := r.reconcileExportSecret(cr); err != nil {
+	keyID, err := r.reconcileExportSecret(cr)
+	if err != nil {
+		return err
+	}
+
+	instance, err := r.resolveArgoCD(cr)
+	if err != nil {
+		return err
+	}
+
+	if err := validateExportImage(cr, instance); err != nil {
+		return err
+	}
+
+	log.Info("reconciling export rbac")
+	if err := r.reconcileExportRBAC(cr, instance); err != nil {
 		return err
 	}
 
+	// The key ID is passed to the export process and only recorded once the export has been set up for it, so
+	// that the export triggered by a key change is retried after a failure.
+	previousKeyID := cr.Status.KeyID
+	cr.Status.KeyID = keyID
+
 	if cr.Spec.Schedule != nil && len(*cr.Spec.Schedule) > 0 {
 		log.Info("reconciling export cronjob")
-		if err := r.reconcileCronJob(cr); err != nil {
+		if err := r.reconcileCronJob(cr, instance); err != nil {
+			cr.Status.KeyID = previousKeyID
+			return err
+		}
+
+		log.Info("reconciling export key rotation job")
+		if err := r.reconcileKeyRotationJob(cr, previousKeyID); err != nil {
+			cr.Status.KeyID = previousKeyID
 			return err
 		}
 	} else {
 		log.Info("reconciling export job")
-		if err := r.reconcileJob(cr); err != nil {
+		if err := r.reconcileJob(cr, instance); err != nil {
+			cr.Status.KeyID = previousKeyID
 			return err
 		}
 	}
@@ -59,40 +95,221 @@ func (r *ReconcileArgoCDExport) reconcileExport(cr *argoprojv1alpha1.ArgoCDExpor
 	return nil
 }
 
-// reconcileExportSecret will ensure that the Secret used for the export process is present.
-func (r *ReconcileArgoCDExport) reconcileExportSecret(cr *argoprojv1alpha1.ArgoCDExport) error {
+// reconcileExportSecret will ensure that the Secret used for the export process is present and holds the current
+// backup key, and returns the ID of the key new backups are encrypted with.
+//
+// When the backup key changes, the former key is kept in the backup.key.previous key of the Secret until every restore
+// point has been re-encrypted with the current key by the export process.
+func (r *ReconcileArgoCDExport) reconcileExportSecret(cr *argoprojv1alpha1.ArgoCDExport) (string, error) {
 	name := argoutil.FetchStorageSecretName(cr)
 	// Dummy CR to retrieve secret
 	a := &argoproj.ArgoCD{}
 	a.ObjectMeta = cr.ObjectMeta
 	secret := argoutil.NewSecretWithName(a, name)
-	if argoutil.IsObjectFound(r.Client, cr.Namespace, name, secret) {
-		backupKey := secret.Data[common.ArgoCDKeyBackupKey]
-		if len(backupKey) <= 0 {
-			backupKey, err := generateBackupKey()
-			if err != nil {
-				return err
+	found := argoutil.IsObjectFound(r.Client, cr.Namespace, name, secret)
+
+	backupKey, err := r.backupKey(cr, secret.Data[common.ArgoCDKeyBackupKey])
+	if err != nil {
+		return "", err
+	}
+	keyID := argoutil.BackupKeyID(backupKey)
+	if cr.Spec.Encryption != nil && cr.Spec.Encryption.KMS != nil {
+		keyID = common.ArgoCDExportKMSKeyIDPrefix + cr.Spec.Encryption.KMS.KeyID
+	}
+
+	if !found {
+		secret.Data = map[string][]byte{
+			common.ArgoCDKeyBackupKey: backupKey,
+		}
+
+		if err := controllerutil.SetControllerReference(cr, secret, r.Scheme); err != nil {
+			return "", err
+		}
+		return keyID, r.Client.Create(context.TODO(), secret)
+	}
+
+	if secret.Data == nil {
+		secret.Data = map[string][]byte{}
+	}
+	changed := false
+	previous, rotating := secret.Data[common.ArgoCDKeyBackupKeyPrevious]
+
+	if current := secret.Data[common.ArgoCDKeyBackupKey]; !bytes.Equal(current, backupKey) {
+		if len(current) > 0 {
+			previous = appendPreviousBackupKey(previous, current, backupKey)
+			rotating = true
+			message := fmt.Sprintf("Backup key rotated to %s, the retained backups are re-encrypted by the next export.", keyID)
+			if err := argoutil.CreateEvent(r.Client, "Normal", "Exporting", message, "BackupKeyRotated", cr.ObjectMeta, cr.TypeMeta); err != nil {
+				log.Error(err, "failed to create event")
 			}
-			secret.Data[common.ArgoCDKeyBackupKey] = backupKey
-			return r.Client.Update(context.TODO(), secret)
 		}
+		secret.Data[common.ArgoCDKeyBackupKey] = backupKey
+		changed = true
+	}
 
-		return nil // TODO: Handle case where backup key changes, should trigger a new export?
+	// A change of KMS key does not change the backup key, it is detected from the key ID of the last reconciliation.
+	if len(cr.Status.KeyID) > 0 && cr.Status.KeyID != keyID {
+		rotating = true
+	}
+	if rotating && restorePointsEncryptedWith(cr, keyID) {
+		log.Info("every restore point is encrypted with the current backup key, dropping the previous keys")
+		rotating = false
 	}
 
-	backupKey, err := generateBackupKey()
-	if err != nil {
-		return err
+	existing, exists := secret.Data[common.ArgoCDKeyBackupKeyPrevious]
+	if rotating && (!exists || !bytes.Equal(existing, previous)) {
+		if previous == nil {
+			previous = []byte{}
+		}
+		secret.Data[common.ArgoCDKeyBackupKeyPrevious] = previous
+		changed = true
+	} else if !rotating && exists {
+		delete(secret.Data, common.ArgoCDKeyBackupKeyPrevious)
+		changed = true
 	}
 
-	secret.Data = map[string][]byte{
-		common.ArgoCDKeyBackupKey: backupKey,
+	if changed {
+		return keyID, r.Client.Update(context.TODO(), secret)
 	}
+	return keyID, nil
+}
 
-	if err := controllerutil.SetControllerReference(cr, secret, r.Scheme); err != nil {
-		return err
+// backupKey returns the backup key for the given ArgoCDExport and records the outcome in its EncryptionKeyResolved
+// condition. The key is read from the Secret referenced by the encryption options, else the existing key of the
+// export Secret is kept, or a new one is generated. The export Secret keeps a backup key when a KMS is used, so that
+// the backups written before remain readable.
+func (r *ReconcileArgoCDExport) backupKey(cr *argoprojv1alpha1.ArgoCDExport, existing []byte) ([]byte, error) {
+	var (
+		key     []byte
+		reason  string
+		message string
+	)
+
+	if cr.Spec.Encryption != nil && cr.Spec.Encryption.KeySecretRef != nil {
+		ref := cr.Spec.Encryption.KeySecretRef
+		secret := &corev1.Secret{}
+		if err := argoutil.FetchObject(r.Client, cr.Namespace, ref.Name, secret); err != nil {
+			if !apierrors.IsNotFound(err) {
+				return nil, err
+			}
+			reason = argoprojv1alpha1.ArgoCDExportReasonKeyNotFound
+			message = fmt.Sprintf("backup key secret %s not found in namespace %s", ref.Name, cr.Namespace)
+		} else if key = secret.Data[ref.Key]; len(key) == 0 {
+			reason = argoprojv1alpha1.ArgoCDExportReasonKeyNotFound
+			message = fmt.Sprintf("backup key secret %s has no key %s", ref.Name, ref.Key)
+		} else if len(bytes.TrimSpace(firstLine(key))) < common.ArgoCDExportMinBackupKeyLength {
+			reason = argoprojv1alpha1.ArgoCDExportReasonKeyInvalid
+			message = fmt.Sprintf("backup key %s of secret %s must be at least %d characters long", ref.Key, ref.Name, common.ArgoCDExportMinBackupKeyLength)
+		} else {
+			message = fmt.Sprintf("using the backup key %s of secret %s", ref.Key, ref.Name)
+		}
+	} else {
+		key = existing
+		if len(key) == 0 {
+			var err error
+			if key, err = generateBackupKey(); err != nil {
+				return nil, err
+			}
+		}
+		message = "using the backup key generated in the export secret"
+		if cr.Spec.Encryption != nil && cr.Spec.Encryption.KMS != nil {
+			message = fmt.Sprintf("using the kms key %s", cr.Spec.Encryption.KMS.KeyID)
+		}
+	}
+
+	if len(reason) > 0 {
+		meta.SetStatusCondition(&cr.Status.Conditions, metav1.Condition{
+			Type:               argoprojv1alpha1.ArgoCDExportConditionEncryptionKeyResolved,
+			Status:             metav1.ConditionFalse,
+			Reason:             reason,
+			Message:            message,
+			ObservedGeneration: cr.Generation,
+		})
+		return nil, errors.New(message)
+	}
+
+	meta.SetStatusCondition(&cr.Status.Conditions, metav1.Condition{
+		Type:               argoprojv1alpha1.ArgoCDExportConditionEncryptionKeyResolved,
+		Status:             metav1.ConditionTrue,
+		Reason:             argoprojv1alpha1.ArgoCDExportReasonKeyFound,
+		Message:            message,
+		ObservedGeneration: cr.Generation,
+	})
+	return key, nil
+}
+
+// appendPreviousBackupKey returns the given newline separated previous backup keys with the replaced key added first.
+// The current key is left out, and only the most recent keys are kept.
+func appendPreviousBackupKey(previous, replaced, current []byte) []byte {
+	keys := [][]byte{firstLine(replaced)}
+	for _, key := range bytes.Split(previous, []byte("\n")) {
+		if len(key) > 0 && !bytes.Equal(key, keys[0]) {
+			keys = append(keys, key)
+		}
 	}
-	return r.Client.Create(context.TODO(), secret)
+
+	kept := make([][]byte, 0, len(keys))
+	for _, key := range keys {
+		if !bytes.Equal(key, firstLine(current)) && len(kept) < common.ArgoCDDefaultExportMaxPreviousBackupKeys {
+			kept = append(kept, key)
+		}
+	}
+	return bytes.Join(kept, []byte("\n"))
+}
+
+// firstLine returns the first line of the given backup key, the export process ignores the rest.
+func firstLine(key []byte) []byte {
+	if i := bytes.IndexAny(key, "\r\n"); i >= 0 {
+		return key[:i]
+	}
+	return key
+}
+
+// restorePointsEncryptedWith returns true if the last export reported restore points, and all of them are encrypted
+// with the given key.
+func restorePointsEncryptedWith(cr *argoprojv1alpha1.ArgoCDExport, keyID string) bool {
+	if len(cr.Status.RestorePoints) == 0 {
+		return false
+	}
+	for _, rp := range cr.Status.RestorePoints {
+		if rp.KeyID != keyID {
+			return false
+		}
+	}
+	return true
+}
+
+// validateExportImage fails the export of the given Argo CD instance when its image is the legacy one, and the
+// ArgoCDExport uses options that the legacy image would silently ignore. The export is not run, and the Failed
+// condition records the options to drop or the image to use instead.
+func validateExportImage(cr *argoprojv1alpha1.ArgoCDExport, instance *argoprojv1alpha1.ArgoCD) error {
+	for _, t := range []string{argoprojv1alpha1.ArgoCDExportConditionComplete, argoprojv1alpha1.ArgoCDExportConditionFailed} {
+		if c := meta.FindStatusCondition(cr.Status.Conditions, t); c != nil && c.Reason == argoprojv1alpha1.ArgoCDExportReasonUnsupportedByImage {
+			meta.RemoveStatusCondition(&cr.Status.Conditions, t)
+		}
+	}
+
+	image := getArgoExportContainerImage(cr, instance)
+	if !argoutil.IsLegacyExportImage(image) {
+		return nil
+	}
+
+	unsupported := argoutil.LegacyExportUnsupportedOptions(cr)
+	if cr.Spec.Retention != nil {
+		unsupported = append(unsupported, "spec.retention")
+	}
+	// util.sh exports the namespace its Pod runs in.
+	if instance.Namespace != cr.Namespace {
+		unsupported = append(unsupported, fmt.Sprintf("exporting Argo CD instance %s from namespace %s", instance.Name, instance.Namespace))
+	}
+	if len(unsupported) == 0 {
+		return nil
+	}
+
+	message := fmt.Sprintf("export image %s does not support %s, set spec.image and spec.version to an image built from build/util/Dockerfile",
+		image, strings.Join(unsupported, ", "))
+	setExportRunConditions(cr, argoprojv1alpha1.ArgoCDExportConditionFailed, argoprojv1alpha1.ArgoCDExportReasonUnsupportedByImage, message)
+	return errors.New(message)
 }
 
 // validateExport will ensure that the given ArgoCDExport is valid.
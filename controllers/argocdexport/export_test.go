@@ -0,0 +1,249 @@
+package argocdexport
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	argoproj "github.com/argoproj-labs/argocd-operator/api/v1alpha1"
+	"github.com/argoproj-labs/argocd-operator/common"
+	"github.com/argoproj-labs/argocd-operator/controllers/argoutil"
+)
+
+func withKeySecretRef(name, key string) func(*argoproj.ArgoCDExport) {
+	return func(cr *argoproj.ArgoCDExport) {
+		cr.Spec.Encryption = &argoproj.ArgoCDExportEncryptionSpec{
+			KeySecretRef: &corev1.SecretKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: name},
+				Key:                  key,
+			},
+		}
+	}
+}
+
+func makeTestKeySecret(key string) *corev1.Secret {
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "backup-key",
+			Namespace: "argocd",
+		},
+		Data: map[string][]byte{
+			"key": []byte(key),
+		},
+	}
+}
+
+func exportContainerEnv(t *testing.T, r *ReconcileArgoCDExport, cr *argoproj.ArgoCDExport) []corev1.EnvVar {
+	job := &batchv1.Job{}
+	assert.NoError(t, r.Client.Get(context.TODO(), types.NamespacedName{Name: cr.Name, Namespace: cr.Namespace}, job))
+	return job.Spec.Template.Spec.Containers[0].Env
+}
+
+func TestReconcileArgoCDExport_reconcileExportSecret_Generated(t *testing.T) {
+	cr := makeTestArgoCDExport()
+	r := makeTestReconciler(t, cr)
+
+	keyID, err := r.reconcileExportSecret(cr)
+	assert.NoError(t, err)
+
+	secret := &corev1.Secret{}
+	assert.NoError(t, argoutil.FetchObject(r.Client, cr.Namespace, argoutil.FetchStorageSecretName(cr), secret))
+	assert.Len(t, secret.Data[common.ArgoCDKeyBackupKey], common.ArgoCDDefaultBackupKeyLength)
+	assert.Equal(t, argoutil.BackupKeyID(secret.Data[common.ArgoCDKeyBackupKey]), keyID)
+	assert.True(t, meta.IsStatusConditionTrue(cr.Status.Conditions, argoproj.ArgoCDExportConditionEncryptionKeyResolved))
+
+	// the generated key is kept
+	again, err := r.reconcileExportSecret(cr)
+	assert.NoError(t, err)
+	assert.Equal(t, keyID, again)
+}
+
+func TestReconcileArgoCDExport_reconcileExportSecret_KeySecretRef(t *testing.T) {
+	tests := []struct {
+		name       string
+		objs       []client.Object
+		wantReason string
+	}{
+		{
+			name:       "key found",
+			objs:       []client.Object{makeTestKeySecret("0123456789abcdef")},
+			wantReason: argoproj.ArgoCDExportReasonKeyFound,
+		},
+		{
+			name:       "secret not found",
+			wantReason: argoproj.ArgoCDExportReasonKeyNotFound,
+		},
+		{
+			name: "key not found",
+			objs: []client.Object{&corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "backup-key", Namespace: "argocd"},
+			}},
+			wantReason: argoproj.ArgoCDExportReasonKeyNotFound,
+		},
+		{
+			name:       "key too short",
+			objs:       []client.Object{makeTestKeySecret("short\n0123456789abcdef")},
+			wantReason: argoproj.ArgoCDExportReasonKeyInvalid,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cr := makeTestArgoCDExport(withKeySecretRef("backup-key", "key"))
+			r := makeTestReconciler(t, append(test.objs, cr)...)
+
+			keyID, err := r.reconcileExportSecret(cr)
+			condition := meta.FindStatusCondition(cr.Status.Conditions, argoproj.ArgoCDExportConditionEncryptionKeyResolved)
+			assert.NotNil(t, condition)
+			assert.Equal(t, test.wantReason, condition.Reason)
+
+			if test.wantReason != argoproj.ArgoCDExportReasonKeyFound {
+				assert.Error(t, err)
+				assert.Equal(t, metav1.ConditionFalse, condition.Status)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, metav1.ConditionTrue, condition.Status)
+			assert.Equal(t, argoutil.BackupKeyID([]byte("0123456789abcdef")), keyID)
+
+			secret := &corev1.Secret{}
+			assert.NoError(t, argoutil.FetchObject(r.Client, cr.Namespace, argoutil.FetchStorageSecretName(cr), secret))
+			assert.Equal(t, "0123456789abcdef", string(secret.Data[common.ArgoCDKeyBackupKey]))
+		})
+	}
+}
+
+func TestReconcileArgoCDExport_reconcileExport_KeyRotation(t *testing.T) {
+	keySecret := makeTestKeySecret("first-backup-key-0123")
+	cr := makeTestArgoCDExport(withKeySecretRef("backup-key", "key"))
+	r := makeTestReconciler(t, cr, keySecret, makeTestArgoCD("argocd"))
+
+	assert.NoError(t, r.reconcileExport(cr))
+	firstKeyID := argoutil.BackupKeyID([]byte("first-backup-key-0123"))
+	assert.Equal(t, firstKeyID, cr.Status.KeyID)
+	assert.Contains(t, exportContainerEnv(t, r, cr), corev1.EnvVar{Name: common.ArgoCDExportKeyIDEnvName, Value: firstKeyID})
+
+	cr.Status.RestorePoints = []argoproj.ArgoCDExportRestorePoint{{BackupID: "20230102T030405Z", KeyID: firstKeyID}}
+
+	// rotating the key keeps the former one, and replaces the export Job
+	keySecret.Data["key"] = []byte("second-backup-key-0123")
+	assert.NoError(t, r.Client.Update(context.TODO(), keySecret))
+	assert.NoError(t, r.reconcileExport(cr))
+	secondKeyID := argoutil.BackupKeyID([]byte("second-backup-key-0123"))
+	assert.Equal(t, secondKeyID, cr.Status.KeyID)
+
+	secret := &corev1.Secret{}
+	assert.NoError(t, argoutil.FetchObject(r.Client, cr.Namespace, argoutil.FetchStorageSecretName(cr), secret))
+	assert.Equal(t, "second-backup-key-0123", string(secret.Data[common.ArgoCDKeyBackupKey]))
+	assert.Equal(t, "first-backup-key-0123", string(secret.Data[common.ArgoCDKeyBackupKeyPrevious]))
+
+	assert.NoError(t, r.reconcileExport(cr))
+	assert.Contains(t, exportContainerEnv(t, r, cr), corev1.EnvVar{Name: common.ArgoCDExportKeyIDEnvName, Value: secondKeyID})
+
+	// the former key is kept until every restore point is re-encrypted
+	assert.NoError(t, r.reconcileExport(cr))
+	assert.NoError(t, argoutil.FetchObject(r.Client, cr.Namespace, argoutil.FetchStorageSecretName(cr), secret))
+	assert.Contains(t, secret.Data, common.ArgoCDKeyBackupKeyPrevious)
+
+	cr.Status.RestorePoints[0].KeyID = secondKeyID
+	assert.NoError(t, r.reconcileExport(cr))
+	assert.NoError(t, argoutil.FetchObject(r.Client, cr.Namespace, argoutil.FetchStorageSecretName(cr), secret))
+	assert.NotContains(t, secret.Data, common.ArgoCDKeyBackupKeyPrevious)
+}
+
+func TestReconcileArgoCDExport_reconcileKeyRotationJob(t *testing.T) {
+	keySecret := makeTestKeySecret("first-backup-key-0123")
+	cr := makeTestArgoCDExport(withKeySecretRef("backup-key", "key"), withSchedule("0 * * * *"))
+	r := makeTestReconciler(t, cr, keySecret, makeTestArgoCD("argocd"))
+
+	// no rotation job for the first key
+	assert.NoError(t, r.reconcileExport(cr))
+	jobs := &batchv1.JobList{}
+	assert.NoError(t, r.Client.List(context.TODO(), jobs))
+	assert.Empty(t, jobs.Items)
+
+	cr.Status.RestorePoints = []argoproj.ArgoCDExportRestorePoint{{BackupID: "20230102T030405Z", KeyID: cr.Status.KeyID}}
+	keySecret.Data["key"] = []byte("second-backup-key-0123")
+	assert.NoError(t, r.Client.Update(context.TODO(), keySecret))
+	assert.NoError(t, r.reconcileExport(cr))
+
+	job := &batchv1.Job{}
+	name := keyRotationJobName(cr.Name, cr.Status.KeyID)
+	assert.NoError(t, r.Client.Get(context.TODO(), types.NamespacedName{Name: name, Namespace: cr.Namespace}, job))
+	assert.True(t, metav1.IsControlledBy(job, cr))
+	assert.Contains(t, job.Spec.Template.Spec.Containers[0].Env, corev1.EnvVar{Name: common.ArgoCDExportKeyIDEnvName, Value: cr.Status.KeyID})
+
+	// the rotation job is the last export run
+	last, err := r.lastExportJob(cr)
+	assert.NoError(t, err)
+	assert.Equal(t, name, last.Name)
+
+	// it is created once per key
+	assert.NoError(t, r.reconcileExport(cr))
+	assert.NoError(t, r.Client.List(context.TODO(), jobs))
+	assert.Len(t, jobs.Items, 1)
+}
+
+func TestReconcileArgoCDExport_keySecretMapper(t *testing.T) {
+	cr := makeTestArgoCDExport(withKeySecretRef("backup-key", "key"))
+	other := makeTestArgoCDExport()
+	other.Name = "other-export"
+	r := makeTestReconciler(t, cr, other)
+
+	requests := r.keySecretMapper(context.TODO(), makeTestKeySecret("0123456789abcdef"))
+	assert.Equal(t, []reconcile.Request{{NamespacedName: types.NamespacedName{Name: cr.Name, Namespace: cr.Namespace}}}, requests)
+
+	unrelated := makeTestKeySecret("0123456789abcdef")
+	unrelated.Name = "unrelated"
+	assert.Empty(t, r.keySecretMapper(context.TODO(), unrelated))
+}
+
+func TestReconcileArgoCDExport_reconcileExport_LegacyImage(t *testing.T) {
+	instance := makeTestArgoCD("argocd")
+	instance.Namespace = "argocd-instance"
+	cr := makeTestArgoCDExport(withArgoCDNamespace(instance.Namespace), func(cr *argoproj.ArgoCDExport) {
+		keepLast := int32(2)
+		cr.Spec.Retention = &argoproj.ArgoCDExportRetentionSpec{KeepLast: &keepLast}
+	})
+	r := makeTestReconciler(t, cr, instance)
+
+	// the legacy default image would ignore the retention and export the namespace of the export
+	existing := cr.Status.DeepCopy()
+	err := r.reconcileExport(cr)
+	assert.ErrorContains(t, err, "spec.retention, exporting Argo CD instance argocd from namespace argocd-instance")
+	assert.True(t, apierrors.IsNotFound(r.Client.Get(context.TODO(), types.NamespacedName{Name: cr.Name, Namespace: cr.Namespace}, &batchv1.Job{})))
+	c := meta.FindStatusCondition(cr.Status.Conditions, argoproj.ArgoCDExportConditionFailed)
+	assert.NotNil(t, c)
+	assert.Equal(t, metav1.ConditionTrue, c.Status)
+	assert.Equal(t, argoproj.ArgoCDExportReasonUnsupportedByImage, c.Reason)
+	assert.NoError(t, r.reconcileExportStatus(cr, existing))
+	assert.Equal(t, common.ArgoCDStatusFailed, cr.Status.Phase)
+
+	// an image built from build/util/Dockerfile supports them
+	cr.Spec.Version = "v0.6.0"
+	existing = cr.Status.DeepCopy()
+	assert.NoError(t, r.reconcileExport(cr))
+	job := &batchv1.Job{}
+	assert.NoError(t, r.Client.Get(context.TODO(), types.NamespacedName{Name: cr.Name, Namespace: cr.Namespace}, job))
+	assert.Equal(t, []string{"argocd-operator-util", "export", "local"}, job.Spec.Template.Spec.Containers[0].Command)
+	assert.Nil(t, meta.FindStatusCondition(cr.Status.Conditions, argoproj.ArgoCDExportConditionFailed))
+	assert.NoError(t, r.reconcileExportStatus(cr, existing))
+	assert.Equal(t, common.ArgoCDStatusRunning, cr.Status.Phase)
+}
+
+func TestAppendPreviousBackupKey(t *testing.T) {
+	assert.Equal(t, "old", string(appendPreviousBackupKey(nil, []byte("old\n"), []byte("new"))))
+	assert.Equal(t, "old\nolder", string(appendPreviousBackupKey([]byte("older\nold\nnew"), []byte("old"), []byte("new"))))
+
+	previous := []byte("k1\nk2\nk3\nk4\nk5")
+	assert.Equal(t, "k0\nk1\nk2\nk3\nk4", string(appendPreviousBackupKey(previous, []byte("k0"), []byte("new"))))
+}
@@ -16,13 +16,21 @@ package argocdexport
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"reflect"
+	"strconv"
 	"strings"
 
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 
@@ -32,20 +40,32 @@ import (
 	"github.com/argoproj-labs/argocd-operator/controllers/argoutil"
 )
 
-// getArgoExportCommand will return the command for the ArgoCD export process.
-func getArgoExportCommand(cr *argoproj.ArgoCDExport) []string {
+// exportContainerName is the name of the container performing the export process.
+const exportContainerName = "argocd-export"
+
+// getArgoExportCommand will return the command for the ArgoCD export process, performed by the
+// argocd-operator-util binary built from cmd/argocd-operator-util, or by util.sh in the given legacy image.
+func getArgoExportCommand(cr *argoproj.ArgoCDExport, image string) []string {
 	cmd := make([]string, 0)
-	cmd = append(cmd, "uid_entrypoint.sh")
+	if argoutil.IsLegacyExportImage(image) {
+		cmd = append(cmd, "uid_entrypoint.sh")
+	}
 	cmd = append(cmd, "argocd-operator-util")
 	cmd = append(cmd, "export")
 	cmd = append(cmd, cr.Spec.Storage.Backend)
 	return cmd
 }
 
-func getArgoExportContainerEnv(cr *argoproj.ArgoCDExport) []corev1.EnvVar {
+func getArgoExportContainerEnv(cr *argoproj.ArgoCDExport, instance *argoproj.ArgoCD) []corev1.EnvVar {
 	env := make([]corev1.EnvVar, 0)
 
-	switch cr.Spec.Storage.Backend {
+	// The export process runs in the namespace of the ArgoCDExport, which may differ from the one of the instance.
+	env = append(env, corev1.EnvVar{
+		Name:  common.ArgoCDExportNamespaceEnvName,
+		Value: instance.Namespace,
+	})
+
+	switch strings.ToLower(cr.Spec.Storage.Backend) {
 	case common.ArgoCDExportStorageBackendAWS:
 		env = append(env, corev1.EnvVar{
 			Name: "AWS_ACCESS_KEY_ID",
@@ -70,13 +90,39 @@ func getArgoExportContainerEnv(cr *argoproj.ArgoCDExport) []corev1.EnvVar {
 				},
 			},
 		})
+	case common.ArgoCDExportStorageBackendS3:
+		env = append(env, argoutil.GetS3StorageEnv(cr)...)
 	}
 
+	if cr.Spec.Retention != nil {
+		if cr.Spec.Retention.KeepLast != nil {
+			env = append(env, corev1.EnvVar{
+				Name:  common.ArgoCDExportRetentionKeepLastEnvName,
+				Value: strconv.Itoa(int(*cr.Spec.Retention.KeepLast)),
+			})
+		}
+		if cr.Spec.Retention.MaxAge != nil {
+			env = append(env, corev1.EnvVar{
+				Name:  common.ArgoCDExportRetentionMaxAgeEnvName,
+				Value: cr.Spec.Retention.MaxAge.Duration.String(),
+			})
+		}
+	}
+
+	// The export process fails rather than writing a backup with another key than the one recorded in the status.
+	if len(cr.Status.KeyID) > 0 {
+		env = append(env, corev1.EnvVar{
+			Name:  common.ArgoCDExportKeyIDEnvName,
+			Value: cr.Status.KeyID,
+		})
+	}
+	env = append(env, argoutil.GetEncryptionEnv(cr)...)
 	return env
 }
 
-// getArgoExportContainerImage will return the container image for ArgoCD.
-func getArgoExportContainerImage(cr *argoproj.ArgoCDExport) string {
+// getArgoExportContainerImage will return the container image for ArgoCD, pulled from the image registry of the given
+// Argo CD instance when set.
+func getArgoExportContainerImage(cr *argoproj.ArgoCDExport, instance *argoproj.ArgoCD) string {
 	img := cr.Spec.Image
 	if len(img) <= 0 {
 		img = common.ArgoCDDefaultExportJobImage
@@ -87,11 +133,32 @@ func getArgoExportContainerImage(cr *argoproj.ArgoCDExport) string {
 		tag = common.ArgoCDDefaultExportJobVersion
 	}
 
-	return argoutil.CombineImageTag(img, tag)
+	return argoutil.ReplaceImageRegistry(argoutil.CombineImageTag(img, tag), instance.Spec.ImageRegistry)
+}
+
+// getArgoExportImagePullPolicy will return the pull policy of the export container, which is the one of the given Argo
+// CD instance when set.
+func getArgoExportImagePullPolicy(instance *argoproj.ArgoCD) corev1.PullPolicy {
+	if instance.Spec.ImagePullPolicy != "" {
+		return instance.Spec.ImagePullPolicy
+	}
+	return corev1.PullAlways
+}
+
+// getArgoExportResources will return the ResourceRequirements for the export container.
+func getArgoExportResources(cr *argoproj.ArgoCDExport) corev1.ResourceRequirements {
+	resources := corev1.ResourceRequirements{}
+
+	// Allow override of resource requirements from CR
+	if cr.Spec.Resources != nil {
+		resources = *cr.Spec.Resources
+	}
+
+	return resources
 }
 
 // getArgoExportVolumeMounts will return the VolumneMounts for the given ArgoCDExport.
-func getArgoExportVolumeMounts() []corev1.VolumeMount {
+func getArgoExportVolumeMounts(cr *argoproj.ArgoCDExport) []corev1.VolumeMount {
 	mounts := make([]corev1.VolumeMount, 0)
 
 	mounts = append(mounts, corev1.VolumeMount{
@@ -104,6 +171,8 @@ func getArgoExportVolumeMounts() []corev1.VolumeMount {
 		MountPath: "/secrets",
 	})
 
+	mounts = append(mounts, argoutil.GetS3StorageVolumeMounts(cr)...)
+
 	return mounts
 }
 
@@ -116,6 +185,8 @@ func getArgoSecretVolume(name string, cr *argoproj.ArgoCDExport) corev1.Volume {
 	volume.VolumeSource = corev1.VolumeSource{
 		Secret: &corev1.SecretVolumeSource{
 			SecretName: argoutil.FetchStorageSecretName(cr),
+			// Set explicitly, so that the Volume matches the one returned by the API server.
+			DefaultMode: int32Ptr(corev1.SecretVolumeSourceDefaultMode),
 		},
 	}
 
@@ -165,19 +236,18 @@ func newCronJob(cr *argoproj.ArgoCDExport) *batchv1.CronJob {
 	}
 }
 
-func newExportPodSpec(cr *argoproj.ArgoCDExport, argocdName string, client client.Client) corev1.PodSpec {
+// newExportPodSpec returns the desired PodSpec of the export process of the given ArgoCDExport, exporting the given
+// Argo CD instance.
+func newExportPodSpec(cr *argoproj.ArgoCDExport, instance *argoproj.ArgoCD, client client.Client) corev1.PodSpec {
 	pod := corev1.PodSpec{}
 
-	boolPtr := func(value bool) *bool {
-		return &value
-	}
-
+	image := getArgoExportContainerImage(cr, instance)
 	pod.Containers = []corev1.Container{{
-		Command:         getArgoExportCommand(cr),
-		Env:             getArgoExportContainerEnv(cr),
-		Image:           getArgoExportContainerImage(cr),
-		ImagePullPolicy: corev1.PullAlways,
-		Name:            "argocd-export",
+		Command:         getArgoExportCommand(cr, image),
+		Env:             getArgoExportContainerEnv(cr, instance),
+		Image:           image,
+		ImagePullPolicy: getArgoExportImagePullPolicy(instance),
+		Name:            exportContainerName,
 		SecurityContext: &corev1.SecurityContext{
 			AllowPrivilegeEscalation: boolPtr(false),
 			Capabilities: &corev1.Capabilities{
@@ -187,15 +257,28 @@ func newExportPodSpec(cr *argoproj.ArgoCDExport, argocdName string, client clien
 			},
 			RunAsNonRoot: boolPtr(true),
 		},
-		VolumeMounts: getArgoExportVolumeMounts(),
+		Resources:    getArgoExportResources(cr),
+		VolumeMounts: getArgoExportVolumeMounts(cr),
 	}}
 
+	if cr.Spec.NodePlacement != nil {
+		placement := cr.Spec.NodePlacement.DeepCopy()
+		pod.NodeSelector = placement.NodeSelector
+		pod.Tolerations = placement.Tolerations
+		pod.Affinity = placement.Affinity
+		pod.TopologySpreadConstraints = placement.TopologySpreadConstraints
+		pod.PriorityClassName = placement.PriorityClassName
+		pod.RuntimeClassName = placement.RuntimeClassName
+	}
+
+	pod.ImagePullSecrets = append([]corev1.LocalObjectReference(nil), instance.Spec.ImagePullSecrets...)
 	pod.RestartPolicy = corev1.RestartPolicyOnFailure
-	pod.ServiceAccountName = fmt.Sprintf("%s-%s", argocdName, "argocd-application-controller")
+	pod.ServiceAccountName = getExportServiceAccountName(cr)
 	pod.Volumes = []corev1.Volume{
 		getArgoStorageVolume("backup-storage", cr),
 		getArgoSecretVolume("secret-storage", cr),
 	}
+	pod.Volumes = append(pod.Volumes, argoutil.GetS3StorageVolumes(cr)...)
 
 	// Configure runAsUser, runAsGroup and fsGroup so that the job can write to the PV
 	// 999 is the uid/gid of the argocd user that the container runs as
@@ -210,91 +293,368 @@ func newExportPodSpec(cr *argoproj.ArgoCDExport, argocdName string, client clien
 	return pod
 }
 
-func newPodTemplateSpec(cr *argoproj.ArgoCDExport, argocdName string, client client.Client) corev1.PodTemplateSpec {
+func newPodTemplateSpec(cr *argoproj.ArgoCDExport, instance *argoproj.ArgoCD, client client.Client) corev1.PodTemplateSpec {
 	return corev1.PodTemplateSpec{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      cr.Name,
 			Namespace: cr.Namespace,
 			Labels:    common.DefaultLabels(cr.Name),
 		},
-		Spec: newExportPodSpec(cr, argocdName, client),
+		Spec: newExportPodSpec(cr, instance, client),
 	}
 }
 
-// reconcileCronJob will ensure that the CronJob for the ArgoCDExport is present.
-func (r *ReconcileArgoCDExport) reconcileCronJob(cr *argoproj.ArgoCDExport) error {
+// reconcileCronJob will ensure that the CronJob for the ArgoCDExport is present and matches the desired state. A
+// one-shot Job left over from before the Schedule was set is removed.
+func (r *ReconcileArgoCDExport) reconcileCronJob(cr *argoproj.ArgoCDExport, instance *argoproj.ArgoCD) error {
 	if cr.Spec.Storage == nil {
 		return nil // Do nothing if storage options not set
 	}
 
+	if err := r.deleteExportJob(cr); err != nil {
+		return err
+	}
+
+	desired := newExportCronJobSpec(cr, instance, r.Client)
+
 	cj := newCronJob(cr)
 	if argoutil.IsObjectFound(r.Client, cr.Namespace, cj.Name, cj) {
-		if *cr.Spec.Schedule != cj.Spec.Schedule {
-			cj.Spec.Schedule = *cr.Spec.Schedule
+		changed := false
+		if cj.Spec.Schedule != desired.Schedule {
+			cj.Spec.Schedule = desired.Schedule
+			changed = true
+		}
+		if cj.Spec.ConcurrencyPolicy != desired.ConcurrencyPolicy {
+			cj.Spec.ConcurrencyPolicy = desired.ConcurrencyPolicy
+			changed = true
+		}
+		if !reflect.DeepEqual(cj.Spec.Suspend, desired.Suspend) {
+			cj.Spec.Suspend = desired.Suspend
+			changed = true
+		}
+		if !reflect.DeepEqual(cj.Spec.SuccessfulJobsHistoryLimit, desired.SuccessfulJobsHistoryLimit) {
+			cj.Spec.SuccessfulJobsHistoryLimit = desired.SuccessfulJobsHistoryLimit
+			changed = true
+		}
+		if !reflect.DeepEqual(cj.Spec.FailedJobsHistoryLimit, desired.FailedJobsHistoryLimit) {
+			cj.Spec.FailedJobsHistoryLimit = desired.FailedJobsHistoryLimit
+			changed = true
+		}
+		if !reflect.DeepEqual(cj.Spec.JobTemplate.Labels, desired.JobTemplate.Labels) {
+			cj.Spec.JobTemplate.Labels = desired.JobTemplate.Labels
+			changed = true
+		}
+		if updateExportPodSpec(&cj.Spec.JobTemplate.Spec.Template.Spec, &desired.JobTemplate.Spec.Template.Spec) {
+			changed = true
+		}
+		if changed {
+			log.Info("updating export cronjob", "name", cj.Name, "namespace", cj.Namespace)
 			return r.Client.Update(context.TODO(), cj)
 		}
 		return nil
 	}
 
-	cj.Spec.Schedule = *cr.Spec.Schedule
-
-	// To create the job, we need the name of the argocd instance.  Although the argocd export cr contains a field with
-	// the argocd instance name, it's never used anywhere, and so there may be existing argocd export resources with the
-	// wrong name. To avoid these breaking, we look up the name of the argocd instance in the namespace of the export cr.
-	argocdName, err := r.argocdName(cr.Namespace)
-	if err != nil {
-		return err
-	}
-	job := newJob(cr)
-	job.Spec.Template = newPodTemplateSpec(cr, argocdName, r.Client)
-
-	cj.Spec.JobTemplate.Spec = job.Spec
-
+	cj.Spec = desired
 	if err := controllerutil.SetControllerReference(cr, cj, r.Scheme); err != nil {
 		return err
 	}
 	return r.Client.Create(context.TODO(), cj)
 }
 
-// reconcileJob will ensure that the Job for the ArgoCDExport is present.
-func (r *ReconcileArgoCDExport) reconcileJob(cr *argoproj.ArgoCDExport) error {
+// reconcileJob will ensure that the Job for the ArgoCDExport is present and matches the desired state. A CronJob left
+// over from before the Schedule was removed is deleted.
+//
+// The pod template of a Job is immutable, a Job that does not match the desired state is deleted and created again on
+// the next reconciliation, which runs the export again with the new settings.
+func (r *ReconcileArgoCDExport) reconcileJob(cr *argoproj.ArgoCDExport, instance *argoproj.ArgoCD) error {
 	if cr.Spec.Storage == nil {
 		return nil // Do nothing if storage options not set
 	}
 
+	if err := r.deleteExportCronJob(cr); err != nil {
+		return err
+	}
+
+	desired := newPodTemplateSpec(cr, instance, r.Client)
+
 	job := newJob(cr)
 	if argoutil.IsObjectFound(r.Client, cr.Namespace, job.Name, job) {
-		if job.Status.Succeeded > 0 && cr.Status.Phase != common.ArgoCDStatusCompleted {
-			// Mark status Phase as Complete
-			cr.Status.Phase = common.ArgoCDStatusCompleted
-			return r.Client.Status().Update(context.TODO(), cr)
+		existing := job.Spec.Template.Spec.DeepCopy()
+		if !updateExportPodSpec(existing, &desired.Spec) {
+			return nil // Job is up to date, its progress is tracked by reconcileExportStatus
+		}
+
+		log.Info("replacing outdated export job", "name", job.Name, "namespace", job.Namespace)
+		if err := r.Client.Delete(context.TODO(), job, client.PropagationPolicy(metav1.DeletePropagationBackground)); err != nil && !apierrors.IsNotFound(err) {
+			return err
 		}
-		return nil // Job not complete, move along...
+		return argoutil.CreateEvent(r.Client, "Normal", "Exporting", "Export Job no longer matches the ArgoCDExport and was replaced.", "ExportJobReplaced", cr.ObjectMeta, cr.TypeMeta)
 	}
 
-	// To create the job, we need the name of the argocd instance.  Although the argocd export cr contains a field with
-	// the argocd instance name, it's never used anywhere, and so there may be existing argocd export resources with the
-	// wrong name. To avoid these breaking, we look up the name of the argocd instance in the namespace of the export cr.
-	argocdName, err := r.argocdName(cr.Namespace)
-	if err != nil {
+	job.Spec.Template = desired
+	if err := controllerutil.SetControllerReference(cr, job, r.Scheme); err != nil {
 		return err
 	}
-	job.Spec.Template = newPodTemplateSpec(cr, argocdName, r.Client)
+	return r.Client.Create(context.TODO(), job)
+}
+
+// reconcileKeyRotationJob will ensure that a scheduled ArgoCDExport is run once, outside of its schedule, after its
+// backup key changed from the given previous key ID, so that a backup is taken and the retained backups are
+// re-encrypted with the new key. The Job is created from the template of the export CronJob, and named after the
+// new key so that it is created once per key.
+func (r *ReconcileArgoCDExport) reconcileKeyRotationJob(cr *argoproj.ArgoCDExport, previousKeyID string) error {
+	if cr.Spec.Storage == nil || len(previousKeyID) == 0 || previousKeyID == cr.Status.KeyID || len(cr.Status.RestorePoints) == 0 {
+		return nil // Nothing was exported with the previous key
+	}
+	if cr.Spec.Suspend != nil && *cr.Spec.Suspend {
+		return nil // Suspended exports are only run again once resumed
+	}
+
+	cj := newCronJob(cr)
+	if !argoutil.IsObjectFound(r.Client, cr.Namespace, cj.Name, cj) {
+		return nil
+	}
+
+	job := newJob(cr)
+	job.Name = keyRotationJobName(cr.Name, cr.Status.KeyID)
+	if argoutil.IsObjectFound(r.Client, cr.Namespace, job.Name, job) {
+		return nil
+	}
 
+	job.Labels = cj.Spec.JobTemplate.Labels
+	job.Spec = *cj.Spec.JobTemplate.Spec.DeepCopy()
 	if err := controllerutil.SetControllerReference(cr, job, r.Scheme); err != nil {
 		return err
 	}
-	return r.Client.Create(context.TODO(), job)
+
+	log.Info("creating export job for the new backup key", "name", job.Name, "namespace", job.Namespace)
+	if err := r.Client.Create(context.TODO(), job); err != nil {
+		return err
+	}
+	message := fmt.Sprintf("Export Job %s started to re-encrypt the retained backups with the new backup key.", job.Name)
+	return argoutil.CreateEvent(r.Client, "Normal", "Exporting", message, "BackupKeyRotationStarted", cr.ObjectMeta, cr.TypeMeta)
+}
+
+// keyRotationJobName returns the name of the Job exporting the given ArgoCDExport after its key changed to the given
+// key ID.
+func keyRotationJobName(name, keyID string) string {
+	sum := sha256.Sum256([]byte(keyID))
+	suffix := "-rotate-" + hex.EncodeToString(sum[:4])
+	if len(name)+len(suffix) > 63 {
+		name = name[:63-len(suffix)]
+	}
+	return name + suffix
+}
+
+// deleteExportCronJob will delete the CronJob owned by the given ArgoCDExport, along with the Jobs it created.
+func (r *ReconcileArgoCDExport) deleteExportCronJob(cr *argoproj.ArgoCDExport) error {
+	cj := newCronJob(cr)
+	if !argoutil.IsObjectFound(r.Client, cr.Namespace, cj.Name, cj) || !metav1.IsControlledBy(cj, cr) {
+		return nil
+	}
+
+	log.Info("deleting export cronjob, schedule has been removed", "name", cj.Name, "namespace", cj.Namespace)
+	if err := r.Client.Delete(context.TODO(), cj, client.PropagationPolicy(metav1.DeletePropagationBackground)); err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
+
+// deleteExportJob will delete the one-shot Job owned by the given ArgoCDExport. The Jobs created by the CronJob have
+// different names and are left to its history limits.
+func (r *ReconcileArgoCDExport) deleteExportJob(cr *argoproj.ArgoCDExport) error {
+	job := newJob(cr)
+	if !argoutil.IsObjectFound(r.Client, cr.Namespace, job.Name, job) || !metav1.IsControlledBy(job, cr) {
+		return nil
+	}
+
+	log.Info("deleting export job, schedule has been set", "name", job.Name, "namespace", job.Namespace)
+	if err := r.Client.Delete(context.TODO(), job, client.PropagationPolicy(metav1.DeletePropagationBackground)); err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
+
+// newExportCronJobSpec returns the desired CronJobSpec for the given scheduled ArgoCDExport. Defaults are set
+// explicitly, so that the spec can be compared with the one returned by the API server.
+func newExportCronJobSpec(cr *argoproj.ArgoCDExport, instance *argoproj.ArgoCD, client client.Client) batchv1.CronJobSpec {
+	spec := batchv1.CronJobSpec{
+		Schedule:                   *cr.Spec.Schedule,
+		ConcurrencyPolicy:          batchv1.AllowConcurrent,
+		Suspend:                    boolPtr(false),
+		SuccessfulJobsHistoryLimit: int32Ptr(common.ArgoCDDefaultExportSuccessfulJobsHistoryLimit),
+		FailedJobsHistoryLimit:     int32Ptr(common.ArgoCDDefaultExportFailedJobsHistoryLimit),
+	}
+	if len(cr.Spec.ConcurrencyPolicy) > 0 {
+		spec.ConcurrencyPolicy = cr.Spec.ConcurrencyPolicy
+	}
+	if cr.Spec.Suspend != nil {
+		spec.Suspend = boolPtr(*cr.Spec.Suspend)
+	}
+	if cr.Spec.SuccessfulJobsHistoryLimit != nil {
+		spec.SuccessfulJobsHistoryLimit = int32Ptr(*cr.Spec.SuccessfulJobsHistoryLimit)
+	}
+	if cr.Spec.FailedJobsHistoryLimit != nil {
+		spec.FailedJobsHistoryLimit = int32Ptr(*cr.Spec.FailedJobsHistoryLimit)
+	}
+
+	// Label the Jobs created by the CronJob so that the runs can be traced back to the ArgoCDExport.
+	spec.JobTemplate.ObjectMeta.Labels = common.DefaultLabels(cr.Name)
+	spec.JobTemplate.Spec.Template = newPodTemplateSpec(cr, instance, client)
+	return spec
+}
+
+// updateExportPodSpec will update the fields of the existing export PodSpec that are managed by the operator to match
+// the desired PodSpec, and returns true if any of them changed. Fields are compared semantically, as empty lists are
+// dropped and quantities are normalized by the API server.
+func updateExportPodSpec(existing *corev1.PodSpec, desired *corev1.PodSpec) bool {
+	changed := false
+
+	if len(existing.Containers) != len(desired.Containers) {
+		existing.Containers = desired.Containers
+		changed = true
+	}
+	for i := range desired.Containers {
+		e := &existing.Containers[i]
+		d := desired.Containers[i]
+		if e.Name != d.Name {
+			existing.Containers[i] = d
+			changed = true
+			continue
+		}
+		if e.Image != d.Image {
+			e.Image = d.Image
+			changed = true
+		}
+		if e.ImagePullPolicy != d.ImagePullPolicy {
+			e.ImagePullPolicy = d.ImagePullPolicy
+			changed = true
+		}
+		if !equality.Semantic.DeepEqual(e.Command, d.Command) {
+			e.Command = d.Command
+			changed = true
+		}
+		if !equality.Semantic.DeepEqual(e.Env, d.Env) {
+			e.Env = d.Env
+			changed = true
+		}
+		if !equality.Semantic.DeepEqual(e.VolumeMounts, d.VolumeMounts) {
+			e.VolumeMounts = d.VolumeMounts
+			changed = true
+		}
+		if !equality.Semantic.DeepEqual(e.Resources, d.Resources) {
+			e.Resources = d.Resources
+			changed = true
+		}
+	}
+
+	if !equality.Semantic.DeepEqual(existing.Volumes, desired.Volumes) {
+		existing.Volumes = desired.Volumes
+		changed = true
+	}
+	if !equality.Semantic.DeepEqual(existing.NodeSelector, desired.NodeSelector) {
+		existing.NodeSelector = desired.NodeSelector
+		changed = true
+	}
+	if !equality.Semantic.DeepEqual(existing.Tolerations, desired.Tolerations) {
+		existing.Tolerations = desired.Tolerations
+		changed = true
+	}
+	if !equality.Semantic.DeepEqual(existing.Affinity, desired.Affinity) {
+		existing.Affinity = desired.Affinity
+		changed = true
+	}
+	if !equality.Semantic.DeepEqual(existing.TopologySpreadConstraints, desired.TopologySpreadConstraints) {
+		existing.TopologySpreadConstraints = desired.TopologySpreadConstraints
+		changed = true
+	}
+	if existing.PriorityClassName != desired.PriorityClassName {
+		existing.PriorityClassName = desired.PriorityClassName
+		changed = true
+	}
+	if !equality.Semantic.DeepEqual(existing.RuntimeClassName, desired.RuntimeClassName) {
+		existing.RuntimeClassName = desired.RuntimeClassName
+		changed = true
+	}
+	if !equality.Semantic.DeepEqual(existing.ImagePullSecrets, desired.ImagePullSecrets) {
+		existing.ImagePullSecrets = desired.ImagePullSecrets
+		changed = true
+	}
+	if existing.ServiceAccountName != desired.ServiceAccountName {
+		existing.ServiceAccountName = desired.ServiceAccountName
+		changed = true
+	}
+	return changed
+}
+
+func boolPtr(value bool) *bool {
+	return &value
+}
+
+func int32Ptr(value int32) *int32 {
+	return &value
 }
 
-func (r *ReconcileArgoCDExport) argocdName(namespace string) (string, error) {
+// resolveArgoCD resolves the Argo CD instance to export and records the outcome in the ArgoCDResolved condition of
+// the given ArgoCDExport.
+//
+// The instance is looked up by name in the namespace given by ArgocdNamespace, or in the namespace of the export cr.
+// Although the argocd export cr contains a field with the argocd instance name, it was never used in the past, and so
+// there may be existing argocd export resources with the wrong name. To avoid these breaking, we fall back to the only
+// argocd instance in the namespace of the export cr when the named instance does not exist and no ArgocdNamespace is
+// set.
+func (r *ReconcileArgoCDExport) resolveArgoCD(cr *argoproj.ArgoCDExport) (*argoproj.ArgoCD, error) {
+	namespace := getArgoCDNamespace(cr)
 	argocds := &argoproj.ArgoCDList{}
 	if err := r.Client.List(context.TODO(), argocds, &client.ListOptions{Namespace: namespace}); err != nil {
-		return "", err
+		return nil, err
 	}
-	if len(argocds.Items) != 1 {
-		return "", fmt.Errorf("No Argo CD instance found in namespace %s", namespace)
+
+	var (
+		found   *argoproj.ArgoCD
+		reason  string
+		message string
+	)
+	for i, a := range argocds.Items {
+		if a.Name == cr.Spec.Argocd {
+			found = &argocds.Items[i]
+		}
+	}
+	explicit := cr.Spec.ArgocdNamespace != nil && len(*cr.Spec.ArgocdNamespace) > 0
+	switch {
+	case found != nil:
+		message = fmt.Sprintf("Argo CD instance %s found in namespace %s", found.Name, namespace)
+	case explicit:
+		reason = argoproj.ArgoCDExportReasonArgoCDNotFound
+		message = fmt.Sprintf("Argo CD instance %s not found in namespace %s", cr.Spec.Argocd, namespace)
+	case len(argocds.Items) == 1:
+		found = &argocds.Items[0]
+		message = fmt.Sprintf("spec.argocd %q not found, using the only Argo CD instance %s in namespace %s", cr.Spec.Argocd, found.Name, namespace)
+	case len(argocds.Items) == 0:
+		reason = argoproj.ArgoCDExportReasonArgoCDNotFound
+		message = fmt.Sprintf("no Argo CD instance found in namespace %s", namespace)
+	default:
+		reason = argoproj.ArgoCDExportReasonAmbiguousArgoCD
+		message = fmt.Sprintf("spec.argocd %q does not match any of the %d Argo CD instances in namespace %s", cr.Spec.Argocd, len(argocds.Items), namespace)
+	}
+
+	if len(reason) > 0 {
+		meta.SetStatusCondition(&cr.Status.Conditions, metav1.Condition{
+			Type:               argoproj.ArgoCDExportConditionArgoCDResolved,
+			Status:             metav1.ConditionFalse,
+			Reason:             reason,
+			Message:            message,
+			ObservedGeneration: cr.Generation,
+		})
+		return nil, errors.New(message)
 	}
-	argocd := argocds.Items[0]
-	return argocd.Name, nil
+
+	meta.SetStatusCondition(&cr.Status.Conditions, metav1.Condition{
+		Type:               argoproj.ArgoCDExportConditionArgoCDResolved,
+		Status:             metav1.ConditionTrue,
+		Reason:             argoproj.ArgoCDExportReasonArgoCDFound,
+		Message:            message,
+		ObservedGeneration: cr.Generation,
+	})
+	return found, nil
 }
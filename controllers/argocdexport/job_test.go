@@ -0,0 +1,163 @@
+package argocdexport
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/types"
+
+	argoproj "github.com/argoproj-labs/argocd-operator/api/v1alpha1"
+	"github.com/argoproj-labs/argocd-operator/common"
+)
+
+func withSchedule(schedule string) func(*argoproj.ArgoCDExport) {
+	return func(cr *argoproj.ArgoCDExport) {
+		cr.Spec.Schedule = &schedule
+	}
+}
+
+func withVersion(version string) func(*argoproj.ArgoCDExport) {
+	return func(cr *argoproj.ArgoCDExport) {
+		cr.Spec.Version = version
+	}
+}
+
+func TestGetArgoExportCommand(t *testing.T) {
+	cr := makeTestArgoCDExport()
+	instance := makeTestArgoCD("argocd")
+
+	// the legacy default image runs util.sh through its entrypoint
+	assert.Equal(t, []string{"uid_entrypoint.sh", "argocd-operator-util", "export", "local"},
+		getArgoExportCommand(cr, getArgoExportContainerImage(cr, instance)))
+
+	cr = makeTestArgoCDExport(withVersion("v0.6.0"))
+	assert.Equal(t, []string{"argocd-operator-util", "export", "local"},
+		getArgoExportCommand(cr, getArgoExportContainerImage(cr, instance)))
+}
+
+func TestReconcileArgoCDExport_reconcileCronJob(t *testing.T) {
+	cr := makeTestArgoCDExport(withSchedule("0 * * * *"))
+	r := makeTestReconciler(t, cr, makeTestArgoCD("argocd"))
+
+	assert.NoError(t, r.reconcileCronJob(cr, makeTestArgoCD("argocd")))
+
+	cj := &batchv1.CronJob{}
+	assert.NoError(t, r.Client.Get(context.TODO(), types.NamespacedName{Name: cr.Name, Namespace: cr.Namespace}, cj))
+	assert.Equal(t, "0 * * * *", cj.Spec.Schedule)
+	assert.Equal(t, batchv1.AllowConcurrent, cj.Spec.ConcurrencyPolicy)
+	assert.False(t, *cj.Spec.Suspend)
+	assert.Equal(t, int32(common.ArgoCDDefaultExportSuccessfulJobsHistoryLimit), *cj.Spec.SuccessfulJobsHistoryLimit)
+	assert.Equal(t, int32(common.ArgoCDDefaultExportFailedJobsHistoryLimit), *cj.Spec.FailedJobsHistoryLimit)
+
+	// an up to date CronJob is not updated
+	resourceVersion := cj.ResourceVersion
+	assert.NoError(t, r.reconcileCronJob(cr, makeTestArgoCD("argocd")))
+	assert.NoError(t, r.Client.Get(context.TODO(), types.NamespacedName{Name: cr.Name, Namespace: cr.Namespace}, cj))
+	assert.Equal(t, resourceVersion, cj.ResourceVersion)
+
+	// the whole template converges
+	schedule := "30 2 * * *"
+	cr.Spec.Schedule = &schedule
+	cr.Spec.Image = "registry.example.com/argocd-operator-util"
+	cr.Spec.Version = "v1"
+	cr.Spec.ConcurrencyPolicy = batchv1.ForbidConcurrent
+	cr.Spec.Suspend = boolPtr(true)
+	cr.Spec.SuccessfulJobsHistoryLimit = int32Ptr(5)
+	cr.Spec.FailedJobsHistoryLimit = int32Ptr(2)
+	cr.Spec.Resources = &corev1.ResourceRequirements{
+		Limits: corev1.ResourceList{
+			corev1.ResourceMemory: resource.MustParse("256Mi"),
+		},
+	}
+	cr.Spec.NodePlacement = &argoproj.ArgoCDNodePlacementSpec{
+		NodeSelector: map[string]string{"kubernetes.io/os": "linux"},
+		Tolerations:  []corev1.Toleration{{Key: "dedicated", Operator: corev1.TolerationOpExists}},
+	}
+	cr.Spec.Retention = &argoproj.ArgoCDExportRetentionSpec{KeepLast: int32Ptr(7)}
+	assert.NoError(t, r.reconcileCronJob(cr, makeTestArgoCD("argocd")))
+
+	assert.NoError(t, r.Client.Get(context.TODO(), types.NamespacedName{Name: cr.Name, Namespace: cr.Namespace}, cj))
+	assert.Equal(t, schedule, cj.Spec.Schedule)
+	assert.Equal(t, batchv1.ForbidConcurrent, cj.Spec.ConcurrencyPolicy)
+	assert.True(t, *cj.Spec.Suspend)
+	assert.Equal(t, int32(5), *cj.Spec.SuccessfulJobsHistoryLimit)
+	assert.Equal(t, int32(2), *cj.Spec.FailedJobsHistoryLimit)
+
+	pod := cj.Spec.JobTemplate.Spec.Template.Spec
+	assert.Equal(t, "registry.example.com/argocd-operator-util:v1", pod.Containers[0].Image)
+	assert.Equal(t, *cr.Spec.Resources, pod.Containers[0].Resources)
+	assert.Contains(t, pod.Containers[0].Env, corev1.EnvVar{Name: common.ArgoCDExportRetentionKeepLastEnvName, Value: "7"})
+	assert.Equal(t, cr.Spec.NodePlacement.NodeSelector, pod.NodeSelector)
+	assert.Equal(t, cr.Spec.NodePlacement.Tolerations, pod.Tolerations)
+}
+
+func TestReconcileArgoCDExport_reconcileJob(t *testing.T) {
+	cr := makeTestArgoCDExport()
+	r := makeTestReconciler(t, cr, makeTestArgoCD("argocd"))
+
+	assert.NoError(t, r.reconcileJob(cr, makeTestArgoCD("argocd")))
+
+	job := &batchv1.Job{}
+	assert.NoError(t, r.Client.Get(context.TODO(), types.NamespacedName{Name: cr.Name, Namespace: cr.Namespace}, job))
+
+	// an up to date Job is kept
+	resourceVersion := job.ResourceVersion
+	assert.NoError(t, r.reconcileJob(cr, makeTestArgoCD("argocd")))
+	assert.NoError(t, r.Client.Get(context.TODO(), types.NamespacedName{Name: cr.Name, Namespace: cr.Namespace}, job))
+	assert.Equal(t, resourceVersion, job.ResourceVersion)
+
+	// an outdated Job is deleted, then created again
+	cr.Spec.Version = "v2"
+	assert.NoError(t, r.reconcileJob(cr, makeTestArgoCD("argocd")))
+	err := r.Client.Get(context.TODO(), types.NamespacedName{Name: cr.Name, Namespace: cr.Namespace}, job)
+	assert.True(t, apierrors.IsNotFound(err))
+
+	assert.NoError(t, r.reconcileJob(cr, makeTestArgoCD("argocd")))
+	assert.NoError(t, r.Client.Get(context.TODO(), types.NamespacedName{Name: cr.Name, Namespace: cr.Namespace}, job))
+	assert.Equal(t, common.ArgoCDDefaultExportJobImage+":v2", job.Spec.Template.Spec.Containers[0].Image)
+}
+
+func TestReconcileArgoCDExport_reconcileExport_ScheduleToggle(t *testing.T) {
+	cr := makeTestArgoCDExport()
+	r := makeTestReconciler(t, cr, makeTestArgoCD("argocd"))
+	key := types.NamespacedName{Name: cr.Name, Namespace: cr.Namespace}
+
+	assert.NoError(t, r.reconcileExport(cr))
+	assert.NoError(t, r.Client.Get(context.TODO(), key, &batchv1.Job{}))
+
+	// setting a schedule replaces the Job with a CronJob
+	schedule := "0 * * * *"
+	cr.Spec.Schedule = &schedule
+	assert.NoError(t, r.reconcileExport(cr))
+	assert.True(t, apierrors.IsNotFound(r.Client.Get(context.TODO(), key, &batchv1.Job{})))
+	assert.NoError(t, r.Client.Get(context.TODO(), key, &batchv1.CronJob{}))
+
+	// removing the schedule replaces the CronJob with a Job
+	cr.Spec.Schedule = nil
+	assert.NoError(t, r.reconcileExport(cr))
+	assert.True(t, apierrors.IsNotFound(r.Client.Get(context.TODO(), key, &batchv1.CronJob{})))
+	assert.NoError(t, r.Client.Get(context.TODO(), key, &batchv1.Job{}))
+}
+
+func TestReconcileArgoCDExport_reconcileJob_imagePullOptions(t *testing.T) {
+	cr := makeTestArgoCDExport()
+	argocd := makeTestArgoCD("argocd")
+	argocd.Spec.ImageRegistry = "mirror.example.com"
+	argocd.Spec.ImagePullPolicy = corev1.PullIfNotPresent
+	argocd.Spec.ImagePullSecrets = []corev1.LocalObjectReference{{Name: "mirror"}}
+	r := makeTestReconciler(t, cr, argocd)
+
+	assert.NoError(t, r.reconcileJob(cr, argocd))
+
+	job := &batchv1.Job{}
+	assert.NoError(t, r.Client.Get(context.TODO(), types.NamespacedName{Name: cr.Name, Namespace: cr.Namespace}, job))
+	pod := job.Spec.Template.Spec
+	assert.Equal(t, "mirror.example.com/argoprojlabs/argocd-operator-util@"+common.ArgoCDDefaultExportJobVersion, pod.Containers[0].Image)
+	assert.Equal(t, corev1.PullIfNotPresent, pod.Containers[0].ImagePullPolicy)
+	assert.Equal(t, argocd.Spec.ImagePullSecrets, pod.ImagePullSecrets)
+}
@@ -0,0 +1,270 @@
+// Copyright 2023 ArgoCD Operator Developers
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package argocdexport
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	argoproj "github.com/argoproj-labs/argocd-operator/api/v1alpha1"
+	"github.com/argoproj-labs/argocd-operator/common"
+	"github.com/argoproj-labs/argocd-operator/controllers/argoutil"
+)
+
+// getExportServiceAccountName returns the name of the ServiceAccount, Role and RoleBinding used by the export
+// process of the given ArgoCDExport.
+func getExportServiceAccountName(cr *argoproj.ArgoCDExport) string {
+	name := cr.Name
+	suffix := "-argocd-export"
+	if len(name)+len(suffix) > 63 {
+		name = name[:63-len(suffix)]
+	}
+	return name + suffix
+}
+
+// getArgoCDNamespace returns the namespace of the Argo CD instance exported by the given ArgoCDExport.
+func getArgoCDNamespace(cr *argoproj.ArgoCDExport) string {
+	if cr.Spec.ArgocdNamespace != nil && len(*cr.Spec.ArgocdNamespace) > 0 {
+		return *cr.Spec.ArgocdNamespace
+	}
+	return cr.Namespace
+}
+
+// exportRBACLabels returns the labels identifying the RBAC resources of the given ArgoCDExport, which may live in
+// another namespace and so cannot be owned by it.
+func exportRBACLabels(cr *argoproj.ArgoCDExport) map[string]string {
+	labels := common.DefaultLabels(cr.Name)
+	labels[common.ArgoCDExportNameLabel] = cr.Name
+	labels[common.ArgoCDExportNamespaceLabel] = cr.Namespace
+	return labels
+}
+
+// policyRulesForExport returns the rules needed by "argocd admin export" to read the Argo CD configuration and
+// resources from the namespace of the Argo CD instance.
+func policyRulesForExport() []rbacv1.PolicyRule {
+	return []rbacv1.PolicyRule{
+		{
+			APIGroups: []string{""},
+			Resources: []string{"configmaps", "secrets"},
+			Verbs:     []string{"get", "list"},
+		},
+		{
+			APIGroups: []string{"argoproj.io"},
+			Resources: []string{"applications", "applicationsets", "appprojects"},
+			Verbs:     []string{"get", "list"},
+		},
+	}
+}
+
+// reconcileExportRBAC will ensure that the export process of the given ArgoCDExport runs with a dedicated
+// ServiceAccount, which may only read the Argo CD configuration and resources from the namespace of the given Argo CD
+// instance.
+func (r *ReconcileArgoCDExport) reconcileExportRBAC(cr *argoproj.ArgoCDExport, instance *argoproj.ArgoCD) error {
+	if err := r.reconcileExportServiceAccount(cr); err != nil {
+		return err
+	}
+	if err := r.reconcileExportRole(cr, instance.Namespace); err != nil {
+		return err
+	}
+	if err := r.reconcileExportRoleBinding(cr, instance.Namespace); err != nil {
+		return err
+	}
+	// The Role and RoleBinding are left behind in the former namespace when the exported instance changes.
+	return r.deleteExportRBAC(cr, instance.Namespace)
+}
+
+// reconcileExportServiceAccount will ensure that the ServiceAccount of the export process is present.
+func (r *ReconcileArgoCDExport) reconcileExportServiceAccount(cr *argoproj.ArgoCDExport) error {
+	sa := &corev1.ServiceAccount{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      getExportServiceAccountName(cr),
+			Namespace: cr.Namespace,
+			Labels:    common.DefaultLabels(cr.Name),
+		},
+	}
+	if argoutil.IsObjectFound(r.Client, sa.Namespace, sa.Name, sa) {
+		return nil
+	}
+
+	if err := controllerutil.SetControllerReference(cr, sa, r.Scheme); err != nil {
+		return err
+	}
+	log.Info("creating export service account", "name", sa.Name, "namespace", sa.Namespace)
+	return r.Client.Create(context.TODO(), sa)
+}
+
+// reconcileExportRole will ensure that the Role of the export process is present in the given namespace, and only
+// grants the rules of policyRulesForExport.
+func (r *ReconcileArgoCDExport) reconcileExportRole(cr *argoproj.ArgoCDExport, namespace string) error {
+	role := &rbacv1.Role{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      getExportServiceAccountName(cr),
+			Namespace: namespace,
+		},
+	}
+	rules := policyRulesForExport()
+
+	if argoutil.IsObjectFound(r.Client, role.Namespace, role.Name, role) {
+		if !reflect.DeepEqual(role.Rules, rules) {
+			role.Rules = rules
+			log.Info("updating export role", "name", role.Name, "namespace", role.Namespace)
+			return r.Client.Update(context.TODO(), role)
+		}
+		return nil
+	}
+
+	role.Labels = exportRBACLabels(cr)
+	role.Rules = rules
+	if err := r.setExportRBACOwner(cr, role); err != nil {
+		return err
+	}
+	log.Info("creating export role", "name", role.Name, "namespace", role.Namespace)
+	return r.Client.Create(context.TODO(), role)
+}
+
+// reconcileExportRoleBinding will ensure that the ServiceAccount of the export process is bound to its Role in the
+// given namespace.
+func (r *ReconcileArgoCDExport) reconcileExportRoleBinding(cr *argoproj.ArgoCDExport, namespace string) error {
+	name := getExportServiceAccountName(cr)
+	roleRef := rbacv1.RoleRef{
+		APIGroup: rbacv1.GroupName,
+		Kind:     "Role",
+		Name:     name,
+	}
+	subjects := []rbacv1.Subject{{
+		Kind:      rbacv1.ServiceAccountKind,
+		Name:      name,
+		Namespace: cr.Namespace,
+	}}
+
+	rb := &rbacv1.RoleBinding{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+		},
+	}
+	if argoutil.IsObjectFound(r.Client, rb.Namespace, rb.Name, rb) {
+		if rb.RoleRef == roleRef {
+			if !reflect.DeepEqual(rb.Subjects, subjects) {
+				rb.Subjects = subjects
+				log.Info("updating export role binding", "name", rb.Name, "namespace", rb.Namespace)
+				return r.Client.Update(context.TODO(), rb)
+			}
+			return nil
+		}
+
+		// The RoleRef of a RoleBinding is immutable, the RoleBinding is created again.
+		log.Info("replacing export role binding with an outdated role", "name", rb.Name, "namespace", rb.Namespace)
+		if err := r.Client.Delete(context.TODO(), rb); err != nil && !apierrors.IsNotFound(err) {
+			return err
+		}
+		rb = &rbacv1.RoleBinding{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: namespace,
+			},
+		}
+	}
+
+	rb.Labels = exportRBACLabels(cr)
+	rb.RoleRef = roleRef
+	rb.Subjects = subjects
+	if err := r.setExportRBACOwner(cr, rb); err != nil {
+		return err
+	}
+	log.Info("creating export role binding", "name", rb.Name, "namespace", rb.Namespace)
+	return r.Client.Create(context.TODO(), rb)
+}
+
+// setExportRBACOwner sets the given ArgoCDExport as the owner of the given RBAC resource when they share a namespace.
+// Otherwise the resource is removed by deleteExportRBAC, once the ArgoCDExport is deleted.
+func (r *ReconcileArgoCDExport) setExportRBACOwner(cr *argoproj.ArgoCDExport, obj client.Object) error {
+	if obj.GetNamespace() != cr.Namespace {
+		return nil
+	}
+	return controllerutil.SetControllerReference(cr, obj, r.Scheme)
+}
+
+// deleteExportRBAC will delete the Roles and RoleBindings of the given ArgoCDExport from every namespace but the
+// given one, all of them if it is empty.
+func (r *ReconcileArgoCDExport) deleteExportRBAC(cr *argoproj.ArgoCDExport, keepNamespace string) error {
+	selector := client.MatchingLabels{
+		common.ArgoCDExportNameLabel:      cr.Name,
+		common.ArgoCDExportNamespaceLabel: cr.Namespace,
+	}
+
+	bindings := &rbacv1.RoleBindingList{}
+	if err := r.Client.List(context.TODO(), bindings, selector); err != nil {
+		return err
+	}
+	for i := range bindings.Items {
+		rb := &bindings.Items[i]
+		if rb.Namespace == keepNamespace {
+			continue
+		}
+		log.Info("deleting export role binding", "name", rb.Name, "namespace", rb.Namespace)
+		if err := r.Client.Delete(context.TODO(), rb); err != nil && !apierrors.IsNotFound(err) {
+			return fmt.Errorf("failed to delete export role binding %s/%s: %w", rb.Namespace, rb.Name, err)
+		}
+	}
+
+	roles := &rbacv1.RoleList{}
+	if err := r.Client.List(context.TODO(), roles, selector); err != nil {
+		return err
+	}
+	for i := range roles.Items {
+		role := &roles.Items[i]
+		if role.Namespace == keepNamespace {
+			continue
+		}
+		log.Info("deleting export role", "name", role.Name, "namespace", role.Namespace)
+		if err := r.Client.Delete(context.TODO(), role); err != nil && !apierrors.IsNotFound(err) {
+			return fmt.Errorf("failed to delete export role %s/%s: %w", role.Namespace, role.Name, err)
+		}
+	}
+	return nil
+}
+
+// reconcileExportFinalizer will ensure that an ArgoCDExport of an Argo CD instance in another namespace has a
+// finalizer, so that its RBAC resources can be removed from that namespace once it is deleted. It returns true if the
+// ArgoCDExport is being deleted and its finalizer has been handled.
+func (r *ReconcileArgoCDExport) reconcileExportFinalizer(cr *argoproj.ArgoCDExport) (bool, error) {
+	if cr.DeletionTimestamp != nil {
+		if !controllerutil.ContainsFinalizer(cr, common.ArgoCDExportFinalizer) {
+			return true, nil
+		}
+		if err := r.deleteExportRBAC(cr, ""); err != nil {
+			return true, err
+		}
+		controllerutil.RemoveFinalizer(cr, common.ArgoCDExportFinalizer)
+		return true, r.Client.Update(context.TODO(), cr)
+	}
+
+	if getArgoCDNamespace(cr) != cr.Namespace && !controllerutil.ContainsFinalizer(cr, common.ArgoCDExportFinalizer) {
+		controllerutil.AddFinalizer(cr, common.ArgoCDExportFinalizer)
+		if err := r.Client.Update(context.TODO(), cr); err != nil {
+			return false, fmt.Errorf("failed to add export finalizer to %s: %w", cr.Name, err)
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,116 @@
+package argocdexport
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	argoproj "github.com/argoproj-labs/argocd-operator/api/v1alpha1"
+	"github.com/argoproj-labs/argocd-operator/common"
+)
+
+func withArgoCDNamespace(namespace string) func(*argoproj.ArgoCDExport) {
+	return func(cr *argoproj.ArgoCDExport) {
+		cr.Spec.ArgocdNamespace = &namespace
+	}
+}
+
+func TestReconcileArgoCDExport_reconcileExport_RBAC(t *testing.T) {
+	cr := makeTestArgoCDExport()
+	r := makeTestReconciler(t, cr, makeTestArgoCD("argocd"))
+
+	assert.NoError(t, r.reconcileExport(cr))
+	name := getExportServiceAccountName(cr)
+	key := types.NamespacedName{Name: name, Namespace: cr.Namespace}
+
+	sa := &corev1.ServiceAccount{}
+	assert.NoError(t, r.Client.Get(context.TODO(), key, sa))
+	assert.True(t, metav1.IsControlledBy(sa, cr))
+
+	role := &rbacv1.Role{}
+	assert.NoError(t, r.Client.Get(context.TODO(), key, role))
+	assert.True(t, metav1.IsControlledBy(role, cr))
+	assert.Equal(t, policyRulesForExport(), role.Rules)
+
+	rb := &rbacv1.RoleBinding{}
+	assert.NoError(t, r.Client.Get(context.TODO(), key, rb))
+	assert.Equal(t, name, rb.RoleRef.Name)
+	assert.Equal(t, []rbacv1.Subject{{Kind: rbacv1.ServiceAccountKind, Name: name, Namespace: cr.Namespace}}, rb.Subjects)
+
+	job := &batchv1.Job{}
+	assert.NoError(t, r.Client.Get(context.TODO(), types.NamespacedName{Name: cr.Name, Namespace: cr.Namespace}, job))
+	assert.Equal(t, name, job.Spec.Template.Spec.ServiceAccountName)
+	assert.Contains(t, job.Spec.Template.Spec.Containers[0].Env, corev1.EnvVar{Name: common.ArgoCDExportNamespaceEnvName, Value: "argocd"})
+
+	// a modified Role is restored
+	role.Rules = append(role.Rules, rbacv1.PolicyRule{APIGroups: []string{""}, Resources: []string{"pods"}, Verbs: []string{"*"}})
+	assert.NoError(t, r.Client.Update(context.TODO(), role))
+	assert.NoError(t, r.reconcileExport(cr))
+	assert.NoError(t, r.Client.Get(context.TODO(), key, role))
+	assert.Equal(t, policyRulesForExport(), role.Rules)
+}
+
+func TestReconcileArgoCDExport_reconcileExport_OtherNamespace(t *testing.T) {
+	instance := makeTestArgoCD("argocd")
+	instance.Namespace = "argocd-instance"
+	cr := makeTestArgoCDExport(withArgoCDNamespace(instance.Namespace), withVersion("v0.6.0"))
+	r := makeTestReconciler(t, cr, instance)
+
+	deleted, err := r.reconcileExportFinalizer(cr)
+	assert.NoError(t, err)
+	assert.False(t, deleted)
+	assert.True(t, controllerutil.ContainsFinalizer(cr, common.ArgoCDExportFinalizer))
+
+	assert.NoError(t, r.reconcileExport(cr))
+	name := getExportServiceAccountName(cr)
+
+	// the Role and RoleBinding are created in the namespace of the instance, for the ServiceAccount of the export
+	assert.NoError(t, r.Client.Get(context.TODO(), types.NamespacedName{Name: name, Namespace: cr.Namespace}, &corev1.ServiceAccount{}))
+	role := &rbacv1.Role{}
+	assert.NoError(t, r.Client.Get(context.TODO(), types.NamespacedName{Name: name, Namespace: instance.Namespace}, role))
+	assert.Empty(t, role.OwnerReferences)
+	rb := &rbacv1.RoleBinding{}
+	assert.NoError(t, r.Client.Get(context.TODO(), types.NamespacedName{Name: name, Namespace: instance.Namespace}, rb))
+	assert.Equal(t, cr.Namespace, rb.Subjects[0].Namespace)
+
+	job := &batchv1.Job{}
+	assert.NoError(t, r.Client.Get(context.TODO(), types.NamespacedName{Name: cr.Name, Namespace: cr.Namespace}, job))
+	assert.Contains(t, job.Spec.Template.Spec.Containers[0].Env, corev1.EnvVar{Name: common.ArgoCDExportNamespaceEnvName, Value: instance.Namespace})
+
+	// the Role and RoleBinding are removed from the namespace of the instance once the export is deleted
+	assert.NoError(t, r.Client.Delete(context.TODO(), cr))
+	assert.NoError(t, r.Client.Get(context.TODO(), types.NamespacedName{Name: cr.Name, Namespace: cr.Namespace}, cr))
+	deleted, err = r.reconcileExportFinalizer(cr)
+	assert.NoError(t, err)
+	assert.True(t, deleted)
+	assert.True(t, apierrors.IsNotFound(r.Client.Get(context.TODO(), types.NamespacedName{Name: name, Namespace: instance.Namespace}, role)))
+	assert.True(t, apierrors.IsNotFound(r.Client.Get(context.TODO(), types.NamespacedName{Name: name, Namespace: instance.Namespace}, rb)))
+}
+
+func TestReconcileArgoCDExport_argoCDMapper(t *testing.T) {
+	local := makeTestArgoCDExport()
+	remote := makeTestArgoCDExport(withArgoCDNamespace("argocd-instance"))
+	remote.Name = "remote-export"
+	r := makeTestReconciler(t, local, remote)
+
+	instance := makeTestArgoCD("argocd")
+	instance.Namespace = "argocd-instance"
+	requests := r.argoCDMapper(context.TODO(), instance)
+	assert.Equal(t, []reconcile.Request{{NamespacedName: types.NamespacedName{Name: remote.Name, Namespace: remote.Namespace}}}, requests)
+
+	// an export without an explicit namespace may use any instance in its namespace
+	requests = r.argoCDMapper(context.TODO(), makeTestArgoCD("other"))
+	assert.Equal(t, []reconcile.Request{{NamespacedName: types.NamespacedName{Name: local.Name, Namespace: local.Namespace}}}, requests)
+
+	instance.Name = "other"
+	assert.Empty(t, r.argoCDMapper(context.TODO(), instance))
+}
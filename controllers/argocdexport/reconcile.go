@@ -15,11 +15,19 @@
 package argocdexport
 
 import (
+	"context"
+
 	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	argoproj "github.com/argoproj-labs/argocd-operator/api/v1alpha1"
+	argoprojv1beta1 "github.com/argoproj-labs/argocd-operator/api/v1beta1"
 )
 
 // reconcileArgoCDExportResources will reconcile all ArgoCDExport resources for the give CR.
@@ -32,14 +40,70 @@ func (r *ReconcileArgoCDExport) reconcileArgoCDExportResources(cr *argoproj.Argo
 		return err
 	}
 
-	if err := r.reconcileExport(cr); err != nil {
+	existing := cr.Status.DeepCopy()
+	exportErr := r.reconcileExport(cr)
+
+	// Reflect the last export run, and any error resolving the Argo CD instance, even if the export failed.
+	if err := r.reconcileExportStatus(cr, existing); err != nil {
+		if exportErr != nil {
+			log.Error(err, "failed to update ArgoCDExport status")
+			return exportErr
+		}
 		return err
 	}
-	return nil
+	return exportErr
+}
+
+// keySecretMapper maps a Secret to the ArgoCDExports in its namespace that use it as their backup key, so that a key
+// change is picked up.
+func (r *ReconcileArgoCDExport) keySecretMapper(ctx context.Context, o client.Object) []reconcile.Request {
+	result := []reconcile.Request{}
+
+	exports := &argoproj.ArgoCDExportList{}
+	if err := r.Client.List(ctx, exports, client.InNamespace(o.GetNamespace())); err != nil {
+		log.Error(err, "failed to list ArgoCDExports", "namespace", o.GetNamespace())
+		return result
+	}
+
+	for _, export := range exports.Items {
+		encryption := export.Spec.Encryption
+		if encryption != nil && encryption.KeySecretRef != nil && encryption.KeySecretRef.Name == o.GetName() {
+			result = append(result, reconcile.Request{
+				NamespacedName: types.NamespacedName{Name: export.Name, Namespace: export.Namespace},
+			})
+		}
+	}
+	return result
+}
+
+// argoCDMapper maps an Argo CD instance to the ArgoCDExports that may export it, so that an export waiting for its
+// instance is run once the instance is created. ArgoCDExports without an ArgocdNamespace may fall back to any instance
+// in their namespace, see resolveArgoCD.
+func (r *ReconcileArgoCDExport) argoCDMapper(ctx context.Context, o client.Object) []reconcile.Request {
+	result := []reconcile.Request{}
+
+	exports := &argoproj.ArgoCDExportList{}
+	if err := r.Client.List(ctx, exports); err != nil {
+		log.Error(err, "failed to list ArgoCDExports")
+		return result
+	}
+
+	for _, export := range exports.Items {
+		if getArgoCDNamespace(&export) != o.GetNamespace() {
+			continue
+		}
+		explicit := export.Spec.ArgocdNamespace != nil && len(*export.Spec.ArgocdNamespace) > 0
+		if export.Spec.Argocd == o.GetName() || !explicit {
+			result = append(result, reconcile.Request{
+				NamespacedName: types.NamespacedName{Name: export.Name, Namespace: export.Namespace},
+			})
+		}
+	}
+	return result
 }
 
 // setResourceWatches will register Watches for each of the supported Resources.
-func setResourceWatches(bld *builder.Builder) *builder.Builder {
+func setResourceWatches(bld *builder.Builder, keySecretMapper, argoCDMapper handler.MapFunc) *builder.Builder {
 	// Watch for changes to primary resource ArgoCDExport
 	bld.For(&argoproj.ArgoCDExport{})
 
@@ -55,5 +119,20 @@ func setResourceWatches(bld *builder.Builder) *builder.Builder {
 	// Watch for changes to Secret sub-resources owned by ArgoCD instances.
 	bld.Owns(&corev1.Secret{})
 
+	// Watch for changes to ServiceAccount sub-resources owned by ArgoCDExport instances.
+	bld.Owns(&corev1.ServiceAccount{})
+
+	// Watch for changes to Role sub-resources owned by ArgoCDExport instances.
+	bld.Owns(&rbacv1.Role{})
+
+	// Watch for changes to RoleBinding sub-resources owned by ArgoCDExport instances.
+	bld.Owns(&rbacv1.RoleBinding{})
+
+	// Watch for changes to the Argo CD instances exported by ArgoCDExport instances.
+	bld.Watches(&argoprojv1beta1.ArgoCD{}, handler.EnqueueRequestsFromMapFunc(argoCDMapper))
+
+	// Watch for changes to the Secrets holding the backup key of ArgoCDExport instances.
+	bld.Watches(&corev1.Secret{}, handler.EnqueueRequestsFromMapFunc(keySecretMapper))
+
 	return bld
 }
@@ -0,0 +1,276 @@
+// Copyright 2019 ArgoCD Operator Developers
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package argocdexport
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	argoproj "github.com/argoproj-labs/argocd-operator/api/v1alpha1"
+	"github.com/argoproj-labs/argocd-operator/common"
+	"github.com/argoproj-labs/argocd-operator/controllers/argoutil"
+)
+
+// reconcileExportStatus will ensure that the Status of the ArgoCDExport reflects the last export run. The given
+// existing status is compared with the computed one to decide whether an update is needed.
+func (r *ReconcileArgoCDExport) reconcileExportStatus(cr *argoproj.ArgoCDExport, existing *argoproj.ArgoCDExportStatus) error {
+	job, err := r.lastExportJob(cr)
+	if err != nil {
+		return err
+	}
+
+	if c := meta.FindStatusCondition(cr.Status.Conditions, argoproj.ArgoCDExportConditionFailed); c != nil &&
+		c.Reason == argoproj.ArgoCDExportReasonUnsupportedByImage {
+		// The export is not run with the current options, see validateExportImage.
+		cr.Status.Phase = common.ArgoCDStatusFailed
+	} else if job != nil {
+		if job.Status.StartTime != nil {
+			cr.Status.LastRunStartTime = job.Status.StartTime
+		}
+
+		if c := jobCondition(job, batchv1.JobComplete); c != nil {
+			cr.Status.Phase = common.ArgoCDStatusCompleted
+			cr.Status.LastRunCompletionTime = job.Status.CompletionTime
+			cr.Status.LastSuccessfulRunTime = job.Status.CompletionTime
+			r.setExportResult(cr, job)
+			setExportRunConditions(cr, argoproj.ArgoCDExportConditionComplete, argoproj.ArgoCDExportReasonExportSucceeded,
+				fmt.Sprintf("export job %s completed", job.Name))
+		} else if c := jobCondition(job, batchv1.JobFailed); c != nil {
+			cr.Status.Phase = common.ArgoCDStatusFailed
+			cr.Status.LastRunCompletionTime = &c.LastTransitionTime
+			message := fmt.Sprintf("export job %s failed: %s", job.Name, c.Reason)
+			if len(c.Message) > 0 {
+				message = fmt.Sprintf("%s: %s", message, c.Message)
+			}
+			setExportRunConditions(cr, argoproj.ArgoCDExportConditionFailed, argoproj.ArgoCDExportReasonExportFailed, message)
+
+			if existing.Phase != common.ArgoCDStatusFailed || !reflect.DeepEqual(existing.LastRunStartTime, cr.Status.LastRunStartTime) {
+				if err := argoutil.CreateEvent(r.Client, "Warning", "Exporting", message, "ExportFailed", cr.ObjectMeta, cr.TypeMeta); err != nil {
+					log.Error(err, "failed to create event")
+				}
+			}
+		} else {
+			cr.Status.Phase = common.ArgoCDStatusRunning
+			setExportPendingConditions(cr, argoproj.ArgoCDExportReasonExportRunning, fmt.Sprintf("export job %s is running", job.Name))
+		}
+	} else if cr.Status.Phase != common.ArgoCDStatusCompleted {
+		cr.Status.Phase = common.ArgoCDStatusPending
+		setExportPendingConditions(cr, argoproj.ArgoCDExportReasonExportPending, "waiting for the export job to be scheduled")
+	}
+
+	cr.Status.ObservedGeneration = cr.Generation
+
+	if !reflect.DeepEqual(existing, &cr.Status) {
+		return r.Client.Status().Update(context.TODO(), cr)
+	}
+	return nil
+}
+
+// setExportRunConditions marks the given terminal condition type as true and the other one as false.
+func setExportRunConditions(cr *argoproj.ArgoCDExport, conditionType, reason, message string) {
+	for _, t := range []string{argoproj.ArgoCDExportConditionComplete, argoproj.ArgoCDExportConditionFailed} {
+		status := metav1.ConditionFalse
+		if t == conditionType {
+			status = metav1.ConditionTrue
+		}
+		meta.SetStatusCondition(&cr.Status.Conditions, metav1.Condition{
+			Type:               t,
+			Status:             status,
+			Reason:             reason,
+			Message:            message,
+			ObservedGeneration: cr.Generation,
+		})
+	}
+}
+
+// setExportPendingConditions initializes the Complete and Failed conditions while no export run has finished yet.
+// The outcome of a previous run, e.g. of a scheduled export, is preserved.
+func setExportPendingConditions(cr *argoproj.ArgoCDExport, reason, message string) {
+	for _, t := range []string{argoproj.ArgoCDExportConditionComplete, argoproj.ArgoCDExportConditionFailed} {
+		if c := meta.FindStatusCondition(cr.Status.Conditions, t); c != nil && c.Reason != argoproj.ArgoCDExportReasonExportPending &&
+			c.Reason != argoproj.ArgoCDExportReasonExportRunning {
+			continue
+		}
+		meta.SetStatusCondition(&cr.Status.Conditions, metav1.Condition{
+			Type:               t,
+			Status:             metav1.ConditionFalse,
+			Reason:             reason,
+			Message:            message,
+			ObservedGeneration: cr.Generation,
+		})
+	}
+}
+
+// jobCondition returns the condition of the given type if it is true for the Job.
+func jobCondition(job *batchv1.Job, conditionType batchv1.JobConditionType) *batchv1.JobCondition {
+	for i := range job.Status.Conditions {
+		c := &job.Status.Conditions[i]
+		if c.Type == conditionType && c.Status == corev1.ConditionTrue {
+			return c
+		}
+	}
+	return nil
+}
+
+// lastExportJob returns the Job of the most recent export run, or nil if there was none yet. For scheduled exports
+// this is the most recently created Job owned by the export CronJob, or started after a backup key rotation.
+func (r *ReconcileArgoCDExport) lastExportJob(cr *argoproj.ArgoCDExport) (*batchv1.Job, error) {
+	if cr.Spec.Schedule == nil || len(*cr.Spec.Schedule) == 0 {
+		job := newJob(cr)
+		if argoutil.IsObjectFound(r.Client, cr.Namespace, job.Name, job) {
+			return job, nil
+		}
+		return nil, nil
+	}
+
+	jobs := &batchv1.JobList{}
+	if err := r.Client.List(context.TODO(), jobs, client.InNamespace(cr.Namespace)); err != nil {
+		return nil, err
+	}
+
+	var last *batchv1.Job
+	for i := range jobs.Items {
+		job := &jobs.Items[i]
+		owner := metav1.GetControllerOf(job)
+		if owner == nil || (owner.Kind != "CronJob" && owner.Kind != "ArgoCDExport") || owner.Name != cr.Name {
+			continue
+		}
+		if last == nil || last.CreationTimestamp.Before(&job.CreationTimestamp) ||
+			(last.CreationTimestamp.Equal(&job.CreationTimestamp) && last.Name < job.Name) {
+			last = job
+		}
+	}
+	return last, nil
+}
+
+// exportResult is the result that the export process writes to the termination message of the export container,
+// see ExportResult in pkg/backup.
+type exportResult struct {
+	BackupID           string            `json:"backupID"`
+	RestorePoints      []string          `json:"restorePoints"`
+	KeyID              string            `json:"keyID,omitempty"`
+	RestorePointKeyIDs map[string]string `json:"restorePointKeyIDs,omitempty"`
+}
+
+// setExportResult records the backup written by the given successful export Job, and the restore points available
+// after it, in the Status of the ArgoCDExport. The previous result is kept if the Job did not report one, e.g.
+// because its Pod has been deleted.
+func (r *ReconcileArgoCDExport) setExportResult(cr *argoproj.ArgoCDExport, job *batchv1.Job) {
+	result := r.jobExportResult(job)
+	if result == nil {
+		if len(cr.Status.LastBackupLocation) == 0 {
+			// Export images that predate timestamped backups always write the same file.
+			cr.Status.LastBackupLocation = r.backupLocation(cr) + "/" + common.ArgoCDExportBackupFileName
+		}
+		return
+	}
+
+	location := r.backupLocation(cr)
+	cr.Status.LastBackupID = result.BackupID
+	cr.Status.LastBackupLocation = location + "/" + backupName(result.BackupID)
+
+	restorePoints := make([]argoproj.ArgoCDExportRestorePoint, 0, len(result.RestorePoints))
+	for _, id := range result.RestorePoints {
+		created, err := time.Parse(common.ArgoCDExportBackupIDFormat, id)
+		if err != nil {
+			log.Info("ignoring malformed restore point", "backupID", id, "job", job.Name)
+			continue
+		}
+		keyID, ok := result.RestorePointKeyIDs[id]
+		if !ok {
+			keyID = result.KeyID
+		}
+		restorePoints = append(restorePoints, argoproj.ArgoCDExportRestorePoint{
+			BackupID: id,
+			KeyID:    keyID,
+			// Times decoded from the API server are local, keep them comparable with the existing status.
+			CreationTime: metav1.NewTime(created.Local()),
+			Location:     location + "/" + backupName(id),
+		})
+	}
+	cr.Status.RestorePoints = restorePoints
+}
+
+// jobExportResult returns the result reported by the export container of the given Job, or nil if no Pod of the Job
+// reported one.
+func (r *ReconcileArgoCDExport) jobExportResult(job *batchv1.Job) *exportResult {
+	pods := &corev1.PodList{}
+	if err := r.Client.List(context.TODO(), pods, client.InNamespace(job.Namespace), client.MatchingLabels{"job-name": job.Name}); err != nil {
+		log.Error(err, "failed to list export job pods", "job", job.Name)
+		return nil
+	}
+
+	for _, pod := range pods.Items {
+		for _, status := range pod.Status.ContainerStatuses {
+			terminated := status.State.Terminated
+			if status.Name != exportContainerName || terminated == nil || terminated.ExitCode != 0 || len(terminated.Message) == 0 {
+				continue
+			}
+			result := &exportResult{}
+			if err := json.Unmarshal([]byte(terminated.Message), result); err != nil || len(result.BackupID) == 0 {
+				log.Info("ignoring malformed export result", "pod", pod.Name)
+				continue
+			}
+			return result
+		}
+	}
+	return nil
+}
+
+// backupName returns the name of the backup with the given ID in the storage backend.
+func backupName(id string) string {
+	return common.ArgoCDExportBackupFilePrefix + id + common.ArgoCDExportBackupFileSuffix
+}
+
+// backupLocation returns a URI describing where the export process stores the backups for the given ArgoCDExport.
+func (r *ReconcileArgoCDExport) backupLocation(cr *argoproj.ArgoCDExport) string {
+	backend := common.ArgoCDExportStorageBackendLocal
+	if cr.Spec.Storage != nil && len(cr.Spec.Storage.Backend) > 0 {
+		backend = strings.ToLower(cr.Spec.Storage.Backend)
+	}
+
+	secret := &corev1.Secret{}
+	if backend != common.ArgoCDExportStorageBackendLocal && backend != common.ArgoCDExportStorageBackendS3 {
+		if err := argoutil.FetchObject(r.Client, cr.Namespace, argoutil.FetchStorageSecretName(cr), secret); err != nil {
+			log.Error(err, "failed to fetch export storage secret")
+		}
+	}
+
+	switch backend {
+	case common.ArgoCDExportStorageBackendAWS:
+		return fmt.Sprintf("s3://%s", secret.Data["aws.bucket.name"])
+	case common.ArgoCDExportStorageBackendAzure:
+		return fmt.Sprintf("azure://%s/%s", secret.Data["azure.storage.account"], secret.Data["azure.container.name"])
+	case common.ArgoCDExportStorageBackendGCP:
+		return fmt.Sprintf("gs://%s", secret.Data["gcp.bucket.name"])
+	case common.ArgoCDExportStorageBackendS3:
+		if cr.Spec.Storage.S3 == nil {
+			return ""
+		}
+		return fmt.Sprintf("%s/%s", strings.TrimSuffix(cr.Spec.Storage.S3.Endpoint, "/"), cr.Spec.Storage.S3.Bucket)
+	default:
+		return fmt.Sprintf("pvc://%s", cr.Name)
+	}
+}
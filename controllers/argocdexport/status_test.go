@@ -0,0 +1,305 @@
+package argocdexport
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	argoproj "github.com/argoproj-labs/argocd-operator/api/v1alpha1"
+	"github.com/argoproj-labs/argocd-operator/common"
+)
+
+func makeTestArgoCDExport(opts ...func(*argoproj.ArgoCDExport)) *argoproj.ArgoCDExport {
+	a := &argoproj.ArgoCDExport{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-export",
+			Namespace: "argocd",
+		},
+		Spec: argoproj.ArgoCDExportSpec{
+			Argocd: "argocd",
+			Storage: &argoproj.ArgoCDExportStorageSpec{
+				Backend: common.ArgoCDExportStorageBackendLocal,
+			},
+		},
+	}
+	for _, o := range opts {
+		o(a)
+	}
+	return a
+}
+
+func makeTestArgoCD(name string) *argoproj.ArgoCD {
+	return &argoproj.ArgoCD{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "argocd",
+		},
+	}
+}
+
+func makeTestReconciler(t *testing.T, objs ...client.Object) *ReconcileArgoCDExport {
+	s := scheme.Scheme
+	assert.NoError(t, argoproj.AddToScheme(s))
+
+	cl := fake.NewClientBuilder().
+		WithScheme(s).
+		WithObjects(objs...).
+		WithStatusSubresource(&argoproj.ArgoCDExport{}, &batchv1.Job{}).
+		Build()
+
+	return &ReconcileArgoCDExport{
+		Client: cl,
+		Scheme: s,
+	}
+}
+
+func TestReconcileArgoCDExport_resolveArgoCD(t *testing.T) {
+	otherNamespace := makeTestArgoCD("argocd")
+	otherNamespace.Namespace = "other"
+
+	tests := []struct {
+		name          string
+		objs          []client.Object
+		namespace     string
+		wantName      string
+		wantNamespace string
+		wantReason    string
+	}{
+		{
+			name:          "named instance",
+			objs:          []client.Object{makeTestArgoCD("argocd"), makeTestArgoCD("other")},
+			wantName:      "argocd",
+			wantNamespace: "argocd",
+			wantReason:    argoproj.ArgoCDExportReasonArgoCDFound,
+		},
+		{
+			name:          "single instance with a different name",
+			objs:          []client.Object{makeTestArgoCD("other")},
+			wantName:      "other",
+			wantNamespace: "argocd",
+			wantReason:    argoproj.ArgoCDExportReasonArgoCDFound,
+		},
+		{
+			name:       "no instance",
+			wantReason: argoproj.ArgoCDExportReasonArgoCDNotFound,
+		},
+		{
+			name:       "several instances without a match",
+			objs:       []client.Object{makeTestArgoCD("one"), makeTestArgoCD("two")},
+			wantReason: argoproj.ArgoCDExportReasonAmbiguousArgoCD,
+		},
+		{
+			name:          "named instance in another namespace",
+			objs:          []client.Object{makeTestArgoCD("argocd"), otherNamespace},
+			namespace:     "other",
+			wantName:      "argocd",
+			wantNamespace: "other",
+			wantReason:    argoproj.ArgoCDExportReasonArgoCDFound,
+		},
+		{
+			name:       "no fallback with an explicit namespace",
+			objs:       []client.Object{makeTestArgoCD("other")},
+			namespace:  "argocd",
+			wantReason: argoproj.ArgoCDExportReasonArgoCDNotFound,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cr := makeTestArgoCDExport()
+			if len(test.namespace) > 0 {
+				cr.Spec.ArgocdNamespace = &test.namespace
+			}
+			r := makeTestReconciler(t, test.objs...)
+
+			instance, err := r.resolveArgoCD(cr)
+			if len(test.wantName) == 0 {
+				assert.Error(t, err)
+				assert.Nil(t, instance)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, test.wantName, instance.Name)
+				assert.Equal(t, test.wantNamespace, instance.Namespace)
+			}
+
+			c := meta.FindStatusCondition(cr.Status.Conditions, argoproj.ArgoCDExportConditionArgoCDResolved)
+			assert.NotNil(t, c)
+			assert.Equal(t, test.wantReason, c.Reason)
+		})
+	}
+}
+
+func TestReconcileArgoCDExport_reconcileExportStatus(t *testing.T) {
+	cr := makeTestArgoCDExport()
+	r := makeTestReconciler(t, cr, makeTestArgoCD("argocd"))
+
+	// no job yet
+	assert.NoError(t, r.reconcileExportStatus(cr, cr.Status.DeepCopy()))
+	assert.Equal(t, common.ArgoCDStatusPending, cr.Status.Phase)
+	assert.True(t, meta.IsStatusConditionFalse(cr.Status.Conditions, argoproj.ArgoCDExportConditionComplete))
+
+	// job running
+	assert.NoError(t, r.reconcileJob(cr, makeTestArgoCD("argocd")))
+	job := &batchv1.Job{}
+	assert.NoError(t, r.Client.Get(context.TODO(), client.ObjectKey{Namespace: cr.Namespace, Name: cr.Name}, job))
+	start := metav1.NewTime(time.Now().Add(-time.Minute).Truncate(time.Second))
+	job.Status.StartTime = &start
+	assert.NoError(t, r.Client.Status().Update(context.TODO(), job))
+
+	assert.NoError(t, r.reconcileExportStatus(cr, cr.Status.DeepCopy()))
+	assert.Equal(t, common.ArgoCDStatusRunning, cr.Status.Phase)
+	assert.Equal(t, &start, cr.Status.LastRunStartTime)
+
+	// job failed
+	job.Status.Conditions = []batchv1.JobCondition{{
+		Type:    batchv1.JobFailed,
+		Status:  corev1.ConditionTrue,
+		Reason:  "BackoffLimitExceeded",
+		Message: "Job has reached the specified backoff limit",
+	}}
+	assert.NoError(t, r.Client.Status().Update(context.TODO(), job))
+
+	assert.NoError(t, r.reconcileExportStatus(cr, cr.Status.DeepCopy()))
+	assert.Equal(t, common.ArgoCDStatusFailed, cr.Status.Phase)
+	failed := meta.FindStatusCondition(cr.Status.Conditions, argoproj.ArgoCDExportConditionFailed)
+	assert.Equal(t, metav1.ConditionTrue, failed.Status)
+	assert.Contains(t, failed.Message, "BackoffLimitExceeded")
+	assert.Empty(t, cr.Status.LastBackupLocation)
+
+	// job succeeded
+	completion := metav1.NewTime(time.Now().Truncate(time.Second))
+	job.Status.CompletionTime = &completion
+	job.Status.Conditions = []batchv1.JobCondition{{
+		Type:   batchv1.JobComplete,
+		Status: corev1.ConditionTrue,
+	}}
+	assert.NoError(t, r.Client.Status().Update(context.TODO(), job))
+
+	assert.NoError(t, r.reconcileExportStatus(cr, cr.Status.DeepCopy()))
+	assert.Equal(t, common.ArgoCDStatusCompleted, cr.Status.Phase)
+	assert.True(t, meta.IsStatusConditionTrue(cr.Status.Conditions, argoproj.ArgoCDExportConditionComplete))
+	assert.True(t, meta.IsStatusConditionFalse(cr.Status.Conditions, argoproj.ArgoCDExportConditionFailed))
+	assert.Equal(t, &completion, cr.Status.LastSuccessfulRunTime)
+	assert.Equal(t, "pvc://test-export/argocd-backup.yaml", cr.Status.LastBackupLocation)
+
+	persisted := &argoproj.ArgoCDExport{}
+	assert.NoError(t, r.Client.Get(context.TODO(), client.ObjectKeyFromObject(cr), persisted))
+	assert.Equal(t, common.ArgoCDStatusCompleted, persisted.Status.Phase)
+}
+
+func TestReconcileArgoCDExport_reconcileExportStatus_RestorePoints(t *testing.T) {
+	cr := makeTestArgoCDExport(func(cr *argoproj.ArgoCDExport) {
+		keepLast := int32(2)
+		cr.Spec.Retention = &argoproj.ArgoCDExportRetentionSpec{
+			KeepLast: &keepLast,
+			MaxAge:   &metav1.Duration{Duration: 30 * 24 * time.Hour},
+		}
+	})
+	r := makeTestReconciler(t, cr, makeTestArgoCD("argocd"))
+
+	assert.NoError(t, r.reconcileJob(cr, makeTestArgoCD("argocd")))
+	job := &batchv1.Job{}
+	assert.NoError(t, r.Client.Get(context.TODO(), client.ObjectKey{Namespace: cr.Namespace, Name: cr.Name}, job))
+	assert.Contains(t, job.Spec.Template.Spec.Containers[0].Env, corev1.EnvVar{Name: common.ArgoCDExportRetentionKeepLastEnvName, Value: "2"})
+	assert.Contains(t, job.Spec.Template.Spec.Containers[0].Env, corev1.EnvVar{Name: common.ArgoCDExportRetentionMaxAgeEnvName, Value: "720h0m0s"})
+
+	job.Status.Conditions = []batchv1.JobCondition{{
+		Type:   batchv1.JobComplete,
+		Status: corev1.ConditionTrue,
+	}}
+	assert.NoError(t, r.Client.Status().Update(context.TODO(), job))
+
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      cr.Name + "-x7k2p",
+			Namespace: cr.Namespace,
+			Labels:    map[string]string{"job-name": job.Name},
+		},
+		Status: corev1.PodStatus{
+			ContainerStatuses: []corev1.ContainerStatus{{
+				Name: "argocd-export",
+				State: corev1.ContainerState{
+					Terminated: &corev1.ContainerStateTerminated{
+						Message: `{"backupID":"20231017T120000Z","restorePoints":["20231017T120000Z","20231016T120000Z"],"keyID":"5f2b6c1d8e9a0b3c","restorePointKeyIDs":{"20231016T120000Z":"0a1b2c3d4e5f6a7b"}}`,
+					},
+				},
+			}},
+		},
+	}
+	assert.NoError(t, r.Client.Create(context.TODO(), pod))
+
+	assert.NoError(t, r.reconcileExportStatus(cr, cr.Status.DeepCopy()))
+	assert.Equal(t, "20231017T120000Z", cr.Status.LastBackupID)
+	assert.Equal(t, "pvc://test-export/argocd-backup-20231017T120000Z.yaml", cr.Status.LastBackupLocation)
+	assert.Equal(t, []argoproj.ArgoCDExportRestorePoint{
+		{
+			BackupID:     "20231017T120000Z",
+			CreationTime: metav1.NewTime(time.Date(2023, 10, 17, 12, 0, 0, 0, time.UTC).Local()),
+			Location:     "pvc://test-export/argocd-backup-20231017T120000Z.yaml",
+			KeyID:        "5f2b6c1d8e9a0b3c",
+		},
+		{
+			BackupID:     "20231016T120000Z",
+			CreationTime: metav1.NewTime(time.Date(2023, 10, 16, 12, 0, 0, 0, time.UTC).Local()),
+			Location:     "pvc://test-export/argocd-backup-20231016T120000Z.yaml",
+			KeyID:        "0a1b2c3d4e5f6a7b",
+		},
+	}, cr.Status.RestorePoints)
+
+	// The restore points are kept once the pod is gone.
+	assert.NoError(t, r.Client.Delete(context.TODO(), pod))
+	assert.NoError(t, r.reconcileExportStatus(cr, cr.Status.DeepCopy()))
+	assert.Equal(t, "20231017T120000Z", cr.Status.LastBackupID)
+	assert.Len(t, cr.Status.RestorePoints, 2)
+}
+
+func TestReconcileArgoCDExport_lastExportJob_Scheduled(t *testing.T) {
+	schedule := "0 * * * *"
+	cr := makeTestArgoCDExport(func(a *argoproj.ArgoCDExport) {
+		a.Spec.Schedule = &schedule
+	})
+
+	newCronRun := func(name string, created time.Time) *batchv1.Job {
+		isController := true
+		return &batchv1.Job{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              name,
+				Namespace:         cr.Namespace,
+				CreationTimestamp: metav1.NewTime(created),
+				OwnerReferences: []metav1.OwnerReference{{
+					APIVersion: "batch/v1",
+					Kind:       "CronJob",
+					Name:       cr.Name,
+					Controller: &isController,
+				}},
+			},
+		}
+	}
+
+	now := time.Now()
+	objs := []runtime.Object{
+		newCronRun("test-export-1", now.Add(-2*time.Hour)),
+		newCronRun("test-export-2", now.Add(-time.Hour)),
+		&batchv1.Job{ObjectMeta: metav1.ObjectMeta{Name: "unrelated", Namespace: cr.Namespace, CreationTimestamp: metav1.NewTime(now)}},
+	}
+	s := scheme.Scheme
+	assert.NoError(t, argoproj.AddToScheme(s))
+	r := &ReconcileArgoCDExport{
+		Client: fake.NewClientBuilder().WithScheme(s).WithRuntimeObjects(objs...).Build(),
+		Scheme: s,
+	}
+
+	job, err := r.lastExportJob(cr)
+	assert.NoError(t, err)
+	assert.Equal(t, "test-export-2", job.Name)
+}
@@ -0,0 +1,185 @@
+// Copyright 2023 ArgoCD Operator Developers
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package argoutil
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+
+	argoprojv1alpha1 "github.com/argoproj-labs/argocd-operator/api/v1alpha1"
+	"github.com/argoproj-labs/argocd-operator/common"
+)
+
+const s3CABundleVolumeName = "s3-ca-bundle"
+
+// s3Spec returns the S3 compatible storage options of the given ArgoCDExport, or nil if the export
+// does not use the "s3" backend.
+func s3Spec(export *argoprojv1alpha1.ArgoCDExport) *argoprojv1alpha1.ArgoCDExportS3Spec {
+	if export.Spec.Storage == nil || strings.ToLower(export.Spec.Storage.Backend) != common.ArgoCDExportStorageBackendS3 {
+		return nil
+	}
+	return export.Spec.Storage.S3
+}
+
+// GetS3StorageEnv will return the environment variables that configure the S3 compatible storage backend
+// for the export and import processes of the given ArgoCDExport.
+func GetS3StorageEnv(export *argoprojv1alpha1.ArgoCDExport) []corev1.EnvVar {
+	env := make([]corev1.EnvVar, 0)
+
+	secretKeyRef := func(key string) *corev1.EnvVarSource {
+		return &corev1.EnvVarSource{
+			SecretKeyRef: &corev1.SecretKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{
+					Name: FetchStorageSecretName(export),
+				},
+				Key: key,
+			},
+		}
+	}
+	env = append(env, corev1.EnvVar{Name: "AWS_ACCESS_KEY_ID", ValueFrom: secretKeyRef("s3.access.key.id")})
+	env = append(env, corev1.EnvVar{Name: "AWS_SECRET_ACCESS_KEY", ValueFrom: secretKeyRef("s3.secret.access.key")})
+
+	spec := s3Spec(export)
+	if spec == nil {
+		return env
+	}
+
+	env = append(env, corev1.EnvVar{Name: common.ArgoCDExportS3EndpointEnvName, Value: spec.Endpoint})
+	env = append(env, corev1.EnvVar{Name: common.ArgoCDExportS3BucketEnvName, Value: spec.Bucket})
+	if len(spec.Region) > 0 {
+		env = append(env, corev1.EnvVar{Name: common.ArgoCDExportS3RegionEnvName, Value: spec.Region})
+	}
+	env = append(env, corev1.EnvVar{Name: common.ArgoCDExportS3ForcePathStyleEnvName, Value: strconv.FormatBool(spec.ForcePathStyle)})
+	if spec.CABundle != nil {
+		env = append(env, corev1.EnvVar{
+			Name:  common.ArgoCDExportS3CABundleEnvName,
+			Value: filepath.Join(common.ArgoCDExportS3CABundleMountPath, common.ArgoCDExportS3CABundleFileName),
+		})
+	}
+	if sse := spec.ServerSideEncryption; sse != nil {
+		env = append(env, corev1.EnvVar{Name: common.ArgoCDExportS3SSEEnvName, Value: sse.Type})
+		if len(sse.KMSKeyID) > 0 {
+			env = append(env, corev1.EnvVar{Name: common.ArgoCDExportS3SSEKMSKeyIDEnvName, Value: sse.KMSKeyID})
+		}
+	}
+
+	return env
+}
+
+// GetS3StorageVolumes will return the Volumes needed by the S3 compatible storage backend of the given
+// ArgoCDExport, that is the CA bundle when one is configured.
+func GetS3StorageVolumes(export *argoprojv1alpha1.ArgoCDExport) []corev1.Volume {
+	volumes := make([]corev1.Volume, 0)
+
+	spec := s3Spec(export)
+	if spec == nil || spec.CABundle == nil {
+		return volumes
+	}
+
+	// The default mode is set explicitly, so that the Volume matches the one returned by the API server.
+	defaultMode := corev1.ConfigMapVolumeSourceDefaultMode
+	volumes = append(volumes, corev1.Volume{
+		Name: s3CABundleVolumeName,
+		VolumeSource: corev1.VolumeSource{
+			ConfigMap: &corev1.ConfigMapVolumeSource{
+				LocalObjectReference: spec.CABundle.LocalObjectReference,
+				Items: []corev1.KeyToPath{{
+					Key:  spec.CABundle.Key,
+					Path: common.ArgoCDExportS3CABundleFileName,
+				}},
+				Optional:    spec.CABundle.Optional,
+				DefaultMode: &defaultMode,
+			},
+		},
+	})
+
+	return volumes
+}
+
+// GetS3StorageVolumeMounts will return the VolumeMounts matching GetS3StorageVolumes.
+func GetS3StorageVolumeMounts(export *argoprojv1alpha1.ArgoCDExport) []corev1.VolumeMount {
+	mounts := make([]corev1.VolumeMount, 0)
+
+	spec := s3Spec(export)
+	if spec == nil || spec.CABundle == nil {
+		return mounts
+	}
+
+	mounts = append(mounts, corev1.VolumeMount{
+		Name:      s3CABundleVolumeName,
+		MountPath: common.ArgoCDExportS3CABundleMountPath,
+		ReadOnly:  true,
+	})
+
+	return mounts
+}
+
+// GetEncryptionEnv will return the environment variables that configure the KMS used for the envelope encryption of
+// backups, for the export and import processes of the given ArgoCDExport.
+func GetEncryptionEnv(export *argoprojv1alpha1.ArgoCDExport) []corev1.EnvVar {
+	env := make([]corev1.EnvVar, 0)
+
+	if export.Spec.Encryption == nil || export.Spec.Encryption.KMS == nil {
+		return env
+	}
+
+	kms := export.Spec.Encryption.KMS
+	env = append(env, corev1.EnvVar{Name: common.ArgoCDExportKMSKeyIDEnvName, Value: kms.KeyID})
+	if len(kms.Region) > 0 {
+		env = append(env, corev1.EnvVar{Name: common.ArgoCDExportKMSRegionEnvName, Value: kms.Region})
+	}
+	if len(kms.Endpoint) > 0 {
+		env = append(env, corev1.EnvVar{Name: common.ArgoCDExportKMSEndpointEnvName, Value: kms.Endpoint})
+	}
+
+	return env
+}
+
+// IsLegacyExportImage returns true if the given export container image is the last one running util.sh, which predates
+// argocd-operator-util and must be started through uid_entrypoint.sh.
+func IsLegacyExportImage(image string) bool {
+	return strings.HasSuffix(image, "@"+common.ArgoCDLegacyExportJobVersion)
+}
+
+// LegacyExportUnsupportedOptions returns the options of the given ArgoCDExport that the legacy export image cannot
+// write or read backups with.
+func LegacyExportUnsupportedOptions(export *argoprojv1alpha1.ArgoCDExport) []string {
+	unsupported := make([]string, 0)
+	if export.Spec.Storage != nil && strings.ToLower(export.Spec.Storage.Backend) == common.ArgoCDExportStorageBackendS3 {
+		unsupported = append(unsupported, "the s3 storage backend")
+	}
+	if export.Spec.Encryption != nil && export.Spec.Encryption.KMS != nil {
+		unsupported = append(unsupported, "spec.encryption.kms")
+	}
+	return unsupported
+}
+
+// BackupKeyID will return the ID of the given backup key: a truncated HMAC-SHA256 of a fixed label keyed by the first
+// line of the backup key, which identifies the key without revealing it. It must match BackupKeyID in pkg/backup.
+func BackupKeyID(backupKey []byte) string {
+	if i := bytes.IndexAny(backupKey, "\r\n"); i >= 0 {
+		backupKey = backupKey[:i]
+	}
+	mac := hmac.New(sha256.New, backupKey)
+	mac.Write([]byte(common.ArgoCDExportBackupKeyIDLabel))
+	return hex.EncodeToString(mac.Sum(nil)[:8])
+}
@@ -52,6 +52,24 @@ func CombineImageTag(img string, tag string) string {
 	return img // No tag, use default
 }
 
+// ReplaceImageRegistry will return the given image reference pulled from the given registry instead of its own, keeping
+// its repository path. Images without a registry are considered to be on Docker Hub. The image is returned unchanged
+// when the registry is empty.
+func ReplaceImageRegistry(img string, registry string) string {
+	registry = strings.TrimSuffix(registry, "/")
+	if registry == "" {
+		return img
+	}
+
+	repository := img
+	if i := strings.Index(img, "/"); i < 0 {
+		repository = "library/" + img // Docker Hub official image
+	} else if host := img[:i]; strings.ContainsAny(host, ".:") || host == "localhost" {
+		repository = img[i+1:]
+	}
+	return fmt.Sprintf("%s/%s", registry, repository)
+}
+
 // CreateEvent will create a new Kubernetes Event with the given action, message, reason and involved uid.
 func CreateEvent(client client.Client, eventType, action, message, reason string, objectMeta metav1.ObjectMeta, typeMeta metav1.TypeMeta) error {
 	event := newEvent(objectMeta)
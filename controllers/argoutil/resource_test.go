@@ -57,3 +57,26 @@ func TestDefaultAnnotations(t *testing.T) {
 		})
 	}
 }
+
+func TestReplaceImageRegistry(t *testing.T) {
+	tests := []struct {
+		name     string
+		img      string
+		registry string
+		want     string
+	}{
+		{"no registry", "quay.io/argoproj/argocd:v2.8.3", "", "quay.io/argoproj/argocd:v2.8.3"},
+		{"registry host", "quay.io/argoproj/argocd:v2.8.3", "mirror.example.com", "mirror.example.com/argoproj/argocd:v2.8.3"},
+		{"registry with path", "ghcr.io/dexidp/dex@sha256:abc", "mirror.example.com/ghcr/", "mirror.example.com/ghcr/dexidp/dex@sha256:abc"},
+		{"registry with port", "localhost:5000/argocd:latest", "mirror.example.com", "mirror.example.com/argocd:latest"},
+		{"docker hub image", "haproxy:2.6", "mirror.example.com", "mirror.example.com/library/haproxy:2.6"},
+		{"docker hub repository", "argoproj/argocd:v2.8.3", "mirror.example.com", "mirror.example.com/argoproj/argocd:v2.8.3"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ReplaceImageRegistry(tt.img, tt.registry); got != tt.want {
+				t.Errorf("ReplaceImageRegistry() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
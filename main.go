@@ -20,9 +20,11 @@ import (
 	"crypto/tls"
 	"flag"
 	"fmt"
+	"math"
 	"os"
 	goruntime "runtime"
 	"strings"
+	"time"
 
 	"github.com/argoproj/argo-cd/v2/util/env"
 	monitoringv1 "github.com/coreos/prometheus-operator/pkg/apis/monitoring/v1"
@@ -86,6 +88,12 @@ func main() {
 	var enableLeaderElection bool
 	var probeAddr string
 	var labelSelectorFlag string
+	var maxConcurrentReconciles int
+	var reconcileBaseDelay time.Duration
+	var reconcileMaxDelay time.Duration
+	var reconcileQPS float64
+	var reconcileBurst int
+	var requeueAfter time.Duration
 
 	var secureMetrics = false
 	var enableHTTP2 = false
@@ -93,6 +101,12 @@ func main() {
 	flag.StringVar(&metricsAddr, "metrics-bind-address", fmt.Sprintf(":%d", common.OperatorMetricsPort), "The address the metric endpoint binds to.")
 	flag.StringVar(&probeAddr, "health-probe-bind-address", ":8081", "The address the probe endpoint binds to.")
 	flag.StringVar(&labelSelectorFlag, "label-selector", env.StringFromEnv(common.ArgoCDLabelSelectorKey, common.ArgoCDDefaultLabelSelector), "The label selector is used to map to a subset of ArgoCD instances to reconcile")
+	flag.IntVar(&maxConcurrentReconciles, "max-concurrent-reconciles", env.ParseNumFromEnv(common.ArgoCDMaxConcurrentReconcilesEnvName, common.ArgoCDDefaultMaxConcurrentReconciles, 1, math.MaxInt32), "The maximum number of ArgoCD instances that are reconciled concurrently.")
+	flag.DurationVar(&reconcileBaseDelay, "reconcile-base-delay", env.ParseDurationFromEnv(common.ArgoCDReconcileBaseDelayEnvName, common.ArgoCDDefaultReconcileBaseDelay, 0, math.MaxInt64), "The delay before retrying a failed reconciliation of an ArgoCD instance, doubled on every consecutive failure.")
+	flag.DurationVar(&reconcileMaxDelay, "reconcile-max-delay", env.ParseDurationFromEnv(common.ArgoCDReconcileMaxDelayEnvName, common.ArgoCDDefaultReconcileMaxDelay, 0, math.MaxInt64), "The maximum delay before retrying a failed reconciliation of an ArgoCD instance.")
+	flag.Float64Var(&reconcileQPS, "reconcile-qps", float64(env.ParseFloatFromEnv(common.ArgoCDReconcileQPSEnvName, common.ArgoCDDefaultReconcileQPS, 0, math.MaxFloat32)), "The overall number of reconciliations of ArgoCD instances per second.")
+	flag.IntVar(&reconcileBurst, "reconcile-burst", env.ParseNumFromEnv(common.ArgoCDReconcileBurstEnvName, common.ArgoCDDefaultReconcileBurst, 1, math.MaxInt32), "The burst of reconciliations of ArgoCD instances allowed above the QPS.")
+	flag.DurationVar(&requeueAfter, "reconcile-requeue-after", env.ParseDurationFromEnv(common.ArgoCDRequeueAfterEnvName, common.ArgoCDDefaultRequeueAfter, 0, math.MaxInt64), "The interval after which ArgoCD instances are reconciled again, 0 to only reconcile them on changes. It is overridden by the "+common.ArgoCDRequeueAfterAnnotation+" annotation of an instance.")
 	flag.BoolVar(&enableLeaderElection, "leader-elect", false,
 		"Enable leader election for controller manager. "+
 			"Enabling this will ensure there is only one active controller manager.")
@@ -221,9 +235,13 @@ func main() {
 	}
 
 	if err = (&argocd.ReconcileArgoCD{
-		Client:        mgr.GetClient(),
-		Scheme:        mgr.GetScheme(),
-		LabelSelector: labelSelectorFlag,
+		Client:                  mgr.GetClient(),
+		Scheme:                  mgr.GetScheme(),
+		LabelSelector:           labelSelectorFlag,
+		Instances:               argocd.NewInstanceRegistry(),
+		MaxConcurrentReconciles: maxConcurrentReconciles,
+		RateLimiter:             argocd.NewRateLimiter(reconcileBaseDelay, reconcileMaxDelay, float32(reconcileQPS), reconcileBurst),
+		RequeueAfter:            requeueAfter,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "ArgoCD")
 		os.Exit(1)
@@ -236,12 +254,16 @@ func main() {
 		os.Exit(1)
 	}
 
-	// Start webhook only if ENABLE_CONVERSION_WEBHOOK is set
+	// Start webhooks (conversion, defaulting and validation) only if ENABLE_CONVERSION_WEBHOOK is set
 	if strings.EqualFold(os.Getenv("ENABLE_CONVERSION_WEBHOOK"), "true") {
 		if err = (&v1beta1.ArgoCD{}).SetupWebhookWithManager(mgr); err != nil {
 			setupLog.Error(err, "unable to create webhook", "webhook", "ArgoCD")
 			os.Exit(1)
 		}
+		if err = (&v1alpha1.ArgoCDExport{}).SetupWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "ArgoCDExport")
+			os.Exit(1)
+		}
 	}
 	//+kubebuilder:scaffold:builder
 
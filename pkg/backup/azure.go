@@ -0,0 +1,355 @@
+// Copyright 2023 ArgoCD Operator Developers
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backup
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	azureAPIVersion        = "2021-08-06"
+	azureDefaultAuthority  = "https://login.microsoftonline.com"
+	azureStorageScope      = "https://storage.azure.com/.default"
+	azureAssertionType     = "urn:ietf:params:oauth:client-assertion-type:jwt-bearer"
+	azureAssertionLifetime = 10 * time.Minute
+)
+
+// AzureStorage stores backups in an Azure Blob Storage container, authenticating as a service
+// principal with a client certificate.
+type AzureStorage struct {
+	// BaseURL is the blob service URL of the storage account, e.g. https://account.blob.core.windows.net.
+	BaseURL   string
+	Container string
+
+	// Authority is the Azure AD authority used to obtain tokens.
+	Authority string
+	TenantID  string
+	ClientID  string
+
+	Certificate *x509.Certificate
+	PrivateKey  *rsa.PrivateKey
+
+	HTTPClient *http.Client
+
+	token       string
+	tokenExpiry time.Time
+}
+
+// NewAzureStorage returns an AzureStorage configured from the export Secret.
+func NewAzureStorage(secrets Secrets) (*AzureStorage, error) {
+	values := map[string]string{}
+	for _, key := range []string{"azure.storage.account", "azure.service.id", "azure.service.cert", "azure.tenant.id", "azure.container.name"} {
+		value, err := secrets.Require(key)
+		if err != nil {
+			return nil, err
+		}
+		values[key] = value
+	}
+
+	cert, key, err := parseCertificateAndKey([]byte(values["azure.service.cert"]))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse azure.service.cert: %w", err)
+	}
+
+	return &AzureStorage{
+		BaseURL:     fmt.Sprintf("https://%s.blob.core.windows.net", values["azure.storage.account"]),
+		Container:   values["azure.container.name"],
+		Authority:   azureDefaultAuthority,
+		TenantID:    values["azure.tenant.id"],
+		ClientID:    values["azure.service.id"],
+		Certificate: cert,
+		PrivateKey:  key,
+		HTTPClient:  http.DefaultClient,
+	}, nil
+}
+
+// Put implements Storage. The container is created if it does not exist yet.
+func (s *AzureStorage) Put(ctx context.Context, name string, data []byte) error {
+	resp, err := s.do(ctx, http.MethodPut, s.BaseURL+"/"+url.PathEscape(s.Container)+"?restype=container", nil, nil)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusConflict {
+		return fmt.Errorf("failed to create container %s: unexpected status %s", s.Container, resp.Status)
+	}
+
+	resp, err = s.do(ctx, http.MethodPut, s.blobURL(name), data, map[string]string{"x-ms-blob-type": "BlockBlob"})
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated {
+		return azureError(resp, "failed to upload backup")
+	}
+	return nil
+}
+
+// Get implements Storage.
+func (s *AzureStorage) Get(ctx context.Context, name string) ([]byte, error) {
+	resp, err := s.do(ctx, http.MethodGet, s.blobURL(name), nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, ErrNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, azureError(resp, "failed to download backup")
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// List implements Storage. A missing container holds no backups.
+func (s *AzureStorage) List(ctx context.Context, prefix string) ([]string, error) {
+	names := make([]string, 0)
+	query := url.Values{"restype": {"container"}, "comp": {"list"}, "prefix": {prefix}}
+	for {
+		resp, err := s.do(ctx, http.MethodGet, s.BaseURL+"/"+url.PathEscape(s.Container)+"?"+query.Encode(), nil, nil)
+		if err != nil {
+			return nil, err
+		}
+		if resp.StatusCode == http.StatusNotFound {
+			resp.Body.Close()
+			return names, nil
+		}
+		if resp.StatusCode != http.StatusOK {
+			resp.Body.Close()
+			return nil, azureError(resp, "failed to list backups")
+		}
+
+		var result struct {
+			Blobs struct {
+				Blob []struct {
+					Name string `xml:"Name"`
+				} `xml:"Blob"`
+			} `xml:"Blobs"`
+			NextMarker string `xml:"NextMarker"`
+		}
+		err = xml.NewDecoder(resp.Body).Decode(&result)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode backup list: %w", err)
+		}
+		for _, b := range result.Blobs.Blob {
+			names = append(names, b.Name)
+		}
+		if len(result.NextMarker) == 0 {
+			return names, nil
+		}
+		query.Set("marker", result.NextMarker)
+	}
+}
+
+// Delete implements Storage.
+func (s *AzureStorage) Delete(ctx context.Context, name string) error {
+	resp, err := s.do(ctx, http.MethodDelete, s.blobURL(name), nil, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusAccepted && resp.StatusCode != http.StatusNotFound {
+		return azureError(resp, "failed to delete backup "+name)
+	}
+	return nil
+}
+
+func (s *AzureStorage) blobURL(name string) string {
+	return s.BaseURL + "/" + url.PathEscape(s.Container) + "/" + url.PathEscape(name)
+}
+
+func (s *AzureStorage) do(ctx context.Context, method, u string, body []byte, headers map[string]string) (*http.Response, error) {
+	token, err := s.accessToken(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, u, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.ContentLength = int64(len(body))
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("x-ms-version", azureAPIVersion)
+	req.Header.Set("x-ms-date", time.Now().UTC().Format(http.TimeFormat))
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	return s.HTTPClient.Do(req)
+}
+
+// accessToken returns a cached access token for Azure Storage, or requests a new one with the client
+// credentials flow using a certificate assertion.
+func (s *AzureStorage) accessToken(ctx context.Context) (string, error) {
+	if len(s.token) > 0 && time.Now().Add(time.Minute).Before(s.tokenExpiry) {
+		return s.token, nil
+	}
+
+	tokenURL := fmt.Sprintf("%s/%s/oauth2/v2.0/token", strings.TrimSuffix(s.Authority, "/"), url.PathEscape(s.TenantID))
+	assertion, err := s.clientAssertion(tokenURL)
+	if err != nil {
+		return "", err
+	}
+
+	form := url.Values{
+		"client_id":             {s.ClientID},
+		"scope":                 {azureStorageScope},
+		"grant_type":            {"client_credentials"},
+		"client_assertion_type": {azureAssertionType},
+		"client_assertion":      {assertion},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := s.HTTPClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	var token struct {
+		AccessToken      string `json:"access_token"`
+		ExpiresIn        int64  `json:"expires_in"`
+		ErrorDescription string `json:"error_description"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 64*1024)).Decode(&token); err != nil {
+		return "", fmt.Errorf("failed to decode token response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK || len(token.AccessToken) == 0 {
+		return "", fmt.Errorf("failed to obtain azure access token: %s: %s", resp.Status, token.ErrorDescription)
+	}
+
+	s.token = token.AccessToken
+	s.tokenExpiry = time.Now().Add(time.Duration(token.ExpiresIn) * time.Second)
+	return s.token, nil
+}
+
+// clientAssertion returns a JWT signed with the service principal certificate, as expected by Azure AD.
+func (s *AzureStorage) clientAssertion(audience string) (string, error) {
+	thumbprint := sha1.Sum(s.Certificate.Raw)
+	header, err := json.Marshal(map[string]string{
+		"alg": "RS256",
+		"typ": "JWT",
+		"x5t": base64.RawURLEncoding.EncodeToString(thumbprint[:]),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	jti := make([]byte, 16)
+	if _, err := io.ReadFull(rand.Reader, jti); err != nil {
+		return "", err
+	}
+	now := time.Now()
+	claims, err := json.Marshal(map[string]interface{}{
+		"aud": audience,
+		"iss": s.ClientID,
+		"sub": s.ClientID,
+		"jti": base64.RawURLEncoding.EncodeToString(jti),
+		"nbf": now.Unix(),
+		"exp": now.Add(azureAssertionLifetime).Unix(),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	signingInput := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(claims)
+	digest := sha256.Sum256([]byte(signingInput))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, s.PrivateKey, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", err
+	}
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+// parseCertificateAndKey parses a PEM bundle holding both the certificate and the RSA private key,
+// as used by "az login --service-principal".
+func parseCertificateAndKey(data []byte) (*x509.Certificate, *rsa.PrivateKey, error) {
+	var (
+		cert *x509.Certificate
+		key  *rsa.PrivateKey
+	)
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			break
+		}
+		switch block.Type {
+		case "CERTIFICATE":
+			if cert != nil {
+				continue
+			}
+			c, err := x509.ParseCertificate(block.Bytes)
+			if err != nil {
+				return nil, nil, err
+			}
+			cert = c
+		case "RSA PRIVATE KEY":
+			k, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+			if err != nil {
+				return nil, nil, err
+			}
+			key = k
+		case "PRIVATE KEY":
+			k, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+			if err != nil {
+				return nil, nil, err
+			}
+			rsaKey, ok := k.(*rsa.PrivateKey)
+			if !ok {
+				return nil, nil, errors.New("private key is not an RSA key")
+			}
+			key = rsaKey
+		}
+	}
+	if cert == nil {
+		return nil, nil, errors.New("no certificate found")
+	}
+	if key == nil {
+		return nil, nil, errors.New("no private key found")
+	}
+	return cert, key, nil
+}
+
+// azureError builds an error from an Azure Storage error response.
+func azureError(resp *http.Response, msg string) error {
+	if code := resp.Header.Get("x-ms-error-code"); len(code) > 0 {
+		return fmt.Errorf("%s: %s: %s", msg, resp.Status, code)
+	}
+	return fmt.Errorf("%s: unexpected status %s", msg, resp.Status)
+}
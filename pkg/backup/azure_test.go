@@ -0,0 +1,107 @@
+package backup
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func makeTestCertificatePEM(t *testing.T) []byte {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "argocd-backup"},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	assert.NoError(t, err)
+
+	bundle := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+	return append(bundle, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})...)
+}
+
+func TestAzureStorage_PutGet(t *testing.T) {
+	cert, key, err := parseCertificateAndKey(makeTestCertificatePEM(t))
+	assert.NoError(t, err)
+
+	blobs := map[string][]byte{}
+	tokenRequests := 0
+	mux := http.NewServeMux()
+	mux.HandleFunc("/tenant/oauth2/v2.0/token", func(w http.ResponseWriter, r *http.Request) {
+		tokenRequests++
+		assert.NoError(t, r.ParseForm())
+		assert.Equal(t, "client", r.Form.Get("client_id"))
+		assert.Equal(t, azureAssertionType, r.Form.Get("client_assertion_type"))
+		assert.Len(t, strings.Split(r.Form.Get("client_assertion"), "."), 3)
+		_, _ = io.WriteString(w, `{"access_token":"token","expires_in":3600}`)
+	})
+	mux.HandleFunc("/backups", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "container", r.URL.Query().Get("restype"))
+		w.WriteHeader(http.StatusConflict)
+	})
+	mux.HandleFunc("/backups/", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+		switch r.Method {
+		case http.MethodPut:
+			assert.Equal(t, "BlockBlob", r.Header.Get("x-ms-blob-type"))
+			blobs[r.URL.Path], _ = io.ReadAll(r.Body)
+			w.WriteHeader(http.StatusCreated)
+		case http.MethodGet:
+			data, ok := blobs[r.URL.Path]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			_, _ = w.Write(data)
+		}
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	s := &AzureStorage{
+		BaseURL:     server.URL,
+		Container:   "backups",
+		Authority:   server.URL,
+		TenantID:    "tenant",
+		ClientID:    "client",
+		Certificate: cert,
+		PrivateKey:  key,
+		HTTPClient:  server.Client(),
+	}
+
+	_, err = s.Get(context.TODO(), "argocd-backup.yaml")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	assert.NoError(t, s.Put(context.TODO(), "argocd-backup.yaml", []byte("encrypted")))
+	data, err := s.Get(context.TODO(), "argocd-backup.yaml")
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("encrypted"), data)
+	assert.Equal(t, 1, tokenRequests)
+}
+
+func TestParseCertificateAndKey_Errors(t *testing.T) {
+	bundle := makeTestCertificatePEM(t)
+	certOnly := bundle[strings.Index(string(bundle), "-----BEGIN CERTIFICATE"):]
+
+	_, _, err := parseCertificateAndKey(certOnly)
+	assert.Error(t, err)
+
+	_, _, err = parseCertificateAndKey([]byte("not a pem"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,428 @@
+// Copyright 2023 ArgoCD Operator Developers
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package backup implements the export and import of Argo CD data for ArgoCDExport resources: the
+// output of "argocd admin export" is encrypted with AES-256-GCM and stored in a pluggable Storage.
+package backup
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"time"
+
+	"github.com/go-logr/logr"
+
+	"github.com/argoproj-labs/argocd-operator/common"
+)
+
+// Exit codes returned by the argocd-operator-util command, so that failures can be told apart from
+// the status of the export Job or the import init container.
+const (
+	ExitOK      = 0
+	ExitUsage   = 2
+	ExitConfig  = 3
+	ExitArgoCD  = 4
+	ExitCrypto  = 5
+	ExitStorage = 6
+)
+
+// Error is an error that carries the exit code of the failed step.
+type Error struct {
+	Code int
+	Err  error
+}
+
+func (e *Error) Error() string {
+	return e.Err.Error()
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// ExitCode returns the exit code for the given error.
+func ExitCode(err error) int {
+	if err == nil {
+		return ExitOK
+	}
+	var e *Error
+	if errors.As(err, &e) {
+		return e.Code
+	}
+	return 1
+}
+
+func newError(code int, format string, args ...interface{}) error {
+	return &Error{Code: code, Err: fmt.Errorf(format, args...)}
+}
+
+// Options configures an export or import.
+type Options struct {
+	// Backend is the storage backend, see NewStorage.
+	Backend string
+
+	// SecretsDir is the directory the export Secret is mounted at.
+	SecretsDir string
+
+	// BackupDir is the directory used by the local storage backend.
+	BackupDir string
+
+	// ArgoCDBinary is the path to the argocd CLI.
+	ArgoCDBinary string
+
+	// Namespace is the namespace of the Argo CD instance to export. The namespace of the Pod is used if empty.
+	Namespace string
+
+	// BackupID is the ID of the backup to import. The most recent backup is imported if empty.
+	BackupID string
+
+	// KeyID is the ID of the backup key expected by the operator. The export fails if the mounted key does not
+	// match, e.g. because a rotated key has not been propagated to the Pod yet.
+	KeyID string
+
+	// Retention is the pruning policy applied to the stored backups after an export.
+	Retention Retention
+
+	// TerminationLog is the file the ExportResult or ImportResult is written to, usually the termination message
+	// path of the container. Nothing is written if empty.
+	TerminationLog string
+
+	// Storage overrides the storage created from Backend, it is only set in tests.
+	Storage Storage
+
+	// Keyring overrides the keyring read from SecretsDir, it is only set in tests.
+	Keyring *Keyring
+}
+
+// maxTerminationMessageLength is the maximum size of a container termination message.
+const maxTerminationMessageLength = 4096
+
+// ExportResult is written to the termination log of the export container, so that the operator can report
+// the new backup and the available restore points in the status of the ArgoCDExport.
+type ExportResult struct {
+	// BackupID is the ID of the backup written by the export.
+	BackupID string `json:"backupID"`
+
+	// RestorePoints are the IDs of the stored backups after pruning, most recent first.
+	RestorePoints []string `json:"restorePoints"`
+
+	// KeyID identifies the key the backup is encrypted with.
+	KeyID string `json:"keyID,omitempty"`
+
+	// RestorePointKeyIDs are the key IDs of the restore points that are not encrypted with KeyID, e.g. because
+	// their re-encryption failed, by backup ID. An empty key ID means that the key is unknown.
+	RestorePointKeyIDs map[string]string `json:"restorePointKeyIDs,omitempty"`
+}
+
+// ImportResult is written to the termination log of the import container, so that the operator can report the
+// restored backup in the status of the ArgoCD.
+type ImportResult struct {
+	// BackupID is the ID of the imported backup, it is empty for backups written by former versions of the export.
+	BackupID string `json:"backupID,omitempty"`
+
+	// Name is the name of the imported backup in the storage backend.
+	Name string `json:"name"`
+}
+
+// Runner performs exports and imports, running the argocd CLI through Command.
+type Runner struct {
+	Options Options
+	Log     logr.Logger
+
+	// Command runs the argocd CLI with the given arguments and stdin, returning its stdout.
+	Command func(ctx context.Context, stdin []byte, args ...string) ([]byte, error)
+
+	// now returns the time backups are named after, it is only overridden in tests.
+	now func() time.Time
+}
+
+// NewRunner returns a Runner that executes the argocd CLI.
+func NewRunner(opts Options, log logr.Logger) *Runner {
+	r := &Runner{Options: opts, Log: log, now: time.Now}
+	r.Command = r.execArgoCD
+	return r
+}
+
+// Export exports the Argo CD data, encrypts it and writes it to the storage backend.
+func (r *Runner) Export(ctx context.Context) error {
+	start := time.Now()
+	log := r.Log.WithValues("action", "export", "backend", r.backend())
+
+	keyring, storage, err := r.setup()
+	if err != nil {
+		return err
+	}
+	if len(r.Options.KeyID) > 0 && keyring.KeyID() != r.Options.KeyID {
+		return newError(ExitConfig, "backup key %s does not match the expected key %s, the export secret may not be up to date yet",
+			keyring.KeyID(), r.Options.KeyID)
+	}
+
+	log.Info("creating argo-cd backup", "namespace", r.Options.Namespace)
+	args := []string{"admin", "export"}
+	if len(r.Options.Namespace) > 0 {
+		args = append(args, "--namespace", r.Options.Namespace)
+	}
+	data, err := r.Command(ctx, nil, args...)
+	if err != nil {
+		return newError(ExitArgoCD, "argocd admin export failed: %w", err)
+	}
+
+	log.Info("encrypting argo-cd backup", "bytes", len(data), "keyID", keyring.KeyID())
+	encrypted, err := keyring.Encrypt(ctx, data)
+	if err != nil {
+		return newError(ExitCrypto, "failed to encrypt backup: %w", err)
+	}
+
+	id := NewBackupID(r.now())
+	log.Info("storing argo-cd backup", "name", BackupName(id), "bytes", len(encrypted))
+	if err := storage.Put(ctx, BackupName(id), encrypted); err != nil {
+		return newError(ExitStorage, "failed to store backup: %w", err)
+	}
+
+	restorePoints := r.prune(ctx, log, storage, id)
+	result := ExportResult{BackupID: id, RestorePoints: restorePoints, KeyID: keyring.KeyID()}
+	if keyring.Rotating {
+		result.RestorePointKeyIDs = r.reencrypt(ctx, log, keyring, storage, restorePoints)
+	}
+	r.writeResult(log, result)
+
+	log.Info("argo-cd export complete", "backupID", id, "restorePoints", len(restorePoints), "duration", time.Since(start).String())
+	return nil
+}
+
+// Import reads the backup from the storage backend, decrypts it and imports it into Argo CD.
+func (r *Runner) Import(ctx context.Context) error {
+	start := time.Now()
+	log := r.Log.WithValues("action", "import", "backend", r.backend())
+
+	keyring, storage, err := r.setup()
+	if err != nil {
+		return err
+	}
+
+	name, err := r.importName(ctx, storage)
+	if err != nil {
+		return err
+	}
+
+	log.Info("fetching argo-cd backup", "name", name)
+	encrypted, err := storage.Get(ctx, name)
+	if err != nil {
+		return newError(ExitStorage, "failed to fetch backup %s: %w", name, err)
+	}
+
+	log.Info("decrypting argo-cd backup", "bytes", len(encrypted))
+	data, err := keyring.Decrypt(ctx, encrypted)
+	if err != nil {
+		return newError(ExitCrypto, "failed to decrypt backup: %w", err)
+	}
+
+	log.Info("loading argo-cd backup")
+	if _, err := r.Command(ctx, data, "admin", "import", "-"); err != nil {
+		return newError(ExitArgoCD, "argocd admin import failed: %w", err)
+	}
+
+	id, _ := backupIDFromName(name)
+	if data, err := json.Marshal(ImportResult{BackupID: id, Name: name}); err != nil {
+		log.Error(err, "failed to encode import result")
+	} else {
+		r.writeTerminationMessage(log, data)
+	}
+
+	log.Info("argo-cd import complete", "name", name, "duration", time.Since(start).String())
+	return nil
+}
+
+func (r *Runner) backend() string {
+	if len(r.Options.Backend) == 0 {
+		return common.ArgoCDExportStorageBackendLocal
+	}
+	return r.Options.Backend
+}
+
+// prune deletes the backups that fall outside the retention policy and returns the remaining restore points.
+// Failures are logged but do not fail the export, as the new backup has been stored.
+func (r *Runner) prune(ctx context.Context, log logr.Logger, storage Storage, id string) []string {
+	names, err := storage.List(ctx, common.ArgoCDExportBackupFilePrefix)
+	if err != nil {
+		log.Error(err, "failed to list backups, retention is not applied")
+		return []string{id}
+	}
+
+	ids := backupIDs(names)
+	if !containsString(ids, id) {
+		// The listing of some object stores is only eventually consistent.
+		ids = sortBackupIDs(append(ids, id))
+	}
+
+	expired := r.Options.Retention.Expired(ids, r.now())
+	restorePoints := make([]string, 0, len(ids))
+	for _, existing := range ids {
+		if !containsString(expired, existing) {
+			restorePoints = append(restorePoints, existing)
+			continue
+		}
+		log.Info("pruning argo-cd backup", "name", BackupName(existing))
+		if err := storage.Delete(ctx, BackupName(existing)); err != nil {
+			log.Error(err, "failed to prune backup", "name", BackupName(existing))
+			restorePoints = append(restorePoints, existing)
+		}
+	}
+	return restorePoints
+}
+
+// reencrypt encrypts the given restore points that are not encrypted with the current key yet with it, after a key
+// rotation. It returns the key IDs of the restore points that could not be re-encrypted. Failures are logged but do
+// not fail the export, the next export tries again.
+func (r *Runner) reencrypt(ctx context.Context, log logr.Logger, keyring *Keyring, storage Storage, restorePoints []string) map[string]string {
+	keyID := keyring.KeyID()
+	remaining := map[string]string{}
+	for _, id := range restorePoints {
+		name := BackupName(id)
+		data, err := storage.Get(ctx, name)
+		if err != nil {
+			log.Error(err, "failed to fetch backup for re-encryption", "name", name)
+			remaining[id] = ""
+			continue
+		}
+		header, err := ReadHeader(data)
+		if err != nil {
+			log.Error(err, "failed to read backup header", "name", name)
+			remaining[id] = ""
+			continue
+		}
+		if header.KeyID == keyID {
+			continue
+		}
+
+		log.Info("re-encrypting argo-cd backup", "name", name, "fromKeyID", header.KeyID, "toKeyID", keyID)
+		plaintext, err := keyring.Decrypt(ctx, data)
+		if err == nil {
+			data, err = keyring.Encrypt(ctx, plaintext)
+		}
+		if err == nil {
+			err = storage.Put(ctx, name, data)
+		}
+		if err != nil {
+			log.Error(err, "failed to re-encrypt backup", "name", name)
+			remaining[id] = header.KeyID
+		}
+	}
+	return remaining
+}
+
+// writeResult writes the given result to the termination log, dropping the oldest restore points if the
+// result would not fit in a termination message.
+func (r *Runner) writeResult(log logr.Logger, result ExportResult) {
+	if len(r.Options.TerminationLog) == 0 {
+		return
+	}
+
+	var data []byte
+	for {
+		var err error
+		data, err = json.Marshal(result)
+		if err != nil {
+			log.Error(err, "failed to encode export result")
+			return
+		}
+		if len(data) <= maxTerminationMessageLength || len(result.RestorePoints) == 0 {
+			break
+		}
+		delete(result.RestorePointKeyIDs, result.RestorePoints[len(result.RestorePoints)-1])
+		result.RestorePoints = result.RestorePoints[:len(result.RestorePoints)-1]
+	}
+	r.writeTerminationMessage(log, data)
+}
+
+// writeTerminationMessage writes the given result to the termination log, if any.
+func (r *Runner) writeTerminationMessage(log logr.Logger, data []byte) {
+	if len(r.Options.TerminationLog) == 0 {
+		return
+	}
+	if err := os.WriteFile(r.Options.TerminationLog, data, 0644); err != nil {
+		log.Error(err, "failed to write result", "path", r.Options.TerminationLog)
+	}
+}
+
+// importName returns the name of the backup to import: the requested backup, else the most recent one. Backups
+// written by former versions of the export process, under a single fixed name, are imported if no timestamped
+// backup exists.
+func (r *Runner) importName(ctx context.Context, storage Storage) (string, error) {
+	if len(r.Options.BackupID) > 0 {
+		if _, err := time.Parse(common.ArgoCDExportBackupIDFormat, r.Options.BackupID); err != nil {
+			return "", newError(ExitConfig, "invalid backup ID %q: must be formatted as %s", r.Options.BackupID, common.ArgoCDExportBackupIDFormat)
+		}
+		return BackupName(r.Options.BackupID), nil
+	}
+
+	names, err := storage.List(ctx, common.ArgoCDExportBackupFilePrefix)
+	if err != nil {
+		return "", newError(ExitStorage, "failed to list backups: %w", err)
+	}
+	if ids := backupIDs(names); len(ids) > 0 {
+		return BackupName(ids[0]), nil
+	}
+	return common.ArgoCDExportBackupFileName, nil
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// setup reads the backup keys and creates the storage backend.
+func (r *Runner) setup() (*Keyring, Storage, error) {
+	keyring := r.Options.Keyring
+	if keyring == nil {
+		var err error
+		if keyring, err = NewKeyring(Secrets{Dir: r.Options.SecretsDir}); err != nil {
+			return nil, nil, newError(ExitConfig, "%w", err)
+		}
+	}
+
+	var err error
+	storage := r.Options.Storage
+	if storage == nil {
+		storage, err = NewStorage(r.Options.Backend, Secrets{Dir: r.Options.SecretsDir}, r.Options.BackupDir)
+		if err != nil {
+			return nil, nil, newError(ExitConfig, "failed to configure storage: %w", err)
+		}
+	}
+	return keyring, storage, nil
+}
+
+// execArgoCD runs the argocd CLI, the data is kept in memory and never written to disk unencrypted.
+func (r *Runner) execArgoCD(ctx context.Context, stdin []byte, args ...string) ([]byte, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, r.Options.ArgoCDBinary, args...)
+	if stdin != nil {
+		cmd.Stdin = bytes.NewReader(stdin)
+	}
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("%w: %s", err, bytes.TrimSpace(stderr.Bytes()))
+	}
+	return stdout.Bytes(), nil
+}
@@ -0,0 +1,268 @@
+package backup
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/argoproj-labs/argocd-operator/common"
+)
+
+func makeTestRunner(t *testing.T, backupKey string) (*Runner, string) {
+	t.Helper()
+	secretsDir := t.TempDir()
+	backupDir := t.TempDir()
+	if len(backupKey) > 0 {
+		assert.NoError(t, os.WriteFile(filepath.Join(secretsDir, common.ArgoCDKeyBackupKey), []byte(backupKey), 0600))
+	}
+
+	r := NewRunner(Options{
+		Backend:    common.ArgoCDExportStorageBackendLocal,
+		SecretsDir: secretsDir,
+		BackupDir:  backupDir,
+	}, logr.Discard())
+	return r, backupDir
+}
+
+func TestRunner_ExportImport(t *testing.T) {
+	r, backupDir := makeTestRunner(t, "my-backup-key")
+
+	exported := []byte("apiVersion: v1\nkind: ConfigMap\n")
+	var imported []byte
+	r.Command = func(ctx context.Context, stdin []byte, args ...string) ([]byte, error) {
+		switch args[1] {
+		case "export":
+			return exported, nil
+		case "import":
+			assert.Equal(t, []string{"admin", "import", "-"}, args)
+			imported = stdin
+			return nil, nil
+		}
+		return nil, errors.New("unexpected command")
+	}
+
+	r.now = func() time.Time { return time.Date(2023, 10, 17, 12, 0, 0, 0, time.UTC) }
+	r.Options.TerminationLog = filepath.Join(t.TempDir(), "termination-log")
+	assert.NoError(t, r.Export(context.TODO()))
+
+	stored, err := os.ReadFile(filepath.Join(backupDir, "argocd-backup-20231017T120000Z.yaml"))
+	assert.NoError(t, err)
+	assert.NotEqual(t, exported, stored)
+
+	result, err := os.ReadFile(r.Options.TerminationLog)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"backupID":"20231017T120000Z","restorePoints":["20231017T120000Z"],"keyID":"`+BackupKeyID([]byte("my-backup-key"))+`"}`, string(result))
+
+	assert.NoError(t, r.Import(context.TODO()))
+	assert.Equal(t, exported, imported)
+}
+
+func TestRunner_ExportNamespace(t *testing.T) {
+	r, _ := makeTestRunner(t, "my-backup-key")
+	var exportArgs []string
+	r.Command = func(ctx context.Context, stdin []byte, args ...string) ([]byte, error) {
+		exportArgs = args
+		return []byte("data"), nil
+	}
+
+	assert.NoError(t, r.Export(context.TODO()))
+	assert.Equal(t, []string{"admin", "export"}, exportArgs)
+
+	r.Options.Namespace = "argocd"
+	assert.NoError(t, r.Export(context.TODO()))
+	assert.Equal(t, []string{"admin", "export", "--namespace", "argocd"}, exportArgs)
+}
+
+func TestRunner_Retention(t *testing.T) {
+	r, backupDir := makeTestRunner(t, "my-backup-key")
+	r.Command = func(ctx context.Context, stdin []byte, args ...string) ([]byte, error) {
+		return []byte(args[1]), nil
+	}
+	r.Options.Retention = Retention{KeepLast: 3, MaxAge: 48 * time.Hour}
+	r.Options.TerminationLog = filepath.Join(t.TempDir(), "termination-log")
+
+	// A legacy backup and unrelated files are never pruned.
+	for _, name := range []string{common.ArgoCDExportBackupFileName, "argocd-backup-notes.yaml", "lost+found.yaml"} {
+		assert.NoError(t, os.WriteFile(filepath.Join(backupDir, name), []byte("data"), 0600))
+	}
+
+	now := time.Date(2023, 10, 10, 0, 0, 0, 0, time.UTC)
+	r.now = func() time.Time { return now }
+	for i := 0; i < 5; i++ {
+		assert.NoError(t, r.Export(context.TODO()))
+		now = now.Add(12 * time.Hour)
+	}
+
+	names, err := (&LocalStorage{Dir: backupDir}).List(context.TODO(), "")
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{
+		common.ArgoCDExportBackupFileName,
+		"argocd-backup-notes.yaml",
+		"lost+found.yaml",
+		"argocd-backup-20231012T000000Z.yaml",
+		"argocd-backup-20231011T120000Z.yaml",
+		"argocd-backup-20231011T000000Z.yaml",
+	}, names)
+
+	result, err := os.ReadFile(r.Options.TerminationLog)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"backupID":"20231012T000000Z","restorePoints":["20231012T000000Z","20231011T120000Z","20231011T000000Z"],"keyID":"`+
+		BackupKeyID([]byte("my-backup-key"))+`"}`, string(result))
+
+	// Only the age limit applies a week later, but the most recent backup is kept.
+	r.Options.Retention = Retention{MaxAge: 48 * time.Hour}
+	assert.Empty(t, r.Options.Retention.Expired([]string{"20231012T000000Z"}, now.Add(7*24*time.Hour)))
+}
+
+func TestRunner_ImportBackupID(t *testing.T) {
+	r, backupDir := makeTestRunner(t, "my-backup-key")
+	var imported []byte
+	r.Command = func(ctx context.Context, stdin []byte, args ...string) ([]byte, error) {
+		imported = stdin
+		return nil, nil
+	}
+
+	for name, data := range map[string]string{
+		common.ArgoCDExportBackupFileName:     "legacy",
+		"argocd-backup-20231010T000000Z.yaml": "older",
+		"argocd-backup-20231011T000000Z.yaml": "latest",
+	} {
+		encrypted, err := Encrypt([]byte("my-backup-key"), Header{}, []byte(data))
+		assert.NoError(t, err)
+		assert.NoError(t, os.WriteFile(filepath.Join(backupDir, name), encrypted, 0600))
+	}
+
+	r.Options.TerminationLog = filepath.Join(t.TempDir(), "termination-log")
+	assert.NoError(t, r.Import(context.TODO()))
+	assert.Equal(t, "latest", string(imported))
+	result, err := os.ReadFile(r.Options.TerminationLog)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"backupID":"20231011T000000Z","name":"argocd-backup-20231011T000000Z.yaml"}`, string(result))
+
+	r.Options.BackupID = "20231010T000000Z"
+	assert.NoError(t, r.Import(context.TODO()))
+	assert.Equal(t, "older", string(imported))
+
+	r.Options.BackupID = "20231009T000000Z"
+	assert.Equal(t, ExitStorage, ExitCode(r.Import(context.TODO())))
+
+	r.Options.BackupID = "yesterday"
+	assert.Equal(t, ExitConfig, ExitCode(r.Import(context.TODO())))
+
+	// Backups written before timestamped backups were introduced are still imported.
+	assert.NoError(t, os.Remove(filepath.Join(backupDir, "argocd-backup-20231010T000000Z.yaml")))
+	assert.NoError(t, os.Remove(filepath.Join(backupDir, "argocd-backup-20231011T000000Z.yaml")))
+	r.Options.BackupID = ""
+	assert.NoError(t, r.Import(context.TODO()))
+	assert.Equal(t, "legacy", string(imported))
+	result, err = os.ReadFile(r.Options.TerminationLog)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"name":"`+common.ArgoCDExportBackupFileName+`"}`, string(result))
+}
+
+func TestRunner_KeyRotation(t *testing.T) {
+	r, backupDir := makeTestRunner(t, "old-backup-key")
+	r.Command = func(ctx context.Context, stdin []byte, args ...string) ([]byte, error) {
+		if args[1] == "export" {
+			return []byte("data"), nil
+		}
+		return nil, nil
+	}
+	r.Options.TerminationLog = filepath.Join(t.TempDir(), "termination-log")
+
+	now := time.Date(2023, 10, 10, 0, 0, 0, 0, time.UTC)
+	r.now = func() time.Time { return now }
+	assert.NoError(t, r.Export(context.TODO()))
+
+	// A backup encrypted with a key that is not known anymore cannot be re-encrypted.
+	lost, err := Encrypt([]byte("lost-backup-key"), Header{KeyID: BackupKeyID([]byte("lost-backup-key"))}, []byte("data"))
+	assert.NoError(t, err)
+	assert.NoError(t, os.WriteFile(filepath.Join(backupDir, "argocd-backup-20231009T000000Z.yaml"), lost, 0600))
+
+	// The export fails until the rotated key is mounted.
+	oldKeyID, newKeyID := BackupKeyID([]byte("old-backup-key")), BackupKeyID([]byte("new-backup-key"))
+	r.Options.KeyID = newKeyID
+	now = now.Add(time.Hour)
+	assert.Equal(t, ExitConfig, ExitCode(r.Export(context.TODO())))
+
+	assert.NoError(t, os.WriteFile(filepath.Join(r.Options.SecretsDir, common.ArgoCDKeyBackupKey), []byte("new-backup-key"), 0600))
+	assert.NoError(t, os.WriteFile(filepath.Join(r.Options.SecretsDir, common.ArgoCDKeyBackupKeyPrevious), []byte("old-backup-key\n"), 0600))
+	assert.NoError(t, r.Export(context.TODO()))
+
+	for _, id := range []string{"20231010T000000Z", "20231010T010000Z"} {
+		data, err := os.ReadFile(filepath.Join(backupDir, BackupName(id)))
+		assert.NoError(t, err)
+		header, err := ReadHeader(data)
+		assert.NoError(t, err)
+		assert.Equal(t, newKeyID, header.KeyID)
+	}
+	assert.NotEqual(t, oldKeyID, newKeyID)
+
+	result, err := os.ReadFile(r.Options.TerminationLog)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"backupID":"20231010T010000Z","restorePoints":["20231010T010000Z","20231010T000000Z","20231009T000000Z"],`+
+		`"keyID":"`+newKeyID+`","restorePointKeyIDs":{"20231009T000000Z":"`+BackupKeyID([]byte("lost-backup-key"))+`"}}`, string(result))
+
+	// Once the previous keys are dropped, the re-encrypted backups are imported with the new key.
+	assert.NoError(t, os.Remove(filepath.Join(r.Options.SecretsDir, common.ArgoCDKeyBackupKeyPrevious)))
+	r.Options.BackupID = "20231010T000000Z"
+	assert.NoError(t, r.Import(context.TODO()))
+}
+
+func TestRetentionFromEnv(t *testing.T) {
+	t.Setenv(common.ArgoCDExportRetentionKeepLastEnvName, "7")
+	t.Setenv(common.ArgoCDExportRetentionMaxAgeEnvName, "720h")
+	retention, err := RetentionFromEnv()
+	assert.NoError(t, err)
+	assert.Equal(t, Retention{KeepLast: 7, MaxAge: 720 * time.Hour}, retention)
+
+	t.Setenv(common.ArgoCDExportRetentionMaxAgeEnvName, "30d")
+	_, err = RetentionFromEnv()
+	assert.Error(t, err)
+}
+
+func TestRunner_ExitCodes(t *testing.T) {
+	t.Run("missing backup key", func(t *testing.T) {
+		r, _ := makeTestRunner(t, "")
+		assert.Equal(t, ExitConfig, ExitCode(r.Export(context.TODO())))
+	})
+
+	t.Run("unsupported backend", func(t *testing.T) {
+		r, _ := makeTestRunner(t, "key")
+		r.Options.Backend = "dropbox"
+		assert.Equal(t, ExitConfig, ExitCode(r.Export(context.TODO())))
+	})
+
+	t.Run("missing cloud configuration", func(t *testing.T) {
+		r, _ := makeTestRunner(t, "key")
+		r.Options.Backend = common.ArgoCDExportStorageBackendGCP
+		assert.Equal(t, ExitConfig, ExitCode(r.Export(context.TODO())))
+	})
+
+	t.Run("argocd export fails", func(t *testing.T) {
+		r, _ := makeTestRunner(t, "key")
+		r.Command = func(ctx context.Context, stdin []byte, args ...string) ([]byte, error) {
+			return nil, errors.New("exit status 20")
+		}
+		assert.Equal(t, ExitArgoCD, ExitCode(r.Export(context.TODO())))
+	})
+
+	t.Run("backup not found", func(t *testing.T) {
+		r, _ := makeTestRunner(t, "key")
+		assert.Equal(t, ExitStorage, ExitCode(r.Import(context.TODO())))
+	})
+
+	t.Run("wrong backup key", func(t *testing.T) {
+		r, backupDir := makeTestRunner(t, "key")
+		encrypted, err := Encrypt([]byte("other-key"), Header{}, []byte("data"))
+		assert.NoError(t, err)
+		assert.NoError(t, os.WriteFile(filepath.Join(backupDir, common.ArgoCDExportBackupFileName), encrypted, 0600))
+		assert.Equal(t, ExitCrypto, ExitCode(r.Import(context.TODO())))
+	})
+}
@@ -0,0 +1,240 @@
+// Copyright 2023 ArgoCD Operator Developers
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backup
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"golang.org/x/crypto/pbkdf2"
+)
+
+const (
+	// formatMagic identifies a backup encrypted with AES-256-GCM by this package.
+	formatMagic = "ARGOCDB"
+
+	// formatVersion is the version of the encrypted backup format written by Encrypt. Version 2 adds the key ID and
+	// the wrapped data key to the header, version 1 backups can still be decrypted.
+	formatVersion   byte = 2
+	formatVersionV1 byte = 1
+
+	// kdfIterations is the number of PBKDF2-SHA256 iterations used to derive the encryption key.
+	kdfIterations = 600000
+
+	saltLength = 16
+	keyLength  = 32
+
+	// maxKeyIDLength and maxWrappedKeyLength bound the variable length fields of the header.
+	maxKeyIDLength      = 255
+	maxWrappedKeyLength = 65535
+
+	// legacyMagic prefixes backups encrypted by "openssl enc -aes-256-cbc -pbkdf2", as done by util.sh.
+	legacyMagic         = "Salted__"
+	legacySaltLength    = 8
+	legacyKDFIterations = 10000
+)
+
+// Header is the cleartext part of an encrypted backup that describes how it was encrypted.
+type Header struct {
+	// KeyID identifies the key the backup is encrypted with, it is empty for backups written before key IDs
+	// were recorded.
+	KeyID string
+
+	// WrappedKey is the data key of the backup encrypted by a KMS key, when envelope encryption is used.
+	WrappedKey []byte
+}
+
+// Encrypt encrypts the given backup with AES-256-GCM, using a key derived from the given backup key.
+//
+// The result is laid out as: magic | version | key ID length | key ID | wrapped key length | wrapped key | salt |
+// nonce | ciphertext and tag. The header is authenticated as additional data, so any tampering with the backup is
+// detected on decryption.
+func Encrypt(backupKey []byte, header Header, plaintext []byte) ([]byte, error) {
+	if len(header.KeyID) > maxKeyIDLength {
+		return nil, fmt.Errorf("key ID is longer than %d bytes", maxKeyIDLength)
+	}
+	if len(header.WrappedKey) > maxWrappedKeyLength {
+		return nil, fmt.Errorf("wrapped key is longer than %d bytes", maxWrappedKeyLength)
+	}
+
+	salt := make([]byte, saltLength)
+	if _, err := io.ReadFull(rand.Reader, salt); err != nil {
+		return nil, fmt.Errorf("failed to generate salt: %w", err)
+	}
+
+	gcm, err := newGCM(backupKey, salt)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+
+	data := make([]byte, 0, len(formatMagic)+4+len(header.KeyID)+len(header.WrappedKey)+saltLength+len(nonce))
+	data = append(data, formatMagic...)
+	data = append(data, formatVersion, byte(len(header.KeyID)))
+	data = append(data, header.KeyID...)
+	data = binary.BigEndian.AppendUint16(data, uint16(len(header.WrappedKey)))
+	data = append(data, header.WrappedKey...)
+	data = append(data, salt...)
+	data = append(data, nonce...)
+
+	// The ciphertext is sealed into a separate buffer, as Seal must not write over the header it authenticates.
+	return append(data, gcm.Seal(nil, nonce, plaintext, data)...), nil
+}
+
+// ReadHeader returns the header of the given encrypted backup. Backups without a key ID, including the ones
+// written by the former util.sh script, have an empty header.
+func ReadHeader(data []byte) (Header, error) {
+	if bytes.HasPrefix(data, []byte(legacyMagic)) {
+		return Header{}, nil
+	}
+	header, _, err := parseHeader(data)
+	return header, err
+}
+
+// Decrypt decrypts a backup produced by Encrypt. Backups written by the former util.sh script
+// (AES-256-CBC, openssl format) are detected and decrypted as well, so they can still be imported.
+// The backup key of an envelope encrypted backup is its unwrapped data key.
+func Decrypt(backupKey, data []byte) ([]byte, error) {
+	if bytes.HasPrefix(data, []byte(legacyMagic)) {
+		return decryptLegacy(backupKey, data)
+	}
+
+	_, offset, err := parseHeader(data)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < offset+saltLength {
+		return nil, errors.New("backup is truncated")
+	}
+	salt := data[offset : offset+saltLength]
+
+	gcm, err := newGCM(backupKey, salt)
+	if err != nil {
+		return nil, err
+	}
+
+	headerLength := offset + saltLength + gcm.NonceSize()
+	if len(data) < headerLength+gcm.Overhead() {
+		return nil, errors.New("backup is truncated")
+	}
+	header := data[:headerLength]
+	nonce := data[offset+saltLength : headerLength]
+
+	plaintext, err := gcm.Open(nil, nonce, data[headerLength:], header)
+	if err != nil {
+		return nil, errors.New("failed to authenticate backup, the backup key is wrong or the backup is corrupted")
+	}
+	return plaintext, nil
+}
+
+// parseHeader parses the header of a backup produced by Encrypt and returns the offset of the salt.
+func parseHeader(data []byte) (Header, int, error) {
+	if !bytes.HasPrefix(data, []byte(formatMagic)) {
+		return Header{}, 0, errors.New("unrecognized backup format")
+	}
+	offset := len(formatMagic) + 1
+	if len(data) < offset {
+		return Header{}, 0, errors.New("backup is truncated")
+	}
+
+	switch data[offset-1] {
+	case formatVersionV1:
+		return Header{}, offset, nil
+	case formatVersion:
+	default:
+		return Header{}, 0, errors.New("unsupported backup format version")
+	}
+
+	header := Header{}
+	if len(data) < offset+1 {
+		return Header{}, 0, errors.New("backup is truncated")
+	}
+	keyIDLength := int(data[offset])
+	offset++
+	if len(data) < offset+keyIDLength+2 {
+		return Header{}, 0, errors.New("backup is truncated")
+	}
+	header.KeyID = string(data[offset : offset+keyIDLength])
+	offset += keyIDLength
+
+	wrappedKeyLength := int(binary.BigEndian.Uint16(data[offset:]))
+	offset += 2
+	if len(data) < offset+wrappedKeyLength {
+		return Header{}, 0, errors.New("backup is truncated")
+	}
+	if wrappedKeyLength > 0 {
+		header.WrappedKey = append([]byte(nil), data[offset:offset+wrappedKeyLength]...)
+	}
+	offset += wrappedKeyLength
+
+	return header, offset, nil
+}
+
+func newGCM(backupKey, salt []byte) (cipher.AEAD, error) {
+	key := pbkdf2.Key(normalizeKey(backupKey), salt, kdfIterations, keyLength, sha256.New)
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// decryptLegacy decrypts data produced by "openssl enc -aes-256-cbc -pbkdf2 -pass file:<key>".
+func decryptLegacy(backupKey, data []byte) ([]byte, error) {
+	offset := len(legacyMagic) + legacySaltLength
+	if len(data) < offset+aes.BlockSize || (len(data)-offset)%aes.BlockSize != 0 {
+		return nil, errors.New("legacy backup is truncated")
+	}
+	salt := data[len(legacyMagic):offset]
+
+	derived := pbkdf2.Key(normalizeKey(backupKey), salt, legacyKDFIterations, keyLength+aes.BlockSize, sha256.New)
+	block, err := aes.NewCipher(derived[:keyLength])
+	if err != nil {
+		return nil, err
+	}
+
+	plaintext := make([]byte, len(data)-offset)
+	cipher.NewCBCDecrypter(block, derived[keyLength:]).CryptBlocks(plaintext, data[offset:])
+
+	padding := int(plaintext[len(plaintext)-1])
+	if padding == 0 || padding > aes.BlockSize || padding > len(plaintext) {
+		return nil, errors.New("failed to decrypt legacy backup, the backup key is wrong or the backup is corrupted")
+	}
+	for _, b := range plaintext[len(plaintext)-padding:] {
+		if int(b) != padding {
+			return nil, errors.New("failed to decrypt legacy backup, the backup key is wrong or the backup is corrupted")
+		}
+	}
+	return plaintext[:len(plaintext)-padding], nil
+}
+
+// normalizeKey mirrors how openssl reads a "-pass file:" argument: only the first line is used.
+func normalizeKey(backupKey []byte) []byte {
+	if i := bytes.IndexAny(backupKey, "\r\n"); i >= 0 {
+		return backupKey[:i]
+	}
+	return backupKey
+}
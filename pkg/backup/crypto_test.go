@@ -0,0 +1,111 @@
+package backup
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEncryptDecrypt(t *testing.T) {
+	key := []byte("my-backup-key")
+	plaintext := []byte("apiVersion: v1\nkind: ConfigMap\n")
+
+	encrypted, err := Encrypt(key, Header{}, plaintext)
+	assert.NoError(t, err)
+	assert.NotContains(t, string(encrypted), "ConfigMap")
+
+	decrypted, err := Decrypt(key, encrypted)
+	assert.NoError(t, err)
+	assert.Equal(t, plaintext, decrypted)
+
+	// a trailing newline in the key file is ignored
+	decrypted, err = Decrypt([]byte("my-backup-key\n"), encrypted)
+	assert.NoError(t, err)
+	assert.Equal(t, plaintext, decrypted)
+
+	// each backup uses a fresh salt and nonce
+	again, err := Encrypt(key, Header{}, plaintext)
+	assert.NoError(t, err)
+	assert.NotEqual(t, encrypted, again)
+}
+
+func TestDecrypt_Errors(t *testing.T) {
+	key := []byte("my-backup-key")
+	encrypted, err := Encrypt(key, Header{}, []byte("data"))
+	assert.NoError(t, err)
+
+	_, err = Decrypt([]byte("wrong-key"), encrypted)
+	assert.Error(t, err)
+
+	tampered := append([]byte(nil), encrypted...)
+	tampered[len(tampered)-1] ^= 0xff
+	_, err = Decrypt(key, tampered)
+	assert.Error(t, err)
+
+	_, err = Decrypt(key, encrypted[:len(formatMagic)+4])
+	assert.Error(t, err)
+
+	_, err = Decrypt(key, []byte("plain text"))
+	assert.Error(t, err)
+}
+
+func TestDecrypt_Legacy(t *testing.T) {
+	// printf 'my-backup-key' > key
+	// printf 'apiVersion: v1\nkind: ConfigMap\n' | openssl enc -aes-256-cbc -pbkdf2 -pass file:key
+	legacy, err := base64.StdEncoding.DecodeString("U2FsdGVkX1+Mib8FmpuSxoWwBpx1mqS1rJU1zpjpkLLxs72ssLdbsMQwVUBdp61U")
+	assert.NoError(t, err)
+
+	decrypted, err := Decrypt([]byte("my-backup-key"), legacy)
+	assert.NoError(t, err)
+	assert.Equal(t, "apiVersion: v1\nkind: ConfigMap\n", string(decrypted))
+
+	_, err = Decrypt([]byte("wrong-key"), legacy)
+	assert.Error(t, err)
+}
+
+func TestReadHeader(t *testing.T) {
+	key := []byte("my-backup-key")
+	header := Header{KeyID: "kms:alias/argocd-backups", WrappedKey: []byte("wrapped-data-key")}
+
+	encrypted, err := Encrypt(key, header, []byte("data"))
+	assert.NoError(t, err)
+
+	read, err := ReadHeader(encrypted)
+	assert.NoError(t, err)
+	assert.Equal(t, header, read)
+
+	decrypted, err := Decrypt(key, encrypted)
+	assert.NoError(t, err)
+	assert.Equal(t, "data", string(decrypted))
+
+	// the header is authenticated
+	tampered := append([]byte(nil), encrypted...)
+	tampered[len(formatMagic)+2] ^= 0xff
+	_, err = Decrypt(key, tampered)
+	assert.Error(t, err)
+
+	_, err = Encrypt(key, Header{KeyID: strings.Repeat("a", maxKeyIDLength+1)}, []byte("data"))
+	assert.Error(t, err)
+}
+
+func TestDecrypt_V1(t *testing.T) {
+	key := []byte("my-backup-key")
+
+	// version 1 backups have no key ID nor wrapped key in their header
+	salt := make([]byte, saltLength)
+	gcm, err := newGCM(key, salt)
+	assert.NoError(t, err)
+	nonce := make([]byte, gcm.NonceSize())
+	header := append(append(append([]byte(formatMagic), formatVersionV1), salt...), nonce...)
+	v1 := gcm.Seal(header, nonce, []byte("data"), header)
+
+	read, err := ReadHeader(v1)
+	assert.NoError(t, err)
+	assert.Equal(t, Header{}, read)
+
+	decrypted, err := Decrypt(key, v1)
+	assert.NoError(t, err)
+	assert.Equal(t, "data", string(decrypted))
+}
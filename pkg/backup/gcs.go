@@ -0,0 +1,233 @@
+// Copyright 2023 ArgoCD Operator Developers
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backup
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path/filepath"
+
+	"golang.org/x/oauth2/jwt"
+)
+
+const (
+	gcsDefaultBaseURL  = "https://storage.googleapis.com"
+	gcsDefaultTokenURL = "https://oauth2.googleapis.com/token"
+	gcsReadWriteScope  = "https://www.googleapis.com/auth/devstorage.read_write"
+)
+
+// GCSStorage stores backups in a Google Cloud Storage bucket using the JSON API.
+type GCSStorage struct {
+	// BaseURL is the base URL of the Cloud Storage API.
+	BaseURL   string
+	ProjectID string
+	Bucket    string
+
+	// HTTPClient must authenticate the requests, see NewGCSStorage.
+	HTTPClient *http.Client
+}
+
+// gcsServiceAccountKey holds the fields of a service account key file that are needed to obtain tokens.
+type gcsServiceAccountKey struct {
+	ClientEmail  string `json:"client_email"`
+	PrivateKey   string `json:"private_key"`
+	PrivateKeyID string `json:"private_key_id"`
+	TokenURI     string `json:"token_uri"`
+}
+
+// NewGCSStorage returns a GCSStorage configured from the export Secret, authenticating as the service
+// account whose key is stored in the gcp.key.file key.
+func NewGCSStorage(secrets Secrets) (*GCSStorage, error) {
+	projectID, err := secrets.Require("gcp.project.id")
+	if err != nil {
+		return nil, err
+	}
+	bucket, err := secrets.Require("gcp.bucket.name")
+	if err != nil {
+		return nil, err
+	}
+	keyFile, err := secrets.Require("gcp.key.file")
+	if err != nil {
+		return nil, err
+	}
+
+	key := gcsServiceAccountKey{}
+	if err := json.Unmarshal([]byte(keyFile), &key); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", filepath.Join(secrets.Dir, "gcp.key.file"), err)
+	}
+	tokenURL := key.TokenURI
+	if len(tokenURL) == 0 {
+		tokenURL = gcsDefaultTokenURL
+	}
+
+	conf := &jwt.Config{
+		Email:        key.ClientEmail,
+		PrivateKey:   []byte(key.PrivateKey),
+		PrivateKeyID: key.PrivateKeyID,
+		Scopes:       []string{gcsReadWriteScope},
+		TokenURL:     tokenURL,
+	}
+
+	return &GCSStorage{
+		BaseURL:    gcsDefaultBaseURL,
+		ProjectID:  projectID,
+		Bucket:     bucket,
+		HTTPClient: conf.Client(context.Background()),
+	}, nil
+}
+
+// Put implements Storage. The bucket is created, with uniform bucket-level access, if it does not exist yet.
+func (s *GCSStorage) Put(ctx context.Context, name string, data []byte) error {
+	if err := s.ensureBucket(ctx); err != nil {
+		return err
+	}
+
+	u := fmt.Sprintf("%s/upload/storage/v1/b/%s/o?uploadType=media&name=%s", s.BaseURL, url.PathEscape(s.Bucket), url.QueryEscape(name))
+	resp, err := s.do(ctx, http.MethodPost, u, "application/octet-stream", data)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return gcsError(resp, "failed to upload backup")
+	}
+	return nil
+}
+
+// Get implements Storage.
+func (s *GCSStorage) Get(ctx context.Context, name string) ([]byte, error) {
+	u := fmt.Sprintf("%s/storage/v1/b/%s/o/%s?alt=media", s.BaseURL, url.PathEscape(s.Bucket), url.PathEscape(name))
+	resp, err := s.do(ctx, http.MethodGet, u, "", nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, ErrNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, gcsError(resp, "failed to download backup")
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// List implements Storage. A missing bucket holds no backups.
+func (s *GCSStorage) List(ctx context.Context, prefix string) ([]string, error) {
+	names := make([]string, 0)
+	query := url.Values{"prefix": {prefix}, "fields": {"items(name),nextPageToken"}}
+	for {
+		u := fmt.Sprintf("%s/storage/v1/b/%s/o?%s", s.BaseURL, url.PathEscape(s.Bucket), query.Encode())
+		resp, err := s.do(ctx, http.MethodGet, u, "", nil)
+		if err != nil {
+			return nil, err
+		}
+		if resp.StatusCode == http.StatusNotFound {
+			resp.Body.Close()
+			return names, nil
+		}
+		if resp.StatusCode != http.StatusOK {
+			err := gcsError(resp, "failed to list backups")
+			resp.Body.Close()
+			return nil, err
+		}
+
+		var result struct {
+			Items []struct {
+				Name string `json:"name"`
+			} `json:"items"`
+			NextPageToken string `json:"nextPageToken"`
+		}
+		err = json.NewDecoder(resp.Body).Decode(&result)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode backup list: %w", err)
+		}
+		for _, item := range result.Items {
+			names = append(names, item.Name)
+		}
+		if len(result.NextPageToken) == 0 {
+			return names, nil
+		}
+		query.Set("pageToken", result.NextPageToken)
+	}
+}
+
+// Delete implements Storage.
+func (s *GCSStorage) Delete(ctx context.Context, name string) error {
+	u := fmt.Sprintf("%s/storage/v1/b/%s/o/%s", s.BaseURL, url.PathEscape(s.Bucket), url.PathEscape(name))
+	resp, err := s.do(ctx, http.MethodDelete, u, "", nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusNotFound {
+		return gcsError(resp, "failed to delete backup "+name)
+	}
+	return nil
+}
+
+// ensureBucket creates the bucket, a conflict means that it already exists.
+func (s *GCSStorage) ensureBucket(ctx context.Context) error {
+	body, err := json.Marshal(map[string]interface{}{
+		"name": s.Bucket,
+		"iamConfiguration": map[string]interface{}{
+			"uniformBucketLevelAccess": map[string]bool{"enabled": true},
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	u := fmt.Sprintf("%s/storage/v1/b?project=%s", s.BaseURL, url.QueryEscape(s.ProjectID))
+	resp, err := s.do(ctx, http.MethodPost, u, "application/json", body)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusConflict {
+		return gcsError(resp, "failed to create bucket "+s.Bucket)
+	}
+	return nil
+}
+
+func (s *GCSStorage) do(ctx context.Context, method, u, contentType string, body []byte) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, u, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	if len(contentType) > 0 {
+		req.Header.Set("Content-Type", contentType)
+	}
+	return s.HTTPClient.Do(req)
+}
+
+// gcsError builds an error from a Cloud Storage error response.
+func gcsError(resp *http.Response, msg string) error {
+	var gcsErr struct {
+		Error struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+	if err := json.Unmarshal(body, &gcsErr); err == nil && len(gcsErr.Error.Message) > 0 {
+		return fmt.Errorf("%s: %s: %s", msg, resp.Status, gcsErr.Error.Message)
+	}
+	return fmt.Errorf("%s: unexpected status %s", msg, resp.Status)
+}
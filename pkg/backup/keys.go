@@ -0,0 +1,157 @@
+// Copyright 2023 ArgoCD Operator Developers
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backup
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/argoproj-labs/argocd-operator/common"
+)
+
+// KMS wraps the data keys of envelope encrypted backups with a key managed by an external service.
+type KMS interface {
+	// KeyID identifies the KMS key, it is recorded with every backup encrypted with it.
+	KeyID() string
+
+	// GenerateDataKey returns a new data key, in plaintext and wrapped by the KMS key.
+	GenerateDataKey(ctx context.Context) (plaintext, wrapped []byte, err error)
+
+	// Decrypt unwraps a data key returned by GenerateDataKey, possibly for a former KMS key.
+	Decrypt(ctx context.Context, wrapped []byte) ([]byte, error)
+}
+
+// Keyring holds the keys used to encrypt new backups and to decrypt existing ones.
+type Keyring struct {
+	// Key is the current backup key, new backups are encrypted with it unless KMS is set.
+	Key []byte
+
+	// Previous are the former backup keys, kept until every retained backup has been re-encrypted.
+	Previous [][]byte
+
+	// Rotating is set while retained backups may still be encrypted with a former key, so that the export
+	// re-encrypts them.
+	Rotating bool
+
+	// KMS wraps a new data key for every backup when set.
+	KMS KMS
+}
+
+// NewKeyring returns the Keyring configured by the export Secret, and by the environment for the KMS. The former
+// keys are read from the backup.key.previous key, one per line, whose presence marks a rotation in progress.
+func NewKeyring(secrets Secrets) (*Keyring, error) {
+	key, err := os.ReadFile(filepath.Join(secrets.Dir, common.ArgoCDKeyBackupKey))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read backup key: %w", err)
+	}
+	if len(normalizeKey(key)) == 0 {
+		return nil, errors.New("backup key is empty")
+	}
+	keyring := &Keyring{Key: key}
+
+	previous, err := os.ReadFile(filepath.Join(secrets.Dir, common.ArgoCDKeyBackupKeyPrevious))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("failed to read previous backup keys: %w", err)
+	}
+	if err == nil {
+		keyring.Rotating = true
+		for _, line := range bytes.Split(previous, []byte("\n")) {
+			if line = bytes.TrimSpace(line); len(line) > 0 {
+				keyring.Previous = append(keyring.Previous, line)
+			}
+		}
+	}
+
+	if len(os.Getenv(common.ArgoCDExportKMSKeyIDEnvName)) > 0 {
+		kms, err := NewAWSKMS(secrets)
+		if err != nil {
+			return nil, fmt.Errorf("failed to configure kms: %w", err)
+		}
+		keyring.KMS = kms
+	}
+	return keyring, nil
+}
+
+// KeyID identifies the key new backups are encrypted with.
+func (k *Keyring) KeyID() string {
+	if k.KMS != nil {
+		return k.KMS.KeyID()
+	}
+	return BackupKeyID(k.Key)
+}
+
+// Encrypt encrypts the given backup with the current key, recording its ID in the header.
+func (k *Keyring) Encrypt(ctx context.Context, plaintext []byte) ([]byte, error) {
+	if k.KMS == nil {
+		return Encrypt(k.Key, Header{KeyID: k.KeyID()}, plaintext)
+	}
+
+	dataKey, wrapped, err := k.KMS.GenerateDataKey(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate data key: %w", err)
+	}
+	return Encrypt(dataKey, Header{KeyID: k.KeyID(), WrappedKey: wrapped}, plaintext)
+}
+
+// Decrypt decrypts the given backup with the KMS if its data key is wrapped, else with the current or a former
+// backup key. Only the key matching the recorded key ID is tried, every key is tried for older backups.
+func (k *Keyring) Decrypt(ctx context.Context, data []byte) ([]byte, error) {
+	header, err := ReadHeader(data)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(header.WrappedKey) > 0 {
+		if k.KMS == nil {
+			return nil, fmt.Errorf("backup is encrypted with %s, but no kms is configured", header.KeyID)
+		}
+		dataKey, err := k.KMS.Decrypt(ctx, header.WrappedKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decrypt data key: %w", err)
+		}
+		return Decrypt(dataKey, data)
+	}
+
+	var lastErr error
+	for _, key := range append([][]byte{k.Key}, k.Previous...) {
+		if len(header.KeyID) > 0 && BackupKeyID(key) != header.KeyID {
+			continue
+		}
+		plaintext, err := Decrypt(key, data)
+		if err == nil {
+			return plaintext, nil
+		}
+		lastErr = err
+	}
+	if lastErr == nil {
+		return nil, fmt.Errorf("backup is encrypted with key %s, which is neither the current nor a previous backup key", header.KeyID)
+	}
+	return nil, lastErr
+}
+
+// BackupKeyID returns the ID of the given backup key: a truncated HMAC-SHA256 of a fixed label keyed by the backup
+// key, which identifies the key without revealing it. It must match BackupKeyID in controllers/argoutil.
+func BackupKeyID(backupKey []byte) string {
+	mac := hmac.New(sha256.New, normalizeKey(backupKey))
+	mac.Write([]byte(common.ArgoCDExportBackupKeyIDLabel))
+	return hex.EncodeToString(mac.Sum(nil)[:8])
+}
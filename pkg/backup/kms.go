@@ -0,0 +1,180 @@
+// Copyright 2023 ArgoCD Operator Developers
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backup
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+
+	"github.com/argoproj-labs/argocd-operator/common"
+)
+
+// AWSKMS wraps data keys with a symmetric key managed by AWS KMS, or a compatible service, through the KMS JSON API.
+type AWSKMS struct {
+	// Endpoint is the URL of the KMS API, e.g. https://kms.us-east-1.amazonaws.com.
+	Endpoint *url.URL
+	Region   string
+
+	// Key is the ID, ARN or alias of the KMS key.
+	Key string
+
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+
+	HTTPClient *http.Client
+
+	// now returns the signing time, it is only overridden in tests.
+	now func() time.Time
+}
+
+// NewAWSKMS returns an AWSKMS for the key, region and endpoint passed by the operator as environment variables.
+// Credentials are read from the standard AWS environment variables and fall back to the export Secret.
+func NewAWSKMS(secrets Secrets) (*AWSKMS, error) {
+	key := os.Getenv(common.ArgoCDExportKMSKeyIDEnvName)
+	if len(key) == 0 {
+		return nil, fmt.Errorf("%s is required", common.ArgoCDExportKMSKeyIDEnvName)
+	}
+
+	region := os.Getenv(common.ArgoCDExportKMSRegionEnvName)
+	if len(region) == 0 {
+		region = defaultS3Region
+	}
+
+	endpoint := &url.URL{Scheme: "https", Host: fmt.Sprintf("kms.%s.amazonaws.com", region)}
+	if v := os.Getenv(common.ArgoCDExportKMSEndpointEnvName); len(v) > 0 {
+		var err error
+		if endpoint, err = url.Parse(v); err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", common.ArgoCDExportKMSEndpointEnvName, err)
+		}
+		if (endpoint.Scheme != "http" && endpoint.Scheme != "https") || len(endpoint.Host) == 0 {
+			return nil, fmt.Errorf("%s must be an http or https URL", common.ArgoCDExportKMSEndpointEnvName)
+		}
+	}
+
+	accessKeyID, err := envOrSecret("AWS_ACCESS_KEY_ID", secrets, "kms.access.key.id")
+	if err != nil {
+		return nil, err
+	}
+	secretAccessKey, err := envOrSecret("AWS_SECRET_ACCESS_KEY", secrets, "kms.secret.access.key")
+	if err != nil {
+		return nil, err
+	}
+	if len(accessKeyID) == 0 || len(secretAccessKey) == 0 {
+		return nil, fmt.Errorf("kms credentials are missing, set kms.access.key.id and kms.secret.access.key in the export secret")
+	}
+
+	return &AWSKMS{
+		Endpoint:        endpoint,
+		Region:          region,
+		Key:             key,
+		AccessKeyID:     accessKeyID,
+		SecretAccessKey: secretAccessKey,
+		SessionToken:    os.Getenv("AWS_SESSION_TOKEN"),
+		HTTPClient:      http.DefaultClient,
+	}, nil
+}
+
+// KeyID implements KMS.
+func (k *AWSKMS) KeyID() string {
+	return common.ArgoCDExportKMSKeyIDPrefix + k.Key
+}
+
+// GenerateDataKey implements KMS.
+func (k *AWSKMS) GenerateDataKey(ctx context.Context) ([]byte, []byte, error) {
+	var result struct {
+		CiphertextBlob []byte
+		Plaintext      []byte
+	}
+	if err := k.do(ctx, "GenerateDataKey", map[string]string{"KeyId": k.Key, "KeySpec": "AES_256"}, &result); err != nil {
+		return nil, nil, err
+	}
+	if len(result.Plaintext) == 0 || len(result.CiphertextBlob) == 0 {
+		return nil, nil, fmt.Errorf("kms returned an empty data key")
+	}
+	return result.Plaintext, result.CiphertextBlob, nil
+}
+
+// Decrypt implements KMS. The KMS key is not passed, so that data keys wrapped by a former key can be unwrapped.
+func (k *AWSKMS) Decrypt(ctx context.Context, wrapped []byte) ([]byte, error) {
+	var result struct {
+		Plaintext []byte
+	}
+	if err := k.do(ctx, "Decrypt", map[string][]byte{"CiphertextBlob": wrapped}, &result); err != nil {
+		return nil, err
+	}
+	if len(result.Plaintext) == 0 {
+		return nil, fmt.Errorf("kms returned an empty data key")
+	}
+	return result.Plaintext, nil
+}
+
+// do sends a signed request for the given action to the KMS API and decodes the response into result.
+func (k *AWSKMS) do(ctx context.Context, action string, input, result interface{}) error {
+	body, err := json.Marshal(input)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, k.Endpoint.String(), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.ContentLength = int64(len(body))
+	req.Header.Set("Content-Type", "application/x-amz-json-1.1")
+	req.Header.Set("X-Amz-Target", "TrentService."+action)
+
+	now := time.Now
+	if k.now != nil {
+		now = k.now
+	}
+	signAWSRequest(req, body, now().UTC(), "kms", k.Region, k.AccessKeyID, k.SecretAccessKey, k.SessionToken)
+
+	client := k.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		var kmsErr struct {
+			Type    string `json:"__type"`
+			Message string `json:"message"`
+		}
+		if err := json.Unmarshal(data, &kmsErr); err == nil && len(kmsErr.Type) > 0 {
+			return fmt.Errorf("kms %s failed: %s: %s", action, kmsErr.Type, kmsErr.Message)
+		}
+		return fmt.Errorf("kms %s failed: unexpected status %s", action, resp.Status)
+	}
+	if err := json.Unmarshal(data, result); err != nil {
+		return fmt.Errorf("failed to decode kms %s response: %w", action, err)
+	}
+	return nil
+}
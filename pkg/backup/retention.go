@@ -0,0 +1,121 @@
+// Copyright 2023 ArgoCD Operator Developers
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backup
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/argoproj-labs/argocd-operator/common"
+)
+
+// Retention is the pruning policy applied to the stored backups after each export. The zero value keeps
+// every backup.
+type Retention struct {
+	// KeepLast is the number of most recent backups to keep, zero means no limit.
+	KeepLast int
+
+	// MaxAge is the maximum age of the backups to keep, zero means no limit.
+	MaxAge time.Duration
+}
+
+// RetentionFromEnv returns the Retention configured by the operator through environment variables.
+func RetentionFromEnv() (Retention, error) {
+	retention := Retention{}
+
+	if v := os.Getenv(common.ArgoCDExportRetentionKeepLastEnvName); len(v) > 0 {
+		keepLast, err := strconv.Atoi(v)
+		if err != nil || keepLast < 0 {
+			return retention, fmt.Errorf("invalid %s %q: must be a non-negative integer", common.ArgoCDExportRetentionKeepLastEnvName, v)
+		}
+		retention.KeepLast = keepLast
+	}
+
+	if v := os.Getenv(common.ArgoCDExportRetentionMaxAgeEnvName); len(v) > 0 {
+		maxAge, err := time.ParseDuration(v)
+		if err != nil || maxAge < 0 {
+			return retention, fmt.Errorf("invalid %s %q: must be a non-negative duration", common.ArgoCDExportRetentionMaxAgeEnvName, v)
+		}
+		retention.MaxAge = maxAge
+	}
+
+	return retention, nil
+}
+
+// Expired returns the IDs of the given backups that fall outside the retention policy at the given time.
+// The most recent backup is never expired, so that a restore point is always available.
+func (r Retention) Expired(ids []string, now time.Time) []string {
+	sorted := sortBackupIDs(ids)
+
+	expired := make([]string, 0)
+	for i, id := range sorted {
+		if i == 0 {
+			continue
+		}
+		if r.KeepLast > 0 && i >= r.KeepLast {
+			expired = append(expired, id)
+			continue
+		}
+		if t, err := time.Parse(common.ArgoCDExportBackupIDFormat, id); err == nil && r.MaxAge > 0 && now.Sub(t) > r.MaxAge {
+			expired = append(expired, id)
+		}
+	}
+	return expired
+}
+
+// NewBackupID returns the ID of a backup taken at the given time.
+func NewBackupID(t time.Time) string {
+	return t.UTC().Format(common.ArgoCDExportBackupIDFormat)
+}
+
+// BackupName returns the name the backup with the given ID is stored under.
+func BackupName(id string) string {
+	return common.ArgoCDExportBackupFilePrefix + id + common.ArgoCDExportBackupFileSuffix
+}
+
+// backupIDFromName returns the ID of the backup stored under the given name, or false if the name does not
+// belong to a timestamped backup.
+func backupIDFromName(name string) (string, bool) {
+	if !strings.HasPrefix(name, common.ArgoCDExportBackupFilePrefix) || !strings.HasSuffix(name, common.ArgoCDExportBackupFileSuffix) {
+		return "", false
+	}
+	id := strings.TrimSuffix(strings.TrimPrefix(name, common.ArgoCDExportBackupFilePrefix), common.ArgoCDExportBackupFileSuffix)
+	if _, err := time.Parse(common.ArgoCDExportBackupIDFormat, id); err != nil {
+		return "", false
+	}
+	return id, true
+}
+
+// backupIDs returns the IDs of the given stored names that are timestamped backups, most recent first.
+func backupIDs(names []string) []string {
+	ids := make([]string, 0, len(names))
+	for _, name := range names {
+		if id, ok := backupIDFromName(name); ok {
+			ids = append(ids, id)
+		}
+	}
+	return sortBackupIDs(ids)
+}
+
+// sortBackupIDs returns a copy of the given IDs, most recent first. IDs sort lexically in time order.
+func sortBackupIDs(ids []string) []string {
+	sorted := append([]string(nil), ids...)
+	sort.Sort(sort.Reverse(sort.StringSlice(sorted)))
+	return sorted
+}
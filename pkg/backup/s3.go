@@ -0,0 +1,520 @@
+// Copyright 2023 ArgoCD Operator Developers
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backup
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/md5"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/argoproj-labs/argocd-operator/common"
+)
+
+const (
+	// defaultS3Region is the region used when the export secret does not provide one.
+	defaultS3Region = "us-east-1"
+
+	s3PublicAccessBlock = `<PublicAccessBlockConfiguration xmlns="http://s3.amazonaws.com/doc/2006-03-01/">` +
+		`<BlockPublicAcls>true</BlockPublicAcls><IgnorePublicAcls>true</IgnorePublicAcls>` +
+		`<BlockPublicPolicy>true</BlockPublicPolicy><RestrictPublicBuckets>true</RestrictPublicBuckets>` +
+		`</PublicAccessBlockConfiguration>`
+)
+
+// S3Storage stores backups in an S3 bucket. Requests are signed with AWS Signature Version 4, so any
+// S3 compatible object store can be used.
+type S3Storage struct {
+	// Endpoint is the base URL of the S3 API, e.g. https://s3.us-east-1.amazonaws.com.
+	Endpoint *url.URL
+	Region   string
+	Bucket   string
+
+	// PathStyle addresses the bucket as part of the path instead of the host name.
+	PathStyle bool
+
+	// BlockPublicAccess blocks all public access to buckets created by Put. Only AWS supports this API.
+	BlockPublicAccess bool
+
+	// ServerSideEncryption is the server-side encryption algorithm requested for uploads, "AES256" or
+	// "aws:kms". No encryption is requested if empty.
+	ServerSideEncryption string
+	// SSEKMSKeyID is the KMS key used when ServerSideEncryption is "aws:kms".
+	SSEKMSKeyID string
+
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+
+	HTTPClient *http.Client
+
+	// now returns the signing time, it is only overridden in tests.
+	now func() time.Time
+}
+
+// NewS3Storage returns an S3Storage for AWS, configured from the export Secret. Credentials are read
+// from the standard AWS environment variables and fall back to the export Secret.
+func NewS3Storage(secrets Secrets) (*S3Storage, error) {
+	bucket, err := secrets.Require("aws.bucket.name")
+	if err != nil {
+		return nil, err
+	}
+
+	region, err := secrets.Get("aws.bucket.region")
+	if err != nil {
+		return nil, err
+	}
+	if len(region) == 0 {
+		region = defaultS3Region
+	}
+
+	accessKeyID, err := envOrSecret("AWS_ACCESS_KEY_ID", secrets, "aws.access.key.id")
+	if err != nil {
+		return nil, err
+	}
+	secretAccessKey, err := envOrSecret("AWS_SECRET_ACCESS_KEY", secrets, "aws.secret.access.key")
+	if err != nil {
+		return nil, err
+	}
+	if len(accessKeyID) == 0 || len(secretAccessKey) == 0 {
+		return nil, fmt.Errorf("aws credentials are missing, set aws.access.key.id and aws.secret.access.key in the export secret")
+	}
+
+	return &S3Storage{
+		Endpoint:          &url.URL{Scheme: "https", Host: fmt.Sprintf("s3.%s.amazonaws.com", region)},
+		Region:            region,
+		Bucket:            bucket,
+		BlockPublicAccess: true,
+		AccessKeyID:       accessKeyID,
+		SecretAccessKey:   secretAccessKey,
+		SessionToken:      os.Getenv("AWS_SESSION_TOKEN"),
+		HTTPClient:        http.DefaultClient,
+	}, nil
+}
+
+// NewGenericS3Storage returns an S3Storage for any S3 compatible object store, such as MinIO or Ceph RGW.
+// The endpoint, bucket and TLS options are passed by the operator as environment variables, credentials
+// are read from the standard AWS environment variables and fall back to the export Secret.
+func NewGenericS3Storage(secrets Secrets) (*S3Storage, error) {
+	endpoint, err := url.Parse(os.Getenv(common.ArgoCDExportS3EndpointEnvName))
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", common.ArgoCDExportS3EndpointEnvName, err)
+	}
+	if (endpoint.Scheme != "http" && endpoint.Scheme != "https") || len(endpoint.Host) == 0 {
+		return nil, fmt.Errorf("%s must be an http or https URL", common.ArgoCDExportS3EndpointEnvName)
+	}
+
+	bucket := os.Getenv(common.ArgoCDExportS3BucketEnvName)
+	if len(bucket) == 0 {
+		return nil, fmt.Errorf("%s is required", common.ArgoCDExportS3BucketEnvName)
+	}
+
+	region := os.Getenv(common.ArgoCDExportS3RegionEnvName)
+	if len(region) == 0 {
+		region = defaultS3Region
+	}
+
+	pathStyle := false
+	if v := os.Getenv(common.ArgoCDExportS3ForcePathStyleEnvName); len(v) > 0 {
+		if pathStyle, err = strconv.ParseBool(v); err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", common.ArgoCDExportS3ForcePathStyleEnvName, err)
+		}
+	}
+
+	accessKeyID, err := envOrSecret("AWS_ACCESS_KEY_ID", secrets, "s3.access.key.id")
+	if err != nil {
+		return nil, err
+	}
+	secretAccessKey, err := envOrSecret("AWS_SECRET_ACCESS_KEY", secrets, "s3.secret.access.key")
+	if err != nil {
+		return nil, err
+	}
+	if len(accessKeyID) == 0 || len(secretAccessKey) == 0 {
+		return nil, fmt.Errorf("s3 credentials are missing, set s3.access.key.id and s3.secret.access.key in the export secret")
+	}
+
+	httpClient := http.DefaultClient
+	if caBundle := os.Getenv(common.ArgoCDExportS3CABundleEnvName); len(caBundle) > 0 {
+		if httpClient, err = newHTTPClientWithCABundle(caBundle); err != nil {
+			return nil, err
+		}
+	}
+
+	return &S3Storage{
+		Endpoint:             endpoint,
+		Region:               region,
+		Bucket:               bucket,
+		PathStyle:            pathStyle,
+		ServerSideEncryption: os.Getenv(common.ArgoCDExportS3SSEEnvName),
+		SSEKMSKeyID:          os.Getenv(common.ArgoCDExportS3SSEKMSKeyIDEnvName),
+		AccessKeyID:          accessKeyID,
+		SecretAccessKey:      secretAccessKey,
+		SessionToken:         os.Getenv("AWS_SESSION_TOKEN"),
+		HTTPClient:           httpClient,
+	}, nil
+}
+
+// newHTTPClientWithCABundle returns an HTTP client that trusts the CAs of the given PEM file in addition
+// to the system CAs.
+func newHTTPClientWithCABundle(path string) (*http.Client, error) {
+	pem, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CA bundle: %w", err)
+	}
+
+	pool, err := x509.SystemCertPool()
+	if err != nil || pool == nil {
+		pool = x509.NewCertPool()
+	}
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("no certificates found in CA bundle %s", path)
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = &tls.Config{RootCAs: pool, MinVersion: tls.VersionTLS12}
+	return &http.Client{Transport: transport}, nil
+}
+
+// Put implements Storage. The bucket is created if it does not exist yet, with all public access blocked
+// if BlockPublicAccess is set.
+func (s *S3Storage) Put(ctx context.Context, name string, data []byte) error {
+	if err := s.ensureBucket(ctx); err != nil {
+		return err
+	}
+
+	var headers map[string]string
+	if len(s.ServerSideEncryption) > 0 {
+		headers = map[string]string{"X-Amz-Server-Side-Encryption": s.ServerSideEncryption}
+		if len(s.SSEKMSKeyID) > 0 {
+			headers["X-Amz-Server-Side-Encryption-Aws-Kms-Key-Id"] = s.SSEKMSKeyID
+		}
+	}
+
+	resp, err := s.do(ctx, http.MethodPut, name, nil, data, headers)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return s3Error(resp, "failed to upload backup")
+	}
+	return nil
+}
+
+// Get implements Storage.
+func (s *S3Storage) Get(ctx context.Context, name string) ([]byte, error) {
+	resp, err := s.do(ctx, http.MethodGet, name, nil, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, ErrNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, s3Error(resp, "failed to download backup")
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// List implements Storage. A missing bucket holds no backups.
+func (s *S3Storage) List(ctx context.Context, prefix string) ([]string, error) {
+	names := make([]string, 0)
+	query := url.Values{"list-type": {"2"}, "prefix": {prefix}}
+	for {
+		resp, err := s.do(ctx, http.MethodGet, "", query, nil, nil)
+		if err != nil {
+			return nil, err
+		}
+		if resp.StatusCode == http.StatusNotFound {
+			resp.Body.Close()
+			return names, nil
+		}
+		if resp.StatusCode != http.StatusOK {
+			err := s3Error(resp, "failed to list backups")
+			resp.Body.Close()
+			return nil, err
+		}
+
+		var result struct {
+			Contents []struct {
+				Key string `xml:"Key"`
+			} `xml:"Contents"`
+			IsTruncated           bool   `xml:"IsTruncated"`
+			NextContinuationToken string `xml:"NextContinuationToken"`
+		}
+		err = xml.NewDecoder(resp.Body).Decode(&result)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode backup list: %w", err)
+		}
+		for _, c := range result.Contents {
+			names = append(names, c.Key)
+		}
+		if !result.IsTruncated || len(result.NextContinuationToken) == 0 {
+			return names, nil
+		}
+		query.Set("continuation-token", result.NextContinuationToken)
+	}
+}
+
+// Delete implements Storage.
+func (s *S3Storage) Delete(ctx context.Context, name string) error {
+	resp, err := s.do(ctx, http.MethodDelete, name, nil, nil, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNotFound {
+		return s3Error(resp, "failed to delete backup "+name)
+	}
+	return nil
+}
+
+// ensureBucket creates the bucket if it does not exist.
+func (s *S3Storage) ensureBucket(ctx context.Context) error {
+	resp, err := s.do(ctx, http.MethodHead, "", nil, nil, nil)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusForbidden:
+		// The bucket exists, possibly without permission to list it. The upload reports any real access problem.
+		return nil
+	case http.StatusNotFound:
+	default:
+		return fmt.Errorf("failed to look up bucket %s: unexpected status %s", s.Bucket, resp.Status)
+	}
+
+	var body []byte
+	if s.Region != defaultS3Region {
+		body = []byte(fmt.Sprintf(`<CreateBucketConfiguration xmlns="http://s3.amazonaws.com/doc/2006-03-01/">`+
+			`<LocationConstraint>%s</LocationConstraint></CreateBucketConfiguration>`, s.Region))
+	}
+	resp, err = s.do(ctx, http.MethodPut, "", nil, body, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return s3Error(resp, "failed to create bucket "+s.Bucket)
+	}
+
+	if !s.BlockPublicAccess {
+		return nil
+	}
+
+	sum := md5.Sum([]byte(s3PublicAccessBlock))
+	headers := map[string]string{"Content-MD5": base64.StdEncoding.EncodeToString(sum[:])}
+	resp, err = s.do(ctx, http.MethodPut, "", url.Values{"publicAccessBlock": {""}}, []byte(s3PublicAccessBlock), headers)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return s3Error(resp, "failed to block public access to bucket "+s.Bucket)
+	}
+	return nil
+}
+
+// objectURL returns the URL of the given object, or of the bucket itself if key is empty.
+func (s *S3Storage) objectURL(key string, query url.Values) *url.URL {
+	u := *s.Endpoint
+	basePath := strings.TrimSuffix(u.Path, "/")
+	if s.PathStyle {
+		u.Path = basePath + "/" + s.Bucket
+		if len(key) > 0 {
+			u.Path += "/" + key
+		}
+	} else {
+		u.Host = s.Bucket + "." + u.Host
+		u.Path = basePath + "/" + key
+	}
+	u.RawPath = ""
+	u.RawQuery = query.Encode()
+	return &u
+}
+
+// do sends a signed request for the given object to the S3 API.
+func (s *S3Storage) do(ctx context.Context, method, key string, query url.Values, body []byte, headers map[string]string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, s.objectURL(key, query).String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.ContentLength = int64(len(body))
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	now := time.Now
+	if s.now != nil {
+		now = s.now
+	}
+	s.sign(req, body, now().UTC())
+
+	client := s.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return client.Do(req)
+}
+
+// sign adds an AWS Signature Version 4 Authorization header to the given request.
+func (s *S3Storage) sign(req *http.Request, body []byte, now time.Time) {
+	signAWSRequest(req, body, now, "s3", s.Region, s.AccessKeyID, s.SecretAccessKey, s.SessionToken)
+}
+
+// signAWSRequest adds an AWS Signature Version 4 Authorization header for the given service and region to the
+// given request.
+func signAWSRequest(req *http.Request, body []byte, now time.Time, service, region, accessKeyID, secretAccessKey, sessionToken string) {
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+	payloadHash := sha256Hex(body)
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	if len(sessionToken) > 0 {
+		req.Header.Set("X-Amz-Security-Token", sessionToken)
+	}
+
+	// Canonical headers: host plus every content and x-amz header, lower-cased and sorted.
+	signed := map[string]string{"host": req.URL.Host}
+	for k, v := range req.Header {
+		lk := strings.ToLower(k)
+		if strings.HasPrefix(lk, "x-amz-") || lk == "content-md5" || lk == "content-type" {
+			signed[lk] = strings.TrimSpace(strings.Join(v, ","))
+		}
+	}
+	names := make([]string, 0, len(signed))
+	for k := range signed {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, k := range names {
+		canonicalHeaders.WriteString(k + ":" + signed[k] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		awsURIEscape(req.URL.Path, false),
+		canonicalQuery(req.URL.Query()),
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := date + "/" + region + "/" + service + "/aws4_request"
+	stringToSign := strings.Join([]string{
+		"AWS4-HMAC-SHA256",
+		amzDate,
+		scope,
+		sha256Hex([]byte(canonicalRequest)),
+	}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+secretAccessKey), date)
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, service)
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		accessKeyID, scope, signedHeaders, signature))
+}
+
+// canonicalQuery returns the query string sorted by key, with keys and values escaped as required by SigV4.
+func canonicalQuery(query url.Values) string {
+	keys := make([]string, 0, len(query))
+	for k := range query {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	parts := make([]string, 0, len(keys))
+	for _, k := range keys {
+		values := append([]string(nil), query[k]...)
+		sort.Strings(values)
+		for _, v := range values {
+			parts = append(parts, awsURIEscape(k, true)+"="+awsURIEscape(v, true))
+		}
+	}
+	return strings.Join(parts, "&")
+}
+
+// awsURIEscape escapes every byte except the RFC 3986 unreserved characters, and '/' unless encodeSlash is set.
+func awsURIEscape(s string, encodeSlash bool) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case 'A' <= c && c <= 'Z', 'a' <= c && c <= 'z', '0' <= c && c <= '9', c == '-', c == '_', c == '.', c == '~':
+			b.WriteByte(c)
+		case c == '/' && !encodeSlash:
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
+
+// s3Error builds an error from an S3 error response.
+func s3Error(resp *http.Response, msg string) error {
+	var s3err struct {
+		Code    string `xml:"Code"`
+		Message string `xml:"Message"`
+	}
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+	if err := xml.Unmarshal(body, &s3err); err == nil && len(s3err.Code) > 0 {
+		return fmt.Errorf("%s: %s: %s", msg, s3err.Code, s3err.Message)
+	}
+	return fmt.Errorf("%s: unexpected status %s", msg, resp.Status)
+}
+
+// envOrSecret returns the value of the given environment variable, or of the given export Secret key.
+func envOrSecret(env string, secrets Secrets, key string) (string, error) {
+	if v := os.Getenv(env); len(v) > 0 {
+		return v, nil
+	}
+	return secrets.Get(key)
+}
@@ -0,0 +1,147 @@
+// Copyright 2023 ArgoCD Operator Developers
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/argoproj-labs/argocd-operator/common"
+)
+
+// ErrNotFound is returned by a Storage when the requested backup does not exist.
+var ErrNotFound = errors.New("backup not found")
+
+// Storage is a backend that encrypted backups are written to and read from.
+type Storage interface {
+	// Put stores the given data under the given name, replacing any existing backup with the same name.
+	Put(ctx context.Context, name string, data []byte) error
+
+	// Get returns the data stored under the given name, or ErrNotFound.
+	Get(ctx context.Context, name string) ([]byte, error)
+
+	// List returns the names of the stored backups starting with the given prefix, in no particular order.
+	List(ctx context.Context, prefix string) ([]string, error)
+
+	// Delete removes the backup stored under the given name, a missing backup is not an error.
+	Delete(ctx context.Context, name string) error
+}
+
+// Secrets gives access to the keys of the export Secret, which is mounted as a directory of files.
+type Secrets struct {
+	Dir string
+}
+
+// Get returns the trimmed value of the given key, or an empty string if the key is not present.
+func (s Secrets) Get(key string) (string, error) {
+	data, err := os.ReadFile(filepath.Join(s.Dir, key))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return "", nil
+		}
+		return "", err
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+// Require returns the trimmed value of the given key, or an error if the key is not present.
+func (s Secrets) Require(key string) (string, error) {
+	value, err := s.Get(key)
+	if err != nil {
+		return "", err
+	}
+	if len(value) == 0 {
+		return "", fmt.Errorf("required key %s is missing from the export secret", key)
+	}
+	return value, nil
+}
+
+// NewStorage returns the Storage for the given backend, configured from the export Secret.
+func NewStorage(backend string, secrets Secrets, backupDir string) (Storage, error) {
+	switch strings.ToLower(backend) {
+	case "", common.ArgoCDExportStorageBackendLocal:
+		return &LocalStorage{Dir: backupDir}, nil
+	case common.ArgoCDExportStorageBackendAWS:
+		return NewS3Storage(secrets)
+	case common.ArgoCDExportStorageBackendAzure:
+		return NewAzureStorage(secrets)
+	case common.ArgoCDExportStorageBackendGCP:
+		return NewGCSStorage(secrets)
+	case common.ArgoCDExportStorageBackendS3:
+		return NewGenericS3Storage(secrets)
+	default:
+		return nil, fmt.Errorf("unsupported storage backend %q", backend)
+	}
+}
+
+// LocalStorage stores backups in a local directory, usually backed by a PersistentVolumeClaim.
+type LocalStorage struct {
+	Dir string
+}
+
+// Put implements Storage. The backup is written to a temporary file first, so that an existing
+// backup is never left half-written.
+func (s *LocalStorage) Put(ctx context.Context, name string, data []byte) error {
+	tmp, err := os.CreateTemp(s.Dir, "."+name+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), filepath.Join(s.Dir, name))
+}
+
+// Get implements Storage.
+func (s *LocalStorage) Get(ctx context.Context, name string) ([]byte, error) {
+	data, err := os.ReadFile(filepath.Join(s.Dir, name))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	return data, err
+}
+
+// List implements Storage.
+func (s *LocalStorage) List(ctx context.Context, prefix string) ([]string, error) {
+	entries, err := os.ReadDir(s.Dir)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0)
+	for _, e := range entries {
+		if !e.IsDir() && strings.HasPrefix(e.Name(), prefix) {
+			names = append(names, e.Name())
+		}
+	}
+	return names, nil
+}
+
+// Delete implements Storage.
+func (s *LocalStorage) Delete(ctx context.Context, name string) error {
+	if err := os.Remove(filepath.Join(s.Dir, name)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}